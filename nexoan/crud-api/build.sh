@@ -1,5 +1,5 @@
 echo "Building all packages..."
 go build -v ./...
 echo "Building crud-service binary..."
-go build -v -o crud-service cmd/server/service.go cmd/server/utils.go
+go build -v -o crud-service cmd/server/service.go cmd/server/utils.go cmd/server/config_command.go cmd/server/health.go cmd/server/metrics.go cmd/server/auth.go
 echo "Build complete!"
\ No newline at end of file
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+)
+
+// EntityKind returns the kind of a stored entity, it lets the authorization policy restrict kinds
+// for requests that only carry an entity ID
+func (s *Server) EntityKind(ctx context.Context, entityID string) (*pb.Kind, error) {
+	kind, _, _, _, err := s.graphStore.GetGraphEntity(ctx, entityID)
+	return kind, err
+}
+
+// Relationship returns a stored relationship, the authorization policy applies to the kinds of its endpoints
+func (s *Server) Relationship(ctx context.Context, relationshipID string) (*pb.EntityRelationship, error) {
+	return s.graphStore.GetGraphRelationship(ctx, relationshipID)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/auth"
+	"lk/datafoundation/crud-api/pkg/tlsconfig"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// testCA issues certificates for the tests
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pem         []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testCA{certificate: certificate, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a certificate and key signed by the CA as PEM
+func (ca *testCA) issue(t *testing.T, subject pkix.Name, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      subject,
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, dir string, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func TestMutualTLSAuthorization(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, pkix.Name{CommonName: "crud-api"}, x509.ExtKeyUsageServerAuth)
+	tlsConfig := config.ServerTLSConfig{
+		CertFile:     writeFile(t, dir, "server.crt", serverCert),
+		KeyFile:      writeFile(t, dir, "server.key", serverKey),
+		ClientCAFile: writeFile(t, dir, "ca.crt", ca.pem),
+	}
+	serverTLS, err := tlsconfig.Server(tlsConfig)
+	assert.NoError(t, err)
+
+	policy := writeFile(t, dir, "policy.yaml", []byte(`
+rules:
+  - name: public-read
+    effect: allow
+    roles: [public]
+    access: read
+  - name: organisation-editors
+    effect: allow
+    roles: [editor]
+    kinds: [Organisation]
+`))
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	guard, err := auth.New(config.AuthConfig{Methods: []string{"mtls"}, PolicyFile: policy}, server)
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := newGRPCServer(guard, grpc.Creds(credentials.NewTLS(serverTLS)))
+	pb.RegisterCrudServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	// client connects with a certificate for the common name and organizational unit
+	client := func(commonName string, unit string) pb.CrudServiceClient {
+		certPEM, keyPEM := ca.issue(t, pkix.Name{CommonName: commonName, OrganizationalUnit: []string{unit}}, x509.ExtKeyUsageClientAuth)
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		assert.NoError(t, err)
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.certificate)
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			RootCAs:      roots,
+		})))
+		assert.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return pb.NewCrudServiceClient(conn)
+	}
+	ctx := context.Background()
+	editor := client("ingest-job", "editor")
+	reader := client("dashboard", "public")
+
+	_, err = editor.CreateEntity(ctx, newEntity(t, "org-1", "Organisation", "Org One", "2024-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+	_, err = editor.CreateEntity(ctx, newEntity(t, "person-1", "Person", "Person One", "2024-01-01T00:00:00Z"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	read, err := reader.ReadEntity(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "org-1"}, Output: []string{"metadata"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "org-1", read.Id)
+
+	// Public read-only consumers cannot update or delete
+	_, err = reader.UpdateEntity(ctx, &pb.UpdateEntityRequest{Id: "org-1", Entity: &pb.Entity{Id: "org-1"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = reader.DeleteEntity(ctx, &pb.EntityId{Id: "org-1"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Editors are restricted by the stored kind of the entity they delete
+	_, err = editor.DeleteEntity(ctx, &pb.EntityId{Id: "org-1"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"lk/datafoundation/crud-api/db/config"
+	"lk/datafoundation/crud-api/pkg/tlsconfig"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadConfig loads the configuration file and the environment, applies the storage override
+// given on the command line and validates the result
+func loadConfig(path string, storage string) (*config.Config, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if storage != "" {
+		cfg.Storage = storage
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// runConfigCommand runs the config subcommand and returns the exit code.
+// "config check" prints the effective configuration with secrets masked and reports every validation error.
+func runConfigCommand(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "check" {
+		fmt.Fprintln(stderr, "usage: crud-service config check [--config path] [--storage backend]")
+		return 2
+	}
+
+	flags := flag.NewFlagSet("config check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", os.Getenv("CRUD_CONFIG"), "path to a YAML or TOML configuration file")
+	storage := flags.String("storage", "", "storage backend overriding the configuration")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "configuration could not be loaded: %v\n", err)
+		return 1
+	}
+	if *storage != "" {
+		cfg.Storage = *storage
+	}
+
+	effective, err := yaml.Marshal(cfg.Masked())
+	if err != nil {
+		fmt.Fprintf(stderr, "configuration could not be printed: %v\n", err)
+		return 1
+	}
+	stdout.Write(effective)
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(stderr, "configuration is invalid:\n%v\n", err)
+		return 1
+	}
+	// The certificates are only loaded once the settings are known to be consistent
+	if err := tlsconfig.Validate(cfg); err != nil {
+		fmt.Fprintf(stderr, "TLS configuration is invalid:\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintln(stderr, "configuration is valid")
+	return 0
+}
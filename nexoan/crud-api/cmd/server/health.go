@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	dbcommons "lk/datafoundation/crud-api/commons/db"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthMonitor publishes the status of the service through the standard gRPC health service.
+// Every store is reported under its own name and the service as a whole, both as "" and under
+// the CrudService name, is only SERVING while all stores are reachable.
+type healthMonitor struct {
+	repos    *dbcommons.Repositories
+	server   *health.Server
+	interval time.Duration
+	statuses map[string]healthpb.HealthCheckResponse_ServingStatus
+}
+
+// newHealthMonitor creates a monitor that reports NOT_SERVING until the first successful check
+func newHealthMonitor(repos *dbcommons.Repositories, interval time.Duration) *healthMonitor {
+	m := &healthMonitor{
+		repos:    repos,
+		server:   health.NewServer(),
+		interval: interval,
+		statuses: make(map[string]healthpb.HealthCheckResponse_ServingStatus),
+	}
+	for _, service := range m.serviceNames() {
+		m.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return m
+}
+
+// serviceNames returns every service name reported by the monitor
+func (m *healthMonitor) serviceNames() []string {
+	return []string{
+		"",
+		pb.CrudService_ServiceDesc.ServiceName,
+		dbcommons.GraphStoreName,
+		dbcommons.MetadataStoreName,
+		dbcommons.TabularStoreName,
+		dbcommons.BlobStoreName,
+	}
+}
+
+// register adds the health service to a gRPC server
+func (m *healthMonitor) register(grpcServer *grpc.Server) {
+	healthpb.RegisterHealthServer(grpcServer, m.server)
+}
+
+// check pings every store once and updates the published statuses, it returns whether the service is ready
+func (m *healthMonitor) check(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	results := m.repos.Ping(ctx)
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ready := true
+	for _, name := range names {
+		status := healthpb.HealthCheckResponse_SERVING
+		if err := results[name]; err != nil {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+			ready = false
+			if m.statuses[name] != status {
+				slog.WarnContext(ctx, "Store is unreachable", "store", name, "error", err)
+			}
+		} else if m.statuses[name] != status {
+			slog.InfoContext(ctx, "Store is reachable", "store", name)
+		}
+		m.setStatus(name, status)
+	}
+
+	overall := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready {
+		overall = healthpb.HealthCheckResponse_SERVING
+	}
+	if m.statuses[""] != overall {
+		slog.InfoContext(ctx, "Service status changed", "status", overall.String())
+	}
+	m.setStatus("", overall)
+	m.setStatus(pb.CrudService_ServiceDesc.ServiceName, overall)
+	return ready
+}
+
+// setStatus records and publishes the status of a service
+func (m *healthMonitor) setStatus(service string, status healthpb.HealthCheckResponse_ServingStatus) {
+	m.statuses[service] = status
+	m.server.SetServingStatus(service, status)
+}
+
+// run checks the stores immediately and then at every interval until ctx is cancelled
+func (m *healthMonitor) run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// shutdown reports every service as NOT_SERVING so that clients stop sending new requests.
+// Later checks no longer change the published statuses.
+func (m *healthMonitor) shutdown() {
+	m.server.Shutdown()
+}
+
+// gracefulStop stops accepting new RPCs and waits for the in-flight ones to finish.
+// If they do not finish within timeout the remaining connections are closed.
+func gracefulStop(grpcServer *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		slog.Info("All in-flight RPCs finished")
+	case <-time.After(timeout):
+		slog.Warn("In-flight RPCs did not finish in time, closing remaining connections", "timeout", timeout)
+		grpcServer.Stop()
+	}
+}
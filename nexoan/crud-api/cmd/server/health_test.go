@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	dbcommons "lk/datafoundation/crud-api/commons/db"
+	"lk/datafoundation/crud-api/db/config"
+	memoryrepository "lk/datafoundation/crud-api/db/repository/memory"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// unreachableTabularStore is an in-memory tabular store whose database cannot be reached
+type unreachableTabularStore struct {
+	*memoryrepository.TabularStore
+	err error
+}
+
+func (s *unreachableTabularStore) Ping(ctx context.Context) error {
+	return s.err
+}
+
+// startHealthServer serves the health service of the monitor and returns a client connected to it
+func startHealthServer(t *testing.T, monitor *healthMonitor) (*grpc.Server, healthpb.HealthClient) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	monitor.register(grpcServer)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return grpcServer, healthpb.NewHealthClient(conn)
+}
+
+// servingStatus returns the status reported by the health service
+func servingStatus(t *testing.T, client healthpb.HealthClient, service string) healthpb.HealthCheckResponse_ServingStatus {
+	response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	assert.NoError(t, err)
+	return response.GetStatus()
+}
+
+func TestHealthMonitor(t *testing.T) {
+	ctx := context.Background()
+	tabular := &unreachableTabularStore{TabularStore: memoryrepository.NewTabularStore(), err: errors.New("connection refused")}
+	repos := dbcommons.NewMemoryRepositories(config.RelationshipIntegrityConfig{})
+	repos.Tabular = tabular
+
+	monitor := newHealthMonitor(repos, time.Second)
+	grpcServer, client := startHealthServer(t, monitor)
+
+	// The service is not ready before the stores have been checked
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, dbcommons.GraphStoreName))
+
+	assert.False(t, monitor.check(ctx))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, dbcommons.TabularStoreName))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, client, dbcommons.GraphStoreName))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, client, dbcommons.MetadataStoreName))
+
+	// Readiness flips once every store is reachable
+	tabular.err = nil
+	assert.True(t, monitor.check(ctx))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, client, pb.CrudService_ServiceDesc.ServiceName))
+
+	// After shutdown every service stays NOT_SERVING
+	monitor.shutdown()
+	monitor.check(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, client, dbcommons.GraphStoreName))
+
+	gracefulStop(grpcServer, time.Second)
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,911 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"net"
+	"testing"
+
+	dbcommons "lk/datafoundation/crud-api/commons/db"
+	"lk/datafoundation/crud-api/db/config"
+	"lk/datafoundation/crud-api/engine"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/auth"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newMemoryServer creates a server backed by in-memory stores
+func newMemoryServer(t *testing.T, rules config.RelationshipIntegrityConfig) *Server {
+	repos := dbcommons.NewMemoryRepositories(rules)
+	server, err := NewServer(context.Background(), repos)
+	assert.NoError(t, err)
+	return server
+}
+
+// newEntity creates an entity request with the given kind and name
+func newEntity(t *testing.T, id string, major string, name string, created string) *pb.Entity {
+	nameValue, err := anypb.New(wrapperspb.String(name))
+	assert.NoError(t, err)
+	return &pb.Entity{
+		Id:      id,
+		Kind:    &pb.Kind{Major: major, Minor: "Test"},
+		Name:    &pb.TimeBasedValue{StartTime: created, Value: nameValue},
+		Created: created,
+	}
+}
+
+func TestMemoryServerEntities(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	person := newEntity(t, "person-1", "Person", "Alice", "2020-01-01T00:00:00Z")
+	owner, err := anypb.New(wrapperspb.String("registry"))
+	assert.NoError(t, err)
+	person.Metadata = map[string]*anypb.Any{"source": owner}
+	_, err = server.CreateEntity(ctx, person)
+	assert.NoError(t, err)
+
+	org := newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z")
+	org.Relationships = map[string]*pb.Relationship{
+		"rel-1": {Id: "rel-1", Name: "EMPLOYS", RelatedEntityId: "person-1", StartTime: "2020-06-01T00:00:00Z"},
+	}
+	_, err = server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	read, err := server.ReadEntity(ctx, &pb.ReadEntityRequest{
+		Entity: &pb.Entity{Id: "person-1"},
+		Output: []string{"metadata", "relationships"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Person", read.Kind.Major)
+	assert.Contains(t, read.Metadata, "source")
+	assert.Equal(t, "org-1", read.Relationships["rel-1"].RelatedEntityId)
+	assert.Equal(t, "INCOMING", read.Relationships["rel-1"].Direction)
+
+	entities, err := server.ReadEntities(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "Organisation"}}})
+	assert.NoError(t, err)
+	assert.Len(t, entities.Entities, 1)
+	assert.Equal(t, "org-1", entities.Entities[0].Id)
+}
+
+func TestMemoryServerMetadataHistory(t *testing.T) {
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{Subject: "ingest-job"})
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	assert.NoError(t, server.kindRegistry.Load([]*pb.KindSchema{{
+		Major:    "Organisation",
+		Metadata: []*pb.MetadataFieldSchema{{Key: "gazette", Type: "string", Required: true}},
+	}}))
+
+	stringValue := func(value string) *anypb.Any {
+		message, err := anypb.New(wrapperspb.String(value))
+		assert.NoError(t, err)
+		return message
+	}
+	readString := func(value *anypb.Any) string {
+		var message wrapperspb.StringValue
+		assert.NoError(t, value.UnmarshalTo(&message))
+		return message.Value
+	}
+
+	ministry := newEntity(t, "ministry-1", "Organisation", "Ministry of Health", "2019-12-01T00:00:00Z")
+	ministry.Metadata = map[string]*anypb.Any{"gazette": stringValue("2019/1"), "minister": stringValue("A"), "secretary": stringValue("C")}
+	_, err := server.CreateEntity(ctx, ministry)
+	assert.NoError(t, err)
+
+	// An update only changes the keys it names, from the time it is valid
+	updated, err := server.UpdateEntity(ctx, &pb.UpdateEntityRequest{
+		Id:        "ministry-1",
+		Entity:    &pb.Entity{Metadata: map[string]*anypb.Any{"minister": stringValue("B")}},
+		ValidFrom: "2022-07-22T00:00:00Z",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, updated.Metadata, 3)
+	assert.Equal(t, "B", readString(updated.Metadata["minister"]))
+
+	_, err = server.UpdateEntity(ctx, &pb.UpdateEntityRequest{Id: "ministry-1", RemoveMetadata: []string{"secretary"}, ValidFrom: "2023-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+
+	read := func(activeAt string) map[string]*anypb.Any {
+		entity, err := server.ReadEntity(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "ministry-1"}, Output: []string{"metadata"}, ActiveAt: activeAt})
+		assert.NoError(t, err)
+		return entity.Metadata
+	}
+	assert.Equal(t, "A", readString(read("2020-01-01T00:00:00Z")["minister"]))
+	assert.Contains(t, read("2022-12-31T00:00:00Z"), "secretary")
+	assert.NotContains(t, read(""), "secretary")
+	assert.Empty(t, read("2019-01-01T00:00:00Z"))
+
+	history, err := server.ReadMetadataHistory(ctx, &pb.MetadataHistoryRequest{EntityId: "ministry-1", Key: "minister"})
+	assert.NoError(t, err)
+	assert.Len(t, history.Versions, 2)
+	assert.Equal(t, "2019-12-01T00:00:00Z", history.Versions[0].ValidFrom)
+	assert.Equal(t, "2022-07-22T00:00:00Z", history.Versions[0].ValidTo)
+	assert.Equal(t, "ingest-job", history.Versions[1].RecordedBy)
+	assert.NotEmpty(t, history.Versions[1].RecordedAt)
+	history, err = server.ReadMetadataHistory(ctx, &pb.MetadataHistoryRequest{EntityId: "ministry-1"})
+	assert.NoError(t, err)
+	assert.Len(t, history.Versions, 4)
+
+	// Changes before the current or last value, invalid times and removing required keys are rejected
+	for _, req := range []*pb.UpdateEntityRequest{
+		{Id: "ministry-1", Entity: &pb.Entity{Metadata: map[string]*anypb.Any{"minister": stringValue("D")}}, ValidFrom: "2021-01-01T00:00:00Z"},
+		{Id: "ministry-1", Entity: &pb.Entity{Metadata: map[string]*anypb.Any{"secretary": stringValue("D")}}, ValidFrom: "2022-12-31T00:00:00Z"},
+		{Id: "ministry-1", Entity: &pb.Entity{Metadata: map[string]*anypb.Any{"minister": stringValue("D")}}, ValidFrom: "22 July 2022"},
+		{Id: "ministry-1", RemoveMetadata: []string{"gazette"}},
+	} {
+		_, err = server.UpdateEntity(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+	_, err = server.ReadEntity(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "ministry-1"}, Output: []string{"metadata"}, ActiveAt: "yesterday"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// An entity whose creation time or metadata cannot be stored is rejected before any store is written
+	badCreated := newEntity(t, "ministry-2", "Organisation", "Ministry of Finance", "1 January 2020")
+	badCreated.Metadata = map[string]*anypb.Any{"gazette": stringValue("2020/1")}
+	badMetadata := newEntity(t, "ministry-2", "Organisation", "Ministry of Finance", "2020-01-01T00:00:00Z")
+	badMetadata.Metadata = map[string]*anypb.Any{"gazette": stringValue("2020/1"), "": stringValue("empty")}
+	for _, entity := range []*pb.Entity{badCreated, badMetadata} {
+		_, err = server.CreateEntity(ctx, entity)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), entity.String())
+	}
+	entities, err := server.ReadEntities(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "Organisation"}}})
+	assert.NoError(t, err)
+	assert.Len(t, entities.Entities, 1)
+
+	_, err = server.ReadMetadataHistory(ctx, &pb.MetadataHistoryRequest{EntityId: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.ReadMetadataHistory(ctx, &pb.MetadataHistoryRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMemoryServerTabularAttributes(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": []interface{}{"year", "revenue"},
+		"rows": []interface{}{
+			[]interface{}{2023, 1.5},
+			[]interface{}{2024, 2.5},
+		},
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+
+	org := newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z")
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"finances": {Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: value}}},
+	}
+	_, err = server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	data, err := server.tabularStore.GetData(ctx, "attr_org_2d1__finances", map[string]interface{}{"year": 2024})
+	assert.NoError(t, err)
+	var result structpb.Struct
+	assert.NoError(t, data.UnmarshalTo(&result))
+	assert.Contains(t, result.Fields["data"].GetStringValue(), "2.5")
+
+	// The inferred schema of the attribute is returned as JSON Schema
+	described, err := server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1", Name: "finances"})
+	assert.NoError(t, err)
+	assert.Equal(t, "tabular", described.StorageType)
+	declared, err := schema.ParseJSONSchema([]byte(described.JsonSchema))
+	assert.NoError(t, err)
+	assert.Equal(t, typeinference.IntType, declared.Fields["year"].TypeInfo.Type)
+	assert.Equal(t, typeinference.FloatType, declared.Fields["revenue"].TypeInfo.Type)
+
+	_, err = server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1", Name: "staff"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Column types are inferred from every row, mixed values are widened and nulls make a column nullable
+	update := func(name string, value *pb.TimeBasedValue) error {
+		_, err := server.UpdateEntity(ctx, &pb.UpdateEntityRequest{
+			Id:     "org-1",
+			Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{name: {Values: []*pb.TimeBasedValue{value}}}},
+		})
+		return err
+	}
+	assert.NoError(t, update("staff", newTable(t, []interface{}{"grade", "note"}, []interface{}{1, nil}, []interface{}{1.5, "on leave"})))
+	described, err = server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1", Name: "staff"})
+	assert.NoError(t, err)
+	staff, err := schema.ParseJSONSchema([]byte(described.JsonSchema))
+	assert.NoError(t, err)
+	assert.Equal(t, typeinference.FloatType, staff.Fields["grade"].TypeInfo.Type)
+	assert.False(t, staff.Fields["grade"].TypeInfo.IsNullable)
+	assert.Equal(t, typeinference.StringType, staff.Fields["note"].TypeInfo.Type)
+	assert.True(t, staff.Fields["note"].TypeInfo.IsNullable)
+
+	// Cells that cannot be stored in a column are reported with their rows
+	err = update("tags", newTable(t, []interface{}{"tag"}, []interface{}{"core"}, []interface{}{[]interface{}{"a", "b"}}))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "column tag holds lists or objects in rows 1")
+}
+
+func TestMemoryServerTypedValues(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	typed := func(message proto.Message) *pb.TimeBasedValueList {
+		value, err := anypb.New(message)
+		assert.NoError(t, err)
+		return &pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: value}}}
+	}
+	row, err := structpb.NewList([]interface{}{2024, 1.5})
+	assert.NoError(t, err)
+	// A document whose keys look like a table is routed by its message type
+	document, err := structpb.NewStruct(map[string]interface{}{"columns": []interface{}{"a"}, "rows": []interface{}{}})
+	assert.NoError(t, err)
+
+	node, err := structpb.NewStruct(map[string]interface{}{"id": "hq"})
+	assert.NoError(t, err)
+	edge, err := structpb.NewStruct(map[string]interface{}{"source": "hq", "target": "hq"})
+	assert.NoError(t, err)
+
+	org := newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z")
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"finances":  typed(&pb.TabularValue{Columns: []string{"year", "revenue"}, Rows: []*structpb.ListValue{row}}),
+		"layout":    typed(&pb.DocumentValue{Document: document}),
+		"network":   typed(&pb.GraphValue{Nodes: []*structpb.Struct{node}, Edges: []*structpb.Struct{edge}}),
+		"headcount": typed(&pb.ScalarValue{Value: structpb.NewNumberValue(42)}),
+		"logo":      typed(&pb.BlobValue{Data: []byte("<svg></svg>"), ContentType: "image/svg+xml"}),
+	}
+	_, err = server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	data, err := server.tabularStore.GetData(ctx, "attr_org_2d1__finances", nil)
+	assert.NoError(t, err)
+	var result structpb.Struct
+	assert.NoError(t, data.UnmarshalTo(&result))
+	assert.Contains(t, result.Fields["data"].GetStringValue(), "1.5")
+	_, err = server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1", Name: "layout"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Every typed value is read back, scalars, documents and graphs in the layout they are stored in
+	read, err := server.ReadEntity(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "org-1", Attributes: org.Attributes}, Output: []string{"attributes"}})
+	assert.NoError(t, err)
+	readValue := func(name string) *structpb.Struct {
+		values := read.Attributes[name].GetValues()
+		if !assert.Len(t, values, 1, name) {
+			return nil
+		}
+		assert.Equal(t, "2024-01-01T00:00:00Z", values[0].StartTime, name)
+		var value structpb.Struct
+		assert.NoError(t, values[0].Value.UnmarshalTo(&value), name)
+		return &value
+	}
+	assert.Contains(t, read.Attributes["finances"].GetValues()[0].GetValue().String(), "1.5")
+	assert.True(t, proto.Equal(document, readValue("layout")))
+	assert.Equal(t, float64(42), readValue("headcount").Fields["value"].GetNumberValue())
+	network := readValue("network")
+	assert.Equal(t, "hq", network.Fields["nodes"].GetListValue().GetValues()[0].GetStructValue().Fields["id"].GetStringValue())
+	assert.Equal(t, "hq", network.Fields["edges"].GetListValue().GetValues()[0].GetStructValue().Fields["target"].GetStringValue())
+	var logo pb.BlobValue
+	assert.NoError(t, read.Attributes["logo"].GetValues()[0].GetValue().UnmarshalTo(&logo))
+	assert.Equal(t, "image/svg+xml", logo.ContentType)
+
+	// A later value of a scalar is kept next to the earlier one
+	headcount := typed(&pb.ScalarValue{Value: structpb.NewNumberValue(45)})
+	headcount.Values[0].StartTime = "2025-01-01T00:00:00Z"
+	_, err = server.UpdateEntity(ctx, &pb.UpdateEntityRequest{Id: "org-1", Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{"headcount": headcount}}})
+	assert.NoError(t, err)
+	read, err = server.ReadEntity(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{"headcount": headcount}}, Output: []string{"attributes"}})
+	assert.NoError(t, err)
+	values := read.Attributes["headcount"].GetValues()
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, "2025-01-01T00:00:00Z", values[1].StartTime)
+		var later structpb.Struct
+		assert.NoError(t, values[1].Value.UnmarshalTo(&later))
+		assert.Equal(t, float64(45), later.Fields["value"].GetNumberValue())
+	}
+}
+
+// newGeometry creates an attribute value holding a GeoJSON geometry
+func newGeometry(t *testing.T, geoJSON string) *pb.TimeBasedValueList {
+	geometry := &structpb.Struct{}
+	assert.NoError(t, geometry.UnmarshalJSON([]byte(geoJSON)))
+	value, err := anypb.New(geometry)
+	assert.NoError(t, err)
+	return &pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: value}}}
+}
+
+func TestMemoryServerGeospatialAttributes(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	boundaries := map[string]string{
+		"colombo": `{"type": "Polygon", "coordinates": [[[79.80, 6.85], [79.95, 6.85], [79.95, 7.00], [79.80, 7.00], [79.80, 6.85]]]}`,
+		"gampaha": `{"type": "Polygon", "coordinates": [[[79.90, 7.00], [80.20, 7.00], [80.20, 7.30], [79.90, 7.30], [79.90, 7.00]]]}`,
+		"kandy":   `{"type": "Feature", "geometry": {"type": "Polygon", "coordinates": [[[80.55, 7.20], [80.75, 7.20], [80.75, 7.40], [80.55, 7.40], [80.55, 7.20]]]}, "properties": {}}`,
+	}
+	for id, boundary := range boundaries {
+		district := newEntity(t, id, "District", id, "2019-01-01T00:00:00Z")
+		district.Attributes = map[string]*pb.TimeBasedValueList{"boundary": newGeometry(t, boundary)}
+		_, err := server.CreateEntity(ctx, district)
+		assert.NoError(t, err)
+	}
+	_, err := server.CreateEntity(ctx, newEntity(t, "ministry-1", "Ministry", "Health", "2019-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+
+	readDistrictsAt := func(filter *pb.SpatialFilter, activeAt string) ([]string, error) {
+		entities, err := server.ReadEntities(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "District"}}, Spatial: filter, ActiveAt: activeAt})
+		if err != nil {
+			return nil, err
+		}
+		var ids []string
+		for _, entity := range entities.Entities {
+			ids = append(ids, entity.Id)
+		}
+		return ids, nil
+	}
+	readDistricts := func(filter *pb.SpatialFilter) ([]string, error) {
+		return readDistrictsAt(filter, "")
+	}
+
+	// The district whose boundary contains the point
+	ids, err := readDistricts(&pb.SpatialFilter{Attribute: "boundary", ContainsPoint: &pb.Position{Longitude: 79.86, Latitude: 6.93}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo"}, ids)
+
+	// The districts whose boundary intersects the box
+	ids, err = readDistricts(&pb.SpatialFilter{Attribute: "boundary", Intersects: &pb.BoundingBox{MinLongitude: 79.9, MinLatitude: 6.9, MaxLongitude: 81, MaxLatitude: 7.3}})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"colombo", "gampaha", "kandy"}, ids)
+
+	ids, err = readDistricts(nil)
+	assert.NoError(t, err)
+	assert.Len(t, ids, 3)
+
+	for _, filter := range []*pb.SpatialFilter{
+		{ContainsPoint: &pb.Position{Longitude: 79.86, Latitude: 6.93}},
+		{Attribute: "boundary"},
+		{Attribute: "boundary", ContainsPoint: &pb.Position{Longitude: 79.86, Latitude: 96.93}},
+		{Attribute: "boundary", Intersects: &pb.BoundingBox{MinLongitude: 81, MaxLongitude: 79}},
+	} {
+		_, err = readDistricts(filter)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", filter)
+	}
+
+	// The geometry is stored under the geospatial storage type and read back as GeoJSON
+	described, err := server.metadataStore.GetMetadata(ctx, "kandy_attr_boundary", "")
+	assert.NoError(t, err)
+	var storageType wrapperspb.StringValue
+	assert.NoError(t, described["storage_type"].UnmarshalTo(&storageType))
+	assert.Equal(t, "geospatial", storageType.Value)
+
+	read, err := server.ReadEntity(ctx, &pb.ReadEntityRequest{
+		Entity: &pb.Entity{Id: "kandy", Attributes: map[string]*pb.TimeBasedValueList{"boundary": newGeometry(t, boundaries["kandy"])}},
+		Output: []string{"attributes"},
+	})
+	assert.NoError(t, err)
+	var geometry structpb.Struct
+	assert.NoError(t, read.Attributes["boundary"].Values[0].Value.UnmarshalTo(&geometry))
+	assert.Equal(t, "Polygon", geometry.Fields["type"].GetStringValue())
+
+	// A later boundary replaces the earlier one from its start time, the earlier one is matched before
+	moved := newGeometry(t, `{"type": "Polygon", "coordinates": [[[79.80, 6.70], [79.95, 6.70], [79.95, 6.80], [79.80, 6.80], [79.80, 6.70]]]}`)
+	moved.Values[0].StartTime = "2025-01-01T00:00:00Z"
+	_, err = server.UpdateEntity(ctx, &pb.UpdateEntityRequest{Id: "colombo", Entity: &pb.Entity{Id: "colombo", Attributes: map[string]*pb.TimeBasedValueList{"boundary": moved}}})
+	assert.NoError(t, err)
+	point := &pb.SpatialFilter{Attribute: "boundary", ContainsPoint: &pb.Position{Longitude: 79.86, Latitude: 6.93}}
+	ids, err = readDistricts(point)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	ids, err = readDistrictsAt(point, "2024-06-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo"}, ids)
+	ids, err = readDistrictsAt(point, "2023-06-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+	_, err = readDistrictsAt(point, "June 2024")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Invalid GeoJSON is rejected
+	invalid := newEntity(t, "matara", "District", "matara", "2019-01-01T00:00:00Z")
+	invalid.Attributes = map[string]*pb.TimeBasedValueList{"boundary": newGeometry(t, `{"type": "Polygon", "coordinates": [[[80.5, 5.9], [80.6, 5.9], [80.6, 6.0]]]}`)}
+	_, err = server.CreateEntity(ctx, invalid)
+	assert.Error(t, err)
+}
+
+// startCrudServer serves the CrudService of server and returns a client connected to it
+func startCrudServer(t *testing.T, server *Server) pb.CrudServiceClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	pb.RegisterCrudServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+	})
+	return pb.NewCrudServiceClient(conn)
+}
+
+// uploadBlob streams content to UploadBlob in chunks of chunkSize bytes
+func uploadBlob(client pb.CrudServiceClient, info *pb.BlobInfo, content []byte, chunkSize int) (*pb.BlobInfo, error) {
+	stream, err := client.UploadBlob(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	chunk := &pb.BlobChunk{Info: info}
+	for len(content) > 0 || chunk.Info != nil {
+		n := min(chunkSize, len(content))
+		chunk.Data = content[:n]
+		content = content[n:]
+		if err := stream.Send(chunk); err != nil {
+			break
+		}
+		chunk = &pb.BlobChunk{}
+	}
+	return stream.CloseAndRecv()
+}
+
+// downloadBlob reads the info and content streamed by DownloadBlob
+func downloadBlob(client pb.CrudServiceClient, entityID, name string) (*pb.BlobInfo, []byte, int, error) {
+	stream, err := client.DownloadBlob(context.Background(), &pb.AttributeId{EntityId: entityID, Name: name})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	var info *pb.BlobInfo
+	var content bytes.Buffer
+	chunks := 0
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return info, content.Bytes(), chunks, nil
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if chunks == 0 {
+			info = chunk.Info
+		}
+		content.Write(chunk.Data)
+		chunks++
+	}
+}
+
+func TestMemoryServerBlobAttributes(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	client := startCrudServer(t, server)
+
+	org := newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z")
+	logo, err := anypb.New(&pb.BlobValue{Data: []byte("<svg></svg>"), ContentType: "image/svg+xml"})
+	assert.NoError(t, err)
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"logo": {Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: logo}}},
+	}
+	_, err = server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	// Blob values sent with the entity are stored like uploaded ones
+	info, content, _, err := downloadBlob(client, "org-1", "logo")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/svg+xml", info.ContentType)
+	assert.Equal(t, []byte("<svg></svg>"), content)
+
+	// The MIME type of an upload is detected when none is given
+	gazette := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte("gazette "), 20000)...)
+	uploaded, err := uploadBlob(client, &pb.BlobInfo{EntityId: "org-1", Name: "gazette", StartTime: "2024-02-01T00:00:00Z"}, gazette, 10000)
+	assert.NoError(t, err)
+	assert.Equal(t, "application/pdf", uploaded.ContentType)
+	assert.Equal(t, int64(len(gazette)), uploaded.Size)
+	assert.Regexp(t, "^sha256:[0-9a-f]{64}$", uploaded.Digest)
+
+	info, content, chunks, err := downloadBlob(client, "org-1", "gazette")
+	assert.NoError(t, err)
+	assert.Equal(t, gazette, content)
+	assert.Equal(t, uploaded.Digest, info.Digest)
+	assert.Equal(t, uploaded.Size, info.Size)
+	assert.Greater(t, chunks, 1)
+
+	// Uploading the same content again keeps its digest
+	again, err := uploadBlob(client, &pb.BlobInfo{EntityId: "org-1", Name: "gazette_copy", ContentType: "application/pdf"}, gazette, 64*1024)
+	assert.NoError(t, err)
+	assert.Equal(t, uploaded.Digest, again.Digest)
+
+	_, err = uploadBlob(client, &pb.BlobInfo{Name: "gazette"}, gazette, 1024)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Content larger than the maximum blob size is rejected
+	limited := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	limited.maxBlobSize = int64(len(gazette))
+	_, err = limited.CreateEntity(ctx, newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+	limitedClient := startCrudServer(t, limited)
+	_, err = uploadBlob(limitedClient, &pb.BlobInfo{EntityId: "org-1", Name: "gazette"}, gazette, 10000)
+	assert.NoError(t, err)
+	_, err = uploadBlob(limitedClient, &pb.BlobInfo{EntityId: "org-1", Name: "gazette_large"}, append(gazette, '\n'), 10000)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	// A rejected upload leaves no attribute behind
+	_, err = limited.ReadRelationship(ctx, &pb.RelationshipId{Id: engine.GenerateAttributeRelationshipID("org-1", "gazette_large")})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = limited.graphStore.ReadGraphEntity(ctx, engine.GenerateAttributeID("org-1", "gazette_large"))
+	assert.Error(t, err)
+	_, _, _, err = downloadBlob(limitedClient, "org-1", "gazette_large")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = uploadBlob(client, &pb.BlobInfo{EntityId: "org-2", Name: "gazette"}, gazette, 1024)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, _, _, err = downloadBlob(client, "org-1", "missing")
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Attributes holding other data cannot receive blob content
+	org2 := newEntity(t, "org-2", "Organisation", "Beta", "2019-01-01T00:00:00Z")
+	org2.Attributes = map[string]*pb.TimeBasedValueList{
+		"staff": {Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"name"}, []interface{}{"Ann"})}},
+	}
+	_, err = server.CreateEntity(ctx, org2)
+	assert.NoError(t, err)
+	_, err = uploadBlob(client, &pb.BlobInfo{EntityId: "org-2", Name: "staff"}, gazette, 1024)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// newTable packs columns and rows as a tabular attribute value
+func newTable(t *testing.T, columns []interface{}, rows ...[]interface{}) *pb.TimeBasedValue {
+	rowValues := make([]interface{}, len(rows))
+	for i, row := range rows {
+		rowValues[i] = row
+	}
+	tabular, err := structpb.NewStruct(map[string]interface{}{"columns": columns, "rows": rowValues})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	return &pb.TimeBasedValue{StartTime: "2024-01-01T00:00:00Z", Value: value}
+}
+
+func TestMemoryServerDeclaredSchemas(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+	declared := `{
+		"type": "array",
+		"x-storage-type": "tabular",
+		"items": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"grade": {"type": "number"},
+				"joined": {"type": "string", "format": "date"}
+			},
+			"required": ["name", "grade"]
+		}
+	}`
+	columns := []interface{}{"name", "grade"}
+
+	// The declared schema overrides the integer that would be inferred from the first rows
+	org := newEntity(t, "org-1", "Organisation", "Acme", "2019-01-01T00:00:00Z")
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"staff": {JsonSchema: declared, Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Alice", 3})}},
+	}
+	_, err := server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	described, err := server.DescribeAttribute(ctx, &pb.AttributeId{EntityId: "org-1", Name: "staff"})
+	assert.NoError(t, err)
+	assert.True(t, described.Declared)
+	stored, err := schema.ParseJSONSchema([]byte(described.JsonSchema))
+	assert.NoError(t, err)
+	assert.Equal(t, typeinference.FloatType, stored.Fields["grade"].TypeInfo.Type)
+	assert.True(t, stored.Fields["joined"].TypeInfo.IsNullable)
+
+	// Later writes are validated against the stored schema
+	update := func(values *pb.TimeBasedValueList) error {
+		_, err := server.UpdateEntity(ctx, &pb.UpdateEntityRequest{
+			Id:     "org-1",
+			Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{"staff": values}},
+		})
+		return err
+	}
+	assert.NoError(t, update(&pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{
+		newTable(t, []interface{}{"name", "grade", "joined"}, []interface{}{"Bob", 4.5, "2024-02-01"}, []interface{}{"Carol", 2, nil}),
+	}}))
+
+	err = update(&pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Dan", "high"})}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "row 0, column grade: expected float")
+	err = update(&pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"name", "grade", "team"}, []interface{}{"Dan", 1, "ops"})}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "column team is not declared")
+
+	// Values rejected by a schema declared in the request are not stored at all
+	err = update(&pb.TimeBasedValueList{JsonSchema: declared, Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"grade"}, []interface{}{1})}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "column name is missing")
+
+	data, err := server.tabularStore.GetData(ctx, "attr_org_2d1__staff", nil)
+	assert.NoError(t, err)
+	var result structpb.Struct
+	assert.NoError(t, data.UnmarshalTo(&result))
+	assert.Contains(t, result.Fields["data"].GetStringValue(), "Carol")
+	assert.NotContains(t, result.Fields["data"].GetStringValue(), "Dan")
+
+	// Schemas cannot be declared once a schema was inferred for the attribute
+	err = update(&pb.TimeBasedValueList{Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Eve", 1})}})
+	assert.NoError(t, err)
+	_, err = server.UpdateEntity(ctx, &pb.UpdateEntityRequest{
+		Id: "org-1",
+		Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{
+			"interns": {Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Frank", 1})}},
+		}},
+	})
+	assert.NoError(t, err)
+	_, err = server.UpdateEntity(ctx, &pb.UpdateEntityRequest{
+		Id: "org-1",
+		Entity: &pb.Entity{Id: "org-1", Attributes: map[string]*pb.TimeBasedValueList{
+			"interns": {JsonSchema: declared, Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Grace", 1})}},
+		}},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	err = update(&pb.TimeBasedValueList{JsonSchema: `{"type": "object"}`, Values: []*pb.TimeBasedValue{newTable(t, columns, []interface{}{"Heidi", 1})}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "declared schemas must describe tabular data")
+}
+
+func TestMemoryServerRelationships(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{SingleValuedTypes: []string{"LIVES_IN"}})
+
+	for _, entity := range []*pb.Entity{
+		newEntity(t, "person-1", "Person", "Alice", "2020-01-01T00:00:00Z"),
+		newEntity(t, "city-1", "City", "Colombo", "1900-01-01T00:00:00Z"),
+		newEntity(t, "city-2", "City", "Kandy", "1900-01-01T00:00:00Z"),
+	} {
+		_, err := server.CreateEntity(ctx, entity)
+		assert.NoError(t, err)
+	}
+
+	created, err := server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId:     "person-1",
+		Relationship: &pb.Relationship{Id: "rel-1", Name: "LIVES_IN", RelatedEntityId: "city-1", StartTime: "2020-01-01T00:00:00Z"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "city-1", created.Relationship.RelatedEntityId)
+
+	// A second active LIVES_IN relationship violates the single valued rule
+	_, err = server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId:     "person-1",
+		Relationship: &pb.Relationship{Id: "rel-2", Name: "LIVES_IN", RelatedEntityId: "city-2", StartTime: "2021-01-01T00:00:00Z"},
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	terminated, err := server.TerminateRelationship(ctx, &pb.TerminateRelationshipRequest{Id: "rel-1", EndTime: "2021-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Equal(t, "2021-01-01T00:00:00Z", terminated.Relationship.EndTime)
+
+	_, err = server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId:     "person-1",
+		Relationship: &pb.Relationship{Id: "rel-2", Name: "LIVES_IN", RelatedEntityId: "city-2", StartTime: "2021-01-01T00:00:00Z"},
+	})
+	assert.NoError(t, err)
+
+	relationships, err := server.ReadRelationships(ctx, &pb.ReadRelationshipsRequest{SourceEntityId: "person-1"})
+	assert.NoError(t, err)
+	assert.Len(t, relationships.Relationships, 2)
+	assert.Equal(t, "rel-1", relationships.Relationships[0].Relationship.Id)
+
+	// Properties that cannot be stored are rejected, unsigned integers above the signed range would wrap around
+	tooLarge, err := anypb.New(wrapperspb.UInt64(math.MaxUint64))
+	assert.NoError(t, err)
+	_, err = server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId: "person-1",
+		Relationship: &pb.Relationship{
+			Id: "rel-3", Name: "VISITED", RelatedEntityId: "city-1", StartTime: "2021-01-01T00:00:00Z",
+			Properties: map[string]*anypb.Any{"visits": tooLarge},
+		},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.DeleteRelationship(ctx, &pb.RelationshipId{Id: "rel-1"})
+	assert.NoError(t, err)
+	_, err = server.ReadRelationship(ctx, &pb.RelationshipId{Id: "rel-1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMemoryServerAttributeRelationships(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	population, err := anypb.New(&pb.ScalarValue{Value: structpb.NewNumberValue(750000)})
+	assert.NoError(t, err)
+	city := newEntity(t, "city-1", "City", "Colombo", "1900-01-01T00:00:00Z")
+	city.Attributes = map[string]*pb.TimeBasedValueList{
+		"population": {Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: population}}},
+	}
+	_, err = server.CreateEntity(ctx, city)
+	assert.NoError(t, err)
+	_, err = server.CreateEntity(ctx, newEntity(t, "city-2", "City", "Kandy", "1900-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+
+	// The engine links the attribute to its entity with an IS_ATTRIBUTE relationship
+	attributeRelationshipID := engine.GenerateAttributeRelationshipID("city-1", "population")
+	attributeRelationship, err := server.ReadRelationship(ctx, &pb.RelationshipId{Id: attributeRelationshipID})
+	assert.NoError(t, err)
+	assert.Equal(t, engine.IS_ATTRIBUTE_RELATIONSHIP, attributeRelationship.Relationship.Name)
+
+	// Clients can neither create IS_ATTRIBUTE relationships nor change the ones of the engine
+	_, err = server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId:     "city-2",
+		Relationship: &pb.Relationship{Id: "rel-1", Name: engine.IS_ATTRIBUTE_RELATIONSHIP, RelatedEntityId: "city-1", StartTime: "2020-01-01T00:00:00Z"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	entity := newEntity(t, "city-3", "City", "Galle", "1900-01-01T00:00:00Z")
+	entity.Relationships = map[string]*pb.Relationship{
+		"rel-2": {Id: "rel-2", Name: engine.IS_ATTRIBUTE_RELATIONSHIP, RelatedEntityId: "city-1", StartTime: "2020-01-01T00:00:00Z"},
+	}
+	_, err = server.CreateEntity(ctx, entity)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.UpdateRelationship(ctx, &pb.EntityRelationship{
+		Relationship: &pb.Relationship{Id: attributeRelationshipID, StartTime: "2020-01-01T00:00:00Z"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.TerminateRelationship(ctx, &pb.TerminateRelationshipRequest{Id: attributeRelationshipID, EndTime: "2025-01-01T00:00:00Z"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = server.DeleteRelationship(ctx, &pb.RelationshipId{Id: attributeRelationshipID})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	unchanged, err := server.ReadRelationship(ctx, &pb.RelationshipId{Id: attributeRelationshipID})
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(attributeRelationship, unchanged))
+	_, err = server.ReadRelationship(ctx, &pb.RelationshipId{Id: "rel-1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMemoryServerIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	// Kind and relationship names that could end a quoted Cypher name are rejected
+	_, err := server.CreateEntity(ctx, newEntity(t, "person-1", "Person`) DETACH DELETE n //", "Alice", "2020-01-01T00:00:00Z"))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	org := newEntity(t, "org-1", "Budget Office", "Treasury", "2019-01-01T00:00:00Z")
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"Budget-2020": {Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"item", "amount"}, []interface{}{"roads", 120})}},
+		"budget_2020": {Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"item", "amount"}, []interface{}{"schools", 80})}},
+	}
+	_, err = server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+	_, err = server.CreateEntity(ctx, newEntity(t, "org-2", "Budget Office", "Audit", "2019-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+
+	_, err = server.CreateRelationship(ctx, &pb.EntityRelationship{
+		EntityId:     "org-1",
+		Relationship: &pb.Relationship{Id: "rel-1", Name: "FUNDS]->() DELETE r //`", RelatedEntityId: "org-2", StartTime: "2020-01-01T00:00:00Z"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Attribute names that differ only in case and punctuation are kept in separate tables
+	for attribute, items := range map[string][2]string{"Budget-2020": {"roads", "schools"}, "budget_2020": {"schools", "roads"}} {
+		tableName, err := server.tabularStore.AttributeTable(ctx, "org-1", attribute)
+		assert.NoError(t, err)
+		data, err := server.tabularStore.GetData(ctx, tableName, nil)
+		assert.NoError(t, err)
+		var result structpb.Struct
+		assert.NoError(t, data.UnmarshalTo(&result))
+		assert.Contains(t, result.Fields["data"].GetStringValue(), items[0])
+		assert.NotContains(t, result.Fields["data"].GetStringValue(), items[1])
+	}
+}
+
+func TestMemoryServerSearch(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	_, err := server.CreateEntity(ctx, newEntity(t, "org-1", "Organisation", "Ministry of Défence", "2019-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+	finance := newEntity(t, "org-2", "Organisation", "Ministry of Finance", "2019-01-01T00:00:00Z")
+	mandate, err := anypb.New(wrapperspb.String("Defence ministry budgets"))
+	assert.NoError(t, err)
+	finance.Metadata = map[string]*anypb.Any{"mandate": mandate}
+	_, err = server.CreateEntity(ctx, finance)
+	assert.NoError(t, err)
+	records := newEntity(t, "org-3", "Organisation", "Records Office", "2019-01-01T00:00:00Z")
+	records.Attributes = map[string]*pb.TimeBasedValueList{
+		"units": {Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"unit", "staff"},
+			[]interface{}{"Defence Ministry archive", 4},
+			[]interface{}{"Land registry", 9})}},
+	}
+	_, err = server.CreateEntity(ctx, records)
+	assert.NoError(t, err)
+	_, err = server.CreateEntity(ctx, newEntity(t, "person-1", "Person", "Defence Ministry Spokesperson", "2019-01-01T00:00:00Z"))
+	assert.NoError(t, err)
+
+	// Names rank above metadata, which ranks above tabular cells, and accents and case are ignored
+	response, err := server.Search(ctx, &pb.SearchRequest{Query: "DEFENCE ministry", Kinds: []*pb.Kind{{Major: "Organisation"}}})
+	assert.NoError(t, err)
+	var ids []string
+	for _, hit := range response.Hits {
+		ids = append(ids, hit.Entity.Id)
+		assert.Equal(t, "Organisation", hit.Entity.Kind.Major)
+		assert.Len(t, hit.Matches, 1)
+	}
+	assert.Equal(t, []string{"org-1", "org-2", "org-3"}, ids)
+	assert.Equal(t, "name", response.Hits[0].Matches[0].Field)
+	assert.Equal(t, "metadata.mandate", response.Hits[1].Matches[0].Field)
+	assert.Equal(t, "Defence ministry budgets", response.Hits[1].Matches[0].Text)
+	assert.Equal(t, "attributes.units.unit", response.Hits[2].Matches[0].Field)
+	assert.Greater(t, response.Hits[0].Score, response.Hits[1].Score)
+	assert.Greater(t, response.Hits[1].Score, response.Hits[2].Score)
+
+	// Without kinds every kind is searched, up to the limit
+	response, err = server.Search(ctx, &pb.SearchRequest{Query: "défence ministry", Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, response.Hits, 2)
+	response, err = server.Search(ctx, &pb.SearchRequest{Query: "spokesperson"})
+	assert.NoError(t, err)
+	assert.Len(t, response.Hits, 1)
+	assert.Equal(t, "person-1", response.Hits[0].Entity.Id)
+
+	// Every term must be a whole word
+	response, err = server.Search(ctx, &pb.SearchRequest{Query: "minis"})
+	assert.NoError(t, err)
+	assert.Empty(t, response.Hits)
+
+	for _, req := range []*pb.SearchRequest{
+		{Query: " - "},
+		{Query: "defence", Limit: 101},
+		{Query: "defence", Limit: -1},
+		{Query: "defence", Kinds: []*pb.Kind{{Minor: "Test"}}},
+	} {
+		_, err = server.Search(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", req)
+	}
+}
+
+func TestMemoryServerAttributeIndexes(t *testing.T) {
+	ctx := context.Background()
+	server := newMemoryServer(t, config.RelationshipIntegrityConfig{})
+
+	org := newEntity(t, "org-1", "Organisation", "Records Office", "2019-01-01T00:00:00Z")
+	org.Attributes = map[string]*pb.TimeBasedValueList{
+		"units": {Values: []*pb.TimeBasedValue{newTable(t, []interface{}{"Unit Name", "staff"},
+			[]interface{}{"Archive", 4},
+			[]interface{}{"Land registry", 9})}},
+	}
+	_, err := server.CreateEntity(ctx, org)
+	assert.NoError(t, err)
+
+	index, err := server.CreateAttributeIndex(ctx, &pb.AttributeIndexRequest{EntityId: "org-1", Name: "units", Columns: []string{"Unit Name", "staff"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "org-1", index.EntityId)
+	assert.Equal(t, "units", index.Name)
+	assert.Equal(t, []string{"Unit Name", "staff"}, index.Columns)
+	assert.NotEmpty(t, index.IndexName)
+
+	// Requesting the same index again returns it
+	again, err := server.CreateAttributeIndex(ctx, &pb.AttributeIndexRequest{EntityId: "org-1", Name: "units", Columns: []string{"Unit Name", "staff"}})
+	assert.NoError(t, err)
+	assert.Equal(t, index.IndexName, again.IndexName)
+
+	_, err = server.CreateAttributeIndex(ctx, &pb.AttributeIndexRequest{EntityId: "org-1", Name: "budget", Columns: []string{"year"}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	for _, req := range []*pb.AttributeIndexRequest{
+		{Name: "units", Columns: []string{"staff"}},
+		{EntityId: "org-1", Name: "units"},
+		{EntityId: "org-1", Name: "units", Columns: []string{"budget"}},
+		{EntityId: "org-1", Name: "units", Columns: []string{"unit_name"}},
+		{EntityId: "org-1", Name: "units", Columns: []string{"staff", "staff"}},
+	} {
+		_, err = server.CreateAttributeIndex(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", req)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/metrics"
+)
+
+// newMetricsMux serves the Prometheus metrics on /metrics. The log level can be read and changed on
+// /debug/loglevel when levelEndpoint is set, the endpoint is not authenticated so it is off by default.
+func newMetricsMux(levelEndpoint bool) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	if levelEndpoint {
+		mux.Handle("/debug/loglevel", logging.LevelHandler())
+	}
+	return mux
+}
+
+// startMetricsServer serves the metrics mux at the given host and port
+func startMetricsServer(host string, port int, levelEndpoint bool) *http.Server {
+	metricsServer := &http.Server{
+		Addr:              net.JoinHostPort(host, strconv.Itoa(port)),
+		Handler:           newMetricsMux(levelEndpoint),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		slog.Info("Serving metrics", "address", metricsServer.Addr)
+		if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server failed", "error", err)
+		}
+	}()
+	return metricsServer
+}
+
+// stopMetricsServer shuts the metrics server down, waiting at most timeout for open scrapes
+func stopMetricsServer(metricsServer *http.Server, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := metricsServer.Shutdown(ctx); err != nil {
+		slog.Error("Error shutting down metrics server", "error", err)
+	}
+}
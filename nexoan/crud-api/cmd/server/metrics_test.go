@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dbcommons "lk/datafoundation/crud-api/commons/db"
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// scrapeMetrics returns the metrics in the Prometheus text format
+func scrapeMetrics(t *testing.T) string {
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestInstrumentedServerMetrics(t *testing.T) {
+	ctx := context.Background()
+	repos := dbcommons.NewMemoryRepositories(config.RelationshipIntegrityConfig{}).Instrument()
+	server, err := NewServer(ctx, repos)
+	assert.NoError(t, err)
+
+	entity := newEntity(t, "metrics-org", "Organisation", "Metrics Org", "2024-01-01T00:00:00Z")
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": []interface{}{"year", "budget"},
+		"rows":    []interface{}{[]interface{}{2024, 1000}},
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	entity.Attributes = map[string]*pb.TimeBasedValueList{
+		"budget": {Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: value}}},
+	}
+	_, err = server.CreateEntity(ctx, entity)
+	assert.NoError(t, err)
+
+	_, _, _, _, err = repos.Graph.GetGraphEntity(ctx, "missing")
+	assert.Error(t, err)
+
+	scraped := scrapeMetrics(t)
+	// The entity and the node of its attribute are both created in the graph
+	assert.Contains(t, scraped, `crud_store_operations_total{method="HandleGraphEntityCreation",result="success",store="memory"} 2`)
+	assert.Contains(t, scraped, `crud_store_operations_total{method="HandleTabularData",result="success",store="memory"} 1`)
+	assert.Contains(t, scraped, `crud_store_operations_total{method="GetGraphEntity",result="error",store="memory"} 1`)
+	assert.Contains(t, scraped, `crud_store_operation_duration_seconds_count{method="HandleGraphEntityCreation",store="memory"} 2`)
+	// Other tests in the package process tabular attributes too
+	assert.Regexp(t, `crud_attributes_processed_total\{operation="create",result="success",storage_type="tabular"\} \d+`, scraped)
+}
+
+func TestMetricsMuxLevelEndpoint(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newMetricsMux(false).ServeHTTP(recorder, httptest.NewRequest("PUT", "/debug/loglevel?level=debug", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	newMetricsMux(true).ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/loglevel", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -2,21 +2,45 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
 
-	"lk/datafoundation/crud-api/db/config"
+	dbcommons "lk/datafoundation/crud-api/commons/db"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
 
-	mongorepository "lk/datafoundation/crud-api/db/repository/mongo"
-	neo4jrepository "lk/datafoundation/crud-api/db/repository/neo4j"
-	postgres "lk/datafoundation/crud-api/db/repository/postgres"
 	engine "lk/datafoundation/crud-api/engine"
-
+	"lk/datafoundation/crud-api/pkg/auth"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/geo"
+	"lk/datafoundation/crud-api/pkg/kindschema"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/metrics"
+	"lk/datafoundation/crud-api/pkg/relationships"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/search"
+	"lk/datafoundation/crud-api/pkg/storageinference"
+	"lk/datafoundation/crud-api/pkg/tlsconfig"
+	"lk/datafoundation/crud-api/pkg/tracing"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -24,62 +48,209 @@ import (
 // Server implements the CrudService
 type Server struct {
 	pb.UnimplementedCrudServiceServer
-	mongoRepo    *mongorepository.MongoRepository
-	neo4jRepo    *neo4jrepository.Neo4jRepository
-	postgresRepo *postgres.PostgresRepository
+	graphStore    dbcommons.GraphStore
+	metadataStore dbcommons.MetadataStore
+	tabularStore  dbcommons.TabularStore
+	kindRegistry  *kindschema.Registry
+	processor     *engine.EntityAttributeProcessor
+	// maxBlobSize is the largest content in bytes UploadBlob accepts, zero for no limit
+	maxBlobSize int64
+}
+
+// relationshipError reports relationship integrity violations as FailedPrecondition, missing
+// relationships as NotFound and invalid relationship names and properties as InvalidArgument so that
+// clients can tell them apart from a storage failure
+func relationshipError(err error) error {
+	var invalidErr *cypher.InvalidIdentifierError
+	if errors.As(err, &invalidErr) {
+		return status.Error(codes.InvalidArgument, invalidErr.Error())
+	}
+	var propertyErr *relationships.InvalidPropertyError
+	if errors.As(err, &propertyErr) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var reservedErr *relationships.ReservedNameError
+	if errors.As(err, &reservedErr) {
+		return status.Error(codes.InvalidArgument, reservedErr.Error())
+	}
+	var integrityErr *relationships.IntegrityError
+	if errors.As(err, &integrityErr) {
+		return status.Error(codes.FailedPrecondition, integrityErr.Error())
+	}
+	var notFoundErr *relationships.NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return status.Error(codes.NotFound, notFoundErr.Error())
+	}
+	return err
+}
+
+// checkRelationshipName rejects relationship names reserved for the relationships the engine creates
+// internally, such as the IS_ATTRIBUTE links of attributes
+func (s *Server) checkRelationshipName(relationshipID string, name string) error {
+	if s.graphStore.RelationshipTypes().IsExempt(name) {
+		return relationshipError(&relationships.ReservedNameError{RelationshipID: relationshipID, Name: name})
+	}
+	return nil
+}
+
+// kindError reports kind schema violations as InvalidArgument
+func kindError(err error) error {
+	var validationErr *kindschema.ValidationError
+	if errors.As(err, &validationErr) {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+	return err
+}
+
+// identifierError reports kind and relationship names that cannot be stored as InvalidArgument
+func identifierError(err error) error {
+	var invalidErr *cypher.InvalidIdentifierError
+	if errors.As(err, &invalidErr) {
+		return status.Error(codes.InvalidArgument, invalidErr.Error())
+	}
+	return err
+}
+
+// metadataError reports metadata changes that conflict with the history of a key as InvalidArgument
+func metadataError(err error) error {
+	var changeErr *metadatahistory.InvalidChangeError
+	if errors.As(err, &changeErr) {
+		return status.Error(codes.InvalidArgument, changeErr.Error())
+	}
+	return err
+}
+
+// declaredSchemas returns the schemas declared for the attributes of an entity, in the request or by its kind.
+// The values are checked against them before anything is stored.
+func (s *Server) declaredSchemas(entity *pb.Entity, kind *pb.Kind) (map[string]*schema.SchemaInfo, error) {
+	schemas := make(map[string]*schema.SchemaInfo)
+	for name, values := range entity.GetAttributes() {
+		var declared *schema.SchemaInfo
+		var err error
+		if values.GetJsonSchema() != "" {
+			declared, err = schema.ParseDeclaredSchema(values.JsonSchema)
+		} else {
+			declared, err = s.kindRegistry.AttributeSchema(kind.GetMajor(), name)
+		}
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid schema declared for attribute %s: %v", name, err)
+		}
+		if declared == nil {
+			continue
+		}
+		for _, value := range values.GetValues() {
+			if value.GetValue() == nil {
+				continue
+			}
+			unwrapped, err := storageinference.Unwrap(value.Value)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid value for attribute %s: %v", name, err)
+			}
+			if err := schema.ValidateTable(unwrapped, declared); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "attribute %s: %v", name, err)
+			}
+		}
+		schemas[name] = declared
+	}
+	return schemas, nil
+}
+
+// attributeError reports attributes that do not match their declared schema as InvalidArgument
+// and declared schemas that conflict with the stored ones as FailedPrecondition
+func attributeError(results map[string]*engine.Result) error {
+	for name, result := range results {
+		var validationErr *schema.ValidationError
+		if errors.As(result.Error, &validationErr) {
+			return status.Errorf(codes.InvalidArgument, "attribute %s: %v", name, validationErr)
+		}
+		var conflictErr *typeinference.ColumnConflictError
+		if errors.As(result.Error, &conflictErr) {
+			return status.Errorf(codes.InvalidArgument, "attribute %s: %v", name, conflictErr)
+		}
+		if errors.Is(result.Error, engine.ErrSchemaConflict) {
+			return status.Error(codes.FailedPrecondition, result.Error.Error())
+		}
+	}
+	return fmt.Errorf("some attributes failed to process")
 }
 
 // CreateEntity handles entity creation with relationships, metadata and attributes
 func (s *Server) CreateEntity(ctx context.Context, req *pb.Entity) (*pb.Entity, error) {
-	log.Printf("Creating Entity: %s", req.Id)
+	logger := logging.FromContext(ctx).With("entity_id", req.Id)
+	logger.InfoContext(ctx, "Creating entity")
+
+	// Validate the entity against its kind schema
+	if err := s.kindRegistry.Validate(req, req.Kind, true); err != nil {
+		logger.WarnContext(ctx, "Entity does not match its kind", "error", err)
+		return nil, kindError(err)
+	}
+	schemas, err := s.declaredSchemas(req, req.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the creation time and the metadata before any store is written, so that a rejected entity
+	// leaves nothing behind
+	if req.Created != "" {
+		if _, err := metadatahistory.ParseTime(req.Created); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "created %q is not an RFC3339 timestamp", req.Created)
+		}
+	}
+	metadataChange := &metadatahistory.Change{
+		Set:        req.Metadata,
+		ValidFrom:  req.Created,
+		RecordedBy: auth.SubjectFromContext(ctx),
+	}
+	if _, err := metadatahistory.Apply(nil, metadataChange); err != nil {
+		logger.WarnContext(ctx, "Entity metadata cannot be stored", "error", err)
+		return nil, metadataError(err)
+	}
 
 	// Validate required fields for Neo4j entity creation
-	success, err := s.neo4jRepo.HandleGraphEntityCreation(ctx, req)
+	success, err := s.graphStore.HandleGraphEntityCreation(ctx, req)
 	if !success {
-		log.Printf("[server.CreateEntity] Error saving entity in Neo4j: %v", err)
-		return nil, err
+		logger.ErrorContext(ctx, "Error saving entity in the graph store", "error", err)
+		return nil, identifierError(err)
 	} else {
-		log.Printf("[server.CreateEntity] Successfully saved entity in Neo4j for entity: %s", req.Id)
+		logger.DebugContext(ctx, "Saved entity in the graph store")
 	}
 
 	// Handle relationships
-	err = s.neo4jRepo.HandleGraphRelationshipsCreate(ctx, req)
+	err = s.graphStore.HandleGraphRelationshipsCreate(ctx, req)
 	if err != nil {
-		log.Printf("[server.CreateEntity] Error saving relationships in Neo4j: %v", err)
-		return nil, err
+		logger.ErrorContext(ctx, "Error saving relationships in the graph store", "error", err)
+		return nil, relationshipError(err)
 	} else {
-		log.Printf("[server.CreateEntity] Successfully saved relationships in Neo4j for entity: %s", req.Id)
+		logger.DebugContext(ctx, "Saved relationships in the graph store")
 	}
 
 	// The HandleMetadata function will only process it if it has metadata
 	// If metadata is not provided, a document will not be created in MongoDB
 	// FIXME: https://github.com/LDFLK/nexoan/issues/120
-	err = s.mongoRepo.HandleMetadata(ctx, req.Id, req)
+	err = s.metadataStore.HandleMetadata(ctx, req.Id, metadataChange)
 	if err != nil {
-		log.Printf("[server.CreateEntity] Error saving metadata in MongoDB: %v", err)
-		return nil, err
+		logger.ErrorContext(ctx, "Error saving metadata in the metadata store", "error", err)
+		return nil, metadataError(err)
 	} else {
-		log.Printf("[server.CreateEntity] Successfully saved metadata in MongoDB for entity: %s", req.Id)
+		logger.DebugContext(ctx, "Saved metadata in the metadata store")
 	}
 
 	// Handle attributes
-	processor := engine.NewEntityAttributeProcessor()
-	attributeResults := processor.ProcessEntityAttributes(ctx, req, "create", nil)
+	attributeResults := s.processor.ProcessEntityAttributes(ctx, req, "create", engine.NewCreateOptions(&engine.CreateOptions{Schemas: schemas, RecordedBy: auth.SubjectFromContext(ctx)}))
 
 	// Check if any attributes failed
 	hasErrors := false
 	for attrName, result := range attributeResults {
 		if !result.Success || result.Error != nil {
-			log.Printf("[server.CreateEntity] Error handling attribute %s: %v", attrName, result.Error)
+			logger.ErrorContext(ctx, "Error handling attribute", "attribute", attrName, "error", result.Error)
 			hasErrors = true
 		} else {
-			log.Printf("[server.CreateEntity] Successfully handled attribute %s for entity: %s", attrName, req.Id)
+			logger.DebugContext(ctx, "Handled attribute", "attribute", attrName)
 		}
 	}
 
 	if hasErrors {
-		log.Printf("[server.CreateEntity] Some attributes failed to process")
-		return nil, fmt.Errorf("some attributes failed to process")
+		return nil, attributeError(attributeResults)
 	}
 
 	return req, nil
@@ -87,7 +258,8 @@ func (s *Server) CreateEntity(ctx context.Context, req *pb.Entity) (*pb.Entity,
 
 // ReadEntity retrieves an entity's metadata
 func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb.Entity, error) {
-	log.Printf("Reading Entity: %s with output fields: %v", req.Entity.Id, req.Output)
+	logger := logging.FromContext(ctx).With("entity_id", req.Entity.Id)
+	logger.InfoContext(ctx, "Reading entity", "output", req.Output)
 
 	// Initialize a complete response entity with empty fields
 	response := &pb.Entity{
@@ -102,9 +274,9 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 	}
 
 	// Always fetch basic entity info from Neo4j
-	kind, name, created, terminated, err := s.neo4jRepo.GetGraphEntity(ctx, req.Entity.Id)
+	kind, name, created, terminated, err := s.graphStore.GetGraphEntity(ctx, req.Entity.Id)
 	if err != nil {
-		log.Printf("Error fetching entity info: %v", err)
+		logger.ErrorContext(ctx, "Error fetching entity info", "error", err)
 		return nil, fmt.Errorf("error fetching entity info: %v", err)
 	} else {
 		response.Kind = kind
@@ -115,23 +287,27 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 
 	// If no output fields specified, return the entity with basic info
 	if len(req.Output) == 0 {
-		log.Printf("Returning entity from ReadEntity: %+v", response)
+		logger.DebugContext(ctx, "Returning entity", logging.Payload("entity", response))
 		return response, nil
 	}
 
 	// Process each requested output field
 	for _, field := range req.Output {
-		log.Printf("[DEBUG] Entering switch statement for entity ID: %s", req.Entity.Id)
+		logger.DebugContext(ctx, "Processing output field", "field", field)
 		switch field {
 		case "metadata":
-			log.Printf("[DEBUG] Processing metadata field for entity ID: %s", req.Entity.Id)
-			// Get metadata from MongoDB
-			metadata, err := s.mongoRepo.GetMetadata(ctx, req.Entity.Id)
+			// Get metadata from MongoDB, as of activeAt when it is given
+			if req.ActiveAt != "" {
+				if _, err := metadatahistory.ParseTime(req.ActiveAt); err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "activeAt %q is not an RFC3339 timestamp", req.ActiveAt)
+				}
+			}
+			metadata, err := s.metadataStore.GetMetadata(ctx, req.Entity.Id, req.ActiveAt)
 			if err != nil {
-				log.Printf("Error fetching metadata: %v", err)
+				logger.ErrorContext(ctx, "Error fetching metadata", "error", err)
 				return nil, fmt.Errorf("error fetching metadata: %v", err)
 			} else {
-				log.Printf("[DEBUG] Retrieved metadata: %+v", metadata)
+				logger.DebugContext(ctx, "Retrieved metadata", logging.Payload("metadata", metadata))
 				response.Metadata = metadata
 			}
 
@@ -140,9 +316,9 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 			if req.Entity != nil {
 				if len(req.Entity.Relationships) == 0 {
 					// No filters provided, fetch all relationships for the entity
-					filteredRels, err := s.neo4jRepo.GetFilteredRelationships(ctx, req.Entity.Id, "", "", "", "", "", "", req.ActiveAt)
+					filteredRels, err := s.graphStore.GetFilteredRelationships(ctx, req.Entity.Id, "", "", "", "", "", "", nil, req.ActiveAt)
 					if err != nil {
-						log.Printf("Error fetching related entity IDs for entity %s: %v", req.Entity.Id, err)
+						logger.ErrorContext(ctx, "Error fetching related entity IDs", "error", err)
 						return nil, fmt.Errorf("error fetching related entity IDs: %v", err)
 					} else {
 						for id, relationship := range filteredRels {
@@ -152,10 +328,10 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 				} else {
 					// Call GetFilteredRelationships for each relationship
 					for _, rel := range req.Entity.Relationships {
-						log.Printf("Fetching related entity IDs for entity %s with relationship %s and start time %s", req.Entity.Id, rel.Name, rel.StartTime)
-						filteredRels, err := s.neo4jRepo.GetFilteredRelationships(ctx, req.Entity.Id, rel.Id, rel.Name, rel.RelatedEntityId, rel.StartTime, rel.EndTime, rel.Direction, req.ActiveAt)
+						logger.DebugContext(ctx, "Fetching related entity IDs", "relationship", rel.Name, "start_time", rel.StartTime)
+						filteredRels, err := s.graphStore.GetFilteredRelationships(ctx, req.Entity.Id, rel.Id, rel.Name, rel.RelatedEntityId, rel.StartTime, rel.EndTime, rel.Direction, rel.Properties, req.ActiveAt)
 						if err != nil {
-							log.Printf("Error fetching related entity IDs for entity %s: %v", req.Entity.Id, err)
+							logger.ErrorContext(ctx, "Error fetching related entity IDs", "relationship", rel.Name, "error", err)
 							return nil, fmt.Errorf("error fetching related entity IDs: %v", err)
 						}
 
@@ -170,40 +346,35 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 			}
 
 		case "attributes":
-			log.Printf("Processing attributes for entity: %s", req.Entity.Id)
-
-			// For now, create a minimal entity with test attributes to demonstrate the conversion
-
-			log.Printf("[server.ReadEntity] Processing attributes for entity: %s, attributes: %+v", req.Entity.Id, req.Entity.Attributes)
+			logger.DebugContext(ctx, "Processing attributes", logging.Payload("attributes", req.Entity.Attributes))
 
 			// Use the EntityAttributeProcessor to read and process attributes
-			processor := engine.NewEntityAttributeProcessor()
+			processor := s.processor
 
 			// Extract fields from the request attributes based on storage type
 			fields := extractFieldsFromAttributes(req.Entity.Attributes)
-			log.Printf("Extracted fields from attributes: %v", fields)
+			logger.DebugContext(ctx, "Extracted fields from attributes", "fields", fields)
 
 			readOptions := engine.NewReadOptions(make(map[string]interface{}), fields...)
 
 			// Process the entity with attributes to get the results map
 			attributeResults := processor.ProcessEntityAttributes(ctx, req.Entity, "read", readOptions)
 
-			log.Printf("[server.ReadEntity] Successfully processed attributes for entity: %s, results: %+v", req.Entity.Id, attributeResults)
-
 			// Convert the results map back to TimeBasedValueList and attach to response.Attributes
 			for attrName, result := range attributeResults {
-				log.Printf("[server.ReadEntity] Successfully processed attribute %s for entity: %s, result: %+v", attrName, req.Entity.Id, result)
+				logger.DebugContext(ctx, "Processed attribute", "attribute", attrName, "success", result.Success, logging.Payload("result", result.Data))
 				if result.Success && result.Data != nil {
 					// Convert the result data back to TimeBasedValue format
-					if timeBasedValue, ok := result.Data.(*pb.TimeBasedValue); ok {
+					if timeBasedValues, ok := result.Data.(*pb.TimeBasedValueList); ok {
+						// Scalar, document and graph attributes return every value with its start and end time
+						response.Attributes[attrName] = timeBasedValues
+					} else if timeBasedValue, ok := result.Data.(*pb.TimeBasedValue); ok {
 						// If the data is already in TimeBasedValue format, use it directly
-						log.Printf("[server.ReadEntity] Successfully processed attribute %s for entity: %s", attrName, req.Entity.Id)
 						response.Attributes[attrName] = &pb.TimeBasedValueList{
 							Values: []*pb.TimeBasedValue{timeBasedValue},
 						}
 					} else {
 						// Convert other data types to TimeBasedValue format
-						log.Printf("[server.ReadEntity] Successfully processed attribute %s for entity: %s", attrName, req.Entity.Id)
 						response.Attributes[attrName] = &pb.TimeBasedValueList{
 							Values: []*pb.TimeBasedValue{
 								{
@@ -221,79 +392,124 @@ func (s *Server) ReadEntity(ctx context.Context, req *pb.ReadEntityRequest) (*pb
 			}
 
 		default:
-			log.Printf("Unknown output field requested: %s", field)
+			logger.WarnContext(ctx, "Unknown output field requested", "field", field)
 			return nil, fmt.Errorf("unknown output field requested: %s", field)
 		}
 	}
 	return response, nil
 }
 
-// UpdateEntity modifies existing metadata
+// UpdateEntity modifies an existing entity. Only the metadata keys named in the request change, the values
+// they replace are kept in the metadata history.
 func (s *Server) UpdateEntity(ctx context.Context, req *pb.UpdateEntityRequest) (*pb.Entity, error) {
-	// Extract ID from request parameter and entity data
+	// Extract ID from request parameter and entity data, a request may only remove metadata
 	updateEntityID := req.Id
+	if req.Entity == nil {
+		req.Entity = &pb.Entity{}
+	}
 	updateEntity := req.Entity
+	logger := logging.FromContext(ctx).With("entity_id", updateEntityID)
+	logger.InfoContext(ctx, "Updating entity")
+
+	if req.ValidFrom != "" {
+		if _, err := metadatahistory.ParseTime(req.ValidFrom); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "validFrom %q is not an RFC3339 timestamp", req.ValidFrom)
+		}
+	}
 
 	// Ensure the entity ID matches the URL parameter - since the id is already passed in the url param, the user does not need to pass it again in the payload
 	if updateEntity.Id == "" || updateEntity.Id != updateEntityID {
 		updateEntity.Id = updateEntityID
 	}
 
-	// Pass the ID and metadata to HandleMetadata- if no metadata was provided this will rerturn nil
-	err := s.mongoRepo.HandleMetadata(ctx, updateEntityID, updateEntity)
+	// Validate the update against the kind schema, the kind itself cannot be updated so it is read from the graph
+	var kind *pb.Kind
+	if !s.kindRegistry.IsEmpty() {
+		graphEntity, err := s.graphStore.ReadGraphEntity(ctx, updateEntityID)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error reading kind of entity", "error", err)
+			return nil, fmt.Errorf("error reading kind of entity %s: %v", updateEntityID, err)
+		}
+		major, ok := graphEntity["MajorKind"].(string)
+		if !ok || major == "" {
+			logger.WarnContext(ctx, "Entity has no kind")
+			return nil, status.Errorf(codes.NotFound, "entity %s not found", updateEntityID)
+		}
+		minor, _ := graphEntity["MinorKind"].(string)
+		kind = &pb.Kind{Major: major, Minor: minor}
+		if err := s.kindRegistry.Validate(updateEntity, kind, false); err != nil {
+			logger.WarnContext(ctx, "Entity does not match its kind", "error", err)
+			return nil, kindError(err)
+		}
+		if err := s.kindRegistry.ValidateMetadataRemoval(updateEntityID, kind, req.RemoveMetadata); err != nil {
+			logger.WarnContext(ctx, "Metadata removal does not match the kind", "error", err)
+			return nil, kindError(err)
+		}
+	}
+	schemas, err := s.declaredSchemas(updateEntity, kind)
 	if err != nil {
-		log.Printf("[server.UpdateEntity] Error updating metadata for entity %s: %v", updateEntityID, err)
-		return nil, fmt.Errorf("error updating metadata for entity %s: %v", updateEntityID, err)
+		return nil, err
+	}
+
+	// Pass the ID and the metadata change to HandleMetadata- if no metadata was provided this will rerturn nil
+	err = s.metadataStore.HandleMetadata(ctx, updateEntityID, &metadatahistory.Change{
+		Set:        updateEntity.Metadata,
+		Remove:     req.RemoveMetadata,
+		ValidFrom:  req.ValidFrom,
+		RecordedBy: auth.SubjectFromContext(ctx),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error updating metadata", "error", err)
+		return nil, metadataError(fmt.Errorf("error updating metadata for entity %s: %w", updateEntityID, err))
 	}
 
 	// Handle Graph Entity update if entity has required fields
-	success, err := s.neo4jRepo.HandleGraphEntityUpdate(ctx, updateEntity)
+	success, err := s.graphStore.HandleGraphEntityUpdate(ctx, updateEntity)
 	if !success {
-		log.Printf("[server.UpdateEntity] Error updating graph entity for %s: %v", updateEntityID, err)
+		logger.ErrorContext(ctx, "Error updating graph entity", "error", err)
 		return nil, fmt.Errorf("error updating graph entity for entity %s: %v", updateEntityID, err)
 	}
 
 	// Handle Relationships update
-	err = s.neo4jRepo.HandleGraphRelationshipsUpdate(ctx, updateEntity)
+	err = s.graphStore.HandleGraphRelationshipsUpdate(ctx, updateEntity)
 	if err != nil {
-		log.Printf("[server.UpdateEntity] Error updating relationships for entity %s: %v", updateEntityID, err)
-		return nil, fmt.Errorf("error updating relationships for entity %s: %v", updateEntityID, err)
+		logger.ErrorContext(ctx, "Error updating relationships", "error", err)
+		return nil, relationshipError(fmt.Errorf("error updating relationships for entity %s: %w", updateEntityID, err))
 	}
 
 	// Handle attributes
-	processor := engine.NewEntityAttributeProcessor()
+	processor := s.processor
 	// Note that in the perspective of the attribute this is a creation operation
 	// The entity is already there but here the attribute is set later.
 	// There is no alignment of update operation with the attribute.
 	// TODO: https://github.com/LDFLK/nexoan/issues/286
-	attributeResults := processor.ProcessEntityAttributes(ctx, req.Entity, "create", nil)
+	attributeResults := processor.ProcessEntityAttributes(ctx, req.Entity, "create", engine.NewCreateOptions(&engine.CreateOptions{Schemas: schemas, RecordedBy: auth.SubjectFromContext(ctx)}))
 
 	// Check if any attributes failed
 	hasErrors := false
 	for attrName, result := range attributeResults {
 		if !result.Success || result.Error != nil {
-			log.Printf("[server.CreateEntity] Error handling attribute %s: %v", attrName, result.Error)
+			logger.ErrorContext(ctx, "Error handling attribute", "attribute", attrName, "error", result.Error)
 			hasErrors = true
 		} else {
-			log.Printf("[server.CreateEntity] Successfully handled attribute %s for entity: %s", attrName, req.Id)
+			logger.DebugContext(ctx, "Handled attribute", "attribute", attrName)
 		}
 	}
 
 	if hasErrors {
-		log.Printf("[server.CreateEntity] Some attributes failed to process")
-		return nil, fmt.Errorf("some attributes failed to process")
+		return nil, attributeError(attributeResults)
 	}
 
 	// Prepare the Update Response
 
 	// Read entity data from Neo4j to include in response
-	kind, name, created, terminated, _ := s.neo4jRepo.GetGraphEntity(ctx, updateEntityID)
+	kind, name, created, terminated, _ := s.graphStore.GetGraphEntity(ctx, updateEntityID)
 
 	// Get relationships from Neo4j
-	relationships, _ := s.neo4jRepo.GetGraphRelationships(ctx, updateEntityID)
+	relationships, _ := s.graphStore.GetGraphRelationships(ctx, updateEntityID)
 
 	// Get metadata from MongoDB
-	metadata, _ := s.mongoRepo.GetMetadata(ctx, updateEntityID)
+	metadata, _ := s.metadataStore.GetMetadata(ctx, updateEntityID, "")
 
 	// Return updated entity with all available information
 	return &pb.Entity{
@@ -308,25 +524,46 @@ func (s *Server) UpdateEntity(ctx context.Context, req *pb.UpdateEntityRequest)
 	}, nil
 }
 
+// ReadMetadataHistory returns every value the metadata keys of an entity have held, or those of one key
+func (s *Server) ReadMetadataHistory(ctx context.Context, req *pb.MetadataHistoryRequest) (*pb.MetadataHistory, error) {
+	if req.GetEntityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity ID is required")
+	}
+	logger := logging.FromContext(ctx).With("entity_id", req.EntityId)
+	logger.InfoContext(ctx, "Reading metadata history", "key", req.Key)
+
+	if _, _, _, _, err := s.graphStore.GetGraphEntity(ctx, req.EntityId); err != nil {
+		logger.WarnContext(ctx, "Error fetching entity info", "error", err)
+		return nil, status.Errorf(codes.NotFound, "entity %s not found", req.EntityId)
+	}
+	versions, err := s.metadataStore.GetMetadataHistory(ctx, req.EntityId, req.Key)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error fetching metadata history", "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to read metadata history of entity %s: %v", req.EntityId, err)
+	}
+	return &pb.MetadataHistory{EntityId: req.EntityId, Versions: versions}, nil
+}
+
 // DeleteEntity removes metadata
 func (s *Server) DeleteEntity(ctx context.Context, req *pb.EntityId) (*pb.Empty, error) {
-	log.Printf("[server.DeleteEntity] Deleting Entity metadata: %s", req.Id)
+	logger := logging.FromContext(ctx).With("entity_id", req.Id)
+	logger.InfoContext(ctx, "Deleting entity metadata")
 
 	// Check if entity exists before deleting
-	_, err := s.mongoRepo.ReadEntity(ctx, req.Id)
+	_, err := s.metadataStore.ReadEntity(ctx, req.Id)
 	if err != nil {
 		// NOTE: Not returning an error here because we want to delete the 
 		// entity even if it does not contain metadata
-		log.Printf("[server.DeleteEntity] Entity %s does not contain metadata: %v", req.Id, err)
+		logger.DebugContext(ctx, "Entity does not contain metadata", "error", err)
 	} else {
-		log.Printf("[server.DeleteEntity] Entity %s metadata exists.", req.Id)
-		_, err = s.mongoRepo.DeleteEntity(ctx, req.Id)
+		logger.DebugContext(ctx, "Entity metadata exists")
+		_, err = s.metadataStore.DeleteEntity(ctx, req.Id)
 		if err != nil {
 			// Log error
-			log.Printf("[server.DeleteEntity] Error deleting metadata for entity %s: %v", req.Id, err)
+			logger.ErrorContext(ctx, "Error deleting metadata", "error", err)
 			return nil, fmt.Errorf("error deleting metadata for entity %s: %v", req.Id, err)
 		} else {
-			log.Printf("[server.DeleteEntity] Entity %s metadata deleted.", req.Id)
+			logger.DebugContext(ctx, "Deleted entity metadata")
 		}
 	}
 	// TODO: Implement Relationship Deletion in Neo4j
@@ -335,7 +572,30 @@ func (s *Server) DeleteEntity(ctx context.Context, req *pb.EntityId) (*pb.Empty,
 	return &pb.Empty{}, nil
 }
 
-// ReadEntities retrieves a list of entities filtered by base attributes
+// spatialFilter converts the spatial filter of a request, it returns false when there is none
+func spatialFilter(filter *pb.SpatialFilter) (geo.Filter, bool, error) {
+	if filter == nil {
+		return geo.Filter{}, false, nil
+	}
+	if filter.Attribute == "" {
+		return geo.Filter{}, false, status.Error(codes.InvalidArgument, "spatial filter attribute is required")
+	}
+	var result geo.Filter
+	if point := filter.ContainsPoint; point != nil {
+		result.ContainsPoint = &geo.Position{Lon: point.Longitude, Lat: point.Latitude}
+	}
+	if box := filter.Intersects; box != nil {
+		result.IntersectsBox = &geo.Box{MinLon: box.MinLongitude, MinLat: box.MinLatitude, MaxLon: box.MaxLongitude, MaxLat: box.MaxLatitude}
+	}
+	if err := result.Validate(); err != nil {
+		return geo.Filter{}, false, status.Errorf(codes.InvalidArgument, "invalid spatial filter: %v", err)
+	}
+	return result, true, nil
+}
+
+// ReadEntities retrieves a list of entities filtered by base attributes.
+// A spatial filter keeps the entities whose geospatial attribute contains a point or intersects a bounding box,
+// the geometry an entity had at activeAt is used when it is given and the current one otherwise.
 func (s *Server) ReadEntities(ctx context.Context, req *pb.ReadEntityRequest) (*pb.EntityList, error) {
 	if req.Entity == nil {
 		return nil, fmt.Errorf("entity is required for filtering entities")
@@ -345,24 +605,53 @@ func (s *Server) ReadEntities(ctx context.Context, req *pb.ReadEntityRequest) (*
 	if req.Entity.Id == "" && (req.Entity.Kind == nil || req.Entity.Kind.Major == "") {
 		return nil, fmt.Errorf("either Entity.Id or Entity.Kind.Major is required for filtering entities")
 	}
+	filter, hasSpatialFilter, err := spatialFilter(req.Spatial)
+	if err != nil {
+		return nil, err
+	}
+	spatialActiveAt := time.Now()
+	if hasSpatialFilter && req.ActiveAt != "" {
+		if spatialActiveAt, err = metadatahistory.ParseTime(req.ActiveAt); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "activeAt %q is not an RFC3339 timestamp", req.ActiveAt)
+		}
+	}
 
 	// If we have an ID, add it to the filters
+	logger := logging.FromContext(ctx)
 	if req.Entity.Id != "" {
-		log.Printf("Filtering entities by ID: %s", req.Entity.Id)
+		logger.InfoContext(ctx, "Filtering entities by ID", "entity_id", req.Entity.Id)
 	} else {
-		log.Printf("Filtering entities by Kind.Major: %s", req.Entity.Kind.Major)
+		logger.InfoContext(ctx, "Filtering entities by kind", "kind", req.Entity.Kind.Major)
 	}
 
 	// Use HandleGraphEntityFilter to get filtered entities
-	filteredEntities, err := s.neo4jRepo.HandleGraphEntityFilter(ctx, req)
+	filteredEntities, err := s.graphStore.HandleGraphEntityFilter(ctx, req)
 	if err != nil {
-		log.Printf("Error filtering entities: %v", err)
-		return nil, err
+		logger.ErrorContext(ctx, "Error filtering entities", "error", err)
+		return nil, identifierError(err)
+	}
+
+	// Look up the entities matching the spatial filter in the spatial index
+	var spatialMatches map[string]bool
+	if hasSpatialFilter {
+		entityIDs, err := s.processor.FindEntities(ctx, req.Spatial.Attribute, filter, spatialActiveAt)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error filtering entities by geometry", "attribute", req.Spatial.Attribute, "error", err)
+			return nil, err
+		}
+		logger.DebugContext(ctx, "Filtered entities by geometry", "attribute", req.Spatial.Attribute, "matches", len(entityIDs))
+		spatialMatches = make(map[string]bool, len(entityIDs))
+		for _, entityID := range entityIDs {
+			spatialMatches[entityID] = true
+		}
 	}
 
 	// Convert filtered entities to pb.Entity format
 	var entities []*pb.Entity
 	for _, entity := range filteredEntities {
+		if hasSpatialFilter && !spatialMatches[entity["id"].(string)] {
+			continue
+		}
 		pbEntity := &pb.Entity{
 			Id: entity["id"].(string),
 			Kind: &pb.Kind{
@@ -393,6 +682,472 @@ func (s *Server) ReadEntities(ctx context.Context, req *pb.ReadEntityRequest) (*
 	}, nil
 }
 
+// Default and maximum number of hits returned by Search
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// Search finds the entities whose name, metadata values or tabular string cells contain every word of the query.
+// The matches of the graph, metadata and tabular stores are ranked together, then every hit is read from the graph
+// so that the kind filter applies to all of them and hits without an entity, such as attribute metadata, are dropped.
+func (s *Server) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	query, err := search.NewQuery(req.GetQuery(), req.GetKinds())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	limit := int(req.GetLimit())
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "search limit must be between 1 and %d", maxSearchLimit)
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+
+	logger := logging.FromContext(ctx)
+	logger.InfoContext(ctx, "Searching entities", "query", query.String(), "kinds", req.GetKinds(), "limit", limit)
+	nameMatches, err := s.graphStore.SearchEntityNames(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching entity names", "error", err)
+		return nil, err
+	}
+	metadataMatches, err := s.metadataStore.SearchMetadata(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching metadata", "error", err)
+		return nil, err
+	}
+	tabularMatches, err := s.tabularStore.SearchTabular(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching tabular attributes", "error", err)
+		return nil, err
+	}
+	matches := append(append(nameMatches, metadataMatches...), tabularMatches...)
+
+	var hits []*pb.SearchHit
+	for _, hit := range search.Rank(matches) {
+		if len(hits) == limit {
+			break
+		}
+		kind, name, created, terminated, err := s.graphStore.GetGraphEntity(ctx, hit.EntityID)
+		if err != nil {
+			logger.DebugContext(ctx, "Skipping search hit without an entity", "entity_id", hit.EntityID)
+			continue
+		}
+		if !query.MatchesKind(kind) {
+			continue
+		}
+		searchHit := &pb.SearchHit{
+			Entity: &pb.Entity{
+				Id:         hit.EntityID,
+				Kind:       kind,
+				Name:       name,
+				Created:    created,
+				Terminated: terminated,
+			},
+			Score: hit.Score,
+		}
+		for _, match := range hit.Matches {
+			searchHit.Matches = append(searchHit.Matches, &pb.SearchMatch{Field: match.Field, Text: match.Text})
+		}
+		hits = append(hits, searchHit)
+	}
+	logger.DebugContext(ctx, "Searched entities", "matches", len(matches), "hits", len(hits))
+	return &pb.SearchResponse{Hits: hits}, nil
+}
+
+// ListRelationshipTypes returns the declared relationship types
+func (s *Server) ListRelationshipTypes(ctx context.Context, req *pb.Empty) (*pb.RelationshipTypeList, error) {
+	return &pb.RelationshipTypeList{
+		RelationshipTypes: s.graphStore.RelationshipTypes().List(),
+	}, nil
+}
+
+// ListKinds returns the declared kind schemas
+func (s *Server) ListKinds(ctx context.Context, req *pb.Empty) (*pb.KindSchemaList, error) {
+	return &pb.KindSchemaList{
+		Kinds: s.kindRegistry.List(),
+	}, nil
+}
+
+// DescribeKind returns the schema declared for a kind
+func (s *Server) DescribeKind(ctx context.Context, req *pb.Kind) (*pb.KindSchema, error) {
+	if req.GetMajor() == "" {
+		return nil, status.Error(codes.InvalidArgument, "kind major is required")
+	}
+	schema, ok := s.kindRegistry.Get(req.Major)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "kind %s is not declared", req.Major)
+	}
+	if req.Minor != "" && len(schema.Minors) > 0 && !slices.Contains(schema.Minors, req.Minor) {
+		return nil, status.Errorf(codes.NotFound, "minor kind %s is not declared for kind %s", req.Minor, req.Major)
+	}
+	return schema, nil
+}
+
+// DescribeAttribute returns the stored schema of an attribute as a JSON Schema document.
+// Only tabular attributes have a stored schema.
+func (s *Server) DescribeAttribute(ctx context.Context, req *pb.AttributeId) (*pb.AttributeSchema, error) {
+	if req.GetEntityId() == "" || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity ID and attribute name are required")
+	}
+	if s.tabularStore == nil {
+		return nil, status.Error(codes.Unavailable, "tabular store is not configured")
+	}
+	tableName, err := s.tabularStore.AttributeTable(ctx, req.EntityId, req.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find table of attribute %s: %v", req.Name, err)
+	}
+	schemaInfo, err := s.tabularStore.GetSchemaOfTable(ctx, tableName)
+	if errors.Is(err, dbcommons.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "attribute %s of entity %s has no stored schema", req.Name, req.EntityId)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read schema of attribute %s: %v", req.Name, err)
+	}
+	document, err := schema.MarshalJSONSchema(schemaInfo)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert schema of attribute %s: %v", req.Name, err)
+	}
+	return &pb.AttributeSchema{
+		EntityId:    req.EntityId,
+		Name:        req.Name,
+		StorageType: string(schemaInfo.StorageType),
+		JsonSchema:  string(document),
+		Declared:    schemaInfo.Declared,
+	}, nil
+}
+
+// CreateAttributeIndex creates a secondary index on columns of a tabular attribute to speed up reads filtering on them.
+// Requesting an index that exists returns it.
+func (s *Server) CreateAttributeIndex(ctx context.Context, req *pb.AttributeIndexRequest) (*pb.AttributeIndex, error) {
+	if req.GetEntityId() == "" || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "entity ID and attribute name are required")
+	}
+	if len(req.GetColumns()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one column is required")
+	}
+	tableName, err := s.tabularStore.AttributeTable(ctx, req.EntityId, req.Name)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find table of attribute %s: %v", req.Name, err)
+	}
+	schemaInfo, err := s.tabularStore.GetSchemaOfTable(ctx, tableName)
+	if errors.Is(err, dbcommons.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "attribute %s of entity %s is not a tabular attribute", req.Name, req.EntityId)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read schema of attribute %s: %v", req.Name, err)
+	}
+	seen := make(map[string]bool, len(req.Columns))
+	for _, column := range req.Columns {
+		if _, ok := schemaInfo.Fields[column]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "attribute %s has no column %s", req.Name, column)
+		}
+		if seen[column] {
+			return nil, status.Errorf(codes.InvalidArgument, "column %s is repeated", column)
+		}
+		seen[column] = true
+	}
+
+	logger := logging.FromContext(ctx).With("entity_id", req.EntityId, "attribute", req.Name)
+	indexName, err := s.tabularStore.CreateIndex(ctx, tableName, req.Columns)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error creating attribute index", "columns", req.Columns, "error", err)
+		return nil, status.Errorf(codes.Internal, "failed to index attribute %s: %v", req.Name, err)
+	}
+	logger.InfoContext(ctx, "Created attribute index", "columns", req.Columns, "index", indexName)
+	return &pb.AttributeIndex{
+		EntityId:  req.EntityId,
+		Name:      req.Name,
+		Columns:   req.Columns,
+		IndexName: indexName,
+	}, nil
+}
+
+// blobChunkSize is the size of the chunks streamed by DownloadBlob
+const blobChunkSize = 64 * 1024
+
+// errBlobTooLarge is returned by blobChunkReader once more content than the maximum blob size is received
+var errBlobTooLarge = errors.New("blob content exceeds the maximum size")
+
+// blobChunkReader reads the content carried by the chunks received by UploadBlob
+type blobChunkReader struct {
+	stream  pb.CrudService_UploadBlobServer
+	pending []byte
+	// received counts the content bytes received, reading fails with errBlobTooLarge once it exceeds limit
+	received int64
+	limit    int64
+}
+
+func (r *blobChunkReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		chunk, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.pending = chunk.Data
+		r.received += int64(len(chunk.Data))
+	}
+	if r.limit > 0 && r.received > r.limit {
+		return 0, errBlobTooLarge
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// UploadBlob stores the content streamed by the caller as a blob attribute of an entity. The first chunk names
+// the entity and the attribute, the content is stored under its digest once the caller closes the stream.
+func (s *Server) UploadBlob(stream pb.CrudService_UploadBlobServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "blob info is required")
+	}
+	if err != nil {
+		return err
+	}
+	info := first.GetInfo()
+	if info.GetEntityId() == "" || info.GetName() == "" {
+		return status.Error(codes.InvalidArgument, "the first chunk must carry the entity ID and attribute name")
+	}
+	startTime := time.Now().UTC()
+	if info.StartTime != "" {
+		startTime, err = time.Parse(time.RFC3339, info.StartTime)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid start time %q: %v", info.StartTime, err)
+		}
+	}
+	if _, _, _, _, err := s.graphStore.GetGraphEntity(ctx, info.EntityId); err != nil {
+		return status.Errorf(codes.NotFound, "entity %s not found", info.EntityId)
+	}
+	logger := logging.FromContext(ctx).With("entity_id", info.EntityId, "attribute", info.Name)
+	logger.InfoContext(ctx, "Uploading blob attribute", "content_type", info.ContentType)
+
+	content := &blobChunkReader{stream: stream, pending: first.Data, received: int64(len(first.Data)), limit: s.maxBlobSize}
+	options := engine.NewCreateOptions(&engine.CreateOptions{RecordedBy: auth.SubjectFromContext(ctx)})
+	stored, err := s.processor.UploadBlob(ctx, info.EntityId, info.Name, startTime, info.ContentType, content, options)
+	if errors.Is(err, engine.ErrNoBlob) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if errors.Is(err, errBlobTooLarge) {
+		logger.WarnContext(ctx, "Blob attribute too large", "max_size", s.maxBlobSize)
+		return status.Errorf(codes.ResourceExhausted, "blob attribute %s exceeds the maximum size of %d bytes", info.Name, s.maxBlobSize)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error storing blob attribute", "error", err)
+		return status.Errorf(codes.Internal, "failed to store blob attribute %s: %v", info.Name, err)
+	}
+	logger.InfoContext(ctx, "Uploaded blob attribute", "digest", stored.Digest, "size", stored.Size)
+	return stream.SendAndClose(&pb.BlobInfo{
+		EntityId:    info.EntityId,
+		Name:        info.Name,
+		ContentType: stored.ContentType,
+		Size:        stored.Size,
+		Digest:      stored.Digest,
+		StartTime:   startTime.Format(time.RFC3339),
+	})
+}
+
+// DownloadBlob streams the content of a blob attribute, the first chunk carries its MIME type, size and digest
+func (s *Server) DownloadBlob(req *pb.AttributeId, stream pb.CrudService_DownloadBlobServer) error {
+	ctx := stream.Context()
+	if req.GetEntityId() == "" || req.GetName() == "" {
+		return status.Error(codes.InvalidArgument, "entity ID and attribute name are required")
+	}
+	if _, _, _, _, err := s.graphStore.GetGraphEntity(ctx, req.EntityId); err != nil {
+		return status.Errorf(codes.NotFound, "entity %s not found", req.EntityId)
+	}
+	content, info, err := s.processor.OpenBlob(ctx, req.EntityId, req.Name)
+	if errors.Is(err, engine.ErrNoBlob) || errors.Is(err, os.ErrNotExist) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error opening blob attribute", "entity_id", req.EntityId, "attribute", req.Name, "error", err)
+		return status.Errorf(codes.Internal, "failed to open blob attribute %s: %v", req.Name, err)
+	}
+	defer content.Close()
+
+	chunk := &pb.BlobChunk{Info: &pb.BlobInfo{
+		EntityId:    req.EntityId,
+		Name:        req.Name,
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		Digest:      info.Digest,
+	}}
+	buffer := make([]byte, blobChunkSize)
+	for {
+		n, err := io.ReadFull(content, buffer)
+		if n > 0 || chunk.Info != nil {
+			chunk.Data = buffer[:n]
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+			chunk = &pb.BlobChunk{}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read blob attribute %s: %v", req.Name, err)
+		}
+	}
+}
+
+// CreateRelationship creates a single relationship starting at req.EntityId
+func (s *Server) CreateRelationship(ctx context.Context, req *pb.EntityRelationship) (*pb.EntityRelationship, error) {
+	if req.GetEntityId() == "" || req.GetRelationship() == nil {
+		return nil, status.Error(codes.InvalidArgument, "entityId and relationship are required")
+	}
+	if err := s.checkRelationshipName(req.Relationship.Id, req.Relationship.Name); err != nil {
+		return nil, err
+	}
+	logger := logging.FromContext(ctx).With("relationship_id", req.Relationship.Id)
+	logger.InfoContext(ctx, "Creating relationship", "entity_id", req.EntityId)
+
+	// Reuse the entity relationship handling so the same validation applies
+	err := s.graphStore.HandleGraphRelationshipsCreate(ctx, &pb.Entity{
+		Id:            req.EntityId,
+		Relationships: map[string]*pb.Relationship{req.Relationship.Id: req.Relationship},
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error creating relationship", "error", err)
+		return nil, relationshipError(err)
+	}
+
+	created, err := s.graphStore.GetGraphRelationship(ctx, req.Relationship.Id)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	return created, nil
+}
+
+// ReadRelationship reads a single relationship by its ID
+func (s *Server) ReadRelationship(ctx context.Context, req *pb.RelationshipId) (*pb.EntityRelationship, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "relationship id is required")
+	}
+	relationship, err := s.graphStore.GetGraphRelationship(ctx, req.Id)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	return relationship, nil
+}
+
+// ReadRelationships reads the relationships starting and/or ending at the given entities
+func (s *Server) ReadRelationships(ctx context.Context, req *pb.ReadRelationshipsRequest) (*pb.RelationshipList, error) {
+	if req.GetSourceEntityId() == "" && req.GetTargetEntityId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "either sourceEntityId or targetEntityId is required")
+	}
+	relationships, err := s.graphStore.GetRelationshipsByEndpoints(ctx, req.SourceEntityId, req.TargetEntityId, req.Name, req.ActiveAt)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading relationships", "error", err)
+		return nil, err
+	}
+	return &pb.RelationshipList{Relationships: relationships}, nil
+}
+
+// UpdateRelationship updates the start time, end time or properties of an existing relationship
+func (s *Server) UpdateRelationship(ctx context.Context, req *pb.EntityRelationship) (*pb.EntityRelationship, error) {
+	if req.GetRelationship().GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "relationship id is required")
+	}
+	relationshipID := req.Relationship.Id
+
+	existing, err := s.graphStore.GetGraphRelationship(ctx, relationshipID)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	if req.EntityId != "" && req.EntityId != existing.EntityId {
+		return nil, status.Errorf(codes.InvalidArgument, "relationship %s does not start at entity %s", relationshipID, req.EntityId)
+	}
+	if err := s.checkRelationshipName(relationshipID, existing.Relationship.Name); err != nil {
+		return nil, err
+	}
+	if err := s.checkRelationshipName(relationshipID, req.Relationship.Name); err != nil {
+		return nil, err
+	}
+
+	logger := logging.FromContext(ctx).With("relationship_id", relationshipID)
+	logger.InfoContext(ctx, "Updating relationship")
+	err = s.graphStore.HandleGraphRelationshipsUpdate(ctx, &pb.Entity{
+		Id:            existing.EntityId,
+		Relationships: map[string]*pb.Relationship{relationshipID: req.Relationship},
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error updating relationship", "error", err)
+		return nil, relationshipError(err)
+	}
+
+	updated, err := s.graphStore.GetGraphRelationship(ctx, relationshipID)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	return updated, nil
+}
+
+// TerminateRelationship ends an active relationship at the given time
+func (s *Server) TerminateRelationship(ctx context.Context, req *pb.TerminateRelationshipRequest) (*pb.EntityRelationship, error) {
+	if req.GetId() == "" || req.GetEndTime() == "" {
+		return nil, status.Error(codes.InvalidArgument, "relationship id and endTime are required")
+	}
+	endTime, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "endTime must be an RFC3339 timestamp: %v", err)
+	}
+
+	existing, err := s.graphStore.GetGraphRelationship(ctx, req.Id)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	if err := s.checkRelationshipName(req.Id, existing.Relationship.Name); err != nil {
+		return nil, err
+	}
+	if existing.Relationship.EndTime != "" {
+		return nil, status.Errorf(codes.FailedPrecondition, "relationship %s is already terminated at %s", req.Id, existing.Relationship.EndTime)
+	}
+	if startTime, err := time.Parse(time.RFC3339, existing.Relationship.StartTime); err == nil && !endTime.After(startTime) {
+		return nil, status.Errorf(codes.InvalidArgument, "endTime %s must be after the relationship start time %s", req.EndTime, existing.Relationship.StartTime)
+	}
+
+	logger := logging.FromContext(ctx).With("relationship_id", req.Id)
+	logger.InfoContext(ctx, "Terminating relationship", "end_time", req.EndTime)
+	_, err = s.graphStore.UpdateRelationship(ctx, req.Id, map[string]interface{}{
+		"Terminated": req.EndTime,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "Error terminating relationship", "error", err)
+		return nil, relationshipError(err)
+	}
+
+	terminated, err := s.graphStore.GetGraphRelationship(ctx, req.Id)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	return terminated, nil
+}
+
+// DeleteRelationship removes a relationship
+func (s *Server) DeleteRelationship(ctx context.Context, req *pb.RelationshipId) (*pb.Empty, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "relationship id is required")
+	}
+	existing, err := s.graphStore.GetGraphRelationship(ctx, req.Id)
+	if err != nil {
+		return nil, relationshipError(err)
+	}
+	if err := s.checkRelationshipName(req.Id, existing.Relationship.Name); err != nil {
+		return nil, err
+	}
+
+	logger := logging.FromContext(ctx).With("relationship_id", req.Id)
+	logger.InfoContext(ctx, "Deleting relationship")
+	if err := s.graphStore.DeleteRelationship(ctx, req.Id); err != nil {
+		logger.ErrorContext(ctx, "Error deleting relationship", "error", err)
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
 // extractFieldsFromAttributes extracts field names from entity attributes based on storage type
 // TODO: Limitation in multi-value attribute reads.
 // FIXME: https://github.com/LDFLK/nexoan/issues/285
@@ -413,7 +1168,7 @@ func extractFieldsFromAttributes(attributes map[string]*pb.TimeBasedValueList) [
 		// Determine storage type and extract fields accordingly
 		storageType, err := determineStorageTypeFromValue(value.Value)
 		if err != nil {
-			log.Printf("Warning: could not determine storage type for attribute %s: %v", attrName, err)
+			slog.Warn("Could not determine storage type of attribute", "attribute", attrName, "error", err)
 			continue
 		}
 
@@ -423,16 +1178,18 @@ func extractFieldsFromAttributes(attributes map[string]*pb.TimeBasedValueList) [
 			if columns, err := extractColumnsFromTabularAttribute(value.Value); err == nil {
 				fields = append(fields, columns...)
 			} else {
-				log.Printf("Warning: could not extract columns from tabular attribute %s: %v", attrName, err)
+				slog.Warn("Could not extract columns from tabular attribute", "attribute", attrName, "error", err)
 			}
 		case "graph":
 			// TODO: Handle graph data fields
-			log.Printf("Graph data fields extraction not implemented yet for attribute %s", attrName)
+			slog.Debug("Graph data fields extraction not implemented yet", "attribute", attrName)
 		case "map":
 			// TODO: Handle document/map data fields
-			log.Printf("Document data fields extraction not implemented yet for attribute %s", attrName)
+			slog.Debug("Document data fields extraction not implemented yet", "attribute", attrName)
+		case "scalar":
+			// A scalar attribute has no fields, every value is returned
 		default:
-			log.Printf("Unknown storage type %s for attribute %s", storageType, attrName)
+			slog.Warn("Unknown storage type of attribute", "attribute", attrName, "storage_type", storageType)
 		}
 	}
 
@@ -506,79 +1263,186 @@ func extractColumnsFromTabularAttribute(anyValue *anypb.Any) ([]string, error) {
 	return columns, nil
 }
 
-// Start the gRPC server
-func main() {
-	// Initialize MongoDB config
-	mongoConfig := &config.MongoConfig{
-		URI:        os.Getenv("MONGO_URI"),
-		DBName:     os.Getenv("MONGO_DB_NAME"),
-		Collection: os.Getenv("MONGO_COLLECTION"),
+// NewServer creates a server on top of the given repositories and loads the relationship type
+// and kind registries from the metadata store
+func NewServer(ctx context.Context, repos *dbcommons.Repositories) (*Server, error) {
+	// Load the relationship type registry used to validate new relationships
+	relationshipTypes, err := repos.Metadata.ReadRelationshipTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read relationship types: %v", err)
 	}
+	if err := repos.Graph.RelationshipTypes().Load(relationshipTypes); err != nil {
+		return nil, fmt.Errorf("invalid relationship types: %v", err)
+	}
+	// Attribute relationships are created by the engine only, clients cannot create or change them
+	repos.Graph.RelationshipTypes().Exempt(engine.IS_ATTRIBUTE_RELATIONSHIP)
 
-	// Initialize Neo4j config
-	neo4jConfig := &config.Neo4jConfig{
-		URI:      os.Getenv("NEO4J_URI"),
-		Username: os.Getenv("NEO4J_USER"),
-		Password: os.Getenv("NEO4J_PASSWORD"),
+	// Load the kind registry used to validate entities
+	kindSchemas, err := repos.Metadata.ReadKindSchemas(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kind schemas: %v", err)
+	}
+	kindRegistry := kindschema.NewRegistry()
+	if err := kindRegistry.Load(kindSchemas); err != nil {
+		return nil, fmt.Errorf("invalid kind schemas: %v", err)
 	}
 
-	// Initialize PostgreSQL config
-	postgresConfig := &postgres.Config{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_DB"),
-		SSLMode:  os.Getenv("POSTGRES_SSL_MODE"),
+	// Create the indexes missing from entities and attribute tables written before they were added
+	if err := repos.CreateIndexes(ctx); err != nil {
+		return nil, err
 	}
 
-	// Get host and port from environment variables with defaults
-	host := os.Getenv("CRUD_SERVICE_HOST")
-	if host == "" {
-		host = "0.0.0.0"
+	// Create the full-text indexes used by Search
+	if err := repos.CreateSearchIndexes(ctx); err != nil {
+		return nil, err
 	}
-	port := os.Getenv("CRUD_SERVICE_PORT")
-	if port == "" {
-		port = "50051"
+
+	return &Server{
+		graphStore:    repos.Graph,
+		metadataStore: repos.Metadata,
+		tabularStore:  repos.Tabular,
+		kindRegistry:  kindRegistry,
+		processor:     engine.NewEntityAttributeProcessor(repos),
+	}, nil
+}
+
+// newGRPCServer creates the gRPC server with the tracing, logging and metrics instrumentation.
+// The guard authenticates and authorizes the CrudService RPCs, a nil guard accepts any caller.
+func newGRPCServer(guard *auth.Guard, options ...grpc.ServerOption) *grpc.Server {
+	// Assign a request ID to every RPC and carry a logger including it in the context
+	unary := []grpc.UnaryServerInterceptor{logging.UnaryServerInterceptor(), metrics.UnaryServerInterceptor()}
+	stream := []grpc.StreamServerInterceptor{logging.StreamServerInterceptor(), metrics.StreamServerInterceptor()}
+	// Rejected RPCs are still logged and counted
+	if guard != nil {
+		unary = append(unary, guard.UnaryServerInterceptor())
+		stream = append(stream, guard.StreamServerInterceptor())
 	}
 
-	// Create MongoDB repository
-	ctx := context.Background()
-	mongoRepo := mongorepository.NewMongoRepository(ctx, mongoConfig)
+	return grpc.NewServer(append(options,
+		// Start a span for every RPC, continuing the trace context found in the incoming metadata
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)...)
+}
+
+// fatal logs an error that prevents the service from starting and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
 
-	// Create Neo4j repository
-	neo4jRepo, err := neo4jrepository.NewNeo4jRepository(ctx, neo4jConfig)
+	configPath := flag.String("config", os.Getenv("CRUD_CONFIG"),
+		"path to a YAML or TOML configuration file, environment variables take precedence over its values")
+	storage := flag.String("storage", "",
+		"storage backend overriding the configuration: database (MongoDB, Neo4j and PostgreSQL), embedded (SQLite and a JSON document store in EMBEDDED_DATA_DIR) or memory (nothing is persisted)")
+	flag.Parse()
+
+	cfg, err := loadConfig(*configPath, *storage)
+	if err != nil {
+		fatal("Invalid configuration", err)
+	}
+	if err := logging.Setup(cfg.Logging); err != nil {
+		fatal("Failed to set up logging", err)
+	}
+	// Load every certificate before connecting so that a missing or invalid file stops the service at once
+	if err := tlsconfig.Validate(cfg); err != nil {
+		fatal("Invalid TLS configuration", err)
+	}
+	serverTLS, err := tlsconfig.Server(cfg.Server.TLS)
 	if err != nil {
-		log.Fatalf("[service.main] Failed to create Neo4j repository: %v", err)
+		fatal("Failed to set up TLS", err)
 	}
-	defer neo4jRepo.Close(ctx)
 
-	// Create PostgreSQL repository
-	postgresRepo, err := postgres.NewPostgresRepository(*postgresConfig)
+	// Export spans for every RPC, attribute resolver call and repository method
+	ctx := context.Background()
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
+	// Create the repositories shared by the server and the attribute engine
+	switch cfg.Storage {
+	case "embedded":
+		slog.Info("Using embedded storage", "data_dir", cfg.Embedded.DataDir)
+	case "memory":
+		slog.Warn("Using in-memory storage, data will be lost when the service stops")
+	}
+	repos, err := dbcommons.NewRepositoriesFromConfig(ctx, cfg)
 	if err != nil {
-		log.Fatalf("[service.main] Failed to create PostgreSQL repository: %v", err)
+		fatal("Failed to create repositories", err)
 	}
-	defer postgresRepo.Close()
+	// Record the latency and result of every store operation
+	repos = repos.Instrument()
+	defer repos.Close(ctx)
 
-	listener, err := net.Listen("tcp", host+":"+port)
+	server, err := NewServer(ctx, repos)
 	if err != nil {
-		log.Fatalf("[service.main] Failed to listen: %v", err)
+		fatal("Failed to create server", err)
 	}
+	server.maxBlobSize = int64(cfg.Blob.MaxSize)
 
-	grpcServer := grpc.NewServer()
-	server := &Server{
-		mongoRepo:    mongoRepo,
-		neo4jRepo:    neo4jRepo,
-		postgresRepo: postgresRepo,
+	address := net.JoinHostPort(cfg.Server.Host, strconv.Itoa(cfg.Server.Port))
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		fatal("Failed to listen", err)
+	}
+
+	var options []grpc.ServerOption
+	if serverTLS != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(serverTLS)))
+	}
+	// Authenticate callers and apply the authorization policy when an authentication method is configured
+	var guard *auth.Guard
+	if len(cfg.Auth.Methods) > 0 || cfg.Auth.AllowAnonymous {
+		if guard, err = auth.New(cfg.Auth, server); err != nil {
+			fatal("Failed to set up authentication", err)
+		}
+		slog.Info("Authenticating callers", "methods", cfg.Auth.Methods, "allow_anonymous", cfg.Auth.AllowAnonymous, "policy_file", cfg.Auth.PolicyFile)
 	}
 
+	grpcServer := newGRPCServer(guard, options...)
 	pb.RegisterCrudServiceServer(grpcServer, server)
 
+	// Register the health service, the service only reports SERVING once every store is reachable
+	monitor := newHealthMonitor(repos, cfg.Server.HealthCheckInterval)
+	monitor.register(grpcServer)
+
 	// Register reflection service
 	reflection.Register(grpcServer)
 
-	log.Printf("[service.main] CRUD Service is running on %s:%s...", host, port)
-	if err := grpcServer.Serve(listener); err != nil {
-		log.Fatalf("[service.main] Failed to serve: %v", err)
+	// Stop on SIGINT or SIGTERM, draining the in-flight RPCs before the repositories are closed
+	shutdownCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go monitor.run(shutdownCtx)
+
+	if cfg.Server.MetricsPort != 0 {
+		metricsServer := startMetricsServer(cfg.Server.Host, cfg.Server.MetricsPort, cfg.Logging.LevelEndpoint)
+		defer stopMetricsServer(metricsServer, cfg.Server.ShutdownTimeout)
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- grpcServer.Serve(listener)
+	}()
+	slog.Info("CRUD Service is running", "address", address, "storage", cfg.Storage, "tls", serverTLS != nil)
+
+	select {
+	case err := <-serveErr:
+		slog.Error("Failed to serve", "error", err)
+	case <-shutdownCtx.Done():
+		slog.Info("Shutting down, waiting for in-flight RPCs", "timeout", cfg.Server.ShutdownTimeout)
+		monitor.shutdown()
+		gracefulStop(grpcServer, cfg.Server.ShutdownTimeout)
 	}
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	dbcommons "lk/datafoundation/crud-api/commons/db"
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestTracingPropagation(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := tracing.Setup(ctx, config.TracingConfig{Exporter: "file", FilePath: path, ServiceName: "crud-api", SampleRatio: 1})
+	assert.NoError(t, err)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	repos := dbcommons.NewMemoryRepositories(config.RelationshipIntegrityConfig{}).Instrument()
+	server, err := NewServer(ctx, repos)
+	assert.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	grpcServer := newGRPCServer(nil)
+	pb.RegisterCrudServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	entity := newEntity(t, "traced-org", "Organisation", "Traced Org", "2024-01-01T00:00:00Z")
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": []interface{}{"year"},
+		"rows":    []interface{}{[]interface{}{2024}},
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	entity.Attributes = map[string]*pb.TimeBasedValueList{
+		"years": {Values: []*pb.TimeBasedValue{{StartTime: "2024-01-01T00:00:00Z", Value: value}}},
+	}
+
+	// The caller's trace context is sent in the gRPC metadata
+	ctx = metadata.AppendToOutgoingContext(ctx, "traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, err = pb.NewCrudServiceClient(conn).CreateEntity(ctx, entity)
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	spans := string(content)
+	assert.Contains(t, spans, `"Name":"crud.CrudService/CreateEntity"`)
+	assert.Contains(t, spans, `"Name":"memory.HandleGraphEntityCreation"`)
+	assert.Contains(t, spans, `"Name":"attribute_lookup.create"`)
+	assert.Contains(t, spans, `"Name":"resolver.create"`)
+	assert.Contains(t, spans, `"Name":"memory.HandleTabularData"`)
+	assert.Contains(t, spans, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
@@ -1,52 +1,49 @@
 package main
 
 import (
-	"fmt"
+	"context"
+
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
-	"log"
+	"lk/datafoundation/crud-api/pkg/logging"
 )
 
-func debugMetadata(req *pb.Entity) {
-
-	log.Printf("Reading Entity metadata: %s", req.Id)
-	fmt.Println("DEBUG - Received request:")
-	fmt.Printf("DEBUG - Entity ID: %s\n", req.Id)
-	fmt.Printf("DEBUG - Kind: %+v\n", req.Kind)
-	fmt.Printf("DEBUG - Created: %s\n", req.Created)
-	fmt.Printf("DEBUG - Terminated: %s\n", req.Terminated)
-	fmt.Printf("DEBUG - Name: %+v\n", req.Name)
-
-	// Print all metadata entries
-	fmt.Println("DEBUG - Metadata entries count:", len(req.Metadata))
+func debugMetadata(ctx context.Context, req *pb.Entity) {
+	logger := logging.FromContext(ctx)
+	logger.DebugContext(ctx, "Received entity",
+		"entity_id", req.Id,
+		"created", req.Created,
+		"terminated", req.Terminated,
+		"metadata_count", len(req.Metadata),
+		logging.Payload("kind", req.Kind),
+		logging.Payload("name", req.Name),
+	)
+
+	// Log all metadata entries
 	for key, value := range req.Metadata {
-		fmt.Printf("DEBUG - Metadata key: %s\n", key)
 		if value != nil {
-			fmt.Printf("DEBUG - Metadata value typeUrl: %s\n", value.TypeUrl)
-			fmt.Printf("DEBUG - Metadata value data length: %d\n", len(value.Value))
+			logger.DebugContext(ctx, "Metadata entry", "key", key, "type_url", value.TypeUrl, "length", len(value.Value))
 		} else {
-			fmt.Println("DEBUG - Metadata value is nil")
+			logger.DebugContext(ctx, "Metadata entry is nil", "key", key)
 		}
 	}
-
 }
 
-func debugUtils(req *pb.Entity) {
+func debugUtils(ctx context.Context, req *pb.Entity) {
+	logger := logging.FromContext(ctx)
 
-	// Print attributes if present
-	fmt.Println("DEBUG - Attributes count:", len(req.Attributes))
+	// Log attributes if present
+	logger.DebugContext(ctx, "Entity attributes", "count", len(req.Attributes))
 	for key, valueList := range req.Attributes {
-		fmt.Printf("DEBUG - Attribute key: %s\n", key)
 		if valueList != nil {
-			fmt.Printf("DEBUG - Attribute values count: %d\n", len(valueList.Values))
+			logger.DebugContext(ctx, "Attribute", "key", key, "values", len(valueList.Values))
 		}
 	}
 
-	// Print relationships if present
-	fmt.Println("DEBUG - Relationships count:", len(req.Relationships))
+	// Log relationships if present
+	logger.DebugContext(ctx, "Entity relationships", "count", len(req.Relationships))
 	for key, rel := range req.Relationships {
-		fmt.Printf("DEBUG - Relationship key: %s\n", key)
 		if rel != nil {
-			fmt.Printf("DEBUG - Related entity ID: %s\n", rel.RelatedEntityId)
+			logger.DebugContext(ctx, "Relationship", "key", key, "related_entity_id", rel.RelatedEntityId)
 		}
 	}
 }
@@ -0,0 +1,425 @@
+package dbcommons
+
+import (
+	"context"
+	"io"
+	"time"
+
+	blobrepository "lk/datafoundation/crud-api/db/repository/blob"
+	documentrepository "lk/datafoundation/crud-api/db/repository/document"
+	memoryrepository "lk/datafoundation/crud-api/db/repository/memory"
+	mongorepository "lk/datafoundation/crud-api/db/repository/mongo"
+	neo4jrepository "lk/datafoundation/crud-api/db/repository/neo4j"
+	postgresrepository "lk/datafoundation/crud-api/db/repository/postgres"
+	sqliterepository "lk/datafoundation/crud-api/db/repository/sqlite"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/geo"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/metrics"
+	"lk/datafoundation/crud-api/pkg/relationships"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/search"
+	"lk/datafoundation/crud-api/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// storeName returns the name a store is reported under in the metrics
+func storeName(store interface{}) string {
+	switch store.(type) {
+	case *neo4jrepository.Neo4jRepository:
+		return "neo4j"
+	case *mongorepository.MongoRepository:
+		return "mongo"
+	case *postgresrepository.PostgresRepository:
+		return "postgres"
+	case *sqliterepository.GraphRepository, *sqliterepository.TabularRepository:
+		return "sqlite"
+	case *documentrepository.DocumentRepository:
+		return "document"
+	case *blobrepository.LocalStore:
+		return "filesystem"
+	case *blobrepository.S3Store:
+		return "s3"
+	case *memoryrepository.GraphStore, *memoryrepository.MetadataStore, *memoryrepository.TabularStore, *memoryrepository.BlobStore,
+		*memoryrepository.SpatialStore:
+		return "memory"
+	default:
+		return "unknown"
+	}
+}
+
+// startOperation starts a span for a store operation and returns the function ending it.
+// The function records the latency and result of the operation in the metrics and ends the span.
+func startOperation(ctx context.Context, store string, method string) (context.Context, func(err *error)) {
+	start := time.Now()
+	ctx, span := tracing.Tracer().Start(ctx, store+"."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", store),
+			attribute.String("crud.store.method", method),
+		))
+	return ctx, func(err *error) {
+		metrics.ObserveStoreOperation(store, method, start, err)
+		tracing.End(span, *err)
+	}
+}
+
+// Instrument wraps every store so that each operation is traced and its latency and result recorded in the metrics.
+// The stores are labelled with their backend, for example neo4j or postgres, and the repository method.
+func (r *Repositories) Instrument() *Repositories {
+	return &Repositories{
+		Graph:    &instrumentedGraphStore{store: r.Graph, name: storeName(r.Graph)},
+		Metadata: &instrumentedMetadataStore{store: r.Metadata, name: storeName(r.Metadata)},
+		Tabular:  &instrumentedTabularStore{store: r.Tabular, name: storeName(r.Tabular)},
+		Blob:     &instrumentedBlobStore{store: r.Blob, name: storeName(r.Blob)},
+		Spatial:  &instrumentedSpatialStore{store: r.Spatial, name: storeName(r.Spatial)},
+	}
+}
+
+// instrumentedGraphStore traces and records metrics for every operation of a graph store
+type instrumentedGraphStore struct {
+	store GraphStore
+	name  string
+}
+
+func (s *instrumentedGraphStore) RelationshipTypes() *relationships.TypeRegistry {
+	return s.store.RelationshipTypes()
+}
+
+func (s *instrumentedGraphStore) Ping(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "Ping")
+	defer end(&err)
+	return s.store.Ping(ctx)
+}
+
+func (s *instrumentedGraphStore) Close(ctx context.Context) {
+	s.store.Close(ctx)
+}
+
+func (s *instrumentedGraphStore) ReadGraphEntity(ctx context.Context, entityID string) (entity map[string]interface{}, err error) {
+	ctx, end := startOperation(ctx, s.name, "ReadGraphEntity")
+	defer end(&err)
+	return s.store.ReadGraphEntity(ctx, entityID)
+}
+
+func (s *instrumentedGraphStore) GetGraphEntity(ctx context.Context, entityId string) (kind *pb.Kind, name *pb.TimeBasedValue, created string, terminated string, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetGraphEntity")
+	defer end(&err)
+	return s.store.GetGraphEntity(ctx, entityId)
+}
+
+func (s *instrumentedGraphStore) HandleGraphEntityCreation(ctx context.Context, entity *pb.Entity) (success bool, err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleGraphEntityCreation")
+	defer end(&err)
+	return s.store.HandleGraphEntityCreation(ctx, entity)
+}
+
+func (s *instrumentedGraphStore) HandleGraphEntityUpdate(ctx context.Context, entity *pb.Entity) (success bool, err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleGraphEntityUpdate")
+	defer end(&err)
+	return s.store.HandleGraphEntityUpdate(ctx, entity)
+}
+
+func (s *instrumentedGraphStore) HandleGraphEntityFilter(ctx context.Context, req *pb.ReadEntityRequest) (entities []map[string]interface{}, err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleGraphEntityFilter")
+	defer end(&err)
+	return s.store.HandleGraphEntityFilter(ctx, req)
+}
+
+func (s *instrumentedGraphStore) CreateIndexes(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateIndexes")
+	defer end(&err)
+	return s.store.CreateIndexes(ctx)
+}
+
+func (s *instrumentedGraphStore) CreateSearchIndexes(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateSearchIndexes")
+	defer end(&err)
+	return s.store.CreateSearchIndexes(ctx)
+}
+
+func (s *instrumentedGraphStore) SearchEntityNames(ctx context.Context, query search.Query) (matches []search.Match, err error) {
+	ctx, end := startOperation(ctx, s.name, "SearchEntityNames")
+	defer end(&err)
+	return s.store.SearchEntityNames(ctx, query)
+}
+
+func (s *instrumentedGraphStore) HandleGraphRelationshipsCreate(ctx context.Context, entity *pb.Entity) (err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleGraphRelationshipsCreate")
+	defer end(&err)
+	return s.store.HandleGraphRelationshipsCreate(ctx, entity)
+}
+
+func (s *instrumentedGraphStore) HandleGraphRelationshipsUpdate(ctx context.Context, entity *pb.Entity) (err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleGraphRelationshipsUpdate")
+	defer end(&err)
+	return s.store.HandleGraphRelationshipsUpdate(ctx, entity)
+}
+
+func (s *instrumentedGraphStore) GetGraphRelationships(ctx context.Context, entityId string) (relationships map[string]*pb.Relationship, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetGraphRelationships")
+	defer end(&err)
+	return s.store.GetGraphRelationships(ctx, entityId)
+}
+
+func (s *instrumentedGraphStore) GetGraphRelationship(ctx context.Context, relationshipId string) (relationship *pb.EntityRelationship, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetGraphRelationship")
+	defer end(&err)
+	return s.store.GetGraphRelationship(ctx, relationshipId)
+}
+
+func (s *instrumentedGraphStore) GetRelationshipsByEndpoints(ctx context.Context, sourceEntityId string, targetEntityId string, name string, activeAt string) (relationships []*pb.EntityRelationship, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetRelationshipsByEndpoints")
+	defer end(&err)
+	return s.store.GetRelationshipsByEndpoints(ctx, sourceEntityId, targetEntityId, name, activeAt)
+}
+
+func (s *instrumentedGraphStore) GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, properties map[string]*anypb.Any, activeAt string) (relationships map[string]*pb.Relationship, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetFilteredRelationships")
+	defer end(&err)
+	return s.store.GetFilteredRelationships(ctx, entityId, relationshipId, relationship, relatedEntityId, startTime, endTime, direction, properties, activeAt)
+}
+
+func (s *instrumentedGraphStore) ReadFilteredRelationships(ctx context.Context, entityID string, relationshipFilters map[string]interface{}, activeAt string) (relationships []map[string]interface{}, err error) {
+	ctx, end := startOperation(ctx, s.name, "ReadFilteredRelationships")
+	defer end(&err)
+	return s.store.ReadFilteredRelationships(ctx, entityID, relationshipFilters, activeAt)
+}
+
+func (s *instrumentedGraphStore) UpdateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (relationship map[string]interface{}, err error) {
+	ctx, end := startOperation(ctx, s.name, "UpdateRelationship")
+	defer end(&err)
+	return s.store.UpdateRelationship(ctx, relationshipID, updateData)
+}
+
+func (s *instrumentedGraphStore) DeleteRelationship(ctx context.Context, relationshipID string) (err error) {
+	ctx, end := startOperation(ctx, s.name, "DeleteRelationship")
+	defer end(&err)
+	return s.store.DeleteRelationship(ctx, relationshipID)
+}
+
+// instrumentedMetadataStore traces and records metrics for every operation of a metadata store
+type instrumentedMetadataStore struct {
+	store MetadataStore
+	name  string
+}
+
+func (s *instrumentedMetadataStore) Ping(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "Ping")
+	defer end(&err)
+	return s.store.Ping(ctx)
+}
+
+func (s *instrumentedMetadataStore) Close(ctx context.Context) error {
+	return s.store.Close(ctx)
+}
+
+func (s *instrumentedMetadataStore) CreateEntity(ctx context.Context, entity *pb.Entity) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateEntity")
+	defer end(&err)
+	return s.store.CreateEntity(ctx, entity)
+}
+
+func (s *instrumentedMetadataStore) ReadEntity(ctx context.Context, id string) (entity *pb.Entity, err error) {
+	ctx, end := startOperation(ctx, s.name, "ReadEntity")
+	defer end(&err)
+	return s.store.ReadEntity(ctx, id)
+}
+
+func (s *instrumentedMetadataStore) DeleteEntity(ctx context.Context, id string) (deleted bool, err error) {
+	ctx, end := startOperation(ctx, s.name, "DeleteEntity")
+	defer end(&err)
+	return s.store.DeleteEntity(ctx, id)
+}
+
+func (s *instrumentedMetadataStore) HandleMetadata(ctx context.Context, entityId string, change *metadatahistory.Change) (err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleMetadata")
+	defer end(&err)
+	return s.store.HandleMetadata(ctx, entityId, change)
+}
+
+func (s *instrumentedMetadataStore) GetMetadata(ctx context.Context, entityId string, activeAt string) (metadata map[string]*anypb.Any, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetMetadata")
+	defer end(&err)
+	return s.store.GetMetadata(ctx, entityId, activeAt)
+}
+
+func (s *instrumentedMetadataStore) GetMetadataHistory(ctx context.Context, entityId string, key string) (versions []*pb.MetadataVersion, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetMetadataHistory")
+	defer end(&err)
+	return s.store.GetMetadataHistory(ctx, entityId, key)
+}
+
+func (s *instrumentedMetadataStore) SaveAttributeValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) (err error) {
+	ctx, end := startOperation(ctx, s.name, "SaveAttributeValue")
+	defer end(&err)
+	return s.store.SaveAttributeValue(ctx, entityID, attrName, value)
+}
+
+func (s *instrumentedMetadataStore) GetAttributeValues(ctx context.Context, entityID, attrName string) (values []*pb.TimeBasedValue, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetAttributeValues")
+	defer end(&err)
+	return s.store.GetAttributeValues(ctx, entityID, attrName)
+}
+
+func (s *instrumentedMetadataStore) CreateIndexes(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateIndexes")
+	defer end(&err)
+	return s.store.CreateIndexes(ctx)
+}
+
+func (s *instrumentedMetadataStore) CreateSearchIndexes(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateSearchIndexes")
+	defer end(&err)
+	return s.store.CreateSearchIndexes(ctx)
+}
+
+func (s *instrumentedMetadataStore) SearchMetadata(ctx context.Context, query search.Query) (matches []search.Match, err error) {
+	ctx, end := startOperation(ctx, s.name, "SearchMetadata")
+	defer end(&err)
+	return s.store.SearchMetadata(ctx, query)
+}
+
+func (s *instrumentedMetadataStore) ReadRelationshipTypes(ctx context.Context) (relTypes []*pb.RelationshipType, err error) {
+	ctx, end := startOperation(ctx, s.name, "ReadRelationshipTypes")
+	defer end(&err)
+	return s.store.ReadRelationshipTypes(ctx)
+}
+
+func (s *instrumentedMetadataStore) ReadKindSchemas(ctx context.Context) (schemas []*pb.KindSchema, err error) {
+	ctx, end := startOperation(ctx, s.name, "ReadKindSchemas")
+	defer end(&err)
+	return s.store.ReadKindSchemas(ctx)
+}
+
+// instrumentedTabularStore traces and records metrics for every operation of a tabular store
+type instrumentedTabularStore struct {
+	store TabularStore
+	name  string
+}
+
+func (s *instrumentedTabularStore) Ping(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "Ping")
+	defer end(&err)
+	return s.store.Ping(ctx)
+}
+
+func (s *instrumentedTabularStore) Close() error {
+	return s.store.Close()
+}
+
+func (s *instrumentedTabularStore) InitializeTables(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "InitializeTables")
+	defer end(&err)
+	return s.store.InitializeTables(ctx)
+}
+
+func (s *instrumentedTabularStore) AttributeTable(ctx context.Context, entityID, attrName string) (tableName string, err error) {
+	ctx, end := startOperation(ctx, s.name, "AttributeTable")
+	defer end(&err)
+	return s.store.AttributeTable(ctx, entityID, attrName)
+}
+
+func (s *instrumentedTabularStore) HandleTabularData(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, schemaInfo *schema.SchemaInfo) (err error) {
+	ctx, end := startOperation(ctx, s.name, "HandleTabularData")
+	defer end(&err)
+	return s.store.HandleTabularData(ctx, entityID, attrName, value, schemaInfo)
+}
+
+func (s *instrumentedTabularStore) GetData(ctx context.Context, tableName string, filters map[string]interface{}, fields ...string) (data *anypb.Any, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetData")
+	defer end(&err)
+	return s.store.GetData(ctx, tableName, filters, fields...)
+}
+
+func (s *instrumentedTabularStore) GetSchemaOfTable(ctx context.Context, tableName string) (schemaInfo *schema.SchemaInfo, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetSchemaOfTable")
+	defer end(&err)
+	return s.store.GetSchemaOfTable(ctx, tableName)
+}
+
+func (s *instrumentedTabularStore) SearchTabular(ctx context.Context, query search.Query) (matches []search.Match, err error) {
+	ctx, end := startOperation(ctx, s.name, "SearchTabular")
+	defer end(&err)
+	return s.store.SearchTabular(ctx, query)
+}
+
+func (s *instrumentedTabularStore) CreateIndexes(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateIndexes")
+	defer end(&err)
+	return s.store.CreateIndexes(ctx)
+}
+
+func (s *instrumentedTabularStore) CreateIndex(ctx context.Context, tableName string, columns []string) (name string, err error) {
+	ctx, end := startOperation(ctx, s.name, "CreateIndex")
+	defer end(&err)
+	return s.store.CreateIndex(ctx, tableName, columns)
+}
+
+// instrumentedBlobStore traces and records metrics for every operation of a blob store
+type instrumentedBlobStore struct {
+	store BlobStore
+	name  string
+}
+
+func (s *instrumentedBlobStore) Ping(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "Ping")
+	defer end(&err)
+	return s.store.Ping(ctx)
+}
+
+func (s *instrumentedBlobStore) Close() error {
+	return s.store.Close()
+}
+
+func (s *instrumentedBlobStore) Put(ctx context.Context, content io.Reader) (object *blobrepository.Object, err error) {
+	ctx, end := startOperation(ctx, s.name, "Put")
+	defer end(&err)
+	return s.store.Put(ctx, content)
+}
+
+// Open is recorded until the content is opened, reading it is left to the caller
+func (s *instrumentedBlobStore) Open(ctx context.Context, digest string) (content io.ReadCloser, object *blobrepository.Object, err error) {
+	ctx, end := startOperation(ctx, s.name, "Open")
+	defer end(&err)
+	return s.store.Open(ctx, digest)
+}
+
+// instrumentedSpatialStore traces and records metrics for every operation of a spatial store
+type instrumentedSpatialStore struct {
+	store SpatialStore
+	name  string
+}
+
+func (s *instrumentedSpatialStore) InitializeGeometries(ctx context.Context) (err error) {
+	ctx, end := startOperation(ctx, s.name, "InitializeGeometries")
+	defer end(&err)
+	return s.store.InitializeGeometries(ctx)
+}
+
+func (s *instrumentedSpatialStore) SaveGeometry(ctx context.Context, entityID, attrName string, geometry *geo.Geometry, startTime, endTime time.Time) (err error) {
+	ctx, end := startOperation(ctx, s.name, "SaveGeometry")
+	defer end(&err)
+	return s.store.SaveGeometry(ctx, entityID, attrName, geometry, startTime, endTime)
+}
+
+func (s *instrumentedSpatialStore) GetGeometry(ctx context.Context, entityID, attrName string) (geometry *geo.Geometry, err error) {
+	ctx, end := startOperation(ctx, s.name, "GetGeometry")
+	defer end(&err)
+	return s.store.GetGeometry(ctx, entityID, attrName)
+}
+
+func (s *instrumentedSpatialStore) DeleteGeometry(ctx context.Context, entityID, attrName string) (err error) {
+	ctx, end := startOperation(ctx, s.name, "DeleteGeometry")
+	defer end(&err)
+	return s.store.DeleteGeometry(ctx, entityID, attrName)
+}
+
+func (s *instrumentedSpatialStore) FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) (entityIDs []string, err error) {
+	ctx, end := startOperation(ctx, s.name, "FindEntities")
+	defer end(&err)
+	return s.store.FindEntities(ctx, attrName, filter, activeAt)
+}
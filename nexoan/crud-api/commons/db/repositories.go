@@ -0,0 +1,205 @@
+package dbcommons
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"lk/datafoundation/crud-api/db/config"
+	blobrepository "lk/datafoundation/crud-api/db/repository/blob"
+	documentrepository "lk/datafoundation/crud-api/db/repository/document"
+	memoryrepository "lk/datafoundation/crud-api/db/repository/memory"
+	mongorepository "lk/datafoundation/crud-api/db/repository/mongo"
+	neo4jrepository "lk/datafoundation/crud-api/db/repository/neo4j"
+	postgresrepository "lk/datafoundation/crud-api/db/repository/postgres"
+	sqliterepository "lk/datafoundation/crud-api/db/repository/sqlite"
+)
+
+// Repositories holds the stores shared by the whole service.
+// It is created once in main() and injected into the server and the engine so that
+// every request reuses the same Neo4j driver, Mongo client and Postgres pool.
+type Repositories struct {
+	Graph    GraphStore
+	Metadata MetadataStore
+	Tabular  TabularStore
+	Blob     BlobStore
+	Spatial  SpatialStore
+}
+
+// NewRepositories connects to all databases using the given configurations and opens the blob store in the blob directory
+func NewRepositories(ctx context.Context, mongoConfig *config.MongoConfig, neo4jConfig *config.Neo4jConfig, postgresConfig postgresrepository.Config, blobConfig *config.BlobConfig) (*Repositories, error) {
+	repos := &Repositories{}
+
+	mongoRepo, err := mongorepository.NewMongoRepository(ctx, mongoConfig)
+	if err != nil {
+		return nil, fmt.Errorf("[Commons] failed to create Mongo repository: %w", err)
+	}
+	repos.Metadata = mongoRepo
+
+	neo4jRepo, err := neo4jrepository.NewNeo4jRepository(ctx, neo4jConfig)
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to create Neo4j repository: %w", err)
+	}
+	repos.Graph = neo4jRepo
+
+	postgresRepo, err := postgresrepository.NewPostgresRepository(postgresConfig)
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to create Postgres repository: %w", err)
+	}
+	repos.Tabular = postgresRepo
+	repos.Spatial = postgresRepo
+
+	blobStore, err := blobrepository.NewLocalStore(blobConfig.Dir)
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to open blob store: %w", err)
+	}
+	repos.Blob = blobStore
+
+	return repos, nil
+}
+
+// NewRepositoriesFromEnv connects to all databases using configurations read from environment variables
+func NewRepositoriesFromEnv(ctx context.Context) (*Repositories, error) {
+	return NewRepositories(ctx, GetMongoConfig(), GetNeo4jConfig(), GetPostgresConfig(), GetBlobConfig())
+}
+
+// NewRepositoriesFromConfig creates the repositories of the storage backend selected in the configuration.
+// The configuration is expected to be validated already.
+func NewRepositoriesFromConfig(ctx context.Context, cfg *config.Config) (*Repositories, error) {
+	switch cfg.Storage {
+	case "database":
+		return NewRepositories(ctx, &cfg.Mongo, &cfg.Neo4j, PostgresRepositoryConfig(cfg.Postgres), &cfg.Blob)
+	case "embedded":
+		return NewEmbeddedRepositories(ctx, &cfg.Embedded, cfg.Neo4j.RelationshipIntegrity)
+	case "memory":
+		return NewMemoryRepositories(cfg.Neo4j.RelationshipIntegrity), nil
+	default:
+		return nil, fmt.Errorf("[Commons] unknown storage backend %q, expected database, embedded or memory", cfg.Storage)
+	}
+}
+
+// NewEmbeddedRepositories opens the embedded stores in the configured data directory, creating it if needed.
+// The graph, tabular and geospatial data are kept in SQLite databases, the metadata in a JSON document store
+// and the blob content in the blobs directory.
+func NewEmbeddedRepositories(ctx context.Context, embeddedConfig *config.EmbeddedConfig, rules config.RelationshipIntegrityConfig) (*Repositories, error) {
+	if err := os.MkdirAll(embeddedConfig.DataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("[Commons] failed to create data directory %s: %w", embeddedConfig.DataDir, err)
+	}
+	repos := &Repositories{}
+
+	documentRepo, err := documentrepository.NewDocumentRepository(filepath.Join(embeddedConfig.DataDir, "metadata.json"))
+	if err != nil {
+		return nil, fmt.Errorf("[Commons] failed to open document store: %w", err)
+	}
+	repos.Metadata = documentRepo
+
+	graphRepo, err := sqliterepository.NewGraphRepository(ctx, filepath.Join(embeddedConfig.DataDir, "graph.db"), rules)
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to open SQLite graph store: %w", err)
+	}
+	repos.Graph = graphRepo
+
+	tabularRepo, err := sqliterepository.NewTabularRepository(filepath.Join(embeddedConfig.DataDir, "tabular.db"))
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to open SQLite tabular store: %w", err)
+	}
+	repos.Tabular = tabularRepo
+	repos.Spatial = tabularRepo
+
+	blobStore, err := blobrepository.NewLocalStore(filepath.Join(embeddedConfig.DataDir, "blobs"))
+	if err != nil {
+		repos.Close(ctx)
+		return nil, fmt.Errorf("[Commons] failed to open blob store: %w", err)
+	}
+	repos.Blob = blobStore
+
+	return repos, nil
+}
+
+// NewMemoryRepositories creates in-memory stores enforcing the given relationship integrity rules.
+// Nothing is persisted, they are meant for tests and local development.
+func NewMemoryRepositories(rules config.RelationshipIntegrityConfig) *Repositories {
+	return &Repositories{
+		Graph:    memoryrepository.NewGraphStore(rules),
+		Metadata: memoryrepository.NewMetadataStore(),
+		Tabular:  memoryrepository.NewTabularStore(),
+		Blob:     memoryrepository.NewBlobStore(),
+		Spatial:  memoryrepository.NewSpatialStore(),
+	}
+}
+
+// Store names used when reporting the status of each repository
+const (
+	GraphStoreName    = "graph"
+	MetadataStoreName = "metadata"
+	TabularStoreName  = "tabular"
+	BlobStoreName     = "blob"
+)
+
+// Ping checks that every store can be reached and returns the result of each one by store name
+func (r *Repositories) Ping(ctx context.Context) map[string]error {
+	return map[string]error{
+		GraphStoreName:    r.Graph.Ping(ctx),
+		MetadataStoreName: r.Metadata.Ping(ctx),
+		TabularStoreName:  r.Tabular.Ping(ctx),
+		BlobStoreName:     r.Blob.Ping(ctx),
+	}
+}
+
+// CreateIndexes creates the indexes of the graph, metadata and tabular stores on the data written before they
+// were added
+func (r *Repositories) CreateIndexes(ctx context.Context) error {
+	if err := r.Graph.CreateIndexes(ctx); err != nil {
+		return fmt.Errorf("[Commons] failed to create graph indexes: %w", err)
+	}
+	if err := r.Metadata.CreateIndexes(ctx); err != nil {
+		return fmt.Errorf("[Commons] failed to create metadata indexes: %w", err)
+	}
+	if err := r.Tabular.CreateIndexes(ctx); err != nil {
+		return fmt.Errorf("[Commons] failed to create tabular indexes: %w", err)
+	}
+	return nil
+}
+
+// CreateSearchIndexes creates the full-text indexes of the graph and metadata stores
+func (r *Repositories) CreateSearchIndexes(ctx context.Context) error {
+	if err := r.Graph.CreateSearchIndexes(ctx); err != nil {
+		return fmt.Errorf("[Commons] failed to create graph search indexes: %w", err)
+	}
+	if err := r.Metadata.CreateSearchIndexes(ctx); err != nil {
+		return fmt.Errorf("[Commons] failed to create metadata search indexes: %w", err)
+	}
+	return nil
+}
+
+// Close releases every open repository connection
+func (r *Repositories) Close(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	if r.Tabular != nil {
+		if err := r.Tabular.Close(); err != nil {
+			slog.ErrorContext(ctx, "Failed to close tabular store", "error", err)
+		}
+	}
+	if r.Graph != nil {
+		r.Graph.Close(ctx)
+	}
+	if r.Metadata != nil {
+		if err := r.Metadata.Close(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to close metadata store", "error", err)
+		}
+	}
+	if r.Blob != nil {
+		if err := r.Blob.Close(); err != nil {
+			slog.ErrorContext(ctx, "Failed to close blob store", "error", err)
+		}
+	}
+}
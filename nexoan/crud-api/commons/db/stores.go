@@ -0,0 +1,167 @@
+package dbcommons
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	blobrepository "lk/datafoundation/crud-api/db/repository/blob"
+	documentrepository "lk/datafoundation/crud-api/db/repository/document"
+	memoryrepository "lk/datafoundation/crud-api/db/repository/memory"
+	mongorepository "lk/datafoundation/crud-api/db/repository/mongo"
+	neo4jrepository "lk/datafoundation/crud-api/db/repository/neo4j"
+	postgresrepository "lk/datafoundation/crud-api/db/repository/postgres"
+	sqliterepository "lk/datafoundation/crud-api/db/repository/sqlite"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/geo"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/relationships"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// ErrNotFound is wrapped by the errors the stores return when an entity, table or geometry does not exist.
+// It is defined in the commons package so that the repositories can return it without importing this package.
+var ErrNotFound = commons.ErrNotFound
+
+// GraphStore stores entities and the relationships between them.
+// It is implemented by the Neo4j and SQLite repositories and by the in-memory graph store.
+type GraphStore interface {
+	RelationshipTypes() *relationships.TypeRegistry
+	Ping(ctx context.Context) error
+	Close(ctx context.Context)
+
+	ReadGraphEntity(ctx context.Context, entityID string) (map[string]interface{}, error)
+	GetGraphEntity(ctx context.Context, entityId string) (*pb.Kind, *pb.TimeBasedValue, string, string, error)
+	HandleGraphEntityCreation(ctx context.Context, entity *pb.Entity) (bool, error)
+	HandleGraphEntityUpdate(ctx context.Context, entity *pb.Entity) (bool, error)
+	HandleGraphEntityFilter(ctx context.Context, req *pb.ReadEntityRequest) ([]map[string]interface{}, error)
+
+	HandleGraphRelationshipsCreate(ctx context.Context, entity *pb.Entity) error
+	HandleGraphRelationshipsUpdate(ctx context.Context, entity *pb.Entity) error
+	GetGraphRelationships(ctx context.Context, entityId string) (map[string]*pb.Relationship, error)
+	GetGraphRelationship(ctx context.Context, relationshipId string) (*pb.EntityRelationship, error)
+	GetRelationshipsByEndpoints(ctx context.Context, sourceEntityId string, targetEntityId string, name string, activeAt string) ([]*pb.EntityRelationship, error)
+	GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, properties map[string]*anypb.Any, activeAt string) (map[string]*pb.Relationship, error)
+	ReadFilteredRelationships(ctx context.Context, entityID string, relationshipFilters map[string]interface{}, activeAt string) ([]map[string]interface{}, error)
+	UpdateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error)
+	DeleteRelationship(ctx context.Context, relationshipID string) error
+
+	// CreateIndexes creates the indexes on the IDs of the entities already stored, it is called once at startup
+	CreateIndexes(ctx context.Context) error
+	// CreateSearchIndexes creates the indexes used by SearchEntityNames, it is called once at startup
+	CreateSearchIndexes(ctx context.Context) error
+	// SearchEntityNames returns the entities of the query kinds whose name contains every term of the query
+	SearchEntityNames(ctx context.Context, query search.Query) ([]search.Match, error)
+}
+
+// MetadataStore stores entity metadata, the values of scalar, document and graph attributes and the kind
+// and relationship type registries.
+// It is implemented by the MongoDB and document repositories and by the in-memory metadata store.
+type MetadataStore interface {
+	Ping(ctx context.Context) error
+	Close(ctx context.Context) error
+
+	CreateEntity(ctx context.Context, entity *pb.Entity) error
+	// ReadEntity returns the current metadata of an entity, the error wraps ErrNotFound when there is none
+	ReadEntity(ctx context.Context, id string) (*pb.Entity, error)
+	// DeleteEntity removes the metadata of an entity and reports whether there was any
+	DeleteEntity(ctx context.Context, id string) (bool, error)
+	// HandleMetadata sets and removes metadata keys of an entity, the keys the change does not name keep their
+	// values. Every value keeps its place in the history of its key.
+	HandleMetadata(ctx context.Context, entityId string, change *metadatahistory.Change) error
+	// GetMetadata returns the current metadata of an entity, or the metadata it had at activeAt when it is set
+	GetMetadata(ctx context.Context, entityId string, activeAt string) (map[string]*anypb.Any, error)
+	// GetMetadataHistory returns the versions of a metadata key of an entity, or of every key when key is empty
+	GetMetadataHistory(ctx context.Context, entityId string, key string) ([]*pb.MetadataVersion, error)
+
+	// SaveAttributeValue stores a value of a scalar, document or graph attribute, replacing the value of the
+	// attribute with the same start time
+	SaveAttributeValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) error
+	// GetAttributeValues returns the values of an attribute ordered by start time, the error wraps ErrNotFound
+	// when the attribute has none
+	GetAttributeValues(ctx context.Context, entityID, attrName string) ([]*pb.TimeBasedValue, error)
+
+	ReadRelationshipTypes(ctx context.Context) ([]*pb.RelationshipType, error)
+	ReadKindSchemas(ctx context.Context) ([]*pb.KindSchema, error)
+
+	// CreateIndexes creates the indexes used to read the metadata history and the attribute values, it is called
+	// once at startup
+	CreateIndexes(ctx context.Context) error
+	// CreateSearchIndexes creates the indexes used by SearchMetadata, it is called once at startup
+	CreateSearchIndexes(ctx context.Context) error
+	// SearchMetadata returns the metadata values that contain every term of the query
+	SearchMetadata(ctx context.Context, query search.Query) ([]search.Match, error)
+}
+
+// TabularStore stores tabular attribute data.
+// It is implemented by the PostgreSQL and SQLite repositories and by the in-memory tabular store.
+type TabularStore interface {
+	Ping(ctx context.Context) error
+	Close() error
+
+	InitializeTables(ctx context.Context) error
+	// AttributeTable returns the table holding the rows of an attribute, or the one it will be created with
+	AttributeTable(ctx context.Context, entityID, attrName string) (string, error)
+	HandleTabularData(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, schemaInfo *schema.SchemaInfo) error
+	GetData(ctx context.Context, tableName string, filters map[string]interface{}, fields ...string) (*anypb.Any, error)
+	// GetSchemaOfTable returns the latest schema stored for a table, the error wraps ErrNotFound when there is none
+	GetSchemaOfTable(ctx context.Context, tableName string) (*schema.SchemaInfo, error)
+	// SearchTabular returns the string cells of tabular attributes that contain every term of the query
+	SearchTabular(ctx context.Context, query search.Query) ([]search.Match, error)
+	// CreateIndexes creates the index on the attribute and write time of the tables created before it was added,
+	// it is called once at startup
+	CreateIndexes(ctx context.Context) error
+	// CreateIndex creates an index on the given columns of a table unless it exists and returns its name
+	CreateIndex(ctx context.Context, tableName string, columns []string) (string, error)
+}
+
+// BlobStore stores the binary content of blob attributes addressed by the SHA-256 digest of its bytes.
+// It is implemented by the local filesystem and S3 blob stores and by the in-memory blob store.
+type BlobStore interface {
+	Ping(ctx context.Context) error
+	Close() error
+
+	// Put stores content and returns its digest and size, storing the same content twice keeps a single copy
+	Put(ctx context.Context, content io.Reader) (*blobrepository.Object, error)
+	// Open returns the content with the given digest, the error wraps os.ErrNotExist when there is none
+	Open(ctx context.Context, digest string) (io.ReadCloser, *blobrepository.Object, error)
+}
+
+// SpatialStore stores the geometries of geospatial attributes and finds the entities whose geometry matches a filter.
+// It is implemented by the PostgreSQL repository with PostGIS, by the SQLite repository with a bounding box index
+// and by the in-memory spatial store. It shares the connection of the tabular store, which opens and closes it.
+type SpatialStore interface {
+	InitializeGeometries(ctx context.Context) error
+	// SaveGeometry stores the geometry an attribute has from startTime until endTime, replacing the geometry
+	// with the same start time. A zero start time is before any other and a zero end time leaves it open.
+	SaveGeometry(ctx context.Context, entityID, attrName string, geometry *geo.Geometry, startTime, endTime time.Time) error
+	// GetGeometry returns the latest geometry of an attribute, the error wraps ErrNotFound when there is none
+	GetGeometry(ctx context.Context, entityID, attrName string) (*geo.Geometry, error)
+	// DeleteGeometry removes every geometry of an attribute
+	DeleteGeometry(ctx context.Context, entityID, attrName string) error
+	// FindEntities returns the sorted IDs of the entities whose attribute geometry at activeAt matches the filter.
+	// The geometry of an entity at a time is the latest one started by then, unless it ended before.
+	FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) ([]string, error)
+}
+
+var (
+	_ GraphStore    = (*neo4jrepository.Neo4jRepository)(nil)
+	_ GraphStore    = (*memoryrepository.GraphStore)(nil)
+	_ GraphStore    = (*sqliterepository.GraphRepository)(nil)
+	_ MetadataStore = (*mongorepository.MongoRepository)(nil)
+	_ MetadataStore = (*memoryrepository.MetadataStore)(nil)
+	_ MetadataStore = (*documentrepository.DocumentRepository)(nil)
+	_ TabularStore  = (*postgresrepository.PostgresRepository)(nil)
+	_ TabularStore  = (*memoryrepository.TabularStore)(nil)
+	_ TabularStore  = (*sqliterepository.TabularRepository)(nil)
+	_ BlobStore     = (*blobrepository.LocalStore)(nil)
+	_ BlobStore     = (*blobrepository.S3Store)(nil)
+	_ BlobStore     = (*memoryrepository.BlobStore)(nil)
+	_ SpatialStore  = (*postgresrepository.PostgresRepository)(nil)
+	_ SpatialStore  = (*sqliterepository.TabularRepository)(nil)
+	_ SpatialStore  = (*memoryrepository.SpatialStore)(nil)
+)
@@ -3,7 +3,6 @@ package dbcommons
 import (
 	"context"
 	"fmt"
-	"os"
 
 	"lk/datafoundation/crud-api/db/config"
 	mongorepository "lk/datafoundation/crud-api/db/repository/mongo"
@@ -13,23 +12,31 @@ import (
 
 // GetNeo4jConfig creates a Neo4jConfig from environment variables
 func GetNeo4jConfig() *config.Neo4jConfig {
-	return &config.Neo4jConfig{
-		URI:      os.Getenv("NEO4J_URI"),
-		Username: os.Getenv("NEO4J_USER"),
-		Password: os.Getenv("NEO4J_PASSWORD"),
-	}
+	return &config.FromEnv().Neo4j
+}
+
+// GetRelationshipIntegrityConfig creates a RelationshipIntegrityConfig from environment variables
+func GetRelationshipIntegrityConfig() config.RelationshipIntegrityConfig {
+	return config.FromEnv().Neo4j.RelationshipIntegrity
 }
 
 // GetMongoConfig creates a MongoConfig from environment variables
 func GetMongoConfig() *config.MongoConfig {
-	return &config.MongoConfig{
-		URI:        os.Getenv("MONGO_URI"),
-		DBName:     os.Getenv("MONGO_DB_NAME"),
-		Collection: os.Getenv("MONGO_COLLECTION"),
-	}
+	return &config.FromEnv().Mongo
+}
+
+// GetEmbeddedConfig creates an EmbeddedConfig from environment variables
+func GetEmbeddedConfig() *config.EmbeddedConfig {
+	return &config.FromEnv().Embedded
+}
+
+// GetBlobConfig creates a BlobConfig from environment variables
+func GetBlobConfig() *config.BlobConfig {
+	return &config.FromEnv().Blob
 }
 
 // GetNeo4jRepository retrieves a Neo4j repository
+// Each call opens a new connection, long running services should share a Repositories instead
 func GetNeo4jRepository(ctx context.Context) (*neo4jrepository.Neo4jRepository, error) {
 	cfg := GetNeo4jConfig()
 	repo, err := neo4jrepository.NewNeo4jRepository(ctx, cfg)
@@ -40,25 +47,49 @@ func GetNeo4jRepository(ctx context.Context) (*neo4jrepository.Neo4jRepository,
 }
 
 // GetMongoRepository retrieves a Mongo repository
-// TODO: Handle errors better
-func GetMongoRepository(ctx context.Context) *mongorepository.MongoRepository {
+// Each call opens a new connection, long running services should share a Repositories instead
+func GetMongoRepository(ctx context.Context) (*mongorepository.MongoRepository, error) {
 	cfg := GetMongoConfig()
-	return mongorepository.NewMongoRepository(ctx, cfg)
+	repo, err := mongorepository.NewMongoRepository(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("[Commons] failed to create Mongo repository: %w", err)
+	}
+	return repo, nil
 }
 
 // GetPostgresConfig creates a PostgresConfig from environment variables
 func GetPostgresConfig() postgresrepository.Config {
+	return PostgresRepositoryConfig(config.FromEnv().Postgres)
+}
+
+// PostgresRepositoryConfig converts the PostgreSQL section of the service configuration
+// into the configuration of the Postgres repository
+func PostgresRepositoryConfig(cfg config.PostgresConfig) postgresrepository.Config {
+	// The TLS section takes precedence over ssl_mode, the driver reads the certificate files itself
+	sslMode := cfg.SSLMode
+	if cfg.TLS.Mode != "" {
+		sslMode = cfg.TLS.Mode
+	}
 	return postgresrepository.Config{
-		Host:     os.Getenv("POSTGRES_HOST"),
-		Port:     os.Getenv("POSTGRES_PORT"),
-		User:     os.Getenv("POSTGRES_USER"),
-		Password: os.Getenv("POSTGRES_PASSWORD"),
-		DBName:   os.Getenv("POSTGRES_DB"),
-		SSLMode:  os.Getenv("POSTGRES_SSL_MODE"),
+		Host:        cfg.Host,
+		Port:        cfg.Port,
+		User:        cfg.User,
+		Password:    cfg.Password,
+		DBName:      cfg.DBName,
+		SSLMode:     sslMode,
+		SSLRootCert: cfg.TLS.CAFile,
+		SSLCert:     cfg.TLS.CertFile,
+		SSLKey:      cfg.TLS.KeyFile,
+		Pool: postgresrepository.PoolConfig{
+			MaxOpenConns:    cfg.MaxOpenConns,
+			MaxIdleConns:    cfg.MaxIdleConns,
+			ConnMaxLifetime: cfg.ConnMaxLifetime,
+		},
 	}
 }
 
 // GetPostgresRepository retrieves a Postgres repository
+// Each call opens a new connection, long running services should share a Repositories instead
 func GetPostgresRepository(ctx context.Context) (*postgresrepository.PostgresRepository, error) {
 	cfg := GetPostgresConfig()
 	repo, err := postgresrepository.NewPostgresRepository(cfg)
@@ -0,0 +1,7 @@
+package commons
+
+import "errors"
+
+// ErrNotFound is wrapped by the errors the stores return when an entity, relationship, table or geometry does
+// not exist, so that callers can tell a missing record from a storage failure with errors.Is
+var ErrNotFound = errors.New("not found")
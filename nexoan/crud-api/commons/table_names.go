@@ -0,0 +1,95 @@
+package commons
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// attributeTablePrefix starts the name of every attribute table named after its entity and attribute
+	attributeTablePrefix = "attr_"
+	// hashedTablePrefix starts the name of the attribute tables whose names would be too long
+	hashedTablePrefix = "attrh_"
+	// tableNameSeparator separates the entity ID from the attribute name, an escape never starts with it
+	tableNameSeparator = "__"
+	// maxTableNameLength is the longest identifier PostgreSQL keeps without truncating it
+	maxTableNameLength = 63
+)
+
+// escapeName keeps lowercase letters and digits and writes every other byte as an underscore followed by two
+// hex digits, so different names are always escaped differently
+func escapeName(name string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			escaped.WriteByte(c)
+		} else {
+			escaped.WriteByte('_')
+			escaped.WriteString(hex.EncodeToString([]byte{c}))
+		}
+	}
+	return escaped.String()
+}
+
+// unescapeName reverses escapeName, it returns false when the name is not escaped
+func unescapeName(escaped string) (string, bool) {
+	var name strings.Builder
+	for i := 0; i < len(escaped); i++ {
+		c := escaped[i]
+		if c != '_' {
+			name.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(escaped) {
+			return "", false
+		}
+		decoded, err := hex.DecodeString(escaped[i+1 : i+3])
+		if err != nil {
+			return "", false
+		}
+		name.Write(decoded)
+		i += 2
+	}
+	return name.String(), true
+}
+
+// AttributeTableName returns the name of the table created for the rows of a tabular attribute.
+// The entity ID and attribute name are escaped and joined by a double underscore, so distinct attributes
+// never share a table and ParseAttributeTableName reads both back. A name longer than PostgreSQL keeps is
+// replaced by a digest of the two, which is read back from the entity_attributes table instead.
+func AttributeTableName(entityID, attrName string) string {
+	name := attributeTablePrefix + escapeName(entityID) + tableNameSeparator + escapeName(attrName)
+	if len(name) <= maxTableNameLength {
+		return name
+	}
+	digest := sha256.Sum256([]byte(entityID + "\x00" + attrName))
+	return hashedTablePrefix + hex.EncodeToString(digest[:16])
+}
+
+// ParseAttributeTableName returns the entity ID and attribute name of a table named by AttributeTableName.
+// It returns false for digest names and for tables named before AttributeTableName existed.
+func ParseAttributeTableName(tableName string) (entityID, attrName string, ok bool) {
+	escaped, found := strings.CutPrefix(tableName, attributeTablePrefix)
+	if !found {
+		return "", "", false
+	}
+	// An escape is an underscore followed by two hex digits, so the first underscore that does not start an
+	// escape is the separator
+	for i := 0; i+1 < len(escaped); i++ {
+		if escaped[i] != '_' {
+			continue
+		}
+		if escaped[i+1] == '_' {
+			entityID, entityOK := unescapeName(escaped[:i])
+			attrName, attrOK := unescapeName(escaped[i+2:])
+			if !entityOK || !attrOK || AttributeTableName(entityID, attrName) != tableName {
+				return "", "", false
+			}
+			return entityID, attrName, true
+		}
+		i += 2
+	}
+	return "", "", false
+}
@@ -0,0 +1,57 @@
+package commons
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var tableNamePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func TestAttributeTableName(t *testing.T) {
+	assert.Equal(t, "attr_org_2d1__budget_2d2020", AttributeTableName("org-1", "budget-2020"))
+	assert.NotEqual(t, AttributeTableName("org-1", "Budget-2020"), AttributeTableName("org-1", "budget_2020"))
+	assert.NotEqual(t, AttributeTableName("org_1", "x"), AttributeTableName("org", "1_x"))
+
+	for _, names := range [][2]string{{"org-1", "Budget-2020"}, {"org_1", "budget_2020"}, {"a__b", "__"}, {"", ""}} {
+		entityID, attrName, ok := ParseAttributeTableName(AttributeTableName(names[0], names[1]))
+		assert.True(t, ok, names)
+		assert.Equal(t, names[0], entityID)
+		assert.Equal(t, names[1], attrName)
+	}
+
+	long := AttributeTableName("org-1", strings.Repeat("Expenditure ", 10))
+	assert.True(t, strings.HasPrefix(long, hashedTablePrefix))
+	assert.LessOrEqual(t, len(long), maxTableNameLength)
+	assert.NotEqual(t, long, AttributeTableName("org-1", strings.Repeat("expenditure ", 10)))
+	_, _, ok := ParseAttributeTableName(long)
+	assert.False(t, ok)
+
+	// Tables named by SanitizeIdentifier before the escaped names are not parsed
+	for _, legacy := range []string{"attr_org_1_budget_2020", "attr_org_1_x", "employees"} {
+		_, _, ok := ParseAttributeTableName(legacy)
+		assert.False(t, ok, legacy)
+	}
+}
+
+func FuzzAttributeTableName(f *testing.F) {
+	f.Add("org-1", "Budget-2020")
+	f.Add("org_1", "budget_2020")
+	f.Add("x'; DROP TABLE entity_attributes; --", "\"")
+	f.Add("__", "_2d")
+	f.Fuzz(func(t *testing.T, entityID, attrName string) {
+		name := AttributeTableName(entityID, attrName)
+		if !tableNamePattern.MatchString(name) || len(name) > maxTableNameLength {
+			t.Fatalf("table name %q is not a plain identifier", name)
+		}
+		if strings.HasPrefix(name, hashedTablePrefix) {
+			return
+		}
+		parsedEntityID, parsedAttrName, ok := ParseAttributeTableName(name)
+		if !ok || parsedEntityID != entityID || parsedAttrName != attrName {
+			t.Fatalf("table name %q reads back as %q, %q", name, parsedEntityID, parsedAttrName)
+		}
+	})
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
 	"lk/datafoundation/crud-api/pkg/storageinference"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -54,6 +54,10 @@ func ConvertStorageTypeStringToEnum(storageTypeStr string) storageinference.Stor
 		return storageinference.ListData
 	case "scalar":
 		return storageinference.ScalarData
+	case "blob":
+		return storageinference.BlobData
+	case "geospatial":
+		return storageinference.GeospatialData
 	default:
 		return storageinference.UnknownData
 	}
@@ -74,8 +78,6 @@ func ExtractAttributeMetadataFields(entity *pb.Entity) (storageTypeStr, storageP
 	updatedStr = ExtractStringFromAny(metadataMap["updated"])
 	schemaStr := ExtractStringFromAny(metadataMap["schema"])
 
-	log.Printf("[Commons.ExtractAttributeMetadataFields] storageTypeStr: %s, storagePath: %s, updatedStr: %s, schemaStr: %s", storageTypeStr, storagePath, updatedStr, schemaStr)
-
 	// Convert schema JSON string to map
 	schemaMap, err := ConvertJSONStringToMap(schemaStr)
 	if err != nil {
@@ -149,15 +151,16 @@ func ParseTimestamp(timestampStr string, context string) time.Time {
 
 	parsed, err := time.Parse(time.RFC3339, timestampStr)
 	if err != nil {
-		log.Printf("[Commons.ParseTimestamp] Warning: Failed to parse timestamp '%s' for %s, using zero value", timestampStr, context)
+		slog.Warn("Failed to parse timestamp, using zero value", "timestamp", timestampStr, "field", context)
 		return time.Time{} // Return zero value for invalid timestamps
 	}
 
 	return parsed
 }
 
-// SanitizeIdentifier makes a string safe for use as a PostgreSQL identifier
-// IMPROVEME: https://github.com/LDFLK/nexoan/issues/160
+// SanitizeIdentifier makes a string safe for use as an unquoted PostgreSQL identifier. Distinct strings can be
+// sanitized to the same identifier, so it is only used to find the columns of attribute tables created before
+// column names were quoted.
 func SanitizeIdentifier(s string) string {
 	// Replace invalid characters with underscores
 	safe := strings.Map(func(r rune) rune {
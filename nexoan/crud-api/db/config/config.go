@@ -1,22 +1,198 @@
 package config
 
+import "time"
+
+// Config is the complete configuration of the CRUD service.
+// It is read from an optional YAML or TOML file and every field can be overridden by the
+// environment variable named in its env tag, see Load.
+type Config struct {
+	// Storage selects the storage backend: database, embedded or memory
+	Storage string `env:"CRUD_STORAGE" yaml:"storage" toml:"storage"`
+
+	Server   ServerConfig   `yaml:"server" toml:"server"`
+	Mongo    MongoConfig    `yaml:"mongo" toml:"mongo"`
+	Neo4j    Neo4jConfig    `yaml:"neo4j" toml:"neo4j"`
+	Postgres PostgresConfig `yaml:"postgres" toml:"postgres"`
+	Embedded EmbeddedConfig `yaml:"embedded" toml:"embedded"`
+	Blob     BlobConfig     `yaml:"blob" toml:"blob"`
+	Tracing  TracingConfig  `yaml:"tracing" toml:"tracing"`
+	Logging  LoggingConfig  `yaml:"logging" toml:"logging"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+}
+
+// ServerConfig holds the address the gRPC server listens on and its health, shutdown and metrics settings
+type ServerConfig struct {
+	Host string `env:"CRUD_SERVICE_HOST" yaml:"host" toml:"host"`
+	Port int    `env:"CRUD_SERVICE_PORT" yaml:"port" toml:"port"`
+
+	// HealthCheckInterval is how often the stores are pinged to update the health service, defaults to 10 seconds
+	HealthCheckInterval time.Duration `env:"CRUD_HEALTH_CHECK_INTERVAL" yaml:"health_check_interval" toml:"health_check_interval"`
+	// ShutdownTimeout bounds how long in-flight RPCs are drained on shutdown, defaults to 30 seconds
+	ShutdownTimeout time.Duration `env:"CRUD_SHUTDOWN_TIMEOUT" yaml:"shutdown_timeout" toml:"shutdown_timeout"`
+	// MetricsPort is the port of the HTTP server exposing the Prometheus metrics on /metrics, 0 disables it
+	MetricsPort int `env:"CRUD_METRICS_PORT" yaml:"metrics_port" toml:"metrics_port"`
+
+	TLS ServerTLSConfig `yaml:"tls" toml:"tls"`
+}
+
+// ServerTLSConfig enables TLS on the gRPC listener when a certificate and key are set
+type ServerTLSConfig struct {
+	CertFile string `env:"CRUD_TLS_CERT_FILE" yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `env:"CRUD_TLS_KEY_FILE" yaml:"key_file" toml:"key_file"`
+	// ClientCAFile is a PEM bundle of the CAs trusted to sign client certificates
+	ClientCAFile string `env:"CRUD_TLS_CLIENT_CA_FILE" yaml:"client_ca_file" toml:"client_ca_file"`
+	// ClientAuth is none, optional (verify a client certificate when one is presented) or require,
+	// defaults to require when a client CA bundle is set and none otherwise
+	ClientAuth string `env:"CRUD_TLS_CLIENT_AUTH" yaml:"client_auth" toml:"client_auth"`
+	// MinVersion is the lowest TLS version accepted: 1.2 or 1.3, defaults to 1.2
+	MinVersion string `env:"CRUD_TLS_MIN_VERSION" yaml:"min_version" toml:"min_version"`
+}
+
+// TLSConfig holds the TLS settings of the connection to a store. The environment variables of its fields
+// are prefixed with the name of the store, such as MONGO_TLS_MODE.
+type TLSConfig struct {
+	// Mode is disable, require (encrypt without verifying the server), verify-ca (verify that the server
+	// certificate is signed by a trusted CA) or verify-full (also verify the host name), defaults to disable
+	Mode string `env:"MODE" yaml:"mode" toml:"mode"`
+	// CAFile is a PEM bundle of the CAs trusted to sign the server certificate, the system pool is used when empty
+	CAFile string `env:"CA_FILE" yaml:"ca_file" toml:"ca_file"`
+	// CertFile and KeyFile are the client certificate presented to stores requiring one
+	CertFile string `env:"CERT_FILE" yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `env:"KEY_FILE" yaml:"key_file" toml:"key_file"`
+}
+
+// Enabled reports whether connections to the store are encrypted
+func (c TLSConfig) Enabled() bool {
+	return c.Mode != "" && c.Mode != "disable"
+}
+
 type MongoConfig struct {
-	URI        string `env:"MONGO_URI"`
-	DBName     string `env:"MONGO_DB_NAME"`
-	Collection string `env:"MONGO_COLLECTION"`
+	URI        string `env:"MONGO_URI" yaml:"uri" toml:"uri"`
+	DBName     string `env:"MONGO_DB_NAME" yaml:"db_name" toml:"db_name"`
+	Collection string `env:"MONGO_COLLECTION" yaml:"collection" toml:"collection"`
+
+	// RelationshipTypesCollection holds the relationship type registry, defaults to relationship_types
+	RelationshipTypesCollection string `env:"MONGO_RELATIONSHIP_TYPES_COLLECTION" yaml:"relationship_types_collection" toml:"relationship_types_collection"`
+	// KindSchemasCollection holds the kind registry, defaults to kind_schemas
+	KindSchemasCollection string `env:"MONGO_KIND_SCHEMAS_COLLECTION" yaml:"kind_schemas_collection" toml:"kind_schemas_collection"`
+	// MetadataHistoryCollection holds the metadata values that are no longer current, defaults to metadata_history
+	MetadataHistoryCollection string `env:"MONGO_METADATA_HISTORY_COLLECTION" yaml:"metadata_history_collection" toml:"metadata_history_collection"`
+	// AttributeValuesCollection holds the values of scalar, document and graph attributes, defaults to attribute_values
+	AttributeValuesCollection string `env:"MONGO_ATTRIBUTE_VALUES_COLLECTION" yaml:"attribute_values_collection" toml:"attribute_values_collection"`
+
+	// MaxPoolSize and MinPoolSize bound the connection pool of the client, zero keeps the driver default
+	MaxPoolSize uint64 `env:"MONGO_MAX_POOL_SIZE" yaml:"max_pool_size" toml:"max_pool_size"`
+	MinPoolSize uint64 `env:"MONGO_MIN_POOL_SIZE" yaml:"min_pool_size" toml:"min_pool_size"`
+
+	TLS TLSConfig `env_prefix:"MONGO_TLS_" yaml:"tls" toml:"tls"`
 }
 
 type Neo4jConfig struct {
-	URI      string `env:"NEO4J_URI"`
-	Username string `env:"NEO4J_USER"`
-	Password string `env:"NEO4J_PASSWORD"`
+	URI      string `env:"NEO4J_URI" yaml:"uri" toml:"uri"`
+	Username string `env:"NEO4J_USER" yaml:"username" toml:"username"`
+	Password string `env:"NEO4J_PASSWORD" yaml:"password" toml:"password"`
+
+	// MaxConnectionPoolSize bounds the connection pool of the driver, zero keeps the driver default
+	MaxConnectionPoolSize int `env:"NEO4J_MAX_CONNECTION_POOL_SIZE" yaml:"max_connection_pool_size" toml:"max_connection_pool_size"`
+
+	// TLS selects the neo4j+s or neo4j+ssc scheme matching its mode, the URI must then use a plain neo4j or bolt scheme
+	TLS TLSConfig `env_prefix:"NEO4J_TLS_" yaml:"tls" toml:"tls"`
+
+	RelationshipIntegrity RelationshipIntegrityConfig `yaml:"relationship_integrity" toml:"relationship_integrity"`
+}
+
+// RelationshipIntegrityConfig holds the integrity rules enforced when relationships are created or updated.
+// All rules are disabled by default.
+type RelationshipIntegrityConfig struct {
+	// EnforceEndpointLifetimes requires a relationship interval to lie within the lifetimes of both endpoints
+	EnforceEndpointLifetimes bool `env:"RELATIONSHIP_ENFORCE_ENDPOINT_LIFETIMES" yaml:"enforce_endpoint_lifetimes" toml:"enforce_endpoint_lifetimes"`
+	// NonOverlappingTypes lists relationship types for which two relationships between the same
+	// pair of entities cannot overlap in time
+	NonOverlappingTypes []string `env:"RELATIONSHIP_NON_OVERLAPPING_TYPES" yaml:"non_overlapping_types" toml:"non_overlapping_types"`
+	// SingleValuedTypes lists relationship types for which an entity can have at most one
+	// outgoing relationship active at any instant
+	SingleValuedTypes []string `env:"RELATIONSHIP_SINGLE_VALUED_TYPES" yaml:"single_valued_types" toml:"single_valued_types"`
+}
+
+// EmbeddedConfig holds the configuration of the embedded storage backend, which keeps
+// the graph and tabular data in SQLite and the metadata in a JSON document store
+type EmbeddedConfig struct {
+	// DataDir is the directory holding the database files, defaults to data
+	DataDir string `env:"EMBEDDED_DATA_DIR" yaml:"data_dir" toml:"data_dir"`
+}
+
+// BlobConfig holds the configuration of the store keeping the content of blob attributes
+type BlobConfig struct {
+	// Dir is the directory holding blob content in the database storage backend, defaults to blobs.
+	// The embedded backend keeps blob content in the blobs directory of its data directory.
+	Dir string `env:"CRUD_BLOB_DIR" yaml:"dir" toml:"dir"`
+	// MaxSize is the largest content in bytes UploadBlob accepts, defaults to 256 MiB
+	MaxSize int `env:"CRUD_BLOB_MAX_SIZE" yaml:"max_size" toml:"max_size"`
 }
 
 type PostgresConfig struct {
-	Host     string `env:"POSTGRES_HOST"`
-	Port     string `env:"POSTGRES_PORT"`
-	User     string `env:"POSTGRES_USER"`
-	Password string `env:"POSTGRES_PASSWORD"`
-	DBName   string `env:"POSTGRES_DB"`
-	SSLMode  string `env:"POSTGRES_SSL_MODE"`
+	Host     string `env:"POSTGRES_HOST" yaml:"host" toml:"host"`
+	Port     string `env:"POSTGRES_PORT" yaml:"port" toml:"port"`
+	User     string `env:"POSTGRES_USER" yaml:"user" toml:"user"`
+	Password string `env:"POSTGRES_PASSWORD" yaml:"password" toml:"password"`
+	DBName   string `env:"POSTGRES_DB" yaml:"db_name" toml:"db_name"`
+	// SSLMode is passed to the driver as sslmode when tls.mode is not set
+	SSLMode string `env:"POSTGRES_SSL_MODE" yaml:"ssl_mode" toml:"ssl_mode"`
+
+	// Connection pool settings, they default to 25 open and idle connections recycled every 5 minutes
+	MaxOpenConns    int           `env:"POSTGRES_MAX_OPEN_CONNS" yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `env:"POSTGRES_MAX_IDLE_CONNS" yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME" yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+
+	// TLS is passed to the driver as the sslmode, sslrootcert, sslcert and sslkey parameters
+	TLS TLSConfig `env_prefix:"POSTGRES_TLS_" yaml:"tls" toml:"tls"`
+}
+
+// TracingConfig holds the OpenTelemetry tracing settings, tracing is disabled by default
+type TracingConfig struct {
+	// Exporter selects where spans are sent: none, otlp or file
+	Exporter string `env:"CRUD_TRACING_EXPORTER" yaml:"exporter" toml:"exporter"`
+	// Endpoint is the host:port of the OTLP gRPC collector, defaults to localhost:4317
+	Endpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" yaml:"endpoint" toml:"endpoint"`
+	// Insecure disables TLS on the connection to the OTLP collector
+	Insecure bool `env:"CRUD_TRACING_INSECURE" yaml:"insecure" toml:"insecure"`
+	// FilePath is the file spans are written to as JSON by the file exporter
+	FilePath string `env:"CRUD_TRACING_FILE" yaml:"file_path" toml:"file_path"`
+	// ServiceName is reported as the service.name resource attribute, defaults to crud-api
+	ServiceName string `env:"OTEL_SERVICE_NAME" yaml:"service_name" toml:"service_name"`
+	// SampleRatio is the fraction of new traces that are recorded, traces started by a caller follow its decision
+	SampleRatio float64 `env:"CRUD_TRACING_SAMPLE_RATIO" yaml:"sample_ratio" toml:"sample_ratio"`
+}
+
+// LoggingConfig holds the settings of the structured logger
+type LoggingConfig struct {
+	// Level is the minimum level logged: debug, info, warn or error, defaults to info.
+	// It can be changed while the service runs through the metrics server when LevelEndpoint is set.
+	Level string `env:"CRUD_LOG_LEVEL" yaml:"level" toml:"level"`
+	// LevelEndpoint serves /debug/loglevel on the metrics server, it is unauthenticated and off by default
+	LevelEndpoint bool `env:"CRUD_LOG_LEVEL_ENDPOINT" yaml:"level_endpoint" toml:"level_endpoint"`
+	// Format selects the output format: text or json, defaults to text
+	Format string `env:"CRUD_LOG_FORMAT" yaml:"format" toml:"format"`
+	// Payloads enables logging of entities, attribute values and query results, which can hold personal data
+	Payloads bool `env:"CRUD_LOG_PAYLOADS" yaml:"payloads" toml:"payloads"`
+}
+
+// AuthConfig holds the authentication and authorization settings of the CrudService RPCs.
+// Authentication is disabled when no method is listed.
+type AuthConfig struct {
+	// Methods lists the accepted credentials: mtls and jwt
+	Methods []string `env:"CRUD_AUTH_METHODS" yaml:"methods" toml:"methods"`
+	// AllowAnonymous lets callers without credentials through as the anonymous subject, the policy still applies
+	AllowAnonymous bool `env:"CRUD_AUTH_ALLOW_ANONYMOUS" yaml:"allow_anonymous" toml:"allow_anonymous"`
+
+	// JWKSFile is the JSON Web Key Set holding the keys bearer tokens are signed with
+	JWKSFile string `env:"CRUD_AUTH_JWKS_FILE" yaml:"jwks_file" toml:"jwks_file"`
+	// Issuer and Audience are checked against the iss and aud claims of bearer tokens when set
+	Issuer   string `env:"CRUD_AUTH_ISSUER" yaml:"issuer" toml:"issuer"`
+	Audience string `env:"CRUD_AUTH_AUDIENCE" yaml:"audience" toml:"audience"`
+	// RolesClaim is the token claim holding the roles of the caller, defaults to roles
+	RolesClaim string `env:"CRUD_AUTH_ROLES_CLAIM" yaml:"roles_claim" toml:"roles_claim"`
+
+	// PolicyFile is the YAML authorization policy, every authenticated caller is allowed everything without one
+	PolicyFile string `env:"CRUD_AUTH_POLICY_FILE" yaml:"policy_file" toml:"policy_file"`
 }
@@ -0,0 +1,443 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// maskedSecret replaces secrets when a configuration is printed, it matches the mask used by url.URL.Redacted
+const maskedSecret = "xxxxx"
+
+// Default returns the configuration used when neither a file nor the environment set a value
+func Default() *Config {
+	return &Config{
+		Storage: "database",
+		Server: ServerConfig{
+			Host:                "0.0.0.0",
+			Port:                50051,
+			HealthCheckInterval: 10 * time.Second,
+			ShutdownTimeout:     30 * time.Second,
+			MetricsPort:         9090,
+		},
+		Mongo: MongoConfig{
+			RelationshipTypesCollection: "relationship_types",
+			KindSchemasCollection:       "kind_schemas",
+			MetadataHistoryCollection:   "metadata_history",
+			AttributeValuesCollection:   "attribute_values",
+		},
+		Postgres: PostgresConfig{
+			Port:            "5432",
+			MaxOpenConns:    25,
+			MaxIdleConns:    25,
+			ConnMaxLifetime: 5 * time.Minute,
+		},
+		Embedded: EmbeddedConfig{
+			DataDir: "data",
+		},
+		Blob: BlobConfig{
+			Dir:     "blobs",
+			MaxSize: 256 << 20,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4317",
+			ServiceName: "crud-api",
+			SampleRatio: 1,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Auth: AuthConfig{
+			RolesClaim: "roles",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the file at path and the environment, in that order
+// of precedence. The file is YAML or TOML depending on its extension, an empty path skips it.
+// Load does not validate the result, see Validate.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		if err := readFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// FromEnv builds the configuration from the defaults and the environment only.
+// Malformed values are reported and left at their default.
+func FromEnv() *Config {
+	cfg, err := Load("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[config.FromEnv] %v\n", err)
+		cfg = Default()
+	}
+	return cfg
+}
+
+// readFile decodes a YAML or TOML file into cfg, unknown keys are rejected so that typos are not silently ignored
+func readFile(path string, cfg *Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file %s: %v", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("error decoding config file %s: %v", path, err)
+		}
+	case ".toml":
+		metadata, err := toml.Decode(string(content), cfg)
+		if err != nil {
+			return fmt.Errorf("error decoding config file %s: %v", path, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("error decoding config file %s: unknown keys %v", path, undecoded)
+		}
+	default:
+		return fmt.Errorf("unsupported config file %s, expected a .yaml, .yml or .toml file", path)
+	}
+	return nil
+}
+
+// applyEnv overrides the fields of a struct with the environment variables named in their env tags.
+// The names are prefixed with the env_prefix tags of the enclosing structs, so that a struct can be shared.
+// Empty variables are ignored so that an exported but unset variable does not clear a value from the file.
+func applyEnv(v reflect.Value, prefix string) error {
+	var errs []error
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		structField := v.Type().Field(i)
+
+		name, ok := structField.Tag.Lookup("env")
+		if !ok {
+			if field.Kind() == reflect.Struct {
+				if err := applyEnv(field, prefix+structField.Tag.Get("env_prefix")); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+
+		name = prefix + name
+		value := strings.TrimSpace(os.Getenv(name))
+		if value == "" {
+			continue
+		}
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %v", value, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setField parses value into a field of one of the types used by the configuration
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Slice:
+		// Lists are given as comma separated values
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		field.Set(reflect.ValueOf(values))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// validSSLModes are the sslmode values accepted by the PostgreSQL driver
+var validSSLModes = map[string]bool{
+	"":            true,
+	"disable":     true,
+	"allow":       true,
+	"prefer":      true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// validNeo4jSchemes are the URI schemes accepted by the Neo4j driver
+var validNeo4jSchemes = map[string]bool{
+	"neo4j":      true,
+	"neo4j+s":    true,
+	"neo4j+ssc":  true,
+	"bolt":       true,
+	"bolt+s":     true,
+	"bolt+ssc":   true,
+	"bolt+unix":  true,
+	"neo4j+unix": true,
+}
+
+// Validate checks the configuration and reports every problem at once.
+// The database settings are only required when the database backend is selected.
+func (c *Config) Validate() error {
+	var errs []error
+	addError := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		addError("server.port must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.HealthCheckInterval <= 0 {
+		addError("server.health_check_interval must be positive")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		addError("server.shutdown_timeout must be positive")
+	}
+	if c.Server.MetricsPort < 0 || c.Server.MetricsPort > 65535 {
+		addError("server.metrics_port must be between 0 and 65535, got %d", c.Server.MetricsPort)
+	} else if c.Server.MetricsPort == c.Server.Port {
+		addError("server.metrics_port must differ from server.port")
+	}
+	if (c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == "") {
+		addError("server.tls.cert_file and server.tls.key_file must be set together")
+	}
+	if c.Server.TLS.ClientCAFile != "" && c.Server.TLS.CertFile == "" {
+		addError("server.tls.client_ca_file requires server.tls.cert_file and server.tls.key_file")
+	}
+	switch c.Server.TLS.ClientAuth {
+	case "", "none":
+	case "optional", "require":
+		if c.Server.TLS.ClientCAFile == "" {
+			addError("server.tls.client_auth %s requires server.tls.client_ca_file", c.Server.TLS.ClientAuth)
+		}
+	default:
+		addError("server.tls.client_auth must be none, optional or require, got %q", c.Server.TLS.ClientAuth)
+	}
+	switch c.Server.TLS.MinVersion {
+	case "", "1.2", "1.3":
+	default:
+		addError("server.tls.min_version must be 1.2 or 1.3, got %q", c.Server.TLS.MinVersion)
+	}
+
+	if c.Blob.MaxSize < 1 {
+		addError("blob.max_size must be at least 1")
+	}
+
+	switch c.Storage {
+	case "database":
+		if c.Mongo.URI == "" {
+			addError("mongo.uri is required")
+		} else if uri, err := url.Parse(c.Mongo.URI); err != nil || (uri.Scheme != "mongodb" && uri.Scheme != "mongodb+srv") {
+			addError("mongo.uri must be a mongodb:// or mongodb+srv:// URI")
+		}
+		if c.Mongo.DBName == "" {
+			addError("mongo.db_name is required")
+		}
+		if c.Mongo.Collection == "" {
+			addError("mongo.collection is required")
+		}
+		if c.Mongo.MaxPoolSize > 0 && c.Mongo.MinPoolSize > c.Mongo.MaxPoolSize {
+			addError("mongo.min_pool_size (%d) cannot exceed mongo.max_pool_size (%d)", c.Mongo.MinPoolSize, c.Mongo.MaxPoolSize)
+		}
+		errs = append(errs, c.Mongo.TLS.validate("mongo.tls")...)
+
+		if c.Neo4j.URI == "" {
+			addError("neo4j.uri is required")
+		} else if uri, err := url.Parse(c.Neo4j.URI); err != nil || !validNeo4jSchemes[uri.Scheme] {
+			addError("neo4j.uri must be a neo4j:// or bolt:// URI")
+		}
+		if c.Neo4j.MaxConnectionPoolSize < 0 {
+			addError("neo4j.max_connection_pool_size cannot be negative")
+		}
+		errs = append(errs, c.Neo4j.TLS.validate("neo4j.tls")...)
+		if uri, err := url.Parse(c.Neo4j.URI); err == nil && c.Neo4j.TLS.Enabled() && strings.Contains(uri.Scheme, "+") {
+			addError("neo4j.uri must use the neo4j or bolt scheme when neo4j.tls.mode is set, got %s", uri.Scheme)
+		}
+
+		if c.Postgres.Host == "" {
+			addError("postgres.host is required")
+		}
+		if port, err := strconv.Atoi(c.Postgres.Port); err != nil || port < 1 || port > 65535 {
+			addError("postgres.port must be between 1 and 65535, got %q", c.Postgres.Port)
+		}
+		if c.Postgres.User == "" {
+			addError("postgres.user is required")
+		}
+		if c.Postgres.DBName == "" {
+			addError("postgres.db_name is required")
+		}
+		if !validSSLModes[c.Postgres.SSLMode] {
+			addError("postgres.ssl_mode %q is not a valid sslmode", c.Postgres.SSLMode)
+		}
+		if c.Postgres.MaxOpenConns < 1 {
+			addError("postgres.max_open_conns must be at least 1")
+		}
+		if c.Postgres.MaxIdleConns < 0 || c.Postgres.MaxIdleConns > c.Postgres.MaxOpenConns {
+			addError("postgres.max_idle_conns must be between 0 and postgres.max_open_conns")
+		}
+		if c.Postgres.ConnMaxLifetime < 0 {
+			addError("postgres.conn_max_lifetime cannot be negative")
+		}
+		errs = append(errs, c.Postgres.TLS.validate("postgres.tls")...)
+		if c.Blob.Dir == "" {
+			addError("blob.dir is required")
+		}
+	case "embedded":
+		if c.Embedded.DataDir == "" {
+			addError("embedded.data_dir is required")
+		}
+	case "memory":
+	default:
+		addError("storage must be database, embedded or memory, got %q", c.Storage)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none":
+	case "otlp":
+		if c.Tracing.Endpoint == "" {
+			addError("tracing.endpoint is required by the otlp exporter")
+		}
+	case "file":
+		if c.Tracing.FilePath == "" {
+			addError("tracing.file_path is required by the file exporter")
+		}
+	default:
+		addError("tracing.exporter must be none, otlp or file, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		addError("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		addError("logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	switch strings.ToLower(c.Logging.Format) {
+	case "text", "json":
+	default:
+		addError("logging.format must be text or json, got %q", c.Logging.Format)
+	}
+
+	for _, method := range c.Auth.Methods {
+		switch method {
+		case "mtls":
+			if c.Server.TLS.ClientCAFile == "" || c.Server.TLS.ClientAuth == "none" {
+				addError("auth method mtls requires server.tls.client_ca_file and client certificates")
+			}
+		case "jwt":
+			if c.Auth.JWKSFile == "" {
+				addError("auth method jwt requires auth.jwks_file")
+			}
+		default:
+			addError("auth.methods must only contain mtls or jwt, got %q", method)
+		}
+	}
+	if c.Auth.PolicyFile != "" && len(c.Auth.Methods) == 0 && !c.Auth.AllowAnonymous {
+		addError("auth.policy_file requires auth.methods or auth.allow_anonymous")
+	}
+
+	return errors.Join(errs...)
+}
+
+// validTLSModes are the verification modes of the connections to the stores
+var validTLSModes = map[string]bool{
+	"":            true,
+	"disable":     true,
+	"require":     true,
+	"verify-ca":   true,
+	"verify-full": true,
+}
+
+// validate checks the TLS settings of a store, section is the name used in the errors
+func (c TLSConfig) validate(section string) []error {
+	var errs []error
+	if !validTLSModes[c.Mode] {
+		errs = append(errs, fmt.Errorf("%s.mode must be disable, require, verify-ca or verify-full, got %q", section, c.Mode))
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("%s.cert_file and %s.key_file must be set together", section, section))
+	}
+	if !c.Enabled() && (c.CAFile != "" || c.CertFile != "") {
+		errs = append(errs, fmt.Errorf("%s.mode must be set to use %s.ca_file or %s.cert_file", section, section, section))
+	}
+	return errs
+}
+
+// Masked returns a copy of the configuration with passwords and URI credentials replaced, safe to print
+func (c *Config) Masked() *Config {
+	masked := *c
+	if masked.Neo4j.Password != "" {
+		masked.Neo4j.Password = maskedSecret
+	}
+	if masked.Postgres.Password != "" {
+		masked.Postgres.Password = maskedSecret
+	}
+	masked.Mongo.URI = maskURI(masked.Mongo.URI)
+	masked.Neo4j.URI = maskURI(masked.Neo4j.URI)
+	return &masked
+}
+
+// maskURI hides the password of a URI, values that cannot be parsed are hidden completely
+func maskURI(value string) string {
+	if value == "" {
+		return value
+	}
+	uri, err := url.Parse(value)
+	if err != nil {
+		return maskedSecret
+	}
+	return uri.Redacted()
+}
@@ -0,0 +1,165 @@
+// Package blobrepository stores the binary content of blob attributes, such as PDF gazettes and images,
+// addressed by the SHA-256 digest of their bytes. Storing the same content twice keeps a single copy.
+// LocalStore keeps the content in a directory and S3Store in a bucket of an S3-compatible object store.
+package blobrepository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// digestPrefix is the algorithm prefix of every digest
+const digestPrefix = "sha256:"
+
+// Object describes stored content
+type Object struct {
+	Digest string // sha256:<hex> digest of the content
+	Size   int64  // Size of the content in bytes
+}
+
+// Digest returns the digest content is stored under
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return digestPrefix + hex.EncodeToString(sum[:])
+}
+
+// ValidateDigest checks that a digest is a SHA-256 digest, so that it can safely be used in a path or object key
+func ValidateDigest(digest string) error {
+	hexDigest, ok := strings.CutPrefix(digest, digestPrefix)
+	if !ok || len(hexDigest) != sha256.Size*2 || strings.ToLower(hexDigest) != hexDigest {
+		return fmt.Errorf("invalid digest %q, expected sha256:<64 lowercase hex digits>", digest)
+	}
+	if _, err := hex.DecodeString(hexDigest); err != nil {
+		return fmt.Errorf("invalid digest %q, expected sha256:<64 lowercase hex digits>", digest)
+	}
+	return nil
+}
+
+// objectKey returns the relative path of content, it is spread over 256 directories by the first byte of its digest
+func objectKey(digest string) string {
+	hexDigest := strings.TrimPrefix(digest, digestPrefix)
+	return "sha256/" + hexDigest[:2] + "/" + hexDigest
+}
+
+// spool copies content into a temporary file in dir while hashing it.
+// The file is positioned at its start, the caller removes it once done.
+func spool(ctx context.Context, dir string, content io.Reader) (*os.File, *Object, error) {
+	file, err := os.CreateTemp(dir, "upload-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating temporary file: %v", err)
+	}
+	discard := func(err error) (*os.File, *Object, error) {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, nil, err
+	}
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hasher), &contextReader{ctx: ctx, reader: content})
+	if err != nil {
+		return discard(fmt.Errorf("error reading content: %w", err))
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return discard(fmt.Errorf("error rewinding temporary file: %v", err))
+	}
+	return file, &Object{Digest: hashDigest(hasher), Size: size}, nil
+}
+
+// hashDigest formats the sum of a SHA-256 hash as a digest
+func hashDigest(hasher hash.Hash) string {
+	return digestPrefix + hex.EncodeToString(hasher.Sum(nil))
+}
+
+// contextReader stops reading once its context is done, so that an abandoned upload is not stored
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// LocalStore keeps blob content in files below a root directory, named after their digest.
+// Content is written to a temporary file first and renamed once complete, so readers never see partial content.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore opens the store in root, creating the directory if needed
+func NewLocalStore(root string) (*LocalStore, error) {
+	for _, dir := range []string{filepath.Join(root, "sha256"), filepath.Join(root, "tmp")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("error creating blob directory %s: %v", dir, err)
+		}
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Ping checks that the root directory can still be reached
+func (s *LocalStore) Ping(ctx context.Context) error {
+	if _, err := os.Stat(s.root); err != nil {
+		return fmt.Errorf("error checking blob directory: %v", err)
+	}
+	return nil
+}
+
+// Close is a no-op since every file is closed after each operation
+func (s *LocalStore) Close() error {
+	return nil
+}
+
+// Put stores content and returns its digest and size
+func (s *LocalStore) Put(ctx context.Context, content io.Reader) (*Object, error) {
+	file, object, err := spool(ctx, filepath.Join(s.root, "tmp"), content)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error writing content: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("error writing content: %v", err)
+	}
+
+	path := filepath.Join(s.root, filepath.FromSlash(objectKey(object.Digest)))
+	if _, err := os.Stat(path); err == nil {
+		return object, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating blob directory: %v", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return nil, fmt.Errorf("error storing content: %v", err)
+	}
+	return object, nil
+}
+
+// Open returns the content with the given digest, the error wraps os.ErrNotExist when there is none
+func (s *LocalStore) Open(ctx context.Context, digest string) (io.ReadCloser, *Object, error) {
+	if err := ValidateDigest(digest); err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(filepath.Join(s.root, filepath.FromSlash(objectKey(digest))))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening blob %s: %w", digest, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("error opening blob %s: %w", digest, err)
+	}
+	return file, &Object{Digest: digest, Size: info.Size()}, nil
+}
@@ -0,0 +1,74 @@
+package blobrepository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigest(t *testing.T) {
+	assert.Equal(t, "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", Digest(nil))
+	assert.NoError(t, ValidateDigest(Digest([]byte("gazette"))))
+
+	for _, digest := range []string{
+		"",
+		"md5:d41d8cd98f00b204e9800998ecf8427e",
+		"sha256:E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855",
+		"sha256:../../etc/passwd",
+		"sha256:zz" + strings.Repeat("0", 62),
+	} {
+		assert.Error(t, ValidateDigest(digest), "Expected %q to be rejected", digest)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Ping(ctx))
+
+	content := []byte("%PDF-1.7 gazette")
+	object, err := store.Put(ctx, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, Digest(content), object.Digest)
+	assert.Equal(t, int64(len(content)), object.Size)
+
+	// The same content is kept once
+	again, err := store.Put(ctx, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, object, again)
+	files, err := filepath.Glob(filepath.Join(root, "sha256", "*", "*"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	temporary, err := os.ReadDir(filepath.Join(root, "tmp"))
+	assert.NoError(t, err)
+	assert.Empty(t, temporary)
+
+	reader, opened, err := store.Open(ctx, object.Digest)
+	assert.NoError(t, err)
+	read, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, content, read)
+	assert.Equal(t, object, opened)
+
+	_, _, err = store.Open(ctx, Digest([]byte("missing")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, _, err = store.Open(ctx, "sha256:../../secret")
+	assert.Error(t, err)
+
+	// An abandoned upload is not stored
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = store.Put(cancelled, bytes.NewReader([]byte("partial")))
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = store.Open(ctx, Digest([]byte("partial")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
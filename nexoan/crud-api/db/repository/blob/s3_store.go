@@ -0,0 +1,87 @@
+package blobrepository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// S3API is the part of an S3-compatible client used by S3Store. It is small enough to be implemented
+// on top of the AWS SDK, the MinIO client or any other S3-compatible client.
+type S3API interface {
+	// HeadBucket checks that the bucket can be reached
+	HeadBucket(ctx context.Context, bucket string) error
+	// HeadObject returns the size of an object, the error wraps os.ErrNotExist when there is none
+	HeadObject(ctx context.Context, bucket, key string) (int64, error)
+	// PutObject uploads an object of a known size
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64) error
+	// GetObject returns the content of an object and its size, the error wraps os.ErrNotExist when there is none
+	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, int64, error)
+}
+
+// S3Store keeps blob content as objects of a bucket in an S3-compatible object store, keyed by their digest.
+// Content is spooled to a temporary file to compute its digest before it is uploaded.
+type S3Store struct {
+	client S3API
+	bucket string
+	prefix string
+	// spoolDir is the directory of the temporary files, the system default is used when empty
+	spoolDir string
+}
+
+// NewS3Store creates a store keeping objects in bucket below the key prefix
+func NewS3Store(client S3API, bucket, prefix, spoolDir string) *S3Store {
+	return &S3Store{client: client, bucket: bucket, prefix: prefix, spoolDir: spoolDir}
+}
+
+// Ping checks that the bucket can be reached
+func (s *S3Store) Ping(ctx context.Context) error {
+	if err := s.client.HeadBucket(ctx, s.bucket); err != nil {
+		return fmt.Errorf("error checking bucket %s: %v", s.bucket, err)
+	}
+	return nil
+}
+
+// Close is a no-op, the client is owned by the caller
+func (s *S3Store) Close() error {
+	return nil
+}
+
+// Put stores content and returns its digest and size, content already in the bucket is not uploaded again
+func (s *S3Store) Put(ctx context.Context, content io.Reader) (*Object, error) {
+	file, object, err := spool(ctx, s.spoolDir, content)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		file.Close()
+		os.Remove(file.Name())
+	}()
+
+	key := s.prefix + objectKey(object.Digest)
+	_, err = s.client.HeadObject(ctx, s.bucket, key)
+	if err == nil {
+		return object, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error checking object %s: %v", key, err)
+	}
+	if err := s.client.PutObject(ctx, s.bucket, key, file, object.Size); err != nil {
+		return nil, fmt.Errorf("error uploading object %s: %v", key, err)
+	}
+	return object, nil
+}
+
+// Open returns the content with the given digest, the error wraps os.ErrNotExist when there is none
+func (s *S3Store) Open(ctx context.Context, digest string) (io.ReadCloser, *Object, error) {
+	if err := ValidateDigest(digest); err != nil {
+		return nil, nil, err
+	}
+	body, size, err := s.client.GetObject(ctx, s.bucket, s.prefix+objectKey(digest))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening blob %s: %w", digest, err)
+	}
+	return body, &Object{Digest: digest, Size: size}, nil
+}
@@ -0,0 +1,83 @@
+package blobrepository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 keeps the objects of a single bucket in memory and counts the uploads
+type fakeS3 struct {
+	objects map[string][]byte
+	puts    int
+}
+
+func (f *fakeS3) HeadBucket(ctx context.Context, bucket string) error {
+	if bucket != "gazettes" {
+		return fmt.Errorf("bucket %s does not exist", bucket)
+	}
+	return nil
+}
+
+func (f *fakeS3) HeadObject(ctx context.Context, bucket, key string) (int64, error) {
+	content, ok := f.objects[key]
+	if !ok {
+		return 0, fmt.Errorf("object %s: %w", key, os.ErrNotExist)
+	}
+	return int64(len(content)), nil
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, bucket, key string, body io.Reader, size int64) error {
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if int64(len(content)) != size {
+		return fmt.Errorf("expected %d bytes, got %d", size, len(content))
+	}
+	f.objects[key] = content
+	f.puts++
+	return nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, int64, error) {
+	content, ok := f.objects[key]
+	if !ok {
+		return nil, 0, fmt.Errorf("object %s: %w", key, os.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(content)), int64(len(content)), nil
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3{objects: make(map[string][]byte)}
+	store := NewS3Store(client, "gazettes", "crud/", t.TempDir())
+	assert.NoError(t, store.Ping(ctx))
+	assert.Error(t, NewS3Store(client, "missing", "", "").Ping(ctx))
+
+	content := []byte("\x89PNG image")
+	object, err := store.Put(ctx, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, Digest(content), object.Digest)
+	assert.Contains(t, client.objects, "crud/"+objectKey(object.Digest))
+
+	// Content already in the bucket is not uploaded again
+	_, err = store.Put(ctx, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.puts)
+
+	reader, opened, err := store.Open(ctx, object.Digest)
+	assert.NoError(t, err)
+	read, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, content, read)
+	assert.Equal(t, object, opened)
+
+	_, _, err = store.Open(ctx, Digest([]byte("missing")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
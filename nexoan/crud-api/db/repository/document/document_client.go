@@ -0,0 +1,494 @@
+// Package documentrepository stores entity metadata, the values of scalar, document and graph attributes
+// and the kind and relationship type registries in a JSON file. It replaces MongoDB when the service runs in embedded mode.
+package documentrepository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// storedValue is the stored form of a metadata value, the type URL and the serialized message
+type storedValue struct {
+	TypeURL string `json:"typeUrl"`
+	Value   []byte `json:"value"`
+}
+
+// storedVersion is the stored form of a version of a metadata value
+type storedVersion struct {
+	Key        string      `json:"key"`
+	Value      storedValue `json:"value"`
+	ValidFrom  string      `json:"validFrom,omitempty"`
+	ValidTo    string      `json:"validTo,omitempty"`
+	RecordedBy string      `json:"recordedBy,omitempty"`
+	RecordedAt string      `json:"recordedAt,omitempty"`
+}
+
+// storedAttributeValue is the stored form of a value of a scalar, document or graph attribute
+type storedAttributeValue struct {
+	StartTime string      `json:"startTime,omitempty"`
+	EndTime   string      `json:"endTime,omitempty"`
+	Value     storedValue `json:"value"`
+}
+
+// document is the content of the store file. Entities holds the current metadata of every entity and
+// History the versions of its values, entities stored before the history was kept only have current metadata.
+// AttributeValues holds the values of the attributes of every entity ordered by start time.
+type document struct {
+	Entities          map[string]map[string]storedValue            `json:"entities"`
+	History           map[string][]storedVersion                   `json:"history"`
+	AttributeValues   map[string]map[string][]storedAttributeValue `json:"attributeValues"`
+	RelationshipTypes map[string]json.RawMessage                   `json:"relationshipTypes"`
+	KindSchemas       map[string]json.RawMessage                   `json:"kindSchemas"`
+}
+
+// DocumentRepository keeps entity metadata and the registries in memory and writes them to a JSON file
+// after every change. Like the MongoDB repository only the id and the metadata history of an entity are stored.
+type DocumentRepository struct {
+	mu   sync.RWMutex
+	path string
+	data document
+}
+
+// NewDocumentRepository opens the store file at path, it is created on the first write if it does not exist
+func NewDocumentRepository(path string) (*DocumentRepository, error) {
+	repo := &DocumentRepository{
+		path: path,
+		data: document{
+			Entities:          make(map[string]map[string]storedValue),
+			History:           make(map[string][]storedVersion),
+			AttributeValues:   make(map[string]map[string][]storedAttributeValue),
+			RelationshipTypes: make(map[string]json.RawMessage),
+			KindSchemas:       make(map[string]json.RawMessage),
+		},
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return repo, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading document store %s: %v", path, err)
+	}
+	if err := json.Unmarshal(content, &repo.data); err != nil {
+		return nil, fmt.Errorf("error decoding document store %s: %v", path, err)
+	}
+	if repo.data.Entities == nil {
+		repo.data.Entities = make(map[string]map[string]storedValue)
+	}
+	if repo.data.History == nil {
+		repo.data.History = make(map[string][]storedVersion)
+	}
+	if repo.data.AttributeValues == nil {
+		repo.data.AttributeValues = make(map[string]map[string][]storedAttributeValue)
+	}
+	if repo.data.RelationshipTypes == nil {
+		repo.data.RelationshipTypes = make(map[string]json.RawMessage)
+	}
+	if repo.data.KindSchemas == nil {
+		repo.data.KindSchemas = make(map[string]json.RawMessage)
+	}
+	return repo, nil
+}
+
+// Close is a no-op since every change is written immediately
+func (repo *DocumentRepository) Close(ctx context.Context) error {
+	return nil
+}
+
+// Ping checks that the directory holding the store file is still accessible
+func (repo *DocumentRepository) Ping(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Dir(repo.path)); err != nil {
+		return fmt.Errorf("document store is not accessible: %v", err)
+	}
+	return nil
+}
+
+// save writes the store file, the caller must hold the write lock.
+// The file is replaced atomically so that a crash never leaves a partial file behind.
+func (repo *DocumentRepository) save() error {
+	content, err := json.MarshalIndent(repo.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding document store: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(repo.path), filepath.Base(repo.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error writing document store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing document store: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing document store: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), repo.path); err != nil {
+		return fmt.Errorf("error writing document store: %v", err)
+	}
+	return nil
+}
+
+// toStoredMetadata converts metadata into its stored form
+func toStoredMetadata(metadata map[string]*anypb.Any) map[string]storedValue {
+	stored := make(map[string]storedValue, len(metadata))
+	for key, value := range metadata {
+		stored[key] = storedValue{TypeURL: value.GetTypeUrl(), Value: value.GetValue()}
+	}
+	return stored
+}
+
+// fromStoredMetadata converts stored metadata back into protobuf values
+func fromStoredMetadata(stored map[string]storedValue) map[string]*anypb.Any {
+	metadata := make(map[string]*anypb.Any, len(stored))
+	for key, value := range stored {
+		metadata[key] = &anypb.Any{TypeUrl: value.TypeURL, Value: value.Value}
+	}
+	return metadata
+}
+
+// toStoredVersions converts metadata versions into their stored form
+func toStoredVersions(versions []*pb.MetadataVersion) []storedVersion {
+	stored := make([]storedVersion, len(versions))
+	for i, version := range versions {
+		stored[i] = storedVersion{
+			Key:        version.Key,
+			Value:      storedValue{TypeURL: version.GetValue().GetTypeUrl(), Value: version.GetValue().GetValue()},
+			ValidFrom:  version.ValidFrom,
+			ValidTo:    version.ValidTo,
+			RecordedBy: version.RecordedBy,
+			RecordedAt: version.RecordedAt,
+		}
+	}
+	return stored
+}
+
+// versions returns the metadata versions of an entity, the caller must hold the lock
+func (repo *DocumentRepository) versions(entityId string) []*pb.MetadataVersion {
+	stored, ok := repo.data.History[entityId]
+	if !ok {
+		return metadatahistory.FromMetadata(fromStoredMetadata(repo.data.Entities[entityId]))
+	}
+	versions := make([]*pb.MetadataVersion, len(stored))
+	for i, version := range stored {
+		versions[i] = &pb.MetadataVersion{
+			Key:        version.Key,
+			Value:      &anypb.Any{TypeUrl: version.Value.TypeURL, Value: version.Value.Value},
+			ValidFrom:  version.ValidFrom,
+			ValidTo:    version.ValidTo,
+			RecordedBy: version.RecordedBy,
+			RecordedAt: version.RecordedAt,
+		}
+	}
+	return versions
+}
+
+// saveVersions stores the metadata versions of an entity and writes the file, the previous state is restored
+// on failure. The caller must hold the write lock.
+func (repo *DocumentRepository) saveVersions(entityId string, versions []*pb.MetadataVersion) error {
+	previous, existed := repo.data.Entities[entityId]
+	previousHistory, hadHistory := repo.data.History[entityId]
+	repo.data.Entities[entityId] = toStoredMetadata(metadatahistory.Current(versions))
+	repo.data.History[entityId] = toStoredVersions(versions)
+	if err := repo.save(); err != nil {
+		if existed {
+			repo.data.Entities[entityId] = previous
+		} else {
+			delete(repo.data.Entities, entityId)
+		}
+		if hadHistory {
+			repo.data.History[entityId] = previousHistory
+		} else {
+			delete(repo.data.History, entityId)
+		}
+		return err
+	}
+	return nil
+}
+
+// CreateEntity stores the metadata of a new entity, valid from its creation
+func (repo *DocumentRepository) CreateEntity(ctx context.Context, entity *pb.Entity) error {
+	versions, err := metadatahistory.Apply(nil, &metadatahistory.Change{Set: entity.Metadata, ValidFrom: entity.Created})
+	if err != nil {
+		return err
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if _, exists := repo.data.Entities[entity.Id]; exists {
+		return fmt.Errorf("entity with Id %s already exists", entity.Id)
+	}
+	return repo.saveVersions(entity.Id, versions)
+}
+
+// ReadEntity fetches an entity by ID, the error wraps commons.ErrNotFound when it does not exist
+func (repo *DocumentRepository) ReadEntity(ctx context.Context, id string) (*pb.Entity, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	stored, ok := repo.data.Entities[id]
+	if !ok {
+		return nil, fmt.Errorf("entity with Id %s: %w", id, commons.ErrNotFound)
+	}
+	return &pb.Entity{Id: id, Metadata: fromStoredMetadata(stored)}, nil
+}
+
+// DeleteEntity removes an entity and its attribute values and reports whether it existed
+func (repo *DocumentRepository) DeleteEntity(ctx context.Context, id string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	stored, ok := repo.data.Entities[id]
+	attributeValues, hadAttributeValues := repo.data.AttributeValues[id]
+	if !ok && !hadAttributeValues {
+		return false, nil
+	}
+	history, hadHistory := repo.data.History[id]
+	delete(repo.data.Entities, id)
+	delete(repo.data.History, id)
+	delete(repo.data.AttributeValues, id)
+	if err := repo.save(); err != nil {
+		if ok {
+			repo.data.Entities[id] = stored
+		}
+		if hadHistory {
+			repo.data.History[id] = history
+		}
+		if hadAttributeValues {
+			repo.data.AttributeValues[id] = attributeValues
+		}
+		return false, err
+	}
+	return ok, nil
+}
+
+// SaveAttributeValue stores a value of an attribute and writes the file, replacing the value of the attribute
+// with the same start time. The previous values are restored on failure.
+func (repo *DocumentRepository) SaveAttributeValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	attributes, hadAttributes := repo.data.AttributeValues[entityID]
+	if !hadAttributes {
+		attributes = make(map[string][]storedAttributeValue)
+		repo.data.AttributeValues[entityID] = attributes
+	}
+	previous, hadValues := attributes[attrName]
+
+	stored := storedAttributeValue{
+		StartTime: value.StartTime,
+		EndTime:   value.EndTime,
+		Value:     storedValue{TypeURL: value.GetValue().GetTypeUrl(), Value: value.GetValue().GetValue()},
+	}
+	i := sort.Search(len(previous), func(i int) bool { return previous[i].StartTime >= value.StartTime })
+	values := append([]storedAttributeValue{}, previous[:i]...)
+	values = append(values, stored)
+	if i < len(previous) && previous[i].StartTime == value.StartTime {
+		i++
+	}
+	attributes[attrName] = append(values, previous[i:]...)
+
+	if err := repo.save(); err != nil {
+		switch {
+		case !hadAttributes:
+			delete(repo.data.AttributeValues, entityID)
+		case hadValues:
+			attributes[attrName] = previous
+		default:
+			delete(attributes, attrName)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAttributeValues returns the values of an attribute ordered by start time, the error wraps commons.ErrNotFound
+// when the attribute has none
+func (repo *DocumentRepository) GetAttributeValues(ctx context.Context, entityID, attrName string) ([]*pb.TimeBasedValue, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	stored := repo.data.AttributeValues[entityID][attrName]
+	if len(stored) == 0 {
+		return nil, fmt.Errorf("values of attribute %s of entity %s: %w", attrName, entityID, commons.ErrNotFound)
+	}
+	values := make([]*pb.TimeBasedValue, len(stored))
+	for i, value := range stored {
+		values[i] = &pb.TimeBasedValue{
+			StartTime: value.StartTime,
+			EndTime:   value.EndTime,
+			Value:     &anypb.Any{TypeUrl: value.Value.TypeURL, Value: value.Value.Value},
+		}
+	}
+	return values, nil
+}
+
+// HandleMetadata applies a change to the metadata of an entity, empty changes are skipped
+func (repo *DocumentRepository) HandleMetadata(ctx context.Context, entityId string, change *metadatahistory.Change) error {
+	if change.IsEmpty() {
+		return nil
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	versions, err := metadatahistory.Apply(repo.versions(entityId), change)
+	if err != nil {
+		return err
+	}
+	return repo.saveVersions(entityId, versions)
+}
+
+// GetMetadata returns the metadata of an entity now or at activeAt, or an empty map if it has none
+func (repo *DocumentRepository) GetMetadata(ctx context.Context, entityId string, activeAt string) (map[string]*anypb.Any, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	return metadatahistory.Read(repo.versions(entityId), activeAt)
+}
+
+// GetMetadataHistory returns the versions of a metadata key of an entity, or of every key when key is empty
+func (repo *DocumentRepository) GetMetadataHistory(ctx context.Context, entityId string, key string) ([]*pb.MetadataVersion, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	return metadatahistory.Select(repo.versions(entityId), key), nil
+}
+
+// CreateIndexes is a no-op since the metadata history of an entity is kept with the entity
+func (repo *DocumentRepository) CreateIndexes(ctx context.Context) error {
+	return nil
+}
+
+// CreateSearchIndexes is a no-op since metadata is searched by scanning the entities
+func (repo *DocumentRepository) CreateSearchIndexes(ctx context.Context) error {
+	return nil
+}
+
+// SearchMetadata returns the metadata values that contain every term of the query, ordered by entity ID and key
+func (repo *DocumentRepository) SearchMetadata(ctx context.Context, query search.Query) ([]search.Match, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	entityIDs := make([]string, 0, len(repo.data.Entities))
+	for entityID := range repo.data.Entities {
+		entityIDs = append(entityIDs, entityID)
+	}
+	sort.Strings(entityIDs)
+
+	var matches []search.Match
+	for _, entityID := range entityIDs {
+		matches = append(matches, query.MatchMetadata(entityID, fromStoredMetadata(repo.data.Entities[entityID]))...)
+		if len(matches) >= search.MaxMatches {
+			return matches[:search.MaxMatches], nil
+		}
+	}
+	return matches, nil
+}
+
+// saveRegistryEntry stores a registry entry under key and writes the file, the previous entry is restored on failure
+func (repo *DocumentRepository) saveRegistryEntry(registry map[string]json.RawMessage, key string, message proto.Message) error {
+	encoded, err := protojson.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", key, err)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	previous, existed := registry[key]
+	registry[key] = encoded
+	if err := repo.save(); err != nil {
+		if existed {
+			registry[key] = previous
+		} else {
+			delete(registry, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// deleteRegistryEntry removes a registry entry and writes the file
+func (repo *DocumentRepository) deleteRegistryEntry(registry map[string]json.RawMessage, key string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	previous, ok := registry[key]
+	if !ok {
+		return false, nil
+	}
+	delete(registry, key)
+	if err := repo.save(); err != nil {
+		registry[key] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// sortedKeys returns the keys of a registry in order
+func sortedKeys(registry map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(registry))
+	for key := range registry {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SaveRelationshipType creates or replaces a relationship type in the registry
+func (repo *DocumentRepository) SaveRelationshipType(ctx context.Context, relType *pb.RelationshipType) error {
+	if relType == nil || relType.Name == "" {
+		return fmt.Errorf("relationship type name cannot be empty")
+	}
+	return repo.saveRegistryEntry(repo.data.RelationshipTypes, relType.Name, relType)
+}
+
+// ReadRelationshipTypes returns every relationship type in the registry ordered by name
+func (repo *DocumentRepository) ReadRelationshipTypes(ctx context.Context) ([]*pb.RelationshipType, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	var relTypes []*pb.RelationshipType
+	for _, name := range sortedKeys(repo.data.RelationshipTypes) {
+		var relType pb.RelationshipType
+		if err := protojson.Unmarshal(repo.data.RelationshipTypes[name], &relType); err != nil {
+			return nil, fmt.Errorf("error decoding relationship type %s: %v", name, err)
+		}
+		relTypes = append(relTypes, &relType)
+	}
+	return relTypes, nil
+}
+
+// DeleteRelationshipType removes a relationship type from the registry and reports whether it existed
+func (repo *DocumentRepository) DeleteRelationshipType(ctx context.Context, name string) (bool, error) {
+	return repo.deleteRegistryEntry(repo.data.RelationshipTypes, name)
+}
+
+// SaveKindSchema creates or replaces a kind schema in the registry
+func (repo *DocumentRepository) SaveKindSchema(ctx context.Context, schema *pb.KindSchema) error {
+	if schema == nil || schema.Major == "" {
+		return fmt.Errorf("kind major cannot be empty")
+	}
+	return repo.saveRegistryEntry(repo.data.KindSchemas, schema.Major, schema)
+}
+
+// ReadKindSchemas returns every kind schema in the registry ordered by major kind
+func (repo *DocumentRepository) ReadKindSchemas(ctx context.Context) ([]*pb.KindSchema, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	var schemas []*pb.KindSchema
+	for _, major := range sortedKeys(repo.data.KindSchemas) {
+		var schema pb.KindSchema
+		if err := protojson.Unmarshal(repo.data.KindSchemas[major], &schema); err != nil {
+			return nil, fmt.Errorf("error decoding kind schema %s: %v", major, err)
+		}
+		schemas = append(schemas, &schema)
+	}
+	return schemas, nil
+}
+
+// DeleteKindSchema removes a kind schema from the registry and reports whether it existed
+func (repo *DocumentRepository) DeleteKindSchema(ctx context.Context, major string) (bool, error) {
+	return repo.deleteRegistryEntry(repo.data.KindSchemas, major)
+}
@@ -0,0 +1,191 @@
+package documentrepository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestDocumentRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	repo, err := NewDocumentRepository(path)
+	assert.NoError(t, err)
+
+	owner, err := anypb.New(wrapperspb.String("registry"))
+	assert.NoError(t, err)
+	err = repo.CreateEntity(ctx, &pb.Entity{Id: "entity-1", Metadata: map[string]*anypb.Any{"owner": owner}})
+	assert.NoError(t, err)
+	err = repo.CreateEntity(ctx, &pb.Entity{Id: "entity-1"})
+	assert.Error(t, err)
+	assert.NoError(t, repo.SaveRelationshipType(ctx, &pb.RelationshipType{Name: "WORKS_AT", Cardinality: pb.Cardinality_MANY_TO_ONE}))
+	assert.NoError(t, repo.SaveKindSchema(ctx, &pb.KindSchema{Major: "Person"}))
+	assert.Error(t, repo.SaveKindSchema(ctx, &pb.KindSchema{}))
+
+	// Everything is read back from the file by a new repository
+	repo, err = NewDocumentRepository(path)
+	assert.NoError(t, err)
+
+	entity, err := repo.ReadEntity(ctx, "entity-1")
+	assert.NoError(t, err)
+	var ownerValue wrapperspb.StringValue
+	assert.NoError(t, entity.Metadata["owner"].UnmarshalTo(&ownerValue))
+	assert.Equal(t, "registry", ownerValue.Value)
+	_, err = repo.ReadEntity(ctx, "missing")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+
+	relTypes, err := repo.ReadRelationshipTypes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, relTypes, 1)
+	assert.Equal(t, pb.Cardinality_MANY_TO_ONE, relTypes[0].Cardinality)
+	schemas, err := repo.ReadKindSchemas(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+
+	deleted, err := repo.DeleteEntity(ctx, "entity-1")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.DeleteRelationshipType(ctx, "WORKS_AT")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	repo, err = NewDocumentRepository(path)
+	assert.NoError(t, err)
+	metadata, err := repo.GetMetadata(ctx, "entity-1", "")
+	assert.NoError(t, err)
+	assert.Empty(t, metadata)
+	relTypes, err = repo.ReadRelationshipTypes(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, relTypes)
+}
+
+func TestDocumentRepositoryAttributeValues(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	repo, err := NewDocumentRepository(path)
+	assert.NoError(t, err)
+	value := func(start string, text string) *pb.TimeBasedValue {
+		packed, err := anypb.New(wrapperspb.String(text))
+		assert.NoError(t, err)
+		return &pb.TimeBasedValue{StartTime: start, Value: packed}
+	}
+
+	_, err = repo.GetAttributeValues(ctx, "entity-1", "motto")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+	assert.NoError(t, repo.SaveAttributeValue(ctx, "entity-1", "motto", value("2024-01-01T00:00:00Z", "later")))
+	assert.NoError(t, repo.SaveAttributeValue(ctx, "entity-1", "motto", value("2023-01-01T00:00:00Z", "first")))
+	assert.NoError(t, repo.SaveAttributeValue(ctx, "entity-1", "motto", value("2024-01-01T00:00:00Z", "second")))
+
+	// The values are read back from the file ordered by start time, the replaced value is gone
+	repo, err = NewDocumentRepository(path)
+	assert.NoError(t, err)
+	values, err := repo.GetAttributeValues(ctx, "entity-1", "motto")
+	assert.NoError(t, err)
+	if assert.Len(t, values, 2) {
+		var text wrapperspb.StringValue
+		assert.NoError(t, values[0].Value.UnmarshalTo(&text))
+		assert.Equal(t, "first", text.Value)
+		assert.NoError(t, values[1].Value.UnmarshalTo(&text))
+		assert.Equal(t, "second", text.Value)
+		assert.Equal(t, "2024-01-01T00:00:00Z", values[1].StartTime)
+	}
+
+	// Values of an entity without metadata are removed with it
+	_, err = repo.DeleteEntity(ctx, "entity-1")
+	assert.NoError(t, err)
+	_, err = repo.GetAttributeValues(ctx, "entity-1", "motto")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+}
+
+func TestDocumentRepositoryHistory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	repo, err := NewDocumentRepository(path)
+	assert.NoError(t, err)
+
+	first, err := anypb.New(wrapperspb.String("A"))
+	assert.NoError(t, err)
+	second, err := anypb.New(wrapperspb.String("B"))
+	assert.NoError(t, err)
+	err = repo.CreateEntity(ctx, &pb.Entity{Id: "ministry-1", Created: "2019-12-01T00:00:00Z", Metadata: map[string]*anypb.Any{"minister": first}})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{
+		Set:        map[string]*anypb.Any{"minister": second},
+		ValidFrom:  "2022-07-22T00:00:00Z",
+		RecordedBy: "ingest-job",
+	}))
+
+	// The history is read back from the file by a new repository
+	repo, err = NewDocumentRepository(path)
+	assert.NoError(t, err)
+	history, err := repo.GetMetadataHistory(ctx, "ministry-1", "minister")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "2022-07-22T00:00:00Z", history[0].ValidTo)
+	assert.Equal(t, "ingest-job", history[1].RecordedBy)
+	metadata, err := repo.GetMetadata(ctx, "ministry-1", "2020-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	var value wrapperspb.StringValue
+	assert.NoError(t, metadata["minister"].UnmarshalTo(&value))
+	assert.Equal(t, "A", value.Value)
+
+	assert.NoError(t, repo.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{Remove: []string{"minister"}}))
+	entity, err := repo.ReadEntity(ctx, "ministry-1")
+	assert.NoError(t, err)
+	assert.Empty(t, entity.Metadata)
+}
+
+func TestDocumentRepositoryLegacyFile(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	// Files written before the history was kept only hold the current values
+	legacy := `{"entities": {"ministry-1": {"minister": {"typeUrl": "type.googleapis.com/google.protobuf.StringValue", "value": "CgFB"}}}}`
+	assert.NoError(t, os.WriteFile(path, []byte(legacy), 0o600))
+	repo, err := NewDocumentRepository(path)
+	assert.NoError(t, err)
+
+	history, err := repo.GetMetadataHistory(ctx, "ministry-1", "")
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Empty(t, history[0].ValidFrom)
+
+	second, err := anypb.New(wrapperspb.String("B"))
+	assert.NoError(t, err)
+	assert.NoError(t, repo.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{Set: map[string]*anypb.Any{"minister": second}, ValidFrom: "2022-07-22T00:00:00Z"}))
+	metadata, err := repo.GetMetadata(ctx, "ministry-1", "2000-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	var value wrapperspb.StringValue
+	assert.NoError(t, metadata["minister"].UnmarshalTo(&value))
+	assert.Equal(t, "A", value.Value)
+}
+
+func TestDocumentRepositorySearch(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewDocumentRepository(filepath.Join(t.TempDir(), "metadata.json"))
+	assert.NoError(t, err)
+
+	for id, city := range map[string]string{"entity-2": "Kotte", "entity-1": "Sri Jayawardenepura KÖTTE", "entity-3": "Colombo"} {
+		value, err := anypb.New(wrapperspb.String(city))
+		assert.NoError(t, err)
+		assert.NoError(t, repo.HandleMetadata(ctx, id, &metadatahistory.Change{Set: map[string]*anypb.Any{"city": value}}))
+	}
+
+	query, err := search.NewQuery("kotte", nil)
+	assert.NoError(t, err)
+	matches, err := repo.SearchMetadata(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{
+		{EntityID: "entity-1", Field: "metadata.city", Text: "Sri Jayawardenepura KÖTTE"},
+		{EntityID: "entity-2", Field: "metadata.city", Text: "Kotte"},
+	}, matches)
+}
@@ -0,0 +1,68 @@
+package memoryrepository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	blobrepository "lk/datafoundation/crud-api/db/repository/blob"
+)
+
+// BlobStore keeps blob content in memory, addressed by its digest like the local and S3 blob stores
+type BlobStore struct {
+	mu      sync.RWMutex
+	content map[string][]byte
+}
+
+// NewBlobStore creates an empty in-memory blob store
+func NewBlobStore() *BlobStore {
+	return &BlobStore{
+		content: make(map[string][]byte),
+	}
+}
+
+// Close is a no-op, it exists so the store can be used in place of the local blob store
+func (s *BlobStore) Close() error {
+	return nil
+}
+
+// Ping always succeeds since the store lives in the service process
+func (s *BlobStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Put stores content and returns its digest and size
+func (s *BlobStore) Put(ctx context.Context, content io.Reader) (*blobrepository.Object, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading content: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("error reading content: %w", err)
+	}
+	digest := blobrepository.Digest(data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.content[digest]; !ok {
+		s.content[digest] = data
+	}
+	return &blobrepository.Object{Digest: digest, Size: int64(len(data))}, nil
+}
+
+// Open returns the content with the given digest, the error wraps os.ErrNotExist when there is none
+func (s *BlobStore) Open(ctx context.Context, digest string) (io.ReadCloser, *blobrepository.Object, error) {
+	if err := blobrepository.ValidateDigest(digest); err != nil {
+		return nil, nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.content[digest]
+	if !ok {
+		return nil, nil, fmt.Errorf("error opening blob %s: %w", digest, os.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(data)), &blobrepository.Object{Digest: digest, Size: int64(len(data))}, nil
+}
@@ -0,0 +1,36 @@
+package memoryrepository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	blobrepository "lk/datafoundation/crud-api/db/repository/blob"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewBlobStore()
+
+	content := []byte("%PDF-1.7 gazette")
+	object, err := store.Put(ctx, bytes.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, blobrepository.Digest(content), object.Digest)
+	assert.Equal(t, int64(len(content)), object.Size)
+
+	reader, opened, err := store.Open(ctx, object.Digest)
+	assert.NoError(t, err)
+	read, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, content, read)
+	assert.Equal(t, object, opened)
+
+	_, _, err = store.Open(ctx, blobrepository.Digest([]byte("missing")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, _, err = store.Open(ctx, "gazette.pdf")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package memoryrepository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"lk/datafoundation/crud-api/pkg/relationships"
+)
+
+// ruleStore looks up the relationships and entities the integrity rules check against.
+// The caller must hold the write lock of the store.
+type ruleStore struct {
+	s *GraphStore
+}
+
+// overlaps checks if an existing relationship is active at some point of the interval [start, end)
+func (r *graphRelationship) overlaps(start time.Time, end string) bool {
+	if end != "" && !mustParseTime(r.Created).Before(mustParseTime(end)) {
+		return false
+	}
+	return r.Terminated == "" || mustParseTime(r.Terminated).After(start)
+}
+
+// validateRelationshipIntegrity checks the relationship against the integrity rules configured for the store.
+// The caller must hold the write lock.
+func (s *GraphStore) validateRelationshipIntegrity(ctx context.Context, rel *graphRelationship) error {
+	return relationships.Validate(ctx, s.rules, s.relationshipTypes, ruleStore{s: s}, &relationships.Relationship{
+		ID:         rel.ID,
+		Type:       rel.Type,
+		SourceID:   rel.SourceID,
+		TargetID:   rel.TargetID,
+		Created:    rel.Created,
+		Terminated: rel.Terminated,
+	})
+}
+
+// Overlapping describes the other relationships of the same type selected by sel that overlap rel in time
+func (rs ruleStore) Overlapping(ctx context.Context, rel *relationships.Relationship, sel relationships.Selection) ([]string, error) {
+	start := mustParseTime(rel.Created)
+	var conflicts []string
+	for _, other := range rs.s.relationships {
+		if other.ID == rel.ID || other.Type != rel.Type || !sel.Picks(other.SourceID, other.TargetID) || !other.overlaps(start, rel.Terminated) {
+			continue
+		}
+		conflicts = append(conflicts, relationships.DescribeInterval(other.ID, other.Created, other.Terminated))
+	}
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// EndpointsOutside describes the endpoints of rel whose lifetime does not cover the relationship
+func (rs ruleStore) EndpointsOutside(ctx context.Context, rel *relationships.Relationship) ([]string, error) {
+	start := mustParseTime(rel.Created)
+
+	var violations []string
+	for _, id := range []string{rel.SourceID, rel.TargetID} {
+		entity, ok := rs.s.entities[id]
+		if !ok {
+			continue
+		}
+		violated := mustParseTime(entity.Created).After(start)
+		if entity.Terminated != "" {
+			violated = violated || rel.Terminated == "" || mustParseTime(entity.Terminated).Before(mustParseTime(rel.Terminated))
+		}
+		if violated {
+			violations = append(violations, relationships.DescribeInterval(entity.ID, entity.Created, entity.Terminated))
+		}
+		// A self relationship only needs to be checked once
+		if rel.SourceID == rel.TargetID {
+			break
+		}
+	}
+	return violations, nil
+}
@@ -0,0 +1,810 @@
+// Package memoryrepository provides in-memory implementations of the graph, metadata and tabular
+// stores. They behave like the Neo4j, MongoDB and PostgreSQL repositories so that the CRUD service
+// can run in unit tests and in development without any external database. Nothing is persisted.
+package memoryrepository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// graphEntity is an entity node of the in-memory graph
+type graphEntity struct {
+	ID         string
+	Name       string
+	MajorKind  string
+	MinorKind  string
+	Created    string
+	Terminated string // empty while the entity is active
+}
+
+// graphRelationship is a directed relationship of the in-memory graph
+type graphRelationship struct {
+	ID         string
+	Type       string
+	SourceID   string
+	TargetID   string
+	Created    string
+	Terminated string // empty when the relationship is open ended
+	Properties map[string]interface{}
+}
+
+// GraphStore keeps entities and relationships in memory.
+// It applies the same validation, relationship type registry and integrity rules as the Neo4j repository.
+type GraphStore struct {
+	mu                sync.RWMutex
+	rules             config.RelationshipIntegrityConfig
+	entities          map[string]*graphEntity
+	relationships     map[string]*graphRelationship
+	relationshipTypes *relationships.TypeRegistry
+}
+
+// NewGraphStore creates an empty in-memory graph enforcing the given relationship integrity rules
+func NewGraphStore(rules config.RelationshipIntegrityConfig) *GraphStore {
+	return &GraphStore{
+		rules:             rules,
+		entities:          make(map[string]*graphEntity),
+		relationships:     make(map[string]*graphRelationship),
+		relationshipTypes: relationships.NewTypeRegistry(),
+	}
+}
+
+// RelationshipTypes returns the relationship type registry used to validate new relationships
+func (s *GraphStore) RelationshipTypes() *relationships.TypeRegistry {
+	return s.relationshipTypes
+}
+
+// Close is a no-op, it exists so the store can be used in place of the Neo4j repository
+func (s *GraphStore) Close(ctx context.Context) {}
+
+// Ping always succeeds since the store lives in memory
+func (s *GraphStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// parseTime parses a timestamp the way the Neo4j datetime function would accept it
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid datetime %q: %v", value, err)
+	}
+	return t, nil
+}
+
+// mustParseTime parses a timestamp that was validated when it was stored
+func mustParseTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+// sameInstant checks if two timestamps describe the same instant
+func sameInstant(a string, b string) bool {
+	ta, errA := parseTime(a)
+	tb, errB := parseTime(b)
+	return errA == nil && errB == nil && ta.Equal(tb)
+}
+
+// kind returns the kind of an entity
+func (e *graphEntity) kind() *pb.Kind {
+	return &pb.Kind{Major: e.MajorKind, Minor: e.MinorKind}
+}
+
+// toMap converts an entity into the map returned by ReadGraphEntity
+func (e *graphEntity) toMap() map[string]interface{} {
+	entity := map[string]interface{}{
+		"Id":        e.ID,
+		"Name":      e.Name,
+		"Created":   e.Created,
+		"MajorKind": e.MajorKind,
+		"MinorKind": e.MinorKind,
+	}
+	if e.Terminated != "" {
+		entity["Terminated"] = e.Terminated
+	}
+	return entity
+}
+
+// copyProperties copies relationship properties so callers cannot modify the stored values
+func copyProperties(properties map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(properties))
+	for key, value := range properties {
+		result[key] = value
+	}
+	return result
+}
+
+// isActiveAt checks if a relationship is active at the given instant
+func (r *graphRelationship) isActiveAt(t time.Time) bool {
+	if mustParseTime(r.Created).After(t) {
+		return false
+	}
+	return r.Terminated == "" || mustParseTime(r.Terminated).After(t)
+}
+
+// toEntityRelationship converts a relationship into its protobuf form together with its source entity
+func (r *graphRelationship) toEntityRelationship() *pb.EntityRelationship {
+	return &pb.EntityRelationship{
+		EntityId: r.SourceID,
+		Relationship: &pb.Relationship{
+			Id:              r.ID,
+			Name:            r.Type,
+			RelatedEntityId: r.TargetID,
+			StartTime:       r.Created,
+			EndTime:         r.Terminated,
+			Direction:       "OUTGOING",
+			Properties:      relationships.ConvertStoredProperties(r.Properties),
+		},
+	}
+}
+
+// entityName unpacks the name of an entity
+func entityName(entity *pb.Entity) (string, error) {
+	var stringValue wrapperspb.StringValue
+	if err := entity.Name.GetValue().UnmarshalTo(&stringValue); err != nil {
+		return "", fmt.Errorf("error unpacking Name value: %v", err)
+	}
+	return stringValue.Value, nil
+}
+
+// ReadGraphEntity retrieves an entity by its ID and returns it as a map
+func (s *GraphStore) ReadGraphEntity(ctx context.Context, entityID string) (map[string]interface{}, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity Id cannot be empty")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entity, ok := s.entities[entityID]
+	if !ok {
+		return nil, fmt.Errorf("entity with Id %s not found", entityID)
+	}
+	return entity.toMap(), nil
+}
+
+// GetGraphEntity retrieves the kind, name, created and terminated values of an entity,
+// the error wraps commons.ErrNotFound when there is no such entity
+func (s *GraphStore) GetGraphEntity(ctx context.Context, entityId string) (*pb.Kind, *pb.TimeBasedValue, string, string, error) {
+	s.mu.RLock()
+	entity, ok := s.entities[entityId]
+	s.mu.RUnlock()
+	if !ok {
+		logging.FromContext(ctx).DebugContext(ctx, "Entity not found", "entity_id", entityId)
+		return nil, nil, "", "", fmt.Errorf("[memory_graph.GetGraphEntity] error reading entity: entity with Id %s: %w", entityId, commons.ErrNotFound)
+	}
+
+	value, _ := anypb.New(wrapperspb.String(entity.Name))
+	name := &pb.TimeBasedValue{
+		StartTime: entity.Created,
+		EndTime:   entity.Terminated,
+		Value:     value,
+	}
+	return entity.kind(), name, entity.Created, entity.Terminated, nil
+}
+
+// HandleGraphEntityCreation creates a new entity
+func (s *GraphStore) HandleGraphEntityCreation(ctx context.Context, entity *pb.Entity) (bool, error) {
+	if entity.Kind.GetMajor() == "" || entity.Kind.GetMinor() == "" || entity.Name.GetValue() == nil || entity.Created == "" {
+		logging.FromContext(ctx).WarnContext(ctx, "Entity is missing required fields", "entity_id", entity.Id)
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityCreation] missing required fields for entity creation")
+	}
+	if err := cypher.ValidateIdentifier(entity.Kind.Major); err != nil {
+		return false, err
+	}
+
+	name, err := entityName(entity)
+	if err != nil {
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityCreation] %v", err)
+	}
+	if _, err := parseTime(entity.Created); err != nil {
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityCreation] %v", err)
+	}
+	if entity.Terminated != "" {
+		if _, err := parseTime(entity.Terminated); err != nil {
+			return false, fmt.Errorf("[memory_graph.HandleGraphEntityCreation] %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entities[entity.Id]; exists {
+		logging.FromContext(ctx).WarnContext(ctx, "Entity already exists", "entity_id", entity.Id)
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityCreation] entity with Id %s already exists", entity.Id)
+	}
+	s.entities[entity.Id] = &graphEntity{
+		ID:         entity.Id,
+		Name:       name,
+		MajorKind:  entity.Kind.Major,
+		MinorKind:  entity.Kind.Minor,
+		Created:    entity.Created,
+		Terminated: entity.Terminated,
+	}
+	return true, nil
+}
+
+// HandleGraphEntityUpdate updates the name and termination time of an existing entity.
+// As with the Neo4j repository the kind cannot be changed and the created time is kept.
+func (s *GraphStore) HandleGraphEntityUpdate(ctx context.Context, entity *pb.Entity) (bool, error) {
+	if entity.Id == "" {
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityUpdate] entity ID is required")
+	}
+	if entity.Kind != nil && (entity.Kind.Major != "" || entity.Kind.Minor != "") {
+		logging.FromContext(ctx).WarnContext(ctx, "Kind of an entity cannot be updated", "entity_id", entity.Id)
+		return false, fmt.Errorf("[memory_graph.HandleGraphEntityUpdate] Kind cannot be updated")
+	}
+
+	var name string
+	if entity.Name.GetValue() != nil {
+		var err error
+		if name, err = entityName(entity); err != nil {
+			return false, fmt.Errorf("[memory_graph.HandleGraphEntityUpdate] %v", err)
+		}
+	}
+	if entity.Terminated != "" {
+		if _, err := parseTime(entity.Terminated); err != nil {
+			return false, fmt.Errorf("[memory_graph.HandleGraphEntityUpdate] %v", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.entities[entity.Id]
+	if !ok {
+		return false, fmt.Errorf("entity with Id %s does not exist", entity.Id)
+	}
+	if name != "" {
+		existing.Name = name
+	}
+	if entity.Terminated != "" {
+		existing.Terminated = entity.Terminated
+	}
+	return true, nil
+}
+
+// HandleGraphEntityFilter returns the entities matching a ReadEntityRequest.
+// The returned maps use the same keys as the Neo4j repository, ordered by entity ID.
+func (s *GraphStore) HandleGraphEntityFilter(ctx context.Context, req *pb.ReadEntityRequest) ([]map[string]interface{}, error) {
+	if req == nil || req.Entity == nil {
+		return nil, fmt.Errorf("invalid request: ReadEntityRequest or Entity is nil")
+	}
+	filter := req.Entity
+	if filter.Id == "" && filter.Kind.GetMajor() == "" {
+		return nil, fmt.Errorf("kind.Major is required")
+	}
+
+	var name string
+	if filter.Id == "" && filter.Name.GetValue() != nil {
+		name, _ = entityName(filter)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entities []map[string]interface{}
+	for _, entity := range s.entities {
+		if filter.Id != "" {
+			if entity.ID != filter.Id {
+				continue
+			}
+		} else {
+			if entity.MajorKind != filter.Kind.Major ||
+				(filter.Kind.Minor != "" && entity.MinorKind != filter.Kind.Minor) ||
+				(filter.Created != "" && !sameInstant(entity.Created, filter.Created)) ||
+				(filter.Terminated != "" && !sameInstant(entity.Terminated, filter.Terminated)) ||
+				(name != "" && entity.Name != name) {
+				continue
+			}
+		}
+
+		var terminated interface{}
+		if entity.Terminated != "" {
+			terminated = entity.Terminated
+		}
+		entities = append(entities, map[string]interface{}{
+			"id":         entity.ID,
+			"kind":       entity.MajorKind,
+			"created":    entity.Created,
+			"terminated": terminated,
+			"name":       entity.Name,
+			"minorKind":  entity.MinorKind,
+		})
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i]["id"].(string) < entities[j]["id"].(string)
+	})
+	return entities, nil
+}
+
+// validateNewRelationship checks the fields required to create a relationship
+func validateNewRelationship(relationship *pb.Relationship) error {
+	if relationship.RelatedEntityId == "" {
+		return fmt.Errorf("missing RelatedEntityId for relationship %s. Required for creation", relationship.Id)
+	}
+	if relationship.Name == "" {
+		return fmt.Errorf("missing Name for relationship %s. Required for creation", relationship.Id)
+	}
+	if err := cypher.ValidateIdentifier(relationship.Name); err != nil {
+		return err
+	}
+	if relationship.StartTime == "" {
+		return fmt.Errorf("missing StartTime for relationship %s. Required for creation", relationship.Id)
+	}
+	return nil
+}
+
+// createRelationship stores a new relationship starting at parent, the caller must hold the write lock
+func (s *GraphStore) createRelationship(ctx context.Context, parent *graphEntity, rel *pb.Relationship) error {
+	child, ok := s.entities[rel.RelatedEntityId]
+	if !ok {
+		return fmt.Errorf("[memory_graph.createRelationship] child entity %s does not exist", rel.RelatedEntityId)
+	}
+
+	// Check the relationship against the declared relationship types
+	if err := s.relationshipTypes.Validate(ctx, rel, parent.kind(), child.kind()); err != nil {
+		slog.Debug("Relationship rejected", "relationship_id", rel.Id, "error", err)
+		return err
+	}
+
+	if _, exists := s.relationships[rel.Id]; exists {
+		return fmt.Errorf("relationship with Id %s already exists", rel.Id)
+	}
+	properties, err := relationships.ConvertProperties(rel.Properties)
+	if err != nil {
+		return fmt.Errorf("invalid relationship properties: %w", err)
+	}
+	if _, err := parseTime(rel.StartTime); err != nil {
+		return err
+	}
+	if rel.EndTime != "" {
+		if _, err := parseTime(rel.EndTime); err != nil {
+			return err
+		}
+	}
+
+	created := &graphRelationship{
+		ID:         rel.Id,
+		Type:       rel.Name,
+		SourceID:   parent.ID,
+		TargetID:   child.ID,
+		Created:    rel.StartTime,
+		Terminated: rel.EndTime,
+		Properties: properties,
+	}
+	if err := s.validateRelationshipIntegrity(ctx, created); err != nil {
+		return err
+	}
+	s.relationships[rel.Id] = created
+	return nil
+}
+
+// HandleGraphRelationshipsCreate creates the relationships of an entity
+func (s *GraphStore) HandleGraphRelationshipsCreate(ctx context.Context, entity *pb.Entity) error {
+	if len(entity.Relationships) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parent, ok := s.entities[entity.Id]
+	if !ok {
+		return fmt.Errorf("[memory_graph.HandleGraphRelationshipsCreate] parent entity %s does not exist", entity.Id)
+	}
+
+	for _, relationship := range entity.Relationships {
+		if relationship == nil || relationship.Id == "" {
+			return fmt.Errorf("relationship missing ID field")
+		}
+		if err := validateNewRelationship(relationship); err != nil {
+			return err
+		}
+		if err := s.createRelationship(ctx, parent, relationship); err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error creating relationship", "entity_id", entity.Id,
+				"related_entity_id", relationship.RelatedEntityId, "error", err)
+			return fmt.Errorf("[memory_graph.HandleGraphRelationshipsCreate] error creating relationship: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandleGraphRelationshipsUpdate updates the existing relationships of an entity and creates the new ones.
+// Only the start time, end time and properties of an existing relationship can be updated.
+func (s *GraphStore) HandleGraphRelationshipsUpdate(ctx context.Context, entity *pb.Entity) error {
+	if len(entity.Relationships) == 0 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parent, ok := s.entities[entity.Id]
+	if !ok {
+		return fmt.Errorf("[memory_graph.HandleGraphRelationshipsUpdate] parent entity %s does not exist", entity.Id)
+	}
+
+	for _, relationship := range entity.Relationships {
+		if relationship == nil || relationship.Id == "" {
+			return fmt.Errorf("relationship missing ID field")
+		}
+
+		if _, exists := s.relationships[relationship.Id]; !exists {
+			if err := validateNewRelationship(relationship); err != nil {
+				return err
+			}
+			if err := s.createRelationship(ctx, parent, relationship); err != nil {
+				return fmt.Errorf("[memory_graph.HandleGraphRelationshipsUpdate] failed to create relationship: %w", err)
+			}
+			continue
+		}
+
+		var invalidFields []string
+		if relationship.Name != "" {
+			invalidFields = append(invalidFields, "Name")
+		}
+		if relationship.RelatedEntityId != "" {
+			invalidFields = append(invalidFields, "RelatedEntityId")
+		}
+		if relationship.Direction != "" {
+			invalidFields = append(invalidFields, "Direction")
+		}
+		if len(invalidFields) > 0 {
+			return fmt.Errorf("cannot update immutable fields: %v. Only StartTime, EndTime and Properties are allowed", invalidFields)
+		}
+
+		relationshipData := map[string]interface{}{}
+		if relationship.StartTime != "" {
+			relationshipData["Created"] = relationship.StartTime
+		}
+		if relationship.EndTime != "" {
+			relationshipData["Terminated"] = relationship.EndTime
+		}
+		if len(relationship.Properties) > 0 {
+			properties, err := relationships.ConvertProperties(relationship.Properties)
+			if err != nil {
+				return fmt.Errorf("invalid properties for relationship %s: %w", relationship.Id, err)
+			}
+			relationshipData["Properties"] = properties
+		}
+		if len(relationshipData) == 0 {
+			return fmt.Errorf("no valid fields provided for relationship update. Only StartTime, EndTime and Properties are allowed")
+		}
+
+		if _, err := s.updateRelationship(ctx, relationship.Id, relationshipData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetGraphRelationships returns the outgoing relationships of an entity keyed by relationship ID
+func (s *GraphStore) GetGraphRelationships(ctx context.Context, entityId string) (map[string]*pb.Relationship, error) {
+	relationships := make(map[string]*pb.Relationship)
+	if entityId == "" {
+		return relationships, fmt.Errorf("[memory_graph.GetGraphRelationships] error reading relationships: entity Id cannot be empty")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rel := range s.relationships {
+		if rel.SourceID == entityId {
+			relationship := rel.toEntityRelationship().Relationship
+			relationship.Direction = ""
+			relationships[rel.ID] = relationship
+		}
+	}
+	return relationships, nil
+}
+
+// GetGraphRelationship retrieves a single relationship and its source entity
+func (s *GraphStore) GetGraphRelationship(ctx context.Context, relationshipId string) (*pb.EntityRelationship, error) {
+	if relationshipId == "" {
+		return nil, fmt.Errorf("relationship Id cannot be empty")
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rel, ok := s.relationships[relationshipId]
+	if !ok {
+		return nil, &relationships.NotFoundError{RelationshipID: relationshipId}
+	}
+	return rel.toEntityRelationship(), nil
+}
+
+// GetRelationshipsByEndpoints retrieves the relationships starting at sourceEntityId and/or ending at
+// targetEntityId, ordered by start time and ID
+func (s *GraphStore) GetRelationshipsByEndpoints(ctx context.Context, sourceEntityId string, targetEntityId string, name string, activeAt string) ([]*pb.EntityRelationship, error) {
+	if sourceEntityId == "" && targetEntityId == "" {
+		return nil, fmt.Errorf("either a source or a target entity Id is required")
+	}
+	var activeAtTime time.Time
+	if activeAt != "" {
+		var err error
+		if activeAtTime, err = parseTime(activeAt); err != nil {
+			return nil, err
+		}
+	}
+
+	s.mu.RLock()
+	var matches []*graphRelationship
+	for _, rel := range s.relationships {
+		if (sourceEntityId != "" && rel.SourceID != sourceEntityId) ||
+			(targetEntityId != "" && rel.TargetID != targetEntityId) ||
+			(name != "" && rel.Type != name) ||
+			(activeAt != "" && !rel.isActiveAt(activeAtTime)) {
+			continue
+		}
+		matches = append(matches, rel)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		ci, cj := mustParseTime(matches[i].Created), mustParseTime(matches[j].Created)
+		if !ci.Equal(cj) {
+			return ci.Before(cj)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	relationships := make([]*pb.EntityRelationship, 0, len(matches))
+	for _, rel := range matches {
+		relationships = append(relationships, rel.toEntityRelationship())
+	}
+	return relationships, nil
+}
+
+// GetFilteredRelationships retrieves the relationships of an entity matching every given filter
+func (s *GraphStore) GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, properties map[string]*anypb.Any, activeAt string) (map[string]*pb.Relationship, error) {
+	if entityId == "" {
+		return nil, fmt.Errorf("entityId cannot be empty")
+	}
+
+	filters := map[string]interface{}{}
+	if relationshipId != "" {
+		filters["id"] = relationshipId
+	}
+	if relationship != "" {
+		filters["name"] = relationship
+	}
+	if relatedEntityId != "" {
+		filters["relatedEntityId"] = relatedEntityId
+	}
+	if startTime != "" {
+		filters["startTime"] = startTime
+	}
+	if endTime != "" {
+		filters["endTime"] = endTime
+	}
+	if direction != "" {
+		filters["direction"] = direction
+	}
+	if len(properties) > 0 {
+		propertyFilters, err := relationships.ConvertProperties(properties)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relationship property filters: %w", err)
+		}
+		filters["properties"] = propertyFilters
+	}
+
+	relationshipData, err := s.ReadFilteredRelationships(ctx, entityId, filters, activeAt)
+	if err != nil {
+		return nil, err
+	}
+
+	relationshipMap := make(map[string]*pb.Relationship)
+	for _, rel := range relationshipData {
+		relID := rel["id"].(string)
+		relationshipMap[relID] = &pb.Relationship{
+			Id:              relID,
+			Name:            rel["name"].(string),
+			RelatedEntityId: rel["relatedEntityId"].(string),
+			StartTime:       rel["startTime"].(string),
+			EndTime:         rel["endTime"].(string),
+			Direction:       rel["direction"].(string),
+			Properties:      relationships.ConvertStoredProperties(rel["properties"].(map[string]interface{})),
+		}
+	}
+	return relationshipMap, nil
+}
+
+// propertyEquals compares a stored relationship property with a filter value, numbers compare by value
+func propertyEquals(stored interface{}, expected interface{}) bool {
+	toFloat := func(v interface{}) (float64, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+		return 0, false
+	}
+	if a, ok := toFloat(stored); ok {
+		b, ok := toFloat(expected)
+		return ok && a == b
+	}
+	return stored == expected
+}
+
+// matchesRelationshipFilters checks a relationship against the filters accepted by ReadFilteredRelationships
+func matchesRelationshipFilters(rel *graphRelationship, relatedID string, filters map[string]interface{}) bool {
+	if id, ok := filters["id"].(string); ok && id != "" && rel.ID != id {
+		return false
+	}
+	if related, ok := filters["relatedEntityId"].(string); ok && related != "" && relatedID != related {
+		return false
+	}
+	if name, ok := filters["name"].(string); ok && name != "" && rel.Type != name {
+		return false
+	}
+	if startTime, ok := filters["startTime"].(string); ok && startTime != "" && !sameInstant(rel.Created, startTime) {
+		return false
+	}
+	if endTime, ok := filters["endTime"].(string); ok && endTime != "" && !sameInstant(rel.Terminated, endTime) {
+		return false
+	}
+	if properties, ok := filters["properties"].(map[string]interface{}); ok {
+		for key, value := range properties {
+			if !propertyEquals(rel.Properties[key], value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ReadFilteredRelationships retrieves the incoming and outgoing relationships of an entity matching the filters.
+// The returned maps use the same keys as the Neo4j repository, ordered by relationship ID.
+func (s *GraphStore) ReadFilteredRelationships(ctx context.Context, entityID string, relationshipFilters map[string]interface{}, activeAt string) ([]map[string]interface{}, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity Id cannot be empty")
+	}
+	for _, key := range []string{"startTime", "endTime"} {
+		if value, ok := relationshipFilters[key].(string); ok && value != "" {
+			if _, err := parseTime(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var activeAtTime time.Time
+	if activeAt != "" {
+		var err error
+		if activeAtTime, err = parseTime(activeAt); err != nil {
+			return nil, err
+		}
+	}
+
+	direction, _ := relationshipFilters["direction"].(string)
+	includeOutgoing := direction != "INCOMING"
+	includeIncoming := direction != "OUTGOING"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var relationships []map[string]interface{}
+	add := func(rel *graphRelationship, relatedID string, direction string) {
+		if !matchesRelationshipFilters(rel, relatedID, relationshipFilters) {
+			return
+		}
+		if activeAt != "" && !rel.isActiveAt(activeAtTime) {
+			return
+		}
+		relationships = append(relationships, map[string]interface{}{
+			"id":              rel.ID,
+			"name":            rel.Type,
+			"relatedEntityId": relatedID,
+			"startTime":       rel.Created,
+			"endTime":         rel.Terminated,
+			"direction":       direction,
+			"properties":      copyProperties(rel.Properties),
+		})
+	}
+	for _, rel := range s.relationships {
+		if includeOutgoing && rel.SourceID == entityID {
+			add(rel, rel.TargetID, "OUTGOING")
+		}
+		if includeIncoming && rel.TargetID == entityID {
+			add(rel, rel.SourceID, "INCOMING")
+		}
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		return relationships[i]["id"].(string) < relationships[j]["id"].(string)
+	})
+	return relationships, nil
+}
+
+// updateRelationship applies an update to a relationship, the caller must hold the write lock
+func (s *GraphStore) updateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error) {
+	if relationshipID == "" {
+		return nil, fmt.Errorf("relationship Id cannot be empty")
+	}
+	existing, ok := s.relationships[relationshipID]
+	if !ok {
+		return nil, fmt.Errorf("relationship with Id %s does not exist", relationshipID)
+	}
+
+	for key := range updateData {
+		if key != "Created" && key != "Terminated" && key != "Properties" {
+			return nil, fmt.Errorf("unsupported field '%s' for relationship update. Only 'Created', 'Terminated' and 'Properties' are allowed", key)
+		}
+	}
+	if len(updateData) == 0 {
+		return nil, fmt.Errorf("no valid fields provided for update")
+	}
+
+	updated := *existing
+	if created, ok := updateData["Created"]; ok {
+		updated.Created = fmt.Sprintf("%v", created)
+		if _, err := parseTime(updated.Created); err != nil {
+			return nil, err
+		}
+	}
+	if terminated, ok := updateData["Terminated"]; ok {
+		updated.Terminated = fmt.Sprintf("%v", terminated)
+		if _, err := parseTime(updated.Terminated); err != nil {
+			return nil, err
+		}
+	}
+	if properties, ok := updateData["Properties"]; ok {
+		propertiesMap, ok := properties.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T for 'Properties'. Expected map[string]interface{}", properties)
+		}
+		// Existing properties not in the map are kept
+		updated.Properties = copyProperties(existing.Properties)
+		for key, value := range propertiesMap {
+			if relationships.IsReservedProperty(key) {
+				return nil, fmt.Errorf("relationship property '%s' is reserved", key)
+			}
+			updated.Properties[key] = value
+		}
+	}
+
+	// Validate the resulting interval against the integrity rules when the dates change
+	if updated.Created != existing.Created || updated.Terminated != existing.Terminated {
+		if err := s.validateRelationshipIntegrity(ctx, &updated); err != nil {
+			return nil, err
+		}
+	}
+	*existing = updated
+
+	result := map[string]interface{}{
+		"Id":         existing.ID,
+		"Created":    existing.Created,
+		"properties": copyProperties(existing.Properties),
+	}
+	if existing.Terminated != "" {
+		result["Terminated"] = existing.Terminated
+	}
+	return result, nil
+}
+
+// UpdateRelationship updates the created time, terminated time or properties of a relationship
+func (s *GraphStore) UpdateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.updateRelationship(ctx, relationshipID, updateData)
+}
+
+// DeleteRelationship removes a relationship
+func (s *GraphStore) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	if relationshipID == "" {
+		return fmt.Errorf("relationship Id cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.relationships[relationshipID]; !ok {
+		return fmt.Errorf("relationship with Id %s does not exist", relationshipID)
+	}
+	delete(s.relationships, relationshipID)
+	return nil
+}
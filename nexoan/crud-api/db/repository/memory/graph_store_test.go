@@ -0,0 +1,206 @@
+package memoryrepository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newTestEntity creates an entity with the given kind and lifetime
+func newTestEntity(t *testing.T, id string, major string, created string, terminated string) *pb.Entity {
+	name, err := anypb.New(wrapperspb.String(id + " name"))
+	assert.NoError(t, err)
+	return &pb.Entity{
+		Id:         id,
+		Kind:       &pb.Kind{Major: major, Minor: "Test"},
+		Name:       &pb.TimeBasedValue{StartTime: created, Value: name},
+		Created:    created,
+		Terminated: terminated,
+	}
+}
+
+// createTestEntities creates the given entities in the store
+func createTestEntities(t *testing.T, store *GraphStore, entities ...*pb.Entity) {
+	for _, entity := range entities {
+		success, err := store.HandleGraphEntityCreation(context.Background(), entity)
+		assert.NoError(t, err)
+		assert.True(t, success)
+	}
+}
+
+// createTestRelationship creates a relationship between two existing entities
+func createTestRelationship(store *GraphStore, id string, name string, parentID string, childID string, start string, end string) error {
+	return store.HandleGraphRelationshipsCreate(context.Background(), &pb.Entity{
+		Id: parentID,
+		Relationships: map[string]*pb.Relationship{
+			id: {Id: id, Name: name, RelatedEntityId: childID, StartTime: start, EndTime: end},
+		},
+	})
+}
+
+func TestGraphStoreEntities(t *testing.T) {
+	ctx := context.Background()
+	store := NewGraphStore(config.RelationshipIntegrityConfig{})
+	createTestEntities(t, store,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "person-2", "Person", "2021-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", ""),
+	)
+
+	// Duplicate IDs are rejected
+	_, err := store.HandleGraphEntityCreation(ctx, newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""))
+	assert.Error(t, err)
+
+	// Invalid timestamps are rejected
+	_, err = store.HandleGraphEntityCreation(ctx, newTestEntity(t, "person-3", "Person", "yesterday", ""))
+	assert.Error(t, err)
+
+	kind, name, created, terminated, err := store.GetGraphEntity(ctx, "person-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Person", kind.Major)
+	assert.Equal(t, "2020-01-01T00:00:00Z", created)
+	assert.Empty(t, terminated)
+	var nameValue wrapperspb.StringValue
+	assert.NoError(t, name.Value.UnmarshalTo(&nameValue))
+	assert.Equal(t, "person-1 name", nameValue.Value)
+
+	// The kind cannot be updated
+	_, err = store.HandleGraphEntityUpdate(ctx, &pb.Entity{Id: "person-1", Kind: &pb.Kind{Major: "Organisation"}})
+	assert.Error(t, err)
+
+	success, err := store.HandleGraphEntityUpdate(ctx, &pb.Entity{Id: "person-1", Terminated: "2022-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.True(t, success)
+	entity, err := store.ReadGraphEntity(ctx, "person-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-01-01T00:00:00Z", entity["Terminated"])
+
+	entities, err := store.HandleGraphEntityFilter(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "Person"}}})
+	assert.NoError(t, err)
+	assert.Len(t, entities, 2)
+	assert.Equal(t, "person-1", entities[0]["id"])
+	assert.Equal(t, "person-2", entities[1]["id"])
+
+	_, err = store.ReadGraphEntity(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestGraphStoreRelationships(t *testing.T) {
+	ctx := context.Background()
+	store := NewGraphStore(config.RelationshipIntegrityConfig{})
+	createTestEntities(t, store,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", ""),
+	)
+
+	assert.NoError(t, createTestRelationship(store, "rel-1", "WORKS_AT", "person-1", "org-1", "2020-06-01T00:00:00Z", ""))
+	assert.Error(t, createTestRelationship(store, "rel-2", "WORKS_AT", "person-1", "missing", "2020-06-01T00:00:00Z", ""))
+
+	relationship, err := store.GetGraphRelationship(ctx, "rel-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "person-1", relationship.EntityId)
+	assert.Equal(t, "org-1", relationship.Relationship.RelatedEntityId)
+
+	var notFoundErr *relationships.NotFoundError
+	_, err = store.GetGraphRelationship(ctx, "missing")
+	assert.True(t, errors.As(err, &notFoundErr))
+
+	// The relationship is outgoing from the person and incoming to the organisation
+	incoming, err := store.GetFilteredRelationships(ctx, "org-1", "", "", "", "", "", "INCOMING", nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "person-1", incoming["rel-1"].RelatedEntityId)
+	outgoing, err := store.GetFilteredRelationships(ctx, "org-1", "", "", "", "", "", "OUTGOING", nil, "")
+	assert.NoError(t, err)
+	assert.Empty(t, outgoing)
+
+	// Terminating the relationship hides it from queries at a later instant
+	_, err = store.UpdateRelationship(ctx, "rel-1", map[string]interface{}{"Terminated": "2021-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	active, err := store.GetRelationshipsByEndpoints(ctx, "person-1", "", "", "2022-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Empty(t, active)
+	active, err = store.GetRelationshipsByEndpoints(ctx, "person-1", "", "", "2020-07-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	// Immutable fields cannot be updated
+	err = store.HandleGraphRelationshipsUpdate(ctx, &pb.Entity{
+		Id:            "person-1",
+		Relationships: map[string]*pb.Relationship{"rel-1": {Id: "rel-1", Name: "MANAGES"}},
+	})
+	assert.Error(t, err)
+
+	assert.NoError(t, store.DeleteRelationship(ctx, "rel-1"))
+	assert.Error(t, store.DeleteRelationship(ctx, "rel-1"))
+}
+
+func TestGraphStoreIntegrityRules(t *testing.T) {
+	store := NewGraphStore(config.RelationshipIntegrityConfig{
+		EnforceEndpointLifetimes: true,
+		NonOverlappingTypes:      []string{"WORKS_AT"},
+		SingleValuedTypes:        []string{"LIVES_IN"},
+	})
+	createTestEntities(t, store,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", "2025-01-01T00:00:00Z"),
+		newTestEntity(t, "city-1", "City", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "city-2", "City", "2000-01-01T00:00:00Z", ""),
+	)
+
+	var integrityErr *relationships.IntegrityError
+
+	// Starts before the person was created
+	err := createTestRelationship(store, "rel-1", "WORKS_AT", "person-1", "org-1", "2019-01-01T00:00:00Z", "2021-01-01T00:00:00Z")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	// Open ended while the organisation is terminated
+	err = createTestRelationship(store, "rel-1", "WORKS_AT", "person-1", "org-1", "2021-01-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	assert.NoError(t, createTestRelationship(store, "rel-1", "WORKS_AT", "person-1", "org-1", "2021-01-01T00:00:00Z", "2022-01-01T00:00:00Z"))
+	err = createTestRelationship(store, "rel-2", "WORKS_AT", "person-1", "org-1", "2021-06-01T00:00:00Z", "2023-01-01T00:00:00Z")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.NonOverlappingRule, integrityErr.Rule)
+	// Adjacent intervals do not overlap
+	assert.NoError(t, createTestRelationship(store, "rel-2", "WORKS_AT", "person-1", "org-1", "2022-01-01T00:00:00Z", "2023-01-01T00:00:00Z"))
+
+	assert.NoError(t, createTestRelationship(store, "rel-3", "LIVES_IN", "person-1", "city-1", "2020-01-01T00:00:00Z", ""))
+	err = createTestRelationship(store, "rel-4", "LIVES_IN", "person-1", "city-2", "2021-01-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.SingleValuedRule, integrityErr.Rule)
+}
+
+func TestGraphStoreRelationshipTypes(t *testing.T) {
+	store := NewGraphStore(config.RelationshipIntegrityConfig{})
+	assert.NoError(t, store.RelationshipTypes().Load([]*pb.RelationshipType{{
+		Name:        "HEADS",
+		SourceKinds: []*pb.Kind{{Major: "Person"}},
+		TargetKinds: []*pb.Kind{{Major: "Organisation"}},
+		Cardinality: pb.Cardinality_ONE_TO_ONE,
+	}}))
+	createTestEntities(t, store,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "person-2", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", ""),
+	)
+
+	// The source kind must match the declared type
+	assert.Error(t, createTestRelationship(store, "rel-1", "HEADS", "org-1", "person-1", "2020-01-01T00:00:00Z", ""))
+
+	assert.NoError(t, createTestRelationship(store, "rel-1", "HEADS", "person-1", "org-1", "2020-01-01T00:00:00Z", "2021-01-01T00:00:00Z"))
+	var integrityErr *relationships.IntegrityError
+	err := createTestRelationship(store, "rel-2", "HEADS", "person-2", "org-1", "2020-06-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.CardinalityRule, integrityErr.Rule)
+	assert.NoError(t, createTestRelationship(store, "rel-2", "HEADS", "person-2", "org-1", "2021-01-01T00:00:00Z", ""))
+}
@@ -0,0 +1,42 @@
+package memoryrepository
+
+import (
+	"context"
+	"fmt"
+
+	commons "lk/datafoundation/crud-api/commons"
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+)
+
+// CreateIndexes is a no-op since entities are kept in a map by ID
+func (s *GraphStore) CreateIndexes(ctx context.Context) error {
+	return nil
+}
+
+// CreateIndexes is a no-op since metadata is kept in a map by entity ID
+func (s *MetadataStore) CreateIndexes(ctx context.Context) error {
+	return nil
+}
+
+// CreateIndexes is a no-op since tables are scanned in memory
+func (s *TabularStore) CreateIndexes(ctx context.Context) error {
+	return nil
+}
+
+// CreateIndex checks that the table has the given columns and returns the name the PostgreSQL repository gives
+// the index, nothing is indexed since tables are scanned in memory
+func (s *TabularStore) CreateIndex(ctx context.Context, tableName string, columns []string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableName]
+	if !ok {
+		return "", fmt.Errorf("error creating index on %s: %w", tableName, commons.ErrNotFound)
+	}
+	for _, column := range columns {
+		if !table.hasColumn(column) {
+			return "", fmt.Errorf("error creating index on %s: unknown column %s", tableName, column)
+		}
+	}
+	return postgres.IndexName(tableName, columns), nil
+}
@@ -0,0 +1,35 @@
+package memoryrepository
+
+import (
+	"context"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTabularStoreCreateIndex(t *testing.T) {
+	ctx := context.Background()
+	store := NewTabularStore()
+	assert.NoError(t, store.CreateIndexes(ctx))
+
+	_, err := store.CreateIndex(ctx, "attr_org_2d1__units", []string{"unit"})
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"Unit", "staff"},
+		[]interface{}{[]interface{}{"Records", 3}})
+	assert.NoError(t, store.HandleTabularData(ctx, "org-1", "units", value, schemaInfo))
+
+	// The index is named like the PostgreSQL one
+	name, err := store.CreateIndex(ctx, "attr_org_2d1__units", []string{"Unit", "staff"})
+	assert.NoError(t, err)
+	assert.Equal(t, postgres.IndexName("attr_org_2d1__units", []string{"Unit", "staff"}), name)
+
+	_, err = store.CreateIndex(ctx, "attr_org_2d1__units", []string{"missing"})
+	assert.Error(t, err)
+	_, err = store.CreateIndex(ctx, "attr_org_2d1__units", []string{"unit"})
+	assert.Error(t, err, "Expected column names to be exact")
+}
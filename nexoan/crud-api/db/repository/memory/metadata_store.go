@@ -0,0 +1,199 @@
+package memoryrepository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// MetadataStore keeps entity metadata, the values of scalar, document and graph attributes and the kind
+// and relationship type registries in memory. Like the MongoDB repository only the id, the metadata history
+// and the attribute values of an entity are stored.
+type MetadataStore struct {
+	mu                sync.RWMutex
+	entities          map[string][]*pb.MetadataVersion
+	attributeValues   map[string]map[string][]*pb.TimeBasedValue
+	relationshipTypes map[string]*pb.RelationshipType
+	kindSchemas       map[string]*pb.KindSchema
+}
+
+// NewMetadataStore creates an empty in-memory metadata store
+func NewMetadataStore() *MetadataStore {
+	return &MetadataStore{
+		entities:          make(map[string][]*pb.MetadataVersion),
+		attributeValues:   make(map[string]map[string][]*pb.TimeBasedValue),
+		relationshipTypes: make(map[string]*pb.RelationshipType),
+		kindSchemas:       make(map[string]*pb.KindSchema),
+	}
+}
+
+// Close is a no-op, it exists so the store can be used in place of the MongoDB repository
+func (s *MetadataStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// Ping always succeeds since the store lives in memory
+func (s *MetadataStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CreateEntity stores the metadata of a new entity, valid from its creation
+func (s *MetadataStore) CreateEntity(ctx context.Context, entity *pb.Entity) error {
+	versions, err := metadatahistory.Apply(nil, &metadatahistory.Change{Set: entity.Metadata, ValidFrom: entity.Created})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.entities[entity.Id]; exists {
+		return fmt.Errorf("entity with Id %s already exists", entity.Id)
+	}
+	s.entities[entity.Id] = versions
+	return nil
+}
+
+// ReadEntity fetches an entity by ID, the error wraps commons.ErrNotFound when it does not exist
+func (s *MetadataStore) ReadEntity(ctx context.Context, id string) (*pb.Entity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions, ok := s.entities[id]
+	if !ok {
+		return nil, fmt.Errorf("entity with Id %s: %w", id, commons.ErrNotFound)
+	}
+	return &pb.Entity{Id: id, Metadata: metadatahistory.Current(versions)}, nil
+}
+
+// DeleteEntity removes an entity and its attribute values and reports whether it existed
+func (s *MetadataStore) DeleteEntity(ctx context.Context, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attributeValues, id)
+	if _, ok := s.entities[id]; !ok {
+		return false, nil
+	}
+	delete(s.entities, id)
+	return true, nil
+}
+
+// HandleMetadata applies a change to the metadata of an entity, empty changes are skipped
+func (s *MetadataStore) HandleMetadata(ctx context.Context, entityId string, change *metadatahistory.Change) error {
+	if change.IsEmpty() {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, err := metadatahistory.Apply(s.entities[entityId], change)
+	if err != nil {
+		return err
+	}
+	s.entities[entityId] = versions
+	return nil
+}
+
+// GetMetadata returns the metadata of an entity now or at activeAt, or an empty map if it has none
+func (s *MetadataStore) GetMetadata(ctx context.Context, entityId string, activeAt string) (map[string]*anypb.Any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return metadatahistory.Read(s.entities[entityId], activeAt)
+}
+
+// GetMetadataHistory returns the versions of a metadata key of an entity, or of every key when key is empty
+func (s *MetadataStore) GetMetadataHistory(ctx context.Context, entityId string, key string) ([]*pb.MetadataVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return metadatahistory.Select(s.entities[entityId], key), nil
+}
+
+// SaveAttributeValue stores a value of an attribute, replacing the value of the attribute with the same start time
+func (s *MetadataStore) SaveAttributeValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attributes, ok := s.attributeValues[entityID]
+	if !ok {
+		attributes = make(map[string][]*pb.TimeBasedValue)
+		s.attributeValues[entityID] = attributes
+	}
+	values := attributes[attrName]
+	i := sort.Search(len(values), func(i int) bool { return values[i].StartTime >= value.StartTime })
+	stored := proto.Clone(value).(*pb.TimeBasedValue)
+	if i < len(values) && values[i].StartTime == value.StartTime {
+		values[i] = stored
+		return nil
+	}
+	attributes[attrName] = append(values[:i], append([]*pb.TimeBasedValue{stored}, values[i:]...)...)
+	return nil
+}
+
+// GetAttributeValues returns the values of an attribute ordered by start time, the error wraps commons.ErrNotFound
+// when the attribute has none
+func (s *MetadataStore) GetAttributeValues(ctx context.Context, entityID, attrName string) ([]*pb.TimeBasedValue, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	values := s.attributeValues[entityID][attrName]
+	if len(values) == 0 {
+		return nil, fmt.Errorf("values of attribute %s of entity %s: %w", attrName, entityID, commons.ErrNotFound)
+	}
+	result := make([]*pb.TimeBasedValue, len(values))
+	for i, value := range values {
+		result[i] = proto.Clone(value).(*pb.TimeBasedValue)
+	}
+	return result, nil
+}
+
+// SaveRelationshipType creates or replaces a relationship type in the registry
+func (s *MetadataStore) SaveRelationshipType(ctx context.Context, relType *pb.RelationshipType) error {
+	if relType == nil || relType.Name == "" {
+		return fmt.Errorf("relationship type name cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.relationshipTypes[relType.Name] = proto.Clone(relType).(*pb.RelationshipType)
+	return nil
+}
+
+// ReadRelationshipTypes returns every relationship type in the registry ordered by name
+func (s *MetadataStore) ReadRelationshipTypes(ctx context.Context) ([]*pb.RelationshipType, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var relTypes []*pb.RelationshipType
+	for _, relType := range s.relationshipTypes {
+		relTypes = append(relTypes, proto.Clone(relType).(*pb.RelationshipType))
+	}
+	sort.Slice(relTypes, func(i, j int) bool {
+		return relTypes[i].Name < relTypes[j].Name
+	})
+	return relTypes, nil
+}
+
+// SaveKindSchema creates or replaces a kind schema in the registry
+func (s *MetadataStore) SaveKindSchema(ctx context.Context, schema *pb.KindSchema) error {
+	if schema == nil || schema.Major == "" {
+		return fmt.Errorf("kind major cannot be empty")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.kindSchemas[schema.Major] = proto.Clone(schema).(*pb.KindSchema)
+	return nil
+}
+
+// ReadKindSchemas returns every kind schema in the registry ordered by major kind
+func (s *MetadataStore) ReadKindSchemas(ctx context.Context) ([]*pb.KindSchema, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var schemas []*pb.KindSchema
+	for _, schema := range s.kindSchemas {
+		schemas = append(schemas, proto.Clone(schema).(*pb.KindSchema))
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Major < schemas[j].Major
+	})
+	return schemas, nil
+}
@@ -0,0 +1,161 @@
+package memoryrepository
+
+import (
+	"context"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestMetadataStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetadataStore()
+
+	owner, err := anypb.New(wrapperspb.String("alice"))
+	assert.NoError(t, err)
+	entity := &pb.Entity{Id: "entity-1", Metadata: map[string]*anypb.Any{"owner": owner}}
+
+	// Changes without metadata are skipped
+	assert.NoError(t, store.HandleMetadata(ctx, "entity-2", &metadatahistory.Change{}))
+	_, err = store.ReadEntity(ctx, "entity-2")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+
+	assert.NoError(t, store.HandleMetadata(ctx, entity.Id, &metadatahistory.Change{Set: entity.Metadata}))
+	metadata, err := store.GetMetadata(ctx, entity.Id, "")
+	assert.NoError(t, err)
+	var value wrapperspb.StringValue
+	assert.NoError(t, metadata["owner"].UnmarshalTo(&value))
+	assert.Equal(t, "alice", value.Value)
+
+	// Stored metadata is not shared with the caller
+	delete(metadata, "owner")
+	metadata, err = store.GetMetadata(ctx, entity.Id, "")
+	assert.NoError(t, err)
+	assert.Len(t, metadata, 1)
+
+	err = store.CreateEntity(ctx, entity)
+	assert.Error(t, err)
+
+	deleted, err := store.DeleteEntity(ctx, entity.Id)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	metadata, err = store.GetMetadata(ctx, entity.Id, "")
+	assert.NoError(t, err)
+	assert.Empty(t, metadata)
+}
+
+func TestMetadataStoreHistory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetadataStore()
+
+	first, err := anypb.New(wrapperspb.String("A"))
+	assert.NoError(t, err)
+	second, err := anypb.New(wrapperspb.String("B"))
+	assert.NoError(t, err)
+	gazette, err := anypb.New(wrapperspb.String("2019/1"))
+	assert.NoError(t, err)
+	err = store.CreateEntity(ctx, &pb.Entity{
+		Id:       "ministry-1",
+		Created:  "2019-12-01T00:00:00Z",
+		Metadata: map[string]*anypb.Any{"minister": first, "gazette": gazette},
+	})
+	assert.NoError(t, err)
+
+	// A partial update only changes the keys it names
+	assert.NoError(t, store.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{
+		Set:        map[string]*anypb.Any{"minister": second},
+		ValidFrom:  "2022-07-22T00:00:00Z",
+		RecordedBy: "ingest-job",
+	}))
+	metadata, err := store.GetMetadata(ctx, "ministry-1", "")
+	assert.NoError(t, err)
+	assert.Len(t, metadata, 2)
+	var value wrapperspb.StringValue
+	assert.NoError(t, metadata["minister"].UnmarshalTo(&value))
+	assert.Equal(t, "B", value.Value)
+
+	metadata, err = store.GetMetadata(ctx, "ministry-1", "2020-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.NoError(t, metadata["minister"].UnmarshalTo(&value))
+	assert.Equal(t, "A", value.Value)
+	_, err = store.GetMetadata(ctx, "ministry-1", "2020")
+	assert.Error(t, err)
+
+	history, err := store.GetMetadataHistory(ctx, "ministry-1", "minister")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "2019-12-01T00:00:00Z", history[0].ValidFrom)
+	assert.Equal(t, "2022-07-22T00:00:00Z", history[0].ValidTo)
+	assert.Equal(t, "ingest-job", history[1].RecordedBy)
+
+	// A rejected change leaves the history as it was
+	assert.Error(t, store.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{Remove: []string{"minister"}, ValidFrom: "2021-01-01T00:00:00Z"}))
+	assert.NoError(t, store.HandleMetadata(ctx, "ministry-1", &metadatahistory.Change{Remove: []string{"gazette"}, ValidFrom: "2023-01-01T00:00:00Z"}))
+	entity, err := store.ReadEntity(ctx, "ministry-1")
+	assert.NoError(t, err)
+	assert.Len(t, entity.Metadata, 1)
+	history, err = store.GetMetadataHistory(ctx, "ministry-1", "")
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+}
+
+func TestMetadataStoreAttributeValues(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetadataStore()
+	value := func(start string, number int64) *pb.TimeBasedValue {
+		packed, err := anypb.New(wrapperspb.Int64(number))
+		assert.NoError(t, err)
+		return &pb.TimeBasedValue{StartTime: start, Value: packed}
+	}
+	number := func(value *pb.TimeBasedValue) int64 {
+		var number wrapperspb.Int64Value
+		assert.NoError(t, value.Value.UnmarshalTo(&number))
+		return number.Value
+	}
+
+	_, err := store.GetAttributeValues(ctx, "entity-1", "headcount")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+
+	// Values are ordered by start time and a value with the same start time is replaced
+	assert.NoError(t, store.SaveAttributeValue(ctx, "entity-1", "headcount", value("2024-01-01T00:00:00Z", 45)))
+	assert.NoError(t, store.SaveAttributeValue(ctx, "entity-1", "headcount", value("2023-01-01T00:00:00Z", 40)))
+	assert.NoError(t, store.SaveAttributeValue(ctx, "entity-1", "headcount", value("2024-01-01T00:00:00Z", 42)))
+	values, err := store.GetAttributeValues(ctx, "entity-1", "headcount")
+	assert.NoError(t, err)
+	if assert.Len(t, values, 2) {
+		assert.Equal(t, int64(40), number(values[0]))
+		assert.Equal(t, int64(42), number(values[1]))
+	}
+
+	// The values are removed with the entity
+	assert.NoError(t, store.CreateEntity(ctx, &pb.Entity{Id: "entity-1"}))
+	deleted, err := store.DeleteEntity(ctx, "entity-1")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = store.GetAttributeValues(ctx, "entity-1", "headcount")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+}
+
+func TestMetadataStoreRegistries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMetadataStore()
+
+	assert.NoError(t, store.SaveRelationshipType(ctx, &pb.RelationshipType{Name: "WORKS_AT"}))
+	assert.NoError(t, store.SaveRelationshipType(ctx, &pb.RelationshipType{Name: "HEADS"}))
+	assert.Error(t, store.SaveRelationshipType(ctx, &pb.RelationshipType{}))
+	relTypes, err := store.ReadRelationshipTypes(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, relTypes, 2)
+	assert.Equal(t, "HEADS", relTypes[0].Name)
+
+	assert.NoError(t, store.SaveKindSchema(ctx, &pb.KindSchema{Major: "Person"}))
+	schemas, err := store.ReadKindSchemas(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+}
@@ -0,0 +1,90 @@
+package memoryrepository
+
+import (
+	"context"
+	"sort"
+
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/search"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+)
+
+// sortMatches orders matches by entity ID and field and keeps at most search.MaxMatches of them
+func sortMatches(matches []search.Match) []search.Match {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].EntityID != matches[j].EntityID {
+			return matches[i].EntityID < matches[j].EntityID
+		}
+		if matches[i].Field != matches[j].Field {
+			return matches[i].Field < matches[j].Field
+		}
+		return matches[i].Text < matches[j].Text
+	})
+	if len(matches) > search.MaxMatches {
+		matches = matches[:search.MaxMatches]
+	}
+	return matches
+}
+
+// CreateSearchIndexes is a no-op since names are searched by scanning the entities
+func (s *GraphStore) CreateSearchIndexes(ctx context.Context) error {
+	return nil
+}
+
+// SearchEntityNames returns the entities of the query kinds whose name contains every term of the query
+func (s *GraphStore) SearchEntityNames(ctx context.Context, query search.Query) ([]search.Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matches []search.Match
+	for _, entity := range s.entities {
+		if query.MatchesKind(entity.kind()) && query.MatchesText(entity.Name) {
+			matches = append(matches, search.Match{EntityID: entity.ID, Field: search.NameField, Text: entity.Name})
+		}
+	}
+	return sortMatches(matches), nil
+}
+
+// CreateSearchIndexes is a no-op since metadata is searched by scanning the entities
+func (s *MetadataStore) CreateSearchIndexes(ctx context.Context) error {
+	return nil
+}
+
+// SearchMetadata returns the current metadata values that contain every term of the query
+func (s *MetadataStore) SearchMetadata(ctx context.Context, query search.Query) ([]search.Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matches []search.Match
+	for entityID, versions := range s.entities {
+		matches = append(matches, query.MatchMetadata(entityID, metadatahistory.Current(versions))...)
+	}
+	return sortMatches(matches), nil
+}
+
+// SearchTabular returns the string cells of tabular attributes that contain every term of the query.
+// A text repeated in a column is matched once.
+func (s *TabularStore) SearchTabular(ctx context.Context, query search.Query) ([]search.Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matches []search.Match
+	for _, table := range s.tables {
+		for _, column := range table.columns {
+			if table.types[column] != typeinference.StringType {
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, row := range table.rows {
+				text, ok := row[column].(string)
+				if !ok || seen[text] || !query.MatchesText(text) {
+					continue
+				}
+				seen[text] = true
+				matches = append(matches, search.Match{
+					EntityID: table.entityID,
+					Field:    search.AttributeField(table.attrName, column),
+					Text:     text,
+				})
+			}
+		}
+	}
+	return sortMatches(matches), nil
+}
@@ -0,0 +1,58 @@
+package memoryrepository
+
+import (
+	"context"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	query, err := search.NewQuery("defence ministry", []*pb.Kind{{Major: "Organisation"}})
+	assert.NoError(t, err)
+
+	graph := NewGraphStore(config.RelationshipIntegrityConfig{})
+	for id, name := range map[string]string{"org-1": "Ministry of Défence", "org-2": "Ministry of Finance"} {
+		entity := newTestEntity(t, id, "Organisation", "2024-01-01T00:00:00Z", "")
+		entity.Name.Value, err = anypb.New(wrapperspb.String(name))
+		assert.NoError(t, err)
+		createTestEntities(t, graph, entity)
+	}
+	person := newTestEntity(t, "person-1", "Person", "2024-01-01T00:00:00Z", "")
+	person.Name.Value, err = anypb.New(wrapperspb.String("Defence Ministry Spokesperson"))
+	assert.NoError(t, err)
+	createTestEntities(t, graph, person)
+
+	matches, err := graph.SearchEntityNames(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "org-1", Field: "name", Text: "Ministry of Défence"}}, matches)
+
+	metadata := NewMetadataStore()
+	mandate, err := anypb.New(wrapperspb.String("Defence policy of the ministry"))
+	assert.NoError(t, err)
+	assert.NoError(t, metadata.HandleMetadata(ctx, "org-2", &metadatahistory.Change{Set: map[string]*anypb.Any{"mandate": mandate}}))
+	matches, err = metadata.SearchMetadata(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "org-2", Field: "metadata.mandate", Text: "Defence policy of the ministry"}}, matches)
+
+	tabular := NewTabularStore()
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"unit", "staff"},
+		[]interface{}{
+			[]interface{}{"Defence Ministry secretariat", 12},
+			[]interface{}{"Defence Ministry secretariat", 4},
+			[]interface{}{"Records", 3},
+		})
+	assert.NoError(t, tabular.HandleTabularData(ctx, "org-2", "units", value, schemaInfo))
+	matches, err = tabular.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "org-2", Field: "attributes.units.unit", Text: "Defence Ministry secretariat"}}, matches)
+}
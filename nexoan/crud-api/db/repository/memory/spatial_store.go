@@ -0,0 +1,103 @@
+package memoryrepository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/geo"
+)
+
+// SpatialStore keeps the geometries of geospatial attributes in memory by attribute name and entity ID,
+// ordered by start time. Filters are evaluated by the geo package, which gives the same answers as PostGIS.
+type SpatialStore struct {
+	mu         sync.RWMutex
+	geometries map[string]map[string][]timedGeometry
+}
+
+// timedGeometry is a geometry with the time it started and ended, a zero end time leaves it open
+type timedGeometry struct {
+	geometry  *geo.Geometry
+	startTime time.Time
+	endTime   time.Time
+}
+
+// NewSpatialStore creates an empty in-memory spatial store
+func NewSpatialStore() *SpatialStore {
+	return &SpatialStore{
+		geometries: make(map[string]map[string][]timedGeometry),
+	}
+}
+
+// InitializeGeometries is a no-op since there is no table to create
+func (s *SpatialStore) InitializeGeometries(ctx context.Context) error {
+	return nil
+}
+
+// SaveGeometry stores the geometry an attribute has from startTime until endTime, replacing the geometry
+// with the same start time
+func (s *SpatialStore) SaveGeometry(ctx context.Context, entityID, attrName string, geometry *geo.Geometry, startTime, endTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.geometries[attrName] == nil {
+		s.geometries[attrName] = make(map[string][]timedGeometry)
+	}
+	versions := s.geometries[attrName][entityID]
+	i := sort.Search(len(versions), func(i int) bool { return !versions[i].startTime.Before(startTime) })
+	version := timedGeometry{geometry: geometry, startTime: startTime, endTime: endTime}
+	if i < len(versions) && versions[i].startTime.Equal(startTime) {
+		versions[i] = version
+		return nil
+	}
+	s.geometries[attrName][entityID] = append(versions[:i], append([]timedGeometry{version}, versions[i:]...)...)
+	return nil
+}
+
+// GetGeometry returns the latest geometry of an attribute, the error wraps commons.ErrNotFound when there is none
+func (s *SpatialStore) GetGeometry(ctx context.Context, entityID, attrName string) (*geo.Geometry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	versions := s.geometries[attrName][entityID]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("error getting geometry of attribute %s: %w", attrName, commons.ErrNotFound)
+	}
+	return versions[len(versions)-1].geometry, nil
+}
+
+// DeleteGeometry removes every geometry of an attribute
+func (s *SpatialStore) DeleteGeometry(ctx context.Context, entityID, attrName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.geometries[attrName], entityID)
+	return nil
+}
+
+// FindEntities returns the sorted IDs of the entities whose attribute geometry at activeAt matches the filter
+func (s *SpatialStore) FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var entityIDs []string
+	for entityID, versions := range s.geometries[attrName] {
+		if geometry := geometryAt(versions, activeAt); geometry != nil && filter.Matches(geometry) {
+			entityIDs = append(entityIDs, entityID)
+		}
+	}
+	sort.Strings(entityIDs)
+	return entityIDs, nil
+}
+
+// geometryAt returns the latest geometry started by activeAt, or nil when there is none or it ended before
+func geometryAt(versions []timedGeometry, activeAt time.Time) *geo.Geometry {
+	i := sort.Search(len(versions), func(i int) bool { return versions[i].startTime.After(activeAt) }) - 1
+	if i < 0 || (!versions[i].endTime.IsZero() && !versions[i].endTime.After(activeAt)) {
+		return nil
+	}
+	return versions[i].geometry
+}
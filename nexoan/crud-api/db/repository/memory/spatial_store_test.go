@@ -0,0 +1,66 @@
+package memoryrepository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/geo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpatialStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewSpatialStore()
+	now := time.Now()
+
+	for entityID, geoJSON := range map[string]string{
+		"colombo": `{"type": "Polygon", "coordinates": [[[79.80, 6.85], [79.95, 6.85], [79.95, 7.00], [79.80, 7.00], [79.80, 6.85]]]}`,
+		"kandy":   `{"type": "Polygon", "coordinates": [[[80.55, 7.20], [80.75, 7.20], [80.75, 7.40], [80.55, 7.40], [80.55, 7.20]]]}`,
+		"office":  `{"type": "Point", "coordinates": [79.86, 6.93]}`,
+	} {
+		geometry, err := geo.ParseJSON([]byte(geoJSON))
+		assert.NoError(t, err)
+		assert.NoError(t, store.SaveGeometry(ctx, entityID, "boundary", geometry, time.Time{}, time.Time{}))
+	}
+
+	entityIDs, err := store.FindEntities(ctx, "boundary", geo.Filter{ContainsPoint: &geo.Position{Lon: 79.86, Lat: 6.93}}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo", "office"}, entityIDs)
+
+	entityIDs, err = store.FindEntities(ctx, "boundary", geo.Filter{IntersectsBox: &geo.Box{MinLon: 79, MinLat: 6, MaxLon: 81, MaxLat: 8}}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo", "kandy", "office"}, entityIDs)
+
+	_, err = store.FindEntities(ctx, "boundary", geo.Filter{IntersectsBox: &geo.Box{MinLon: 81, MaxLon: 79}}, now)
+	assert.Error(t, err)
+
+	// A later geometry replaces the earlier one from its start time, an ended geometry matches no more
+	moved, err := geo.ParseJSON([]byte(`{"type": "Point", "coordinates": [80.6, 7.3]}`))
+	assert.NoError(t, err)
+	movedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, store.SaveGeometry(ctx, "office", "boundary", moved, movedAt, time.Time{}))
+	assert.NoError(t, store.SaveGeometry(ctx, "colombo", "boundary", moved, movedAt, movedAt.AddDate(1, 0, 0)))
+	point := geo.Filter{ContainsPoint: &geo.Position{Lon: 80.6, Lat: 7.3}}
+	entityIDs, err = store.FindEntities(ctx, "boundary", point, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kandy", "office"}, entityIDs)
+	entityIDs, err = store.FindEntities(ctx, "boundary", point, movedAt.AddDate(0, 6, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo", "kandy", "office"}, entityIDs)
+	entityIDs, err = store.FindEntities(ctx, "boundary", point, movedAt.AddDate(0, 0, -1))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"kandy"}, entityIDs)
+	geometry, err := store.GetGeometry(ctx, "office", "boundary")
+	assert.NoError(t, err)
+	assert.Equal(t, geo.PointType, geometry.Type)
+
+	assert.NoError(t, store.DeleteGeometry(ctx, "office", "boundary"))
+	_, err = store.GetGeometry(ctx, "office", "boundary")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+	geometry, err = store.GetGeometry(ctx, "kandy", "boundary")
+	assert.NoError(t, err)
+	assert.Equal(t, geo.PolygonType, geometry.Type)
+}
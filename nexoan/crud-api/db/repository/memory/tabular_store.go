@@ -0,0 +1,258 @@
+package memoryrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	commons "lk/datafoundation/crud-api/commons"
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// idColumn is the row identifier returned with every row, like the PostgreSQL primary key
+const idColumn = "id"
+
+// memoryTable holds the rows of one tabular attribute
+type memoryTable struct {
+	entityID string
+	attrName string
+	schema   *schema.SchemaInfo
+	columns  []string // sanitized column names excluding the id column
+	types    map[string]typeinference.DataType
+	rows     []map[string]interface{}
+	nextID   int64
+}
+
+// TabularStore keeps tabular attribute data in memory.
+// Tables are named and validated the same way as in the PostgreSQL repository so that
+// the attribute engine behaves identically on both.
+type TabularStore struct {
+	mu     sync.RWMutex
+	tables map[string]*memoryTable
+}
+
+// NewTabularStore creates an empty in-memory tabular store
+func NewTabularStore() *TabularStore {
+	return &TabularStore{
+		tables: make(map[string]*memoryTable),
+	}
+}
+
+// Close is a no-op, it exists so the store can be used in place of the PostgreSQL repository
+func (s *TabularStore) Close() error {
+	return nil
+}
+
+// Ping always succeeds since the store lives in memory
+func (s *TabularStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// InitializeTables is a no-op since tables are created on the first write
+func (s *TabularStore) InitializeTables(ctx context.Context) error {
+	return nil
+}
+
+// newMemoryTable creates the table of an attribute with a column for every field of the schema
+func newMemoryTable(entityID, attrName string, schemaInfo *schema.SchemaInfo) *memoryTable {
+	table := &memoryTable{
+		entityID: entityID,
+		attrName: attrName,
+		schema:   schemaInfo,
+		types:    make(map[string]typeinference.DataType),
+		nextID:   1,
+	}
+	for column, field := range schemaInfo.Fields {
+		if field.TypeInfo != nil {
+			table.types[column] = field.TypeInfo.Type
+		}
+		// The id column is always present
+		if column == idColumn {
+			continue
+		}
+		table.columns = append(table.columns, column)
+	}
+	sort.Strings(table.columns)
+	return table
+}
+
+// hasColumn checks if a column belongs to the table
+func (t *memoryTable) hasColumn(column string) bool {
+	if column == idColumn {
+		return true
+	}
+	_, ok := t.types[column]
+	return ok
+}
+
+// AttributeTable returns the table holding the rows of an attribute, every table is named by commons.AttributeTableName
+func (s *TabularStore) AttributeTable(ctx context.Context, entityID, attrName string) (string, error) {
+	return commons.AttributeTableName(entityID, attrName), nil
+}
+
+// HandleTabularData validates tabular data against the schema of its table and appends its rows.
+// The table is created with the given schema on the first write.
+func (s *TabularStore) HandleTabularData(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, schemaInfo *schema.SchemaInfo) error {
+	tableName := commons.AttributeTableName(entityID, attrName)
+
+	var tabularStruct structpb.Struct
+	if err := value.GetValue().UnmarshalTo(&tabularStruct); err != nil {
+		return fmt.Errorf("error unmarshaling tabular data: %v", err)
+	}
+	columnsValue := tabularStruct.Fields["columns"].GetListValue()
+	rowsValue := tabularStruct.Fields["rows"].GetListValue()
+	if columnsValue == nil || rowsValue == nil {
+		return fmt.Errorf("invalid tabular data format")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table, exists := s.tables[tableName]
+	if exists {
+		compatible, err := postgres.CompareSchemas(table.schema, schemaInfo)
+		if err != nil {
+			return fmt.Errorf("schema compatibility check failed: %v", err)
+		}
+		if !compatible {
+			return fmt.Errorf("incompatible schema changes detected")
+		}
+		if err := postgres.ValidateDataAgainstSchema(&tabularStruct, table.schema); err != nil {
+			return fmt.Errorf("data validation failed: %v", err)
+		}
+	} else {
+		for fieldName := range schemaInfo.Fields {
+			if err := postgres.ValidateColumnName(fieldName); err != nil {
+				return err
+			}
+		}
+		table = newMemoryTable(entityID, attrName, schemaInfo)
+	}
+
+	columnNames := make([]string, len(columnsValue.Values))
+	for i, col := range columnsValue.Values {
+		if err := postgres.ValidateColumnName(col.GetStringValue()); err != nil {
+			return err
+		}
+		columnNames[i] = col.GetStringValue()
+		if !table.hasColumn(columnNames[i]) {
+			return fmt.Errorf("error inserting tabular data: column %s does not exist in %s", columnNames[i], tableName)
+		}
+	}
+
+	// Build every row before storing any so a bad row does not leave a partial insert behind
+	rows := make([]map[string]interface{}, len(rowsValue.Values))
+	nextID := table.nextID
+	for i, row := range rowsValue.Values {
+		rowList := row.GetListValue()
+		if rowList == nil {
+			return fmt.Errorf("invalid row format at index %d", i)
+		}
+		if len(rowList.Values) != len(columnNames) {
+			return fmt.Errorf("error inserting tabular data: row %d has %d values for %d columns", i, len(rowList.Values), len(columnNames))
+		}
+
+		rows[i] = make(map[string]interface{}, len(columnNames)+1)
+		for j, cell := range rowList.Values {
+			rows[i][columnNames[j]] = typeinference.ColumnValue(cell, table.types[columnNames[j]])
+		}
+		if _, ok := rows[i][idColumn]; !ok {
+			rows[i][idColumn] = nextID
+			nextID++
+		}
+	}
+
+	table.rows = append(table.rows, rows...)
+	table.nextID = nextID
+	s.tables[tableName] = table
+	return nil
+}
+
+// GetData returns the rows of a table matching every filter, restricted to the given fields.
+// The result has the same shape as the PostgreSQL repository: a struct holding the tabular data as JSON.
+func (s *TabularStore) GetData(ctx context.Context, tableName string, filters map[string]interface{}, fields ...string) (*anypb.Any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("error querying data from %s: table does not exist", tableName)
+	}
+
+	columns := append([]string{idColumn}, table.columns...)
+	if len(fields) > 0 {
+		columns = fields
+		for _, field := range fields {
+			if !table.hasColumn(field) {
+				return nil, fmt.Errorf("error querying data from %s: column %s does not exist", tableName, field)
+			}
+		}
+	}
+
+	filterColumns := make(map[string]interface{}, len(filters))
+	for key, value := range filters {
+		if !table.hasColumn(key) {
+			return nil, fmt.Errorf("error querying data from %s: column %s does not exist", tableName, key)
+		}
+		filterColumns[key] = value
+	}
+
+	var tabularRows [][]interface{}
+	for _, row := range table.rows {
+		if !matchesFilters(row, filterColumns) {
+			continue
+		}
+		values := make([]interface{}, len(columns))
+		for i, column := range columns {
+			values[i] = row[column]
+		}
+		tabularRows = append(tabularRows, values)
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"columns": columns,
+		"rows":    tabularRows,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling tabular data to JSON: %v", err)
+	}
+
+	structValue, err := structpb.NewStruct(map[string]interface{}{
+		"data": string(jsonData),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating struct for JSON data: %v", err)
+	}
+	return anypb.New(structValue)
+}
+
+// GetSchemaOfTable returns the schema the table was created with
+func (s *TabularStore) GetSchemaOfTable(ctx context.Context, tableName string) (*schema.SchemaInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	table, ok := s.tables[tableName]
+	if !ok {
+		return nil, fmt.Errorf("error getting schema for table %s: %w", tableName, commons.ErrNotFound)
+	}
+	return table.schema, nil
+}
+
+// matchesFilters checks if a row has the filtered value in every filtered column.
+// Values are compared by their string form so that numbers match regardless of their Go type.
+func matchesFilters(row map[string]interface{}, filters map[string]interface{}) bool {
+	for column, expected := range filters {
+		if fmt.Sprintf("%v", row[column]) != fmt.Sprintf("%v", expected) {
+			return false
+		}
+	}
+	return true
+}
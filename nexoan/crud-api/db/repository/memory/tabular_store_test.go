@@ -0,0 +1,88 @@
+package memoryrepository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/schema"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newTabularValue creates a tabular value and its schema from columns and rows
+func newTabularValue(t *testing.T, columns []interface{}, rows []interface{}) (*pb.TimeBasedValue, *schema.SchemaInfo) {
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": columns,
+		"rows":    rows,
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	schemaInfo, err := schema.GenerateSchema(value)
+	assert.NoError(t, err)
+	return &pb.TimeBasedValue{StartTime: "2024-01-01T00:00:00Z", Value: value}, schemaInfo
+}
+
+// readTabularData unpacks the JSON data returned by GetData
+func readTabularData(t *testing.T, result *anypb.Any) (columns []string, rows [][]interface{}) {
+	var data structpb.Struct
+	assert.NoError(t, result.UnmarshalTo(&data))
+	var tabular struct {
+		Columns []string        `json:"columns"`
+		Rows    [][]interface{} `json:"rows"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(data.Fields["data"].GetStringValue()), &tabular))
+	return tabular.Columns, tabular.Rows
+}
+
+func TestTabularStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewTabularStore()
+
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"name", "age"},
+		[]interface{}{
+			[]interface{}{"Alice", 30},
+			[]interface{}{"Bob", 25},
+		})
+	assert.NoError(t, store.HandleTabularData(ctx, "entity-1", "people", value, schemaInfo))
+
+	// Rows are appended to the existing table
+	more, moreSchema := newTabularValue(t,
+		[]interface{}{"name", "age"},
+		[]interface{}{[]interface{}{"Carol", 41}})
+	assert.NoError(t, store.HandleTabularData(ctx, "entity-1", "people", more, moreSchema))
+
+	result, err := store.GetData(ctx, "attr_entity_2d1__people", nil)
+	assert.NoError(t, err)
+	columns, rows := readTabularData(t, result)
+	assert.Equal(t, []string{"id", "age", "name"}, columns)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []interface{}{float64(3), float64(41), "Carol"}, rows[2])
+
+	result, err = store.GetData(ctx, "attr_entity_2d1__people", map[string]interface{}{"name": "Bob"}, "age")
+	assert.NoError(t, err)
+	columns, rows = readTabularData(t, result)
+	assert.Equal(t, []string{"age"}, columns)
+	assert.Equal(t, [][]interface{}{{float64(25)}}, rows)
+
+	// Unknown tables and columns are rejected
+	_, err = store.GetData(ctx, "attr_entity_2d1__missing", nil)
+	assert.Error(t, err)
+	_, err = store.GetData(ctx, "attr_entity_2d1__people", nil, "salary")
+	assert.Error(t, err)
+
+	// Incompatible data is rejected without storing any row
+	bad, badSchema := newTabularValue(t,
+		[]interface{}{"name", "age"},
+		[]interface{}{[]interface{}{"Dave", "old"}})
+	assert.Error(t, store.HandleTabularData(ctx, "entity-1", "people", bad, badSchema))
+	result, err = store.GetData(ctx, "attr_entity_2d1__people", nil)
+	assert.NoError(t, err)
+	_, rows = readTabularData(t, result)
+	assert.Len(t, rows, 3)
+}
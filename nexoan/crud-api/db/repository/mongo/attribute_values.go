@@ -0,0 +1,99 @@
+package mongorepository
+
+import (
+	"context"
+	"fmt"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// defaultAttributeValuesCollection is used when no attribute values collection is configured
+const defaultAttributeValuesCollection = "attribute_values"
+
+// attributeValuesIndexName is the name of the index the values of an attribute are read with
+const attributeValuesIndexName = "entity_attribute_start"
+
+// attributeValueDocument is the stored form of a value of a scalar, document or graph attribute,
+// keyed by the entity, the attribute and the start time of the value
+type attributeValueDocument struct {
+	ID        string     `bson:"_id"`
+	EntityID  string     `bson:"entityId"`
+	Attribute string     `bson:"attribute"`
+	StartTime string     `bson:"startTime"`
+	EndTime   string     `bson:"endTime,omitempty"`
+	Value     *anypb.Any `bson:"value"`
+}
+
+func (repo *MongoRepository) attributeValuesCollection() *mongo.Collection {
+	collection := repo.config.AttributeValuesCollection
+	if collection == "" {
+		collection = defaultAttributeValuesCollection
+	}
+	return repo.client.Database(repo.config.DBName).Collection(collection)
+}
+
+// createAttributeValuesIndex creates the index the values of an attribute are read with
+func (repo *MongoRepository) createAttributeValuesIndex(ctx context.Context) error {
+	_, err := repo.attributeValuesCollection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "entityId", Value: 1}, {Key: "attribute", Value: 1}, {Key: "startTime", Value: 1}},
+		Options: options.Index().SetName(attributeValuesIndexName),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating attribute values index: %v", err)
+	}
+	return nil
+}
+
+// SaveAttributeValue stores a value of an attribute, replacing the value of the attribute with the same start time
+func (repo *MongoRepository) SaveAttributeValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) error {
+	doc := attributeValueDocument{
+		ID:        entityID + "|" + attrName + "|" + value.StartTime,
+		EntityID:  entityID,
+		Attribute: attrName,
+		StartTime: value.StartTime,
+		EndTime:   value.EndTime,
+		Value:     value.Value,
+	}
+	_, err := repo.attributeValuesCollection().ReplaceOne(ctx, bson.M{"_id": doc.ID}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error saving attribute value", "entity_id", entityID, "attribute", attrName, "error", err)
+		return fmt.Errorf("error saving value of attribute %s: %v", attrName, err)
+	}
+	return nil
+}
+
+// GetAttributeValues returns the values of an attribute ordered by start time, the error wraps commons.ErrNotFound
+// when the attribute has none
+func (repo *MongoRepository) GetAttributeValues(ctx context.Context, entityID, attrName string) ([]*pb.TimeBasedValue, error) {
+	cursor, err := repo.attributeValuesCollection().Find(ctx,
+		bson.M{"entityId": entityID, "attribute": attrName},
+		options.Find().SetSort(bson.D{{Key: "startTime", Value: 1}}))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading attribute values", "entity_id", entityID, "attribute", attrName, "error", err)
+		return nil, fmt.Errorf("error reading values of attribute %s: %v", attrName, err)
+	}
+	defer cursor.Close(ctx)
+
+	var values []*pb.TimeBasedValue
+	for cursor.Next(ctx) {
+		var doc attributeValueDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("error decoding value of attribute %s: %v", attrName, err)
+		}
+		values = append(values, &pb.TimeBasedValue{StartTime: doc.StartTime, EndTime: doc.EndTime, Value: doc.Value})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over values of attribute %s: %v", attrName, err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("values of attribute %s of entity %s: %w", attrName, entityID, commons.ErrNotFound)
+	}
+	return values, nil
+}
@@ -0,0 +1,124 @@
+package mongorepository
+
+import (
+	"context"
+	"fmt"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultKindSchemasCollection is used when no kind schemas collection is configured
+const defaultKindSchemasCollection = "kind_schemas"
+
+type metadataFieldDocument struct {
+	Key      string `bson:"key"`
+	Type     string `bson:"type,omitempty"`
+	Required bool   `bson:"required,omitempty"`
+}
+
+type attributeFieldDocument struct {
+	Name        string `bson:"name"`
+	StorageType string `bson:"storageType,omitempty"`
+	Required    bool   `bson:"required,omitempty"`
+	JsonSchema  string `bson:"jsonSchema,omitempty"`
+}
+
+// kindSchemaDocument is the MongoDB representation of a KindSchema, keyed by the major kind
+type kindSchemaDocument struct {
+	Major       string                   `bson:"_id"`
+	Minors      []string                 `bson:"minors,omitempty"`
+	Metadata    []metadataFieldDocument  `bson:"metadata,omitempty"`
+	Attributes  []attributeFieldDocument `bson:"attributes,omitempty"`
+	Closed      bool                     `bson:"closed,omitempty"`
+	Description string                   `bson:"description,omitempty"`
+}
+
+func (repo *MongoRepository) kindSchemasCollection() *mongo.Collection {
+	collection := repo.config.KindSchemasCollection
+	if collection == "" {
+		collection = defaultKindSchemasCollection
+	}
+	return repo.client.Database(repo.config.DBName).Collection(collection)
+}
+
+func toKindSchemaDocument(schema *pb.KindSchema) kindSchemaDocument {
+	doc := kindSchemaDocument{
+		Major:       schema.Major,
+		Minors:      schema.Minors,
+		Closed:      schema.Closed,
+		Description: schema.Description,
+	}
+	for _, field := range schema.Metadata {
+		doc.Metadata = append(doc.Metadata, metadataFieldDocument{Key: field.Key, Type: field.Type, Required: field.Required})
+	}
+	for _, field := range schema.Attributes {
+		doc.Attributes = append(doc.Attributes, attributeFieldDocument{Name: field.Name, StorageType: field.StorageType, Required: field.Required, JsonSchema: field.JsonSchema})
+	}
+	return doc
+}
+
+func fromKindSchemaDocument(doc *kindSchemaDocument) *pb.KindSchema {
+	schema := &pb.KindSchema{
+		Major:       doc.Major,
+		Minors:      doc.Minors,
+		Closed:      doc.Closed,
+		Description: doc.Description,
+	}
+	for _, field := range doc.Metadata {
+		schema.Metadata = append(schema.Metadata, &pb.MetadataFieldSchema{Key: field.Key, Type: field.Type, Required: field.Required})
+	}
+	for _, field := range doc.Attributes {
+		schema.Attributes = append(schema.Attributes, &pb.AttributeFieldSchema{Name: field.Name, StorageType: field.StorageType, Required: field.Required, JsonSchema: field.JsonSchema})
+	}
+	return schema
+}
+
+// SaveKindSchema creates or replaces a kind schema in the registry
+func (repo *MongoRepository) SaveKindSchema(ctx context.Context, schema *pb.KindSchema) error {
+	if schema == nil || schema.Major == "" {
+		return fmt.Errorf("kind major cannot be empty")
+	}
+	_, err := repo.kindSchemasCollection().ReplaceOne(ctx, bson.M{"_id": schema.Major}, toKindSchemaDocument(schema), options.Replace().SetUpsert(true))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error saving kind schema", "kind", schema.Major, "error", err)
+		return fmt.Errorf("error saving kind schema %s: %v", schema.Major, err)
+	}
+	return nil
+}
+
+// ReadKindSchemas fetches every kind schema in the registry
+func (repo *MongoRepository) ReadKindSchemas(ctx context.Context) ([]*pb.KindSchema, error) {
+	cursor, err := repo.kindSchemasCollection().Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading kind schemas", "error", err)
+		return nil, fmt.Errorf("error reading kind schemas: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var schemas []*pb.KindSchema
+	for cursor.Next(ctx) {
+		var doc kindSchemaDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("error decoding kind schema: %v", err)
+		}
+		schemas = append(schemas, fromKindSchemaDocument(&doc))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over kind schemas: %v", err)
+	}
+	return schemas, nil
+}
+
+// DeleteKindSchema removes a kind schema from the registry and reports whether it existed
+func (repo *MongoRepository) DeleteKindSchema(ctx context.Context, major string) (bool, error) {
+	result, err := repo.kindSchemasCollection().DeleteOne(ctx, bson.M{"_id": major})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
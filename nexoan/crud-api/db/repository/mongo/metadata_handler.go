@@ -2,69 +2,362 @@ package mongorepository
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
 
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// Add this function to handle metadata operations
-func (repo *MongoRepository) HandleMetadata(ctx context.Context, entityId string, entity *pb.Entity) error {
-	// Skip operations if no metadata is provided
-	if entity == nil || entity.GetMetadata() == nil || len(entity.GetMetadata()) == 0 {
-		return nil
+// maxMetadataAttempts is the number of times a metadata change is tried when other changes of the same
+// entity are written between reading and writing its document
+const maxMetadataAttempts = 5
+
+// defaultMetadataHistoryCollection is used when no metadata history collection is configured
+const defaultMetadataHistoryCollection = "metadata_history"
+
+// historyIndexName is the name of the index the versions of an entity are read with
+const historyIndexName = "entity_key_revision"
+
+// historyCollection holds the versions of metadata values that are no longer current, one document per version,
+// so that the document of an entity only grows with its current metadata
+func (repo *MongoRepository) historyCollection() *mongo.Collection {
+	collection := repo.config.MetadataHistoryCollection
+	if collection == "" {
+		collection = defaultMetadataHistoryCollection
+	}
+	return repo.client.Database(repo.config.DBName).Collection(collection)
+}
+
+// CreateIndexes creates the indexes the metadata history and the attribute values of an entity are read with
+func (repo *MongoRepository) CreateIndexes(ctx context.Context) error {
+	_, err := repo.historyCollection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "entityId", Value: 1}, {Key: "key", Value: 1}, {Key: "revision", Value: 1}},
+		Options: options.Index().SetName(historyIndexName),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating metadata history index: %v", err)
+	}
+	return repo.createAttributeValuesIndex(ctx)
+}
+
+// readDocument reads the document of an entity, nil is returned when the entity has no metadata
+func (repo *MongoRepository) readDocument(ctx context.Context, entityId string) (*entityDocument, error) {
+	var doc entityDocument
+	err := repo.collection().FindOne(ctx, bson.M{"_id": entityId}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	return &doc, nil
+}
 
-	// Check if metadata for entity already exists
-	existingEntity, err := repo.ReadEntity(ctx, entityId)
-	if err != nil && err != mongo.ErrNoDocuments {
-		return err
+// currentVersions returns the versions of the current metadata values of a document ordered by key. The
+// metadata of a document stored before the history was kept has been valid since an unknown time.
+func currentVersions(doc *entityDocument) []versionDocument {
+	var current []versionDocument
+	if doc.Revision == 0 {
+		for _, version := range metadatahistory.FromMetadata(doc.Metadata) {
+			current = append(current, toVersionDocument(version, 0))
+		}
+		return current
 	}
+	for _, version := range doc.Current {
+		current = append(current, version)
+	}
+	sort.Slice(current, func(i, j int) bool {
+		return current[i].Key < current[j].Key
+	})
+	return current
+}
+
+// isFieldName reports whether a metadata key can be updated on its own through a field path
+func isFieldName(key string) bool {
+	return !strings.Contains(key, ".") && !strings.HasPrefix(key, "$")
+}
+
+// metadataUpdate returns the update of a document that records the versions after a change of its current
+// versions, and the versions the change ended. Only the keys the change sets or removes are written, unless
+// the document was stored before the history was kept or a key cannot be used as a field name.
+func metadataUpdate(doc *entityDocument, current []versionDocument, versions []*pb.MetadataVersion, revision int64) (bson.M, []versionDocument) {
+	set := bson.M{"revision": revision}
+	unset := bson.M{}
+	whole := doc.Revision == 0
 
-	if existingEntity == nil {
-		// Create new entity metadata with all fields including metadata
-		newEntity := &pb.Entity{
-			Id:            entityId,
-			Metadata:      entity.GetMetadata(),
-			Kind:          entity.Kind,
-			Created:       entity.Created,
-			Terminated:    entity.Terminated,
-			Name:          entity.Name,
-			Attributes:    entity.Attributes,
-			Relationships: entity.Relationships,
+	next := make(map[string]versionDocument, len(current))
+	ended := []versionDocument{}
+	for i, version := range current {
+		if versions[i].ValidTo == "" {
+			next[version.Key] = version
+			continue
 		}
-		_, err = repo.CreateEntity(ctx, newEntity)
+		version.EntityID = doc.ID
+		version.ValidTo = versions[i].ValidTo
+		ended = append(ended, version)
+		whole = whole || !isFieldName(version.Key)
+	}
+	added := make(map[string]versionDocument)
+	for _, version := range versions[len(current):] {
+		added[version.Key] = toVersionDocument(version, revision)
+		next[version.Key] = added[version.Key]
+		whole = whole || !isFieldName(version.Key)
+	}
+
+	metadata := make(map[string]*anypb.Any, len(next))
+	for key, version := range next {
+		metadata[key] = version.Value
+	}
+	set["search"] = searchEntries(metadata)
+
+	if whole {
+		set["metadata"] = metadata
+		set["current"] = next
+	} else {
+		for _, version := range ended {
+			unset["metadata."+version.Key] = ""
+			unset["current."+version.Key] = ""
+		}
+		for key, version := range added {
+			delete(unset, "metadata."+key)
+			delete(unset, "current."+key)
+			set["metadata."+key] = version.Value
+			set["current."+key] = version
+		}
+	}
+	if len(ended) > 0 {
+		set["closed"] = ended
 	} else {
-		// Update existing entity's metadata
-		// TODO: Should we choose _id for placing our id or should we use id field separately and use that.
-		// Because then it is going to be reading or deleting or whatever by filtering using an attribute not the id of the object.
-		_, err = repo.UpdateEntity(ctx, existingEntity.Id, bson.M{"metadata": entity.GetMetadata()})
+		unset["closed"] = ""
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
 	}
+	return update, ended
+}
 
+// moveClosed copies versions that are no longer current to the history collection. A version keeps its ID, so
+// moving it again does nothing.
+func (repo *MongoRepository) moveClosed(ctx context.Context, versions []versionDocument) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, len(versions))
+	for i, version := range versions {
+		models[i] = mongo.NewReplaceOneModel().SetFilter(bson.M{"_id": version.ID}).SetReplacement(version).SetUpsert(true)
+	}
+	_, err := repo.historyCollection().BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 	return err
 }
 
-// Improved GetMetadata function that handles conversion internally
-func (repo *MongoRepository) GetMetadata(ctx context.Context, entityId string) (map[string]*anypb.Any, error) {
-	// Use the existing ReadEntity method for consistency
-	entity, err := repo.ReadEntity(ctx, entityId)
+// HandleMetadata applies a change to the metadata of an entity, empty changes are skipped.
+// The document is only written if its revision did not change since it was read, so concurrent changes of
+// different keys are all kept and the change is tried again when it loses the race. The versions a change ends
+// are kept in the document until they are moved to the history collection, which the next change retries when
+// it did not complete.
+func (repo *MongoRepository) HandleMetadata(ctx context.Context, entityId string, change *metadatahistory.Change) error {
+	if change.IsEmpty() {
+		return nil
+	}
+
+	for attempt := 1; attempt <= maxMetadataAttempts; attempt++ {
+		doc, err := repo.readDocument(ctx, entityId)
+		if err != nil {
+			return fmt.Errorf("error reading metadata of entity %s: %w", entityId, err)
+		}
+
+		if doc == nil {
+			versions, err := metadatahistory.Apply(nil, change)
+			if err != nil {
+				return err
+			}
+			newDoc := toDocument(versions)
+			newDoc["_id"] = entityId
+			_, err = repo.collection().InsertOne(ctx, newDoc)
+			if mongo.IsDuplicateKeyError(err) {
+				logging.FromContext(ctx).DebugContext(ctx, "Metadata created concurrently, retrying", "entity_id", entityId, "attempt", attempt)
+				continue
+			}
+			return err
+		}
+
+		// The change replaces the versions the previous change ended, which must be moved first
+		if err := repo.moveClosed(ctx, doc.Closed); err != nil {
+			return fmt.Errorf("error moving metadata history of entity %s: %w", entityId, err)
+		}
+
+		current := currentVersions(doc)
+		currentMessages := make([]*pb.MetadataVersion, len(current))
+		for i, version := range current {
+			currentMessages[i] = fromVersionDocument(version)
+		}
+		previous, err := repo.lastEndedVersions(ctx, entityId, current, change)
+		if err != nil {
+			return fmt.Errorf("error reading metadata history of entity %s: %w", entityId, err)
+		}
+		versions, err := metadatahistory.Apply(append(currentMessages, previous...), change)
+		if err != nil {
+			return err
+		}
+		// The ended versions are only passed for Apply to check the change against them, they are not changed
+		versions = append(versions[:len(current)], versions[len(current)+len(previous):]...)
+		revision := doc.Revision + 1
+		update, ended := metadataUpdate(doc, current, versions, revision)
+		filter := bson.M{"_id": entityId, "revision": doc.Revision}
+		if doc.Revision == 0 {
+			filter["revision"] = bson.M{"$exists": false}
+		}
+		result, err := repo.collection().UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 1 {
+			repo.finishChange(ctx, entityId, revision, ended)
+			return nil
+		}
+		logging.FromContext(ctx).DebugContext(ctx, "Metadata changed concurrently, retrying", "entity_id", entityId, "attempt", attempt)
+	}
+	return fmt.Errorf("error updating metadata of entity %s: it was changed concurrently %d times", entityId, maxMetadataAttempts)
+}
+
+// lastEndedVersions returns the last version of every key a change sets that has no current value, which the
+// change cannot start before
+func (repo *MongoRepository) lastEndedVersions(ctx context.Context, entityId string, current []versionDocument, change *metadatahistory.Change) ([]*pb.MetadataVersion, error) {
+	hasValue := make(map[string]bool, len(current))
+	for _, version := range current {
+		hasValue[version.Key] = true
+	}
+	keys := make([]string, 0, len(change.Set))
+	for key := range change.Set {
+		if !hasValue[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	previous := []*pb.MetadataVersion{}
+	for _, key := range keys {
+		var version versionDocument
+		err := repo.historyCollection().FindOne(ctx, bson.M{"entityId": entityId, "key": key},
+			options.FindOne().SetSort(bson.D{{Key: "revision", Value: -1}})).Decode(&version)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, fromVersionDocument(version))
+	}
+	return previous, nil
+}
+
+// finishChange moves the versions a change ended to the history collection and removes them from the document,
+// unless another change was written since. The change is already stored, so failures are only logged and the
+// versions stay in the document until the next change moves them.
+func (repo *MongoRepository) finishChange(ctx context.Context, entityId string, revision int64, ended []versionDocument) {
+	if len(ended) == 0 {
+		return
+	}
+	if err := repo.moveClosed(ctx, ended); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Error moving metadata history", "entity_id", entityId, "error", err)
+		return
+	}
+	_, err := repo.collection().UpdateOne(ctx, bson.M{"_id": entityId, "revision": revision}, bson.M{"$unset": bson.M{"closed": ""}})
 	if err != nil {
-		// Log error and return empty metadata map
-		log.Printf("Error retrieving metadata for entity %s: %v", entityId, err)
-		metadata := make(map[string]*anypb.Any)
-		return metadata, nil
+		logging.FromContext(ctx).WarnContext(ctx, "Error removing moved metadata history", "entity_id", entityId, "error", err)
+	}
+}
+
+// readVersions returns the versions of a metadata key of an entity, or of every key when key is empty, in the
+// order they were recorded
+func (repo *MongoRepository) readVersions(ctx context.Context, doc *entityDocument, key string) ([]*pb.MetadataVersion, error) {
+	filter := bson.M{"entityId": doc.ID}
+	if key != "" {
+		filter["key"] = key
+	}
+	cursor, err := repo.historyCollection().Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var stored []versionDocument
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
 	}
 
-	// Handle nil metadata (this shouldn't happen given our HandleMetadata implementation,
-	// but adding as a safeguard)
-	if entity.Metadata == nil {
+	// The versions the last change ended may not have been moved yet
+	moved := make(map[string]bool, len(stored))
+	for _, version := range stored {
+		moved[version.ID.Hex()] = true
+	}
+	for _, version := range doc.Closed {
+		if !moved[version.ID.Hex()] && (key == "" || version.Key == key) {
+			stored = append(stored, version)
+		}
+	}
+	for _, version := range currentVersions(doc) {
+		if key == "" || version.Key == key {
+			stored = append(stored, version)
+		}
+	}
+
+	sort.SliceStable(stored, func(i, j int) bool {
+		return stored[i].Revision < stored[j].Revision
+	})
+	versions := make([]*pb.MetadataVersion, len(stored))
+	for i, version := range stored {
+		versions[i] = fromVersionDocument(version)
+	}
+	return versions, nil
+}
+
+// GetMetadata returns the metadata of an entity now or at activeAt, or an empty map if it has none
+func (repo *MongoRepository) GetMetadata(ctx context.Context, entityId string, activeAt string) (map[string]*anypb.Any, error) {
+	doc, err := repo.readDocument(ctx, entityId)
+	if err != nil {
+		// Log error and return empty metadata map
+		logging.FromContext(ctx).WarnContext(ctx, "Error retrieving metadata", "entity_id", entityId, "error", err)
 		return make(map[string]*anypb.Any), nil
 	}
+	if doc == nil {
+		return make(map[string]*anypb.Any), nil
+	}
+	if activeAt == "" {
+		if doc.Metadata == nil {
+			return make(map[string]*anypb.Any), nil
+		}
+		return doc.Metadata, nil
+	}
+	versions, err := repo.readVersions(ctx, doc, "")
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata history of entity %s: %w", entityId, err)
+	}
+	return metadatahistory.Read(versions, activeAt)
+}
 
-	// Return the original protobuf Any metadata
-	return entity.Metadata, nil
+// GetMetadataHistory returns the versions of a metadata key of an entity, or of every key when key is empty
+func (repo *MongoRepository) GetMetadataHistory(ctx context.Context, entityId string, key string) ([]*pb.MetadataVersion, error) {
+	doc, err := repo.readDocument(ctx, entityId)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata of entity %s: %w", entityId, err)
+	}
+	if doc == nil {
+		return []*pb.MetadataVersion{}, nil
+	}
+	versions, err := repo.readVersions(ctx, doc, key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading metadata history of entity %s: %w", entityId, err)
+	}
+	return metadatahistory.Select(versions, key), nil
 }
@@ -2,12 +2,18 @@ package mongorepository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"lk/datafoundation/crud-api/commons"
 	"lk/datafoundation/crud-api/db/config"
-	"log"
+	"log/slog"
 
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/tlsconfig"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -28,14 +34,66 @@ type entityDocument struct {
 	Name          *pb.TimeBasedValue                `bson:"name,omitempty"`
 	Attributes    map[string]*pb.TimeBasedValueList `bson:"attributes,omitempty"`
 	Relationships map[string]*pb.Relationship       `bson:"relationships,omitempty"`
+	// Current holds the versions of the current metadata values by key and Closed the versions the last change
+	// ended, until they are moved to the history collection. Documents stored before the history was kept have
+	// neither versions nor a revision.
+	Current  map[string]versionDocument `bson:"current,omitempty"`
+	Closed   []versionDocument          `bson:"closed,omitempty"`
+	Revision int64                      `bson:"revision,omitempty"`
 }
 
-// Convert protobuf Entity to MongoDB document
-func toDocument(entity *pb.Entity) interface{} {
+// versionDocument is the stored form of a version of a metadata value. The versions of a key are ordered by the
+// revision of the entity document that recorded them.
+type versionDocument struct {
+	ID         primitive.ObjectID `bson:"_id"`
+	EntityID   string             `bson:"entityId,omitempty"`
+	Revision   int64              `bson:"revision"`
+	Key        string             `bson:"key"`
+	Value      *anypb.Any         `bson:"value"`
+	ValidFrom  string             `bson:"validFrom,omitempty"`
+	ValidTo    string             `bson:"validTo,omitempty"`
+	RecordedBy string             `bson:"recordedBy,omitempty"`
+	RecordedAt string             `bson:"recordedAt,omitempty"`
+}
+
+// Convert a new metadata version to its MongoDB document
+func toVersionDocument(version *pb.MetadataVersion, revision int64) versionDocument {
+	return versionDocument{
+		ID:         primitive.NewObjectID(),
+		Revision:   revision,
+		Key:        version.Key,
+		Value:      version.Value,
+		ValidFrom:  version.ValidFrom,
+		ValidTo:    version.ValidTo,
+		RecordedBy: version.RecordedBy,
+		RecordedAt: version.RecordedAt,
+	}
+}
+
+// Convert a MongoDB version document to a metadata version
+func fromVersionDocument(version versionDocument) *pb.MetadataVersion {
+	return &pb.MetadataVersion{
+		Key:        version.Key,
+		Value:      version.Value,
+		ValidFrom:  version.ValidFrom,
+		ValidTo:    version.ValidTo,
+		RecordedBy: version.RecordedBy,
+		RecordedAt: version.RecordedAt,
+	}
+}
+
+// Convert the metadata versions of a new entity, all of them current, to the fields of its MongoDB document
+func toDocument(versions []*pb.MetadataVersion) bson.M {
+	current := make(map[string]versionDocument, len(versions))
+	for _, version := range versions {
+		current[version.Key] = toVersionDocument(version, 1)
+	}
+	metadata := metadatahistory.Current(versions)
 	return bson.M{
-		"_id":      entity.Id,
-		"metadata": entity.Metadata,
-		// Map other entity fields as needed
+		"metadata": metadata,
+		"search":   searchEntries(metadata),
+		"current":  current,
+		"revision": int64(1),
 	}
 }
 
@@ -54,36 +112,71 @@ func fromDocument(data *entityDocument) *pb.Entity {
 }
 
 // NewMongoRepository initializes a MongoDB client
-// TODO: Handle errors better
-func NewMongoRepository(ctx context.Context, config *config.MongoConfig) *MongoRepository {
+func NewMongoRepository(ctx context.Context, config *config.MongoConfig) (*MongoRepository, error) {
 	clientOptions := options.Client().ApplyURI(config.URI)
+	if config.MaxPoolSize > 0 {
+		clientOptions.SetMaxPoolSize(config.MaxPoolSize)
+	}
+	if config.MinPoolSize > 0 {
+		clientOptions.SetMinPoolSize(config.MinPoolSize)
+	}
+	tlsConfig, err := tlsconfig.Client(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MongoDB TLS configuration: %w", err)
+	}
+	if tlsConfig != nil {
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
-		log.Fatal(err)
+		slog.ErrorContext(ctx, "Failed to create MongoDB client", "error", err)
+		return nil, fmt.Errorf("failed to create MongoDB client: %w", err)
 	}
 	return &MongoRepository{
 		client: client,
 		config: config,
-	}
+	}, nil
+}
+
+// Close disconnects the MongoDB client
+func (repo *MongoRepository) Close(ctx context.Context) error {
+	return repo.client.Disconnect(ctx)
+}
+
+// Ping verifies that the MongoDB server can still be reached
+func (repo *MongoRepository) Ping(ctx context.Context) error {
+	return repo.client.Ping(ctx, nil)
 }
 
 func (repo *MongoRepository) collection() *mongo.Collection {
 	return repo.client.Database(repo.config.DBName).Collection(repo.config.Collection)
 }
 
-// CreateEntity inserts a new entity in MongoDB
+// CreateEntity inserts a new entity in MongoDB, its metadata is valid from its creation
 // FIXME: https://github.com/LDFLK/nexoan/issues/118
-func (repo *MongoRepository) CreateEntity(ctx context.Context, entity *pb.Entity) (*mongo.InsertOneResult, error) {
+func (repo *MongoRepository) CreateEntity(ctx context.Context, entity *pb.Entity) error {
+	versions, err := metadatahistory.Apply(nil, &metadatahistory.Change{Set: entity.Metadata, ValidFrom: entity.Created})
+	if err != nil {
+		return err
+	}
 	// Use the entity.Id as MongoDB's _id field
-	doc := toDocument(entity)
-	result, err := repo.collection().InsertOne(ctx, doc)
-	return result, err
+	doc := toDocument(versions)
+	doc["_id"] = entity.Id
+	// The kind lets SearchMetadata filter on it before limiting the matches
+	if entity.Kind.GetMajor() != "" {
+		doc["kind"] = bson.M{"major": entity.Kind.GetMajor(), "minor": entity.Kind.GetMinor()}
+	}
+	_, err = repo.collection().InsertOne(ctx, doc)
+	return err
 }
 
-// ReadEntity fetches an entity by ID from MongoDB
+// ReadEntity fetches an entity by ID from MongoDB, the error wraps commons.ErrNotFound when it does not exist
 func (repo *MongoRepository) ReadEntity(ctx context.Context, id string) (*pb.Entity, error) {
 	var doc entityDocument
 	err := repo.collection().FindOne(ctx, bson.M{"_id": id}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("entity with Id %s: %w", id, commons.ErrNotFound)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -97,8 +190,17 @@ func (repo *MongoRepository) UpdateEntity(ctx context.Context, id string, update
 	return result, err
 }
 
-// DeleteEntity removes an entity from MongoDB
-func (repo *MongoRepository) DeleteEntity(ctx context.Context, id string) (*mongo.DeleteResult, error) {
+// DeleteEntity removes an entity, its metadata history and its attribute values from MongoDB and reports whether it existed
+func (repo *MongoRepository) DeleteEntity(ctx context.Context, id string) (bool, error) {
 	result, err := repo.collection().DeleteOne(ctx, bson.M{"_id": id})
-	return result, err
+	if err != nil {
+		return false, err
+	}
+	if _, err := repo.historyCollection().DeleteMany(ctx, bson.M{"entityId": id}); err != nil {
+		return false, fmt.Errorf("error deleting metadata history of entity %s: %w", id, err)
+	}
+	if _, err := repo.attributeValuesCollection().DeleteMany(ctx, bson.M{"entityId": id}); err != nil {
+		return false, fmt.Errorf("error deleting attribute values of entity %s: %w", id, err)
+	}
+	return result.DeletedCount > 0, nil
 }
@@ -2,16 +2,21 @@ package mongorepository
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
+	"lk/datafoundation/crud-api/commons"
 	"lk/datafoundation/crud-api/db/config"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/search"
 )
 
 var testRepo *MongoRepository
@@ -36,11 +41,19 @@ func TestMain(m *testing.M) {
 		URI:        os.Getenv("MONGO_URI"),
 		DBName:     os.Getenv("MONGO_DB_NAME"),
 		Collection: os.Getenv("MONGO_COLLECTION") + "_test",
+
+		RelationshipTypesCollection: "relationship_types_test",
+		KindSchemasCollection:       "kind_schemas_test",
+		MetadataHistoryCollection:   "metadata_history_test",
 	}
 
 	// Initialize MongoDB repository
 	testCtx = context.Background()
-	testRepo = NewMongoRepository(testCtx, testConfig)
+	var err error
+	testRepo, err = NewMongoRepository(testCtx, testConfig)
+	if err != nil {
+		log.Fatalf("Cannot create MongoDB client: %v", err)
+	}
 
 	// Clear test collection before tests
 	// testRepo.collection().Drop(testCtx)
@@ -50,6 +63,9 @@ func TestMain(m *testing.M) {
 		log.Fatalf("Cannot connect to MongoDB: %v", err)
 	}
 	log.Println("Successfully connected to MongoDB")
+	if err := testRepo.CreateIndexes(testCtx); err != nil {
+		log.Fatalf("Cannot create MongoDB indexes: %v", err)
+	}
 
 	// Run tests
 	log.Println("Running tests")
@@ -93,10 +109,9 @@ func TestCreateAndReadEntity(t *testing.T) {
 	}
 
 	// Create entity
-	result, err := testRepo.CreateEntity(testCtx, entity)
+	err = testRepo.CreateEntity(testCtx, entity)
 	assert.NoError(t, err)
-	assert.NotNil(t, result, "Insert result should not be nil")
-	log.Printf("Inserted document with ID: %v", result.InsertedID)
+	log.Printf("Inserted document with ID: %v", entityID)
 
 	// Read entity with error check
 	readEntity, err := testRepo.ReadEntity(testCtx, entityID)
@@ -143,7 +158,7 @@ func TestUpdateEntityMetadata(t *testing.T) {
 	}
 
 	// Create entity
-	_, err = testRepo.CreateEntity(testCtx, entity)
+	err = testRepo.CreateEntity(testCtx, entity)
 	assert.NoError(t, err)
 
 	// Update metadata
@@ -175,7 +190,7 @@ func TestUpdateEntityMetadata(t *testing.T) {
 // 1. Creates an entity with test metadata
 // 2. Confirms the entity exists in the database after creation
 // 3. Tests the DeleteEntity method by removing the entity
-// 4. Verifies that exactly one document was deleted (DeletedCount = 1)
+// 4. Verifies that the entity is reported as deleted
 // 5. Confirms the entity no longer exists by attempting to read it
 // 6. Validates that an error is returned when trying to read a deleted entity
 func TestDeleteEntity(t *testing.T) {
@@ -198,17 +213,48 @@ func TestDeleteEntity(t *testing.T) {
 	}
 
 	// Create entity
-	_, err = testRepo.CreateEntity(testCtx, entity)
+	err = testRepo.CreateEntity(testCtx, entity)
 	assert.NoError(t, err)
 
 	// Delete entity
-	result, err := testRepo.DeleteEntity(testCtx, entityID)
+	deleted, err := testRepo.DeleteEntity(testCtx, entityID)
 	assert.NoError(t, err)
-	assert.Equal(t, int64(1), result.DeletedCount)
+	assert.True(t, deleted)
 
 	// Verify entity is deleted
 	_, err = testRepo.ReadEntity(testCtx, entityID)
-	assert.Error(t, err) // Should return an error since entity doesn't exist
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+}
+
+// TestAttributeValues verifies that the values of an attribute are read ordered by start time, that a value
+// with the same start time is replaced and that the values are removed with the entity
+func TestAttributeValues(t *testing.T) {
+	entityID := "test-entity-attribute-values"
+	value := func(start string, number int64) *pb.TimeBasedValue {
+		packed, err := anypb.New(wrapperspb.Int64(number))
+		assert.NoError(t, err)
+		return &pb.TimeBasedValue{StartTime: start, Value: packed}
+	}
+	assert.NoError(t, testRepo.CreateEntity(testCtx, &pb.Entity{Id: entityID}))
+
+	assert.NoError(t, testRepo.SaveAttributeValue(testCtx, entityID, "headcount", value("2024-01-01T00:00:00Z", 45)))
+	assert.NoError(t, testRepo.SaveAttributeValue(testCtx, entityID, "headcount", value("2023-01-01T00:00:00Z", 40)))
+	assert.NoError(t, testRepo.SaveAttributeValue(testCtx, entityID, "headcount", value("2024-01-01T00:00:00Z", 42)))
+	values, err := testRepo.GetAttributeValues(testCtx, entityID, "headcount")
+	assert.NoError(t, err)
+	if assert.Len(t, values, 2) {
+		var number wrapperspb.Int64Value
+		assert.NoError(t, values[0].Value.UnmarshalTo(&number))
+		assert.Equal(t, int64(40), number.Value)
+		assert.NoError(t, values[1].Value.UnmarshalTo(&number))
+		assert.Equal(t, int64(42), number.Value)
+	}
+
+	deleted, err := testRepo.DeleteEntity(testCtx, entityID)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	_, err = testRepo.GetAttributeValues(testCtx, entityID, "headcount")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
 }
 
 // TestMetadataHandling verifies the handling of complex metadata with various data types:
@@ -247,9 +293,8 @@ func TestMetadataHandling(t *testing.T) {
 	}
 
 	// Create entity - this should use the entityID as the document ID
-	result, err := testRepo.CreateEntity(testCtx, entity)
+	err = testRepo.CreateEntity(testCtx, entity)
 	assert.NoError(t, err)
-	assert.NotNil(t, result, "Insert result should not be nil")
 
 	// Read entity to verify metadata was stored correctly
 	readEntity, err := testRepo.ReadEntity(testCtx, entityID)
@@ -274,3 +319,218 @@ func TestMetadataHandling(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int32(42), intWrapper.Value)
 }
+
+// TestRelationshipTypes verifies that relationship types can be saved, read back and deleted
+func TestRelationshipTypes(t *testing.T) {
+	relType := &pb.RelationshipType{
+		Name:        "TEST_HEAD_OF",
+		SourceKinds: []*pb.Kind{{Major: "Person"}},
+		TargetKinds: []*pb.Kind{{Major: "Organisation", Minor: "Ministry"}},
+		Cardinality: pb.Cardinality_ONE_TO_MANY,
+		Direction:   pb.RelationshipDirection_DIRECTED,
+		Description: "Head of an organisation",
+	}
+
+	err := testRepo.SaveRelationshipType(testCtx, relType)
+	assert.NoError(t, err)
+
+	// Saving again replaces the existing type
+	relType.Description = "Head of a ministry"
+	err = testRepo.SaveRelationshipType(testCtx, relType)
+	assert.NoError(t, err)
+
+	relTypes, err := testRepo.ReadRelationshipTypes(testCtx)
+	assert.NoError(t, err)
+
+	var found *pb.RelationshipType
+	for _, rt := range relTypes {
+		if rt.Name == "TEST_HEAD_OF" {
+			found = rt
+		}
+	}
+	assert.NotNil(t, found, "Expected saved relationship type to be read back")
+	assert.Equal(t, "Head of a ministry", found.Description)
+	assert.Equal(t, pb.Cardinality_ONE_TO_MANY, found.Cardinality)
+	assert.Equal(t, "Ministry", found.TargetKinds[0].Minor)
+	assert.Equal(t, "", found.SourceKinds[0].Minor)
+
+	_, err = testRepo.DeleteRelationshipType(testCtx, "TEST_HEAD_OF")
+	assert.NoError(t, err)
+}
+
+// TestKindSchemas verifies that kind schemas can be saved, read back and deleted
+func TestKindSchemas(t *testing.T) {
+	schema := &pb.KindSchema{
+		Major:  "TestOrganisation",
+		Minors: []string{"Ministry"},
+		Metadata: []*pb.MetadataFieldSchema{
+			{Key: "gazetteNumber", Type: "string", Required: true},
+		},
+		Attributes: []*pb.AttributeFieldSchema{
+			{Name: "employees", StorageType: "tabular"},
+		},
+		Closed: true,
+	}
+
+	err := testRepo.SaveKindSchema(testCtx, schema)
+	assert.NoError(t, err)
+
+	schemas, err := testRepo.ReadKindSchemas(testCtx)
+	assert.NoError(t, err)
+
+	var found *pb.KindSchema
+	for _, s := range schemas {
+		if s.Major == "TestOrganisation" {
+			found = s
+		}
+	}
+	assert.NotNil(t, found, "Expected saved kind schema to be read back")
+	assert.Equal(t, []string{"Ministry"}, found.Minors)
+	assert.True(t, found.Metadata[0].Required)
+	assert.Equal(t, "tabular", found.Attributes[0].StorageType)
+	assert.True(t, found.Closed)
+
+	_, err = testRepo.DeleteKindSchema(testCtx, "TestOrganisation")
+	assert.NoError(t, err)
+}
+
+// TestSearchMetadata verifies that metadata values are found by the text index whatever their case and accents
+func TestSearchMetadata(t *testing.T) {
+	assert.NoError(t, testRepo.CreateSearchIndexes(testCtx))
+	assert.NoError(t, testRepo.CreateSearchIndexes(testCtx), "Expected creating the indexes twice to succeed")
+
+	city, err := anypb.New(wrapperspb.String("Sri Jayawardenepura KÖTTE"))
+	assert.NoError(t, err)
+	err = testRepo.HandleMetadata(testCtx, "test-search-entity", &metadatahistory.Change{Set: map[string]*anypb.Any{"city": city}})
+	assert.NoError(t, err)
+
+	query, err := search.NewQuery("kotte jayawardenepura", nil)
+	assert.NoError(t, err)
+	matches, err := testRepo.SearchMetadata(testCtx, query)
+	assert.NoError(t, err)
+	assert.Contains(t, matches, search.Match{EntityID: "test-search-entity", Field: "metadata.city", Text: "Sri Jayawardenepura KÖTTE"})
+
+	// Documents of other kinds are filtered out by the query, documents without a kind are kept
+	err = testRepo.CreateEntity(testCtx, &pb.Entity{Id: "test-search-person", Kind: &pb.Kind{Major: "Person", Minor: "Citizen"},
+		Metadata: map[string]*anypb.Any{"city": city}})
+	assert.NoError(t, err)
+	query, err = search.NewQuery("kotte jayawardenepura", []*pb.Kind{{Major: "Organisation"}})
+	assert.NoError(t, err)
+	matches, err = testRepo.SearchMetadata(testCtx, query)
+	assert.NoError(t, err)
+	assert.Contains(t, matches, search.Match{EntityID: "test-search-entity", Field: "metadata.city", Text: "Sri Jayawardenepura KÖTTE"})
+	assert.NotContains(t, matches, search.Match{EntityID: "test-search-person", Field: "metadata.city", Text: "Sri Jayawardenepura KÖTTE"})
+
+	// Every term is required
+	query, err = search.NewQuery("kotte colombo", nil)
+	assert.NoError(t, err)
+	matches, err = testRepo.SearchMetadata(testCtx, query)
+	assert.NoError(t, err)
+	assert.NotContains(t, matches, search.Match{EntityID: "test-search-entity", Field: "metadata.city", Text: "Sri Jayawardenepura KÖTTE"})
+
+	for _, id := range []string{"test-search-entity", "test-search-person"} {
+		_, err = testRepo.DeleteEntity(testCtx, id)
+		assert.NoError(t, err)
+	}
+}
+
+// TestMetadataHistory verifies that partial updates keep the values they replace and that metadata can be
+// read as of a past time
+func TestMetadataHistory(t *testing.T) {
+	entityID := "test-history-entity"
+	first, err := anypb.New(wrapperspb.String("A"))
+	assert.NoError(t, err)
+	second, err := anypb.New(wrapperspb.String("B"))
+	assert.NoError(t, err)
+	gazette, err := anypb.New(wrapperspb.String("2019/1"))
+	assert.NoError(t, err)
+
+	err = testRepo.CreateEntity(testCtx, &pb.Entity{
+		Id:       entityID,
+		Created:  "2019-12-01T00:00:00Z",
+		Metadata: map[string]*anypb.Any{"minister": first, "gazette": gazette},
+	})
+	assert.NoError(t, err)
+	err = testRepo.HandleMetadata(testCtx, entityID, &metadatahistory.Change{
+		Set:        map[string]*anypb.Any{"minister": second},
+		ValidFrom:  "2022-07-22T00:00:00Z",
+		RecordedBy: "ingest-job",
+	})
+	assert.NoError(t, err)
+
+	// The keys that were not named keep their values
+	readEntity, err := testRepo.ReadEntity(testCtx, entityID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(readEntity.Metadata))
+
+	metadata, err := testRepo.GetMetadata(testCtx, entityID, "2020-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	minister := &wrapperspb.StringValue{}
+	assert.NoError(t, metadata["minister"].UnmarshalTo(minister))
+	assert.Equal(t, "A", minister.Value)
+
+	history, err := testRepo.GetMetadataHistory(testCtx, entityID, "minister")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "2022-07-22T00:00:00Z", history[0].ValidTo)
+	assert.Equal(t, "ingest-job", history[1].RecordedBy)
+
+	// The replaced value is moved out of the entity document
+	countHistory := func() int64 {
+		count, err := testRepo.historyCollection().CountDocuments(testCtx, bson.M{"entityId": entityID})
+		assert.NoError(t, err)
+		return count
+	}
+	assert.Equal(t, int64(1), countHistory())
+	doc, err := testRepo.readDocument(testCtx, entityID)
+	assert.NoError(t, err)
+	assert.Empty(t, doc.Closed)
+	assert.Len(t, doc.Current, 2)
+
+	err = testRepo.HandleMetadata(testCtx, entityID, &metadatahistory.Change{Remove: []string{"gazette"}})
+	assert.NoError(t, err)
+	readEntity, err = testRepo.ReadEntity(testCtx, entityID)
+	assert.NoError(t, err)
+	assert.NotContains(t, readEntity.Metadata, "gazette")
+	assert.Equal(t, int64(2), countHistory())
+
+	// A removed key cannot be set again before its last value ended
+	err = testRepo.HandleMetadata(testCtx, entityID, &metadatahistory.Change{
+		Set:       map[string]*anypb.Any{"gazette": gazette},
+		ValidFrom: "2020-01-01T00:00:00Z",
+	})
+	var invalid *metadatahistory.InvalidChangeError
+	assert.True(t, errors.As(err, &invalid))
+
+	_, err = testRepo.DeleteEntity(testCtx, entityID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), countHistory())
+}
+
+// TestMetadataHistoryOfStoredMetadata verifies that metadata stored before the history was kept becomes its
+// first version when it is changed
+func TestMetadataHistoryOfStoredMetadata(t *testing.T) {
+	entityID := "test-stored-history-entity"
+	first, err := anypb.New(wrapperspb.String("A"))
+	assert.NoError(t, err)
+	second, err := anypb.New(wrapperspb.String("B"))
+	assert.NoError(t, err)
+
+	_, err = testRepo.collection().InsertOne(testCtx, bson.M{"_id": entityID, "metadata": map[string]*anypb.Any{"minister": first}})
+	assert.NoError(t, err)
+	err = testRepo.HandleMetadata(testCtx, entityID, &metadatahistory.Change{
+		Set:       map[string]*anypb.Any{"minister": second},
+		ValidFrom: "2022-07-22T00:00:00Z",
+	})
+	assert.NoError(t, err)
+
+	history, err := testRepo.GetMetadataHistory(testCtx, entityID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(history))
+	assert.Equal(t, "", history[0].ValidFrom)
+	assert.Equal(t, "2022-07-22T00:00:00Z", history[0].ValidTo)
+	assert.Equal(t, "2022-07-22T00:00:00Z", history[1].ValidFrom)
+
+	_, err = testRepo.DeleteEntity(testCtx, entityID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,139 @@
+package mongorepository
+
+import (
+	"context"
+	"fmt"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultRelationshipTypesCollection is used when no relationship types collection is configured
+const defaultRelationshipTypesCollection = "relationship_types"
+
+// kindDocument is the MongoDB representation of a Kind
+type kindDocument struct {
+	Major string `bson:"major"`
+	Minor string `bson:"minor,omitempty"`
+}
+
+// relationshipTypeDocument is the MongoDB representation of a RelationshipType, keyed by the relationship name.
+// Cardinality and direction are stored by their enum names so the registry can be edited by hand.
+type relationshipTypeDocument struct {
+	Name        string         `bson:"_id"`
+	SourceKinds []kindDocument `bson:"sourceKinds,omitempty"`
+	TargetKinds []kindDocument `bson:"targetKinds,omitempty"`
+	Cardinality string         `bson:"cardinality,omitempty"`
+	Direction   string         `bson:"direction,omitempty"`
+	Description string         `bson:"description,omitempty"`
+}
+
+func (repo *MongoRepository) relationshipTypesCollection() *mongo.Collection {
+	collection := repo.config.RelationshipTypesCollection
+	if collection == "" {
+		collection = defaultRelationshipTypesCollection
+	}
+	return repo.client.Database(repo.config.DBName).Collection(collection)
+}
+
+func toKindDocuments(kinds []*pb.Kind) []kindDocument {
+	var docs []kindDocument
+	for _, kind := range kinds {
+		docs = append(docs, kindDocument{Major: kind.GetMajor(), Minor: kind.GetMinor()})
+	}
+	return docs
+}
+
+func fromKindDocuments(docs []kindDocument) []*pb.Kind {
+	var kinds []*pb.Kind
+	for _, doc := range docs {
+		kinds = append(kinds, &pb.Kind{Major: doc.Major, Minor: doc.Minor})
+	}
+	return kinds
+}
+
+// fromRelationshipTypeDocument converts a stored relationship type into its protobuf form
+func fromRelationshipTypeDocument(doc *relationshipTypeDocument) (*pb.RelationshipType, error) {
+	relType := &pb.RelationshipType{
+		Name:        doc.Name,
+		SourceKinds: fromKindDocuments(doc.SourceKinds),
+		TargetKinds: fromKindDocuments(doc.TargetKinds),
+		Description: doc.Description,
+	}
+	if doc.Cardinality != "" {
+		cardinality, ok := pb.Cardinality_value[doc.Cardinality]
+		if !ok {
+			return nil, fmt.Errorf("relationship type %s has unknown cardinality %s", doc.Name, doc.Cardinality)
+		}
+		relType.Cardinality = pb.Cardinality(cardinality)
+	}
+	if doc.Direction != "" {
+		direction, ok := pb.RelationshipDirection_value[doc.Direction]
+		if !ok {
+			return nil, fmt.Errorf("relationship type %s has unknown direction %s", doc.Name, doc.Direction)
+		}
+		relType.Direction = pb.RelationshipDirection(direction)
+	}
+	return relType, nil
+}
+
+// SaveRelationshipType creates or replaces a relationship type in the registry
+func (repo *MongoRepository) SaveRelationshipType(ctx context.Context, relType *pb.RelationshipType) error {
+	if relType == nil || relType.Name == "" {
+		return fmt.Errorf("relationship type name cannot be empty")
+	}
+	doc := relationshipTypeDocument{
+		Name:        relType.Name,
+		SourceKinds: toKindDocuments(relType.SourceKinds),
+		TargetKinds: toKindDocuments(relType.TargetKinds),
+		Cardinality: relType.Cardinality.String(),
+		Direction:   relType.Direction.String(),
+		Description: relType.Description,
+	}
+	_, err := repo.relationshipTypesCollection().ReplaceOne(ctx, bson.M{"_id": relType.Name}, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error saving relationship type", "relationship_type", relType.Name, "error", err)
+		return fmt.Errorf("error saving relationship type %s: %v", relType.Name, err)
+	}
+	return nil
+}
+
+// ReadRelationshipTypes fetches every relationship type in the registry
+func (repo *MongoRepository) ReadRelationshipTypes(ctx context.Context) ([]*pb.RelationshipType, error) {
+	cursor, err := repo.relationshipTypesCollection().Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading relationship types", "error", err)
+		return nil, fmt.Errorf("error reading relationship types: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var relTypes []*pb.RelationshipType
+	for cursor.Next(ctx) {
+		var doc relationshipTypeDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("error decoding relationship type: %v", err)
+		}
+		relType, err := fromRelationshipTypeDocument(&doc)
+		if err != nil {
+			return nil, err
+		}
+		relTypes = append(relTypes, relType)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over relationship types: %v", err)
+	}
+	return relTypes, nil
+}
+
+// DeleteRelationshipType removes a relationship type from the registry and reports whether it existed
+func (repo *MongoRepository) DeleteRelationshipType(ctx context.Context, name string) (bool, error) {
+	result, err := repo.relationshipTypesCollection().DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,155 @@
+package mongorepository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// searchIndexName is the name of the text index over the searchable text of metadata values
+const searchIndexName = "metadata_search"
+
+// searchEntry is the searchable text of a metadata value.
+// Metadata values are stored as serialized protobuf messages, so their text is kept next to them for the text index.
+type searchEntry struct {
+	Key  string `bson:"key"`
+	Text string `bson:"text"`
+}
+
+// searchEntries returns the searchable text of the metadata values that have any, ordered by key
+func searchEntries(metadata map[string]*anypb.Any) []searchEntry {
+	entries := []searchEntry{}
+	for key, value := range metadata {
+		if text := search.ValueText(value); text != "" {
+			entries = append(entries, searchEntry{Key: key, Text: text})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// CreateSearchIndexes creates the text index used by SearchMetadata and adds the searchable text
+// to the documents written before it existed. The index ignores case and accents, and it does not
+// stem words or drop stop words since metadata values are names and codes rather than prose.
+func (repo *MongoRepository) CreateSearchIndexes(ctx context.Context) error {
+	_, err := repo.collection().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "search.text", Value: "text"}},
+		Options: options.Index().
+			SetName(searchIndexName).
+			SetDefaultLanguage("none").
+			SetTextVersion(3),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating metadata search index: %v", err)
+	}
+
+	cursor, err := repo.collection().Find(ctx, bson.M{"search": bson.M{"$exists": false}})
+	if err != nil {
+		return fmt.Errorf("error finding metadata without search text: %v", err)
+	}
+	defer cursor.Close(ctx)
+	updated := 0
+	for cursor.Next(ctx) {
+		var doc entityDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return fmt.Errorf("error decoding metadata: %v", err)
+		}
+		if _, err := repo.UpdateEntity(ctx, doc.ID, bson.M{"search": searchEntries(doc.Metadata)}); err != nil {
+			return fmt.Errorf("error adding search text to entity %s: %v", doc.ID, err)
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("error iterating metadata: %v", err)
+	}
+	if updated > 0 {
+		logging.FromContext(ctx).InfoContext(ctx, "Added search text to existing metadata", "entities", updated)
+	}
+	return nil
+}
+
+// searchFilter returns the filter of the documents holding every term of the query, each given as a phrase so
+// that the text index requires all of them, and of the entities of the query kinds. Documents stored before
+// their kind was kept may be of any kind.
+func searchFilter(query search.Query) bson.M {
+	phrases := make([]string, len(query.Terms))
+	for i, term := range query.Terms {
+		phrases[i] = `"` + term + `"`
+	}
+	filter := bson.M{"$text": bson.M{"$search": strings.Join(phrases, " ")}}
+	if len(query.Kinds) == 0 {
+		return filter
+	}
+	kinds := bson.A{bson.M{"kind": bson.M{"$exists": false}}}
+	for _, kind := range query.Kinds {
+		condition := bson.M{"kind.major": kind.GetMajor()}
+		if kind.GetMinor() != "" {
+			condition["kind.minor"] = kind.GetMinor()
+		}
+		kinds = append(kinds, condition)
+	}
+	return bson.M{"$and": bson.A{filter, bson.M{"$or": kinds}}}
+}
+
+// SearchMetadata returns the metadata values that contain every term of the query.
+// The text index finds the documents of the query kinds containing every term, best first, and the values of
+// those documents are then checked for every term. Documents without a matching value do not count towards the
+// limit, so the documents are read a page at a time until enough values match or there are no more.
+func (repo *MongoRepository) SearchMetadata(ctx context.Context, query search.Query) ([]search.Match, error) {
+	var matches []search.Match
+	for skip := int64(0); len(matches) < search.MaxMatches; skip += search.MaxMatches {
+		page, documents, err := repo.searchMetadataPage(ctx, query, skip)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, page...)
+		if documents < search.MaxMatches {
+			break
+		}
+	}
+	if len(matches) > search.MaxMatches {
+		matches = matches[:search.MaxMatches]
+	}
+	return matches, nil
+}
+
+// searchMetadataPage returns the matching values of a page of the documents holding every term and the number
+// of documents in the page
+func (repo *MongoRepository) searchMetadataPage(ctx context.Context, query search.Query, skip int64) ([]search.Match, int, error) {
+	findOptions := options.Find().
+		SetProjection(bson.M{"metadata": 1, "score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}).
+		SetSkip(skip).
+		SetLimit(search.MaxMatches)
+	cursor, err := repo.collection().Find(ctx, searchFilter(query), findOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching metadata: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var matches []search.Match
+	documents := 0
+	for cursor.Next(ctx) {
+		var doc entityDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, 0, fmt.Errorf("error decoding metadata: %v", err)
+		}
+		documents++
+		matches = append(matches, query.MatchMetadata(doc.ID, doc.Metadata)...)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating metadata search results: %v", err)
+	}
+	return matches, documents, nil
+}
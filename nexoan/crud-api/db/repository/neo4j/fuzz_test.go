@@ -0,0 +1,104 @@
+package neo4jrepository
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+)
+
+// splitQuoted returns a query with every quoted name replaced by an empty pair of backticks, and the names in order
+func splitQuoted(t *testing.T, query string) (string, []string) {
+	t.Helper()
+	var skeleton strings.Builder
+	var names []string
+	for i := 0; i < len(query); i++ {
+		if query[i] != '`' {
+			skeleton.WriteByte(query[i])
+			continue
+		}
+		var name strings.Builder
+		for i++; ; i++ {
+			if i >= len(query) {
+				t.Fatalf("query %q has a name that is never closed", query)
+			}
+			if query[i] == '`' {
+				if i+1 < len(query) && query[i+1] == '`' {
+					name.WriteByte('`')
+					i++
+					continue
+				}
+				break
+			}
+			name.WriteByte(query[i])
+		}
+		skeleton.WriteString("``")
+		names = append(names, name.String())
+	}
+	return skeleton.String(), names
+}
+
+// FuzzQueries builds the queries of entities and relationships under fuzzed kinds and relationship names and
+// checks that every name is either rejected or quoted as a single name that reads back unchanged, so no name
+// can change what a query does
+func FuzzQueries(f *testing.F) {
+	f.Add("Person", "WORKS_AT", "Engineer")
+	f.Add("Person`) DETACH DELETE n //", "KNOWS]->() DELETE r //", "' OR 1=1 //")
+	f.Add("Searchable", "a``b", "`")
+	f.Fuzz(func(t *testing.T, major, relationshipName, minor string) {
+		check := func(query, expected string, names ...string) {
+			t.Helper()
+			skeleton, quoted := splitQuoted(t, query)
+			expectedSkeleton, _ := splitQuoted(t, expected)
+			if skeleton != expectedSkeleton {
+				t.Fatalf("query %q does not have the shape of %q", query, expected)
+			}
+			if !slices.Equal(quoted, names) {
+				t.Fatalf("query %q quotes %q instead of %q", query, quoted, names)
+			}
+		}
+
+		// Labels read back from the database are quoted without being validated
+		check(idConstraintStatement(major), idConstraintStatement("Person"), idConstraintName(major), major)
+
+		label, err := quoteKind(major)
+		if err != nil {
+			if cypher.ValidateIdentifier(major) == nil && major != searchableLabel {
+				t.Fatalf("kind %q was rejected: %v", major, err)
+			}
+		} else {
+			check(entityExistsQuery(label), entityExistsQuery("`Person`"), major)
+			for _, terminated := range []bool{false, true} {
+				check(createEntityQuery(label, terminated), createEntityQuery("`Person`", terminated), major)
+			}
+
+			// Everything but the kind is passed as a parameter
+			filters := map[string]interface{}{"name": minor, "created": minor, "terminated": minor}
+			query, params, err := filterEntitiesQuery(&pb.Kind{Major: major, Minor: minor}, filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, _, err := filterEntitiesQuery(&pb.Kind{Major: "Person", Minor: minor}, filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			check(query, expected, major)
+			for _, value := range params {
+				if value != minor {
+					t.Fatalf("filter %q was passed as %q", minor, value)
+				}
+			}
+		}
+
+		relType, err := cypher.QuoteIdentifier(relationshipName)
+		if err != nil {
+			return
+		}
+		for _, terminated := range []bool{false, true} {
+			check(createRelationshipQuery(relType, terminated), createRelationshipQuery("`WORKS_AT`", terminated), relationshipName)
+		}
+		check(relatedEntitiesQuery(relType), relatedEntitiesQuery("`WORKS_AT`"), relationshipName)
+	})
+}
@@ -3,9 +3,11 @@ package neo4jrepository
 import (
 	"context"
 	"fmt"
-	"log"
 
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api" // Replace with your actual protobuf package
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
 
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -62,8 +64,8 @@ func (repo *Neo4jRepository) GetGraphEntity(ctx context.Context, entityId string
 			terminated = termValue.(string)
 		}
 	} else {
-		log.Printf("[neo4j_handler.GetGraphEntity] Error reading entity %s: %v", entityId, err)
-		return nil, nil, "", "", fmt.Errorf("[neo4j_handler.GetGraphEntity] error reading entity: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading entity", "entity_id", entityId, "error", err)
+		return nil, nil, "", "", fmt.Errorf("[neo4j_handler.GetGraphEntity] error reading entity: %w", err)
 	}
 
 	return kind, name, created, terminated, err
@@ -71,12 +73,12 @@ func (repo *Neo4jRepository) GetGraphEntity(ctx context.Context, entityId string
 
 // GetGraphRelationships retrieves relationships for an entity from Neo4j
 func (repo *Neo4jRepository) GetGraphRelationships(ctx context.Context, entityId string) (map[string]*pb.Relationship, error) {
-	relationships := make(map[string]*pb.Relationship)
+	relationshipMap := make(map[string]*pb.Relationship)
 	// Retrieve relationships from Neo4j
 	relData, err := repo.ReadRelationships(ctx, entityId)
 	if err != nil {
-		log.Printf("[neo4j_handler.GetGraphRelationships] Error reading relationships for entity %s: %v", entityId, err)
-		return relationships, fmt.Errorf("[neo4j_handler.GetGraphRelationships] error reading relationships: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading relationships", "entity_id", entityId, "error", err)
+		return relationshipMap, fmt.Errorf("[neo4j_handler.GetGraphRelationships] error reading relationships: %v", err)
 	}
 
 	// Process each relationship
@@ -107,15 +109,67 @@ func (repo *Neo4jRepository) GetGraphRelationships(ctx context.Context, entityId
 			relationship.EndTime = terminated
 		}
 
+		// Add relationship properties if available
+		if props, ok := rel["properties"].(map[string]interface{}); ok {
+			relationship.Properties = relationships.ConvertStoredProperties(props)
+		}
+
 		// Store in map with unique key
-		relationships[relID] = relationship
+		relationshipMap[relID] = relationship
+	}
+
+	return relationshipMap, nil
+}
+
+// entityRelationshipFromGraph converts a relationship map returned by ReadRelationship into an EntityRelationship
+func entityRelationshipFromGraph(rel map[string]interface{}) *pb.EntityRelationship {
+	relationship := &pb.Relationship{
+		Id:              fmt.Sprintf("%v", rel["relationshipID"]),
+		Name:            fmt.Sprintf("%v", rel["type"]),
+		RelatedEntityId: fmt.Sprintf("%v", rel["endEntityID"]),
+		StartTime:       fmt.Sprintf("%v", rel["Created"]),
+		Direction:       "OUTGOING",
+	}
+	if terminated, ok := rel["Terminated"].(string); ok && terminated != "" {
+		relationship.EndTime = terminated
+	}
+	if props, ok := rel["properties"].(map[string]interface{}); ok {
+		relationship.Properties = relationships.ConvertStoredProperties(props)
 	}
+	return &pb.EntityRelationship{
+		EntityId:     fmt.Sprintf("%v", rel["startEntityID"]),
+		Relationship: relationship,
+	}
+}
 
+// GetGraphRelationship retrieves a single relationship and its source entity from Neo4j
+func (repo *Neo4jRepository) GetGraphRelationship(ctx context.Context, relationshipId string) (*pb.EntityRelationship, error) {
+	rel, err := repo.ReadRelationship(ctx, relationshipId)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading relationship", "relationship_id", relationshipId, "error", err)
+		return nil, err
+	}
+	return entityRelationshipFromGraph(rel), nil
+}
+
+// GetRelationshipsByEndpoints retrieves the relationships starting at sourceEntityId and/or ending at targetEntityId
+func (repo *Neo4jRepository) GetRelationshipsByEndpoints(ctx context.Context, sourceEntityId string, targetEntityId string, name string, activeAt string) ([]*pb.EntityRelationship, error) {
+	relData, err := repo.ReadRelationshipsByEndpoints(ctx, sourceEntityId, targetEntityId, name, activeAt)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error reading relationships", "error", err)
+		return nil, fmt.Errorf("[neo4j_handler.GetRelationshipsByEndpoints] error reading relationships: %v", err)
+	}
+
+	relationships := make([]*pb.EntityRelationship, 0, len(relData))
+	for _, rel := range relData {
+		relationships = append(relationships, entityRelationshipFromGraph(rel))
+	}
 	return relationships, nil
 }
 
 // GetRelationshipsByName retrieves relationships for an entity by various filters
-func (repo *Neo4jRepository) GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, activeAt string) (map[string]*pb.Relationship, error) {
+// Relationship properties act as an additional filter, every given property must match the stored value.
+func (repo *Neo4jRepository) GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, properties map[string]*anypb.Any, activeAt string) (map[string]*pb.Relationship, error) {
 	// Validate input parameters
 	if entityId == "" {
 		return nil, fmt.Errorf("entityId cannot be empty")
@@ -141,16 +195,24 @@ func (repo *Neo4jRepository) GetFilteredRelationships(ctx context.Context, entit
 	if direction != "" {
 		filters["direction"] = direction
 	}
+	if len(properties) > 0 {
+		propertyFilters, err := relationships.ConvertProperties(properties)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Invalid relationship property filters", "entity_id", entityId, "error", err)
+			return nil, fmt.Errorf("invalid relationship property filters: %w", err)
+		}
+		filters["properties"] = propertyFilters
+	}
 
 	relationshipData, err := repo.ReadFilteredRelationships(ctx, entityId, filters, activeAt)
 
 	if err != nil {
-		log.Printf("[GetEntityIdsByRelationship] Error fetching related relationships for entity %s with filters %v: %v", entityId, filters, err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error fetching filtered relationships", "entity_id", entityId, logging.Payload("filters", filters), "error", err)
 		return nil, err
 	}
 
 	// Convert the list of relationships into a map[string]*pb.Relationship
-	relationships := make(map[string]*pb.Relationship)
+	relationshipMap := make(map[string]*pb.Relationship)
 	for _, rel := range relationshipData {
 		relID, relIDOk := rel["id"].(string)
 		relatedEntityID, relatedEntityIdOk := rel["relatedEntityId"].(string)
@@ -161,12 +223,12 @@ func (repo *Neo4jRepository) GetFilteredRelationships(ctx context.Context, entit
 
 		// Ensure required fields are present
 		if !relIDOk || !relatedEntityIdOk || !startTimeOk || !nameOk || !directionOk {
-			log.Printf("[GetEntityIdsByRelationship] Missing required fields in relationship: %v", rel)
+			logging.FromContext(ctx).WarnContext(ctx, "Missing required fields in relationship", logging.Payload("relationship", rel))
 			return nil, fmt.Errorf("relationship missing required fields: %v", rel)
 		}
 
 		// Create a pb.Relationship object
-		relationships[relID] = &pb.Relationship{
+		relationshipMap[relID] = &pb.Relationship{
 			Id:              relID,
 			RelatedEntityId: relatedEntityID,
 			StartTime:       startTime,
@@ -174,28 +236,33 @@ func (repo *Neo4jRepository) GetFilteredRelationships(ctx context.Context, entit
 			Name:            name,
 			Direction:       direction,
 		}
+
+		// Optional relationship properties
+		if props, ok := rel["properties"].(map[string]interface{}); ok {
+			relationshipMap[relID].Properties = relationships.ConvertStoredProperties(props)
+		}
 	}
 
-	return relationships, nil
+	return relationshipMap, nil
 }
 
 // validateGraphEntityCreation checks if an entity has all required fields for Neo4j storage
-func validateGraphEntityCreation(entity *pb.Entity) bool {
+func validateGraphEntityCreation(ctx context.Context, entity *pb.Entity) bool {
 	// Check if Kind is present and has a Major value
 	if entity.Kind == nil || entity.Kind.GetMajor() == "" || entity.Kind.GetMinor() == "" {
-		log.Printf("[neo4j_handler.validateGraphEntityCreation] Skipping Neo4j entity creation for %s: Missing or empty Kind.Major", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Skipping entity creation, Kind.Major or Kind.Minor is missing", "entity_id", entity.Id)
 		return false
 	}
 
 	// Check if Name is present and has a Value
 	if entity.Name == nil || entity.Name.GetValue() == nil {
-		log.Printf("[neo4j_handler.validateGraphEntityCreation] Skipping Neo4j entity creation for %s: Missing or empty Name.Value", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Skipping entity creation, Name.Value is missing", "entity_id", entity.Id)
 		return false
 	}
 
 	// Check if Created date is present
 	if entity.Created == "" {
-		log.Printf("[neo4j_handler.validateGraphEntityCreation] Skipping Neo4j entity creation for %s: Missing Created date", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Skipping entity creation, Created is missing", "entity_id", entity.Id)
 		return false
 	}
 
@@ -205,12 +272,12 @@ func validateGraphEntityCreation(entity *pb.Entity) bool {
 // HandleGraphEntityCreation creates a new entity in Neo4j
 func (repo *Neo4jRepository) HandleGraphEntityCreation(ctx context.Context, entity *pb.Entity) (bool, error) {
 	// Validate required fields for Neo4j entity creation
-	if !validateGraphEntityCreation(entity) {
-		log.Printf("[neo4j_handler.HandleGraphEntityCreation] Neo4j entity creation failed for entity: %s", entity.Id)
+	if !validateGraphEntityCreation(ctx, entity) {
+		logging.FromContext(ctx).WarnContext(ctx, "Entity is missing required fields", "entity_id", entity.Id)
 		return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityCreation] missing required fields for Neo4j entity creation")
 	}
 
-	log.Printf("[neo4j_handler.HandleGraphEntityCreation] Creating new entity in Neo4j: %s", entity.Id)
+	logging.FromContext(ctx).DebugContext(ctx, "Creating new entity in Neo4j", "entity_id", entity.Id)
 
 	// Prepare data for Neo4j with safety checks
 	entityMap := map[string]interface{}{
@@ -221,6 +288,9 @@ func (repo *Neo4jRepository) HandleGraphEntityCreation(ctx context.Context, enti
 	if entity.Kind == nil || entity.Kind.GetMajor() == "" || entity.Kind.GetMinor() == "" {
 		return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityCreation] missing or invalid Kind.Major or Kind.Minor for entity %s", entity.Id)
 	}
+	if err := cypher.ValidateIdentifier(entity.Kind.GetMajor()); err != nil {
+		return false, err
+	}
 
 	kind := &pb.Kind{
 		Major: entity.Kind.GetMajor(),
@@ -240,17 +310,15 @@ func (repo *Neo4jRepository) HandleGraphEntityCreation(ctx context.Context, enti
 					// The first byte is the length, followed by the actual string
 					if len(rawValue) > 1 {
 						entityMap["Name"] = string(rawValue[1:])
-						log.Printf("Using raw value from Any: %s\n", string(rawValue[1:]))
 					}
 				}
 			} else {
-				fmt.Printf("Error unpacking Name value for entity %s: %v\n", entity.Id, err)
+				logging.FromContext(ctx).ErrorContext(ctx, "Error unpacking Name value", "entity_id", entity.Id, "error", err)
 				return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityCreation] error unpacking Name value: %v", err)
 			}
 		} else {
 			// Successfully unpacked to StringValue
 			entityMap["Name"] = stringValue.Value
-			log.Printf("Using unpacked StringValue: %s\n", stringValue.Value)
 		}
 	}
 
@@ -266,10 +334,10 @@ func (repo *Neo4jRepository) HandleGraphEntityCreation(ctx context.Context, enti
 	// Create the entity
 	result, err := repo.CreateGraphEntity(ctx, kind, entityMap)
 	if err != nil {
-		log.Printf("[neo4j_handler.HandleGraphEntityCreation] Error creating entity in Neo4j: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error creating entity in Neo4j", "error", err)
 		return false, err
 	} else {
-		log.Printf("[neo4j_handler.HandleGraphEntityCreation] Successfully created entity in Neo4j: %s", entity.Id)
+		logging.FromContext(ctx).DebugContext(ctx, "Created entity in Neo4j", "entity_id", entity.Id)
 		return result != nil, nil // Success if we got a non-nil result
 	}
 }
@@ -278,17 +346,17 @@ func (repo *Neo4jRepository) HandleGraphEntityCreation(ctx context.Context, enti
 func (repo *Neo4jRepository) HandleGraphEntityUpdate(ctx context.Context, entity *pb.Entity) (bool, error) {
 	// Validate required fields for Neo4j entity update
 	if entity.Id == "" {
-		log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Entity ID is required for Neo4j entity update")
+		logging.FromContext(ctx).WarnContext(ctx, "Entity ID is required for Neo4j entity update")
 		return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityUpdate] entity ID is required")
 	}
 
 	// Check if user is trying to update Kind (not allowed)
 	if entity.Kind != nil && (entity.Kind.Major != "" || entity.Kind.Minor != "") {
-		log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Cannot update Kind for entity %s", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Cannot update Kind for entity", "entity_id", entity.Id)
 		return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityUpdate] Kind cannot be updated")
 	}
 
-	log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Updating existing entity in Neo4j: %s", entity.Id)
+	logging.FromContext(ctx).DebugContext(ctx, "Updating existing entity in Neo4j", "entity_id", entity.Id)
 
 	// Prepare data for Neo4j with safety checks
 	entityMap := map[string]interface{}{
@@ -301,7 +369,7 @@ func (repo *Neo4jRepository) HandleGraphEntityUpdate(ctx context.Context, entity
 		var stringValue wrapperspb.StringValue
 		err := entity.Name.GetValue().UnmarshalTo(&stringValue)
 		if err != nil {
-			log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Error unpacking Name value for entity %s: %v", entity.Id, err)
+			logging.FromContext(ctx).ErrorContext(ctx, "Error unpacking Name value", "entity_id", entity.Id, "error", err)
 			return false, fmt.Errorf("[neo4j_handler.HandleGraphEntityUpdate] error unpacking Name value: %v", err)
 		}
 		// Get the actual string value from the StringValue and check it's not empty
@@ -321,13 +389,12 @@ func (repo *Neo4jRepository) HandleGraphEntityUpdate(ctx context.Context, entity
 
 	// Update the entity
 	result, err := repo.UpdateGraphEntity(ctx, entity.Id, entityMap)
-	log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Entity map for update: %+v", entityMap)
+	logging.FromContext(ctx).DebugContext(ctx, "Updating graph entity", logging.Payload("entity", entityMap))
 	if err != nil {
-		log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Error updating entity in Neo4j: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error updating entity in Neo4j", "error", err)
 		return false, err
 	} else {
-		log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Successfully updated entity in Neo4j: %s", entity.Id)
-		log.Printf("[neo4j_handler.HandleGraphEntityUpdate] Update result: %+v", result)
+		logging.FromContext(ctx).DebugContext(ctx, "Updated entity in Neo4j", "entity_id", entity.Id)
 		return result != nil, nil // Success if we got a non-nil result
 	}
 }
@@ -335,58 +402,67 @@ func (repo *Neo4jRepository) HandleGraphEntityUpdate(ctx context.Context, entity
 // HandleGraphRelationshipsCreate handles creating new relationships
 func (repo *Neo4jRepository) HandleGraphRelationshipsCreate(ctx context.Context, entity *pb.Entity) error {
 	if len(entity.Relationships) == 0 {
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] No relationships to process for entity: %s", entity.Id)
+		logging.FromContext(ctx).DebugContext(ctx, "No relationships to process", "entity_id", entity.Id)
 		return nil
 	}
 
-	log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Processing %d relationships for entity: %s", len(entity.Relationships), entity.Id)
+	logging.FromContext(ctx).DebugContext(ctx, "Processing relationships", "entity_id", entity.Id, "relationships", len(entity.Relationships))
 
 	// First verify the parent entity exists
 	parentEntity, err := repo.ReadGraphEntity(ctx, entity.Id)
 	if err != nil || parentEntity == nil {
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Parent entity %s does not exist in Neo4j", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Parent entity does not exist in Neo4j", "entity_id", entity.Id)
 		return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsCreate] parent entity %s does not exist", entity.Id)
 	}
 
 	// Process all child entities
 	for _, relationship := range entity.Relationships {
 		if relationship == nil || relationship.Id == "" {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Relationship missing ID field")
+			logging.FromContext(ctx).WarnContext(ctx, "Relationship missing ID field")
 			return fmt.Errorf("relationship missing ID field")
 		}
 
 		// Validate required fields for creation
 		if relationship.RelatedEntityId == "" {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing RelatedEntityId for relationship creation")
+			logging.FromContext(ctx).WarnContext(ctx, "Missing RelatedEntityId for relationship creation")
 			return fmt.Errorf("missing RelatedEntityId for relationship %s. Required for creation", relationship.Id)
 		}
 		if relationship.Name == "" {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing Name for relationship creation")
+			logging.FromContext(ctx).WarnContext(ctx, "Missing Name for relationship creation")
 			return fmt.Errorf("missing Name for relationship %s. Required for creation", relationship.Id)
 		}
+		if err := cypher.ValidateIdentifier(relationship.Name); err != nil {
+			return err
+		}
 		if relationship.StartTime == "" {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing StartTime for relationship creation")
+			logging.FromContext(ctx).WarnContext(ctx, "Missing StartTime for relationship creation")
 			return fmt.Errorf("missing StartTime for relationship %s. Required for creation", relationship.Id)
 		}
 
 		// Check if the child entity exists
 		childEntityMap, err := repo.ReadGraphEntity(ctx, relationship.RelatedEntityId)
 		if err != nil || childEntityMap == nil {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Child entity %s does not exist in Neo4j. Make sure to create it first.",
-				relationship.RelatedEntityId)
+			logging.FromContext(ctx).WarnContext(ctx, "Child entity does not exist in Neo4j, it must be created first",
+				"related_entity_id", relationship.RelatedEntityId)
 			return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsCreate] child entity %s does not exist", relationship.RelatedEntityId)
 		}
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Child entity %s exists in Neo4j", relationship.RelatedEntityId)
+		logging.FromContext(ctx).DebugContext(ctx, "Child entity exists in Neo4j", "related_entity_id", relationship.RelatedEntityId)
+
+		// Check the relationship against the declared relationship types
+		if err := repo.relationshipTypes.Validate(ctx, relationship, kindFromGraphEntity(parentEntity), kindFromGraphEntity(childEntityMap)); err != nil {
+			logging.FromContext(ctx).DebugContext(ctx, "Relationship rejected", "relationship_id", relationship.Id, "error", err)
+			return err
+		}
 
 		// Create the relationship
 		_, err = repo.CreateRelationship(ctx, entity.Id, relationship)
 		if err != nil {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Error creating relationship from %s to %s: %v",
-				entity.Id, relationship.RelatedEntityId, err)
-			return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsCreate] error creating relationship: %v", err)
+			logging.FromContext(ctx).ErrorContext(ctx, "Error creating relationship", "entity_id", entity.Id,
+				"related_entity_id", relationship.RelatedEntityId, "error", err)
+			return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsCreate] error creating relationship: %w", err)
 		}
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsCreate] Successfully created relationship from %s to %s",
-			entity.Id, relationship.RelatedEntityId)
+		logging.FromContext(ctx).DebugContext(ctx, "Created relationship", "entity_id", entity.Id,
+			"related_entity_id", relationship.RelatedEntityId)
 	}
 
 	return nil
@@ -394,25 +470,25 @@ func (repo *Neo4jRepository) HandleGraphRelationshipsCreate(ctx context.Context,
 
 // HandleGraphRelationshipsUpdate handles updating existing relationships
 func (repo *Neo4jRepository) HandleGraphRelationshipsUpdate(ctx context.Context, entity *pb.Entity) error {
-	log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Received entity: %+v", entity)
+	logging.FromContext(ctx).DebugContext(ctx, "Received entity", logging.Payload("entity", entity))
 
 	if len(entity.Relationships) == 0 {
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] No relationships to process for entity: %s", entity.Id)
+		logging.FromContext(ctx).DebugContext(ctx, "No relationships to process", "entity_id", entity.Id)
 		return nil
 	}
 
-	log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Processing %d relationships for entity: %s", len(entity.Relationships), entity.Id)
+	logging.FromContext(ctx).DebugContext(ctx, "Processing relationships", "entity_id", entity.Id, "relationships", len(entity.Relationships))
 
 	// First verify the parent entity exists
 	parentEntity, err := repo.ReadGraphEntity(ctx, entity.Id)
 	if err != nil || parentEntity == nil {
-		log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Parent entity %s does not exist in Neo4j", entity.Id)
+		logging.FromContext(ctx).WarnContext(ctx, "Parent entity does not exist in Neo4j", "entity_id", entity.Id)
 		return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsUpdate] parent entity %s does not exist", entity.Id)
 	}
 
 	for _, relationship := range entity.Relationships {
 		if relationship == nil || relationship.Id == "" {
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Relationship missing ID field")
+			logging.FromContext(ctx).WarnContext(ctx, "Relationship missing ID field")
 			return fmt.Errorf("relationship missing ID field")
 		}
 
@@ -422,9 +498,9 @@ func (repo *Neo4jRepository) HandleGraphRelationshipsUpdate(ctx context.Context,
 
 		if relationshipExists {
 			// RELATIONSHIP EXISTS - UPDATE IT
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Relationship %s exists, updating...", relationship.Id)
+			logging.FromContext(ctx).DebugContext(ctx, "Relationship exists, updating it", "relationship_id", relationship.Id)
 
-			// Validate: only StartTime and EndTime are allowed for updates
+			// Validate: only StartTime, EndTime and Properties are allowed for updates
 			if relationship.Name != "" || relationship.RelatedEntityId != "" || relationship.Direction != "" {
 				invalidFields := []string{}
 				if relationship.Name != "" {
@@ -436,8 +512,8 @@ func (repo *Neo4jRepository) HandleGraphRelationshipsUpdate(ctx context.Context,
 				if relationship.Direction != "" {
 					invalidFields = append(invalidFields, "Direction")
 				}
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Cannot update immutable fields: %v", invalidFields)
-				return fmt.Errorf("cannot update immutable fields: %v. Only StartTime and EndTime are allowed", invalidFields)
+				logging.FromContext(ctx).WarnContext(ctx, "Cannot update immutable fields", "invalid_fields", invalidFields)
+				return fmt.Errorf("cannot update immutable fields: %v. Only StartTime, EndTime and Properties are allowed", invalidFields)
 			}
 
 			// Build update data with valid fields only
@@ -448,59 +524,76 @@ func (repo *Neo4jRepository) HandleGraphRelationshipsUpdate(ctx context.Context,
 			if relationship.EndTime != "" {
 				relationshipData["Terminated"] = relationship.EndTime
 			}
+			if len(relationship.Properties) > 0 {
+				properties, err := relationships.ConvertProperties(relationship.Properties)
+				if err != nil {
+					logging.FromContext(ctx).WarnContext(ctx, "Invalid relationship properties", "error", err)
+					return fmt.Errorf("invalid properties for relationship %s: %w", relationship.Id, err)
+				}
+				relationshipData["Properties"] = properties
+			}
 
 			// Check if we have any valid fields to update
 			if len(relationshipData) == 0 {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] No valid fields provided for update")
-				return fmt.Errorf("no valid fields provided for relationship update. Only StartTime and EndTime are allowed")
+				logging.FromContext(ctx).WarnContext(ctx, "No valid fields provided for update")
+				return fmt.Errorf("no valid fields provided for relationship update. Only StartTime, EndTime and Properties are allowed")
 			}
 
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Updating relationship with data: %+v", relationshipData)
+			logging.FromContext(ctx).DebugContext(ctx, "Updating relationship", logging.Payload("relationship", relationshipData))
 
 			// Update the relationship
 			_, err = repo.UpdateRelationship(ctx, relationship.Id, relationshipData)
 			if err != nil {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Failed to update relationship: %v", err)
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to update relationship", "error", err)
 				return err
 			}
 
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Successfully updated relationship %s", relationship.Id)
+			logging.FromContext(ctx).DebugContext(ctx, "Updated relationship", "relationship_id", relationship.Id)
 			continue
 
 		} else {
 			// RELATIONSHIP DOESN'T EXIST - CREATE IT
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Relationship %s doesn't exist, creating...", relationship.Id)
+			logging.FromContext(ctx).DebugContext(ctx, "Relationship does not exist, creating it", "relationship_id", relationship.Id)
 
 			// Validate required fields for creation
 			if relationship.RelatedEntityId == "" {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing RelatedEntityId for relationship creation")
+				logging.FromContext(ctx).WarnContext(ctx, "Missing RelatedEntityId for relationship creation")
 				return fmt.Errorf("missing RelatedEntityId for relationship %s. Required for creation", relationship.Id)
 			}
 			if relationship.Name == "" {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing Name for relationship creation")
+				logging.FromContext(ctx).WarnContext(ctx, "Missing Name for relationship creation")
 				return fmt.Errorf("missing Name for relationship %s. Required for creation", relationship.Id)
 			}
+			if err := cypher.ValidateIdentifier(relationship.Name); err != nil {
+				return err
+			}
 			if relationship.StartTime == "" {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Missing StartTime for relationship creation")
+				logging.FromContext(ctx).WarnContext(ctx, "Missing StartTime for relationship creation")
 				return fmt.Errorf("missing StartTime for relationship %s. Required for creation", relationship.Id)
 			}
 
 			// Check if the child entity exists
 			childEntityMap, err := repo.ReadGraphEntity(ctx, relationship.RelatedEntityId)
 			if err != nil || childEntityMap == nil {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Child entity %s does not exist in Neo4j",
-					relationship.RelatedEntityId)
+				logging.FromContext(ctx).WarnContext(ctx, "Child entity does not exist in Neo4j",
+					"related_entity_id", relationship.RelatedEntityId)
 				return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsUpdate] child entity %s does not exist", relationship.RelatedEntityId)
 			}
 
+			// Check the relationship against the declared relationship types
+			if err := repo.relationshipTypes.Validate(ctx, relationship, kindFromGraphEntity(parentEntity), kindFromGraphEntity(childEntityMap)); err != nil {
+				logging.FromContext(ctx).DebugContext(ctx, "Relationship rejected", "relationship_id", relationship.Id, "error", err)
+				return err
+			}
+
 			// Create the relationship
 			_, err = repo.CreateRelationship(ctx, entity.Id, relationship)
 			if err != nil {
-				log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Failed to create relationship: %v", err)
-				return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsUpdate] failed to create relationship: %v", err)
+				logging.FromContext(ctx).ErrorContext(ctx, "Failed to create relationship", "error", err)
+				return fmt.Errorf("[neo4j_handler.HandleGraphRelationshipsUpdate] failed to create relationship: %w", err)
 			}
 
-			log.Printf("[neo4j_handler.HandleGraphRelationshipsUpdate] Successfully created relationship %s", relationship.Id)
+			logging.FromContext(ctx).DebugContext(ctx, "Created relationship", "relationship_id", relationship.Id)
 			continue
 		}
 	}
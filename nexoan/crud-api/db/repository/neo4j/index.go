@@ -0,0 +1,91 @@
+package neo4jrepository
+
+import (
+	"context"
+	"fmt"
+
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+)
+
+// readLabels returns the labels of the kinds in the graph in order, every major kind is a label
+func (r *Neo4jRepository) readLabels(ctx context.Context) ([]string, error) {
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, "CALL db.labels() YIELD label WHERE label <> $searchable RETURN label ORDER BY label",
+		map[string]interface{}{"searchable": searchableLabel})
+	if err != nil {
+		return nil, fmt.Errorf("error reading labels: %v", err)
+	}
+	labels := []string{}
+	for result.Next(ctx) {
+		labels = append(labels, fmt.Sprintf("%v", result.Record().Values[0]))
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("error reading labels: %v", err)
+	}
+	return labels, nil
+}
+
+// idConstraintName returns the name of the uniqueness constraint on the Id of the entities of a kind
+func idConstraintName(label string) string {
+	return "entity_id_" + label
+}
+
+// idConstraintStatement returns the statement creating the Id constraint of a kind
+func idConstraintStatement(label string) string {
+	return "CREATE CONSTRAINT " + cypher.Quote(idConstraintName(label)) + " IF NOT EXISTS " +
+		"FOR (n:" + cypher.Quote(label) + ") REQUIRE n.Id IS UNIQUE"
+}
+
+// createIdConstraint creates the uniqueness constraint on the Id of the entities of a kind. The constraint is
+// backed by an index, so it also serves the lookups of entities by kind and Id.
+func (r *Neo4jRepository) createIdConstraint(ctx context.Context, label string) error {
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	statement := idConstraintStatement(label)
+	if err := runStatement(ctx, session, statement, nil); err != nil {
+		return fmt.Errorf("error creating Id constraint for kind %s: %v", label, err)
+	}
+	return nil
+}
+
+// ensureIdConstraint creates the Id constraint of a kind the first time an entity of that kind is created.
+// A constraint that cannot be created, for instance because the kind already holds duplicate IDs, is logged
+// and not tried again until the next startup.
+func (r *Neo4jRepository) ensureIdConstraint(ctx context.Context, label string) {
+	r.constraintMu.Lock()
+	defer r.constraintMu.Unlock()
+	if r.constrainedLabels[label] {
+		return
+	}
+	if r.constrainedLabels == nil {
+		r.constrainedLabels = make(map[string]bool)
+	}
+	r.constrainedLabels[label] = true
+	if err := r.createIdConstraint(ctx, label); err != nil {
+		logging.FromContext(ctx).WarnContext(ctx, "Failed to create entity Id constraint", "kind", label, "error", err)
+	}
+}
+
+// CreateIndexes creates the Id constraint of every kind already in the graph, it is called once at startup
+func (r *Neo4jRepository) CreateIndexes(ctx context.Context) error {
+	labels, err := r.readLabels(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.constraintMu.Lock()
+	defer r.constraintMu.Unlock()
+	r.constrainedLabels = make(map[string]bool, len(labels))
+	for _, label := range labels {
+		if err := r.createIdConstraint(ctx, label); err != nil {
+			return err
+		}
+		r.constrainedLabels[label] = true
+	}
+	logging.FromContext(ctx).InfoContext(ctx, "Created entity Id constraints", "kinds", len(labels))
+	return nil
+}
@@ -3,50 +3,113 @@ package neo4jrepository
 import (
 	"context"
 	"fmt"
+	"lk/datafoundation/crud-api/commons"
 	"lk/datafoundation/crud-api/db/config"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
-	"log"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+	"lk/datafoundation/crud-api/pkg/tlsconfig"
+	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
 )
 
 type Neo4jRepository struct {
-	client neo4j.DriverWithContext
-	config *config.Neo4jConfig
+	client            neo4j.DriverWithContext
+	config            *config.Neo4jConfig
+	relationshipTypes *relationships.TypeRegistry
+
+	// searchIndexed is set once CreateSearchIndexes has created the entity name search index
+	searchMu      sync.Mutex
+	searchIndexed bool
+
+	// constrainedLabels are the labels whose Id constraint has been created or tried since startup
+	constraintMu      sync.Mutex
+	constrainedLabels map[string]bool
 }
 
 // NewNeo4jRepository initializes a Neo4j driver
 func NewNeo4jRepository(ctx context.Context, config *config.Neo4jConfig) (*Neo4jRepository, error) {
-	client, err := neo4j.NewDriverWithContext(config.URI, neo4j.BasicAuth(config.Username, config.Password, ""))
+	tlsConfig, err := tlsconfig.Client(config.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Neo4j TLS configuration: %w", err)
+	}
+	uri, err := tlsURI(config.URI, config.TLS.Mode)
+	if err != nil {
+		return nil, err
+	}
+	client, err := neo4j.NewDriverWithContext(uri, neo4j.BasicAuth(config.Username, config.Password, ""),
+		func(driverConfig *neo4jconfig.Config) {
+			if config.MaxConnectionPoolSize > 0 {
+				driverConfig.MaxConnectionPoolSize = config.MaxConnectionPoolSize
+			}
+			driverConfig.TlsConfig = tlsConfig
+		})
 	if err != nil {
-		log.Printf("[neo4j_client.NewNeo4jRepository] failed to create Neo4j driver: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to create Neo4j driver", "error", err)
 		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
 	}
 
 	// Verify connectivity
 	if err := client.VerifyConnectivity(ctx); err != nil {
 		client.Close(ctx) // Close if connectivity check fails
-		log.Printf("[neo4j_client.NewNeo4jRepository] failed to connect to Neo4j: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Failed to connect to Neo4j", "error", err)
 		return nil, fmt.Errorf("failed to connect to Neo4j: %w", err)
 	}
 
-	log.Println("[neo4j_client.NewNeo4jRepository] Connected to Neo4j successfully!")
+	logging.FromContext(ctx).InfoContext(ctx, "Connected to Neo4j")
 
 	return &Neo4jRepository{
-		client: client,
-		config: config,
+		client:            client,
+		config:            config,
+		relationshipTypes: relationships.NewTypeRegistry(),
 	}, nil
 }
 
+// tlsURI returns the URI with the scheme enabling the TLS mode. The driver only encrypts neo4j+s and neo4j+ssc
+// connections and it skips verification for neo4j+ssc, where verify-ca still verifies the chain on its own.
+func tlsURI(uri string, mode string) (string, error) {
+	if mode == "" || mode == "disable" {
+		return uri, nil
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("invalid Neo4j URI: %w", err)
+	}
+	if strings.Contains(parsed.Scheme, "+") {
+		return "", fmt.Errorf("the Neo4j URI must use the neo4j or bolt scheme when a TLS mode is set, got %s", parsed.Scheme)
+	}
+	if mode == "verify-full" {
+		parsed.Scheme += "+s"
+	} else {
+		parsed.Scheme += "+ssc"
+	}
+	return parsed.String(), nil
+}
+
+// RelationshipTypes returns the relationship type registry used to validate new relationships
+func (r *Neo4jRepository) RelationshipTypes() *relationships.TypeRegistry {
+	return r.relationshipTypes
+}
+
 // Close properly closes the Neo4j driver
 func (r *Neo4jRepository) Close(ctx context.Context) {
 	if r.client != nil {
 		r.client.Close(ctx)
-		log.Println("Neo4j connection closed")
+		logging.FromContext(ctx).InfoContext(ctx, "Neo4j connection closed")
 	}
 }
 
+// Ping verifies that the Neo4j server can still be reached
+func (r *Neo4jRepository) Ping(ctx context.Context) error {
+	return r.client.VerifyConnectivity(ctx)
+}
+
 // getSession creates a new session
 func (r *Neo4jRepository) getSession(ctx context.Context) neo4j.SessionWithContext {
 	return r.client.NewSession(ctx, neo4j.SessionConfig{
@@ -58,73 +121,65 @@ func (r *Neo4jRepository) getSession(ctx context.Context) neo4j.SessionWithConte
 func (r *Neo4jRepository) CreateGraphEntity(ctx context.Context, kind *pb.Kind, entityMap map[string]interface{}) (map[string]interface{}, error) {
 	// Validate the kind parameter
 	if kind == nil || kind.Major == "" {
-		log.Printf("[neo4j_client.CreateGraphEntity] missing or invalid 'Kind.Major' field")
+		logging.FromContext(ctx).WarnContext(ctx, "Missing or invalid 'Kind.Major' field")
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] missing or invalid 'Kind.Major' field")
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] Kind.Major: %v", kind.Major)
 	}
 
 	// Extract the required fields from the entityMap
 	id, ok := entityMap["Id"].(string)
 	if !ok {
-		log.Printf("[neo4j_client.CreateGraphEntity] missing or invalid 'Id' field")
+		logging.FromContext(ctx).WarnContext(ctx, "Missing or invalid 'Id' field")
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] missing or invalid 'Id' field")
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] Id: %v", id)
 	}
 
 	name, ok := entityMap["Name"].(string)
 	if !ok {
-		log.Printf("[neo4j_client.CreateGraphEntity] missing or invalid 'Name' field")
+		logging.FromContext(ctx).WarnContext(ctx, "Missing or invalid 'Name' field")
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] missing or invalid 'Name' field")
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] Name: %v", name)
 	}
 
 	created, ok := entityMap["Created"].(string)
 	if !ok {
-		log.Printf("[neo4j_client.CreateGraphEntity] missing or invalid 'Created' field")
+		logging.FromContext(ctx).WarnContext(ctx, "Missing or invalid 'Created' field")
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] missing or invalid 'Created' field")
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] Created: %v", created)
 	}
 
 	// Optional field
 	var terminated *string
 	if term, ok := entityMap["Terminated"].(string); ok {
 		terminated = &term
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] Terminated: %v", terminated)
 	}
+	logger := logging.FromContext(ctx).With("entity_id", id)
+	logger.DebugContext(ctx, "Creating graph entity", "kind", kind.Major, "name", name, "created", created)
+
+	label, err := quoteKind(kind.Major)
+	if err != nil {
+		logger.WarnContext(ctx, "Invalid kind", "kind", kind.Major, "error", err)
+		return nil, err
+	}
+
+	// Make sure the kind has its Id constraint before its first entity is created
+	r.ensureIdConstraint(ctx, kind.Major)
 
 	// Open a session
 	session := r.getSession(ctx)
 	defer session.Close(ctx)
 
 	// Check if the node already exists
-	existsQuery := `MATCH (e:` + kind.Major + ` {Id: $Id}) RETURN e`
-	result, err := session.Run(ctx, existsQuery, map[string]interface{}{"Id": id})
+	result, err := session.Run(ctx, entityExistsQuery(label), map[string]interface{}{"Id": id})
 	if err != nil {
-		log.Printf("[neo4j_client.CreateGraphEntity] error checking if entity exists: %v", err)
+		logger.ErrorContext(ctx, "Error checking if entity exists", "error", err)
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] error checking if entity exists: %v", err)
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] existsQuery: %v", existsQuery)
 	}
 
 	// If entity exists, return an error
 	if result.Next(ctx) {
-		log.Printf("[neo4j_client.CreateGraphEntity] entity with Id %s already exists", id)
+		logger.WarnContext(ctx, "Entity already exists")
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] entity with Id %s already exists", id)
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] entity with Id %s does not exist", id)
 	}
 
 	// Create the node
-	createQuery := `CREATE (e:` + kind.Major + ` {Id: $Id, Name: $Name, Created: datetime($Created), MinorKind: $MinorKind`
-	if terminated != nil {
-		createQuery += `, Terminated: datetime($Terminated)`
-	}
-	createQuery += `}) RETURN e`
+	createQuery := createEntityQuery(label, terminated != nil)
 
 	// Set parameters for the query
 	params := map[string]interface{}{
@@ -140,10 +195,8 @@ func (r *Neo4jRepository) CreateGraphEntity(ctx context.Context, kind *pb.Kind,
 	// Run the query to create the entity and return it
 	result, err = session.Run(ctx, createQuery, params)
 	if err != nil {
-		log.Printf("[neo4j_client.CreateGraphEntity] error creating entity: %v", err)
+		logger.ErrorContext(ctx, "Error creating entity", "error", err)
 		return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] error creating entity: %v", err)
-	} else {
-		log.Printf("[neo4j_client.CreateGraphEntity] created entity(run query): %v", params)
 	}
 
 	// Retrieve the created entity
@@ -151,10 +204,8 @@ func (r *Neo4jRepository) CreateGraphEntity(ctx context.Context, kind *pb.Kind,
 		createdEntity, _ := result.Record().Get("e")
 		node, ok := createdEntity.(neo4j.Node)
 		if !ok {
-			log.Printf("[neo4j_client.CreateGraphEntity] failed to cast created entity to neo4j.Node")
+			logger.ErrorContext(ctx, "Failed to cast created entity to neo4j.Node")
 			return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] failed to cast created entity to neo4j.Node")
-		} else {
-			log.Printf("[neo4j_client.CreateGraphEntity] created entity(retrieved-initial): %v", createdEntity)
 		}
 
 		// Convert the node properties to a map
@@ -177,120 +228,138 @@ func (r *Neo4jRepository) CreateGraphEntity(ctx context.Context, kind *pb.Kind,
 			} else {
 				createdEntityMap["Terminated"] = fmt.Sprintf("%v", *terminated)
 			}
-		} else {
-			log.Printf("[neo4j_client.CreateGraphEntity] Terminated: %v", terminated)
 		}
-		log.Printf("[neo4j_client.CreateGraphEntity] created entity(retrieved-final): %v", createdEntityMap)
+		logger.DebugContext(ctx, "Created graph entity", logging.Payload("entity", createdEntityMap))
 		return createdEntityMap, nil
 	}
 
-	log.Printf("[neo4j_client.CreateGraphEntity] failed to create entity")
+	logger.ErrorContext(ctx, "Failed to create entity")
 	return nil, fmt.Errorf("[neo4j_client.CreateGraphEntity] failed to create entity")
 }
 
 // CreateRelationship creates a relationship between two entities
 func (r *Neo4jRepository) CreateRelationship(ctx context.Context, entityID string, rel *pb.Relationship) (map[string]interface{}, error) {
-	session := r.getSession(ctx)
-	defer session.Close(ctx)
-
-	// Check if relationship with this ID already exists
-	relExistsQuery := `MATCH ()-[r {Id: $relationshipID}]->() RETURN r`
-	relResult, err := session.Run(ctx, relExistsQuery, map[string]interface{}{
-		"relationshipID": rel.Id,
-	})
+	// Convert the relationship properties before touching the database
+	properties, err := relationships.ConvertProperties(rel.Properties)
 	if err != nil {
-		log.Printf("[neo4j_client.CreateRelationship] error checking if relationship exists: %v", err)
-		return nil, fmt.Errorf("error checking if relationship exists: %v", err)
+		logging.FromContext(ctx).WarnContext(ctx, "Invalid relationship properties", "error", err)
+		return nil, fmt.Errorf("invalid relationship properties: %w", err)
 	}
-	if relResult.Next(ctx) {
-		log.Printf("[neo4j_client.CreateRelationship] relationship with Id %s already exists", rel.Id)
-		return nil, fmt.Errorf("relationship with Id %s already exists", rel.Id)
-	}
-
-	existsQuery := `MATCH (p {Id: $parentID}), (c {Id: $childID}) RETURN p, c`
-	result, err := session.Run(ctx, existsQuery, map[string]interface{}{
-		"parentID": entityID,
-		"childID":  rel.RelatedEntityId,
-	})
+	relType, err := cypher.QuoteIdentifier(rel.Name)
 	if err != nil {
-		log.Printf("[neo4j_client.CreateRelationship] error checking entities: %v", err)
-		return nil, fmt.Errorf("error checking entities: %v", err)
-	} else {
-		log.Printf("[neo4j_client.CreateRelationship] existsQuery: %v", existsQuery)
-	}
-	if !result.Next(ctx) {
-		log.Printf("[neo4j_client.CreateRelationship] either parent or child entity does not exist")
-		return nil, fmt.Errorf("either parent or child entity does not exist")
-	} else {
-		log.Printf("[neo4j_client.CreateRelationship] either parent or child entity exist")
+		logging.FromContext(ctx).WarnContext(ctx, "Invalid relationship name", "name", rel.Name, "error", err)
+		return nil, err
 	}
 
-	params := map[string]interface{}{
-		"parentID":       entityID,
-		"childID":        rel.RelatedEntityId,
-		"relationshipID": rel.Id,
-		"startDate":      rel.StartTime,
-	}
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
 
-	createQuery := `MATCH (p {Id: $parentID}), (c {Id: $childID})
-					CREATE (p)-[r:` + rel.Name + ` {Id: $relationshipID, Created: datetime($startDate)`
+	// The checks and the write run in one transaction that locks the endpoints first, so concurrent writes
+	// of relationships between the same entities are checked one after another
+	written, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Lock the endpoints, this also checks that they exist
+		existsQuery := `MATCH (p {Id: $parentID}), (c {Id: $childID}) ` + lockEndpointsClause + ` RETURN p, c`
+		result, err := tx.Run(ctx, existsQuery, map[string]interface{}{
+			"parentID": entityID,
+			"childID":  rel.RelatedEntityId,
+		})
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error checking entities", "error", err)
+			return nil, fmt.Errorf("error checking entities: %v", err)
+		}
+		if !result.Next(ctx) {
+			logging.FromContext(ctx).WarnContext(ctx, "Either parent or child entity does not exist", "entity_id", entityID, "related_entity_id", rel.RelatedEntityId)
+			return nil, fmt.Errorf("either parent or child entity does not exist")
+		}
 
-	if rel.EndTime != "" {
-		createQuery += `, Terminated: datetime($endDate)`
-		params["endDate"] = rel.EndTime
-	}
+		// Check if relationship with this ID already exists
+		relExistsQuery := `MATCH ()-[r {Id: $relationshipID}]->() RETURN r`
+		relResult, err := tx.Run(ctx, relExistsQuery, map[string]interface{}{
+			"relationshipID": rel.Id,
+		})
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error checking if relationship exists", "error", err)
+			return nil, fmt.Errorf("error checking if relationship exists: %v", err)
+		}
+		if relResult.Next(ctx) {
+			logging.FromContext(ctx).WarnContext(ctx, "Relationship already exists", "relationship_id", rel.Id)
+			return nil, fmt.Errorf("relationship with Id %s already exists", rel.Id)
+		}
 
-	createQuery += `}]->(c) RETURN r`
+		// Validate the relationship against the configured integrity rules
+		if err := r.validateRelationshipIntegrity(ctx, tx, &relationships.Relationship{
+			ID:         rel.Id,
+			Type:       rel.Name,
+			SourceID:   entityID,
+			TargetID:   rel.RelatedEntityId,
+			Created:    rel.StartTime,
+			Terminated: rel.EndTime,
+		}); err != nil {
+			return nil, err
+		}
 
-	result, err = session.Run(ctx, createQuery, params)
-	if err != nil {
-		log.Printf("[neo4j_client.CreateRelationship] error creating relationship: %v", err)
-		return nil, fmt.Errorf("error creating relationship: %v", err)
-	} else {
-		log.Printf("[neo4j_client.CreateRelationship] createQuery: %v", createQuery)
-		log.Printf("[neo4j_client.CreateRelationship] params: %v", params)
-	}
+		params := map[string]interface{}{
+			"parentID":       entityID,
+			"childID":        rel.RelatedEntityId,
+			"relationshipID": rel.Id,
+			"startDate":      rel.StartTime,
+			"properties":     properties,
+		}
 
-	if result.Next(ctx) {
+		createQuery := createRelationshipQuery(relType, rel.EndTime != "")
+		if rel.EndTime != "" {
+			params["endDate"] = rel.EndTime
+		}
+
+		result, err = tx.Run(ctx, createQuery, params)
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error creating relationship", "error", err)
+			return nil, fmt.Errorf("error creating relationship: %v", err)
+		}
+		if !result.Next(ctx) {
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to retrieve created relationship", "relationship_id", rel.Id)
+			return nil, fmt.Errorf("failed to retrieve created relationship")
+		}
 		createdRel, _ := result.Record().Get("r")
 		relationship, ok := createdRel.(neo4j.Relationship)
 		if !ok {
-			log.Printf("[neo4j_client.CreateRelationship] failed to cast created relationship to neo4j.Relationship")
+			logging.FromContext(ctx).ErrorContext(ctx, "Failed to cast created relationship to neo4j.Relationship")
 			return nil, fmt.Errorf("failed to cast created relationship to neo4j.Relationship")
-		} else {
-			log.Printf("[neo4j_client.CreateRelationship] created relationship: %v", createdRel)
 		}
+		return relationship, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	relationship := written.(neo4j.Relationship)
 
-		relationshipMap := map[string]interface{}{
-			"Id":               fmt.Sprintf("%v", relationship.Props["Id"]),
-			"relationshipType": rel.Name,
-		}
+	relationshipMap := map[string]interface{}{
+		"Id":               fmt.Sprintf("%v", relationship.Props["Id"]),
+		"relationshipType": rel.Name,
+		"properties":       userRelationshipProperties(relationship.Props),
+	}
 
-		// Handle date fields with proper formatting
-		if created, ok := relationship.Props["Created"].(time.Time); ok {
-			relationshipMap["Created"] = created.Format(time.RFC3339)
-		} else {
-			relationshipMap["Created"] = fmt.Sprintf("%v", relationship.Props["Created"])
-		}
+	// Handle date fields with proper formatting
+	if created, ok := relationship.Props["Created"].(time.Time); ok {
+		relationshipMap["Created"] = created.Format(time.RFC3339)
+	} else {
+		relationshipMap["Created"] = fmt.Sprintf("%v", relationship.Props["Created"])
+	}
 
-		if rel.EndTime != "" {
-			if terminated, ok := relationship.Props["Terminated"].(time.Time); ok {
-				relationshipMap["Terminated"] = terminated.Format(time.RFC3339)
-			} else {
-				relationshipMap["Terminated"] = fmt.Sprintf("%v", relationship.Props["Terminated"])
-			}
+	if rel.EndTime != "" {
+		if terminated, ok := relationship.Props["Terminated"].(time.Time); ok {
+			relationshipMap["Terminated"] = terminated.Format(time.RFC3339)
+		} else {
+			relationshipMap["Terminated"] = fmt.Sprintf("%v", relationship.Props["Terminated"])
 		}
-
-		log.Printf("[neo4j_client.CreateRelationship] created relationship: %v", relationshipMap)
-		return relationshipMap, nil
-	} else {
-		log.Printf("[neo4j_client.CreateRelationship] failed to retrieve created relationship: %v", result)
 	}
 
-	return nil, fmt.Errorf("failed to retrieve created relationship")
+	logging.FromContext(ctx).DebugContext(ctx, "Created relationship", "relationship_id", rel.Id, logging.Payload("relationship", relationshipMap))
+	return relationshipMap, nil
 }
 
 // ReadGraphEntity retrieves an entity by its ID from the Neo4j database and returns it as a map.
+// The error wraps commons.ErrNotFound when there is no such entity.
 func (r *Neo4jRepository) ReadGraphEntity(ctx context.Context, entityID string) (map[string]interface{}, error) {
 	if entityID == "" {
 		return nil, fmt.Errorf("entity Id cannot be empty")
@@ -303,7 +372,7 @@ func (r *Neo4jRepository) ReadGraphEntity(ctx context.Context, entityID string)
 	// Cypher query to retrieve the entity with both Major and Minor kinds
 	query := `
         MATCH (e {Id: $Id})
-        RETURN labels(e)[0] AS MajorKind, e.MinorKind AS MinorKind, e.Id AS Id, e.Name AS Name, 
+        RETURN ` + majorKindOf("e") + ` AS MajorKind, e.MinorKind AS MinorKind, e.Id AS Id, e.Name AS Name, 
                toString(e.Created) AS Created, 
                CASE WHEN e.Terminated IS NOT NULL THEN toString(e.Terminated) ELSE NULL END AS Terminated
     `
@@ -311,7 +380,7 @@ func (r *Neo4jRepository) ReadGraphEntity(ctx context.Context, entityID string)
 	// Run the query
 	result, err := session.Run(ctx, query, map[string]interface{}{"Id": entityID})
 	if err != nil {
-		log.Printf("[neo4j_client.ReadGraphEntity] error querying entity: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying entity", "error", err)
 		return nil, fmt.Errorf("error querying entity: %v", err)
 	}
 
@@ -321,11 +390,16 @@ func (r *Neo4jRepository) ReadGraphEntity(ctx context.Context, entityID string)
 
 		// Map the entity properties
 		entity := map[string]interface{}{
-			"Id":        fmt.Sprintf("%v", record.Values[2]), // e.Id
-			"Name":      fmt.Sprintf("%v", record.Values[3]), // e.Name
-			"Created":   fmt.Sprintf("%v", record.Values[4]), // e.Created
-			"MajorKind": fmt.Sprintf("%v", record.Values[0]), // labels(e)[0]
-			"MinorKind": fmt.Sprintf("%v", record.Values[1]), // e.MinorKind
+			"Id":      fmt.Sprintf("%v", record.Values[2]), // e.Id
+			"Name":    fmt.Sprintf("%v", record.Values[3]), // e.Name
+			"Created": fmt.Sprintf("%v", record.Values[4]), // e.Created
+		}
+		// The kind is left out when it is missing rather than read as "<nil>"
+		if major, ok := record.Values[0].(string); ok { // major kind
+			entity["MajorKind"] = major
+		}
+		if minor, ok := record.Values[1].(string); ok { // e.MinorKind
+			entity["MinorKind"] = minor
 		}
 
 		// Add Terminated if it exists
@@ -337,7 +411,7 @@ func (r *Neo4jRepository) ReadGraphEntity(ctx context.Context, entityID string)
 	}
 
 	// If no entity is found
-	return nil, fmt.Errorf("entity with Id %s not found", entityID)
+	return nil, fmt.Errorf("entity with Id %s: %w", entityID, commons.ErrNotFound)
 }
 
 // ReadRelatedGraphEntityIds retrieves related relationships based on a given relationship type and timestamp
@@ -346,21 +420,20 @@ func (r *Neo4jRepository) ReadRelatedGraphEntityIds(ctx context.Context, entityI
 		return nil, fmt.Errorf("entity Id cannot be empty")
 	}
 
+	relType, err := cypher.QuoteIdentifier(relationship)
+	if err != nil {
+		return nil, err
+	}
+
 	session := r.getSession(ctx)
 	defer session.Close(ctx)
 
-	query := fmt.Sprintf(`
-        MATCH (e {Id: $entityID})-[r:%s]->(related)
-        WHERE r.Created <= datetime($ts) AND (r.Terminated IS NULL OR r.Terminated > datetime($ts))
-        RETURN r.Id AS relationshipID, r.Created AS startTime, r.Terminated AS endTime, type(r) AS name, related.Id AS relatedEntityId
-    `, relationship)
-
-	result, err := session.Run(ctx, query, map[string]interface{}{
+	result, err := session.Run(ctx, relatedEntitiesQuery(relType), map[string]interface{}{
 		"entityID": entityID,
 		"ts":       ts,
 	})
 	if err != nil {
-		log.Printf("[neo4j_client.ReadRelatedGraphEntityIds] error querying related entities: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying related entities", "error", err)
 		return nil, fmt.Errorf("error querying related entities: %v", err)
 	}
 
@@ -410,7 +483,7 @@ func (r *Neo4jRepository) ReadRelatedGraphEntityIds(ctx context.Context, entityI
 	}
 
 	if err := result.Err(); err != nil {
-		log.Printf("[neo4j_client.ReadRelatedGraphEntityIds] error iterating over query result: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error iterating over query result", "error", err)
 		return nil, fmt.Errorf("error iterating over query result: %v", err)
 	}
 
@@ -433,13 +506,13 @@ func (r *Neo4jRepository) ReadRelationships(ctx context.Context, entityID string
         RETURN type(r) AS type, related.Id AS relatedID, "OUTGOING" AS direction, 
                toString(r.Created) AS Created, 
                CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS Terminated,
-               r.Id AS relationshipID
+               r.Id AS relationshipID, properties(r) AS properties
         UNION
         MATCH (e {Id: $entityID})<-[r]-(related)
         RETURN type(r) AS type, related.Id AS relatedID, "INCOMING" AS direction, 
                toString(r.Created) AS Created, 
                CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS Terminated,
-               r.Id AS relationshipID
+               r.Id AS relationshipID, properties(r) AS properties
     `
 
 	// Run the query
@@ -447,7 +520,7 @@ func (r *Neo4jRepository) ReadRelationships(ctx context.Context, entityID string
 		"entityID": entityID,
 	})
 	if err != nil {
-		log.Printf("[neo4j_client.ReadRelationships] error querying relationships: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying relationships", "error", err)
 		return nil, fmt.Errorf("error querying relationships: %v", err)
 	}
 
@@ -458,7 +531,7 @@ func (r *Neo4jRepository) ReadRelationships(ctx context.Context, entityID string
 		values := record.Values
 
 		// Ensure expected values exist
-		if len(values) < 6 {
+		if len(values) < 7 {
 			continue
 		}
 
@@ -471,6 +544,11 @@ func (r *Neo4jRepository) ReadRelationships(ctx context.Context, entityID string
 			"relationshipID": fmt.Sprintf("%v", values[5]), // Relationship ID
 		}
 
+		// Relationship properties
+		if props, ok := values[6].(map[string]interface{}); ok {
+			rel["properties"] = userRelationshipProperties(props)
+		}
+
 		// Optional Terminated
 		if values[4] != nil {
 			rel["Terminated"] = fmt.Sprintf("%v", values[4])
@@ -499,7 +577,7 @@ func (r *Neo4jRepository) ReadRelationship(ctx context.Context, relationshipID s
         RETURN type(r) AS type, startNode(r).Id AS startEntityID, endNode(r).Id AS endEntityID, 
                toString(r.Created) AS Created, 
                CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS Terminated, 
-               r.Id AS relationshipID
+               r.Id AS relationshipID, properties(r) AS properties
     `
 
 	// Run the query to fetch the relationship
@@ -507,7 +585,7 @@ func (r *Neo4jRepository) ReadRelationship(ctx context.Context, relationshipID s
 		"relationshipID": relationshipID,
 	})
 	if err != nil {
-		log.Printf("[neo4j_client.ReadRelationship] error querying relationship: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying relationship", "error", err)
 		return nil, fmt.Errorf("error querying relationship: %v", err)
 	}
 
@@ -517,8 +595,8 @@ func (r *Neo4jRepository) ReadRelationship(ctx context.Context, relationshipID s
 		values := record.Values
 
 		// Ensure expected values exist
-		if len(values) < 6 {
-			log.Printf("[neo4j_client.ReadRelationship] unexpected data format for relationship")
+		if len(values) < 7 {
+			logging.FromContext(ctx).ErrorContext(ctx, "Unexpected data format for relationship")
 			return nil, fmt.Errorf("unexpected data format for relationship")
 		}
 
@@ -536,12 +614,89 @@ func (r *Neo4jRepository) ReadRelationship(ctx context.Context, relationshipID s
 			relationship["Terminated"] = fmt.Sprintf("%v", values[4])
 		}
 
+		// Relationship properties
+		if props, ok := values[6].(map[string]interface{}); ok {
+			relationship["properties"] = userRelationshipProperties(props)
+		}
+
 		// Return the relationship data as a map
 		return relationship, nil
 	}
 
 	// If no relationship was found
-	return nil, fmt.Errorf("relationship with Id %s not found", relationshipID)
+	return nil, &relationships.NotFoundError{RelationshipID: relationshipID}
+}
+
+// ReadRelationshipsByEndpoints retrieves the relationships starting at sourceID and/or ending at targetID.
+// At least one endpoint is required, the relationship type and activeAt filters are optional.
+// The returned maps use the same keys as ReadRelationship.
+func (r *Neo4jRepository) ReadRelationshipsByEndpoints(ctx context.Context, sourceID string, targetID string, relationshipType string, activeAt string) ([]map[string]interface{}, error) {
+	if sourceID == "" && targetID == "" {
+		return nil, fmt.Errorf("either a source or a target entity Id is required")
+	}
+
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	params := map[string]interface{}{}
+	query := `
+        MATCH (source)-[r]->(target)
+        WHERE 1=1
+    `
+	if sourceID != "" {
+		params["sourceID"] = sourceID
+		query += ` AND source.Id = $sourceID`
+	}
+	if targetID != "" {
+		params["targetID"] = targetID
+		query += ` AND target.Id = $targetID`
+	}
+	if relationshipType != "" {
+		params["relationshipType"] = relationshipType
+		query += ` AND type(r) = $relationshipType`
+	}
+	if activeAt != "" {
+		params["activeAt"] = activeAt
+		query += ` AND r.Created <= datetime($activeAt) AND (r.Terminated IS NULL OR r.Terminated > datetime($activeAt))`
+	}
+	query += `
+        RETURN type(r) AS type, source.Id AS startEntityID, target.Id AS endEntityID,
+               toString(r.Created) AS Created,
+               CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS Terminated,
+               r.Id AS relationshipID, properties(r) AS properties
+        ORDER BY r.Created, r.Id
+    `
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying relationships", "error", err)
+		return nil, fmt.Errorf("error querying relationships: %v", err)
+	}
+
+	relationships := []map[string]interface{}{}
+	for result.Next(ctx) {
+		values := result.Record().Values
+		relationship := map[string]interface{}{
+			"type":           fmt.Sprintf("%v", values[0]),
+			"startEntityID":  fmt.Sprintf("%v", values[1]),
+			"endEntityID":    fmt.Sprintf("%v", values[2]),
+			"Created":        fmt.Sprintf("%v", values[3]),
+			"relationshipID": fmt.Sprintf("%v", values[5]),
+		}
+		if values[4] != nil {
+			relationship["Terminated"] = fmt.Sprintf("%v", values[4])
+		}
+		if props, ok := values[6].(map[string]interface{}); ok {
+			relationship["properties"] = userRelationshipProperties(props)
+		}
+		relationships = append(relationships, relationship)
+	}
+	if err := result.Err(); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error iterating over relationships", "error", err)
+		return nil, fmt.Errorf("error iterating over relationships: %v", err)
+	}
+
+	return relationships, nil
 }
 
 // UpdateGraphEntity updates the properties of an existing entity
@@ -563,12 +718,12 @@ func (r *Neo4jRepository) UpdateGraphEntity(ctx context.Context, id string, upda
 	existsQuery := `MATCH (e {Id: $Id}) RETURN e`
 	result, err := session.Run(ctx, existsQuery, params)
 	if err != nil {
-		log.Printf("[neo4j_client.UpdateGraphEntity] error checking if entity exists: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error checking if entity exists", "error", err)
 		return nil, fmt.Errorf("error checking if entity exists: %v", err)
 	}
 
 	if !result.Next(ctx) {
-		log.Printf("[neo4j_client.UpdateGraphEntity] entity with Id %s does not exist", id)
+		logging.FromContext(ctx).WarnContext(ctx, "Entity does not exist", "entity_id", id)
 		return nil, fmt.Errorf("entity with Id %s does not exist", id)
 	}
 
@@ -594,7 +749,7 @@ func (r *Neo4jRepository) UpdateGraphEntity(ctx context.Context, id string, upda
 
 	result, err = session.Run(ctx, query, params)
 	if err != nil {
-		log.Printf("[neo4j_client.UpdateGraphEntity] error updating entity: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error updating entity", "error", err)
 		return nil, fmt.Errorf("error updating entity: %v", err)
 	}
 
@@ -602,7 +757,7 @@ func (r *Neo4jRepository) UpdateGraphEntity(ctx context.Context, id string, upda
 	if result.Next(ctx) {
 		node, ok := result.Record().Get("e")
 		if !ok {
-			log.Printf("[neo4j_client.UpdateGraphEntity] unexpected error retrieving entity")
+			logging.FromContext(ctx).ErrorContext(ctx, "Unexpected error retrieving entity")
 			return nil, fmt.Errorf("unexpected error retrieving entity")
 		}
 
@@ -628,115 +783,181 @@ func (r *Neo4jRepository) UpdateGraphEntity(ctx context.Context, id string, upda
 }
 
 func (r *Neo4jRepository) UpdateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error) {
-	log.Printf("[neo4j_client.UpdateRelationship] Updating relationship %s with data: %+v", relationshipID, updateData)
+	logging.FromContext(ctx).DebugContext(ctx, "Updating relationship", "relationship_id", relationshipID, logging.Payload("update_data", updateData))
 
 	if relationshipID == "" {
-		log.Printf("[neo4j_client.UpdateRelationship] relationship Id cannot be empty")
+		logging.FromContext(ctx).WarnContext(ctx, "Relationship ID is required")
 		return nil, fmt.Errorf("relationship Id cannot be empty")
 	}
 
-	// Prepare update parameters
-	params := map[string]interface{}{
-		"relationshipID": relationshipID,
-	}
-
 	// Open session
 	session := r.getSession(ctx)
 	defer session.Close(ctx)
 
-	// Check if the relationship exists
-	existsQuery := `MATCH ()-[r {Id: $relationshipID}]->() RETURN r`
-	result, err := session.Run(ctx, existsQuery, params)
-	if err != nil {
-		log.Printf("[neo4j_client.UpdateRelationship] error checking if relationship exists: %v", err)
-		return nil, fmt.Errorf("error checking if relationship exists: %v", err)
-	}
+	// The checks and the write run in one transaction that locks the endpoints first, so concurrent writes
+	// of relationships between the same entities are checked one after another
+	written, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		// Prepare update parameters
+		params := map[string]interface{}{
+			"relationshipID": relationshipID,
+		}
 
-	if !result.Next(ctx) {
-		log.Printf("[neo4j_client.UpdateRelationship] relationship with Id %s does not exist", relationshipID)
-		return nil, fmt.Errorf("relationship with Id %s does not exist", relationshipID)
-	}
+		// Lock the endpoints and check if the relationship exists
+		existsQuery := `MATCH (p)-[r {Id: $relationshipID}]->(c) ` + lockEndpointsClause + `
+			RETURN type(r) AS type, p.Id AS parentID, c.Id AS childID,
+			       toString(r.Created) AS created, toString(r.Terminated) AS terminated`
+		result, err := tx.Run(ctx, existsQuery, params)
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error checking if relationship exists", "error", err)
+			return nil, fmt.Errorf("error checking if relationship exists: %v", err)
+		}
 
-	// Build Cypher query for updating relationship
-	query := `
-        MATCH ()-[r {Id: $relationshipID}]->()
-    `
+		if !result.Next(ctx) {
+			logging.FromContext(ctx).WarnContext(ctx, "Relationship does not exist", "relationship_id", relationshipID)
+			return nil, fmt.Errorf("relationship with Id %s does not exist", relationshipID)
+		}
+		existing := result.Record()
+
+		// Validate the resulting interval against the integrity rules when the dates change
+		_, createdChanged := updateData["Created"]
+		_, terminatedChanged := updateData["Terminated"]
+		if createdChanged || terminatedChanged {
+			relType, _ := existing.Get("type")
+			parentID, _ := existing.Get("parentID")
+			childID, _ := existing.Get("childID")
+			created, _ := existing.Get("created")
+			terminated, _ := existing.Get("terminated")
+
+			interval := &relationships.Relationship{
+				ID:       relationshipID,
+				Type:     fmt.Sprintf("%v", relType),
+				SourceID: fmt.Sprintf("%v", parentID),
+				TargetID: fmt.Sprintf("%v", childID),
+			}
+			if created != nil {
+				interval.Created = fmt.Sprintf("%v", created)
+			}
+			if terminated != nil {
+				interval.Terminated = fmt.Sprintf("%v", terminated)
+			}
+			if createdChanged {
+				interval.Created = fmt.Sprintf("%v", updateData["Created"])
+			}
+			if terminatedChanged {
+				interval.Terminated = fmt.Sprintf("%v", updateData["Terminated"])
+			}
 
-	// Track if we have any fields to update
-	hasUpdates := false
+			if err := r.validateRelationshipIntegrity(ctx, tx, interval); err != nil {
+				return nil, err
+			}
+		}
 
-	// Add `Created` if provided
-	if created, exists := updateData["Created"]; exists {
-		params["Created"] = created
-		if hasUpdates {
-			query += `, r.Created = datetime($Created) `
-		} else {
-			query += `SET r.Created = datetime($Created) `
-			hasUpdates = true
+		// Build Cypher query for updating relationship
+		query := `
+            MATCH ()-[r {Id: $relationshipID}]->()
+        `
+
+		// Track if we have any fields to update
+		hasUpdates := false
+
+		// Add `Created` if provided
+		if created, exists := updateData["Created"]; exists {
+			params["Created"] = created
+			if hasUpdates {
+				query += `, r.Created = datetime($Created) `
+			} else {
+				query += `SET r.Created = datetime($Created) `
+				hasUpdates = true
+			}
 		}
-	}
 
-	// Add `Terminated` if provided
-	if terminated, exists := updateData["Terminated"]; exists {
-		params["Terminated"] = terminated
-		if hasUpdates {
-			query += `, r.Terminated = datetime($Terminated) `
-		} else {
-			query += `SET r.Terminated = datetime($Terminated) `
-			hasUpdates = true
+		// Add `Terminated` if provided
+		if terminated, exists := updateData["Terminated"]; exists {
+			params["Terminated"] = terminated
+			if hasUpdates {
+				query += `, r.Terminated = datetime($Terminated) `
+			} else {
+				query += `SET r.Terminated = datetime($Terminated) `
+				hasUpdates = true
+			}
 		}
-	}
 
-	// Check for any unsupported fields
-	for key := range updateData {
-		if key != "Created" && key != "Terminated" {
-			log.Printf("[neo4j_client.UpdateRelationship] unsupported field '%s' provided for update", key)
-			return nil, fmt.Errorf("unsupported field '%s' for relationship update. Only 'Created' and 'Terminated' are allowed", key)
+		// Add relationship `Properties` if provided, existing properties not in the map are kept
+		if properties, exists := updateData["Properties"]; exists {
+			propertiesMap, ok := properties.(map[string]interface{})
+			if !ok {
+				logging.FromContext(ctx).WarnContext(ctx, "Invalid type for 'Properties'", "type", fmt.Sprintf("%T", properties))
+				return nil, fmt.Errorf("invalid type %T for 'Properties'. Expected map[string]interface{}", properties)
+			}
+			for key := range propertiesMap {
+				if relationships.IsReservedProperty(key) {
+					logging.FromContext(ctx).WarnContext(ctx, "Relationship property is reserved", "field", key)
+					return nil, fmt.Errorf("relationship property '%s' is reserved", key)
+				}
+			}
+			params["Properties"] = propertiesMap
+			if hasUpdates {
+				query += `, r += $Properties `
+			} else {
+				query += `SET r += $Properties `
+				hasUpdates = true
+			}
 		}
-	}
 
-	// If no fields to update, return error
-	if !hasUpdates {
-		log.Printf("[neo4j_client.UpdateRelationship] no valid fields provided for update")
-		return nil, fmt.Errorf("no valid fields provided for update")
-	}
+		// Check for any unsupported fields
+		for key := range updateData {
+			if key != "Created" && key != "Terminated" && key != "Properties" {
+				logging.FromContext(ctx).WarnContext(ctx, "Unsupported field provided for update", "field", key)
+				return nil, fmt.Errorf("unsupported field '%s' for relationship update. Only 'Created', 'Terminated' and 'Properties' are allowed", key)
+			}
+		}
 
-	query += `RETURN r`
+		// If no fields to update, return error
+		if !hasUpdates {
+			logging.FromContext(ctx).WarnContext(ctx, "No valid fields provided for update")
+			return nil, fmt.Errorf("no valid fields provided for update")
+		}
 
-	// Execute update query and return updated relationship
-	result, err = session.Run(ctx, query, params)
-	if err != nil {
-		log.Printf("[neo4j_client.UpdateRelationship] error updating relationship: %v", err)
-		return nil, fmt.Errorf("error updating relationship: %v", err)
-	}
+		query += `RETURN r`
 
-	// Retrieve updated relationship
-	if result.Next(ctx) {
+		// Execute update query and return updated relationship
+		result, err = tx.Run(ctx, query, params)
+		if err != nil {
+			logging.FromContext(ctx).ErrorContext(ctx, "Error updating relationship", "error", err)
+			return nil, fmt.Errorf("error updating relationship: %v", err)
+		}
+
+		if !result.Next(ctx) {
+			return nil, fmt.Errorf("failed to retrieve updated relationship")
+		}
 		rel, ok := result.Record().Get("r")
 		if !ok {
-			log.Printf("[neo4j_client.UpdateRelationship] unexpected error retrieving relationship")
+			logging.FromContext(ctx).ErrorContext(ctx, "Unexpected error retrieving relationship")
 			return nil, fmt.Errorf("unexpected error retrieving relationship")
 		}
+		return rel.(neo4j.Relationship), nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Convert relationship properties to map with string values
-		relationship := rel.(neo4j.Relationship)
-		updatedRelationship := make(map[string]interface{})
-		for key, value := range relationship.Props {
-			if key == "Created" || key == "Terminated" {
-				if timeValue, ok := value.(time.Time); ok {
-					updatedRelationship[key] = timeValue.Format(time.RFC3339)
-				} else {
-					updatedRelationship[key] = fmt.Sprintf("%v", value)
-				}
+	// Convert relationship properties to map with string values
+	relationship := written.(neo4j.Relationship)
+	updatedRelationship := make(map[string]interface{})
+	for key, value := range relationship.Props {
+		if key == "Created" || key == "Terminated" {
+			if timeValue, ok := value.(time.Time); ok {
+				updatedRelationship[key] = timeValue.Format(time.RFC3339)
 			} else {
 				updatedRelationship[key] = fmt.Sprintf("%v", value)
 			}
+		} else {
+			updatedRelationship[key] = fmt.Sprintf("%v", value)
 		}
-
-		return updatedRelationship, nil
 	}
+	updatedRelationship["properties"] = userRelationshipProperties(relationship.Props)
 
-	return nil, fmt.Errorf("failed to retrieve updated relationship")
+	return updatedRelationship, nil
 }
 
 func (r *Neo4jRepository) DeleteRelationship(ctx context.Context, relationshipID string) error {
@@ -757,13 +978,13 @@ func (r *Neo4jRepository) DeleteRelationship(ctx context.Context, relationshipID
 	query := `MATCH ()-[r {Id: $relationshipID}]->() RETURN r`
 	result, err := session.Run(ctx, query, params)
 	if err != nil {
-		log.Printf("[neo4j_client.DeleteRelationship] error checking if relationship exists: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error checking if relationship exists", "error", err)
 		return fmt.Errorf("error checking if relationship exists: %v", err)
 	}
 
 	// If no relationship is found, return an error
 	if !result.Next(ctx) {
-		log.Printf("[neo4j_client.DeleteRelationship] relationship with Id %s does not exist", relationshipID)
+		logging.FromContext(ctx).WarnContext(ctx, "Relationship does not exist", "relationship_id", relationshipID)
 		return fmt.Errorf("relationship with Id %s does not exist", relationshipID)
 	}
 
@@ -771,7 +992,7 @@ func (r *Neo4jRepository) DeleteRelationship(ctx context.Context, relationshipID
 	deleteQuery := `MATCH ()-[r {Id: $relationshipID}]->() DELETE r`
 	_, err = session.Run(ctx, deleteQuery, params)
 	if err != nil {
-		log.Printf("[neo4j_client.DeleteRelationship] error deleting relationship: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error deleting relationship", "error", err)
 		return fmt.Errorf("error deleting relationship: %v", err)
 	}
 
@@ -781,7 +1002,7 @@ func (r *Neo4jRepository) DeleteRelationship(ctx context.Context, relationshipID
 // DeleteGraphEntity deletes an entity by its ID
 func (r *Neo4jRepository) DeleteGraphEntity(ctx context.Context, entityID string) error {
 	if entityID == "" {
-		log.Printf("[neo4j_client.DeleteGraphEntity] entity Id cannot be empty")
+		logging.FromContext(ctx).WarnContext(ctx, "Entity ID is required")
 		return fmt.Errorf("entity Id cannot be empty")
 	}
 
@@ -795,25 +1016,25 @@ func (r *Neo4jRepository) DeleteGraphEntity(ctx context.Context, entityID string
 
 	result, err := session.Run(ctx, query, params)
 	if err != nil {
-		log.Printf("[neo4j_client.DeleteGraphEntity] error checking if entity exists: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error checking if entity exists", "error", err)
 		return fmt.Errorf("error checking if entity exists: %v", err)
 	}
 
 	if !result.Next(ctx) {
-		log.Printf("[neo4j_client.DeleteGraphEntity] entity with Id %s does not exist", entityID)
+		logging.FromContext(ctx).WarnContext(ctx, "Entity does not exist", "entity_id", entityID)
 		return fmt.Errorf("entity with Id %s does not exist", entityID)
 	}
 
 	// Get the relationships of the entity
 	relationships, err := r.ReadRelationships(ctx, entityID)
 	if err != nil {
-		log.Printf("[neo4j_client.DeleteGraphEntity] error getting relationships: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error getting relationships", "error", err)
 		return fmt.Errorf("error getting relationships: %v", err)
 	}
 
 	// If there are relationships, return an error with relationship details
 	if len(relationships) > 0 {
-		log.Printf("[neo4j_client.DeleteGraphEntity] entity has relationships and cannot be deleted. Relationships: %v", relationships)
+		logging.FromContext(ctx).WarnContext(ctx, "Entity has relationships and cannot be deleted", "entity_id", entityID, "relationships", len(relationships))
 		return fmt.Errorf("entity has relationships and cannot be deleted. Relationships: %v", relationships)
 	}
 
@@ -821,7 +1042,7 @@ func (r *Neo4jRepository) DeleteGraphEntity(ctx context.Context, entityID string
 	deleteQuery := `MATCH (e {Id: $entityID}) DELETE e`
 	_, err = session.Run(ctx, deleteQuery, params)
 	if err != nil {
-		log.Printf("[neo4j_client.DeleteGraphEntity] error deleting entity: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error deleting entity", "error", err)
 		return fmt.Errorf("error deleting entity: %v", err)
 	}
 
@@ -829,71 +1050,19 @@ func (r *Neo4jRepository) DeleteGraphEntity(ctx context.Context, entityID string
 }
 
 func (r *Neo4jRepository) FilterEntities(ctx context.Context, kind *pb.Kind, filters map[string]interface{}) ([]map[string]interface{}, error) {
+	query, params, err := filterEntitiesQuery(kind, filters)
+	if err != nil {
+		return nil, err
+	}
+
 	// Open a session
 	session := r.getSession(ctx)
 	defer session.Close(ctx)
 
-	var query string
-	var params map[string]interface{}
-
-	// If we have an ID filter, use a simpler query
-	if id, ok := filters["id"].(string); ok && id != "" {
-		query = `
-			MATCH (e {Id: $id})
-			RETURN e.Id AS id, labels(e)[0] AS kind, 
-				   toString(e.Created) AS created, 
-				   CASE WHEN e.Terminated IS NOT NULL THEN toString(e.Terminated) ELSE NULL END AS terminated, 
-				   e.Name AS name, 
-				   e.MinorKind AS minorKind
-		`
-		params = map[string]interface{}{
-			"id": id,
-		}
-	} else {
-		// Original query for other filters
-		if kind == nil || kind.Major == "" {
-			return nil, fmt.Errorf("kind.Major is required")
-		}
-
-		// Start building the Cypher query
-		query = `MATCH (e:` + kind.Major + `) WHERE 1=1 ` // Use kind.Major as the label
-		params = map[string]interface{}{}
-
-		// Add MinorKind filter if provided
-		if kind.Minor != "" {
-			query += `AND e.MinorKind = $minorKind `
-			params["minorKind"] = kind.Minor
-		}
-
-		// Add optional filters
-		if created, ok := filters["created"].(string); ok && created != "" {
-			query += `AND e.Created = datetime($created) `
-			params["created"] = created
-		}
-		if terminated, ok := filters["terminated"].(string); ok && terminated != "" {
-			query += `AND e.Terminated = datetime($terminated) `
-			params["terminated"] = terminated
-		}
-
-		if name, ok := filters["name"].(string); ok && name != "" {
-			query += `AND e.Name = $name `
-			params["name"] = name
-		}
-
-		// Return the matched entities
-		query += `
-			RETURN e.Id AS id, labels(e)[0] AS kind, 
-				   toString(e.Created) AS created, 
-				   CASE WHEN e.Terminated IS NOT NULL THEN toString(e.Terminated) ELSE NULL END AS terminated, 
-				   e.Name AS name, 
-				   e.MinorKind AS minorKind
-		`
-	}
-
 	// Run the query
 	result, err := session.Run(ctx, query, params)
 	if err != nil {
-		log.Printf("[neo4j_client.FilterEntities] error querying entities: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying entities", "error", err)
 		return nil, fmt.Errorf("error querying entities: %v", err)
 	}
 
@@ -904,7 +1073,7 @@ func (r *Neo4jRepository) FilterEntities(ctx context.Context, kind *pb.Kind, fil
 
 		entity := map[string]interface{}{
 			"id":         record.Values[0], // e.Id
-			"kind":       record.Values[1], // labels(e)[0]
+			"kind":       record.Values[1], // major kind
 			"created":    record.Values[2], // e.Created
 			"terminated": record.Values[3], // e.Terminated
 			"name":       record.Values[4], // e.Name
@@ -916,7 +1085,7 @@ func (r *Neo4jRepository) FilterEntities(ctx context.Context, kind *pb.Kind, fil
 
 	// Check for errors during iteration
 	if err := result.Err(); err != nil {
-		log.Printf("[neo4j_client.FilterEntities] error iterating over query results: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error iterating over query results", "error", err)
 		return nil, fmt.Errorf("error iterating over query results: %v", err)
 	}
 
@@ -994,6 +1163,20 @@ func (r *Neo4jRepository) ReadFilteredRelationships(ctx context.Context, entityI
 		paramIndex++
 	}
 
+	// Add relationship property filters, each property must match exactly
+	if properties, ok := relationshipFilters["properties"].(map[string]interface{}); ok {
+		for key, value := range properties {
+			keyParamName := fmt.Sprintf("propertyKey%d", paramIndex)
+			valueParamName := fmt.Sprintf("propertyValue%d", paramIndex)
+			params[keyParamName] = key
+			params[valueParamName] = value
+			propertyCondition := fmt.Sprintf(` AND r[$%s] = $%s`, keyParamName, valueParamName)
+			outgoingQuery += propertyCondition
+			incomingQuery += propertyCondition
+			paramIndex++
+		}
+	}
+
 	// Add activeAt filter if provided
 	if activeAt != "" {
 		paramName := fmt.Sprintf("activeAt%d", paramIndex)
@@ -1009,14 +1192,14 @@ func (r *Neo4jRepository) ReadFilteredRelationships(ctx context.Context, entityI
 		RETURN r.Id AS relationshipID, type(r) AS name, related.Id AS relatedEntityId, 
 		       toString(r.Created) AS startTime, 
 		       CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS endTime,
-		       "OUTGOING" AS direction
+		       "OUTGOING" AS direction, properties(r) AS properties
 	`
 
 	incomingQuery += `
 		RETURN r.Id AS relationshipID, type(r) AS name, related.Id AS relatedEntityId, 
 		       toString(r.Created) AS startTime, 
 		       CASE WHEN r.Terminated IS NOT NULL THEN toString(r.Terminated) ELSE NULL END AS endTime,
-		       "INCOMING" AS direction
+		       "INCOMING" AS direction, properties(r) AS properties
 	`
 
 	// Determine which queries to run based on direction filter
@@ -1041,7 +1224,7 @@ func (r *Neo4jRepository) ReadFilteredRelationships(ctx context.Context, entityI
 	// Execute the query
 	result, err := session.Run(ctx, finalQuery, params)
 	if err != nil {
-		log.Printf("[neo4j_client.ReadFilteredRelationships] error querying relationships: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error querying relationships", "error", err)
 		return nil, fmt.Errorf("error querying relationships: %v", err)
 	}
 
@@ -1057,6 +1240,7 @@ func (r *Neo4jRepository) ReadFilteredRelationships(ctx context.Context, entityI
 		startTime, _ := record.Get("startTime")
 		endTime, _ := record.Get("endTime")
 		direction, _ := record.Get("direction")
+		properties, _ := record.Get("properties")
 
 		// Ensure the relationship ID exists
 		if relationshipID == nil {
@@ -1089,12 +1273,15 @@ func (r *Neo4jRepository) ReadFilteredRelationships(ctx context.Context, entityI
 			"endTime":         formattedEndTime,
 			"direction":       fmt.Sprintf("%v", direction),
 		}
+		if props, ok := properties.(map[string]interface{}); ok {
+			relationship["properties"] = userRelationshipProperties(props)
+		}
 
 		relationships = append(relationships, relationship)
 	}
 
 	if err := result.Err(); err != nil {
-		log.Printf("[neo4j_client.ReadFilteredRelationships] error iterating over query result: %v", err)
+		logging.FromContext(ctx).ErrorContext(ctx, "Error iterating over query result", "error", err)
 		return nil, fmt.Errorf("error iterating over query result: %v", err)
 	}
 
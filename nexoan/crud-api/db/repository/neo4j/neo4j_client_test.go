@@ -2,14 +2,21 @@ package neo4jrepository
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"testing"
 
 	"lk/datafoundation/crud-api/db/config"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/relationships"
+	"lk/datafoundation/crud-api/pkg/search"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 var repository *Neo4jRepository
@@ -67,6 +74,28 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// TestTLSURI tests the scheme selected for each TLS mode
+func TestTLSURI(t *testing.T) {
+	for mode, expected := range map[string]string{
+		"":            "neo4j://localhost:7687",
+		"disable":     "neo4j://localhost:7687",
+		"require":     "neo4j+ssc://localhost:7687",
+		"verify-ca":   "neo4j+ssc://localhost:7687",
+		"verify-full": "neo4j+s://localhost:7687",
+	} {
+		uri, err := tlsURI("neo4j://localhost:7687", mode)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, uri, mode)
+	}
+
+	uri, err := tlsURI("bolt://db.internal:7687", "verify-full")
+	assert.NoError(t, err)
+	assert.Equal(t, "bolt+s://db.internal:7687", uri)
+
+	_, err = tlsURI("neo4j+s://localhost:7687", "verify-ca")
+	assert.Error(t, err)
+}
+
 // TestCreateEntity tests the CreateGraphEntity method of the Neo4jRepository
 func TestCreateEntity(t *testing.T) {
 	ctx := context.Background()
@@ -1137,3 +1166,460 @@ func TestReadFilteredRelationships(t *testing.T) {
 	assert.Equal(t, 1, len(rels), "Expected 1 FRIEND relationship that is OUTGOING and active at 2025-04-15T00:00:00Z")
 	assert.Equal(t, "rel1", rels[0]["id"])
 }
+
+// TestRelationshipProperties tests storing, reading, filtering and updating relationship properties
+func TestRelationshipProperties(t *testing.T) {
+	ctx := context.Background()
+
+	kind := &pb.Kind{
+		Major: "Person",
+		Minor: "Minister",
+	}
+
+	entity1 := map[string]interface{}{
+		"Id":      "rel_props_entity_1",
+		"Name":    "Ministry",
+		"Created": "2025-01-01T00:00:00Z",
+	}
+	entity2 := map[string]interface{}{
+		"Id":      "rel_props_entity_2",
+		"Name":    "Minister",
+		"Created": "2025-01-01T00:00:00Z",
+	}
+	_, err := repository.CreateGraphEntity(ctx, kind, entity1)
+	assert.Nil(t, err, "Expected no error when creating entity 1")
+	_, err = repository.CreateGraphEntity(ctx, kind, entity2)
+	assert.Nil(t, err, "Expected no error when creating entity 2")
+
+	roleTitle, _ := anypb.New(wrapperspb.String("Cabinet Minister"))
+	appointmentOrder, _ := anypb.New(wrapperspb.Int64(42))
+	relationship := &pb.Relationship{
+		Id:              "rel_props_test",
+		Name:            "HEAD_OF",
+		RelatedEntityId: "rel_props_entity_2",
+		StartTime:       "2025-01-01T00:00:00Z",
+		Properties: map[string]*anypb.Any{
+			"roleTitle":        roleTitle,
+			"appointmentOrder": appointmentOrder,
+		},
+	}
+	createdRel, err := repository.CreateRelationship(ctx, "rel_props_entity_1", relationship)
+	assert.Nil(t, err, "Expected no error when creating relationship with properties")
+	createdProps, ok := createdRel["properties"].(map[string]interface{})
+	assert.True(t, ok, "Expected created relationship to contain properties")
+	assert.Equal(t, "Cabinet Minister", createdProps["roleTitle"])
+	assert.Equal(t, int64(42), createdProps["appointmentOrder"])
+
+	// Reserved property names cannot be used
+	reserved, _ := anypb.New(wrapperspb.String("2030-01-01T00:00:00Z"))
+	_, err = repository.CreateRelationship(ctx, "rel_props_entity_1", &pb.Relationship{
+		Id:              "rel_props_reserved",
+		Name:            "HEAD_OF",
+		RelatedEntityId: "rel_props_entity_2",
+		StartTime:       "2025-01-01T00:00:00Z",
+		Properties:      map[string]*anypb.Any{"Created": reserved},
+	})
+	assert.NotNil(t, err, "Expected error when using a reserved property name")
+
+	// Properties are returned by GetFilteredRelationships
+	rels, err := repository.GetFilteredRelationships(ctx, "rel_props_entity_1", "rel_props_test", "", "", "", "", "", nil, "")
+	assert.Nil(t, err, "Expected no error when reading relationships")
+	assert.Equal(t, 1, len(rels), "Expected exactly one relationship")
+	var title wrapperspb.StringValue
+	assert.Nil(t, rels["rel_props_test"].Properties["roleTitle"].UnmarshalTo(&title))
+	assert.Equal(t, "Cabinet Minister", title.Value)
+
+	// Properties can be used as filters
+	rels, err = repository.GetFilteredRelationships(ctx, "rel_props_entity_1", "", "HEAD_OF", "", "", "", "", map[string]*anypb.Any{"roleTitle": roleTitle}, "")
+	assert.Nil(t, err, "Expected no error when filtering by properties")
+	assert.Equal(t, 1, len(rels), "Expected one relationship matching the property filter")
+
+	otherTitle, _ := anypb.New(wrapperspb.String("State Minister"))
+	rels, err = repository.GetFilteredRelationships(ctx, "rel_props_entity_1", "", "HEAD_OF", "", "", "", "", map[string]*anypb.Any{"roleTitle": otherTitle}, "")
+	assert.Nil(t, err, "Expected no error when filtering by properties")
+	assert.Equal(t, 0, len(rels), "Expected no relationship matching a different property value")
+
+	// Updating properties merges them with the existing ones
+	updatedRel, err := repository.UpdateRelationship(ctx, "rel_props_test", map[string]interface{}{
+		"Properties": map[string]interface{}{"sourceDocument": "gazette-2025-01"},
+	})
+	assert.Nil(t, err, "Expected no error when updating relationship properties")
+	updatedProps := updatedRel["properties"].(map[string]interface{})
+	assert.Equal(t, "gazette-2025-01", updatedProps["sourceDocument"])
+	assert.Equal(t, "Cabinet Minister", updatedProps["roleTitle"])
+
+	fetchedRel, err := repository.ReadRelationship(ctx, "rel_props_test")
+	assert.Nil(t, err, "Expected no error when reading relationship")
+	fetchedProps := fetchedRel["properties"].(map[string]interface{})
+	assert.Equal(t, 3, len(fetchedProps), "Expected three user properties on the relationship")
+}
+
+func TestRelationshipIntegrityRules(t *testing.T) {
+	ctx := context.Background()
+
+	// Share the driver with the test repository but enable the integrity rules
+	rulesRepo := &Neo4jRepository{
+		client: repository.client,
+		config: &config.Neo4jConfig{
+			RelationshipIntegrity: config.RelationshipIntegrityConfig{
+				EnforceEndpointLifetimes: true,
+				NonOverlappingTypes:      []string{"AS_MINISTER"},
+				SingleValuedTypes:        []string{"PRIME_MINISTER_OF"},
+			},
+		},
+	}
+
+	kind := &pb.Kind{
+		Major: "Person",
+		Minor: "Minister",
+	}
+	entities := []map[string]interface{}{
+		{"Id": "integrity_person", "Name": "Person", "Created": "2020-01-01T00:00:00Z"},
+		{"Id": "integrity_ministry_1", "Name": "Ministry 1", "Created": "2020-01-01T00:00:00Z", "Terminated": "2024-01-01T00:00:00Z"},
+		{"Id": "integrity_ministry_2", "Name": "Ministry 2", "Created": "2020-01-01T00:00:00Z"},
+	}
+	for _, entity := range entities {
+		_, err := rulesRepo.CreateGraphEntity(ctx, kind, entity)
+		assert.Nil(t, err, "Expected no error when creating entity %v", entity["Id"])
+	}
+
+	var integrityErr *relationships.IntegrityError
+
+	// A relationship cannot start before its endpoints exist
+	_, err := rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_before_created",
+		Name:            "MEMBER_OF",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2019-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for a relationship starting before its endpoint")
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	// An open ended relationship cannot point at a terminated entity
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_after_terminated",
+		Name:            "MEMBER_OF",
+		RelatedEntityId: "integrity_ministry_1",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for a relationship outliving its endpoint")
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	// Non-overlapping relationships between the same entities
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_minister_1",
+		Name:            "AS_MINISTER",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2021-01-01T00:00:00Z",
+		EndTime:         "2022-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating the first AS_MINISTER relationship")
+
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_minister_2",
+		Name:            "AS_MINISTER",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2021-06-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for overlapping AS_MINISTER relationships")
+	assert.Equal(t, relationships.NonOverlappingRule, integrityErr.Rule)
+	assert.Contains(t, integrityErr.Message, "integrity_minister_1")
+
+	// Adjacent intervals do not overlap
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_minister_2",
+		Name:            "AS_MINISTER",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2022-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating an adjacent AS_MINISTER relationship")
+
+	// Extending the first relationship into the second one is rejected
+	_, err = rulesRepo.UpdateRelationship(ctx, "integrity_minister_1", map[string]interface{}{
+		"Terminated": "2023-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error when extending into an existing relationship")
+	assert.Equal(t, relationships.NonOverlappingRule, integrityErr.Rule)
+
+	// Single-valued relationships apply across all target entities
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_pm_1",
+		Name:            "PRIME_MINISTER_OF",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating the first PRIME_MINISTER_OF relationship")
+
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_pm_2",
+		Name:            "PRIME_MINISTER_OF",
+		RelatedEntityId: "integrity_ministry_1",
+		StartTime:       "2022-01-01T00:00:00Z",
+		EndTime:         "2023-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for a second active PRIME_MINISTER_OF relationship")
+	assert.Equal(t, relationships.SingleValuedRule, integrityErr.Rule)
+
+	// Relationship types without rules are not checked for overlaps
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_member_1",
+		Name:            "MEMBER_OF",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating the first MEMBER_OF relationship")
+	_, err = rulesRepo.CreateRelationship(ctx, "integrity_person", &pb.Relationship{
+		Id:              "integrity_member_2",
+		Name:            "MEMBER_OF",
+		RelatedEntityId: "integrity_ministry_2",
+		StartTime:       "2021-06-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating an overlapping MEMBER_OF relationship")
+}
+
+func TestRelationshipTypeRegistry(t *testing.T) {
+	ctx := context.Background()
+
+	registryRepo := &Neo4jRepository{
+		client:            repository.client,
+		config:            &config.Neo4jConfig{},
+		relationshipTypes: relationships.NewTypeRegistry(),
+	}
+	err := registryRepo.RelationshipTypes().Load([]*pb.RelationshipType{
+		{
+			Name:        "REGISTRY_HEAD_OF",
+			SourceKinds: []*pb.Kind{{Major: "Person"}},
+			TargetKinds: []*pb.Kind{{Major: "Organisation", Minor: "Ministry"}},
+			Cardinality: pb.Cardinality_ONE_TO_MANY,
+		},
+	})
+	assert.Nil(t, err, "Expected no error when loading relationship types")
+
+	entities := []*pb.Entity{
+		{Id: "registry_person_1", Kind: &pb.Kind{Major: "Person", Minor: "Minister"}},
+		{Id: "registry_person_2", Kind: &pb.Kind{Major: "Person", Minor: "Minister"}},
+		{Id: "registry_ministry", Kind: &pb.Kind{Major: "Organisation", Minor: "Ministry"}},
+		{Id: "registry_department", Kind: &pb.Kind{Major: "Organisation", Minor: "Department"}},
+	}
+	for _, entity := range entities {
+		_, err := registryRepo.CreateGraphEntity(ctx, entity.Kind, map[string]interface{}{
+			"Id":      entity.Id,
+			"Name":    entity.Id,
+			"Created": "2020-01-01T00:00:00Z",
+		})
+		assert.Nil(t, err, "Expected no error when creating entity %s", entity.Id)
+	}
+
+	var integrityErr *relationships.IntegrityError
+	createRelationships := func(entityID string, rel *pb.Relationship) error {
+		return registryRepo.HandleGraphRelationshipsCreate(ctx, &pb.Entity{
+			Id:            entityID,
+			Relationships: map[string]*pb.Relationship{rel.Id: rel},
+		})
+	}
+
+	// Undeclared relationship names are rejected
+	err = createRelationships("registry_person_1", &pb.Relationship{
+		Id:              "registry_undeclared",
+		Name:            "REGISTRY_UNKNOWN",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for an undeclared relationship type")
+	assert.Equal(t, relationships.TypeRule, integrityErr.Rule)
+
+	// The target kind must match the declared minor kind
+	err = createRelationships("registry_person_1", &pb.Relationship{
+		Id:              "registry_wrong_target",
+		Name:            "REGISTRY_HEAD_OF",
+		RelatedEntityId: "registry_department",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for a disallowed target kind")
+	assert.Equal(t, relationships.TypeRule, integrityErr.Rule)
+
+	err = createRelationships("registry_person_1", &pb.Relationship{
+		Id:              "registry_head_1",
+		Name:            "REGISTRY_HEAD_OF",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2021-01-01T00:00:00Z",
+		EndTime:         "2022-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating a declared relationship")
+
+	// ONE_TO_MANY allows a single active source per target
+	err = createRelationships("registry_person_2", &pb.Relationship{
+		Id:              "registry_head_2",
+		Name:            "REGISTRY_HEAD_OF",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2021-06-01T00:00:00Z",
+	})
+	assert.True(t, errors.As(err, &integrityErr), "Expected an integrity error for a second active source")
+	assert.Equal(t, relationships.CardinalityRule, integrityErr.Rule)
+
+	err = createRelationships("registry_person_2", &pb.Relationship{
+		Id:              "registry_head_2",
+		Name:            "REGISTRY_HEAD_OF",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2022-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when the previous relationship has ended")
+
+	// Exempt names are only allowed for internal writes, without being declared
+	registryRepo.RelationshipTypes().Exempt("REGISTRY_INTERNAL")
+	err = createRelationships("registry_department", &pb.Relationship{
+		Id:              "registry_internal",
+		Name:            "REGISTRY_INTERNAL",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	var reservedErr *relationships.ReservedNameError
+	assert.True(t, errors.As(err, &reservedErr), "Expected a reserved name error for a client write")
+	ctx = relationships.WithInternalWrite(ctx)
+	err = createRelationships("registry_department", &pb.Relationship{
+		Id:              "registry_internal",
+		Name:            "REGISTRY_INTERNAL",
+		RelatedEntityId: "registry_ministry",
+		StartTime:       "2021-01-01T00:00:00Z",
+	})
+	assert.Nil(t, err, "Expected no error when creating an exempt relationship")
+
+	listed := registryRepo.RelationshipTypes().List()
+	assert.Equal(t, 1, len(listed))
+	assert.Equal(t, "REGISTRY_HEAD_OF", listed[0].Name)
+}
+
+func TestReadRelationshipsByEndpoints(t *testing.T) {
+	ctx := context.Background()
+
+	kind := &pb.Kind{
+		Major: "Person",
+		Minor: "Minister",
+	}
+	for _, id := range []string{"endpoints_a", "endpoints_b", "endpoints_c"} {
+		_, err := repository.CreateGraphEntity(ctx, kind, map[string]interface{}{
+			"Id":      id,
+			"Name":    id,
+			"Created": "2020-01-01T00:00:00Z",
+		})
+		assert.Nil(t, err, "Expected no error when creating entity %s", id)
+	}
+
+	fixtures := []struct {
+		source string
+		rel    *pb.Relationship
+	}{
+		{"endpoints_a", &pb.Relationship{Id: "endpoints_ab", Name: "REPORTS_TO", RelatedEntityId: "endpoints_b", StartTime: "2021-01-01T00:00:00Z", EndTime: "2022-01-01T00:00:00Z"}},
+		{"endpoints_a", &pb.Relationship{Id: "endpoints_ac", Name: "ADVISES", RelatedEntityId: "endpoints_c", StartTime: "2021-01-01T00:00:00Z"}},
+		{"endpoints_c", &pb.Relationship{Id: "endpoints_cb", Name: "REPORTS_TO", RelatedEntityId: "endpoints_b", StartTime: "2022-01-01T00:00:00Z"}},
+	}
+	for _, r := range fixtures {
+		_, err := repository.CreateRelationship(ctx, r.source, r.rel)
+		assert.Nil(t, err, "Expected no error when creating relationship %s", r.rel.Id)
+	}
+
+	// Filter by source entity
+	rels, err := repository.ReadRelationshipsByEndpoints(ctx, "endpoints_a", "", "", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rels))
+
+	// Filter by target entity and name
+	rels, err = repository.ReadRelationshipsByEndpoints(ctx, "", "endpoints_b", "REPORTS_TO", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rels))
+
+	// Filter by target entity at a point in time
+	rels, err = repository.ReadRelationshipsByEndpoints(ctx, "", "endpoints_b", "", "2023-01-01T00:00:00Z")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(rels))
+	assert.Equal(t, "endpoints_cb", rels[0]["relationshipID"])
+	assert.Equal(t, "endpoints_c", rels[0]["startEntityID"])
+
+	// At least one endpoint is required
+	_, err = repository.ReadRelationshipsByEndpoints(ctx, "", "", "REPORTS_TO", "")
+	assert.NotNil(t, err)
+
+	// Relationships can be read with their source entity
+	entityRel, err := repository.GetGraphRelationship(ctx, "endpoints_ab")
+	assert.Nil(t, err)
+	assert.Equal(t, "endpoints_a", entityRel.EntityId)
+	assert.Equal(t, "endpoints_b", entityRel.Relationship.RelatedEntityId)
+	assert.NotEmpty(t, entityRel.Relationship.EndTime)
+
+	var notFoundErr *relationships.NotFoundError
+	_, err = repository.GetGraphRelationship(ctx, "endpoints_missing")
+	assert.True(t, errors.As(err, &notFoundErr), "Expected a NotFoundError")
+}
+
+// TestSearchEntityNames tests that the name index covers the kinds created after it and ignores case and accents
+func TestSearchEntityNames(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, repository.CreateSearchIndexes(ctx))
+
+	_, err := repository.CreateGraphEntity(ctx, &pb.Kind{Major: "SearchOrganisation", Minor: "Ministry"}, map[string]interface{}{
+		"Id":      "search_defence",
+		"Name":    "Ministry of Défence",
+		"Created": "2025-03-18T00:00:00Z",
+	})
+	assert.Nil(t, err)
+	_, err = repository.CreateGraphEntity(ctx, &pb.Kind{Major: "SearchPerson", Minor: "Minister"}, map[string]interface{}{
+		"Id":      "search_spokesperson",
+		"Name":    "Defence Ministry Spokesperson",
+		"Created": "2025-03-18T00:00:00Z",
+	})
+	assert.Nil(t, err)
+
+	query, err := search.NewQuery("DEFENCE ministry", []*pb.Kind{{Major: "SearchOrganisation"}})
+	assert.Nil(t, err)
+	matches, err := repository.SearchEntityNames(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "search_defence", Field: "name", Text: "Ministry of Défence"}}, matches)
+
+	// The label shared by searchable entities is not read as their kind and cannot be a kind
+	entity, err := repository.ReadGraphEntity(ctx, "search_defence")
+	assert.Nil(t, err)
+	assert.Equal(t, "SearchOrganisation", entity["MajorKind"])
+	_, err = repository.CreateGraphEntity(ctx, &pb.Kind{Major: searchableLabel, Minor: "Ministry"}, map[string]interface{}{
+		"Id":      "search_reserved",
+		"Name":    "Reserved",
+		"Created": "2025-03-18T00:00:00Z",
+	})
+	var invalidErr *cypher.InvalidIdentifierError
+	assert.True(t, errors.As(err, &invalidErr), "Expected an InvalidIdentifierError")
+}
+
+func TestIdConstraints(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, repository.CreateIndexes(ctx))
+
+	readConstraints := func() []string {
+		session := repository.getSession(ctx)
+		defer session.Close(ctx)
+		result, err := session.Run(ctx, `SHOW CONSTRAINTS YIELD name WHERE name STARTS WITH 'entity_id_Constrained' RETURN name ORDER BY name`, nil)
+		assert.Nil(t, err)
+		var names []string
+		for result.Next(ctx) {
+			names = append(names, fmt.Sprintf("%v", result.Record().Values[0]))
+		}
+		return names
+	}
+
+	// A new kind gets its constraint with its first entity
+	_, err := repository.CreateGraphEntity(ctx, &pb.Kind{Major: "ConstrainedKind", Minor: "Test"}, map[string]interface{}{
+		"Id":      "constrained_1",
+		"Name":    "Constrained",
+		"Created": "2025-03-18T00:00:00Z",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"entity_id_ConstrainedKind"}, readConstraints())
+
+	// The constraint rejects a second node of the kind with the same Id
+	session := repository.getSession(ctx)
+	defer session.Close(ctx)
+	err = runStatement(ctx, session, "CREATE (:ConstrainedKind {Id: $Id})", map[string]interface{}{"Id": "constrained_1"})
+	assert.NotNil(t, err)
+
+	// Startup finds the constraint already in place
+	assert.Nil(t, repository.CreateIndexes(ctx))
+	assert.Equal(t, []string{"entity_id_ConstrainedKind"}, readConstraints())
+}
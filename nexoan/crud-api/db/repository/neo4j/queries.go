@@ -0,0 +1,108 @@
+package neo4jrepository
+
+import (
+	"fmt"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+)
+
+// The queries below take kinds and relationship types already quoted by quoteKind or cypher.QuoteIdentifier,
+// every other value is passed as a parameter.
+
+// entityExistsQuery returns the query finding the entity of a kind with the Id parameter
+func entityExistsQuery(label string) string {
+	return `MATCH (e:` + label + ` {Id: $Id}) RETURN e`
+}
+
+// createEntityQuery returns the statement creating an entity of a kind from the Id, Name, Created and
+// MinorKind parameters, and from the Terminated parameter when the entity is terminated
+func createEntityQuery(label string, terminated bool) string {
+	createQuery := `CREATE (e:` + label + `:` + searchableLabel + ` {Id: $Id, Name: $Name, Created: datetime($Created), MinorKind: $MinorKind`
+	if terminated {
+		createQuery += `, Terminated: datetime($Terminated)`
+	}
+	return createQuery + `}) RETURN e`
+}
+
+// createRelationshipQuery returns the statement creating a relationship of a type between the parentID and
+// childID entities, and from the endDate parameter when the relationship is terminated
+func createRelationshipQuery(relType string, terminated bool) string {
+	createQuery := `MATCH (p {Id: $parentID}), (c {Id: $childID})
+					CREATE (p)-[r:` + relType + ` {Id: $relationshipID, Created: datetime($startDate)`
+	if terminated {
+		createQuery += `, Terminated: datetime($endDate)`
+	}
+	return createQuery + `}]->(c) SET r += $properties RETURN r`
+}
+
+// relatedEntitiesQuery returns the query reading the relationships of a type leaving the entityID entity that
+// are active at the ts parameter
+func relatedEntitiesQuery(relType string) string {
+	return fmt.Sprintf(`
+        MATCH (e {Id: $entityID})-[r:%s]->(related)
+        WHERE r.Created <= datetime($ts) AND (r.Terminated IS NULL OR r.Terminated > datetime($ts))
+        RETURN r.Id AS relationshipID, r.Created AS startTime, r.Terminated AS endTime, type(r) AS name, related.Id AS relatedEntityId
+    `, relType)
+}
+
+// filterEntitiesQuery returns the query finding the entities matching a kind and filters, and its parameters.
+// An id filter finds the entity with that Id whatever its kind.
+func filterEntitiesQuery(kind *pb.Kind, filters map[string]interface{}) (string, map[string]interface{}, error) {
+	// If we have an ID filter, use a simpler query
+	if id, ok := filters["id"].(string); ok && id != "" {
+		query := `
+			MATCH (e {Id: $id})
+			RETURN e.Id AS id, ` + majorKindOf("e") + ` AS kind,
+				   toString(e.Created) AS created,
+				   CASE WHEN e.Terminated IS NOT NULL THEN toString(e.Terminated) ELSE NULL END AS terminated,
+				   e.Name AS name,
+				   e.MinorKind AS minorKind
+		`
+		return query, map[string]interface{}{"id": id}, nil
+	}
+
+	// Original query for other filters
+	if kind == nil || kind.Major == "" {
+		return "", nil, fmt.Errorf("kind.Major is required")
+	}
+
+	label, err := quoteKind(kind.Major)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Start building the Cypher query
+	query := `MATCH (e:` + label + `) WHERE 1=1 ` // Use kind.Major as the label
+	params := map[string]interface{}{}
+
+	// Add MinorKind filter if provided
+	if kind.Minor != "" {
+		query += `AND e.MinorKind = $minorKind `
+		params["minorKind"] = kind.Minor
+	}
+
+	// Add optional filters
+	if created, ok := filters["created"].(string); ok && created != "" {
+		query += `AND e.Created = datetime($created) `
+		params["created"] = created
+	}
+	if terminated, ok := filters["terminated"].(string); ok && terminated != "" {
+		query += `AND e.Terminated = datetime($terminated) `
+		params["terminated"] = terminated
+	}
+
+	if name, ok := filters["name"].(string); ok && name != "" {
+		query += `AND e.Name = $name `
+		params["name"] = name
+	}
+
+	// Return the matched entities
+	query += `
+			RETURN e.Id AS id, ` + majorKindOf("e") + ` AS kind,
+				   toString(e.Created) AS created,
+				   CASE WHEN e.Terminated IS NOT NULL THEN toString(e.Terminated) ELSE NULL END AS terminated,
+				   e.Name AS name,
+				   e.MinorKind AS minorKind
+		`
+	return query, params, nil
+}
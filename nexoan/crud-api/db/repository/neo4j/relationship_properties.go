@@ -0,0 +1,16 @@
+package neo4jrepository
+
+import (
+	"lk/datafoundation/crud-api/pkg/relationships"
+)
+
+// userRelationshipProperties returns the Neo4j relationship properties excluding the reserved ones
+func userRelationshipProperties(props map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range props {
+		if !relationships.IsReservedProperty(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package neo4jrepository
+
+import (
+	"context"
+	"fmt"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// lockEndpointsClause takes the write locks of the endpoints p and c until the end of the transaction.
+// The property is removed in the same clause, so the nodes are left unchanged.
+const lockEndpointsClause = `SET p._lock = true, c._lock = true REMOVE p._lock, c._lock`
+
+// ruleStore looks up the relationships and entities the integrity rules check against in Neo4j,
+// within the transaction that writes the relationship
+type ruleStore struct {
+	tx neo4j.ManagedTransaction
+}
+
+// validateRelationshipIntegrity checks the relationship against the integrity rules configured for the repository.
+// The endpoints of the relationship must be locked by tx, so concurrent writes cannot pass the checks together.
+func (r *Neo4jRepository) validateRelationshipIntegrity(ctx context.Context, tx neo4j.ManagedTransaction, rel *relationships.Relationship) error {
+	var rules config.RelationshipIntegrityConfig
+	if r.config != nil {
+		rules = r.config.RelationshipIntegrity
+	}
+	return relationships.Validate(ctx, rules, r.relationshipTypes, ruleStore{tx: tx}, rel)
+}
+
+// Overlapping describes the other relationships of the same type selected by sel that overlap rel in time
+func (s ruleStore) Overlapping(ctx context.Context, rel *relationships.Relationship, sel relationships.Selection) ([]string, error) {
+	source, target, arrow := "()", "()", "->"
+	if sel.SourceID != "" {
+		source = "({Id: $sourceID})"
+	}
+	if sel.TargetID != "" {
+		target = "({Id: $targetID})"
+	}
+	if sel.EitherDirection {
+		arrow = "-"
+	}
+	query := `
+		MATCH ` + source + `-[r]` + arrow + target + `
+		WHERE type(r) = $relType AND r.Id <> $relationshipID
+		  AND ($endTime IS NULL OR r.Created < datetime($endTime))
+		  AND (r.Terminated IS NULL OR r.Terminated > datetime($startTime))
+		RETURN DISTINCT r.Id AS id, toString(r.Created) AS created, toString(r.Terminated) AS terminated
+		ORDER BY id
+	`
+	return s.describe(ctx, query, map[string]interface{}{
+		"sourceID":       sel.SourceID,
+		"targetID":       sel.TargetID,
+		"relType":        rel.Type,
+		"relationshipID": rel.ID,
+		"startTime":      rel.Created,
+		"endTime":        nullableTime(rel.Terminated),
+	})
+}
+
+// EndpointsOutside describes the endpoints of rel whose lifetime does not cover the relationship
+func (s ruleStore) EndpointsOutside(ctx context.Context, rel *relationships.Relationship) ([]string, error) {
+	query := `
+		MATCH (e)
+		WHERE e.Id IN [$sourceID, $targetID]
+		  AND (e.Created > datetime($startTime)
+		       OR (e.Terminated IS NOT NULL AND ($endTime IS NULL OR e.Terminated < datetime($endTime))))
+		RETURN e.Id AS id, toString(e.Created) AS created, toString(e.Terminated) AS terminated
+		ORDER BY id
+	`
+	return s.describe(ctx, query, map[string]interface{}{
+		"sourceID":  rel.SourceID,
+		"targetID":  rel.TargetID,
+		"startTime": rel.Created,
+		"endTime":   nullableTime(rel.Terminated),
+	})
+}
+
+// describe runs a query returning id, created and terminated columns and describes every record
+func (s ruleStore) describe(ctx context.Context, query string, params map[string]interface{}) ([]string, error) {
+	result, err := s.tx.Run(ctx, query, params)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error checking relationship conflicts", "error", err)
+		return nil, fmt.Errorf("error checking relationship conflicts: %v", err)
+	}
+
+	var intervals []string
+	for result.Next(ctx) {
+		record := result.Record()
+		id, _ := record.Get("id")
+		created, _ := record.Get("created")
+		terminated, _ := record.Get("terminated")
+		if terminated == nil {
+			terminated = ""
+		}
+		intervals = append(intervals, relationships.DescribeInterval(fmt.Sprintf("%v", id), fmt.Sprintf("%v", created), fmt.Sprintf("%v", terminated)))
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over relationship conflicts: %v", err)
+	}
+	return intervals, nil
+}
+
+// nullableTime passes an empty time as null, an open ended relationship has no end time
+func nullableTime(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// kindFromGraphEntity extracts the kind of an entity returned by ReadGraphEntity
+func kindFromGraphEntity(entity map[string]interface{}) *pb.Kind {
+	major, _ := entity["MajorKind"].(string)
+	minor, _ := entity["MinorKind"].(string)
+	return &pb.Kind{Major: major, Minor: minor}
+}
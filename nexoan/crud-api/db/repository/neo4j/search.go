@@ -0,0 +1,171 @@
+package neo4jrepository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// entityNameIndex is the name of the full-text index over entity names
+const entityNameIndex = "entity_name_search"
+
+// searchableLabel is carried by every entity node besides the label of its kind. The name index covers this
+// label alone, so entities of a new kind are indexed without changing the index. It cannot be used as a kind.
+const searchableLabel = "Searchable"
+
+// searchIndexWaitSeconds is how long index creation waits for the index to be populated
+const searchIndexWaitSeconds = 300
+
+// searchableBatchSize is the number of entities labelled per transaction when existing entities are labelled
+const searchableBatchSize = 10000
+
+// runStatement runs a statement that returns no records and waits for it to complete
+func runStatement(ctx context.Context, session neo4j.SessionWithContext, statement string, params map[string]interface{}) error {
+	result, err := session.Run(ctx, statement, params)
+	if err != nil {
+		return err
+	}
+	_, err = result.Consume(ctx)
+	return err
+}
+
+// quoteKind validates and quotes a major kind for use as a label, searchableLabel is reserved
+func quoteKind(major string) (string, error) {
+	if major == searchableLabel {
+		return "", &cypher.InvalidIdentifierError{Name: major, Reason: "the name is reserved"}
+	}
+	return cypher.QuoteIdentifier(major)
+}
+
+// majorKindOf returns the expression of the major kind of the entity node bound to variable, the label of the
+// node other than searchableLabel
+func majorKindOf(variable string) string {
+	return "[label IN labels(" + variable + ") WHERE label <> '" + searchableLabel + "'][0]"
+}
+
+// searchIndexExists reports whether the name index exists
+func (r *Neo4jRepository) searchIndexExists(ctx context.Context) (bool, error) {
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `SHOW FULLTEXT INDEXES YIELD name WHERE name = $name RETURN name`,
+		map[string]interface{}{"name": entityNameIndex})
+	if err != nil {
+		return false, fmt.Errorf("error reading search index: %v", err)
+	}
+	exists := result.Next(ctx)
+	return exists, result.Err()
+}
+
+// CreateSearchIndexes creates the full-text index over the names of the entities carrying searchableLabel.
+// Entities created before the index existed do not carry the label, so they are labelled first. Every entity
+// created since carries it, so the labelling only runs while the index does not exist.
+func (r *Neo4jRepository) CreateSearchIndexes(ctx context.Context) error {
+	indexed, err := r.searchIndexExists(ctx)
+	if err != nil {
+		return err
+	}
+
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	if !indexed {
+		// Entities are labelled in batches of their own transactions, which needs an implicit transaction
+		labelQuery := "MATCH (n) WHERE n.Id IS NOT NULL AND NOT n:" + searchableLabel + " " +
+			"CALL { WITH n SET n:" + searchableLabel + " } IN TRANSACTIONS OF " + strconv.Itoa(searchableBatchSize) + " ROWS"
+		if err := runStatement(ctx, session, labelQuery, nil); err != nil {
+			return fmt.Errorf("error labelling searchable entities: %v", err)
+		}
+		logging.FromContext(ctx).InfoContext(ctx, "Labelled existing entities for the entity name search index")
+	}
+
+	// The standard-folding analyzer ignores case and accents like the search package does
+	createQuery := "CREATE FULLTEXT INDEX " + entityNameIndex + " IF NOT EXISTS FOR (n:" + searchableLabel + ") ON EACH [n.Name] " +
+		"OPTIONS {indexConfig: {`fulltext.analyzer`: 'standard-folding'}}"
+	if err := runStatement(ctx, session, createQuery, nil); err != nil {
+		return fmt.Errorf("error creating search index: %v", err)
+	}
+	if err := runStatement(ctx, session, "CALL db.awaitIndex($name, $timeout)",
+		map[string]interface{}{"name": entityNameIndex, "timeout": searchIndexWaitSeconds}); err != nil {
+		return fmt.Errorf("error waiting for search index: %v", err)
+	}
+
+	r.searchMu.Lock()
+	r.searchIndexed = true
+	r.searchMu.Unlock()
+	return nil
+}
+
+// SearchEntityNames returns the entities of the query kinds whose name contains every term of the query, best first.
+// The full-text index finds the names holding every term, the kinds are filtered before the matches are limited,
+// and the names are checked with the search package so that every graph store tokenizes names the same way.
+func (r *Neo4jRepository) SearchEntityNames(ctx context.Context, query search.Query) ([]search.Match, error) {
+	r.searchMu.Lock()
+	indexed := r.searchIndexed
+	r.searchMu.Unlock()
+	if !indexed {
+		return nil, nil
+	}
+
+	params := map[string]interface{}{
+		"index": entityNameIndex,
+		// Every term is required, terms are letters and digits so they need no escaping
+		"query": "+" + strings.Join(query.Terms, " +"),
+		"limit": search.MaxMatches,
+	}
+	var conditions []string
+	for i, kind := range query.Kinds {
+		condition := fmt.Sprintf("$major%d IN labels(node)", i)
+		params[fmt.Sprintf("major%d", i)] = kind.GetMajor()
+		if kind.GetMinor() != "" {
+			condition += fmt.Sprintf(" AND node.MinorKind = $minor%d", i)
+			params[fmt.Sprintf("minor%d", i)] = kind.GetMinor()
+		}
+		conditions = append(conditions, "("+condition+")")
+	}
+	cypher := `CALL db.index.fulltext.queryNodes($index, $query) YIELD node, score `
+	if len(conditions) > 0 {
+		cypher += `WHERE ` + strings.Join(conditions, " OR ") + ` `
+	}
+	cypher += `RETURN node.Id AS id, node.Name AS name ORDER BY score DESC, id SKIP $skip LIMIT $limit`
+
+	session := r.getSession(ctx)
+	defer session.Close(ctx)
+
+	// Hits the search package rejects do not count towards the limit, so the hits are read a page at a time
+	// until enough of them match or there are no more
+	var matches []search.Match
+	for skip := 0; len(matches) < search.MaxMatches; skip += search.MaxMatches {
+		params["skip"] = skip
+		result, err := session.Run(ctx, cypher, params)
+		if err != nil {
+			return nil, fmt.Errorf("error searching entity names: %v", err)
+		}
+		hits := 0
+		for result.Next(ctx) {
+			hits++
+			entityID := fmt.Sprintf("%v", result.Record().Values[0])
+			name := fmt.Sprintf("%v", result.Record().Values[1])
+			if query.MatchesText(name) {
+				matches = append(matches, search.Match{EntityID: entityID, Field: search.NameField, Text: name})
+			}
+		}
+		if err := result.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating search results: %v", err)
+		}
+		if hits < search.MaxMatches {
+			break
+		}
+	}
+	if len(matches) > search.MaxMatches {
+		matches = matches[:search.MaxMatches]
+	}
+	return matches, nil
+}
@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	commons "lk/datafoundation/crud-api/commons"
+
+	"github.com/lib/pq"
+)
+
+// maxColumnNameLength is the longest column name PostgreSQL keeps without truncating it
+const maxColumnNameLength = 63
+
+// ValidateColumnName checks that a column name can be stored exactly as given. Column names are quoted instead
+// of being rewritten, so distinct names always get distinct columns and read back unchanged.
+func ValidateColumnName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("column names must not be empty")
+	case len(name) > maxColumnNameLength:
+		return fmt.Errorf("column name %q is longer than %d bytes", name, maxColumnNameLength)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("column name %q is not valid UTF-8", name)
+	case strings.ContainsRune(name, 0):
+		return fmt.Errorf("column name %q contains a NUL character", name)
+	}
+	return nil
+}
+
+// tableColumns returns the names of the columns of a table
+func (repo *PostgresRepository) tableColumns(ctx context.Context, tableName string) (map[string]bool, error) {
+	rows, err := repo.DB().QueryContext(ctx,
+		`SELECT column_name FROM information_schema.columns WHERE table_schema = 'public' AND table_name = $1`,
+		tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error querying columns of %s: %v", tableName, err)
+	}
+	defer rows.Close()
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("error scanning column of %s: %v", tableName, err)
+		}
+		columns[column] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating columns of %s: %v", tableName, err)
+	}
+	return columns, nil
+}
+
+// columnName returns the column of a table holding a field. Tables created before column names were quoted
+// have the columns commons.SanitizeIdentifier made of the field names, which are used when the field has no
+// column of its own.
+func columnName(columns map[string]bool, field string) string {
+	if !columns[field] {
+		if sanitized := commons.SanitizeIdentifier(field); columns[sanitized] {
+			return sanitized
+		}
+	}
+	return field
+}
+
+// quoteIdentifiers quotes every name for use as an identifier
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pq.QuoteIdentifier(name)
+	}
+	return quoted
+}
+
+// createTableStatement returns the statement creating an attribute table with the given columns
+func createTableStatement(tableName string, columns []Column) string {
+	columnDefs := []string{
+		// Add primary key and entity_attribute_id first
+		"id SERIAL PRIMARY KEY",
+		"entity_attribute_id INTEGER REFERENCES entity_attributes(id)",
+	}
+	for _, col := range columns {
+		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", pq.QuoteIdentifier(col.Name), col.Type))
+	}
+	// Add created_at timestamp at the end
+	columnDefs = append(columnDefs, "created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP")
+
+	return fmt.Sprintf(`
+	CREATE TABLE IF NOT EXISTS %s (
+		%s
+	);`, pq.QuoteIdentifier(tableName), strings.Join(columnDefs, ",\n"))
+}
+
+// insertStatement returns the statement inserting rows of the given columns into an attribute table, every row
+// starts with its entity_attribute_id
+func insertStatement(tableName string, columns []string, rowCount int) string {
+	valuesPerRow := len(columns) + 1 // +1 for entity_attribute_id
+	placeholders := make([]string, rowCount)
+	for i := range placeholders {
+		rowPlaceholders := make([]string, valuesPerRow)
+		for j := range rowPlaceholders {
+			rowPlaceholders[j] = fmt.Sprintf("$%d", i*valuesPerRow+j+1)
+		}
+		placeholders[i] = fmt.Sprintf("(%s)", strings.Join(rowPlaceholders, ", "))
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		pq.QuoteIdentifier(tableName),
+		strings.Join(append([]string{"entity_attribute_id"}, quoteIdentifiers(columns)...), ", "),
+		strings.Join(placeholders, ", "),
+	)
+}
+
+// selectStatement returns the query reading the given columns of a table, every column when there are none,
+// from the rows equal to a parameter in each filtered column
+func selectStatement(tableName string, columns []string, filterColumns []string) string {
+	selectClause := "*"
+	if len(columns) > 0 {
+		selectClause = strings.Join(quoteIdentifiers(columns), ", ")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selectClause, pq.QuoteIdentifier(tableName))
+
+	whereClauses := make([]string, len(filterColumns))
+	for i, column := range filterColumns {
+		whereClauses[i] = fmt.Sprintf("%s = $%d", pq.QuoteIdentifier(column), i+1)
+	}
+	if len(whereClauses) > 0 {
+		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+	return query
+}
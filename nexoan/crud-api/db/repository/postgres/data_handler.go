@@ -2,13 +2,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"strings"
+	"log/slog"
 	"time"
 
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
 	"lk/datafoundation/crud-api/pkg/schema"
 	"lk/datafoundation/crud-api/pkg/typeinference"
 
@@ -107,7 +109,7 @@ func UnmarshalEntityAttributes(attributes map[string]*anypb.Any) (map[string]int
 			continue
 		}
 
-		log.Printf("Warning: Could not unmarshal attribute %s with type %s", key, value.TypeUrl)
+		slog.Warn("Could not unmarshal attribute", "key", key, "type_url", value.TypeUrl)
 	}
 
 	return result, nil
@@ -163,103 +165,25 @@ func isTabularData(value *anypb.Any) (bool, *structpb.Struct, error) {
 	return true, &dataStruct, nil
 }
 
-// validateAndReturnTabularDataTypes validates that all values in each column have consistent types
+// validateAndReturnTabularDataTypes infers the type of each column from all of its values
 // and returns a map of column names to their inferred TypeInfo
 func validateAndReturnTabularDataTypes(data *structpb.Struct) (map[string]typeinference.TypeInfo, error) {
 	columnsList := data.Fields["columns"].GetListValue()
 	rowsList := data.Fields["rows"].GetListValue()
 
-	columnTypes := make(map[string]typeinference.TypeInfo)
-
-	// If there are no rows, return empty map
-	if len(rowsList.Values) == 0 {
-		return columnTypes, nil
+	columns := make([]string, len(columnsList.Values))
+	for i, col := range columnsList.Values {
+		columns[i] = col.GetStringValue()
 	}
-
-	// Initialize column types
-	for _, col := range columnsList.Values {
-		colName := col.GetStringValue()
-		columnTypes[colName] = typeinference.TypeInfo{
-			Type:       typeinference.StringType, // Default to string
-			IsNullable: false,
-		}
+	inferred, err := typeinference.InferColumnTypes(columns, rowsList.Values)
+	if err != nil {
+		return nil, err
 	}
 
-	// Process all rows to determine types
-	for _, row := range rowsList.Values {
-		rowData := row.GetListValue()
-		for i, value := range rowData.Values {
-			colName := columnsList.Values[i].GetStringValue()
-			currentType := columnTypes[colName]
-
-			switch v := value.Kind.(type) {
-			case *structpb.Value_NumberValue:
-				num := v.NumberValue
-				switch currentType.Type {
-				case typeinference.StringType:
-					// First number we've seen
-					if num == float64(int64(num)) {
-						columnTypes[colName] = typeinference.TypeInfo{Type: typeinference.IntType}
-					} else {
-						columnTypes[colName] = typeinference.TypeInfo{Type: typeinference.FloatType}
-					}
-				case typeinference.IntType:
-					// If we see a float, promote to float
-					if num != float64(int64(num)) {
-						columnTypes[colName] = typeinference.TypeInfo{Type: typeinference.FloatType}
-					}
-				case typeinference.FloatType:
-					// Already float, no change needed
-				default:
-					// Mixed types, convert to string
-					columnTypes[colName] = typeinference.TypeInfo{
-						Type:       typeinference.StringType,
-						IsNullable: true,
-					}
-				}
-			case *structpb.Value_StringValue:
-				str := v.StringValue
-				switch currentType.Type {
-				case typeinference.StringType:
-					// Check if it's a datetime
-					if isDateTime(str) {
-						columnTypes[colName] = typeinference.TypeInfo{Type: typeinference.DateTimeType}
-					}
-				case typeinference.DateTimeType:
-					// If current string is not a datetime, convert to string
-					if !isDateTime(str) {
-						columnTypes[colName] = typeinference.TypeInfo{
-							Type:       typeinference.StringType,
-							IsNullable: true,
-						}
-					}
-				default:
-					// Mixed types, convert to string
-					columnTypes[colName] = typeinference.TypeInfo{
-						Type:       typeinference.StringType,
-						IsNullable: true,
-					}
-				}
-			case *structpb.Value_BoolValue:
-				if currentType.Type != typeinference.BoolType && currentType.Type != typeinference.StringType {
-					// Mixed types, convert to string
-					columnTypes[colName] = typeinference.TypeInfo{
-						Type:       typeinference.StringType,
-						IsNullable: true,
-					}
-				} else if currentType.Type == typeinference.StringType {
-					columnTypes[colName] = typeinference.TypeInfo{Type: typeinference.BoolType}
-				}
-			default:
-				// Unknown type, convert to string
-				columnTypes[colName] = typeinference.TypeInfo{
-					Type:       typeinference.StringType,
-					IsNullable: true,
-				}
-			}
-		}
+	columnTypes := make(map[string]typeinference.TypeInfo, len(inferred))
+	for colName, typeInfo := range inferred {
+		columnTypes[colName] = *typeInfo
 	}
-
 	return columnTypes, nil
 }
 
@@ -288,8 +212,8 @@ func isDateTime(val string) bool {
 	return false
 }
 
-// validateDataAgainstSchema validates that the data matches the schema
-func validateDataAgainstSchema(data *structpb.Struct, schemaInfo *schema.SchemaInfo) error {
+// ValidateDataAgainstSchema validates that the data matches the schema
+func ValidateDataAgainstSchema(data *structpb.Struct, schemaInfo *schema.SchemaInfo) error {
 	columnsList := data.Fields["columns"].GetListValue()
 	rowsList := data.Fields["rows"].GetListValue()
 
@@ -313,24 +237,18 @@ func validateDataAgainstSchema(data *structpb.Struct, schemaInfo *schema.SchemaI
 			colName := columnsList.Values[j].GetStringValue()
 			fieldSchema := schemaInfo.Fields[colName]
 
-			// Validate type
-			switch fieldSchema.TypeInfo.Type {
-			case typeinference.IntType:
-				if v, ok := value.Kind.(*structpb.Value_NumberValue); !ok || v.NumberValue != float64(int64(v.NumberValue)) {
-					return fmt.Errorf("row %d, column %s: expected integer, got %v", i, colName, value)
-				}
-			case typeinference.FloatType:
-				if _, ok := value.Kind.(*structpb.Value_NumberValue); !ok {
-					return fmt.Errorf("row %d, column %s: expected float, got %v", i, colName, value)
-				}
-			case typeinference.BoolType:
-				if _, ok := value.Kind.(*structpb.Value_BoolValue); !ok {
-					return fmt.Errorf("row %d, column %s: expected boolean, got %v", i, colName, value)
-				}
-			case typeinference.DateTimeType:
-				if v, ok := value.Kind.(*structpb.Value_StringValue); !ok || !isDateTime(v.StringValue) {
-					return fmt.Errorf("row %d, column %s: expected datetime, got %v", i, colName, value)
+			// Nullable columns accept null in place of a value of their type
+			if _, isNull := value.Kind.(*structpb.Value_NullValue); isNull {
+				if fieldSchema.TypeInfo.IsNullable {
+					continue
 				}
+				return fmt.Errorf("row %d, column %s: expected %s, got null", i, colName, fieldSchema.TypeInfo.Type)
+			}
+
+			// Values of narrower types are widened into the column type
+			cellType, ok := typeinference.CellType(value)
+			if !ok || typeinference.Widen(fieldSchema.TypeInfo.Type, cellType) != fieldSchema.TypeInfo.Type {
+				return fmt.Errorf("row %d, column %s: expected %s, got %v", i, colName, fieldSchema.TypeInfo.Type, value)
 			}
 		}
 	}
@@ -338,8 +256,8 @@ func validateDataAgainstSchema(data *structpb.Struct, schemaInfo *schema.SchemaI
 	return nil
 }
 
-// compareSchemas compares two schemas and returns true if they are compatible
-func compareSchemas(existing, newSchema *schema.SchemaInfo) (bool, error) {
+// CompareSchemas compares two schemas and returns true if they are compatible
+func CompareSchemas(existing, newSchema *schema.SchemaInfo) (bool, error) {
 	if existing.StorageType != newSchema.StorageType {
 		return false, fmt.Errorf("storage type mismatch: existing=%s, newSchema=%s",
 			existing.StorageType, newSchema.StorageType)
@@ -375,29 +293,44 @@ func isTypeCompatible(existingType, newType typeinference.DataType) bool {
 		return true
 	}
 
-	// Type promotion rules
-	switch existingType {
-	case typeinference.IntType:
-		// Int can be promoted to float
-		return newType == typeinference.FloatType
-	case typeinference.StringType:
-		// String can accept any type
-		return true
-	case typeinference.DateTimeType:
-		// DateTime can only be datetime or string
-		return newType == typeinference.StringType
-	}
+	// The existing column has to hold the new values, see typeinference.Widen
+	return typeinference.Widen(existingType, newType) == existingType
+}
 
-	return false
+// AttributeTable returns the table holding the rows of an attribute. The table recorded in entity_attributes is
+// kept once the attribute has rows, so tables named before commons.AttributeTableName existed are still found.
+func (repo *PostgresRepository) AttributeTable(ctx context.Context, entityID, attrName string) (string, error) {
+	var tableName string
+	err := repo.DB().QueryRowContext(ctx,
+		`SELECT table_name FROM entity_attributes WHERE entity_id = $1 AND attribute_name = $2`,
+		entityID, attrName).Scan(&tableName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return commons.AttributeTableName(entityID, attrName), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading table of attribute %s: %v", attrName, err)
+	}
+	return tableName, nil
 }
 
 // handleTabularData processes tabular data attributes
 func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, schemaInfo *schema.SchemaInfo) error {
-	// Generate table name
-	tableName := fmt.Sprintf("attr_%s_%s", commons.SanitizeIdentifier(entityID), commons.SanitizeIdentifier(attrName))
+	tableName, err := repo.AttributeTable(ctx, entityID, attrName)
+	if err != nil {
+		return err
+	}
+
+	// Column names are kept exactly, so names that cannot be are rejected before anything is written
+	for fieldName := range schemaInfo.Fields {
+		if err := ValidateColumnName(fieldName); err != nil {
+			return err
+		}
+	}
 
 	// Convert schema to columns
 	columns := schemaToColumns(schemaInfo)
+	// Rows are stored under the schema of the table, which is the existing one once the table exists
+	tableSchema := schemaInfo
 
 	// Check if table exists
 	exists, err := repo.TableExists(ctx, tableName)
@@ -421,7 +354,7 @@ func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID,
 		}
 
 		// Compare schemas
-		compatible, err := compareSchemas(&existingSchema, schemaInfo)
+		compatible, err := CompareSchemas(&existingSchema, schemaInfo)
 		if err != nil {
 			return fmt.Errorf("schema compatibility check failed: %v", err)
 		}
@@ -436,9 +369,10 @@ func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID,
 			return fmt.Errorf("error unmarshaling tabular data: %v", err)
 		}
 
-		if err := validateDataAgainstSchema(&tabularStruct, &existingSchema); err != nil {
+		if err := ValidateDataAgainstSchema(&tabularStruct, &existingSchema); err != nil {
 			return fmt.Errorf("data validation failed: %v", err)
 		}
+		tableSchema = &existingSchema
 	} else {
 		// Create new table
 		if err := repo.CreateDynamicTable(ctx, tableName, columns); err != nil {
@@ -488,10 +422,19 @@ func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID,
 		return fmt.Errorf("invalid tabular data format")
 	}
 
-	// Convert columns to string slice
+	// Find the column of every field, the table was created with a column for each field of its schema
+	tableColumns := make(map[string]bool)
+	if exists {
+		if tableColumns, err = repo.tableColumns(ctx, tableName); err != nil {
+			return err
+		}
+	}
 	columnNames := make([]string, len(columnsValue.Values))
 	for i, col := range columnsValue.Values {
-		columnNames[i] = commons.SanitizeIdentifier(col.GetStringValue())
+		if err := ValidateColumnName(col.GetStringValue()); err != nil {
+			return err
+		}
+		columnNames[i] = columnName(tableColumns, col.GetStringValue())
 	}
 
 	// Convert rows to [][]interface{}
@@ -504,16 +447,7 @@ func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID,
 
 		rows[i] = make([]interface{}, len(rowList.Values))
 		for j, cell := range rowList.Values {
-			switch cell.Kind.(type) {
-			case *structpb.Value_StringValue:
-				rows[i][j] = cell.GetStringValue()
-			case *structpb.Value_NumberValue:
-				rows[i][j] = cell.GetNumberValue()
-			case *structpb.Value_BoolValue:
-				rows[i][j] = cell.GetBoolValue()
-			default:
-				rows[i][j] = cell.GetStringValue()
-			}
+			rows[i][j] = typeinference.ColumnValue(cell, columnType(tableSchema, columnsValue.Values[j].GetStringValue()))
 		}
 	}
 
@@ -522,16 +456,49 @@ func (repo *PostgresRepository) HandleTabularData(ctx context.Context, entityID,
 		return fmt.Errorf("error inserting tabular data: %v", err)
 	}
 
+	// Add the string cells to the search table, a new table has then had every cell added
+	cells := make(map[string][]string)
+	seen := make(map[string]bool)
+	for j, col := range columnsValue.Values {
+		if columnType(tableSchema, col.GetStringValue()) != typeinference.StringType {
+			continue
+		}
+		for _, row := range rows {
+			if j >= len(row) {
+				continue
+			}
+			text, ok := row[j].(string)
+			if !ok || seen[col.GetStringValue()+"\x00"+text] {
+				continue
+			}
+			seen[col.GetStringValue()+"\x00"+text] = true
+			cells[col.GetStringValue()] = append(cells[col.GetStringValue()], text)
+		}
+	}
+	if err := repo.addSearchText(ctx, tableName, cells); err != nil {
+		return err
+	}
+	if !exists {
+		return repo.markSearchable(ctx, tableName)
+	}
 	return nil
 }
 
+// columnType returns the type of a column of a table, it is empty for columns missing from the schema
+func columnType(schemaInfo *schema.SchemaInfo, column string) typeinference.DataType {
+	if field, ok := schemaInfo.Fields[column]; ok && field.TypeInfo != nil {
+		return field.TypeInfo.Type
+	}
+	return ""
+}
+
 // schemaToColumns converts a schema to database columns
 func schemaToColumns(schemaInfo *schema.SchemaInfo) []Column {
 	var columns []Column
 
 	for fieldName, field := range schemaInfo.Fields {
 		// Skip "id" columns as they conflict with the auto-generated primary key
-		if strings.ToLower(fieldName) == "id" {
+		if fieldName == "id" {
 			continue
 		}
 
@@ -560,7 +527,7 @@ func schemaToColumns(schemaInfo *schema.SchemaInfo) []Column {
 		}
 
 		columns = append(columns, Column{
-			Name: commons.SanitizeIdentifier(fieldName),
+			Name: fieldName,
 			Type: colType,
 		})
 	}
@@ -568,7 +535,7 @@ func schemaToColumns(schemaInfo *schema.SchemaInfo) []Column {
 	return columns
 }
 
-// Column represents a database column definition
+// Column represents a database column definition, its name is quoted so that it is kept exactly
 type Column struct {
 	Name string
 	Type string
@@ -614,6 +581,9 @@ func GetSchemaOfTable(ctx context.Context, repo *PostgresRepository, tableName s
 	`
 	var schemaJSON []byte
 	err := repo.DB().QueryRowContext(ctx, query, tableName).Scan(&schemaJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error getting schema for table %s: %w", tableName, commons.ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error getting schema for table %s: %v", tableName, err)
 	}
@@ -634,44 +604,29 @@ type TabularData struct {
 
 // GetData retrieves data from a table with optional field selection and filters, returns it as pb.Any with JSON-formatted tabular data.
 func (repo *PostgresRepository) GetData(ctx context.Context, tableName string, filters map[string]interface{}, fields ...string) (*anypb.Any, error) {
-	log.Printf("DEBUG: GetData: tableName=%s, \t\nfilters=%v, \t\nfields=%v", tableName, filters, fields)
-	// Build the SELECT clause
-	var selectClause string
-	if len(fields) > 0 {
-		log.Printf("DEBUG: [DataHandler.GetData] selectClause: %v", fields)
-		// Sanitize and quote field names
-		sanitizedFields := make([]string, len(fields))
+	logger := logging.FromContext(ctx).With("table", tableName)
+	logger.DebugContext(ctx, "Reading tabular data", "fields", fields, logging.Payload("filters", filters))
+	// Find the columns of the requested fields and filters
+	columns := make([]string, len(fields))
+	var filterColumns []string
+	var args []interface{}
+	if len(fields) > 0 || len(filters) > 0 {
+		tableColumns, err := repo.tableColumns(ctx, tableName)
+		if err != nil {
+			return nil, err
+		}
 		for i, field := range fields {
-			sanitizedFields[i] = commons.SanitizeIdentifier(field)
+			columns[i] = columnName(tableColumns, field)
+		}
+		for key, value := range filters {
+			filterColumns = append(filterColumns, columnName(tableColumns, key))
+			args = append(args, value)
 		}
-		selectClause = strings.Join(sanitizedFields, ", ")
-	} else {
-		log.Printf("DEBUG: [DataHandler.GetData] selectClause: *")
-		selectClause = "*"
-	}
-
-	log.Printf("DEBUG: [DataHandler.GetData] selectClause: %s", selectClause)
-	// Base query
-	query := fmt.Sprintf("SELECT %s FROM %s", selectClause, commons.SanitizeIdentifier(tableName))
-
-	log.Printf("DEBUG: [DataHandler.GetData] query: %s", query)
-
-	var args []interface{}
-	var whereClauses []string
-	argCount := 1
-
-	// Add filters to the query
-	for key, value := range filters {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", commons.SanitizeIdentifier(key), argCount))
-		args = append(args, value)
-		argCount++
-	}
-
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
 	}
+	query := selectStatement(tableName, columns, filterColumns)
 
 	// Execute the query
+	logger.DebugContext(ctx, "Executing query", "query", query)
 	rows, err := repo.DB().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying data from %s: %v", tableName, err)
@@ -687,9 +642,7 @@ func (repo *PostgresRepository) GetData(ctx context.Context, tableName string, f
 	// Filter out internal columns that shouldn't be returned by default
 	// unless they are explicitly requested in the fields parameter
 	filteredColumns, columnIndices := filterInternalColumns(resultColumns, fields)
-	log.Printf("DEBUG: [DataHandler.GetData] Original columns: %v", resultColumns)
-	log.Printf("DEBUG: [DataHandler.GetData] Filtered columns: %v", filteredColumns)
-	log.Printf("DEBUG: [DataHandler.GetData] Column indices to keep: %v", columnIndices)
+	logger.DebugContext(ctx, "Filtered internal columns", "columns", resultColumns, "filtered_columns", filteredColumns, "column_indices", columnIndices)
 
 	// Log which internal columns were filtered out or included
 	internalColumns := map[string]bool{
@@ -708,9 +661,9 @@ func (repo *PostgresRepository) GetData(ctx context.Context, tableName string, f
 					}
 				}
 				if found {
-					log.Printf("INFO: [DataHandler.GetData] Internal column '%s' included (explicitly requested)", column)
+					logger.DebugContext(ctx, "Internal column included, it was explicitly requested", "column", column)
 				} else {
-					log.Printf("INFO: [DataHandler.GetData] Internal column '%s' filtered out (not requested)", column)
+					logger.DebugContext(ctx, "Internal column filtered out, it was not requested", "column", column)
 				}
 			}
 		}
@@ -758,7 +711,7 @@ func (repo *PostgresRepository) GetData(ctx context.Context, tableName string, f
 		return nil, fmt.Errorf("error marshaling tabular data to JSON: %v", err)
 	}
 
-	log.Printf("DEBUG: [DataHandler.GetData] jsonData: %s", string(jsonData))
+	logger.DebugContext(ctx, "Read tabular data", "rows", len(tabularRows), logging.Payload("data", string(jsonData)))
 
 	// Create a struct with the JSON string
 	structValue, err := structpb.NewStruct(map[string]interface{}{
@@ -9,9 +9,12 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
 	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/search"
 	"lk/datafoundation/crud-api/pkg/storageinference"
 	"lk/datafoundation/crud-api/pkg/typeinference"
 )
@@ -65,6 +68,10 @@ func setupTestDB(t *testing.T) *PostgresRepository {
 				
 				-- Clean up test attribute_schemas entries  
 				DELETE FROM attribute_schemas WHERE table_name LIKE 'test_%' OR table_name = 'test_table';
+
+				-- Clean up the search text of test tables
+				DELETE FROM attribute_search WHERE table_name LIKE 'attr_test_%';
+				DELETE FROM attribute_search_tables WHERE table_name LIKE 'attr_test_%';
 			`)
 			if err != nil {
 				t.Logf("Warning: Failed to clean up test data: %v", err)
@@ -646,3 +653,73 @@ func TestGetDataTabularFormat(t *testing.T) {
 	assert.Len(t, filteredRows, 1)
 	assert.Equal(t, expectedRows[0], filteredRows[0].([]interface{}))
 }
+
+func TestStringColumns(t *testing.T) {
+	schemaInfo := &schema.SchemaInfo{
+		StorageType: storageinference.TabularData,
+		Fields: map[string]*schema.SchemaInfo{
+			"Unit Name": {TypeInfo: &typeinference.TypeInfo{Type: typeinference.StringType}},
+			"staff":     {TypeInfo: &typeinference.TypeInfo{Type: typeinference.IntType}},
+			"address":   {TypeInfo: &typeinference.TypeInfo{Type: typeinference.StringType}},
+			"opened":    {TypeInfo: &typeinference.TypeInfo{Type: typeinference.DateType}},
+		},
+	}
+	assert.Equal(t, []string{"Unit Name", "address"}, StringColumns(schemaInfo))
+}
+
+func TestIndexStatement(t *testing.T) {
+	name, statement := IndexStatement("attr_org_1_units", []string{"Unit Name", "staff"})
+	assert.Equal(t, IndexName("attr_org_1_units", []string{"Unit Name", "staff"}), name)
+	assert.Equal(t, `CREATE INDEX CONCURRENTLY IF NOT EXISTS `+name+` ON "attr_org_1_units" ("Unit Name", "staff")`, statement)
+	assert.LessOrEqual(t, len(name), 63, "Expected the name to fit in a PostgreSQL identifier")
+
+	// The name depends on the table and on the order of the columns
+	assert.NotEqual(t, name, IndexName("attr_org_2_units", []string{"Unit Name", "staff"}))
+	assert.NotEqual(t, name, IndexName("attr_org_1_units", []string{"staff", "Unit Name"}))
+	assert.NotEqual(t, name, IndexName("attr_org_1_units", []string{"unit_name", "staff"}))
+}
+
+func TestSearchTabular(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+
+	// A word of its own keeps the rows of other tests out of the matches
+	word := fmt.Sprintf("secretariat%d", time.Now().UnixNano())
+	entityID := fmt.Sprintf("test_entity_%d", time.Now().UnixNano())
+	query, err := search.NewQuery("Défence "+word, nil)
+	assert.NoError(t, err)
+
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": []interface{}{"unit", "staff"},
+		"rows": []interface{}{
+			[]interface{}{"Defence Ministry " + word, 12},
+			[]interface{}{"Defence Ministry " + word, 4},
+			[]interface{}{"Finance Ministry " + word, 3},
+		},
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	schemaInfo, err := schema.GenerateSchema(value)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.HandleTabularData(ctx, entityID, "units", &pb.TimeBasedValue{StartTime: "2024-01-01T00:00:00Z", Value: value}, schemaInfo))
+
+	matches, err := repo.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: entityID, Field: "attributes.units.unit", Text: "Defence Ministry " + word}}, matches)
+
+	// Tables written before the search table existed are added at startup
+	tableName, err := repo.AttributeTable(ctx, entityID, "units")
+	assert.NoError(t, err)
+	_, err = repo.DB().ExecContext(ctx, `DELETE FROM attribute_search WHERE table_name = $1`, tableName)
+	assert.NoError(t, err)
+	_, err = repo.DB().ExecContext(ctx, `DELETE FROM attribute_search_tables WHERE table_name = $1`, tableName)
+	assert.NoError(t, err)
+	matches, err = repo.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+	assert.NoError(t, repo.CreateIndexes(ctx))
+	matches, err = repo.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: entityID, Field: "attributes.units.unit", Text: "Defence Ministry " + word}}, matches)
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	commons "lk/datafoundation/crud-api/commons"
+)
+
+// splitQuoted returns a statement with every quoted identifier replaced by an empty pair of double quotes, and
+// the identifiers in order
+func splitQuoted(t *testing.T, statement string) (string, []string) {
+	t.Helper()
+	var skeleton strings.Builder
+	var names []string
+	for i := 0; i < len(statement); i++ {
+		if statement[i] != '"' {
+			skeleton.WriteByte(statement[i])
+			continue
+		}
+		var name strings.Builder
+		for i++; ; i++ {
+			if i >= len(statement) {
+				t.Fatalf("statement %q has an identifier that is never closed", statement)
+			}
+			if statement[i] == '"' {
+				if i+1 < len(statement) && statement[i+1] == '"' {
+					name.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			name.WriteByte(statement[i])
+		}
+		skeleton.WriteString(`""`)
+		names = append(names, name.String())
+	}
+	return skeleton.String(), names
+}
+
+// FuzzStatements builds the statements of an attribute table under fuzzed entity, attribute and column names and
+// checks that every name is quoted as a single identifier that reads back unchanged, so no name can change what
+// a statement does
+func FuzzStatements(f *testing.F) {
+	f.Add("org-1", "Budget-2020", "Unit Name")
+	f.Add("x'); DROP TABLE entity_attributes; --", "budget_2020", `amount"; DROP TABLE attribute_schemas; --`)
+	f.Add("entity-1", "people", `"`)
+	f.Fuzz(func(t *testing.T, entityID, attrName, column string) {
+		if ValidateColumnName(column) != nil {
+			t.Skip()
+		}
+		tableName := commons.AttributeTableName(entityID, attrName)
+		check := func(statement, expected string, names ...string) {
+			t.Helper()
+			skeleton, quoted := splitQuoted(t, statement)
+			expectedSkeleton, _ := splitQuoted(t, expected)
+			if skeleton != expectedSkeleton {
+				t.Fatalf("statement %q does not have the shape of %q", statement, expected)
+			}
+			if !slices.Equal(quoted, names) {
+				t.Fatalf("statement %q quotes %q instead of %q", statement, quoted, names)
+			}
+		}
+
+		check(createTableStatement(tableName, []Column{{Name: column, Type: "TEXT NOT NULL"}}),
+			createTableStatement("t", []Column{{Name: "c", Type: "TEXT NOT NULL"}}), tableName, column)
+		check(insertStatement(tableName, []string{column}, 2), insertStatement("t", []string{"c"}, 2), tableName, column)
+		check(selectStatement(tableName, []string{column}, []string{column}),
+			selectStatement("t", []string{"c"}, []string{"c"}), column, tableName, column)
+		check(copySearchTextStatement(tableName, column), copySearchTextStatement("t", "c"), column, tableName, column)
+
+		name, statement := IndexStatement(tableName, []string{column})
+		check(statement, fmt.Sprintf(`CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON "t" ("c")`, name), tableName, column)
+	})
+}
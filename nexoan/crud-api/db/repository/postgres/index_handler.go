@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"lk/datafoundation/crud-api/pkg/logging"
+
+	"github.com/lib/pq"
+)
+
+// AttributeIndexColumns are the columns of the index created on every attribute table.
+// The rows of an attribute are read by entity_attribute_id in the order they were written.
+var AttributeIndexColumns = []string{"entity_attribute_id", "created_at"}
+
+// IndexName returns the name of the index on the given columns of a table. Attribute table names can already be
+// as long as an identifier allows, so the name is derived from a digest of the table and its columns.
+func IndexName(tableName string, columns []string) string {
+	digest := sha256.Sum256([]byte(tableName + "(" + strings.Join(columns, ",") + ")"))
+	return "attr_idx_" + hex.EncodeToString(digest[:8])
+}
+
+// IndexStatement returns the name of the index on the given columns of a table and the statement creating it.
+// The statement does nothing when the index exists, so the same columns are indexed once, and it builds the
+// index without blocking writes to the table, so it cannot run inside a transaction.
+func IndexStatement(tableName string, columns []string) (string, string) {
+	name := IndexName(tableName, columns)
+	return name, fmt.Sprintf("CREATE INDEX CONCURRENTLY IF NOT EXISTS %s ON %s (%s)",
+		name, pq.QuoteIdentifier(tableName), strings.Join(quoteIdentifiers(columns), ", "))
+}
+
+// CreateIndex creates an index on the given columns of an attribute table and returns its name. The index is
+// built concurrently so that the attribute can still be written while a large table is indexed.
+func (repo *PostgresRepository) CreateIndex(ctx context.Context, tableName string, columns []string) (string, error) {
+	tableColumns, err := repo.tableColumns(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	resolved := make([]string, len(columns))
+	for i, column := range columns {
+		resolved[i] = columnName(tableColumns, column)
+	}
+	name, statement := IndexStatement(tableName, resolved)
+	if _, err := repo.DB().ExecContext(ctx, statement); err != nil {
+		// A concurrent build that fails leaves an invalid index behind, which would make the next attempt
+		// do nothing
+		if dropErr := repo.dropIndex(ctx, name); dropErr != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Failed to drop invalid index", "index", name, "error", dropErr)
+		}
+		return "", fmt.Errorf("error creating index on %s: %v", tableName, err)
+	}
+	return name, nil
+}
+
+// dropIndex drops an index without blocking the table it is on
+func (repo *PostgresRepository) dropIndex(ctx context.Context, name string) error {
+	if _, err := repo.DB().ExecContext(ctx, fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", name)); err != nil {
+		return fmt.Errorf("error dropping index %s: %v", name, err)
+	}
+	return nil
+}
+
+// CreateIndexes creates the attribute index on the attribute tables created before it was added and adds their
+// text to the search table, it is called once at startup
+func (repo *PostgresRepository) CreateIndexes(ctx context.Context) error {
+	if err := repo.InitializeTables(ctx); err != nil {
+		return err
+	}
+	tables, err := repo.attributeTables(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Indexes left invalid by a concurrent build that was interrupted are dropped and built again
+	rows, err := repo.DB().QueryContext(ctx,
+		`SELECT c.relname, i.indisvalid FROM pg_index i
+		JOIN pg_class c ON c.oid = i.indexrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public'`)
+	if err != nil {
+		return fmt.Errorf("error querying indexes: %v", err)
+	}
+	defer rows.Close()
+	indexes := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		var valid bool
+		if err := rows.Scan(&name, &valid); err != nil {
+			return fmt.Errorf("error scanning index name: %v", err)
+		}
+		indexes[name] = valid
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating indexes: %v", err)
+	}
+
+	created := 0
+	for _, table := range tables {
+		valid, exists := indexes[IndexName(table.TableName, AttributeIndexColumns)]
+		if valid {
+			continue
+		}
+		if exists {
+			if err := repo.dropIndex(ctx, IndexName(table.TableName, AttributeIndexColumns)); err != nil {
+				return err
+			}
+		}
+		if _, err := repo.CreateIndex(ctx, table.TableName, AttributeIndexColumns); err != nil {
+			return err
+		}
+		indexes[IndexName(table.TableName, AttributeIndexColumns)] = true
+		created++
+	}
+	if created > 0 {
+		logging.FromContext(ctx).InfoContext(ctx, "Indexed existing attribute tables", "tables", created)
+	}
+	return repo.addMissingSearchText(ctx, tables)
+}
@@ -20,6 +20,27 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	// SSLRootCert, SSLCert and SSLKey are the CA bundle, client certificate and client key files used by the verify modes
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	Pool PoolConfig
+}
+
+// PoolConfig holds the connection pool settings of the repository
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultPoolConfig is used for connections opened from a DSN and for zero pool settings
+var DefaultPoolConfig = PoolConfig{
+	MaxOpenConns:    25,
+	MaxIdleConns:    25,
+	ConnMaxLifetime: 5 * time.Minute,
 }
 
 // PostgresRepository represents a PostgreSQL database repository
@@ -29,23 +50,57 @@ type PostgresRepository struct {
 
 // NewPostgresRepository creates a new PostgreSQL repository
 func NewPostgresRepository(cfg Config) (*PostgresRepository, error) {
+	return NewPostgresRepositoryWithPool(cfg.DSN(), cfg.Pool)
+}
+
+// DSN returns the connection string of the configuration, the TLS files are only included when set
+func (cfg Config) DSN() string {
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+	for _, parameter := range []struct{ key, value string }{
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+	} {
+		if parameter.value != "" {
+			dsn += fmt.Sprintf(" %s=%s", parameter.key, quoteDSNValue(parameter.value))
+		}
+	}
+	return dsn
+}
 
-	return NewPostgresRepositoryFromDSN(dsn)
+// quoteDSNValue quotes a connection string value so that paths can hold spaces and quotes
+func quoteDSNValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
 
 // NewPostgresRepositoryFromDSN creates a new PostgreSQL repository from a connection string
 func NewPostgresRepositoryFromDSN(dsn string) (*PostgresRepository, error) {
+	return NewPostgresRepositoryWithPool(dsn, DefaultPoolConfig)
+}
+
+// NewPostgresRepositoryWithPool creates a new PostgreSQL repository from a connection string
+// with the given pool settings, zero settings fall back to DefaultPoolConfig
+func NewPostgresRepositoryWithPool(dsn string, pool PoolConfig) (*PostgresRepository, error) {
+	if pool.MaxOpenConns == 0 {
+		pool.MaxOpenConns = DefaultPoolConfig.MaxOpenConns
+	}
+	if pool.MaxIdleConns == 0 {
+		pool.MaxIdleConns = DefaultPoolConfig.MaxIdleConns
+	}
+	if pool.ConnMaxLifetime == 0 {
+		pool.ConnMaxLifetime = DefaultPoolConfig.ConnMaxLifetime
+	}
+
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("error opening database: %v", err)
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
 	// Test the connection
 	if err := db.Ping(); err != nil {
@@ -60,6 +115,11 @@ func (r *PostgresRepository) Close() error {
 	return r.db.Close()
 }
 
+// Ping verifies that the database can still be reached
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
 // DB returns the underlying *sql.DB instance
 func (r *PostgresRepository) DB() *sql.DB {
 	return r.db
@@ -123,7 +183,7 @@ func (r *PostgresRepository) InitializeTables(ctx context.Context) error {
 		return fmt.Errorf("error creating attribute_schemas table: %v", err)
 	}
 
-	return nil
+	return r.initializeSearch(ctx)
 }
 
 // TableExists checks if a table exists in the database
@@ -144,59 +204,29 @@ func (r *PostgresRepository) TableExists(ctx context.Context, tableName string)
 	return exists, nil
 }
 
-// CreateDynamicTable creates a new table for storing attribute data
+// CreateDynamicTable creates a new table for storing attribute data together with its attribute index
 func (r *PostgresRepository) CreateDynamicTable(ctx context.Context, tableName string, columns []Column) error {
-	// Build column definitions
-	var columnDefs []string
-	
-	// Add primary key and entity_attribute_id first
-	columnDefs = append(columnDefs, "id SERIAL PRIMARY KEY")
-	columnDefs = append(columnDefs, "entity_attribute_id INTEGER REFERENCES entity_attributes(id)")
-	
-	// Add the rest of the columns
-	for _, col := range columns {
-		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", col.Name, col.Type))
-	}
-	
-	// Add created_at timestamp at the end
-	columnDefs = append(columnDefs, "created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP")
-
-	// Create table query
-	createTableSQL := fmt.Sprintf(`
-	CREATE TABLE IF NOT EXISTS %s (
-		%s
-	);`, tableName, strings.Join(columnDefs, ",\n"))
+	createTableSQL := createTableStatement(tableName, columns)
 
 	// Execute the creation query
 	if _, err := r.db.ExecContext(ctx, createTableSQL); err != nil {
 		return fmt.Errorf("error creating dynamic table: %v", err)
 	}
 
+	// Index the rows by attribute and write time, which every read of the attribute filters on
+	if _, err := r.CreateIndex(ctx, tableName, AttributeIndexColumns); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 // InsertTabularData inserts rows into a dynamic table
 func (r *PostgresRepository) InsertTabularData(ctx context.Context, tableName string, entityAttributeID int, columns []string, rows [][]interface{}) error {
 	// Build the INSERT query
-	columnNames := append([]string{"entity_attribute_id"}, columns...)
-	placeholders := make([]string, len(rows))
+	query := insertStatement(tableName, columns, len(rows))
 	valuesPerRow := len(columns) + 1 // +1 for entity_attribute_id
 
-	for i := range rows {
-		rowPlaceholders := make([]string, valuesPerRow)
-		for j := range rowPlaceholders {
-			rowPlaceholders[j] = fmt.Sprintf("$%d", i*valuesPerRow+j+1)
-		}
-		placeholders[i] = fmt.Sprintf("(%s)", strings.Join(rowPlaceholders, ", "))
-	}
-
-	query := fmt.Sprintf(
-		"INSERT INTO %s (%s) VALUES %s",
-		tableName,
-		strings.Join(columnNames, ", "),
-		strings.Join(placeholders, ", "),
-	)
-
 	// Flatten values for the query
 	values := make([]interface{}, 0, len(rows)*valuesPerRow)
 	for _, row := range rows {
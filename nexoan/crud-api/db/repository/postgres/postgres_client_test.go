@@ -81,6 +81,14 @@ func createTabularDataStruct(columns []string, rows [][]interface{}) (*anypb.Any
 				rowData[j] = structpb.NewBoolValue(v)
 			case time.Time:
 				rowData[j] = structpb.NewStringValue(v.Format(time.RFC3339))
+			case nil:
+				rowData[j] = structpb.NewNullValue()
+			case []interface{}:
+				list, err := structpb.NewList(v)
+				if err != nil {
+					return nil, err
+				}
+				rowData[j] = structpb.NewListValue(list)
 			default:
 				// Try to convert to number if it's a numeric type
 				if i, ok := val.(int64); ok {
@@ -152,8 +160,7 @@ func TestValidateAndReturnTabularDataTypes(t *testing.T) {
 			},
 			expectedTypes: map[string]typeinference.TypeInfo{
 				"mixed_col": {
-					Type:       typeinference.StringType,
-					IsNullable: true,
+					Type: typeinference.StringType,
 				},
 			},
 			expectError: false,
@@ -196,12 +203,39 @@ func TestValidateAndReturnTabularDataTypes(t *testing.T) {
 			},
 			expectedTypes: map[string]typeinference.TypeInfo{
 				"date_col": {
-					Type:       typeinference.StringType,
+					Type: typeinference.StringType,
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:    "values and nulls",
+			columns: []string{"num_col", "null_col"},
+			rows: [][]interface{}{
+				{nil, nil},
+				{1.5, nil},
+			},
+			expectedTypes: map[string]typeinference.TypeInfo{
+				"num_col": {
+					Type:       typeinference.FloatType,
+					IsNullable: true,
+				},
+				"null_col": {
+					Type:       typeinference.NullType,
 					IsNullable: true,
 				},
 			},
 			expectError: false,
 		},
+		{
+			name:    "nested values conflict",
+			columns: []string{"tags"},
+			rows: [][]interface{}{
+				{"core"},
+				{[]interface{}{"a", "b"}},
+			},
+			expectError: true,
+		},
 		{
 			name:    "empty table",
 			columns: []string{"col1", "col2"},
@@ -248,6 +282,18 @@ func TestValidateAndReturnTabularDataTypes(t *testing.T) {
 	}
 }
 
+func TestConfigDSN(t *testing.T) {
+	cfg := Config{Host: "localhost", Port: "5432", User: "postgres", Password: "secret", DBName: "nexoan", SSLMode: "disable"}
+	assert.Equal(t, "host=localhost port=5432 user=postgres password=secret dbname=nexoan sslmode=disable", cfg.DSN())
+
+	cfg.SSLMode = "verify-full"
+	cfg.SSLRootCert = "/etc/ssl/postgres ca.pem"
+	cfg.SSLKey = `/etc/ssl/o'brien.key`
+	cfg.SSLCert = "/etc/ssl/client.crt"
+	assert.Equal(t, `host=localhost port=5432 user=postgres password=secret dbname=nexoan sslmode=verify-full `+
+		`sslrootcert='/etc/ssl/postgres ca.pem' sslcert='/etc/ssl/client.crt' sslkey='/etc/ssl/o\'brien.key'`, cfg.DSN())
+}
+
 func TestDateTimeDetection(t *testing.T) {
 	testCases := []struct {
 		input    string
@@ -437,9 +483,7 @@ func TestInsertSampleData(t *testing.T) {
 			assert.NoError(t, err, "Failed to handle attributes")
 
 			// Verify table exists
-			tableName := fmt.Sprintf("attr_%s_%s", 
-				commons.SanitizeIdentifier(tt.entityID), 
-				commons.SanitizeIdentifier(tt.attrName))
+			tableName := commons.AttributeTableName(tt.entityID, tt.attrName)
 			exists, err := repo.TableExists(ctx, tableName)
 			assert.NoError(t, err, "Failed to check table existence")
 			assert.True(t, exists, "Table should exist")
@@ -490,26 +534,26 @@ func TestQuerySampleData(t *testing.T) {
 	}{
 		{
 			name:       "Query Employee Salaries",
-			tableName:  "attr_emp_data_employee_records",
-			query:      "SELECT name, salary FROM attr_emp_data_employee_records WHERE salary > 70000",
+			tableName:  "attr_emp_5fdata__employee_5frecords",
+			query:      "SELECT name, salary FROM attr_emp_5fdata__employee_5frecords WHERE salary > 70000",
 			expectRows: true,
 		},
 		{
 			name:       "Query Active Employees",
-			tableName:  "attr_emp_data_employee_records",
-			query:      "SELECT name FROM attr_emp_data_employee_records WHERE is_active = true",
+			tableName:  "attr_emp_5fdata__employee_5frecords",
+			query:      "SELECT name FROM attr_emp_5fdata__employee_5frecords WHERE is_active = true",
 			expectRows: true,
 		},
 		{
 			name:       "Query Product Stock",
-			tableName:  "attr_inventory_product_stock",
-			query:      "SELECT name, quantity FROM attr_inventory_product_stock WHERE quantity > 100",
+			tableName:  "attr_inventory__product_5fstock",
+			query:      "SELECT name, quantity FROM attr_inventory__product_5fstock WHERE quantity > 100",
 			expectRows: true,
 		},
 		{
 			name:       "Query Sensor Temperature",
-			tableName:  "attr_sensor_data_temperature_readings",
-			query:      "SELECT location, temperature FROM attr_sensor_data_temperature_readings WHERE temperature > 23",
+			tableName:  "attr_sensor_5fdata__temperature_5freadings",
+			query:      "SELECT location, temperature FROM attr_sensor_5fdata__temperature_5freadings WHERE temperature > 23",
 			expectRows: true,
 		},
 	}
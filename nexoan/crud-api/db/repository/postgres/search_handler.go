@@ -0,0 +1,276 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	commons "lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/search"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"github.com/lib/pq"
+)
+
+// AttributeTable is the table holding the rows of a tabular attribute
+type AttributeTable struct {
+	EntityID  string
+	AttrName  string
+	TableName string
+}
+
+// StringColumns returns the sorted names of the string fields of a table schema
+func StringColumns(schemaInfo *schema.SchemaInfo) []string {
+	var columns []string
+	for fieldName, field := range schemaInfo.Fields {
+		if field.TypeInfo != nil && field.TypeInfo.Type == typeinference.StringType {
+			columns = append(columns, fieldName)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// TableSearcher is implemented by the SQLite repository, whose tabular attributes are searched by scanning
+// the distinct values of their string columns
+type TableSearcher interface {
+	GetSchemaOfTable(ctx context.Context, tableName string) (*schema.SchemaInfo, error)
+	// DistinctValues returns the distinct non null values of a column
+	DistinctValues(ctx context.Context, tableName, column string) ([]string, error)
+}
+
+// SearchTables returns the string cells of the given attribute tables that contain every term of the query.
+// A text repeated in a column is matched once, and the cells are matched after their accents are removed
+// since SQLite does not fold accents.
+func SearchTables(ctx context.Context, searcher TableSearcher, tables []AttributeTable, query search.Query) ([]search.Match, error) {
+	var matches []search.Match
+	for _, table := range tables {
+		schemaInfo, err := searcher.GetSchemaOfTable(ctx, table.TableName)
+		if errors.Is(err, commons.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, column := range StringColumns(schemaInfo) {
+			values, err := searcher.DistinctValues(ctx, table.TableName, column)
+			if err != nil {
+				return nil, err
+			}
+			for _, text := range values {
+				if !query.MatchesText(text) {
+					continue
+				}
+				matches = append(matches, search.Match{
+					EntityID: table.EntityID,
+					Field:    search.AttributeField(table.AttrName, column),
+					Text:     text,
+				})
+				if len(matches) == search.MaxMatches {
+					return matches, nil
+				}
+			}
+		}
+	}
+	return matches, nil
+}
+
+// attributeTables returns the tables of every tabular attribute ordered by entity ID and attribute name
+func (repo *PostgresRepository) attributeTables(ctx context.Context) ([]AttributeTable, error) {
+	rows, err := repo.DB().QueryContext(ctx,
+		`SELECT entity_id, attribute_name, table_name FROM entity_attributes ORDER BY entity_id, attribute_name`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attribute tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []AttributeTable
+	for rows.Next() {
+		var table AttributeTable
+		if err := rows.Scan(&table.EntityID, &table.AttrName, &table.TableName); err != nil {
+			return nil, fmt.Errorf("error scanning attribute table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// initializeSearch creates the attribute_search table holding the distinct string cells of every column of the
+// attribute tables with their text search vector, accents removed by unaccent, and its full-text index.
+// attribute_search_tables lists the tables whose cells have all been added.
+func (repo *PostgresRepository) initializeSearch(ctx context.Context) error {
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS unaccent`,
+		`CREATE TABLE IF NOT EXISTS attribute_search (
+			table_name VARCHAR(255) NOT NULL,
+			column_name TEXT NOT NULL,
+			text TEXT NOT NULL,
+			document TSVECTOR NOT NULL
+		)`,
+		// Cells can be longer than a B-tree entry allows, so they are told apart by their digest
+		`CREATE UNIQUE INDEX IF NOT EXISTS attribute_search_cell ON attribute_search (table_name, column_name, md5(text))`,
+		`CREATE INDEX IF NOT EXISTS attribute_search_document ON attribute_search USING GIN (document)`,
+		`CREATE TABLE IF NOT EXISTS attribute_search_tables (table_name VARCHAR(255) PRIMARY KEY)`,
+	}
+	for _, statement := range statements {
+		if _, err := repo.DB().ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error creating attribute search table: %v", err)
+		}
+	}
+	return nil
+}
+
+// addSearchText adds the given cells of the columns of a table to attribute_search, cells already there are kept
+func (repo *PostgresRepository) addSearchText(ctx context.Context, tableName string, cells map[string][]string) error {
+	for column, texts := range cells {
+		_, err := repo.DB().ExecContext(ctx,
+			`INSERT INTO attribute_search (table_name, column_name, text, document)
+			SELECT $1, $2, text, to_tsvector('simple', unaccent(text)) FROM unnest($3::text[]) AS text
+			ON CONFLICT (table_name, column_name, md5(text)) DO NOTHING`,
+			tableName, column, pq.Array(texts))
+		if err != nil {
+			return fmt.Errorf("error adding search text of %s.%s: %v", tableName, column, err)
+		}
+	}
+	return nil
+}
+
+// markSearchable records that every cell of a table has been added to attribute_search
+func (repo *PostgresRepository) markSearchable(ctx context.Context, tableName string) error {
+	_, err := repo.DB().ExecContext(ctx,
+		`INSERT INTO attribute_search_tables (table_name) VALUES ($1) ON CONFLICT DO NOTHING`, tableName)
+	if err != nil {
+		return fmt.Errorf("error marking %s as searchable: %v", tableName, err)
+	}
+	return nil
+}
+
+// addMissingSearchText adds the cells of the attribute tables written before attribute_search existed, it is
+// called once at startup
+func (repo *PostgresRepository) addMissingSearchText(ctx context.Context, tables []AttributeTable) error {
+	rows, err := repo.DB().QueryContext(ctx, `SELECT table_name FROM attribute_search_tables`)
+	if err != nil {
+		return fmt.Errorf("error querying searchable tables: %v", err)
+	}
+	defer rows.Close()
+	searchable := make(map[string]bool)
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return fmt.Errorf("error scanning searchable table: %v", err)
+		}
+		searchable[tableName] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating searchable tables: %v", err)
+	}
+
+	added := 0
+	for _, table := range tables {
+		if searchable[table.TableName] {
+			continue
+		}
+		schemaInfo, err := repo.GetSchemaOfTable(ctx, table.TableName)
+		if errors.Is(err, commons.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		tableColumns, err := repo.tableColumns(ctx, table.TableName)
+		if err != nil {
+			return err
+		}
+		for _, column := range StringColumns(schemaInfo) {
+			statement := copySearchTextStatement(table.TableName, columnName(tableColumns, column))
+			if _, err := repo.DB().ExecContext(ctx, statement, table.TableName, column); err != nil {
+				return fmt.Errorf("error adding search text of %s.%s: %v", table.TableName, column, err)
+			}
+		}
+		if err := repo.markSearchable(ctx, table.TableName); err != nil {
+			return err
+		}
+		searchable[table.TableName] = true
+		added++
+	}
+	if added > 0 {
+		logging.FromContext(ctx).InfoContext(ctx, "Added search text of existing attribute tables", "tables", added)
+	}
+	return nil
+}
+
+// copySearchTextStatement returns the statement adding the distinct cells of a column of a table to
+// attribute_search under the table and field given as parameters
+func copySearchTextStatement(tableName, column string) string {
+	return fmt.Sprintf(`INSERT INTO attribute_search (table_name, column_name, text, document)
+		SELECT $1, $2, text, to_tsvector('simple', unaccent(text))
+		FROM (SELECT DISTINCT %s::text AS text FROM %s WHERE %s IS NOT NULL) AS cells
+		ON CONFLICT (table_name, column_name, md5(text)) DO NOTHING`,
+		pq.QuoteIdentifier(column), pq.QuoteIdentifier(tableName), pq.QuoteIdentifier(column))
+}
+
+// SearchTabular returns the string cells of tabular attributes that contain every term of the query, best ranked
+// first. The full-text index finds the cells holding every term and the cells are checked with the search
+// package so that every tabular store tokenizes text the same way. The attribute_search table is created
+// by CreateIndexes at startup.
+func (repo *PostgresRepository) SearchTabular(ctx context.Context, query search.Query) ([]search.Match, error) {
+	// Terms are letters and digits so they need no escaping
+	terms := strings.Join(query.Terms, " & ")
+
+	// Cells the search package rejects do not count towards the limit, so the cells are read a page at a time
+	// until enough of them match or there are no more
+	var matches []search.Match
+	for offset := 0; len(matches) < search.MaxMatches; offset += search.MaxMatches {
+		page, cells, err := repo.searchTabularPage(ctx, query, terms, offset)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, page...)
+		if cells < search.MaxMatches {
+			break
+		}
+	}
+	if len(matches) > search.MaxMatches {
+		matches = matches[:search.MaxMatches]
+	}
+	return matches, nil
+}
+
+// searchTabularPage returns the matches among a page of the cells holding every term and the number of cells
+// in the page
+func (repo *PostgresRepository) searchTabularPage(ctx context.Context, query search.Query, terms string, offset int) ([]search.Match, int, error) {
+	rows, err := repo.DB().QueryContext(ctx,
+		`SELECT a.entity_id, a.attribute_name, s.column_name, s.text
+		FROM attribute_search s
+		JOIN entity_attributes a ON a.table_name = s.table_name
+		CROSS JOIN to_tsquery('simple', unaccent($1)) AS terms
+		WHERE s.document @@ terms
+		ORDER BY ts_rank(s.document, terms) DESC, a.entity_id, a.attribute_name, s.column_name, s.text
+		LIMIT $2 OFFSET $3`,
+		terms, search.MaxMatches, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error searching tabular attributes: %v", err)
+	}
+	defer rows.Close()
+
+	var matches []search.Match
+	cells := 0
+	for rows.Next() {
+		var entityID, attrName, column, text string
+		if err := rows.Scan(&entityID, &attrName, &column, &text); err != nil {
+			return nil, 0, fmt.Errorf("error scanning tabular search result: %v", err)
+		}
+		cells++
+		if query.MatchesText(text) {
+			matches = append(matches, search.Match{EntityID: entityID, Field: search.AttributeField(attrName, column), Text: text})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating tabular search results: %v", err)
+	}
+	return matches, cells, nil
+}
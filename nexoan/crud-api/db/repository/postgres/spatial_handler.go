@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/geo"
+)
+
+// InitializeGeometries enables PostGIS and creates the attribute_geometries table if it doesn't exist.
+// The geometries of geospatial attributes are kept in a single table with a GiST index so that
+// the entities whose attribute contains a point or intersects a box are found without a scan.
+// An attribute keeps a geometry per start time, a geometry without a start time starts at -infinity
+// and one without an end time stays open.
+func (r *PostgresRepository) InitializeGeometries(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS postgis;`); err != nil {
+		return fmt.Errorf("error enabling PostGIS: %v", err)
+	}
+
+	attributeGeometriesSQL := `
+	CREATE TABLE IF NOT EXISTS attribute_geometries (
+		entity_id VARCHAR(255) NOT NULL,
+		attribute_name VARCHAR(255) NOT NULL,
+		start_time TIMESTAMPTZ NOT NULL,
+		end_time TIMESTAMPTZ,
+		geometry GEOMETRY(Geometry, 4326) NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (entity_id, attribute_name, start_time)
+	);`
+	if _, err := r.db.ExecContext(ctx, attributeGeometriesSQL); err != nil {
+		return fmt.Errorf("error creating attribute_geometries table: %v", err)
+	}
+
+	indexSQL := `CREATE INDEX IF NOT EXISTS attribute_geometries_geometry_idx ON attribute_geometries USING GIST (geometry);`
+	if _, err := r.db.ExecContext(ctx, indexSQL); err != nil {
+		return fmt.Errorf("error creating attribute_geometries index: %v", err)
+	}
+	return nil
+}
+
+// SaveGeometry stores the geometry an attribute has from startTime until endTime, replacing the geometry
+// with the same start time
+func (r *PostgresRepository) SaveGeometry(ctx context.Context, entityID, attrName string, geometry *geo.Geometry, startTime, endTime time.Time) error {
+	geoJSON, err := json.Marshal(geometry)
+	if err != nil {
+		return fmt.Errorf("error encoding geometry: %v", err)
+	}
+	var start interface{} = "-infinity"
+	if !startTime.IsZero() {
+		start = startTime
+	}
+	var end interface{}
+	if !endTime.IsZero() {
+		end = endTime
+	}
+
+	query := `
+	INSERT INTO attribute_geometries (entity_id, attribute_name, start_time, end_time, geometry)
+	VALUES ($1, $2, $3, $4, ST_SetSRID(ST_GeomFromGeoJSON($5), 4326))
+	ON CONFLICT (entity_id, attribute_name, start_time)
+	DO UPDATE SET end_time = EXCLUDED.end_time, geometry = EXCLUDED.geometry, updated_at = CURRENT_TIMESTAMP;`
+	if _, err := r.db.ExecContext(ctx, query, entityID, attrName, start, end, string(geoJSON)); err != nil {
+		return fmt.Errorf("error saving geometry of attribute %s: %v", attrName, err)
+	}
+	return nil
+}
+
+// GetGeometry returns the latest geometry of an attribute, the error wraps commons.ErrNotFound when there is none
+func (r *PostgresRepository) GetGeometry(ctx context.Context, entityID, attrName string) (*geo.Geometry, error) {
+	var geoJSON string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT ST_AsGeoJSON(geometry) FROM attribute_geometries WHERE entity_id = $1 AND attribute_name = $2
+		ORDER BY start_time DESC LIMIT 1`,
+		entityID, attrName).Scan(&geoJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error getting geometry of attribute %s: %w", attrName, commons.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting geometry of attribute %s: %v", attrName, err)
+	}
+	return geo.ParseJSON([]byte(geoJSON))
+}
+
+// DeleteGeometry removes every geometry of an attribute
+func (r *PostgresRepository) DeleteGeometry(ctx context.Context, entityID, attrName string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM attribute_geometries WHERE entity_id = $1 AND attribute_name = $2`, entityID, attrName)
+	if err != nil {
+		return fmt.Errorf("error deleting geometry of attribute %s: %v", attrName, err)
+	}
+	return nil
+}
+
+// FindEntities returns the sorted IDs of the entities whose attribute geometry at activeAt matches the filter.
+// The geometry of an entity at activeAt is the one started last by then, unless it ended before.
+// ST_Covers includes the boundary of the geometry like geo.Geometry.ContainsPoint does.
+func (r *PostgresRepository) FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	query := `
+	SELECT g.entity_id FROM attribute_geometries g
+	WHERE g.attribute_name = $1 AND g.start_time <= $2 AND (g.end_time IS NULL OR g.end_time > $2)
+		AND NOT EXISTS (
+			SELECT 1 FROM attribute_geometries later
+			WHERE later.entity_id = g.entity_id AND later.attribute_name = g.attribute_name
+				AND later.start_time > g.start_time AND later.start_time <= $2)`
+	args := []interface{}{attrName, activeAt}
+	if p := filter.ContainsPoint; p != nil {
+		args = append(args, p.Lon, p.Lat)
+		query += fmt.Sprintf(" AND ST_Covers(g.geometry, ST_SetSRID(ST_MakePoint($%d, $%d), 4326))", len(args)-1, len(args))
+	}
+	if b := filter.IntersectsBox; b != nil {
+		args = append(args, b.MinLon, b.MinLat, b.MaxLon, b.MaxLat)
+		query += fmt.Sprintf(" AND ST_Intersects(g.geometry, ST_MakeEnvelope($%d, $%d, $%d, $%d, 4326))", len(args)-3, len(args)-2, len(args)-1, len(args))
+	}
+	query += " ORDER BY g.entity_id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error finding entities by attribute %s: %v", attrName, err)
+	}
+	defer rows.Close()
+
+	var entityIDs []string
+	for rows.Next() {
+		var entityID string
+		if err := rows.Scan(&entityID); err != nil {
+			return nil, fmt.Errorf("error scanning entity id: %v", err)
+		}
+		entityIDs = append(entityIDs, entityID)
+	}
+	return entityIDs, rows.Err()
+}
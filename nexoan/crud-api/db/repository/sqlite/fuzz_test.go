@@ -0,0 +1,176 @@
+package sqliterepository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/schema"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// readTableNames returns the names of the tables in the database in order
+func readTableNames(t *testing.T, repo *TabularRepository) []string {
+	rows, err := repo.db.Query(`SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	names := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// FuzzTabularRepository stores a one-cell table under fuzzed names in an empty database and checks that the
+// only table it can create is the attribute table and that the column and cell read back unchanged
+func FuzzTabularRepository(f *testing.F) {
+	ctx := context.Background()
+	f.Add("entity-1", "people", "name", "Alice")
+	f.Add("x'); DROP TABLE entity_attributes; --", "Budget-2020", "amount\"; DROP TABLE attribute_schemas; --", "' OR 1=1 --")
+	f.Add("entity-1", "budget_2020", "entity_attribute_id", "`")
+	f.Add("entity-1", "budget_2020", "a`) ; DROP TABLE entity_attributes; --", "x")
+	f.Fuzz(func(t *testing.T, entityID, attrName, column, cell string) {
+		// Every input gets its own database so that no earlier input decides what this one finds
+		repo, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer repo.Close()
+		if err := repo.InitializeTables(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		tabular, err := structpb.NewStruct(map[string]interface{}{
+			"columns": []interface{}{column},
+			"rows":    []interface{}{[]interface{}{cell}},
+		})
+		if err != nil {
+			t.Skip()
+		}
+		value, err := anypb.New(tabular)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schemaInfo, err := schema.GenerateSchema(value)
+		if err != nil {
+			t.Skip()
+		}
+
+		before := readTableNames(t, repo)
+		err = repo.HandleTabularData(ctx, entityID, attrName, &pb.TimeBasedValue{StartTime: "2024-01-01T00:00:00Z", Value: value}, schemaInfo)
+		after := readTableNames(t, repo)
+
+		tableName, tableErr := repo.AttributeTable(ctx, entityID, attrName)
+		if tableErr != nil {
+			t.Fatal(tableErr)
+		}
+		for _, name := range after {
+			if !slices.Contains(before, name) && name != tableName {
+				t.Fatalf("storing %q, %q created table %q", entityID, attrName, name)
+			}
+		}
+		for _, name := range before {
+			if !slices.Contains(after, name) {
+				t.Fatalf("storing %q, %q dropped table %q", entityID, attrName, name)
+			}
+		}
+		if err != nil {
+			return
+		}
+
+		result, err := repo.GetData(ctx, tableName, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, rows := readTabularData(t, result)
+		if !slices.Contains(columns, column) {
+			t.Fatalf("column %q of %q, %q was read back as %q", column, entityID, attrName, columns)
+		}
+		for _, row := range rows {
+			if slices.Contains(row, interface{}(cell)) {
+				return
+			}
+		}
+		t.Fatalf("cell %q of %q, %q was not read back from %s", cell, entityID, attrName, tableName)
+	})
+}
+
+// FuzzGraphRepository creates entities and relationships under fuzzed kind and relationship names and checks
+// that they are either rejected as invalid identifiers or read back unchanged
+func FuzzGraphRepository(f *testing.F) {
+	ctx := context.Background()
+	repo, err := NewGraphRepository(ctx, filepath.Join(f.TempDir(), "graph.db"), config.RelationshipIntegrityConfig{})
+	if err != nil {
+		f.Fatal(err)
+	}
+	defer repo.Close(ctx)
+
+	f.Add("Person", "WORKS_AT")
+	f.Add("Person`) DETACH DELETE n //", "KNOWS]->() DELETE r //")
+	f.Add("Budget-2020", "HAS CHILD")
+	iteration := 0
+	f.Fuzz(func(t *testing.T, major, relationshipName string) {
+		iteration++
+		parentID := "parent-" + strconv.Itoa(iteration)
+		childID := parentID + "-child"
+		parent := newTestEntity(t, parentID, major, "2020-01-01T00:00:00Z", "")
+		child := newTestEntity(t, childID, "Organisation", "2020-01-01T00:00:00Z", "")
+
+		var invalid *cypher.InvalidIdentifierError
+		_, err := repo.HandleGraphEntityCreation(ctx, parent)
+		if err != nil {
+			if cypher.ValidateIdentifier(major) == nil {
+				t.Fatalf("entity of kind %q was rejected: %v", major, err)
+			}
+			// Empty names are rejected as missing fields before they are validated
+			if major != "" && !errors.As(err, &invalid) {
+				t.Fatalf("entity of kind %q was rejected with %T", major, err)
+			}
+			return
+		}
+		kind, _, _, _, err := repo.GetGraphEntity(ctx, parentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kind.Major != major {
+			t.Fatalf("kind %q was read back as %q", major, kind.Major)
+		}
+
+		if _, err := repo.HandleGraphEntityCreation(ctx, child); err != nil {
+			t.Fatal(err)
+		}
+		relationshipID := parentID + "-rel"
+		err = createTestRelationship(repo, relationshipID, relationshipName, parentID, childID, "2020-06-01T00:00:00Z", "")
+		if err != nil {
+			if cypher.ValidateIdentifier(relationshipName) == nil {
+				t.Fatalf("relationship %q was rejected: %v", relationshipName, err)
+			}
+			// Empty names are rejected as missing fields before they are validated
+			if relationshipName != "" && !errors.As(err, &invalid) {
+				t.Fatalf("relationship %q was rejected with %T", relationshipName, err)
+			}
+			return
+		}
+		relationship, err := repo.GetGraphRelationship(ctx, relationshipID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if relationship.Relationship.Name != relationshipName {
+			t.Fatalf("relationship %q was read back as %q", relationshipName, relationship.Relationship.Name)
+		}
+	})
+}
@@ -0,0 +1,361 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// GraphRepository stores entities and relationships in SQLite.
+// It applies the same validation, relationship type registry and integrity rules as the Neo4j repository.
+// Timestamps are kept as given and, for comparisons, in a sortable UTC form.
+type GraphRepository struct {
+	db                *sql.DB
+	rules             config.RelationshipIntegrityConfig
+	relationshipTypes *relationships.TypeRegistry
+}
+
+// NewGraphRepository opens the graph database at path and creates its tables if they don't exist
+func NewGraphRepository(ctx context.Context, path string, rules config.RelationshipIntegrityConfig) (*GraphRepository, error) {
+	db, err := openDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	repo := &GraphRepository{
+		db:                db,
+		rules:             rules,
+		relationshipTypes: relationships.NewTypeRegistry(),
+	}
+	if err := repo.initializeTables(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return repo, nil
+}
+
+// initializeTables creates the entity and relationship tables
+func (r *GraphRepository) initializeTables(ctx context.Context) error {
+	statements := []string{`
+	CREATE TABLE IF NOT EXISTS entities (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		major_kind TEXT NOT NULL,
+		minor_kind TEXT NOT NULL,
+		created TEXT NOT NULL,
+		created_utc TEXT NOT NULL,
+		terminated TEXT,
+		terminated_utc TEXT
+	);`, `
+	CREATE TABLE IF NOT EXISTS relationships (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		source_id TEXT NOT NULL REFERENCES entities(id),
+		target_id TEXT NOT NULL REFERENCES entities(id),
+		created TEXT NOT NULL,
+		created_utc TEXT NOT NULL,
+		terminated TEXT,
+		terminated_utc TEXT,
+		properties TEXT NOT NULL DEFAULT '{}'
+	);`,
+		`CREATE INDEX IF NOT EXISTS entities_kind ON entities (major_kind, minor_kind);`,
+		`CREATE INDEX IF NOT EXISTS relationships_source ON relationships (source_id, type);`,
+		`CREATE INDEX IF NOT EXISTS relationships_target ON relationships (target_id, type);`,
+	}
+	for _, statement := range statements {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error creating graph tables: %v", err)
+		}
+	}
+	return nil
+}
+
+// RelationshipTypes returns the relationship type registry used to validate new relationships
+func (r *GraphRepository) RelationshipTypes() *relationships.TypeRegistry {
+	return r.relationshipTypes
+}
+
+// Close closes the database connection
+func (r *GraphRepository) Close(ctx context.Context) {
+	if err := r.db.Close(); err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error closing graph database", "error", err)
+	}
+}
+
+// Ping checks that the database can still be reached
+func (r *GraphRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// graphEntity is an entity row
+type graphEntity struct {
+	ID         string
+	Name       string
+	MajorKind  string
+	MinorKind  string
+	Created    string
+	Terminated string // empty while the entity is active
+}
+
+// kind returns the kind of an entity
+func (e *graphEntity) kind() *pb.Kind {
+	return &pb.Kind{Major: e.MajorKind, Minor: e.MinorKind}
+}
+
+// entityColumns are the columns scanned by scanEntity
+const entityColumns = "id, name, major_kind, minor_kind, created, COALESCE(terminated, '')"
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEntity scans a row selected with entityColumns
+func scanEntity(row rowScanner) (*graphEntity, error) {
+	var entity graphEntity
+	if err := row.Scan(&entity.ID, &entity.Name, &entity.MajorKind, &entity.MinorKind, &entity.Created, &entity.Terminated); err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// readEntity reads an entity row, sql.ErrNoRows is returned when it does not exist
+func readEntity(ctx context.Context, q queryer, entityID string) (*graphEntity, error) {
+	return scanEntity(q.QueryRowContext(ctx, "SELECT "+entityColumns+" FROM entities WHERE id = ?", entityID))
+}
+
+// queryer is implemented by *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// entityName unpacks the name of an entity
+func entityName(entity *pb.Entity) (string, error) {
+	var stringValue wrapperspb.StringValue
+	if err := entity.Name.GetValue().UnmarshalTo(&stringValue); err != nil {
+		return "", fmt.Errorf("error unpacking Name value: %v", err)
+	}
+	return stringValue.Value, nil
+}
+
+// ReadGraphEntity retrieves an entity by its ID and returns it as a map
+func (r *GraphRepository) ReadGraphEntity(ctx context.Context, entityID string) (map[string]interface{}, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity Id cannot be empty")
+	}
+	entity, err := readEntity(ctx, r.db, entityID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("entity with Id %s not found", entityID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading entity: %v", err)
+	}
+
+	result := map[string]interface{}{
+		"Id":        entity.ID,
+		"Name":      entity.Name,
+		"Created":   entity.Created,
+		"MajorKind": entity.MajorKind,
+		"MinorKind": entity.MinorKind,
+	}
+	if entity.Terminated != "" {
+		result["Terminated"] = entity.Terminated
+	}
+	return result, nil
+}
+
+// GetGraphEntity retrieves the kind, name, created and terminated values of an entity,
+// the error wraps commons.ErrNotFound when there is no such entity
+func (r *GraphRepository) GetGraphEntity(ctx context.Context, entityId string) (*pb.Kind, *pb.TimeBasedValue, string, string, error) {
+	entity, err := readEntity(ctx, r.db, entityId)
+	if err == sql.ErrNoRows {
+		err = fmt.Errorf("entity with Id %s: %w", entityId, commons.ErrNotFound)
+	}
+	if err != nil {
+		logging.FromContext(ctx).DebugContext(ctx, "Error reading entity", "entity_id", entityId, "error", err)
+		return nil, nil, "", "", fmt.Errorf("[sqlite_graph.GetGraphEntity] error reading entity: %w", err)
+	}
+
+	value, _ := anypb.New(wrapperspb.String(entity.Name))
+	name := &pb.TimeBasedValue{
+		StartTime: entity.Created,
+		EndTime:   entity.Terminated,
+		Value:     value,
+	}
+	return entity.kind(), name, entity.Created, entity.Terminated, nil
+}
+
+// HandleGraphEntityCreation creates a new entity
+func (r *GraphRepository) HandleGraphEntityCreation(ctx context.Context, entity *pb.Entity) (bool, error) {
+	if entity.Kind.GetMajor() == "" || entity.Kind.GetMinor() == "" || entity.Name.GetValue() == nil || entity.Created == "" {
+		logging.FromContext(ctx).WarnContext(ctx, "Entity is missing required fields", "entity_id", entity.Id)
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] missing required fields for entity creation")
+	}
+	if err := cypher.ValidateIdentifier(entity.Kind.Major); err != nil {
+		return false, err
+	}
+
+	name, err := entityName(entity)
+	if err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] %v", err)
+	}
+	createdUTC, err := sortableTime(entity.Created)
+	if err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] %v", err)
+	}
+	terminatedUTC, err := nullableSortableTime(entity.Terminated)
+	if err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] %v", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO entities (id, name, major_kind, minor_kind, created, created_utc, terminated, terminated_utc)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		entity.Id, name, entity.Kind.Major, entity.Kind.Minor, entity.Created, createdUTC, nullableString(entity.Terminated), terminatedUTC)
+	if err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] error creating entity: %v", err)
+	}
+	if inserted, _ := result.RowsAffected(); inserted == 0 {
+		logging.FromContext(ctx).WarnContext(ctx, "Entity already exists", "entity_id", entity.Id)
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityCreation] entity with Id %s already exists", entity.Id)
+	}
+	return true, nil
+}
+
+// HandleGraphEntityUpdate updates the name and termination time of an existing entity.
+// As with the Neo4j repository the kind cannot be changed and the created time is kept.
+func (r *GraphRepository) HandleGraphEntityUpdate(ctx context.Context, entity *pb.Entity) (bool, error) {
+	if entity.Id == "" {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] entity ID is required")
+	}
+	if entity.Kind != nil && (entity.Kind.Major != "" || entity.Kind.Minor != "") {
+		logging.FromContext(ctx).WarnContext(ctx, "Kind of an entity cannot be updated", "entity_id", entity.Id)
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] Kind cannot be updated")
+	}
+
+	var assignments []string
+	var args []interface{}
+	if entity.Name.GetValue() != nil {
+		name, err := entityName(entity)
+		if err != nil {
+			return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] %v", err)
+		}
+		assignments = append(assignments, "name = ?")
+		args = append(args, name)
+	}
+	if entity.Terminated != "" {
+		terminatedUTC, err := sortableTime(entity.Terminated)
+		if err != nil {
+			return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] %v", err)
+		}
+		assignments = append(assignments, "terminated = ?", "terminated_utc = ?")
+		args = append(args, entity.Terminated, terminatedUTC)
+	}
+
+	if _, err := readEntity(ctx, r.db, entity.Id); err == sql.ErrNoRows {
+		return false, fmt.Errorf("entity with Id %s does not exist", entity.Id)
+	} else if err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] error reading entity: %v", err)
+	}
+	if len(assignments) == 0 {
+		return true, nil
+	}
+
+	args = append(args, entity.Id)
+	if _, err := r.db.ExecContext(ctx, "UPDATE entities SET "+strings.Join(assignments, ", ")+" WHERE id = ?", args...); err != nil {
+		return false, fmt.Errorf("[sqlite_graph.HandleGraphEntityUpdate] error updating entity: %v", err)
+	}
+	return true, nil
+}
+
+// HandleGraphEntityFilter returns the entities matching a ReadEntityRequest.
+// The returned maps use the same keys as the Neo4j repository, ordered by entity ID.
+func (r *GraphRepository) HandleGraphEntityFilter(ctx context.Context, req *pb.ReadEntityRequest) ([]map[string]interface{}, error) {
+	if req == nil || req.Entity == nil {
+		return nil, fmt.Errorf("invalid request: ReadEntityRequest or Entity is nil")
+	}
+	filter := req.Entity
+	if filter.Id == "" && filter.Kind.GetMajor() == "" {
+		return nil, fmt.Errorf("kind.Major is required")
+	}
+
+	var conditions []string
+	var args []interface{}
+	if filter.Id != "" {
+		conditions = append(conditions, "id = ?")
+		args = append(args, filter.Id)
+	} else {
+		conditions = append(conditions, "major_kind = ?")
+		args = append(args, filter.Kind.Major)
+		if filter.Kind.Minor != "" {
+			conditions = append(conditions, "minor_kind = ?")
+			args = append(args, filter.Kind.Minor)
+		}
+		if filter.Created != "" {
+			createdUTC, err := sortableTime(filter.Created)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, "created_utc = ?")
+			args = append(args, createdUTC)
+		}
+		if filter.Terminated != "" {
+			terminatedUTC, err := sortableTime(filter.Terminated)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, "terminated_utc = ?")
+			args = append(args, terminatedUTC)
+		}
+		if filter.Name.GetValue() != nil {
+			if name, _ := entityName(filter); name != "" {
+				conditions = append(conditions, "name = ?")
+				args = append(args, name)
+			}
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+entityColumns+" FROM entities WHERE "+strings.Join(conditions, " AND ")+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering entities: %v", err)
+	}
+	defer rows.Close()
+
+	var entities []map[string]interface{}
+	for rows.Next() {
+		entity, err := scanEntity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning entity: %v", err)
+		}
+		var terminated interface{}
+		if entity.Terminated != "" {
+			terminated = entity.Terminated
+		}
+		entities = append(entities, map[string]interface{}{
+			"id":         entity.ID,
+			"kind":       entity.MajorKind,
+			"created":    entity.Created,
+			"terminated": terminated,
+			"name":       entity.Name,
+			"minorKind":  entity.MinorKind,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over entities: %v", err)
+	}
+	return entities, nil
+}
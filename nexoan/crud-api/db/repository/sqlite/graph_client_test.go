@@ -0,0 +1,290 @@
+package sqliterepository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// newTestGraph opens a graph repository in a temporary directory
+func newTestGraph(t *testing.T, rules config.RelationshipIntegrityConfig) *GraphRepository {
+	repo, err := NewGraphRepository(context.Background(), filepath.Join(t.TempDir(), "graph.db"), rules)
+	assert.NoError(t, err)
+	t.Cleanup(func() { repo.Close(context.Background()) })
+	return repo
+}
+
+// newTestEntity creates an entity with the given kind and lifetime
+func newTestEntity(t *testing.T, id string, major string, created string, terminated string) *pb.Entity {
+	name, err := anypb.New(wrapperspb.String(id + " name"))
+	assert.NoError(t, err)
+	return &pb.Entity{
+		Id:         id,
+		Kind:       &pb.Kind{Major: major, Minor: "Test"},
+		Name:       &pb.TimeBasedValue{StartTime: created, Value: name},
+		Created:    created,
+		Terminated: terminated,
+	}
+}
+
+// createTestEntities creates the given entities in the repository
+func createTestEntities(t *testing.T, repo *GraphRepository, entities ...*pb.Entity) {
+	for _, entity := range entities {
+		success, err := repo.HandleGraphEntityCreation(context.Background(), entity)
+		assert.NoError(t, err)
+		assert.True(t, success)
+	}
+}
+
+// createTestRelationship creates a relationship between two existing entities
+func createTestRelationship(repo *GraphRepository, id string, name string, parentID string, childID string, start string, end string) error {
+	return repo.HandleGraphRelationshipsCreate(context.Background(), &pb.Entity{
+		Id: parentID,
+		Relationships: map[string]*pb.Relationship{
+			id: {Id: id, Name: name, RelatedEntityId: childID, StartTime: start, EndTime: end},
+		},
+	})
+}
+
+func TestGraphRepositoryEntities(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestGraph(t, config.RelationshipIntegrityConfig{})
+	createTestEntities(t, repo,
+		newTestEntity(t, "person-2", "Person", "2021-01-01T00:00:00Z", ""),
+		newTestEntity(t, "person-1", "Person", "2020-01-01T05:30:00+05:30", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", ""),
+	)
+
+	_, err := repo.HandleGraphEntityCreation(ctx, newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""))
+	assert.Error(t, err)
+
+	kind, name, created, _, err := repo.GetGraphEntity(ctx, "person-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Person", kind.Major)
+	assert.Equal(t, "2020-01-01T05:30:00+05:30", created)
+	var nameValue wrapperspb.StringValue
+	assert.NoError(t, name.Value.UnmarshalTo(&nameValue))
+	assert.Equal(t, "person-1 name", nameValue.Value)
+
+	_, err = repo.HandleGraphEntityUpdate(ctx, &pb.Entity{Id: "person-1", Kind: &pb.Kind{Major: "Organisation"}})
+	assert.Error(t, err)
+	_, err = repo.HandleGraphEntityUpdate(ctx, &pb.Entity{Id: "missing", Terminated: "2022-01-01T00:00:00Z"})
+	assert.Error(t, err)
+	success, err := repo.HandleGraphEntityUpdate(ctx, &pb.Entity{Id: "person-1", Terminated: "2022-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.True(t, success)
+
+	// Timestamps with different offsets match when they describe the same instant
+	entities, err := repo.HandleGraphEntityFilter(ctx, &pb.ReadEntityRequest{
+		Entity: &pb.Entity{Kind: &pb.Kind{Major: "Person"}, Created: "2020-01-01T00:00:00Z"},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, entities, 1)
+	assert.Equal(t, "person-1", entities[0]["id"])
+	assert.Equal(t, "2022-01-01T00:00:00Z", entities[0]["terminated"])
+
+	entities, err = repo.HandleGraphEntityFilter(ctx, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "Person"}}})
+	assert.NoError(t, err)
+	assert.Len(t, entities, 2)
+}
+
+func TestGraphRepositoryRelationships(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestGraph(t, config.RelationshipIntegrityConfig{})
+	createTestEntities(t, repo,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", ""),
+	)
+
+	role, err := anypb.New(wrapperspb.String("engineer"))
+	assert.NoError(t, err)
+	level, err := anypb.New(wrapperspb.Int64(3))
+	assert.NoError(t, err)
+	err = repo.HandleGraphRelationshipsCreate(ctx, &pb.Entity{
+		Id: "person-1",
+		Relationships: map[string]*pb.Relationship{"rel-1": {
+			Id: "rel-1", Name: "WORKS_AT", RelatedEntityId: "org-1", StartTime: "2020-06-01T00:00:00Z",
+			Properties: map[string]*anypb.Any{"role": role, "level": level},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Error(t, createTestRelationship(repo, "rel-2", "WORKS_AT", "person-1", "missing", "2020-06-01T00:00:00Z", ""))
+
+	relationship, err := repo.GetGraphRelationship(ctx, "rel-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "org-1", relationship.Relationship.RelatedEntityId)
+	var levelValue wrapperspb.Int64Value
+	assert.NoError(t, relationship.Relationship.Properties["level"].UnmarshalTo(&levelValue))
+	assert.Equal(t, int64(3), levelValue.Value)
+
+	var notFoundErr *relationships.NotFoundError
+	_, err = repo.GetGraphRelationship(ctx, "missing")
+	assert.True(t, errors.As(err, &notFoundErr))
+
+	incoming, err := repo.GetFilteredRelationships(ctx, "org-1", "", "", "", "", "", "INCOMING", map[string]*anypb.Any{"role": role}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "person-1", incoming["rel-1"].RelatedEntityId)
+	outgoing, err := repo.GetFilteredRelationships(ctx, "org-1", "", "", "", "", "", "OUTGOING", nil, "")
+	assert.NoError(t, err)
+	assert.Empty(t, outgoing)
+
+	// Updating the properties keeps the existing ones
+	manager, err := anypb.New(wrapperspb.String("manager"))
+	assert.NoError(t, err)
+	err = repo.HandleGraphRelationshipsUpdate(ctx, &pb.Entity{
+		Id: "person-1",
+		Relationships: map[string]*pb.Relationship{"rel-1": {
+			Id: "rel-1", EndTime: "2021-01-01T00:00:00Z", Properties: map[string]*anypb.Any{"role": manager},
+		}},
+	})
+	assert.NoError(t, err)
+	relationship, err = repo.GetGraphRelationship(ctx, "rel-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "2021-01-01T00:00:00Z", relationship.Relationship.EndTime)
+	assert.Contains(t, relationship.Relationship.Properties, "level")
+
+	active, err := repo.GetRelationshipsByEndpoints(ctx, "person-1", "", "", "2022-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Empty(t, active)
+	active, err = repo.GetRelationshipsByEndpoints(ctx, "", "org-1", "WORKS_AT", "2020-07-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Len(t, active, 1)
+
+	assert.NoError(t, repo.DeleteRelationship(ctx, "rel-1"))
+	assert.Error(t, repo.DeleteRelationship(ctx, "rel-1"))
+}
+
+func TestGraphRepositoryIntegrityRules(t *testing.T) {
+	repo := newTestGraph(t, config.RelationshipIntegrityConfig{
+		EnforceEndpointLifetimes: true,
+		NonOverlappingTypes:      []string{"WORKS_AT"},
+		SingleValuedTypes:        []string{"LIVES_IN"},
+	})
+	createTestEntities(t, repo,
+		newTestEntity(t, "person-1", "Person", "2020-01-01T00:00:00Z", ""),
+		newTestEntity(t, "org-1", "Organisation", "2020-01-01T00:00:00Z", "2025-01-01T00:00:00Z"),
+		newTestEntity(t, "city-1", "City", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "city-2", "City", "2000-01-01T00:00:00Z", ""),
+	)
+
+	var integrityErr *relationships.IntegrityError
+
+	err := createTestRelationship(repo, "rel-1", "WORKS_AT", "person-1", "org-1", "2019-01-01T00:00:00Z", "2021-01-01T00:00:00Z")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	err = createTestRelationship(repo, "rel-1", "WORKS_AT", "person-1", "org-1", "2021-01-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.EndpointLifetimeRule, integrityErr.Rule)
+
+	assert.NoError(t, createTestRelationship(repo, "rel-1", "WORKS_AT", "person-1", "org-1", "2021-01-01T00:00:00Z", "2022-01-01T00:00:00Z"))
+	err = createTestRelationship(repo, "rel-2", "WORKS_AT", "person-1", "org-1", "2021-06-01T00:00:00Z", "2023-01-01T00:00:00Z")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.NonOverlappingRule, integrityErr.Rule)
+	assert.NoError(t, createTestRelationship(repo, "rel-2", "WORKS_AT", "person-1", "org-1", "2022-01-01T00:00:00Z", "2023-01-01T00:00:00Z"))
+
+	assert.NoError(t, createTestRelationship(repo, "rel-3", "LIVES_IN", "person-1", "city-1", "2020-01-01T00:00:00Z", ""))
+	err = createTestRelationship(repo, "rel-4", "LIVES_IN", "person-1", "city-2", "2021-01-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.SingleValuedRule, integrityErr.Rule)
+
+	// A rejected update leaves the relationship unchanged
+	_, err = repo.UpdateRelationship(context.Background(), "rel-2", map[string]interface{}{"Created": "2021-06-01T00:00:00Z"})
+	assert.True(t, errors.As(err, &integrityErr))
+	relationship, err := repo.GetGraphRelationship(context.Background(), "rel-2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2022-01-01T00:00:00Z", relationship.Relationship.StartTime)
+}
+
+func TestGraphRepositoryCardinality(t *testing.T) {
+	repo := newTestGraph(t, config.RelationshipIntegrityConfig{})
+	assert.NoError(t, repo.RelationshipTypes().Load([]*pb.RelationshipType{{
+		Name:        "MARRIED_TO",
+		Cardinality: pb.Cardinality_ONE_TO_ONE,
+		Direction:   pb.RelationshipDirection_UNDIRECTED,
+	}}))
+	createTestEntities(t, repo,
+		newTestEntity(t, "person-1", "Person", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "person-2", "Person", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "person-3", "Person", "2000-01-01T00:00:00Z", ""),
+	)
+
+	assert.NoError(t, createTestRelationship(repo, "rel-1", "MARRIED_TO", "person-1", "person-2", "2020-01-01T00:00:00Z", ""))
+
+	// person-2 is already married in the other direction
+	var integrityErr *relationships.IntegrityError
+	err := createTestRelationship(repo, "rel-2", "MARRIED_TO", "person-2", "person-3", "2021-01-01T00:00:00Z", "")
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, relationships.CardinalityRule, integrityErr.Rule)
+}
+
+func TestGraphRepositoryRelatedEntities(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestGraph(t, config.RelationshipIntegrityConfig{})
+	createTestEntities(t, repo,
+		newTestEntity(t, "ministry-1", "Organisation", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "department-1", "Organisation", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "unit-1", "Organisation", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "office-1", "Organisation", "2000-01-01T00:00:00Z", ""),
+		newTestEntity(t, "office-2", "Organisation", "2000-01-01T00:00:00Z", ""),
+	)
+	// ministry-1 -> department-1 -> unit-1 -> office-1, with a cycle back to the ministry
+	// and a shortcut to office-2 that ended before 2022
+	assert.NoError(t, createTestRelationship(repo, "rel-1", "HAS", "ministry-1", "department-1", "2020-01-01T00:00:00Z", ""))
+	assert.NoError(t, createTestRelationship(repo, "rel-2", "HAS", "department-1", "unit-1", "2020-01-01T00:00:00Z", ""))
+	assert.NoError(t, createTestRelationship(repo, "rel-3", "HAS", "unit-1", "office-1", "2021-01-01T00:00:00Z", ""))
+	assert.NoError(t, createTestRelationship(repo, "rel-4", "REPORTS_TO", "office-1", "ministry-1", "2020-01-01T00:00:00Z", ""))
+	assert.NoError(t, createTestRelationship(repo, "rel-5", "HAS", "department-1", "office-2", "2020-01-01T00:00:00Z", "2022-01-01T00:00:00Z"))
+	assert.NoError(t, createTestRelationship(repo, "rel-6", "HAS", "unit-1", "office-2", "2020-01-01T00:00:00Z", ""))
+
+	ids := func(related []*RelatedEntity) []string {
+		result := make([]string, len(related))
+		for i, entity := range related {
+			result[i] = entity.EntityID
+		}
+		return result
+	}
+
+	related, err := repo.GetRelatedEntities(ctx, "ministry-1", "HAS", "OUTGOING", 3, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"department-1", "office-2", "unit-1", "office-1"}, ids(related))
+	assert.Equal(t, []string{"rel-1", "rel-5"}, related[1].RelationshipIDs)
+	assert.Equal(t, 3, related[3].Hops())
+	assert.Equal(t, []string{"rel-1", "rel-2", "rel-3"}, related[3].RelationshipIDs)
+
+	// Every relationship of the path must be active, office-2 is then reached in three hops
+	related, err = repo.GetRelatedEntities(ctx, "ministry-1", "HAS", "OUTGOING", 3, "2023-01-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"department-1", "unit-1", "office-1", "office-2"}, ids(related))
+	assert.Equal(t, []string{"rel-1", "rel-2", "rel-6"}, related[3].RelationshipIDs)
+	related, err = repo.GetRelatedEntities(ctx, "ministry-1", "HAS", "OUTGOING", 3, "2020-06-01T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"department-1", "office-2", "unit-1"}, ids(related))
+
+	// The hop limit is respected and the cycle through the start entity is not followed
+	related, err = repo.GetRelatedEntities(ctx, "ministry-1", "HAS", "OUTGOING", 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"department-1"}, ids(related))
+	related, err = repo.GetRelatedEntities(ctx, "ministry-1", "", "", 5, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"department-1", "office-1", "office-2", "unit-1"}, ids(related))
+	assert.Equal(t, []string{"rel-4"}, related[1].RelationshipIDs, "Expected office-1 to be reached through the incoming relationship")
+
+	related, err = repo.GetRelatedEntities(ctx, "office-1", "", "INCOMING", 4, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unit-1", "department-1", "ministry-1"}, ids(related))
+
+	_, err = repo.GetRelatedEntities(ctx, "ministry-1", "", "SIDEWAYS", 2, "")
+	assert.Error(t, err)
+	_, err = repo.GetRelatedEntities(ctx, "ministry-1", "", "", 0, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package sqliterepository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+	"lk/datafoundation/crud-api/pkg/logging"
+)
+
+// CreateIndexes is a no-op since entity IDs are the primary key of the entities table
+func (r *GraphRepository) CreateIndexes(ctx context.Context) error {
+	return nil
+}
+
+// indexStatement returns the name PostgreSQL gives the index on the given columns of a table and the statement
+// creating it, which does nothing when the index exists
+func indexStatement(tableName string, columns []string) (string, string) {
+	name := postgres.IndexName(tableName, columns)
+	return name, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)",
+		name, quoteIdentifier(tableName), strings.Join(quoteIdentifiers(columns), ", "))
+}
+
+// CreateIndex creates an index on the given columns of an attribute table and returns its name
+func (r *TabularRepository) CreateIndex(ctx context.Context, tableName string, columns []string) (string, error) {
+	name, statement := indexStatement(tableName, columns)
+	if _, err := r.db.ExecContext(ctx, statement); err != nil {
+		return "", fmt.Errorf("error creating index on %s: %v", tableName, err)
+	}
+	return name, nil
+}
+
+// CreateIndexes creates the attribute index on the attribute tables created before it was added, it is called
+// once at startup
+func (r *TabularRepository) CreateIndexes(ctx context.Context) error {
+	if err := r.InitializeTables(ctx); err != nil {
+		return err
+	}
+	tables, err := r.attributeTables(ctx)
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'index'`)
+	if err != nil {
+		return fmt.Errorf("error querying indexes: %v", err)
+	}
+	defer rows.Close()
+	indexes := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("error scanning index name: %v", err)
+		}
+		indexes[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating indexes: %v", err)
+	}
+
+	created := 0
+	for _, table := range tables {
+		if indexes[postgres.IndexName(table.TableName, postgres.AttributeIndexColumns)] {
+			continue
+		}
+		if _, err := r.CreateIndex(ctx, table.TableName, postgres.AttributeIndexColumns); err != nil {
+			return err
+		}
+		indexes[postgres.IndexName(table.TableName, postgres.AttributeIndexColumns)] = true
+		created++
+	}
+	if created > 0 {
+		logging.FromContext(ctx).InfoContext(ctx, "Indexed existing attribute tables", "tables", created)
+	}
+	return nil
+}
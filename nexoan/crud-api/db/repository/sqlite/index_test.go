@@ -0,0 +1,59 @@
+package sqliterepository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTabularRepositoryIndexes(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.NoError(t, repo.CreateIndexes(ctx))
+
+	readIndexes := func() []string {
+		rows, err := repo.db.QueryContext(ctx,
+			`SELECT name FROM sqlite_master WHERE type = 'index' AND tbl_name = 'attr_org_2d1__units' AND sql IS NOT NULL ORDER BY name`)
+		assert.NoError(t, err)
+		defer rows.Close()
+		var names []string
+		for rows.Next() {
+			var name string
+			assert.NoError(t, rows.Scan(&name))
+			names = append(names, name)
+		}
+		return names
+	}
+
+	// New tables are indexed by attribute and write time
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"unit", "staff"},
+		[]interface{}{[]interface{}{"Records", 3}})
+	assert.NoError(t, repo.HandleTabularData(ctx, "org-1", "units", value, schemaInfo))
+	attributeIndex := postgres.IndexName("attr_org_2d1__units", postgres.AttributeIndexColumns)
+	assert.Equal(t, []string{attributeIndex}, readIndexes())
+
+	// Tables created before the attribute index are indexed at startup
+	_, err = repo.db.ExecContext(ctx, "DROP INDEX "+attributeIndex)
+	assert.NoError(t, err)
+	assert.Empty(t, readIndexes())
+	assert.NoError(t, repo.CreateIndexes(ctx))
+	assert.Equal(t, []string{attributeIndex}, readIndexes())
+
+	// Secondary indexes are created once
+	name, err := repo.CreateIndex(ctx, "attr_org_2d1__units", []string{"unit", "staff"})
+	assert.NoError(t, err)
+	again, err := repo.CreateIndex(ctx, "attr_org_2d1__units", []string{"unit", "staff"})
+	assert.NoError(t, err)
+	assert.Equal(t, name, again)
+	assert.ElementsMatch(t, []string{attributeIndex, name}, readIndexes())
+
+	_, err = repo.CreateIndex(ctx, "attr_org_2d1__units", []string{"missing"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,708 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/cypher"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// graphRelationship is a relationship row
+type graphRelationship struct {
+	ID         string
+	Type       string
+	SourceID   string
+	TargetID   string
+	Created    string
+	Terminated string // empty when the relationship is open ended
+	Properties map[string]interface{}
+}
+
+// relationshipColumns are the columns scanned by scanRelationship
+const relationshipColumns = "id, type, source_id, target_id, created, COALESCE(terminated, ''), properties"
+
+// storedProperty keeps the Go type of a relationship property so that it survives the JSON encoding
+type storedProperty struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// encodeProperties converts relationship properties into the JSON stored in the properties column
+func encodeProperties(properties map[string]interface{}) (string, error) {
+	stored := make(map[string]storedProperty, len(properties))
+	for key, value := range properties {
+		switch value.(type) {
+		case string:
+			stored[key] = storedProperty{Type: "string", Value: value}
+		case bool:
+			stored[key] = storedProperty{Type: "bool", Value: value}
+		case int64:
+			stored[key] = storedProperty{Type: "int64", Value: value}
+		case float64:
+			stored[key] = storedProperty{Type: "float64", Value: value}
+		default:
+			return "", fmt.Errorf("unsupported type %T for relationship property '%s'", value, key)
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("error encoding relationship properties: %v", err)
+	}
+	return string(data), nil
+}
+
+// decodeProperties converts the stored JSON back into relationship properties
+func decodeProperties(data string) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var stored map[string]storedProperty
+	if err := decoder.Decode(&stored); err != nil {
+		return nil, fmt.Errorf("error decoding relationship properties: %v", err)
+	}
+
+	properties := make(map[string]interface{}, len(stored))
+	for key, property := range stored {
+		number, isNumber := property.Value.(json.Number)
+		switch {
+		case property.Type == "int64" && isNumber:
+			value, err := number.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("error decoding relationship property '%s': %v", key, err)
+			}
+			properties[key] = value
+		case property.Type == "float64" && isNumber:
+			value, err := number.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("error decoding relationship property '%s': %v", key, err)
+			}
+			properties[key] = value
+		default:
+			properties[key] = property.Value
+		}
+	}
+	return properties, nil
+}
+
+// scanRelationship scans a row selected with relationshipColumns
+func scanRelationship(row rowScanner) (*graphRelationship, error) {
+	var rel graphRelationship
+	var properties string
+	if err := row.Scan(&rel.ID, &rel.Type, &rel.SourceID, &rel.TargetID, &rel.Created, &rel.Terminated, &properties); err != nil {
+		return nil, err
+	}
+	var err error
+	if rel.Properties, err = decodeProperties(properties); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// queryRelationships runs a query selecting relationshipColumns
+func queryRelationships(ctx context.Context, q queryer, query string, args ...interface{}) ([]*graphRelationship, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationships: %v", err)
+	}
+	defer rows.Close()
+
+	var relationships []*graphRelationship
+	for rows.Next() {
+		rel, err := scanRelationship(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning relationship: %v", err)
+		}
+		relationships = append(relationships, rel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over relationships: %v", err)
+	}
+	return relationships, nil
+}
+
+// readRelationship reads a relationship row, sql.ErrNoRows is returned when it does not exist
+func readRelationship(ctx context.Context, q queryer, relationshipID string) (*graphRelationship, error) {
+	return scanRelationship(q.QueryRowContext(ctx, "SELECT "+relationshipColumns+" FROM relationships WHERE id = ?", relationshipID))
+}
+
+// toEntityRelationship converts a relationship into its protobuf form together with its source entity
+func (rel *graphRelationship) toEntityRelationship() *pb.EntityRelationship {
+	return &pb.EntityRelationship{
+		EntityId: rel.SourceID,
+		Relationship: &pb.Relationship{
+			Id:              rel.ID,
+			Name:            rel.Type,
+			RelatedEntityId: rel.TargetID,
+			StartTime:       rel.Created,
+			EndTime:         rel.Terminated,
+			Direction:       "OUTGOING",
+			Properties:      relationships.ConvertStoredProperties(rel.Properties),
+		},
+	}
+}
+
+// inTransaction runs fn in a transaction, committing it if fn succeeds
+func (r *GraphRepository) inTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %v", err)
+	}
+	return nil
+}
+
+// validateNewRelationship checks the fields required to create a relationship
+func validateNewRelationship(relationship *pb.Relationship) error {
+	if relationship.RelatedEntityId == "" {
+		return fmt.Errorf("missing RelatedEntityId for relationship %s. Required for creation", relationship.Id)
+	}
+	if relationship.Name == "" {
+		return fmt.Errorf("missing Name for relationship %s. Required for creation", relationship.Id)
+	}
+	if err := cypher.ValidateIdentifier(relationship.Name); err != nil {
+		return err
+	}
+	if relationship.StartTime == "" {
+		return fmt.Errorf("missing StartTime for relationship %s. Required for creation", relationship.Id)
+	}
+	return nil
+}
+
+// insertRelationship stores a relationship, the row is replaced when it already exists.
+// The integrity rules are checked when validate is set.
+func (r *GraphRepository) insertRelationship(ctx context.Context, tx *sql.Tx, rel *graphRelationship, validate bool) error {
+	createdUTC, err := sortableTime(rel.Created)
+	if err != nil {
+		return err
+	}
+	terminatedUTC, err := nullableSortableTime(rel.Terminated)
+	if err != nil {
+		return err
+	}
+	if validate {
+		if err := r.validateRelationshipIntegrity(ctx, tx, rel); err != nil {
+			return err
+		}
+	}
+	properties, err := encodeProperties(rel.Properties)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO relationships (id, type, source_id, target_id, created, created_utc, terminated, terminated_utc, properties)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			created = excluded.created, created_utc = excluded.created_utc,
+			terminated = excluded.terminated, terminated_utc = excluded.terminated_utc,
+			properties = excluded.properties`,
+		rel.ID, rel.Type, rel.SourceID, rel.TargetID, rel.Created, createdUTC, nullableString(rel.Terminated), terminatedUTC, properties)
+	if err != nil {
+		return fmt.Errorf("error storing relationship %s: %v", rel.ID, err)
+	}
+	return nil
+}
+
+// createRelationship creates a new relationship starting at parent
+func (r *GraphRepository) createRelationship(ctx context.Context, tx *sql.Tx, parent *graphEntity, rel *pb.Relationship) error {
+	child, err := readEntity(ctx, tx, rel.RelatedEntityId)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("[sqlite_graph.createRelationship] child entity %s does not exist", rel.RelatedEntityId)
+	}
+	if err != nil {
+		return fmt.Errorf("[sqlite_graph.createRelationship] error reading child entity: %v", err)
+	}
+
+	// Check the relationship against the declared relationship types
+	if err := r.relationshipTypes.Validate(ctx, rel, parent.kind(), child.kind()); err != nil {
+		logging.FromContext(ctx).DebugContext(ctx, "Relationship rejected", "relationship_id", rel.Id, "error", err)
+		return err
+	}
+
+	if _, err := readRelationship(ctx, tx, rel.Id); err == nil {
+		return fmt.Errorf("relationship with Id %s already exists", rel.Id)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("error reading relationship %s: %v", rel.Id, err)
+	}
+	properties, err := relationships.ConvertProperties(rel.Properties)
+	if err != nil {
+		return fmt.Errorf("invalid relationship properties: %w", err)
+	}
+
+	return r.insertRelationship(ctx, tx, &graphRelationship{
+		ID:         rel.Id,
+		Type:       rel.Name,
+		SourceID:   parent.ID,
+		TargetID:   child.ID,
+		Created:    rel.StartTime,
+		Terminated: rel.EndTime,
+		Properties: properties,
+	}, true)
+}
+
+// readParentEntity reads the entity whose relationships are being created or updated
+func readParentEntity(ctx context.Context, tx *sql.Tx, entityID string, caller string) (*graphEntity, error) {
+	parent, err := readEntity(ctx, tx, entityID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("[sqlite_graph.%s] parent entity %s does not exist", caller, entityID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("[sqlite_graph.%s] error reading parent entity: %v", caller, err)
+	}
+	return parent, nil
+}
+
+// HandleGraphRelationshipsCreate creates the relationships of an entity
+func (r *GraphRepository) HandleGraphRelationshipsCreate(ctx context.Context, entity *pb.Entity) error {
+	if len(entity.Relationships) == 0 {
+		return nil
+	}
+
+	return r.inTransaction(ctx, func(tx *sql.Tx) error {
+		parent, err := readParentEntity(ctx, tx, entity.Id, "HandleGraphRelationshipsCreate")
+		if err != nil {
+			return err
+		}
+
+		for _, relationship := range entity.Relationships {
+			if relationship == nil || relationship.Id == "" {
+				return fmt.Errorf("relationship missing ID field")
+			}
+			if err := validateNewRelationship(relationship); err != nil {
+				return err
+			}
+			if err := r.createRelationship(ctx, tx, parent, relationship); err != nil {
+				logging.FromContext(ctx).ErrorContext(ctx, "Error creating relationship", "entity_id", entity.Id,
+					"related_entity_id", relationship.RelatedEntityId, "error", err)
+				return fmt.Errorf("[sqlite_graph.HandleGraphRelationshipsCreate] error creating relationship: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// HandleGraphRelationshipsUpdate updates the existing relationships of an entity and creates the new ones.
+// Only the start time, end time and properties of an existing relationship can be updated.
+func (r *GraphRepository) HandleGraphRelationshipsUpdate(ctx context.Context, entity *pb.Entity) error {
+	if len(entity.Relationships) == 0 {
+		return nil
+	}
+
+	return r.inTransaction(ctx, func(tx *sql.Tx) error {
+		parent, err := readParentEntity(ctx, tx, entity.Id, "HandleGraphRelationshipsUpdate")
+		if err != nil {
+			return err
+		}
+
+		for _, relationship := range entity.Relationships {
+			if relationship == nil || relationship.Id == "" {
+				return fmt.Errorf("relationship missing ID field")
+			}
+
+			if _, err := readRelationship(ctx, tx, relationship.Id); err == sql.ErrNoRows {
+				if err := validateNewRelationship(relationship); err != nil {
+					return err
+				}
+				if err := r.createRelationship(ctx, tx, parent, relationship); err != nil {
+					return fmt.Errorf("[sqlite_graph.HandleGraphRelationshipsUpdate] failed to create relationship: %w", err)
+				}
+				continue
+			} else if err != nil {
+				return fmt.Errorf("error reading relationship %s: %v", relationship.Id, err)
+			}
+
+			var invalidFields []string
+			if relationship.Name != "" {
+				invalidFields = append(invalidFields, "Name")
+			}
+			if relationship.RelatedEntityId != "" {
+				invalidFields = append(invalidFields, "RelatedEntityId")
+			}
+			if relationship.Direction != "" {
+				invalidFields = append(invalidFields, "Direction")
+			}
+			if len(invalidFields) > 0 {
+				return fmt.Errorf("cannot update immutable fields: %v. Only StartTime, EndTime and Properties are allowed", invalidFields)
+			}
+
+			relationshipData := map[string]interface{}{}
+			if relationship.StartTime != "" {
+				relationshipData["Created"] = relationship.StartTime
+			}
+			if relationship.EndTime != "" {
+				relationshipData["Terminated"] = relationship.EndTime
+			}
+			if len(relationship.Properties) > 0 {
+				properties, err := relationships.ConvertProperties(relationship.Properties)
+				if err != nil {
+					return fmt.Errorf("invalid properties for relationship %s: %w", relationship.Id, err)
+				}
+				relationshipData["Properties"] = properties
+			}
+			if len(relationshipData) == 0 {
+				return fmt.Errorf("no valid fields provided for relationship update. Only StartTime, EndTime and Properties are allowed")
+			}
+
+			if _, err := r.updateRelationship(ctx, tx, relationship.Id, relationshipData); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetGraphRelationships returns the outgoing relationships of an entity keyed by relationship ID
+func (r *GraphRepository) GetGraphRelationships(ctx context.Context, entityId string) (map[string]*pb.Relationship, error) {
+	relationships := make(map[string]*pb.Relationship)
+	if entityId == "" {
+		return relationships, fmt.Errorf("[sqlite_graph.GetGraphRelationships] error reading relationships: entity Id cannot be empty")
+	}
+
+	rels, err := queryRelationships(ctx, r.db, "SELECT "+relationshipColumns+" FROM relationships WHERE source_id = ?", entityId)
+	if err != nil {
+		return relationships, fmt.Errorf("[sqlite_graph.GetGraphRelationships] %v", err)
+	}
+	for _, rel := range rels {
+		relationship := rel.toEntityRelationship().Relationship
+		relationship.Direction = ""
+		relationships[rel.ID] = relationship
+	}
+	return relationships, nil
+}
+
+// GetGraphRelationship retrieves a single relationship and its source entity
+func (r *GraphRepository) GetGraphRelationship(ctx context.Context, relationshipId string) (*pb.EntityRelationship, error) {
+	if relationshipId == "" {
+		return nil, fmt.Errorf("relationship Id cannot be empty")
+	}
+	rel, err := readRelationship(ctx, r.db, relationshipId)
+	if err == sql.ErrNoRows {
+		return nil, &relationships.NotFoundError{RelationshipID: relationshipId}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationship %s: %v", relationshipId, err)
+	}
+	return rel.toEntityRelationship(), nil
+}
+
+// activeAtCondition restricts a query to relationships active at the given instant
+const activeAtCondition = "created_utc <= ? AND (terminated_utc IS NULL OR terminated_utc > ?)"
+
+// GetRelationshipsByEndpoints retrieves the relationships starting at sourceEntityId and/or ending at
+// targetEntityId, ordered by start time and ID
+func (r *GraphRepository) GetRelationshipsByEndpoints(ctx context.Context, sourceEntityId string, targetEntityId string, name string, activeAt string) ([]*pb.EntityRelationship, error) {
+	if sourceEntityId == "" && targetEntityId == "" {
+		return nil, fmt.Errorf("either a source or a target entity Id is required")
+	}
+
+	var conditions []string
+	var args []interface{}
+	if sourceEntityId != "" {
+		conditions = append(conditions, "source_id = ?")
+		args = append(args, sourceEntityId)
+	}
+	if targetEntityId != "" {
+		conditions = append(conditions, "target_id = ?")
+		args = append(args, targetEntityId)
+	}
+	if name != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, name)
+	}
+	if activeAt != "" {
+		activeAtUTC, err := sortableTime(activeAt)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, activeAtCondition)
+		args = append(args, activeAtUTC, activeAtUTC)
+	}
+
+	rels, err := queryRelationships(ctx, r.db,
+		"SELECT "+relationshipColumns+" FROM relationships WHERE "+strings.Join(conditions, " AND ")+" ORDER BY created_utc, id", args...)
+	if err != nil {
+		return nil, err
+	}
+	relationships := make([]*pb.EntityRelationship, 0, len(rels))
+	for _, rel := range rels {
+		relationships = append(relationships, rel.toEntityRelationship())
+	}
+	return relationships, nil
+}
+
+// GetFilteredRelationships retrieves the relationships of an entity matching every given filter
+func (r *GraphRepository) GetFilteredRelationships(ctx context.Context, entityId string, relationshipId string, relationship string, relatedEntityId string, startTime string, endTime string, direction string, properties map[string]*anypb.Any, activeAt string) (map[string]*pb.Relationship, error) {
+	if entityId == "" {
+		return nil, fmt.Errorf("entityId cannot be empty")
+	}
+
+	filters := map[string]interface{}{}
+	if relationshipId != "" {
+		filters["id"] = relationshipId
+	}
+	if relationship != "" {
+		filters["name"] = relationship
+	}
+	if relatedEntityId != "" {
+		filters["relatedEntityId"] = relatedEntityId
+	}
+	if startTime != "" {
+		filters["startTime"] = startTime
+	}
+	if endTime != "" {
+		filters["endTime"] = endTime
+	}
+	if direction != "" {
+		filters["direction"] = direction
+	}
+	if len(properties) > 0 {
+		propertyFilters, err := relationships.ConvertProperties(properties)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relationship property filters: %w", err)
+		}
+		filters["properties"] = propertyFilters
+	}
+
+	relationshipData, err := r.ReadFilteredRelationships(ctx, entityId, filters, activeAt)
+	if err != nil {
+		return nil, err
+	}
+
+	relationshipMap := make(map[string]*pb.Relationship)
+	for _, rel := range relationshipData {
+		relID := rel["id"].(string)
+		relationshipMap[relID] = &pb.Relationship{
+			Id:              relID,
+			Name:            rel["name"].(string),
+			RelatedEntityId: rel["relatedEntityId"].(string),
+			StartTime:       rel["startTime"].(string),
+			EndTime:         rel["endTime"].(string),
+			Direction:       rel["direction"].(string),
+			Properties:      relationships.ConvertStoredProperties(rel["properties"].(map[string]interface{})),
+		}
+	}
+	return relationshipMap, nil
+}
+
+// propertyEquals compares a stored relationship property with a filter value, numbers compare by value
+func propertyEquals(stored interface{}, expected interface{}) bool {
+	toFloat := func(v interface{}) (float64, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+		return 0, false
+	}
+	if a, ok := toFloat(stored); ok {
+		b, ok := toFloat(expected)
+		return ok && a == b
+	}
+	return stored == expected
+}
+
+// ReadFilteredRelationships retrieves the incoming and outgoing relationships of an entity matching the filters.
+// The returned maps use the same keys as the Neo4j repository, ordered by relationship ID.
+func (r *GraphRepository) ReadFilteredRelationships(ctx context.Context, entityID string, relationshipFilters map[string]interface{}, activeAt string) ([]map[string]interface{}, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity Id cannot be empty")
+	}
+
+	// Each relationship is returned once per direction in which it touches the entity
+	var conditions []string
+	var args []interface{}
+	if id, ok := relationshipFilters["id"].(string); ok && id != "" {
+		conditions = append(conditions, "id = ?")
+		args = append(args, id)
+	}
+	if name, ok := relationshipFilters["name"].(string); ok && name != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, name)
+	}
+	if related, ok := relationshipFilters["relatedEntityId"].(string); ok && related != "" {
+		conditions = append(conditions, "related_id = ?")
+		args = append(args, related)
+	}
+	for key, column := range map[string]string{"startTime": "created_utc", "endTime": "terminated_utc"} {
+		if value, ok := relationshipFilters[key].(string); ok && value != "" {
+			valueUTC, err := sortableTime(value)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, column+" = ?")
+			args = append(args, valueUTC)
+		}
+	}
+	if direction, ok := relationshipFilters["direction"].(string); ok && direction != "" {
+		conditions = append(conditions, "direction = ?")
+		args = append(args, direction)
+	}
+	if activeAt != "" {
+		activeAtUTC, err := sortableTime(activeAt)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, activeAtCondition)
+		args = append(args, activeAtUTC, activeAtUTC)
+	}
+
+	query := `
+		WITH touching AS (
+			SELECT *, target_id AS related_id, 'OUTGOING' AS direction FROM relationships WHERE source_id = ?
+			UNION ALL
+			SELECT *, source_id AS related_id, 'INCOMING' AS direction FROM relationships WHERE target_id = ?
+		)
+		SELECT id, type, related_id, created, COALESCE(terminated, ''), direction, properties FROM touching`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY id, direction DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{entityID, entityID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationships: %v", err)
+	}
+	defer rows.Close()
+
+	propertyFilters, _ := relationshipFilters["properties"].(map[string]interface{})
+	var relationships []map[string]interface{}
+	for rows.Next() {
+		var id, relType, relatedID, created, terminated, direction, encodedProperties string
+		if err := rows.Scan(&id, &relType, &relatedID, &created, &terminated, &direction, &encodedProperties); err != nil {
+			return nil, fmt.Errorf("error scanning relationship: %v", err)
+		}
+		properties, err := decodeProperties(encodedProperties)
+		if err != nil {
+			return nil, err
+		}
+
+		matches := true
+		for key, value := range propertyFilters {
+			if !propertyEquals(properties[key], value) {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		relationships = append(relationships, map[string]interface{}{
+			"id":              id,
+			"name":            relType,
+			"relatedEntityId": relatedID,
+			"startTime":       created,
+			"endTime":         terminated,
+			"direction":       direction,
+			"properties":      properties,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over relationships: %v", err)
+	}
+	return relationships, nil
+}
+
+// updateRelationship applies an update to a relationship within a transaction
+func (r *GraphRepository) updateRelationship(ctx context.Context, tx *sql.Tx, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error) {
+	if relationshipID == "" {
+		return nil, fmt.Errorf("relationship Id cannot be empty")
+	}
+	existing, err := readRelationship(ctx, tx, relationshipID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("relationship with Id %s does not exist", relationshipID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationship %s: %v", relationshipID, err)
+	}
+
+	for key := range updateData {
+		if key != "Created" && key != "Terminated" && key != "Properties" {
+			return nil, fmt.Errorf("unsupported field '%s' for relationship update. Only 'Created', 'Terminated' and 'Properties' are allowed", key)
+		}
+	}
+	if len(updateData) == 0 {
+		return nil, fmt.Errorf("no valid fields provided for update")
+	}
+
+	updated := *existing
+	if created, ok := updateData["Created"]; ok {
+		updated.Created = fmt.Sprintf("%v", created)
+	}
+	if terminated, ok := updateData["Terminated"]; ok {
+		updated.Terminated = fmt.Sprintf("%v", terminated)
+	}
+	if properties, ok := updateData["Properties"]; ok {
+		propertiesMap, ok := properties.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid type %T for 'Properties'. Expected map[string]interface{}", properties)
+		}
+		// Existing properties not in the map are kept
+		updated.Properties = make(map[string]interface{}, len(existing.Properties)+len(propertiesMap))
+		for key, value := range existing.Properties {
+			updated.Properties[key] = value
+		}
+		for key, value := range propertiesMap {
+			if relationships.IsReservedProperty(key) {
+				return nil, fmt.Errorf("relationship property '%s' is reserved", key)
+			}
+			updated.Properties[key] = value
+		}
+	}
+
+	// Validate the resulting interval against the integrity rules when the dates change
+	datesChanged := updated.Created != existing.Created || updated.Terminated != existing.Terminated
+	if err := r.insertRelationship(ctx, tx, &updated, datesChanged); err != nil {
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"Id":         updated.ID,
+		"Created":    updated.Created,
+		"properties": updated.Properties,
+	}
+	if updated.Terminated != "" {
+		result["Terminated"] = updated.Terminated
+	}
+	return result, nil
+}
+
+// UpdateRelationship updates the created time, terminated time or properties of a relationship
+func (r *GraphRepository) UpdateRelationship(ctx context.Context, relationshipID string, updateData map[string]interface{}) (map[string]interface{}, error) {
+	var result map[string]interface{}
+	err := r.inTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		result, err = r.updateRelationship(ctx, tx, relationshipID, updateData)
+		return err
+	})
+	return result, err
+}
+
+// DeleteRelationship removes a relationship
+func (r *GraphRepository) DeleteRelationship(ctx context.Context, relationshipID string) error {
+	if relationshipID == "" {
+		return fmt.Errorf("relationship Id cannot be empty")
+	}
+	result, err := r.db.ExecContext(ctx, "DELETE FROM relationships WHERE id = ?", relationshipID)
+	if err != nil {
+		return fmt.Errorf("error deleting relationship %s: %v", relationshipID, err)
+	}
+	if deleted, _ := result.RowsAffected(); deleted == 0 {
+		return fmt.Errorf("relationship with Id %s does not exist", relationshipID)
+	}
+	return nil
+}
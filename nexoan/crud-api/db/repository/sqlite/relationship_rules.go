@@ -0,0 +1,114 @@
+package sqliterepository
+
+import (
+	"context"
+	"fmt"
+
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
+)
+
+// ruleStore looks up the relationships and entities the integrity rules check against in SQLite
+type ruleStore struct {
+	q queryer
+}
+
+// validateRelationshipIntegrity checks the relationship against the integrity rules configured for the repository
+func (r *GraphRepository) validateRelationshipIntegrity(ctx context.Context, q queryer, rel *graphRelationship) error {
+	return relationships.Validate(ctx, r.rules, r.relationshipTypes, ruleStore{q: q}, &relationships.Relationship{
+		ID:         rel.ID,
+		Type:       rel.Type,
+		SourceID:   rel.SourceID,
+		TargetID:   rel.TargetID,
+		Created:    rel.Created,
+		Terminated: rel.Terminated,
+	})
+}
+
+// describeIntervals reads id, created and terminated rows and describes each of them
+func describeIntervals(ctx context.Context, q queryer, query string, args ...interface{}) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error checking relationship conflicts", "error", err)
+		return nil, fmt.Errorf("error checking relationship conflicts: %v", err)
+	}
+	defer rows.Close()
+
+	var intervals []string
+	for rows.Next() {
+		var id, created, terminated string
+		if err := rows.Scan(&id, &created, &terminated); err != nil {
+			return nil, fmt.Errorf("error scanning relationship conflicts: %v", err)
+		}
+		intervals = append(intervals, relationships.DescribeInterval(id, created, terminated))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over relationship conflicts: %v", err)
+	}
+	return intervals, nil
+}
+
+// selectionCondition builds the endpoint condition of a selection, an empty endpoint matches any entity
+func selectionCondition(sel relationships.Selection) (string, []interface{}) {
+	direct := func(sourceColumn string, targetColumn string) (string, []interface{}) {
+		condition, args := "1 = 1", []interface{}{}
+		if sel.SourceID != "" {
+			condition += " AND " + sourceColumn + " = ?"
+			args = append(args, sel.SourceID)
+		}
+		if sel.TargetID != "" {
+			condition += " AND " + targetColumn + " = ?"
+			args = append(args, sel.TargetID)
+		}
+		return condition, args
+	}
+	condition, args := direct("source_id", "target_id")
+	if !sel.EitherDirection {
+		return condition, args
+	}
+	swapped, swappedArgs := direct("target_id", "source_id")
+	return "((" + condition + ") OR (" + swapped + "))", append(args, swappedArgs...)
+}
+
+// Overlapping describes the other relationships of the same type selected by sel that overlap rel in time
+func (s ruleStore) Overlapping(ctx context.Context, rel *relationships.Relationship, sel relationships.Selection) ([]string, error) {
+	startUTC, err := sortableTime(rel.Created)
+	if err != nil {
+		return nil, err
+	}
+	endUTC, err := nullableSortableTime(rel.Terminated)
+	if err != nil {
+		return nil, err
+	}
+
+	endpointCondition, endpointArgs := selectionCondition(sel)
+	query := `
+		SELECT id, created, COALESCE(terminated, '') FROM relationships
+		WHERE type = ? AND id <> ?
+		  AND (? IS NULL OR created_utc < ?)
+		  AND (terminated_utc IS NULL OR terminated_utc > ?)
+		  AND ` + endpointCondition + `
+		ORDER BY id`
+	args := append([]interface{}{rel.Type, rel.ID, endUTC, endUTC, startUTC}, endpointArgs...)
+	return describeIntervals(ctx, s.q, query, args...)
+}
+
+// EndpointsOutside describes the endpoints of rel whose lifetime does not cover the relationship
+func (s ruleStore) EndpointsOutside(ctx context.Context, rel *relationships.Relationship) ([]string, error) {
+	startUTC, err := sortableTime(rel.Created)
+	if err != nil {
+		return nil, err
+	}
+	endUTC, err := nullableSortableTime(rel.Terminated)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeIntervals(ctx, s.q, `
+		SELECT id, created, COALESCE(terminated, '') FROM entities
+		WHERE id IN (?, ?)
+		  AND (created_utc > ?
+		       OR (terminated_utc IS NOT NULL AND (? IS NULL OR terminated_utc < ?)))
+		ORDER BY id`,
+		rel.SourceID, rel.TargetID, startUTC, endUTC, endUTC)
+}
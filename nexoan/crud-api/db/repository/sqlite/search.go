@@ -0,0 +1,105 @@
+package sqliterepository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+	"lk/datafoundation/crud-api/pkg/search"
+)
+
+// CreateSearchIndexes is a no-op since names are matched after their accents are removed, which SQLite cannot index
+func (r *GraphRepository) CreateSearchIndexes(ctx context.Context) error {
+	return nil
+}
+
+// SearchEntityNames returns the entities of the query kinds whose name contains every term of the query, ordered by ID
+func (r *GraphRepository) SearchEntityNames(ctx context.Context, query search.Query) ([]search.Match, error) {
+	var conditions []string
+	var args []interface{}
+	for _, kind := range query.Kinds {
+		if kind.GetMinor() == "" {
+			conditions = append(conditions, "major_kind = ?")
+			args = append(args, kind.GetMajor())
+		} else {
+			conditions = append(conditions, "(major_kind = ? AND minor_kind = ?)")
+			args = append(args, kind.GetMajor(), kind.GetMinor())
+		}
+	}
+	statement := "SELECT id, name FROM entities"
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " OR ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, statement+" ORDER BY id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("error searching entity names: %v", err)
+	}
+	defer rows.Close()
+
+	var matches []search.Match
+	for rows.Next() && len(matches) < search.MaxMatches {
+		var entityID, name string
+		if err := rows.Scan(&entityID, &name); err != nil {
+			return nil, fmt.Errorf("error scanning entity name: %v", err)
+		}
+		if query.MatchesText(name) {
+			matches = append(matches, search.Match{EntityID: entityID, Field: search.NameField, Text: name})
+		}
+	}
+	return matches, rows.Err()
+}
+
+// attributeTables returns the tables of every tabular attribute ordered by entity ID and attribute name
+func (r *TabularRepository) attributeTables(ctx context.Context) ([]postgres.AttributeTable, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT entity_id, attribute_name, table_name FROM entity_attributes ORDER BY entity_id, attribute_name`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attribute tables: %v", err)
+	}
+	defer rows.Close()
+
+	var tables []postgres.AttributeTable
+	for rows.Next() {
+		var table postgres.AttributeTable
+		if err := rows.Scan(&table.EntityID, &table.AttrName, &table.TableName); err != nil {
+			return nil, fmt.Errorf("error scanning attribute table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	return tables, rows.Err()
+}
+
+// DistinctValues returns the distinct non null values of a column
+func (r *TabularRepository) DistinctValues(ctx context.Context, tableName, column string) ([]string, error) {
+	statement := fmt.Sprintf("SELECT DISTINCT CAST(%s AS TEXT) FROM %s WHERE %s IS NOT NULL ORDER BY 1",
+		quoteIdentifier(column), quoteIdentifier(tableName), quoteIdentifier(column))
+	rows, err := r.db.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, fmt.Errorf("error querying values of %s.%s: %v", tableName, column, err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, fmt.Errorf("error scanning value of %s.%s: %v", tableName, column, err)
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// SearchTabular returns the string cells of tabular attributes that contain every term of the query
+func (r *TabularRepository) SearchTabular(ctx context.Context, query search.Query) ([]search.Match, error) {
+	if err := r.InitializeTables(ctx); err != nil {
+		return nil, err
+	}
+	tables, err := r.attributeTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.SearchTables(ctx, r, tables, query)
+}
@@ -0,0 +1,57 @@
+package sqliterepository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	query, err := search.NewQuery("Défence ministry", []*pb.Kind{{Major: "Organisation", Minor: "Test"}})
+	assert.NoError(t, err)
+
+	graph := newTestGraph(t, config.RelationshipIntegrityConfig{})
+	assert.NoError(t, graph.CreateSearchIndexes(ctx))
+	for id, name := range map[string]string{"org-1": "MINISTRY OF DEFENCE", "org-2": "Ministry of Finance"} {
+		entity := newTestEntity(t, id, "Organisation", "2024-01-01T00:00:00Z", "")
+		entity.Name.Value, err = anypb.New(wrapperspb.String(name))
+		assert.NoError(t, err)
+		createTestEntities(t, graph, entity)
+	}
+	person := newTestEntity(t, "person-1", "Person", "2024-01-01T00:00:00Z", "")
+	person.Name.Value, err = anypb.New(wrapperspb.String("Defence Ministry Spokesperson"))
+	assert.NoError(t, err)
+	createTestEntities(t, graph, person)
+
+	matches, err := graph.SearchEntityNames(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "org-1", Field: "name", Text: "MINISTRY OF DEFENCE"}}, matches)
+
+	tabular, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+	assert.NoError(t, err)
+	defer tabular.Close()
+	matches, err = tabular.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Empty(t, matches, "Expected no matches before any table is created")
+
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"unit", "staff"},
+		[]interface{}{
+			[]interface{}{"Defence Ministry secretariat", 12},
+			[]interface{}{"Defence Ministry secretariat", 4},
+			[]interface{}{"Records", 3},
+		})
+	assert.NoError(t, tabular.HandleTabularData(ctx, "org-2", "units", value, schemaInfo))
+	matches, err = tabular.SearchTabular(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, []search.Match{{EntityID: "org-2", Field: "attributes.units.unit", Text: "Defence Ministry secretariat"}}, matches)
+}
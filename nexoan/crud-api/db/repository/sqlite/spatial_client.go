@@ -0,0 +1,146 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/geo"
+)
+
+// geometryTimeLayout formats the start and end times of geometries in UTC with a fixed width,
+// so that they are ordered by comparing the text
+const geometryTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// formatGeometryTime formats a start or end time of a geometry, a zero time is stored as an empty string
+func formatGeometryTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(geometryTimeLayout)
+}
+
+// InitializeGeometries creates the attribute_geometries table if it doesn't exist.
+// SQLite has no spatial types, so the GeoJSON is stored as text next to its bounding box.
+// The bounding box index narrows a query down to candidates and the filter is evaluated by the geo package.
+// An attribute keeps a geometry per start time, an empty start time is before any other and an empty end
+// time leaves the geometry open.
+func (r *TabularRepository) InitializeGeometries(ctx context.Context) error {
+	attributeGeometriesSQL := `
+	CREATE TABLE IF NOT EXISTS attribute_geometries (
+		entity_id TEXT NOT NULL,
+		attribute_name TEXT NOT NULL,
+		start_time TEXT NOT NULL,
+		end_time TEXT NOT NULL,
+		geometry TEXT NOT NULL,
+		min_lon REAL NOT NULL,
+		min_lat REAL NOT NULL,
+		max_lon REAL NOT NULL,
+		max_lat REAL NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (entity_id, attribute_name, start_time)
+	);`
+	if _, err := r.db.ExecContext(ctx, attributeGeometriesSQL); err != nil {
+		return fmt.Errorf("error creating attribute_geometries table: %v", err)
+	}
+
+	indexSQL := `CREATE INDEX IF NOT EXISTS attribute_geometries_bounds_idx
+		ON attribute_geometries (attribute_name, min_lon, max_lon, min_lat, max_lat);`
+	if _, err := r.db.ExecContext(ctx, indexSQL); err != nil {
+		return fmt.Errorf("error creating attribute_geometries index: %v", err)
+	}
+	return nil
+}
+
+// SaveGeometry stores the geometry an attribute has from startTime until endTime, replacing the geometry
+// with the same start time
+func (r *TabularRepository) SaveGeometry(ctx context.Context, entityID, attrName string, geometry *geo.Geometry, startTime, endTime time.Time) error {
+	geoJSON, err := json.Marshal(geometry)
+	if err != nil {
+		return fmt.Errorf("error encoding geometry: %v", err)
+	}
+	bounds := geometry.Bounds()
+
+	query := `
+	INSERT INTO attribute_geometries (entity_id, attribute_name, start_time, end_time, geometry, min_lon, min_lat, max_lon, max_lat)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (entity_id, attribute_name, start_time)
+	DO UPDATE SET end_time = excluded.end_time, geometry = excluded.geometry, min_lon = excluded.min_lon,
+		min_lat = excluded.min_lat, max_lon = excluded.max_lon, max_lat = excluded.max_lat, updated_at = CURRENT_TIMESTAMP;`
+	_, err = r.db.ExecContext(ctx, query, entityID, attrName, formatGeometryTime(startTime), formatGeometryTime(endTime),
+		string(geoJSON), bounds.MinLon, bounds.MinLat, bounds.MaxLon, bounds.MaxLat)
+	if err != nil {
+		return fmt.Errorf("error saving geometry of attribute %s: %v", attrName, err)
+	}
+	return nil
+}
+
+// GetGeometry returns the latest geometry of an attribute, the error wraps commons.ErrNotFound when there is none
+func (r *TabularRepository) GetGeometry(ctx context.Context, entityID, attrName string) (*geo.Geometry, error) {
+	var geoJSON string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT geometry FROM attribute_geometries WHERE entity_id = ? AND attribute_name = ? ORDER BY start_time DESC LIMIT 1`,
+		entityID, attrName).Scan(&geoJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error getting geometry of attribute %s: %w", attrName, commons.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting geometry of attribute %s: %v", attrName, err)
+	}
+	return geo.ParseJSON([]byte(geoJSON))
+}
+
+// DeleteGeometry removes every geometry of an attribute
+func (r *TabularRepository) DeleteGeometry(ctx context.Context, entityID, attrName string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM attribute_geometries WHERE entity_id = ? AND attribute_name = ?`, entityID, attrName)
+	if err != nil {
+		return fmt.Errorf("error deleting geometry of attribute %s: %v", attrName, err)
+	}
+	return nil
+}
+
+// FindEntities returns the sorted IDs of the entities whose attribute geometry at activeAt matches the filter.
+// The geometries the entities have at activeAt whose bounding box meets the bounds of the filter are read
+// and matched one by one.
+func (r *TabularRepository) FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	bounds := filter.Bounds()
+	at := formatGeometryTime(activeAt)
+
+	rows, err := r.db.QueryContext(ctx, `
+	SELECT g.entity_id, g.geometry FROM attribute_geometries g
+	WHERE g.attribute_name = ? AND g.min_lon <= ? AND g.max_lon >= ? AND g.min_lat <= ? AND g.max_lat >= ?
+		AND g.start_time <= ? AND (g.end_time = '' OR g.end_time > ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM attribute_geometries later
+			WHERE later.entity_id = g.entity_id AND later.attribute_name = g.attribute_name
+				AND later.start_time > g.start_time AND later.start_time <= ?)
+	ORDER BY g.entity_id`, attrName, bounds.MaxLon, bounds.MinLon, bounds.MaxLat, bounds.MinLat, at, at, at)
+	if err != nil {
+		return nil, fmt.Errorf("error finding entities by attribute %s: %v", attrName, err)
+	}
+	defer rows.Close()
+
+	var entityIDs []string
+	for rows.Next() {
+		var entityID, geoJSON string
+		if err := rows.Scan(&entityID, &geoJSON); err != nil {
+			return nil, fmt.Errorf("error scanning geometry: %v", err)
+		}
+		geometry, err := geo.ParseJSON([]byte(geoJSON))
+		if err != nil {
+			return nil, fmt.Errorf("error decoding geometry of entity %s: %v", entityID, err)
+		}
+		if filter.Matches(geometry) {
+			entityIDs = append(entityIDs, entityID)
+		}
+	}
+	return entityIDs, rows.Err()
+}
@@ -0,0 +1,83 @@
+package sqliterepository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/pkg/geo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpatialRepository(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.NoError(t, repo.InitializeGeometries(ctx))
+	assert.NoError(t, repo.InitializeGeometries(ctx), "Expected initializing twice to succeed")
+
+	now := time.Now()
+	saveAt := func(entityID, geoJSON string, startTime, endTime time.Time) {
+		geometry, err := geo.ParseJSON([]byte(geoJSON))
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveGeometry(ctx, entityID, "boundary", geometry, startTime, endTime))
+	}
+	save := func(entityID, geoJSON string) {
+		saveAt(entityID, geoJSON, time.Time{}, time.Time{})
+	}
+	save("colombo", `{"type": "Polygon", "coordinates": [[[79.80, 6.85], [79.95, 6.85], [79.95, 7.00], [79.80, 7.00], [79.80, 6.85]]]}`)
+	save("gampaha", `{"type": "Polygon", "coordinates": [[[79.90, 7.00], [80.20, 7.00], [80.20, 7.30], [79.90, 7.30], [79.90, 7.00]]]}`)
+	// A triangle whose bounding box holds the point although the triangle does not
+	save("kalutara", `{"type": "Polygon", "coordinates": [[[79.80, 6.85], [79.95, 6.95], [79.95, 6.85], [79.80, 6.85]]]}`)
+
+	entityIDs, err := repo.FindEntities(ctx, "boundary", geo.Filter{ContainsPoint: &geo.Position{Lon: 79.85, Lat: 6.93}}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo"}, entityIDs)
+
+	entityIDs, err = repo.FindEntities(ctx, "boundary", geo.Filter{IntersectsBox: &geo.Box{MinLon: 79.92, MinLat: 6.98, MaxLon: 80.0, MaxLat: 7.1}}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo", "gampaha"}, entityIDs)
+
+	// Other attributes are not looked at
+	entityIDs, err = repo.FindEntities(ctx, "office", geo.Filter{ContainsPoint: &geo.Position{Lon: 79.85, Lat: 6.93}}, now)
+	assert.NoError(t, err)
+	assert.Empty(t, entityIDs)
+
+	_, err = repo.FindEntities(ctx, "boundary", geo.Filter{}, now)
+	assert.Error(t, err)
+
+	// A later geometry replaces the earlier one from its start time, an ended geometry matches no more
+	movedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("IST", 19800))
+	saveAt("colombo", `{"type": "Point", "coordinates": [80.10, 7.20]}`, movedAt, time.Time{})
+	saveAt("kalutara", `{"type": "Point", "coordinates": [80.10, 7.20]}`, movedAt, movedAt.AddDate(1, 0, 0))
+	point := geo.Filter{ContainsPoint: &geo.Position{Lon: 80.10, Lat: 7.20}}
+	for activeAt, expected := range map[time.Time][]string{
+		now:                       {"colombo", "gampaha"},
+		movedAt.AddDate(0, 6, 0):  {"colombo", "gampaha", "kalutara"},
+		movedAt.Add(-time.Minute): {"gampaha"},
+	} {
+		entityIDs, err = repo.FindEntities(ctx, "boundary", point, activeAt)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, entityIDs, activeAt.String())
+	}
+	entityIDs, err = repo.FindEntities(ctx, "boundary", geo.Filter{ContainsPoint: &geo.Position{Lon: 79.85, Lat: 6.93}}, movedAt.Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"colombo"}, entityIDs)
+
+	// Saving again with the same start time replaces the geometry
+	saveAt("colombo", `{"type": "Point", "coordinates": [79.86, 6.93]}`, movedAt, time.Time{})
+	geometry, err := repo.GetGeometry(ctx, "colombo", "boundary")
+	assert.NoError(t, err)
+	assert.Equal(t, geo.PointType, geometry.Type)
+	entityIDs, err = repo.FindEntities(ctx, "boundary", point, now)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gampaha"}, entityIDs)
+
+	assert.NoError(t, repo.DeleteGeometry(ctx, "colombo", "boundary"))
+	_, err = repo.GetGeometry(ctx, "colombo", "boundary")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+}
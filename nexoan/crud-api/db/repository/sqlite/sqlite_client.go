@@ -0,0 +1,59 @@
+// Package sqliterepository stores the entity graph and tabular attributes in SQLite databases.
+// Together with the document repository it lets the service run as a single process without
+// Neo4j, MongoDB or PostgreSQL.
+package sqliterepository
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// sortableTimeLayout formats timestamps in UTC with a fixed width so that they compare correctly as text
+const sortableTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// openDatabase opens the SQLite database at path, creating the file if needed
+func openDatabase(path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", url.PathEscape(path))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %v", err)
+	}
+
+	// SQLite allows a single writer, sharing one connection avoids busy errors between requests
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error connecting to the database: %v", err)
+	}
+	return db, nil
+}
+
+// sortableTime converts an RFC3339 timestamp into its sortable UTC form
+func sortableTime(value string) (string, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid datetime %q: %v", value, err)
+	}
+	return t.UTC().Format(sortableTimeLayout), nil
+}
+
+// nullableSortableTime converts an optional RFC3339 timestamp, an empty value is stored as NULL
+func nullableSortableTime(value string) (interface{}, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return sortableTime(value)
+}
+
+// nullableString stores an empty string as NULL
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
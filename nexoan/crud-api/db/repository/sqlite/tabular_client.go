@@ -0,0 +1,416 @@
+package sqliterepository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	commons "lk/datafoundation/crud-api/commons"
+	postgres "lk/datafoundation/crud-api/db/repository/postgres"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// TabularRepository stores tabular attributes in SQLite.
+// It uses the same entity_attributes and attribute_schemas bookkeeping and the same
+// attribute tables named by commons.AttributeTableName as the PostgreSQL repository.
+type TabularRepository struct {
+	db *sql.DB
+}
+
+// NewTabularRepository opens the tabular database at path
+func NewTabularRepository(path string) (*TabularRepository, error) {
+	db, err := openDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	return &TabularRepository{db: db}, nil
+}
+
+// Close closes the database connection
+func (r *TabularRepository) Close() error {
+	return r.db.Close()
+}
+
+// Ping checks that the database can still be reached
+func (r *TabularRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// InitializeTables creates the entity_attributes and attribute_schemas tables if they don't exist
+func (r *TabularRepository) InitializeTables(ctx context.Context) error {
+	entityAttributesSQL := `
+	CREATE TABLE IF NOT EXISTS entity_attributes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		entity_id TEXT NOT NULL,
+		attribute_name TEXT NOT NULL,
+		table_name TEXT NOT NULL,
+		schema_version INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(entity_id, attribute_name)
+	);`
+
+	attributeSchemasSQL := `
+	CREATE TABLE IF NOT EXISTS attribute_schemas (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		table_name TEXT NOT NULL,
+		schema_version INTEGER NOT NULL,
+		schema_definition TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(table_name, schema_version)
+	);`
+
+	if _, err := r.db.ExecContext(ctx, entityAttributesSQL); err != nil {
+		return fmt.Errorf("error creating entity_attributes table: %v", err)
+	}
+	if _, err := r.db.ExecContext(ctx, attributeSchemasSQL); err != nil {
+		return fmt.Errorf("error creating attribute_schemas table: %v", err)
+	}
+	return nil
+}
+
+// TableExists checks if a table exists in the database
+func (r *TabularRepository) TableExists(ctx context.Context, tableName string) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, tableName).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("error checking table existence: %v", err)
+	}
+	return count > 0, nil
+}
+
+// columnType returns the SQLite column definition for a schema field
+func columnType(typeInfo *typeinference.TypeInfo) string {
+	var colType string
+	switch typeInfo.Type {
+	case typeinference.IntType:
+		colType = "INTEGER"
+	case typeinference.FloatType:
+		colType = "REAL"
+	case typeinference.BoolType:
+		colType = "BOOLEAN"
+	case typeinference.DateType:
+		colType = "DATE"
+	case typeinference.DateTimeType:
+		colType = "TIMESTAMP"
+	default:
+		colType = "TEXT"
+	}
+	if typeInfo.IsNullable {
+		return colType + " NULL"
+	}
+	return colType + " NOT NULL"
+}
+
+// quoteIdentifier quotes a table or column name so that it is used exactly as given. Grave accents are used
+// since SQLite reads a double quoted name that is not a column as a string.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteIdentifiers quotes every name for use as an identifier
+func quoteIdentifiers(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteIdentifier(name)
+	}
+	return quoted
+}
+
+// createDynamicTable creates the table storing the rows of a tabular attribute and indexes them by attribute and write time
+func createDynamicTable(ctx context.Context, tx *sql.Tx, tableName string, schemaInfo *schema.SchemaInfo) error {
+	columnDefs := []string{
+		"id INTEGER PRIMARY KEY AUTOINCREMENT",
+		"entity_attribute_id INTEGER REFERENCES entity_attributes(id)",
+	}
+	for fieldName, field := range schemaInfo.Fields {
+		if err := postgres.ValidateColumnName(fieldName); err != nil {
+			return err
+		}
+		// Skip "id" columns as they conflict with the auto-generated primary key
+		if fieldName == "id" || field.TypeInfo == nil {
+			continue
+		}
+		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", quoteIdentifier(fieldName), columnType(field.TypeInfo)))
+	}
+	columnDefs = append(columnDefs, "created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP")
+
+	createTableSQL := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);", quoteIdentifier(tableName), strings.Join(columnDefs, ",\n"))
+	if _, err := tx.ExecContext(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("error creating dynamic table: %v", err)
+	}
+	_, indexSQL := indexStatement(tableName, postgres.AttributeIndexColumns)
+	if _, err := tx.ExecContext(ctx, indexSQL); err != nil {
+		return fmt.Errorf("error creating attribute index: %v", err)
+	}
+	return nil
+}
+
+// GetSchemaOfTable retrieves the latest schema stored for an attribute table
+func (r *TabularRepository) GetSchemaOfTable(ctx context.Context, tableName string) (*schema.SchemaInfo, error) {
+	var schemaJSON string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT schema_definition FROM attribute_schemas WHERE table_name = ? ORDER BY schema_version DESC LIMIT 1`,
+		tableName).Scan(&schemaJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error getting schema for table %s: %w", tableName, commons.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting schema for table %s: %v", tableName, err)
+	}
+
+	var schemaInfo schema.SchemaInfo
+	if err := json.Unmarshal([]byte(schemaJSON), &schemaInfo); err != nil {
+		return nil, fmt.Errorf("error unmarshaling schema for table %s: %v", tableName, err)
+	}
+	return &schemaInfo, nil
+}
+
+// AttributeTable returns the table holding the rows of an attribute. The table recorded in entity_attributes is
+// kept once the attribute has rows, so tables named before commons.AttributeTableName existed are still found.
+func (r *TabularRepository) AttributeTable(ctx context.Context, entityID, attrName string) (string, error) {
+	var tableName string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT table_name FROM entity_attributes WHERE entity_id = ? AND attribute_name = ?`,
+		entityID, attrName).Scan(&tableName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return commons.AttributeTableName(entityID, attrName), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading table of attribute %s: %v", attrName, err)
+	}
+	return tableName, nil
+}
+
+// HandleTabularData validates tabular data against the schema of its table and appends its rows.
+// The table is created with the given schema on the first write.
+func (r *TabularRepository) HandleTabularData(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, schemaInfo *schema.SchemaInfo) error {
+	tableName, err := r.AttributeTable(ctx, entityID, attrName)
+	if err != nil {
+		return err
+	}
+
+	var tabularStruct structpb.Struct
+	if err := value.GetValue().UnmarshalTo(&tabularStruct); err != nil {
+		return fmt.Errorf("error unmarshaling tabular data: %v", err)
+	}
+	columnsValue := tabularStruct.Fields["columns"].GetListValue()
+	rowsValue := tabularStruct.Fields["rows"].GetListValue()
+	if columnsValue == nil || rowsValue == nil {
+		return fmt.Errorf("invalid tabular data format")
+	}
+
+	exists, err := r.TableExists(ctx, tableName)
+	if err != nil {
+		return err
+	}
+	// Rows are stored under the schema of the table, which is the existing one once the table exists
+	tableSchema := schemaInfo
+	if exists {
+		existingSchema, err := r.GetSchemaOfTable(ctx, tableName)
+		if err != nil {
+			return fmt.Errorf("error getting existing schema: %v", err)
+		}
+		compatible, err := postgres.CompareSchemas(existingSchema, schemaInfo)
+		if err != nil {
+			return fmt.Errorf("schema compatibility check failed: %v", err)
+		}
+		if !compatible {
+			return fmt.Errorf("incompatible schema changes detected")
+		}
+		if err := postgres.ValidateDataAgainstSchema(&tabularStruct, existingSchema); err != nil {
+			return fmt.Errorf("data validation failed: %v", err)
+		}
+		tableSchema = existingSchema
+	}
+
+	columnNames := make([]string, len(columnsValue.Values))
+	columnTypes := make([]typeinference.DataType, len(columnsValue.Values))
+	for i, col := range columnsValue.Values {
+		if err := postgres.ValidateColumnName(col.GetStringValue()); err != nil {
+			return err
+		}
+		columnNames[i] = col.GetStringValue()
+		if field, ok := tableSchema.Fields[col.GetStringValue()]; ok && field.TypeInfo != nil {
+			columnTypes[i] = field.TypeInfo.Type
+		}
+	}
+	rows := make([][]interface{}, len(rowsValue.Values))
+	for i, row := range rowsValue.Values {
+		rowList := row.GetListValue()
+		if rowList == nil {
+			return fmt.Errorf("invalid row format at index %d", i)
+		}
+		if len(rowList.Values) != len(columnNames) {
+			return fmt.Errorf("error inserting tabular data: row %d has %d values for %d columns", i, len(rowList.Values), len(columnNames))
+		}
+		rows[i] = make([]interface{}, len(rowList.Values))
+		for j, cell := range rowList.Values {
+			rows[i][j] = typeinference.ColumnValue(cell, columnTypes[j])
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if !exists {
+		if err := createDynamicTable(ctx, tx, tableName, schemaInfo); err != nil {
+			return err
+		}
+		schemaJSON, err := json.Marshal(schemaInfo)
+		if err != nil {
+			return fmt.Errorf("error marshaling schema: %v", err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO attribute_schemas (table_name, schema_version, schema_definition) VALUES (?, ?, ?)`,
+			tableName, 1, string(schemaJSON)); err != nil {
+			return fmt.Errorf("error storing schema: %v", err)
+		}
+	}
+
+	var attributeID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO entity_attributes (entity_id, attribute_name, table_name)
+		VALUES (?, ?, ?)
+		ON CONFLICT (entity_id, attribute_name) DO UPDATE SET table_name = excluded.table_name
+		RETURNING id`,
+		entityID, attrName, tableName).Scan(&attributeID)
+	if err != nil {
+		return fmt.Errorf("error creating entity attribute record: %v", err)
+	}
+
+	if len(rows) > 0 {
+		placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columnNames)+1), ", ") + ")"
+		insertSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+			quoteIdentifier(tableName),
+			strings.Join(append([]string{"entity_attribute_id"}, quoteIdentifiers(columnNames)...), ", "),
+			placeholders)
+		for _, row := range rows {
+			if _, err := tx.ExecContext(ctx, insertSQL, append([]interface{}{attributeID}, row...)...); err != nil {
+				return fmt.Errorf("error inserting tabular data: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing tabular data: %v", err)
+	}
+	return nil
+}
+
+// internalColumns are bookkeeping columns that are only returned when explicitly requested
+var internalColumns = map[string]bool{
+	"created_at":          true,
+	"entity_attribute_id": true,
+}
+
+// GetData retrieves data from a table with optional field selection and filters.
+// The result has the same shape as the PostgreSQL repository: a struct holding the tabular data as JSON.
+func (r *TabularRepository) GetData(ctx context.Context, tableName string, filters map[string]interface{}, fields ...string) (*anypb.Any, error) {
+	selectClause := "*"
+	if len(fields) > 0 {
+		selectClause = strings.Join(quoteIdentifiers(fields), ", ")
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", selectClause, quoteIdentifier(tableName))
+
+	var args []interface{}
+	var whereClauses []string
+	for key, value := range filters {
+		whereClauses = append(whereClauses, fmt.Sprintf("%s = ?", quoteIdentifier(key)))
+		args = append(args, value)
+	}
+	if len(whereClauses) > 0 {
+		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying data from %s: %v", tableName, err)
+	}
+	defer rows.Close()
+
+	resultColumns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns from %s: %v", tableName, err)
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("error getting column types from %s: %v", tableName, err)
+	}
+
+	requested := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		requested[field] = true
+	}
+	var columns []string
+	var columnIndices []int
+	for i, column := range resultColumns {
+		if !internalColumns[column] || requested[column] {
+			columns = append(columns, column)
+			columnIndices = append(columnIndices, i)
+		}
+	}
+
+	var tabularRows [][]interface{}
+	for rows.Next() {
+		rowValues := make([]interface{}, len(resultColumns))
+		rowPointers := make([]interface{}, len(resultColumns))
+		for i := range rowValues {
+			rowPointers[i] = &rowValues[i]
+		}
+		if err := rows.Scan(rowPointers...); err != nil {
+			return nil, fmt.Errorf("error scanning row: %v", err)
+		}
+
+		row := make([]interface{}, len(columns))
+		for i, colIndex := range columnIndices {
+			row[i] = cellValue(rowValues[colIndex], columnTypes[colIndex].DatabaseTypeName())
+		}
+		tabularRows = append(tabularRows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %v", err)
+	}
+
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"columns": columns,
+		"rows":    tabularRows,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling tabular data to JSON: %v", err)
+	}
+	structValue, err := structpb.NewStruct(map[string]interface{}{
+		"data": string(jsonData),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating struct for JSON data: %v", err)
+	}
+	return anypb.New(structValue)
+}
+
+// cellValue converts a scanned SQLite value into the value PostgreSQL would return for the column type
+func cellValue(value interface{}, databaseType string) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case int64:
+		// SQLite stores booleans as integers
+		if databaseType == "BOOLEAN" {
+			return v != 0
+		}
+		return v
+	}
+	return value
+}
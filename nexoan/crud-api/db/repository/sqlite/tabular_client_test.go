@@ -0,0 +1,151 @@
+package sqliterepository
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/schema"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newTabularValue creates a tabular value and its schema from columns and rows
+func newTabularValue(t *testing.T, columns []interface{}, rows []interface{}) (*pb.TimeBasedValue, *schema.SchemaInfo) {
+	tabular, err := structpb.NewStruct(map[string]interface{}{
+		"columns": columns,
+		"rows":    rows,
+	})
+	assert.NoError(t, err)
+	value, err := anypb.New(tabular)
+	assert.NoError(t, err)
+	schemaInfo, err := schema.GenerateSchema(value)
+	assert.NoError(t, err)
+	return &pb.TimeBasedValue{StartTime: "2024-01-01T00:00:00Z", Value: value}, schemaInfo
+}
+
+// readTabularData unpacks the JSON data returned by GetData
+func readTabularData(t *testing.T, result *anypb.Any) (columns []string, rows [][]interface{}) {
+	var data structpb.Struct
+	assert.NoError(t, result.UnmarshalTo(&data))
+	var tabular struct {
+		Columns []string        `json:"columns"`
+		Rows    [][]interface{} `json:"rows"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(data.Fields["data"].GetStringValue()), &tabular))
+	return tabular.Columns, tabular.Rows
+}
+
+func TestTabularRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tabular.db")
+	repo, err := NewTabularRepository(path)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.InitializeTables(ctx))
+
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"name", "age", "active"},
+		[]interface{}{
+			[]interface{}{"Alice", 30, true},
+			[]interface{}{"Bob", 25, false},
+		})
+	assert.NoError(t, repo.HandleTabularData(ctx, "entity-1", "people", value, schemaInfo))
+
+	exists, err := repo.TableExists(ctx, "attr_entity_2d1__people")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// Incompatible data is rejected without storing any row
+	bad, badSchema := newTabularValue(t,
+		[]interface{}{"name", "age", "active"},
+		[]interface{}{[]interface{}{"Dave", "old", true}})
+	assert.Error(t, repo.HandleTabularData(ctx, "entity-1", "people", bad, badSchema))
+	assert.NoError(t, repo.Close())
+
+	// The data is still there after reopening the database
+	repo, err = NewTabularRepository(path)
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.NoError(t, repo.InitializeTables(ctx))
+
+	more, moreSchema := newTabularValue(t,
+		[]interface{}{"name", "age", "active"},
+		[]interface{}{[]interface{}{"Carol", 41, true}})
+	assert.NoError(t, repo.HandleTabularData(ctx, "entity-1", "people", more, moreSchema))
+
+	result, err := repo.GetData(ctx, "attr_entity_2d1__people", nil)
+	assert.NoError(t, err)
+	columns, rows := readTabularData(t, result)
+	assert.NotContains(t, columns, "entity_attribute_id")
+	assert.NotContains(t, columns, "created_at")
+	assert.Len(t, rows, 3)
+
+	result, err = repo.GetData(ctx, "attr_entity_2d1__people", map[string]interface{}{"name": "Bob"}, "age", "active")
+	assert.NoError(t, err)
+	columns, rows = readTabularData(t, result)
+	assert.Equal(t, []string{"age", "active"}, columns)
+	assert.Equal(t, [][]interface{}{{float64(25), false}}, rows)
+}
+
+func TestTabularRepositoryColumnNames(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.NoError(t, repo.InitializeTables(ctx))
+
+	// Names that used to be sanitized into the same column are kept apart and read back unchanged
+	value, schemaInfo := newTabularValue(t,
+		[]interface{}{"Budget-2020", "budget_2020", "Unit Name", "a`b"},
+		[]interface{}{[]interface{}{1, 2, "Records", "quoted"}})
+	assert.NoError(t, repo.HandleTabularData(ctx, "entity-1", "budgets", value, schemaInfo))
+	result, err := repo.GetData(ctx, "attr_entity_2d1__budgets", map[string]interface{}{"Unit Name": "Records"}, "Budget-2020", "budget_2020", "a`b")
+	assert.NoError(t, err)
+	columns, rows := readTabularData(t, result)
+	assert.Equal(t, []string{"Budget-2020", "budget_2020", "a`b"}, columns)
+	assert.Equal(t, [][]interface{}{{float64(1), float64(2), "quoted"}}, rows)
+
+	// Names that cannot be kept exactly are rejected
+	long, longSchema := newTabularValue(t,
+		[]interface{}{strings.Repeat("x", 64)},
+		[]interface{}{[]interface{}{1}})
+	assert.Error(t, repo.HandleTabularData(ctx, "entity-1", "long", long, longSchema))
+}
+
+func TestTabularRepositoryDeclaredSchema(t *testing.T) {
+	ctx := context.Background()
+	repo, err := NewTabularRepository(filepath.Join(t.TempDir(), "tabular.db"))
+	assert.NoError(t, err)
+	defer repo.Close()
+	assert.NoError(t, repo.InitializeTables(ctx))
+
+	_, err = repo.GetSchemaOfTable(ctx, "attr_entity_2d1__grades")
+	assert.ErrorIs(t, err, commons.ErrNotFound)
+
+	declared, err := schema.ParseDeclaredSchema(`{
+		"type": "array",
+		"x-storage-type": "tabular",
+		"items": {"type": "object", "properties": {"grade": {"type": "number"}, "note": {"type": "string"}}, "required": ["grade"]}
+	}`)
+	assert.NoError(t, err)
+	value, _ := newTabularValue(t, []interface{}{"grade", "note"}, []interface{}{[]interface{}{3, nil}})
+	assert.NoError(t, repo.HandleTabularData(ctx, "entity-1", "grades", value, declared))
+
+	// The declared schema is stored with its flag and nullable columns accept null on later writes
+	stored, err := repo.GetSchemaOfTable(ctx, "attr_entity_2d1__grades")
+	assert.NoError(t, err)
+	assert.True(t, stored.Declared)
+	more, _ := newTabularValue(t, []interface{}{"grade", "note"}, []interface{}{[]interface{}{4.5, nil}})
+	assert.NoError(t, repo.HandleTabularData(ctx, "entity-1", "grades", more, declared))
+
+	result, err := repo.GetData(ctx, "attr_entity_2d1__grades", nil, "grade")
+	assert.NoError(t, err)
+	_, rows := readTabularData(t, result)
+	assert.Equal(t, [][]interface{}{{float64(3)}, {4.5}}, rows)
+}
@@ -0,0 +1,112 @@
+package sqliterepository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// pathSeparator separates the IDs of a path in the traversal query, it is a control character IDs do not contain
+const pathSeparator = "\x1f"
+
+// RelatedEntity is an entity reached by following relationships from a start entity
+type RelatedEntity struct {
+	EntityID string
+	// RelationshipIDs are the relationships followed from the start entity, in order
+	RelationshipIDs []string
+}
+
+// Hops returns the number of relationships followed to reach the entity
+func (e *RelatedEntity) Hops() int {
+	return len(e.RelationshipIDs)
+}
+
+// GetRelatedEntities returns the entities reachable from entityID by following at most maxHops relationships.
+// Only relationships named name are followed unless it is empty, direction is OUTGOING, INCOMING or empty for
+// both and, when activeAt is set, only relationships active at that instant are followed. Each entity is
+// returned once with the shortest path reaching it, ordered by the number of hops and entity ID.
+func (r *GraphRepository) GetRelatedEntities(ctx context.Context, entityID string, name string, direction string, maxHops int, activeAt string) ([]*RelatedEntity, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("entity Id cannot be empty")
+	}
+	if maxHops < 1 {
+		return nil, fmt.Errorf("the maximum number of hops must be at least 1, got %d", maxHops)
+	}
+	if direction != "" && direction != "OUTGOING" && direction != "INCOMING" {
+		return nil, fmt.Errorf("invalid direction %s, expected OUTGOING or INCOMING", direction)
+	}
+	if strings.Contains(entityID, pathSeparator) {
+		return nil, fmt.Errorf("invalid entity Id %q", entityID)
+	}
+
+	// The filters apply to every relationship of the path, not only to the first one
+	var conditions []string
+	var filterArgs []interface{}
+	if name != "" {
+		conditions = append(conditions, "type = ?")
+		filterArgs = append(filterArgs, name)
+	}
+	if activeAt != "" {
+		activeAtUTC, err := sortableTime(activeAt)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, activeAtCondition)
+		filterArgs = append(filterArgs, activeAtUTC, activeAtUTC)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var edges []string
+	var args []interface{}
+	if direction != "INCOMING" {
+		edges = append(edges, "SELECT id, source_id AS from_id, target_id AS to_id FROM relationships"+where)
+		args = append(args, filterArgs...)
+	}
+	if direction != "OUTGOING" {
+		edges = append(edges, "SELECT id, target_id AS from_id, source_id AS to_id FROM relationships"+where)
+		args = append(args, filterArgs...)
+	}
+
+	// visited holds the entities of the path so that cycles are not followed
+	query := `
+		WITH RECURSIVE edges AS (` + strings.Join(edges, " UNION ALL ") + `),
+		walk(entity_id, hops, path, visited) AS (
+			SELECT ?, 0, '', ?
+			UNION ALL
+			SELECT edges.to_id, walk.hops + 1,
+			       walk.path || CASE WHEN walk.hops = 0 THEN '' ELSE ? END || edges.id,
+			       walk.visited || edges.to_id || ?
+			FROM walk JOIN edges ON edges.from_id = walk.entity_id
+			WHERE walk.hops < ? AND instr(walk.visited, ? || edges.to_id || ?) = 0
+		)
+		SELECT entity_id, path FROM walk WHERE hops > 0 ORDER BY hops, entity_id, path`
+	args = append(args, entityID, pathSeparator+entityID+pathSeparator, pathSeparator, pathSeparator, maxHops, pathSeparator, pathSeparator)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error traversing relationships: %v", err)
+	}
+	defer rows.Close()
+
+	seen := make(map[string]bool)
+	var related []*RelatedEntity
+	for rows.Next() {
+		var id, path string
+		if err := rows.Scan(&id, &path); err != nil {
+			return nil, fmt.Errorf("error scanning related entity: %v", err)
+		}
+		// The first row of an entity has the fewest hops
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		related = append(related, &RelatedEntity{EntityID: id, RelationshipIDs: strings.Split(path, pathSeparator)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over related entities: %v", err)
+	}
+	return related, nil
+}
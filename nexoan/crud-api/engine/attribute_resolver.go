@@ -1,17 +1,23 @@
 package engine
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	commons "lk/datafoundation/crud-api/commons"
 	dbcommons "lk/datafoundation/crud-api/commons/db"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/metrics"
 	schema "lk/datafoundation/crud-api/pkg/schema"
 	storageinference "lk/datafoundation/crud-api/pkg/storageinference"
-	"log"
+	"lk/datafoundation/crud-api/pkg/tracing"
+	"log/slog"
 
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -32,9 +38,25 @@ type AttributeResolver interface {
 	Finalize() error
 }
 
+// ErrSchemaConflict is returned when a declared schema does not match the schema stored for an attribute
+var ErrSchemaConflict = errors.New("declared schema conflicts with the stored schema")
+
+// DeclaredSchemaResolver is implemented by the resolvers that store the schema of their attributes.
+// A declared schema overrides the schema inferred from the value.
+type DeclaredSchemaResolver interface {
+	CreateResolveWithSchema(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, declared *schema.SchemaInfo) *Result
+}
+
+// RecordingResolver is implemented by the resolvers that record the caller creating an attribute in the
+// metadata history of the attribute
+type RecordingResolver interface {
+	CreateResolveRecordedBy(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, recordedBy string) *Result
+}
+
 // BaseAttributeResolver provides common functionality for all resolvers
 type BaseAttributeResolver struct {
 	storageInferrer *storageinference.StorageInferrer
+	repos           *dbcommons.Repositories
 }
 
 func (r *BaseAttributeResolver) Initialize() error {
@@ -52,22 +74,26 @@ type EntityAttributeProcessor struct {
 	graphManager *GraphMetadataManager
 }
 
-// NewEntityAttributeProcessor creates a new processor with all resolvers initialized
-func NewEntityAttributeProcessor() *EntityAttributeProcessor {
+// NewEntityAttributeProcessor creates a new processor with all resolvers initialized.
+// The resolvers and the graph metadata manager share the given repositories.
+func NewEntityAttributeProcessor(repos *dbcommons.Repositories) *EntityAttributeProcessor {
 	processor := &EntityAttributeProcessor{
 		resolvers:    make(map[storageinference.StorageType]AttributeResolver),
-		graphManager: NewGraphMetadataManager(),
+		graphManager: NewGraphMetadataManager(repos),
 	}
 
 	// Initialize all resolvers
-	processor.resolvers[storageinference.GraphData] = &GraphAttributeResolver{}
-	processor.resolvers[storageinference.TabularData] = &TabularAttributeResolver{}
-	processor.resolvers[storageinference.MapData] = &DocumentAttributeResolver{}
+	processor.resolvers[storageinference.GraphData] = &GraphAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
+	processor.resolvers[storageinference.TabularData] = &TabularAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
+	processor.resolvers[storageinference.MapData] = &DocumentAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
+	processor.resolvers[storageinference.ScalarData] = &ScalarAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
+	processor.resolvers[storageinference.BlobData] = &BlobAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
+	processor.resolvers[storageinference.GeospatialData] = &GeospatialAttributeResolver{BaseAttributeResolver: BaseAttributeResolver{repos: repos}}
 
 	// Initialize each resolver
 	for _, resolver := range processor.resolvers {
 		if err := resolver.Initialize(); err != nil {
-			fmt.Printf("Warning: failed to initialize resolver: %v\n", err)
+			slog.Warn("Failed to initialize resolver", "error", err)
 		}
 	}
 
@@ -83,7 +109,8 @@ func (p *EntityAttributeProcessor) GetResolver(storageType storageinference.Stor
 // ProcessEntityAttributes processes all attributes in an Entity with operation options
 // Returns a map of attribute names to their processing results
 func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context, entity *pb.Entity, operation string, options *Options) map[string]*Result {
-	log.Printf("Processing entity attributes at [processor.ProcessEntityAttributes] [operation: %s] [entity: %+v]", operation, entity)
+	logger := logging.FromContext(ctx).With("operation", operation)
+	logger.DebugContext(ctx, "Processing entity attributes", "entity_id", entity.GetId(), logging.Payload("entity", entity))
 	if entity == nil || entity.Attributes == nil {
 		return make(map[string]*Result)
 	}
@@ -93,9 +120,8 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 
 	// Process each attribute
 	for attrName, timeBasedValueList := range entity.Attributes {
-		fmt.Printf("DEBUG: Processing attribute[%s] %s\n", operation, attrName)
 		if timeBasedValueList == nil {
-			log.Printf("DEBUG: Time-based value list is nil for attribute %s", attrName)
+			logger.DebugContext(ctx, "Time-based value list is nil", "attribute", attrName)
 			attributeResults[attrName] = &Result{
 				Success: true,
 				Data:    nil,
@@ -104,7 +130,7 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 			continue
 		}
 
-		log.Printf("DEBUG: Time-based value list is not nil for attribute %s, length: %d", attrName, len(timeBasedValueList.Values))
+		logger.DebugContext(ctx, "Processing attribute", "attribute", attrName, "values", len(timeBasedValueList.Values))
 
 		// Process each time-based value
 		for _, value := range timeBasedValueList.Values {
@@ -112,20 +138,42 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 				continue
 			}
 
-			log.Printf("DEBUG: Processing time-based value for attribute %s: %+v", attrName, value)
+			logger.DebugContext(ctx, "Processing time-based value", "attribute", attrName, logging.Payload("value", value))
 
-			// Determine storage type
-			storageType, err := p.determineStorageType(value.Value)
-			fmt.Printf("DEBUG: Determined storage type[%s] for attribute %s: %s\n", operation, attrName, storageType)
+			// Determine storage type, a declared schema overrides the inferred one
+			var storageType storageinference.StorageType
+			var err error
+			if declared := options.declaredSchema(operation, attrName); declared != nil {
+				storageType = declared.StorageType
+			} else {
+				storageType, err = p.determineStorageType(value.Value)
+			}
+			logger.DebugContext(ctx, "Determined storage type", "attribute", attrName, "storage_type", storageType)
 			if err != nil {
 				attributeResults[attrName] = &Result{
 					Success: false,
 					Data:    nil,
 					Error:   fmt.Errorf("error determining storage type for attribute %s: %v", attrName, err),
 				}
+				metrics.ObserveAttribute(operation, string(storageinference.UnknownData), attributeResults[attrName].Error)
 				continue
 			}
 
+			// Typed values are stored in the same layout as the values whose storage type is inferred
+			unwrapped, err := storageinference.Unwrap(value.Value)
+			if err != nil {
+				attributeResults[attrName] = &Result{
+					Success: false,
+					Data:    nil,
+					Error:   fmt.Errorf("invalid value for attribute %s: %v", attrName, err),
+				}
+				metrics.ObserveAttribute(operation, string(storageType), attributeResults[attrName].Error)
+				continue
+			}
+			if unwrapped != value.Value {
+				value = &pb.TimeBasedValue{StartTime: value.StartTime, EndTime: value.EndTime, Value: unwrapped}
+			}
+
 			// Create or update graph metadata BEFORE processing the attribute
 			// NOTE: for the attribute the timestamp is always the value carried at the attribute level
 			// not the entity level. The entity level timestamp is used for the entity itself.
@@ -136,18 +184,20 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 					Data:    nil,
 					Error:   fmt.Errorf("error handling graph metadata for attribute %s: %v", attrName, err),
 				}
+				metrics.ObserveAttribute(operation, string(storageType), attributeResults[attrName].Error)
 				continue
 			}
 
 			// Get appropriate resolver
 			resolver, exists := p.resolvers[storageType]
 			if !exists {
-				fmt.Printf("Warning: no resolver found for storage type %s, skipping attribute %s\n", storageType, attrName)
+				logger.WarnContext(ctx, "No resolver found for storage type, skipping attribute", "attribute", attrName, "storage_type", storageType)
 				attributeResults[attrName] = &Result{
 					Success: false,
 					Data:    nil,
 					Error:   fmt.Errorf("no resolver found for storage type %s", storageType),
 				}
+				metrics.ObserveAttribute(operation, string(storageType), attributeResults[attrName].Error)
 				continue
 			}
 
@@ -172,17 +222,18 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 				operationOptions = options
 			}
 			result := p.executeOperation(ctx, resolver, operation, entity.Id, attrName, value, operationOptions)
+			resultErr := result.Error
+			if resultErr == nil && !result.Success {
+				resultErr = fmt.Errorf("attribute %s was not processed", attrName)
+			}
+			metrics.ObserveAttribute(operation, string(storageType), resultErr)
 
-			log.Printf("DEBUG: Result for attribute %s: %+v", attrName, result)
+			logger.DebugContext(ctx, "Processed attribute", "attribute", attrName, "success", result.Success, "error", result.Error, logging.Payload("result", result.Data))
 
 			// Store the result for this attribute
 			attributeResults[attrName] = result
 
-			// For read operations, we might want to do something with the result
-			if operation == "read" && result.Data != nil {
-				fmt.Printf("Read operation completed for attribute %s\n", attrName)
-				// TODO: Handle the read result (e.g., store it, return it, etc.)
-			}
+			// TODO: For read operations, handle the read result (e.g., store it, return it, etc.)
 		}
 	}
 
@@ -194,9 +245,16 @@ func (p *EntityAttributeProcessor) ProcessEntityAttributes(ctx context.Context,
 // It creates the attribute look up metadata and the attribute node in the graph.
 // It also creates the IS_ATTRIBUTE relationship between the entity and the attribute.
 // It also creates the attribute metadata in the document database.
-func (p *EntityAttributeProcessor) handleAttributeLookUp(ctx context.Context, entityID, attrName string, storageType storageinference.StorageType, operation string, startTime time.Time) error {
+func (p *EntityAttributeProcessor) handleAttributeLookUp(ctx context.Context, entityID, attrName string, storageType storageinference.StorageType, operation string, startTime time.Time) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "attribute_lookup."+operation, trace.WithAttributes(
+		attribute.String("crud.entity.id", entityID),
+		attribute.String("crud.attribute.name", attrName),
+		attribute.String("crud.attribute.storage_type", string(storageType)),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	// Generate attribute metadata
-	fmt.Printf("DEBUG: Handling graph metadata for attribute %s\n", attrName)
+	logging.FromContext(ctx).DebugContext(ctx, "Handling graph metadata", "entity_id", entityID, "attribute", attrName)
 	attributeID := GenerateAttributeID(entityID, attrName)
 	storagePath := GenerateStoragePath(entityID, attrName, storageType)
 
@@ -238,17 +296,18 @@ func (p *EntityAttributeProcessor) handleAttributeLookUp(ctx context.Context, en
 		// For read operations, retrieve the attribute metadata from the graph
 		attributeMetadata, err := p.graphManager.GetAttribute(ctx, entityID, attrName, startTime)
 		if err != nil {
-			fmt.Printf("Warning: attribute %s not found in graph metadata for entity %s\n", attrName, entityID)
+			logging.FromContext(ctx).WarnContext(ctx, "Attribute not found in graph metadata", "entity_id", entityID, "attribute", attrName, "error", err)
 		} else if attributeMetadata != nil {
 			// Store the retrieved metadata for potential use
-			fmt.Printf("DEBUG: Retrieved attribute metadata for %s: %+v\n", attrName, attributeMetadata)
+			logging.FromContext(ctx).DebugContext(ctx, "Retrieved attribute metadata", "entity_id", entityID, "attribute", attrName, logging.Payload("metadata", attributeMetadata))
 		}
 	}
 
 	return nil
 }
 
-// determineStorageType determines the storage type of a TimeBasedValue
+// determineStorageType determines the storage type of a TimeBasedValue, typed values
+// are routed by their message type and the storage type of other values is inferred
 func (p *EntityAttributeProcessor) determineStorageType(anyValue *anypb.Any) (storageinference.StorageType, error) {
 	if anyValue == nil {
 		return storageinference.UnknownData, fmt.Errorf("anyValue is nil")
@@ -284,7 +343,10 @@ type ReadOptions struct {
 
 // CreateOptions contains options for create operations
 type CreateOptions struct {
-	// Future: validation options, conflict resolution, etc.
+	// Schemas are the schemas declared for attributes by name, they override the inferred schemas
+	Schemas map[string]*schema.SchemaInfo
+	// RecordedBy is the subject of the caller, it is recorded in the metadata history of the attributes created
+	RecordedBy string
 }
 
 // UpdateOptions contains options for update operations
@@ -330,8 +392,37 @@ func NewDeleteOptions(deleteOpts *DeleteOptions) *Options {
 	}
 }
 
+// declaredSchema returns the schema declared for an attribute in the create options
+func (o *Options) declaredSchema(operation string, attrName string) *schema.SchemaInfo {
+	if operation != "create" || o == nil || o.CreateOptions == nil {
+		return nil
+	}
+	return o.CreateOptions.Schemas[attrName]
+}
+
+// recordedBy returns the subject of the caller given in the create options
+func (o *Options) recordedBy(operation string) string {
+	if operation != "create" || o == nil || o.CreateOptions == nil {
+		return ""
+	}
+	return o.CreateOptions.RecordedBy
+}
+
 // executeOperation executes the appropriate operation on the given resolver
-func (p *EntityAttributeProcessor) executeOperation(ctx context.Context, resolver AttributeResolver, operation, entityID, attrName string, value *pb.TimeBasedValue, options *Options) *Result {
+func (p *EntityAttributeProcessor) executeOperation(ctx context.Context, resolver AttributeResolver, operation, entityID, attrName string, value *pb.TimeBasedValue, options *Options) (result *Result) {
+	ctx, span := tracing.Tracer().Start(ctx, "resolver."+operation, trace.WithAttributes(
+		attribute.String("crud.entity.id", entityID),
+		attribute.String("crud.attribute.name", attrName),
+		attribute.String("crud.attribute.resolver", fmt.Sprintf("%T", resolver)),
+	))
+	defer func() {
+		var err error
+		if result != nil {
+			err = result.Error
+		}
+		tracing.End(span, err)
+	}()
+
 	if resolver == nil {
 		return &Result{
 			Data:    nil,
@@ -342,12 +433,25 @@ func (p *EntityAttributeProcessor) executeOperation(ctx context.Context, resolve
 
 	switch operation {
 	case "create":
-		// TODO: Use CreateOptions when implemented
-		log.Printf("Creating attribute %s for entity %s\n", attrName, entityID)
+		logging.FromContext(ctx).DebugContext(ctx, "Creating attribute", "entity_id", entityID, "attribute", attrName)
+		if declared := options.declaredSchema(operation, attrName); declared != nil {
+			schemaResolver, ok := resolver.(DeclaredSchemaResolver)
+			if !ok {
+				return &Result{
+					Data:    nil,
+					Success: false,
+					Error:   fmt.Errorf("schemas cannot be declared for %s attributes", declared.StorageType),
+				}
+			}
+			return schemaResolver.CreateResolveWithSchema(ctx, entityID, attrName, value, declared)
+		}
+		if recordingResolver, ok := resolver.(RecordingResolver); ok {
+			return recordingResolver.CreateResolveRecordedBy(ctx, entityID, attrName, value, options.recordedBy(operation))
+		}
 		return resolver.CreateResolve(ctx, entityID, attrName, value)
 	case "read":
 		// Use provided options or default to empty filters
-		log.Printf("Reading attribute %s for entity %s\n", attrName, entityID)
+		logging.FromContext(ctx).DebugContext(ctx, "Reading attribute", "entity_id", entityID, "attribute", attrName)
 		var filters map[string]interface{}
 		var fields []string
 		if options != nil && options.ReadOptions != nil {
@@ -358,11 +462,11 @@ func (p *EntityAttributeProcessor) executeOperation(ctx context.Context, resolve
 		}
 		return resolver.ReadResolve(ctx, entityID, attrName, filters, fields...)
 	case "update":
-		log.Printf("Updating attribute %s for entity %s\n", attrName, entityID)
+		logging.FromContext(ctx).DebugContext(ctx, "Updating attribute", "entity_id", entityID, "attribute", attrName)
 		// TODO: Use UpdateOptions when implemented
 		return resolver.UpdateResolve(ctx, entityID, attrName, value)
 	case "delete":
-		log.Printf("Deleting attribute %s for entity %s\n", attrName, entityID)
+		logging.FromContext(ctx).DebugContext(ctx, "Deleting attribute", "entity_id", entityID, "attribute", attrName)
 		// TODO: Use DeleteOptions when implemented
 		return resolver.DeleteResolve(ctx, entityID, attrName, value)
 	default:
@@ -374,57 +478,27 @@ func (p *EntityAttributeProcessor) executeOperation(ctx context.Context, resolve
 	}
 }
 
-// GraphAttributeResolver handles graph data structures with nodes and edges
+// GraphAttributeResolver handles graph data structures with nodes and edges.
+// The graphs are kept as documents in the metadata store with their start and end times.
 type GraphAttributeResolver struct {
 	BaseAttributeResolver
 }
 
 func (r *GraphAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
-	// TODO: implement graph-specific create logic
-	// - Validate graph structure (nodes and edges)
-	// - Store in graph database (Neo4j)
-	// - Handle graph relationships
-	fmt.Printf("Creating graph attribute %s for entity %s\n", attrName, entityID)
-	return &Result{
-		Data:    nil,
-		Success: true,
-		Error:   nil,
-	}
+	// TODO: store the nodes and edges in the graph database so that they can be traversed
+	logging.FromContext(ctx).DebugContext(ctx, "Creating graph attribute", "entity_id", entityID, "attribute", attrName)
+	return r.saveValue(ctx, entityID, attrName, value)
 }
 
+// ReadResolve returns every value of the attribute ordered by start time
 func (r *GraphAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
-	// TODO: implement graph-specific read logic
-	// - Query graph database
-	// - Retrieve nodes and edges
-	// - Return graph structure
-	fmt.Printf("Reading graph attribute %s for entity %s with filters: %+v and fields: %+v\n", attrName, entityID, filters, fields)
-
-	// TODO: Return actual graph data from Neo4j
-	// For now, return empty TimeBasedValue
-	timeBasedValue := &pb.TimeBasedValue{
-		StartTime: "",
-		EndTime:   "",
-		Value:     nil, // TODO: Convert graph data to Any
-	}
-
-	return &Result{
-		Data:    timeBasedValue,
-		Success: true,
-		Error:   nil,
-	}
+	logging.FromContext(ctx).DebugContext(ctx, "Reading graph attribute", "entity_id", entityID, "attribute", attrName, "fields", fields, logging.Payload("filters", filters))
+	return r.readValues(ctx, entityID, attrName)
 }
 
+// UpdateResolve stores a new value, the value with the same start time is replaced
 func (r *GraphAttributeResolver) UpdateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
-	// TODO: implement graph-specific update logic
-	// - Update nodes and edges
-	// - Handle graph modifications
-	// - Maintain graph consistency
-	fmt.Printf("Updating graph attribute %s for entity %s\n", attrName, entityID)
-	return &Result{
-		Data:    nil,
-		Success: true,
-		Error:   nil,
-	}
+	return r.CreateResolve(ctx, entityID, attrName, value)
 }
 
 func (r *GraphAttributeResolver) DeleteResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
@@ -432,7 +506,7 @@ func (r *GraphAttributeResolver) DeleteResolve(ctx context.Context, entityID, at
 	// - Remove nodes and edges
 	// - Clean up relationships
 	// - Handle cascading deletes
-	fmt.Printf("Deleting graph attribute %s for entity %s\n", attrName, entityID)
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting graph attribute", "entity_id", entityID, "attribute", attrName)
 	return &Result{
 		Data:    nil,
 		Success: true,
@@ -446,6 +520,13 @@ type TabularAttributeResolver struct {
 }
 
 func (r *TabularAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	return r.CreateResolveWithSchema(ctx, entityID, attrName, value, nil)
+}
+
+// CreateResolveWithSchema stores tabular data under the declared schema, or under the schema inferred
+// from the value when none is declared. Once a schema is declared for an attribute, every later write
+// is validated against the stored schema instead of extending it.
+func (r *TabularAttributeResolver) CreateResolveWithSchema(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, declared *schema.SchemaInfo) *Result {
 	// TODO: startDate and endDate must be stored in somewhere in the tabular database.
 	//  this will be useful for schema evolution setup.
 	startDate := value.StartTime
@@ -461,14 +542,14 @@ func (r *TabularAttributeResolver) CreateResolve(ctx context.Context, entityID,
 		}
 	}
 
-	fmt.Printf("Creating tabular attribute %s for entity %s (validated as tabular) from %v to %v\n", attrName, entityID, startDate, endDate)
+	logging.FromContext(ctx).DebugContext(ctx, "Creating tabular attribute", "entity_id", entityID, "attribute", attrName, "start", startDate, "end", endDate, "declared", declared != nil)
 
-	repo, err := dbcommons.GetPostgresRepository(ctx)
-	if err != nil {
+	repo := r.repos.Tabular
+	if repo == nil {
 		return &Result{
 			Data:    nil,
 			Success: false,
-			Error:   fmt.Errorf("failed to get Postgres repository: %v", err),
+			Error:   fmt.Errorf("failed to get tabular store: store is not configured"),
 		}
 	}
 
@@ -481,12 +562,12 @@ func (r *TabularAttributeResolver) CreateResolve(ctx context.Context, entityID,
 		}
 	}
 
-	schemaInfo, err := schema.GenerateSchema(value.Value)
+	schemaInfo, err := r.resolveSchema(ctx, entityID, attrName, value, declared)
 	if err != nil {
 		return &Result{
 			Data:    nil,
 			Success: false,
-			Error:   fmt.Errorf("failed to generate schema: %v", err),
+			Error:   err,
 		}
 	}
 
@@ -506,25 +587,78 @@ func (r *TabularAttributeResolver) CreateResolve(ctx context.Context, entityID,
 	}
 }
 
+// resolveSchema returns the schema a tabular value is stored under. A schema can only be declared
+// before the first write of an attribute, afterwards it has to match the stored one.
+func (r *TabularAttributeResolver) resolveSchema(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, declared *schema.SchemaInfo) (*schema.SchemaInfo, error) {
+	tableName, err := r.repos.Tabular.AttributeTable(ctx, entityID, attrName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find table of attribute %s: %v", attrName, err)
+	}
+	stored, err := r.repos.Tabular.GetSchemaOfTable(ctx, tableName)
+	if errors.Is(err, dbcommons.ErrNotFound) {
+		stored = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read stored schema: %v", err)
+	}
+
+	switch {
+	case declared != nil && stored != nil && !stored.Declared:
+		return nil, fmt.Errorf("%w: attribute %s already has an inferred schema, schemas can only be declared before the first write", ErrSchemaConflict, attrName)
+	case declared != nil && stored != nil && !sameSchema(declared, stored):
+		return nil, fmt.Errorf("%w: attribute %s already has a different declared schema", ErrSchemaConflict, attrName)
+	case declared == nil && stored != nil && stored.Declared:
+		declared = stored
+	}
+
+	if declared == nil {
+		schemaInfo, err := schema.GenerateSchema(value.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate schema: %w", err)
+		}
+		return schemaInfo, nil
+	}
+
+	if err := schema.ValidateTable(value.Value, declared); err != nil {
+		return nil, fmt.Errorf("attribute %s: %w", attrName, err)
+	}
+	schemaInfo := *declared
+	schemaInfo.Declared = true
+	return &schemaInfo, nil
+}
+
+// sameSchema checks if two schemas describe the same data, their JSON Schema documents are compared
+// so that differences in the type information of containers are ignored
+func sameSchema(a, b *schema.SchemaInfo) bool {
+	aDocument, aErr := schema.MarshalJSONSchema(a)
+	bDocument, bErr := schema.MarshalJSONSchema(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aDocument, bDocument)
+}
+
 func (r *TabularAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
 	// TODO: implement tabular-specific read logic
 	// - Query database table
 	// - Retrieve rows and columns
 	// - Return tabular structure
-	fmt.Printf("[TabularAttributeResolver.ReadResolve] Reading tabular attribute %s for entity %s with filters: %+v and fields: %+v\n", attrName, entityID, filters, fields)
+	logging.FromContext(ctx).DebugContext(ctx, "Reading tabular attribute", "entity_id", entityID, "attribute", attrName, "fields", fields, logging.Payload("filters", filters))
 
-	repo, err := dbcommons.GetPostgresRepository(ctx)
-	if err != nil {
+	repo := r.repos.Tabular
+	if repo == nil {
 		return &Result{
 			Data:    nil,
 			Success: false,
-			Error:   fmt.Errorf("failed to get Postgres repository: %v", err),
+			Error:   fmt.Errorf("failed to get tabular store: store is not configured"),
 		}
 	}
 
 	// Get the table name for this attribute
-	tableName := fmt.Sprintf("attr_%s_%s", commons.SanitizeIdentifier(entityID), commons.SanitizeIdentifier(attrName))
-	log.Printf("[TabularAttributeResolver.ReadResolve] tableName: %s", tableName)
+	tableName, err := repo.AttributeTable(ctx, entityID, attrName)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to find table of attribute %s: %v", attrName, err),
+		}
+	}
 
 	// Use the GetData method from the repository to retrieve data with filters and fields
 	anyData, err := repo.GetData(ctx, tableName, filters, fields...)
@@ -536,7 +670,7 @@ func (r *TabularAttributeResolver) ReadResolve(ctx context.Context, entityID, at
 		}
 	}
 
-	fmt.Printf("Retrieved data from table %s\n", tableName)
+	logging.FromContext(ctx).DebugContext(ctx, "Retrieved data from table", "table", tableName)
 
 	// The data is already in the correct format (pb.Any with JSON)
 	timeBasedValue := &pb.TimeBasedValue{
@@ -557,7 +691,7 @@ func (r *TabularAttributeResolver) UpdateResolve(ctx context.Context, entityID,
 	// - Update table schema if needed
 	// - Update data rows
 	// - Handle schema evolution
-	fmt.Printf("Updating tabular attribute %s for entity %s\n", attrName, entityID)
+	logging.FromContext(ctx).DebugContext(ctx, "Updating tabular attribute", "entity_id", entityID, "attribute", attrName)
 	return &Result{
 		Data:    nil,
 		Success: true,
@@ -570,7 +704,7 @@ func (r *TabularAttributeResolver) DeleteResolve(ctx context.Context, entityID,
 	// - Delete data rows
 	// - Optionally drop table
 	// - Clean up schema
-	fmt.Printf("Deleting tabular attribute %s for entity %s\n", attrName, entityID)
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting tabular attribute", "entity_id", entityID, "attribute", attrName)
 	return &Result{
 		Data:    nil,
 		Success: true,
@@ -578,57 +712,26 @@ func (r *TabularAttributeResolver) DeleteResolve(ctx context.Context, entityID,
 	}
 }
 
-// DocumentAttributeResolver handles document/map data structures with key-value pairs
+// DocumentAttributeResolver handles document/map data structures with key-value pairs.
+// The documents are kept in the metadata store with their start and end times.
 type DocumentAttributeResolver struct {
 	BaseAttributeResolver
 }
 
 func (r *DocumentAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
-	// TODO: implement document-specific create logic
-	// - Validate document structure
-	// - Store in document database (MongoDB)
-	// - Handle document indexing
-	fmt.Printf("Creating document attribute %s for entity %s\n", attrName, entityID)
-	return &Result{
-		Data:    nil,
-		Success: true,
-		Error:   nil,
-	}
+	logging.FromContext(ctx).DebugContext(ctx, "Creating document attribute", "entity_id", entityID, "attribute", attrName)
+	return r.saveValue(ctx, entityID, attrName, value)
 }
 
+// ReadResolve returns every value of the attribute ordered by start time
 func (r *DocumentAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
-	// TODO: implement document-specific read logic
-	// - Query document database
-	// - Retrieve document structure
-	// - Return key-value pairs
-	fmt.Printf("Reading document attribute %s for entity %s with filters: %+v and fields: %+v\n", attrName, entityID, filters, fields)
-
-	// TODO: Return actual document data from MongoDB
-	// For now, return empty TimeBasedValue
-	timeBasedValue := &pb.TimeBasedValue{
-		StartTime: "",
-		EndTime:   "",
-		Value:     nil, // TODO: Convert document data to Any
-	}
-
-	return &Result{
-		Data:    timeBasedValue,
-		Success: true,
-		Error:   nil,
-	}
+	logging.FromContext(ctx).DebugContext(ctx, "Reading document attribute", "entity_id", entityID, "attribute", attrName, "fields", fields, logging.Payload("filters", filters))
+	return r.readValues(ctx, entityID, attrName)
 }
 
+// UpdateResolve stores a new value, the value with the same start time is replaced
 func (r *DocumentAttributeResolver) UpdateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
-	// TODO: implement document-specific update logic
-	// - Update document fields
-	// - Handle partial updates
-	// - Maintain document consistency
-	fmt.Printf("Updating document attribute %s for entity %s\n", attrName, entityID)
-	return &Result{
-		Data:    nil,
-		Success: true,
-		Error:   nil,
-	}
+	return r.CreateResolve(ctx, entityID, attrName, value)
 }
 
 func (r *DocumentAttributeResolver) DeleteResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
@@ -636,7 +739,7 @@ func (r *DocumentAttributeResolver) DeleteResolve(ctx context.Context, entityID,
 	// - Remove document
 	// - Clean up indexes
 	// - Handle cascading deletes
-	fmt.Printf("Deleting document attribute %s for entity %s\n", attrName, entityID)
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting document attribute", "entity_id", entityID, "attribute", attrName)
 	return &Result{
 		Data:    nil,
 		Success: true,
@@ -3,11 +3,14 @@ package engine
 import (
 	"context"
 	"fmt"
+	"os"
+	"sync"
 	"testing"
 	"time"
 
 	"lk/datafoundation/crud-api/commons"
 	dbcommons "lk/datafoundation/crud-api/commons/db"
+	"lk/datafoundation/crud-api/db/config"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
 	"lk/datafoundation/crud-api/pkg/schema"
 	"lk/datafoundation/crud-api/pkg/storageinference"
@@ -15,6 +18,49 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// memoryRepos backs the tests that need no database
+var memoryRepos = dbcommons.NewMemoryRepositories(config.RelationshipIntegrityConfig{})
+
+var (
+	databaseOnce  sync.Once
+	databaseRepos *dbcommons.Repositories
+	databaseErr   error
+)
+
+// testDatabase returns the repositories connected to the databases, the connection is shared by every test.
+// The test is skipped when the databases cannot be reached.
+func testDatabase(t *testing.T) *dbcommons.Repositories {
+	t.Helper()
+	databaseOnce.Do(func() {
+		ctx := context.Background()
+		databaseRepos, databaseErr = dbcommons.NewRepositoriesFromEnv(ctx)
+		if databaseErr != nil {
+			return
+		}
+		for store, err := range databaseRepos.Ping(ctx) {
+			if err != nil {
+				databaseErr = fmt.Errorf("%s store: %v", store, err)
+				databaseRepos.Close(ctx)
+				databaseRepos = nil
+				return
+			}
+		}
+	})
+	if databaseErr != nil {
+		t.Skipf("Databases are not available: %v", databaseErr)
+	}
+	return databaseRepos
+}
+
+// TestMain closes the database connection after the tests that used it
+func TestMain(m *testing.M) {
+	exitCode := m.Run()
+	if databaseRepos != nil {
+		databaseRepos.Close(context.Background())
+	}
+	os.Exit(exitCode)
+}
+
 // createTimeBasedValue creates a TimeBasedValue with the given JSON data
 func createTimeBasedValue(jsonStr string) (*pb.TimeBasedValue, error) {
 	anyValue, err := schema.JSONToAny(jsonStr)
@@ -56,13 +102,8 @@ func createEntityWithAttributes(entityID string, entityName string, attributes m
 	return entity, nil
 }
 
-func saveEntityToDatabase(ctx context.Context, entity *pb.Entity) error {
-	neo4jRepository, err := dbcommons.GetNeo4jRepository(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get Neo4j repository: %w", err)
-	}
-
-	success, err := neo4jRepository.HandleGraphEntityCreation(ctx, entity)
+func saveEntityToDatabase(ctx context.Context, repos *dbcommons.Repositories, entity *pb.Entity) error {
+	success, err := repos.Graph.HandleGraphEntityCreation(ctx, entity)
 	if !success {
 		return fmt.Errorf("failed to save entity: %w", err)
 	}
@@ -86,6 +127,7 @@ func getOptionsForOperation(operation string) *Options {
 
 // TestEntityWithGraphDataOnly tests an entity containing only graph data
 func TestEntityWithGraphDataOnly(t *testing.T) {
+	repos := testDatabase(t)
 	ctx := context.Background()
 	graphData := `{
 		"nodes": [
@@ -106,10 +148,10 @@ func TestEntityWithGraphDataOnly(t *testing.T) {
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 
 	// Test all CRUD operations
 	// create test merely checks if the ProcessEntityAttributes function is working
@@ -131,6 +173,7 @@ func TestEntityWithGraphDataOnly(t *testing.T) {
 
 // TestEntityWithTabularDataOnly tests an entity containing only tabular data
 func TestEntityWithTabularDataOnly(t *testing.T) {
+	repos := testDatabase(t)
 	tabularData := `{
 		"columns": ["id", "name", "age", "department"],
 		"rows": [
@@ -148,10 +191,10 @@ func TestEntityWithTabularDataOnly(t *testing.T) {
 	// save parent entity to the database
 	ctx := context.Background()
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 
 	// Test all CRUD operations
 	// TODO: "read", "update", "delete"
@@ -172,6 +215,7 @@ func TestEntityWithTabularDataOnly(t *testing.T) {
 
 // TestEntityWithDocumentDataOnly tests an entity containing only document data
 func TestEntityWithDocumentDataOnly(t *testing.T) {
+	repos := testDatabase(t)
 	documentData := `{
 		"user_profile": {
 			"name": "John Doe",
@@ -199,10 +243,10 @@ func TestEntityWithDocumentDataOnly(t *testing.T) {
 	ctx := context.Background()
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 
 	// Test all CRUD operations
 	operations := []string{"create", "read", "update", "delete"}
@@ -222,6 +266,7 @@ func TestEntityWithDocumentDataOnly(t *testing.T) {
 
 // TestEntityWithMixedDataTypes tests an entity containing mixed data types
 func TestEntityWithMixedDataTypes(t *testing.T) {
+	repos := testDatabase(t)
 	graphData := `{
 		"nodes": [
 			{"id": "user1", "type": "user", "properties": {"name": "Alice"}},
@@ -258,10 +303,10 @@ func TestEntityWithMixedDataTypes(t *testing.T) {
 	// save parent entity to the database
 	ctx := context.Background()
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 
 	// Test all CRUD operations
 	// TODO: "read", "update", "delete"
@@ -282,6 +327,7 @@ func TestEntityWithMixedDataTypes(t *testing.T) {
 
 // TestComplexGraphEntity tests a complex graph entity with multiple node and edge types
 func TestComplexGraphEntity(t *testing.T) {
+	repos := testDatabase(t)
 	complexGraphData := `{
 		"nodes": [
 			{"id": "user1", "type": "user", "properties": {"name": "Alice", "age": 30, "location": "NY"}},
@@ -307,12 +353,12 @@ func TestComplexGraphEntity(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test all CRUD operations
@@ -334,6 +380,7 @@ func TestComplexGraphEntity(t *testing.T) {
 
 // TestComplexTabularEntity tests a complex tabular entity with various data types
 func TestComplexTabularEntity(t *testing.T) {
+	repos := testDatabase(t)
 	complexTabularData := `{
 		"columns": ["id", "name", "age", "salary", "department", "is_active", "hire_date", "last_login"],
 		"rows": [
@@ -350,12 +397,12 @@ func TestComplexTabularEntity(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test all CRUD operations
@@ -376,6 +423,7 @@ func TestComplexTabularEntity(t *testing.T) {
 
 // TestComplexDocumentEntity tests a complex document entity with nested structures
 func TestComplexDocumentEntity(t *testing.T) {
+	repos := testDatabase(t)
 	complexDocumentData := `{
 		"user_profile": {
 			"personal_info": {
@@ -420,12 +468,12 @@ func TestComplexDocumentEntity(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test all CRUD operations
@@ -446,6 +494,7 @@ func TestComplexDocumentEntity(t *testing.T) {
 
 // TestEntityWithMultipleAttributesOfSameType tests an entity with multiple attributes of the same type
 func TestEntityWithMultipleAttributesOfSameType(t *testing.T) {
+	repos := testDatabase(t)
 	graphData1 := `{
 		"nodes": [{"id": "user1", "type": "user", "properties": {"name": "Alice"}}],
 		"edges": []
@@ -484,12 +533,12 @@ func TestEntityWithMultipleAttributesOfSameType(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test all CRUD operations
@@ -546,7 +595,7 @@ func TestStorageTypeDetection(t *testing.T) {
 		},
 	}
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(memoryRepos)
 
 	for testName, testCase := range testCases {
 		t.Run(testName, func(t *testing.T) {
@@ -572,7 +621,7 @@ func TestEmptyEntity(t *testing.T) {
 		Attributes: make(map[string]*pb.TimeBasedValueList),
 	}
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(memoryRepos)
 	ctx := context.Background()
 
 	// Test all CRUD operations
@@ -593,7 +642,7 @@ func TestEmptyEntity(t *testing.T) {
 
 // TestNilEntity tests handling of nil entity
 func TestNilEntity(t *testing.T) {
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(memoryRepos)
 	ctx := context.Background()
 
 	// Test all CRUD operations
@@ -625,7 +674,7 @@ func TestInvalidOperation(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(memoryRepos)
 	ctx := context.Background()
 
 	options := getOptionsForOperation("invalid_operation")
@@ -646,32 +695,33 @@ func TestInvalidOperation(t *testing.T) {
 
 // TestUnsupportedStorageType tests handling of unsupported storage types
 func TestUnsupportedStorageType(t *testing.T) {
+	repos := testDatabase(t)
 	entity, err := createEntityWithAttributes("id-unsupported-storage-type-entity-1", "unsupported-storage-type-entity-1", map[string]string{
-		"scalar_data": `42`,
+		"list_data": `[1, 2, 3]`,
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save parent entity to the database
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Should not error, but should log a warning and skip the attribute
 	options := getOptionsForOperation("create")
 	attributeResults := processor.ProcessEntityAttributes(ctx, entity, "create", options)
 
-	// Check that scalar_data attribute was handled appropriately (skipped due to unsupported storage type)
-	if result, exists := attributeResults["scalar_data"]; exists {
-		// The attribute should be marked as failed since no resolver exists for scalar data
-		assert.False(t, result.Success, "Attribute scalar_data should fail due to unsupported storage type")
-		assert.Error(t, result.Error, "Attribute scalar_data should have an error for unsupported storage type")
-		assert.Contains(t, result.Error.Error(), "no resolver found for storage type scalar")
+	// Check that list_data attribute was handled appropriately (skipped due to unsupported storage type)
+	if result, exists := attributeResults["list_data"]; exists {
+		// The attribute should be marked as failed since no resolver exists for list data
+		assert.False(t, result.Success, "Attribute list_data should fail due to unsupported storage type")
+		assert.Error(t, result.Error, "Attribute list_data should have an error for unsupported storage type")
+		assert.Contains(t, result.Error.Error(), "no resolver found for storage type list")
 	} else {
 		// If the attribute was completely skipped, that's also acceptable
-		t.Logf("Attribute scalar_data was skipped (no result returned)")
+		t.Logf("Attribute list_data was skipped (no result returned)")
 	}
 
 	assert.NoError(t, err) // Should handle gracefully
@@ -679,8 +729,9 @@ func TestUnsupportedStorageType(t *testing.T) {
 
 // TestBasicFunctionality tests basic functionality of the attribute resolver
 func TestBasicFunctionality(t *testing.T) {
+	repos := testDatabase(t)
 	// Test that we can create a processor
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	assert.NotNil(t, processor)
 	assert.NotNil(t, processor.resolvers)
 
@@ -699,7 +750,7 @@ func TestBasicFunctionality(t *testing.T) {
 	// Test processing
 	ctx := context.Background()
 	fmt.Printf("Saving entity to database: %+v\n", entity)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	options := getOptionsForOperation("create")
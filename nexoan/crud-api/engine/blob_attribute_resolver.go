@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/metadatahistory"
+	"lk/datafoundation/crud-api/pkg/storageinference"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// Keys of the attribute metadata describing the content of a blob attribute
+const (
+	blobDigestKey      = "digest"
+	blobContentTypeKey = "content_type"
+	blobSizeKey        = "size"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at
+const sniffLength = 512
+
+// ErrNoBlob is returned when an attribute has no blob content
+var ErrNoBlob = errors.New("attribute has no blob content")
+
+// BlobInfo describes the stored content of a blob attribute
+type BlobInfo struct {
+	Digest      string // sha256:<hex> digest of the content in the blob store
+	Size        int64  // Size of the content in bytes
+	ContentType string // MIME type of the content
+}
+
+// BlobAttributeResolver handles binary content such as PDF gazettes and images.
+// The content is kept in the blob store, addressed by its digest, and the attribute metadata
+// records its digest, MIME type and size.
+type BlobAttributeResolver struct {
+	BaseAttributeResolver
+}
+
+func (r *BlobAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	return r.CreateResolveRecordedBy(ctx, entityID, attrName, value, "")
+}
+
+// CreateResolveRecordedBy stores the content of a blob attribute and records recordedBy as the caller changing
+// its metadata
+func (r *BlobAttributeResolver) CreateResolveRecordedBy(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue, recordedBy string) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Creating blob attribute", "entity_id", entityID, "attribute", attrName)
+	blob := &pb.BlobValue{}
+	if err := value.GetValue().UnmarshalTo(blob); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to unpack blob value: %v", err),
+		}
+	}
+	info, err := r.Store(ctx, entityID, attrName, blob.ContentType, bytes.NewReader(blob.Data), recordedBy)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	return &Result{
+		Data:    info,
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// ReadResolve returns the content type of a blob attribute without its content, which is streamed by DownloadBlob
+func (r *BlobAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Reading blob attribute", "entity_id", entityID, "attribute", attrName)
+	info, err := r.Describe(ctx, entityID, attrName)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	value, err := anypb.New(&pb.BlobValue{ContentType: info.ContentType})
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to pack blob value: %v", err),
+		}
+	}
+	return &Result{
+		Data:    &pb.TimeBasedValue{Value: value},
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// UpdateResolve replaces the content of a blob attribute
+func (r *BlobAttributeResolver) UpdateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	return r.CreateResolve(ctx, entityID, attrName, value)
+}
+
+// DeleteResolve leaves the content in the blob store since other attributes may hold the same content
+func (r *BlobAttributeResolver) DeleteResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting blob attribute", "entity_id", entityID, "attribute", attrName)
+	return &Result{
+		Data:    nil,
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// Store writes content to the blob store and records its digest, MIME type and size in the attribute metadata,
+// changed by the subject recordedBy. The MIME type is detected from the first bytes of the content when it is empty.
+func (r *BlobAttributeResolver) Store(ctx context.Context, entityID, attrName, contentType string, content io.Reader, recordedBy string) (*BlobInfo, error) {
+	if err := r.checkStorageType(ctx, entityID, attrName); err != nil {
+		return nil, err
+	}
+	info, err := r.put(ctx, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.record(ctx, entityID, attrName, info, recordedBy); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// checkStorageType fails with ErrNoBlob when the attribute already holds data other than a blob
+func (r *BlobAttributeResolver) checkStorageType(ctx context.Context, entityID, attrName string) error {
+	metadata, err := r.repos.Metadata.GetMetadata(ctx, GenerateAttributeID(entityID, attrName), "")
+	if err != nil {
+		return fmt.Errorf("failed to read attribute metadata: %v", err)
+	}
+	if storageType := commons.ExtractStringFromAny(metadata["storage_type"]); storageType != "" && storageType != string(storageinference.BlobData) {
+		return fmt.Errorf("%w: attribute %s holds %s data", ErrNoBlob, attrName, storageType)
+	}
+	return nil
+}
+
+// put writes content to the blob store, the MIME type is detected from the first bytes of the content when
+// contentType is empty
+func (r *BlobAttributeResolver) put(ctx context.Context, contentType string, content io.Reader) (*BlobInfo, error) {
+	if r.repos.Blob == nil {
+		return nil, fmt.Errorf("failed to get blob store: store is not configured")
+	}
+	reader := bufio.NewReaderSize(content, sniffLength)
+	if contentType == "" {
+		head, err := reader.Peek(sniffLength)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read content: %v", err)
+		}
+		contentType = http.DetectContentType(head)
+	}
+	object, err := r.repos.Blob.Put(ctx, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store content: %w", err)
+	}
+	return &BlobInfo{Digest: object.Digest, Size: object.Size, ContentType: contentType}, nil
+}
+
+// record saves the digest, MIME type and size of stored content in the attribute metadata, changed by the
+// subject recordedBy
+func (r *BlobAttributeResolver) record(ctx context.Context, entityID, attrName string, info *BlobInfo, recordedBy string) error {
+	attributeID := GenerateAttributeID(entityID, attrName)
+
+	// Only the blob fields are changed, the other attribute metadata keeps its values
+	size, err := anypb.New(wrapperspb.Int64(info.Size))
+	if err != nil {
+		return fmt.Errorf("failed to pack blob size: %v", err)
+	}
+	change := &metadatahistory.Change{
+		Set: map[string]*anypb.Any{
+			blobDigestKey:      commons.ConvertStringToAny(info.Digest),
+			blobContentTypeKey: commons.ConvertStringToAny(info.ContentType),
+			blobSizeKey:        size,
+			"updated":          commons.ConvertStringToAny(time.Now().Format(time.RFC3339)),
+		},
+		RecordedBy: recordedBy,
+	}
+	if err := r.repos.Metadata.HandleMetadata(ctx, attributeID, change); err != nil {
+		return fmt.Errorf("failed to save attribute metadata: %v", err)
+	}
+
+	logging.FromContext(ctx).DebugContext(ctx, "Stored blob content", "entity_id", entityID, "attribute", attrName,
+		"digest", info.Digest, "size", info.Size, "content_type", info.ContentType)
+	return nil
+}
+
+// Describe returns the digest, MIME type and size recorded in the attribute metadata, or ErrNoBlob
+func (r *BlobAttributeResolver) Describe(ctx context.Context, entityID, attrName string) (*BlobInfo, error) {
+	metadata, err := r.repos.Metadata.GetMetadata(ctx, GenerateAttributeID(entityID, attrName), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attribute metadata: %v", err)
+	}
+	digest := commons.ExtractStringFromAny(metadata[blobDigestKey])
+	if digest == "" {
+		return nil, fmt.Errorf("%w: attribute %s of entity %s", ErrNoBlob, attrName, entityID)
+	}
+	size := &wrapperspb.Int64Value{}
+	if sizeValue := metadata[blobSizeKey]; sizeValue != nil {
+		if err := sizeValue.UnmarshalTo(size); err != nil {
+			return nil, fmt.Errorf("failed to unpack blob size: %v", err)
+		}
+	}
+	return &BlobInfo{
+		Digest:      digest,
+		Size:        size.Value,
+		ContentType: commons.ExtractStringFromAny(metadata[blobContentTypeKey]),
+	}, nil
+}
+
+// UploadBlob creates a blob attribute holding content streamed by the caller, given by the create options.
+// The attribute starts at startTime and its MIME type is detected from the content when contentType is empty.
+// The attribute is only created once the content is stored, so a rejected upload leaves no attribute behind.
+func (p *EntityAttributeProcessor) UploadBlob(ctx context.Context, entityID, attrName string, startTime time.Time, contentType string, content io.Reader, options *Options) (*BlobInfo, error) {
+	resolver := p.blobResolver()
+	if err := resolver.checkStorageType(ctx, entityID, attrName); err != nil {
+		return nil, err
+	}
+	info, err := resolver.put(ctx, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.handleAttributeLookUp(ctx, entityID, attrName, storageinference.BlobData, "create", startTime); err != nil {
+		return nil, fmt.Errorf("error handling graph metadata for attribute %s: %v", attrName, err)
+	}
+	if err := resolver.record(ctx, entityID, attrName, info, options.recordedBy("create")); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// OpenBlob returns the content of a blob attribute, the caller closes it. ErrNoBlob is returned when the
+// attribute has no blob content.
+func (p *EntityAttributeProcessor) OpenBlob(ctx context.Context, entityID, attrName string) (io.ReadCloser, *BlobInfo, error) {
+	resolver := p.blobResolver()
+	info, err := resolver.Describe(ctx, entityID, attrName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resolver.repos.Blob == nil {
+		return nil, nil, fmt.Errorf("failed to get blob store: store is not configured")
+	}
+	content, _, err := resolver.repos.Blob.Open(ctx, info.Digest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open content of attribute %s: %w", attrName, err)
+	}
+	return content, info, nil
+}
+
+// blobResolver returns the resolver of blob attributes
+func (p *EntityAttributeProcessor) blobResolver() *BlobAttributeResolver {
+	return p.resolvers[storageinference.BlobData].(*BlobAttributeResolver)
+}
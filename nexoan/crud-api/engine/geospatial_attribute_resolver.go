@@ -0,0 +1,202 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/geo"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/storageinference"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GeospatialAttributeResolver handles GeoJSON geometries such as the boundary of a district.
+// The geometries are kept in the spatial store with their start and end times, which finds the entities
+// whose geometry at a time contains a point or intersects a bounding box.
+type GeospatialAttributeResolver struct {
+	BaseAttributeResolver
+
+	// initialized is set once the geometry table has been created, initMu guards it
+	initMu      sync.Mutex
+	initialized bool
+}
+
+// initializeGeometries creates the geometry table of the spatial store the first time a geometry is written
+// or queried. A failure is retried by the next call.
+func (r *GeospatialAttributeResolver) initializeGeometries(ctx context.Context) error {
+	r.initMu.Lock()
+	defer r.initMu.Unlock()
+	if r.initialized {
+		return nil
+	}
+	if err := r.repos.Spatial.InitializeGeometries(ctx); err != nil {
+		return fmt.Errorf("failed to initialize geometry table: %v", err)
+	}
+	r.initialized = true
+	return nil
+}
+
+func (r *GeospatialAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Creating geospatial attribute", "entity_id", entityID, "attribute", attrName)
+	geoJSON := &structpb.Struct{}
+	if err := value.GetValue().UnmarshalTo(geoJSON); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to unpack geospatial value: %v", err),
+		}
+	}
+	geometry, err := geo.Parse(geoJSON)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	startTime, endTime, err := geometryPeriod(value)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+
+	repo := r.repos.Spatial
+	if repo == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to get spatial store: store is not configured"),
+		}
+	}
+	if err := r.initializeGeometries(ctx); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	if err := repo.SaveGeometry(ctx, entityID, attrName, geometry, startTime, endTime); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	return &Result{
+		Data:    nil,
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// geometryPeriod parses the start and end times of a geometry, an empty time is returned as the zero time
+func geometryPeriod(value *pb.TimeBasedValue) (time.Time, time.Time, error) {
+	var startTime, endTime time.Time
+	var err error
+	if value.StartTime != "" {
+		if startTime, err = time.Parse(time.RFC3339, value.StartTime); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start time %q is not an RFC3339 timestamp", value.StartTime)
+		}
+	}
+	if value.EndTime != "" {
+		if endTime, err = time.Parse(time.RFC3339, value.EndTime); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end time %q is not an RFC3339 timestamp", value.EndTime)
+		}
+		if !endTime.After(startTime) {
+			return time.Time{}, time.Time{}, fmt.Errorf("end time %s is not after start time %s", value.EndTime, value.StartTime)
+		}
+	}
+	return startTime, endTime, nil
+}
+
+// ReadResolve returns the latest stored geometry as GeoJSON, a feature is returned as its geometry
+func (r *GeospatialAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Reading geospatial attribute", "entity_id", entityID, "attribute", attrName)
+	repo := r.repos.Spatial
+	if repo == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to get spatial store: store is not configured"),
+		}
+	}
+	geometry, err := repo.GetGeometry(ctx, entityID, attrName)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	geoJSON, err := geometry.Struct()
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	value, err := anypb.New(geoJSON)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to pack geospatial value: %v", err),
+		}
+	}
+	return &Result{
+		Data:    &pb.TimeBasedValue{Value: value},
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// UpdateResolve stores a geometry of an attribute, the geometry with the same start time is replaced
+func (r *GeospatialAttributeResolver) UpdateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	return r.CreateResolve(ctx, entityID, attrName, value)
+}
+
+// DeleteResolve removes the geometries so that the entity no longer matches spatial filters
+func (r *GeospatialAttributeResolver) DeleteResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting geospatial attribute", "entity_id", entityID, "attribute", attrName)
+	if r.repos.Spatial == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to get spatial store: store is not configured"),
+		}
+	}
+	if err := r.repos.Spatial.DeleteGeometry(ctx, entityID, attrName); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   err,
+		}
+	}
+	return &Result{
+		Data:    nil,
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// FindEntities returns the sorted IDs of the entities whose geospatial attribute at activeAt matches the filter
+func (p *EntityAttributeProcessor) FindEntities(ctx context.Context, attrName string, filter geo.Filter, activeAt time.Time) ([]string, error) {
+	resolver := p.resolvers[storageinference.GeospatialData].(*GeospatialAttributeResolver)
+	repo := resolver.repos.Spatial
+	if repo == nil {
+		return nil, fmt.Errorf("failed to get spatial store: store is not configured")
+	}
+	if err := resolver.initializeGeometries(ctx); err != nil {
+		return nil, err
+	}
+	return repo.FindEntities(ctx, attrName, filter, activeAt)
+}
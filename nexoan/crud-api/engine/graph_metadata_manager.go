@@ -3,12 +3,13 @@ package engine
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"lk/datafoundation/crud-api/commons"
 	dbcommons "lk/datafoundation/crud-api/commons/db"
 	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/relationships"
 	"lk/datafoundation/crud-api/pkg/storageinference"
 
 	"google.golang.org/protobuf/types/known/anypb"
@@ -19,10 +20,11 @@ const DatasetType = "Dataset"
 
 // DatasetMinorTypes represents the minor types for different storage types
 const (
-	TabularDataset  = "Tabular"
-	GraphDataset    = "Graph"
-	DocumentDataset = "Document"
-	BlobDataset     = "Blob"
+	TabularDataset    = "Tabular"
+	GraphDataset      = "Graph"
+	DocumentDataset   = "Document"
+	BlobDataset       = "Blob"
+	GeospatialDataset = "Geospatial"
 )
 
 // IS_ATTRIBUTE relationship type
@@ -34,13 +36,12 @@ const IS_ATTRIBUTE_RELATIONSHIP_DIRECTION = "OUTGOING"
 
 // GraphMetadataManager handles the reference graph for tracking attributes
 type GraphMetadataManager struct {
-	// This would typically connect to Neo4j or another graph database
-	// For now, we'll define the interface and structure
+	repos *dbcommons.Repositories
 }
 
-// NewGraphMetadataManager creates a new graph metadata manager
-func NewGraphMetadataManager() *GraphMetadataManager {
-	return &GraphMetadataManager{}
+// NewGraphMetadataManager creates a new graph metadata manager using the shared repositories
+func NewGraphMetadataManager(repos *dbcommons.Repositories) *GraphMetadataManager {
+	return &GraphMetadataManager{repos: repos}
 }
 
 // AttributeMetadata represents metadata for an attribute in the graph
@@ -58,8 +59,8 @@ type AttributeMetadata struct {
 
 // CreateAttributeNode creates a node in the graph for an attribute
 func (g *GraphMetadataManager) CreateAttribute(ctx context.Context, metadata *AttributeMetadata) error {
-	fmt.Printf("Creating attribute node: Entity=%s, Attribute=%s, StorageType=%s, Path=%s\n",
-		metadata.EntityID, metadata.AttributeName, metadata.StorageType, metadata.StoragePath)
+	logging.FromContext(ctx).DebugContext(ctx, "Creating attribute node", "entity_id", metadata.EntityID, "attribute", metadata.AttributeName,
+		"storage_type", metadata.StorageType, "storage_path", metadata.StoragePath)
 	// create the attribute look up graph
 	err := g.createAttributeLookUpGraph(ctx, metadata)
 	if err != nil {
@@ -111,8 +112,8 @@ func (g *GraphMetadataManager) CreateAttribute(ctx context.Context, metadata *At
 // Note: This method creates the attribute node and relationship but does not create
 // the parent entity node itself.
 func (g *GraphMetadataManager) createAttributeLookUpGraph(ctx context.Context, metadata *AttributeMetadata) error {
-	fmt.Printf("Creating attribute look up graph: Entity=%s, Attribute=%s, StorageType=%s, Path=%s\n",
-		metadata.EntityID, metadata.AttributeName, metadata.StorageType, metadata.StoragePath)
+	logger := logging.FromContext(ctx).With("entity_id", metadata.EntityID, "attribute", metadata.AttributeName)
+	logger.DebugContext(ctx, "Creating attribute look up graph", "storage_type", metadata.StorageType, "storage_path", metadata.StoragePath)
 	// TODO: Explore a way to update the Look up graph
 	// FIXME: https://github.com/LDFLK/nexoan/issues/288
 
@@ -150,57 +151,57 @@ func (g *GraphMetadataManager) createAttributeLookUpGraph(ctx context.Context, m
 		},
 	}
 
-	neo4jRepository, err := dbcommons.GetNeo4jRepository(ctx)
-	if err != nil {
-		log.Printf("[GraphMetadataManager.CreateAttribute] Error getting Neo4j repository: %v", err)
-		return err
+	graphStore := g.repos.Graph
+	if graphStore == nil {
+		logger.ErrorContext(ctx, "Graph store is not configured")
+		return fmt.Errorf("graph store is not configured")
 	}
 
 	// Check if the attribute node already exists
-	existingEntity, err := neo4jRepository.ReadGraphEntity(ctx, metadata.AttributeID)
+	existingEntity, err := graphStore.ReadGraphEntity(ctx, metadata.AttributeID)
 	if err == nil && existingEntity != nil {
-		log.Printf("[GraphMetadataManager.CreateAttribute] Attribute node already exists: %s, skipping creation", metadata.AttributeID)
+		logger.DebugContext(ctx, "Attribute node already exists, skipping creation", "attribute_id", metadata.AttributeID)
 		// Node already exists, we can still proceed to create/update the relationship
 	} else {
 		// Node doesn't exist, create it
-		success, err := neo4jRepository.HandleGraphEntityCreation(ctx, attributeNode)
+		success, err := graphStore.HandleGraphEntityCreation(ctx, attributeNode)
 		if !success {
-			log.Printf("[GraphMetadataManager.CreateAttribute] Error creating attributeNode as a graph entity: %v", err)
+			logger.ErrorContext(ctx, "Error creating attribute node as a graph entity", "error", err)
 			return err
 		}
-		log.Printf("[GraphMetadataManager.CreateAttribute] Successfully created attribute node for entity: %s, attribute: %s", metadata.EntityID, metadata.AttributeName)
+		logger.DebugContext(ctx, "Created attribute node")
 
 		// FIXME: This means that when updating an attribute we cannot update the relationship
 		// FIXME: https://github.com/LDFLK/nexoan/issues/346
 		// create the relationship between the entity and the attribute
-		err = neo4jRepository.HandleGraphRelationshipsUpdate(ctx, parentNode)
+		err = graphStore.HandleGraphRelationshipsUpdate(relationships.WithInternalWrite(ctx), parentNode)
 		if err != nil {
-			log.Printf("[GraphMetadataManager.CreateAttribute] Error creating relationship between entity and attribute: %v", err)
+			logger.ErrorContext(ctx, "Error creating relationship between entity and attribute", "error", err)
 			return err
 		}
 	}
 
-	log.Printf("[GraphMetadataManager.CreateAttribute] Successfully created relationship for entity: %s, attribute: %s", metadata.EntityID, metadata.AttributeName)
+	logger.DebugContext(ctx, "Created relationship between entity and attribute")
 
 	// create the attribute metadata in the mongo database
 	// stored parameters: attribute_id, attribute_name, storage_type, storage_path, updated, schema
-	mongoRepository := dbcommons.GetMongoRepository(ctx)
+	metadataStore := g.repos.Metadata
 
 	// Check if the attribute metadata already exists
-	existingMetadata, err := mongoRepository.ReadEntity(ctx, metadata.AttributeID)
+	existingMetadata, err := metadataStore.ReadEntity(ctx, metadata.AttributeID)
 	if err == nil && existingMetadata != nil {
-		log.Printf("[GraphMetadataManager.CreateAttribute] Attribute metadata already exists: %s, skipping creation", metadata.AttributeID)
+		logger.DebugContext(ctx, "Attribute metadata already exists, skipping creation", "attribute_id", metadata.AttributeID)
 	} else {
 		// Metadata doesn't exist, create it
-		_, err = mongoRepository.CreateEntity(ctx, attributeNode)
+		err = metadataStore.CreateEntity(ctx, attributeNode)
 		if err != nil {
-			log.Printf("[GraphMetadataManager.CreateAttribute] Error creating attribute metadata: %v", err)
+			logger.ErrorContext(ctx, "Error creating attribute metadata", "error", err)
 			return err
 		}
-		log.Printf("[GraphMetadataManager.CreateAttribute] Successfully created attribute metadata for entity: %s, attribute: %s", metadata.EntityID, metadata.AttributeName)
+		logger.DebugContext(ctx, "Created attribute metadata")
 	}
 
-	log.Print("Lookup graph created successfully!")
+	logger.DebugContext(ctx, "Created attribute look up graph")
 
 	return nil
 }
@@ -243,27 +244,28 @@ func MakeRelationshipFromAttributeMetadata(metadata *AttributeMetadata) *pb.Rela
 
 // GetAttributeMetadata retrieves metadata for an attribute
 func (g *GraphMetadataManager) GetAttribute(ctx context.Context, entityID string, attributeName string, startTime time.Time) (*AttributeMetadata, error) {
-	fmt.Printf("Getting attribute metadata: EntityID=%s, AttributeName=%s\n", entityID, attributeName)
+	logger := logging.FromContext(ctx).With("entity_id", entityID, "attribute", attributeName)
+	logger.DebugContext(ctx, "Getting attribute metadata")
 
-	neo4jRepository, err := dbcommons.GetNeo4jRepository(ctx)
-	if err != nil {
-		log.Printf("[GraphMetadataManager.GetAttribute] Error getting Neo4j repository: %v", err)
-		return nil, err
+	graphStore := g.repos.Graph
+	if graphStore == nil {
+		logger.ErrorContext(ctx, "Graph store is not configured")
+		return nil, fmt.Errorf("graph store is not configured")
 	}
 
 	// Get all IS_ATTRIBUTE relationships for the entity
-	filteredRelationships, err := neo4jRepository.ReadFilteredRelationships(ctx, entityID, map[string]interface{}{"name": IS_ATTRIBUTE_RELATIONSHIP, "direction": IS_ATTRIBUTE_RELATIONSHIP_DIRECTION, "startTime": startTime.Format(time.RFC3339)}, "")
+	filteredRelationships, err := graphStore.ReadFilteredRelationships(ctx, entityID, map[string]interface{}{"name": IS_ATTRIBUTE_RELATIONSHIP, "direction": IS_ATTRIBUTE_RELATIONSHIP_DIRECTION, "startTime": startTime.Format(time.RFC3339)}, "")
 	if err != nil {
-		log.Printf("[GraphMetadataManager.GetAttribute] Error getting relationships: %v", err)
+		logger.ErrorContext(ctx, "Error getting relationships", "error", err)
 		return nil, err
 	}
 
 	if len(filteredRelationships) == 0 {
-		log.Printf("[GraphMetadataManager.GetAttribute] No attributes found for entity %s", entityID)
+		logger.DebugContext(ctx, "No attributes found for entity")
 		return nil, fmt.Errorf("no attributes found for entity %s", entityID)
 	}
 
-	fmt.Printf("Number of related entities: %v\n", len(filteredRelationships))
+	logger.DebugContext(ctx, "Found attribute relationships", "count", len(filteredRelationships))
 
 	// Find the specific attribute by name
 	var targetAttributeID string
@@ -276,14 +278,13 @@ func (g *GraphMetadataManager) GetAttribute(ctx context.Context, entityID string
 		}
 
 		// Get the attribute entity from Neo4j to check its name
-		_, attributeNameTimeBased, _, _, err := neo4jRepository.GetGraphEntity(ctx, attributeID)
+		_, attributeNameTimeBased, _, _, err := graphStore.GetGraphEntity(ctx, attributeID)
 		if err != nil {
-			log.Printf("[GraphMetadataManager.GetAttribute] Error getting attribute entity %s: %v", attributeID, err)
+			logger.WarnContext(ctx, "Error getting attribute entity", "attribute_id", attributeID, "error", err)
 			continue
 		}
 
 		attributeNameStr := commons.ExtractStringFromAny(attributeNameTimeBased.Value)
-		fmt.Printf("Search name: '%s' vs Entity name: '%s'\n", attributeName, attributeNameStr)
 
 		// Check if this entity has the target attribute name
 		if attributeNameStr == attributeName {
@@ -294,15 +295,15 @@ func (g *GraphMetadataManager) GetAttribute(ctx context.Context, entityID string
 	}
 
 	if !found {
-		log.Printf("[GraphMetadataManager.GetAttribute] Attribute '%s' not found for entity %s", attributeName, entityID)
+		logger.DebugContext(ctx, "Attribute not found for entity")
 		return nil, fmt.Errorf("attribute '%s' not found for entity %s", attributeName, entityID)
 	}
 
 	// Get the attribute metadata from MongoDB
-	mongoRepository := dbcommons.GetMongoRepository(ctx)
-	attributeMetadataEntity, err := mongoRepository.ReadEntity(ctx, targetAttributeID)
+	metadataStore := g.repos.Metadata
+	attributeMetadataEntity, err := metadataStore.ReadEntity(ctx, targetAttributeID)
 	if err != nil {
-		log.Printf("[GraphMetadataManager.GetAttribute] Error getting attribute metadata from MongoDB for attribute %s (entity %s): %v", targetAttributeID, entityID, err)
+		logger.ErrorContext(ctx, "Error getting attribute metadata from the metadata store", "attribute_id", targetAttributeID, "error", err)
 		return nil, fmt.Errorf("failed to get attribute metadata from MongoDB for attribute %s (entity %s): %w", targetAttributeID, entityID, err)
 	}
 
@@ -311,12 +312,11 @@ func (g *GraphMetadataManager) GetAttribute(ctx context.Context, entityID string
 
 	// Convert storage type string to StorageType enum
 	storageType := commons.ConvertStorageTypeStringToEnum(storageTypeStr)
-	log.Printf("[GraphMetadataManager.GetAttribute] storageType: %s", storageType)
 
 	// Get creation time from the attribute entity
-	_, _, createdTimeStr, _, err := neo4jRepository.GetGraphEntity(ctx, targetAttributeID)
+	_, _, createdTimeStr, _, err := graphStore.GetGraphEntity(ctx, targetAttributeID)
 	if err != nil {
-		log.Printf("[GraphMetadataManager.GetAttribute] Error getting creation time for attribute %s: %v", targetAttributeID, err)
+		logger.WarnContext(ctx, "Error getting creation time of attribute", "attribute_id", targetAttributeID, "error", err)
 		createdTimeStr = ""
 	}
 
@@ -337,17 +337,18 @@ func (g *GraphMetadataManager) GetAttribute(ctx context.Context, entityID string
 
 // ListEntityAttributes lists all attributes for an entity
 func (g *GraphMetadataManager) ListAttributes(ctx context.Context, entityID string) ([]*AttributeMetadata, error) {
-	fmt.Printf("Listing attributes for entity: %s\n", entityID)
+	logger := logging.FromContext(ctx).With("entity_id", entityID)
+	logger.DebugContext(ctx, "Listing attributes")
 
-	neo4jRepository, err := dbcommons.GetNeo4jRepository(ctx)
-	if err != nil {
-		log.Printf("[GraphMetadataManager.ListAttributes] Error getting Neo4j repository: %v", err)
-		return nil, err
+	graphStore := g.repos.Graph
+	if graphStore == nil {
+		logger.ErrorContext(ctx, "Graph store is not configured")
+		return nil, fmt.Errorf("graph store is not configured")
 	}
 
-	filteredRelationships, err := neo4jRepository.ReadFilteredRelationships(ctx, entityID, map[string]interface{}{"name": IS_ATTRIBUTE_RELATIONSHIP, "direction": IS_ATTRIBUTE_RELATIONSHIP_DIRECTION}, "")
+	filteredRelationships, err := graphStore.ReadFilteredRelationships(ctx, entityID, map[string]interface{}{"name": IS_ATTRIBUTE_RELATIONSHIP, "direction": IS_ATTRIBUTE_RELATIONSHIP_DIRECTION}, "")
 	if err != nil {
-		log.Printf("[GraphMetadataManager.ListAttributes] Error getting relationships: %v", err)
+		logger.ErrorContext(ctx, "Error getting relationships", "error", err)
 		return nil, err
 	}
 
@@ -362,19 +363,19 @@ func (g *GraphMetadataManager) ListAttributes(ctx context.Context, entityID stri
 		// stored parameters: id, kind, name, created
 		//  out of that the GetGraphEntity returns name and createdTime only and we ignore the terminated in this context.
 		// TODO: determine if an attribute needs to be teriminated based on various conditions.
-		_, attributeName, createdTimeStr, _, err := neo4jRepository.GetGraphEntity(ctx, attributeID)
+		_, attributeName, createdTimeStr, _, err := graphStore.GetGraphEntity(ctx, attributeID)
 		if err != nil {
-			log.Printf("[GraphMetadataManager.ListAttributes] Error verifying attribute %s in graph for entity %s: %v", attributeID, entityID, err)
+			logger.ErrorContext(ctx, "Error verifying attribute in the graph", "attribute_id", attributeID, "error", err)
 			return nil, fmt.Errorf("failed to verify attribute %s in graph for entity %s: %w", attributeID, entityID, err)
 		}
 
 		attributeNameStr := commons.ExtractStringFromAny(attributeName.Value)
 
 		// Get the attribute metadata from the mongo database
-		mongoRepository := dbcommons.GetMongoRepository(ctx)
-		attributeMetadataEntity, err := mongoRepository.ReadEntity(ctx, attributeID)
+		metadataStore := g.repos.Metadata
+		attributeMetadataEntity, err := metadataStore.ReadEntity(ctx, attributeID)
 		if err != nil {
-			log.Printf("[GraphMetadataManager.ListAttributes] Error getting attribute metadata from MongoDB for attribute %s (entity %s): %v", attributeID, entityID, err)
+			logger.ErrorContext(ctx, "Error getting attribute metadata from the metadata store", "attribute_id", attributeID, "error", err)
 			return nil, fmt.Errorf("failed to get attribute metadata from MongoDB for attribute %s (entity %s): %w", attributeID, entityID, err)
 		}
 
@@ -408,7 +409,7 @@ func (g *GraphMetadataManager) ListAttributes(ctx context.Context, entityID stri
 func (g *GraphMetadataManager) UpdateAttribute(ctx context.Context, metadata *AttributeMetadata) error {
 	// TODO: Implement Neo4j or graph database connection
 	// This would update the attribute node properties
-	fmt.Printf("Updating attribute metadata: Entity=%s, Attribute=%s\n", metadata.EntityID, metadata.AttributeName)
+	logging.FromContext(ctx).DebugContext(ctx, "Updating attribute metadata", "entity_id", metadata.EntityID, "attribute", metadata.AttributeName)
 
 	return nil
 }
@@ -418,7 +419,7 @@ func (g *GraphMetadataManager) DeleteAttribute(ctx context.Context, entityID, at
 	// TODO: Implement Neo4j or graph database connection
 	// This would delete the attribute node and its IS_ATTRIBUTE relationship
 
-	fmt.Printf("Deleting attribute node: Entity=%s, Attribute=%s\n", entityID, attributeName)
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting attribute node", "entity_id", entityID, "attribute", attributeName)
 
 	return nil
 }
@@ -432,6 +433,8 @@ func GetDatasetType(storageType storageinference.StorageType) string {
 		return GraphDataset
 	case storageinference.MapData, storageinference.ListData, storageinference.ScalarData:
 		return DocumentDataset
+	case storageinference.GeospatialData:
+		return GeospatialDataset
 	default:
 		return BlobDataset
 	}
@@ -459,6 +462,10 @@ func GenerateStoragePath(entityID, attributeName string, storageType storageinfe
 		return fmt.Sprintf("graphs/attr_%s_%s", entityID, attributeName)
 	case storageinference.MapData, storageinference.ListData, storageinference.ScalarData:
 		return fmt.Sprintf("documents/attr_%s_%s", entityID, attributeName)
+	case storageinference.BlobData:
+		return fmt.Sprintf("blobs/attr_%s_%s", entityID, attributeName)
+	case storageinference.GeospatialData:
+		return fmt.Sprintf("geometries/attr_%s_%s", entityID, attributeName)
 	default:
 		return fmt.Sprintf("unknown/attr_%s_%s", entityID, attributeName)
 	}
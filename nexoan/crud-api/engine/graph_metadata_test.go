@@ -12,7 +12,8 @@ import (
 
 // TestGraphMetadataManager tests the graph metadata manager functionality
 func TestGraphMetadataManager(t *testing.T) {
-	manager := NewGraphMetadataManager()
+	repos := testDatabase(t)
+	manager := NewGraphMetadataManager(repos)
 	assert.NotNil(t, manager)
 
 	ctx := context.Background()
@@ -40,7 +41,7 @@ func TestGraphMetadataManager(t *testing.T) {
 		"test-attribute": `{"columns": ["id", "name"], "types": ["int", "string"]}`,
 	})
 	assert.NoError(t, err)
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test creating attribute node
@@ -124,6 +125,7 @@ func TestStoragePathGeneration(t *testing.T) {
 
 // TestGraphMetadataIntegration tests the integration of graph metadata with attribute processing
 func TestGraphMetadataIntegration(t *testing.T) {
+	repos := testDatabase(t)
 	// Create an entity with mixed data types
 	entity, err := createEntityWithAttributes("engine-id-integration-test-entity-1", "integration-test-entity-1", map[string]string{
 		"tabular_data": `{
@@ -140,11 +142,11 @@ func TestGraphMetadataIntegration(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	processor := NewEntityAttributeProcessor()
+	processor := NewEntityAttributeProcessor(repos)
 	ctx := context.Background()
 
 	// save the parent entity in the database
-	err = saveEntityToDatabase(ctx, entity)
+	err = saveEntityToDatabase(ctx, repos, entity)
 	assert.NoError(t, err)
 
 	// Test create operation - this should create graph metadata
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+)
+
+// saveValue stores a value of a scalar, document or graph attribute in the metadata store,
+// a value with the same start time replaces the one stored before
+func (r *BaseAttributeResolver) saveValue(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	if value.GetValue() == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("value of attribute %s is nil", attrName),
+		}
+	}
+	repo := r.repos.Metadata
+	if repo == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to get metadata store: store is not configured"),
+		}
+	}
+	if err := repo.SaveAttributeValue(ctx, entityID, attrName, value); err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to save value of attribute %s: %v", attrName, err),
+		}
+	}
+	return &Result{
+		Data:    nil,
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// readValues returns the values of a scalar, document or graph attribute ordered by start time
+func (r *BaseAttributeResolver) readValues(ctx context.Context, entityID, attrName string) *Result {
+	repo := r.repos.Metadata
+	if repo == nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to get metadata store: store is not configured"),
+		}
+	}
+	values, err := repo.GetAttributeValues(ctx, entityID, attrName)
+	if err != nil {
+		return &Result{
+			Data:    nil,
+			Success: false,
+			Error:   fmt.Errorf("failed to read values of attribute %s: %w", attrName, err),
+		}
+	}
+	return &Result{
+		Data:    &pb.TimeBasedValueList{Values: values},
+		Success: true,
+		Error:   nil,
+	}
+}
+
+// ScalarAttributeResolver handles single values such as a number, a string or a boolean.
+// Typed scalar values are stored as {"value": scalar}, like the scalars whose storage type is inferred,
+// and are kept in the metadata store with their start and end times.
+type ScalarAttributeResolver struct {
+	BaseAttributeResolver
+}
+
+func (r *ScalarAttributeResolver) CreateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Creating scalar attribute", "entity_id", entityID, "attribute", attrName)
+	return r.saveValue(ctx, entityID, attrName, value)
+}
+
+// ReadResolve returns every value of the attribute ordered by start time
+func (r *ScalarAttributeResolver) ReadResolve(ctx context.Context, entityID, attrName string, filters map[string]interface{}, fields ...string) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Reading scalar attribute", "entity_id", entityID, "attribute", attrName)
+	return r.readValues(ctx, entityID, attrName)
+}
+
+// UpdateResolve stores a new value, the value with the same start time is replaced
+func (r *ScalarAttributeResolver) UpdateResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	return r.CreateResolve(ctx, entityID, attrName, value)
+}
+
+// DeleteResolve keeps the values, they are removed with the metadata of the entity
+func (r *ScalarAttributeResolver) DeleteResolve(ctx context.Context, entityID, attrName string, value *pb.TimeBasedValue) *Result {
+	logging.FromContext(ctx).DebugContext(ctx, "Deleting scalar attribute", "entity_id", entityID, "attribute", attrName)
+	return &Result{
+		Data:    nil,
+		Success: true,
+		Error:   nil,
+	}
+}
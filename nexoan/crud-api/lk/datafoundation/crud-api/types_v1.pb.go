@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,6 +23,106 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Cardinality of a relationship type, expressed as source to target
+type Cardinality int32
+
+const (
+	Cardinality_MANY_TO_MANY Cardinality = 0
+	Cardinality_ONE_TO_ONE   Cardinality = 1
+	Cardinality_ONE_TO_MANY  Cardinality = 2 // A target has at most one active source
+	Cardinality_MANY_TO_ONE  Cardinality = 3 // A source has at most one active target
+)
+
+// Enum value maps for Cardinality.
+var (
+	Cardinality_name = map[int32]string{
+		0: "MANY_TO_MANY",
+		1: "ONE_TO_ONE",
+		2: "ONE_TO_MANY",
+		3: "MANY_TO_ONE",
+	}
+	Cardinality_value = map[string]int32{
+		"MANY_TO_MANY": 0,
+		"ONE_TO_ONE":   1,
+		"ONE_TO_MANY":  2,
+		"MANY_TO_ONE":  3,
+	}
+)
+
+func (x Cardinality) Enum() *Cardinality {
+	p := new(Cardinality)
+	*p = x
+	return p
+}
+
+func (x Cardinality) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Cardinality) Descriptor() protoreflect.EnumDescriptor {
+	return file_types_v1_proto_enumTypes[0].Descriptor()
+}
+
+func (Cardinality) Type() protoreflect.EnumType {
+	return &file_types_v1_proto_enumTypes[0]
+}
+
+func (x Cardinality) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Cardinality.Descriptor instead.
+func (Cardinality) EnumDescriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{0}
+}
+
+// Direction of a relationship type
+type RelationshipDirection int32
+
+const (
+	RelationshipDirection_DIRECTED   RelationshipDirection = 0
+	RelationshipDirection_UNDIRECTED RelationshipDirection = 1 // Source and target kinds may be used in either order
+)
+
+// Enum value maps for RelationshipDirection.
+var (
+	RelationshipDirection_name = map[int32]string{
+		0: "DIRECTED",
+		1: "UNDIRECTED",
+	}
+	RelationshipDirection_value = map[string]int32{
+		"DIRECTED":   0,
+		"UNDIRECTED": 1,
+	}
+)
+
+func (x RelationshipDirection) Enum() *RelationshipDirection {
+	p := new(RelationshipDirection)
+	*p = x
+	return p
+}
+
+func (x RelationshipDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RelationshipDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_types_v1_proto_enumTypes[1].Descriptor()
+}
+
+func (RelationshipDirection) Type() protoreflect.EnumType {
+	return &file_types_v1_proto_enumTypes[1]
+}
+
+func (x RelationshipDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RelationshipDirection.Descriptor instead.
+func (RelationshipDirection) EnumDescriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{1}
+}
+
 type Kind struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Major         string                 `protobuf:"bytes,1,opt,name=major,proto3" json:"major,omitempty"`
@@ -142,6 +243,7 @@ type Relationship struct {
 	StartTime       string                 `protobuf:"bytes,4,opt,name=startTime,proto3" json:"startTime,omitempty"`
 	EndTime         string                 `protobuf:"bytes,5,opt,name=endTime,proto3" json:"endTime,omitempty"`
 	Direction       string                 `protobuf:"bytes,6,opt,name=direction,proto3" json:"direction,omitempty"`
+	Properties      map[string]*anypb.Any  `protobuf:"bytes,7,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Properties stored on the relationship edge
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -218,6 +320,13 @@ func (x *Relationship) GetDirection() string {
 	return ""
 }
 
+func (x *Relationship) GetProperties() map[string]*anypb.Any {
+	if x != nil {
+		return x.Properties
+	}
+	return nil
+}
+
 type Entity struct {
 	state         protoimpl.MessageState         `protogen:"open.v1"`
 	Id            string                         `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                 // Read-only unique identifier
@@ -322,6 +431,7 @@ func (x *Entity) GetRelationships() map[string]*Relationship {
 type TimeBasedValueList struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Values        []*TimeBasedValue      `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	JsonSchema    string                 `protobuf:"bytes,2,opt,name=jsonSchema,proto3" json:"jsonSchema,omitempty"` // Declared JSON Schema (draft 2020-12) of the attribute, overrides the inferred schema
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -363,30 +473,36 @@ func (x *TimeBasedValueList) GetValues() []*TimeBasedValue {
 	return nil
 }
 
-// Request message for reading an entity
-type ReadEntityRequest struct {
+func (x *TimeBasedValueList) GetJsonSchema() string {
+	if x != nil {
+		return x.JsonSchema
+	}
+	return ""
+}
+
+// TabularValue is a table stored as rows of one value per column
+type TabularValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entity        *Entity                `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
-	Output        []string               `protobuf:"bytes,2,rep,name=output,proto3" json:"output,omitempty"`
-	ActiveAt      string                 `protobuf:"bytes,3,opt,name=activeAt,proto3" json:"activeAt,omitempty"`
+	Columns       []string               `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	Rows          []*structpb.ListValue  `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ReadEntityRequest) Reset() {
-	*x = ReadEntityRequest{}
+func (x *TabularValue) Reset() {
+	*x = TabularValue{}
 	mi := &file_types_v1_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ReadEntityRequest) String() string {
+func (x *TabularValue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ReadEntityRequest) ProtoMessage() {}
+func (*TabularValue) ProtoMessage() {}
 
-func (x *ReadEntityRequest) ProtoReflect() protoreflect.Message {
+func (x *TabularValue) ProtoReflect() protoreflect.Message {
 	mi := &file_types_v1_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -398,54 +514,48 @@ func (x *ReadEntityRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ReadEntityRequest.ProtoReflect.Descriptor instead.
-func (*ReadEntityRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TabularValue.ProtoReflect.Descriptor instead.
+func (*TabularValue) Descriptor() ([]byte, []int) {
 	return file_types_v1_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ReadEntityRequest) GetEntity() *Entity {
+func (x *TabularValue) GetColumns() []string {
 	if x != nil {
-		return x.Entity
+		return x.Columns
 	}
 	return nil
 }
 
-func (x *ReadEntityRequest) GetOutput() []string {
+func (x *TabularValue) GetRows() []*structpb.ListValue {
 	if x != nil {
-		return x.Output
+		return x.Rows
 	}
 	return nil
 }
 
-func (x *ReadEntityRequest) GetActiveAt() string {
-	if x != nil {
-		return x.ActiveAt
-	}
-	return ""
-}
-
-// Request message for deleting an entity by ID
-type EntityId struct {
+// GraphValue is a graph of nodes and the edges between them
+type GraphValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Nodes         []*structpb.Struct     `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Edges         []*structpb.Struct     `protobuf:"bytes,2,rep,name=edges,proto3" json:"edges,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EntityId) Reset() {
-	*x = EntityId{}
+func (x *GraphValue) Reset() {
+	*x = GraphValue{}
 	mi := &file_types_v1_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EntityId) String() string {
+func (x *GraphValue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EntityId) ProtoMessage() {}
+func (*GraphValue) ProtoMessage() {}
 
-func (x *EntityId) ProtoReflect() protoreflect.Message {
+func (x *GraphValue) ProtoReflect() protoreflect.Message {
 	mi := &file_types_v1_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -457,41 +567,47 @@ func (x *EntityId) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EntityId.ProtoReflect.Descriptor instead.
-func (*EntityId) Descriptor() ([]byte, []int) {
+// Deprecated: Use GraphValue.ProtoReflect.Descriptor instead.
+func (*GraphValue) Descriptor() ([]byte, []int) {
 	return file_types_v1_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *EntityId) GetId() string {
+func (x *GraphValue) GetNodes() []*structpb.Struct {
 	if x != nil {
-		return x.Id
+		return x.Nodes
 	}
-	return ""
+	return nil
 }
 
-// Request message for updating an entity
-type UpdateEntityRequest struct {
+func (x *GraphValue) GetEdges() []*structpb.Struct {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+// DocumentValue is a document stored as a whole, whatever its keys
+type DocumentValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Entity        *Entity                `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
+	Document      *structpb.Struct       `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEntityRequest) Reset() {
-	*x = UpdateEntityRequest{}
+func (x *DocumentValue) Reset() {
+	*x = DocumentValue{}
 	mi := &file_types_v1_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEntityRequest) String() string {
+func (x *DocumentValue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEntityRequest) ProtoMessage() {}
+func (*DocumentValue) ProtoMessage() {}
 
-func (x *UpdateEntityRequest) ProtoReflect() protoreflect.Message {
+func (x *DocumentValue) ProtoReflect() protoreflect.Message {
 	mi := &file_types_v1_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -503,46 +619,40 @@ func (x *UpdateEntityRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEntityRequest.ProtoReflect.Descriptor instead.
-func (*UpdateEntityRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DocumentValue.ProtoReflect.Descriptor instead.
+func (*DocumentValue) Descriptor() ([]byte, []int) {
 	return file_types_v1_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *UpdateEntityRequest) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *UpdateEntityRequest) GetEntity() *Entity {
+func (x *DocumentValue) GetDocument() *structpb.Struct {
 	if x != nil {
-		return x.Entity
+		return x.Document
 	}
 	return nil
 }
 
-// Empty message response
-type Empty struct {
+// ScalarValue is a single number, string, boolean or null
+type ScalarValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         *structpb.Value        `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
+func (x *ScalarValue) Reset() {
+	*x = ScalarValue{}
 	mi := &file_types_v1_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Empty) String() string {
+func (x *ScalarValue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*ScalarValue) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
+func (x *ScalarValue) ProtoReflect() protoreflect.Message {
 	mi := &file_types_v1_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -554,33 +664,41 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
+// Deprecated: Use ScalarValue.ProtoReflect.Descriptor instead.
+func (*ScalarValue) Descriptor() ([]byte, []int) {
 	return file_types_v1_proto_rawDescGZIP(), []int{8}
 }
 
-// EntityList represents a list of entities
-type EntityList struct {
+func (x *ScalarValue) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// BlobValue is binary content
+type BlobValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Entities      []*Entity              `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=contentType,proto3" json:"contentType,omitempty"` // MIME type of the content
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *EntityList) Reset() {
-	*x = EntityList{}
+func (x *BlobValue) Reset() {
+	*x = BlobValue{}
 	mi := &file_types_v1_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *EntityList) String() string {
+func (x *BlobValue) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EntityList) ProtoMessage() {}
+func (*BlobValue) ProtoMessage() {}
 
-func (x *EntityList) ProtoReflect() protoreflect.Message {
+func (x *BlobValue) ProtoReflect() protoreflect.Message {
 	mi := &file_types_v1_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -592,82 +710,2181 @@ func (x *EntityList) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EntityList.ProtoReflect.Descriptor instead.
-func (*EntityList) Descriptor() ([]byte, []int) {
+// Deprecated: Use BlobValue.ProtoReflect.Descriptor instead.
+func (*BlobValue) Descriptor() ([]byte, []int) {
 	return file_types_v1_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *EntityList) GetEntities() []*Entity {
+func (x *BlobValue) GetData() []byte {
 	if x != nil {
-		return x.Entities
+		return x.Data
 	}
 	return nil
 }
 
-var File_types_v1_proto protoreflect.FileDescriptor
+func (x *BlobValue) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
 
-const file_types_v1_proto_rawDesc = "" +
-	"\n" +
-	"\x0etypes_v1.proto\x12\x04crud\x1a\x19google/protobuf/any.proto\"2\n" +
-	"\x04Kind\x12\x14\n" +
-	"\x05major\x18\x01 \x01(\tR\x05major\x12\x14\n" +
-	"\x05minor\x18\x02 \x01(\tR\x05minor\"t\n" +
-	"\x0eTimeBasedValue\x12\x1c\n" +
-	"\tstartTime\x18\x01 \x01(\tR\tstartTime\x12\x18\n" +
-	"\aendTime\x18\x02 \x01(\tR\aendTime\x12*\n" +
-	"\x05value\x18\x03 \x01(\v2\x14.google.protobuf.AnyR\x05value\"\xb2\x01\n" +
-	"\fRelationship\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
-	"\x0frelatedEntityId\x18\x02 \x01(\tR\x0frelatedEntityId\x12\x12\n" +
-	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
-	"\tstartTime\x18\x04 \x01(\tR\tstartTime\x12\x18\n" +
-	"\aendTime\x18\x05 \x01(\tR\aendTime\x12\x1c\n" +
-	"\tdirection\x18\x06 \x01(\tR\tdirection\"\xdb\x04\n" +
-	"\x06Entity\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
-	"\x04kind\x18\x02 \x01(\v2\n" +
-	".crud.KindR\x04kind\x12\x18\n" +
-	"\acreated\x18\x03 \x01(\tR\acreated\x12\x1e\n" +
-	"\n" +
-	"terminated\x18\x04 \x01(\tR\n" +
-	"terminated\x12(\n" +
-	"\x04name\x18\x05 \x01(\v2\x14.crud.TimeBasedValueR\x04name\x126\n" +
-	"\bmetadata\x18\x06 \x03(\v2\x1a.crud.Entity.MetadataEntryR\bmetadata\x12<\n" +
-	"\n" +
-	"attributes\x18\a \x03(\v2\x1c.crud.Entity.AttributesEntryR\n" +
-	"attributes\x12E\n" +
-	"\rrelationships\x18\b \x03(\v2\x1f.crud.Entity.RelationshipsEntryR\rrelationships\x1aQ\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
-	"\x05value\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05value:\x028\x01\x1aW\n" +
-	"\x0fAttributesEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
-	"\x05value\x18\x02 \x01(\v2\x18.crud.TimeBasedValueListR\x05value:\x028\x01\x1aT\n" +
-	"\x12RelationshipsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
-	"\x05value\x18\x02 \x01(\v2\x12.crud.RelationshipR\x05value:\x028\x01\"B\n" +
-	"\x12TimeBasedValueList\x12,\n" +
-	"\x06values\x18\x01 \x03(\v2\x14.crud.TimeBasedValueR\x06values\"m\n" +
-	"\x11ReadEntityRequest\x12$\n" +
-	"\x06entity\x18\x01 \x01(\v2\f.crud.EntityR\x06entity\x12\x16\n" +
-	"\x06output\x18\x02 \x03(\tR\x06output\x12\x1a\n" +
-	"\bactiveAt\x18\x03 \x01(\tR\bactiveAt\"\x1a\n" +
-	"\bEntityId\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"K\n" +
-	"\x13UpdateEntityRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
-	"\x06entity\x18\x02 \x01(\v2\f.crud.EntityR\x06entity\"\a\n" +
-	"\x05Empty\"6\n" +
-	"\n" +
-	"EntityList\x12(\n" +
-	"\bentities\x18\x01 \x03(\v2\f.crud.EntityR\bentities2\x8f\x02\n" +
-	"\vCrudService\x12*\n" +
-	"\fCreateEntity\x12\f.crud.Entity\x1a\f.crud.Entity\x123\n" +
-	"\n" +
-	"ReadEntity\x12\x17.crud.ReadEntityRequest\x1a\f.crud.Entity\x129\n" +
-	"\fReadEntities\x12\x17.crud.ReadEntityRequest\x1a\x10.crud.EntityList\x127\n" +
-	"\fUpdateEntity\x12\x19.crud.UpdateEntityRequest\x1a\f.crud.Entity\x12+\n" +
-	"\fDeleteEntity\x12\x0e.crud.EntityId\x1a\v.crud.EmptyB\x1cZ\x1alk/datafoundation/crud-apib\x06proto3"
+// Request message for reading an entity
+type ReadEntityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        *Entity                `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"`
+	Output        []string               `protobuf:"bytes,2,rep,name=output,proto3" json:"output,omitempty"`
+	ActiveAt      string                 `protobuf:"bytes,3,opt,name=activeAt,proto3" json:"activeAt,omitempty"`
+	Spatial       *SpatialFilter         `protobuf:"bytes,4,opt,name=spatial,proto3" json:"spatial,omitempty"` // Keeps the entities whose geospatial attribute matches, used by ReadEntities
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadEntityRequest) Reset() {
+	*x = ReadEntityRequest{}
+	mi := &file_types_v1_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadEntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadEntityRequest) ProtoMessage() {}
+
+func (x *ReadEntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadEntityRequest.ProtoReflect.Descriptor instead.
+func (*ReadEntityRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ReadEntityRequest) GetEntity() *Entity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+func (x *ReadEntityRequest) GetOutput() []string {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *ReadEntityRequest) GetActiveAt() string {
+	if x != nil {
+		return x.ActiveAt
+	}
+	return ""
+}
+
+func (x *ReadEntityRequest) GetSpatial() *SpatialFilter {
+	if x != nil {
+		return x.Spatial
+	}
+	return nil
+}
+
+// Request message for deleting an entity by ID
+type EntityId struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityId) Reset() {
+	*x = EntityId{}
+	mi := &file_types_v1_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityId) ProtoMessage() {}
+
+func (x *EntityId) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityId.ProtoReflect.Descriptor instead.
+func (*EntityId) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *EntityId) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Request message for updating an entity. Only the metadata keys given in the entity are changed.
+type UpdateEntityRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Entity         *Entity                `protobuf:"bytes,2,opt,name=entity,proto3" json:"entity,omitempty"`
+	RemoveMetadata []string               `protobuf:"bytes,3,rep,name=removeMetadata,proto3" json:"removeMetadata,omitempty"` // Metadata keys to remove, their values stay in the metadata history
+	ValidFrom      string                 `protobuf:"bytes,4,opt,name=validFrom,proto3" json:"validFrom,omitempty"`           // Optional time the metadata changes take effect, the time of the update when empty
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateEntityRequest) Reset() {
+	*x = UpdateEntityRequest{}
+	mi := &file_types_v1_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEntityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEntityRequest) ProtoMessage() {}
+
+func (x *UpdateEntityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEntityRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEntityRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateEntityRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateEntityRequest) GetEntity() *Entity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+func (x *UpdateEntityRequest) GetRemoveMetadata() []string {
+	if x != nil {
+		return x.RemoveMetadata
+	}
+	return nil
+}
+
+func (x *UpdateEntityRequest) GetValidFrom() string {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return ""
+}
+
+// Empty message response
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_types_v1_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{13}
+}
+
+// EntityList represents a list of entities
+type EntityList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entities      []*Entity              `protobuf:"bytes,1,rep,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityList) Reset() {
+	*x = EntityList{}
+	mi := &file_types_v1_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityList) ProtoMessage() {}
+
+func (x *EntityList) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityList.ProtoReflect.Descriptor instead.
+func (*EntityList) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *EntityList) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+// RelationshipType declares a legal relationship name and the kinds it can connect
+type RelationshipType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	SourceKinds   []*Kind                `protobuf:"bytes,2,rep,name=sourceKinds,proto3" json:"sourceKinds,omitempty"` // Allowed source kinds, an empty minor matches any minor kind
+	TargetKinds   []*Kind                `protobuf:"bytes,3,rep,name=targetKinds,proto3" json:"targetKinds,omitempty"` // Allowed target kinds, an empty minor matches any minor kind
+	Cardinality   Cardinality            `protobuf:"varint,4,opt,name=cardinality,proto3,enum=crud.Cardinality" json:"cardinality,omitempty"`
+	Direction     RelationshipDirection  `protobuf:"varint,5,opt,name=direction,proto3,enum=crud.RelationshipDirection" json:"direction,omitempty"`
+	Description   string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelationshipType) Reset() {
+	*x = RelationshipType{}
+	mi := &file_types_v1_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationshipType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationshipType) ProtoMessage() {}
+
+func (x *RelationshipType) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationshipType.ProtoReflect.Descriptor instead.
+func (*RelationshipType) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RelationshipType) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RelationshipType) GetSourceKinds() []*Kind {
+	if x != nil {
+		return x.SourceKinds
+	}
+	return nil
+}
+
+func (x *RelationshipType) GetTargetKinds() []*Kind {
+	if x != nil {
+		return x.TargetKinds
+	}
+	return nil
+}
+
+func (x *RelationshipType) GetCardinality() Cardinality {
+	if x != nil {
+		return x.Cardinality
+	}
+	return Cardinality_MANY_TO_MANY
+}
+
+func (x *RelationshipType) GetDirection() RelationshipDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return RelationshipDirection_DIRECTED
+}
+
+func (x *RelationshipType) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// RelationshipTypeList represents a list of relationship types
+type RelationshipTypeList struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	RelationshipTypes []*RelationshipType    `protobuf:"bytes,1,rep,name=relationshipTypes,proto3" json:"relationshipTypes,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RelationshipTypeList) Reset() {
+	*x = RelationshipTypeList{}
+	mi := &file_types_v1_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationshipTypeList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationshipTypeList) ProtoMessage() {}
+
+func (x *RelationshipTypeList) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationshipTypeList.ProtoReflect.Descriptor instead.
+func (*RelationshipTypeList) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RelationshipTypeList) GetRelationshipTypes() []*RelationshipType {
+	if x != nil {
+		return x.RelationshipTypes
+	}
+	return nil
+}
+
+// MetadataFieldSchema declares a metadata key of a kind
+type MetadataFieldSchema struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"` // int, float, string, bool, date, time or datetime. Empty allows any type
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataFieldSchema) Reset() {
+	*x = MetadataFieldSchema{}
+	mi := &file_types_v1_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataFieldSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataFieldSchema) ProtoMessage() {}
+
+func (x *MetadataFieldSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataFieldSchema.ProtoReflect.Descriptor instead.
+func (*MetadataFieldSchema) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *MetadataFieldSchema) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MetadataFieldSchema) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *MetadataFieldSchema) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+// AttributeFieldSchema declares an attribute of a kind
+type AttributeFieldSchema struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	StorageType   string                 `protobuf:"bytes,2,opt,name=storageType,proto3" json:"storageType,omitempty"` // tabular, scalar, list, map, graph or blob. Empty allows any storage type
+	Required      bool                   `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	JsonSchema    string                 `protobuf:"bytes,4,opt,name=jsonSchema,proto3" json:"jsonSchema,omitempty"` // Declared JSON Schema (draft 2020-12) of the attribute, overrides the inferred schema
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeFieldSchema) Reset() {
+	*x = AttributeFieldSchema{}
+	mi := &file_types_v1_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeFieldSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeFieldSchema) ProtoMessage() {}
+
+func (x *AttributeFieldSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeFieldSchema.ProtoReflect.Descriptor instead.
+func (*AttributeFieldSchema) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AttributeFieldSchema) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeFieldSchema) GetStorageType() string {
+	if x != nil {
+		return x.StorageType
+	}
+	return ""
+}
+
+func (x *AttributeFieldSchema) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+func (x *AttributeFieldSchema) GetJsonSchema() string {
+	if x != nil {
+		return x.JsonSchema
+	}
+	return ""
+}
+
+// KindSchema declares a major kind, its allowed minor kinds and the metadata and attributes expected on its entities
+type KindSchema struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Major         string                  `protobuf:"bytes,1,opt,name=major,proto3" json:"major,omitempty"`
+	Minors        []string                `protobuf:"bytes,2,rep,name=minors,proto3" json:"minors,omitempty"` // Allowed minor kinds, empty allows any minor kind
+	Metadata      []*MetadataFieldSchema  `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty"`
+	Attributes    []*AttributeFieldSchema `protobuf:"bytes,4,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Closed        bool                    `protobuf:"varint,5,opt,name=closed,proto3" json:"closed,omitempty"` // Reject metadata keys and attributes that are not declared
+	Description   string                  `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KindSchema) Reset() {
+	*x = KindSchema{}
+	mi := &file_types_v1_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KindSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KindSchema) ProtoMessage() {}
+
+func (x *KindSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KindSchema.ProtoReflect.Descriptor instead.
+func (*KindSchema) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *KindSchema) GetMajor() string {
+	if x != nil {
+		return x.Major
+	}
+	return ""
+}
+
+func (x *KindSchema) GetMinors() []string {
+	if x != nil {
+		return x.Minors
+	}
+	return nil
+}
+
+func (x *KindSchema) GetMetadata() []*MetadataFieldSchema {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *KindSchema) GetAttributes() []*AttributeFieldSchema {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *KindSchema) GetClosed() bool {
+	if x != nil {
+		return x.Closed
+	}
+	return false
+}
+
+func (x *KindSchema) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// KindSchemaList represents a list of kind schemas
+type KindSchemaList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kinds         []*KindSchema          `protobuf:"bytes,1,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KindSchemaList) Reset() {
+	*x = KindSchemaList{}
+	mi := &file_types_v1_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KindSchemaList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KindSchemaList) ProtoMessage() {}
+
+func (x *KindSchemaList) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KindSchemaList.ProtoReflect.Descriptor instead.
+func (*KindSchemaList) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *KindSchemaList) GetKinds() []*KindSchema {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+// AttributeId identifies an attribute of an entity
+type AttributeId struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeId) Reset() {
+	*x = AttributeId{}
+	mi := &file_types_v1_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeId) ProtoMessage() {}
+
+func (x *AttributeId) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeId.ProtoReflect.Descriptor instead.
+func (*AttributeId) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *AttributeId) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *AttributeId) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// AttributeSchema is the stored schema of an attribute
+type AttributeSchema struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	StorageType   string                 `protobuf:"bytes,3,opt,name=storageType,proto3" json:"storageType,omitempty"`
+	JsonSchema    string                 `protobuf:"bytes,4,opt,name=jsonSchema,proto3" json:"jsonSchema,omitempty"` // JSON Schema (draft 2020-12) document describing the attribute values
+	Declared      bool                   `protobuf:"varint,5,opt,name=declared,proto3" json:"declared,omitempty"`    // The schema was declared by a client instead of inferred from the first write
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeSchema) Reset() {
+	*x = AttributeSchema{}
+	mi := &file_types_v1_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeSchema) ProtoMessage() {}
+
+func (x *AttributeSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeSchema.ProtoReflect.Descriptor instead.
+func (*AttributeSchema) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *AttributeSchema) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *AttributeSchema) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeSchema) GetStorageType() string {
+	if x != nil {
+		return x.StorageType
+	}
+	return ""
+}
+
+func (x *AttributeSchema) GetJsonSchema() string {
+	if x != nil {
+		return x.JsonSchema
+	}
+	return ""
+}
+
+func (x *AttributeSchema) GetDeclared() bool {
+	if x != nil {
+		return x.Declared
+	}
+	return false
+}
+
+// AttributeIndexRequest asks for a secondary index on columns of a tabular attribute
+type AttributeIndexRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Columns       []string               `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"` // Indexed together in this order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeIndexRequest) Reset() {
+	*x = AttributeIndexRequest{}
+	mi := &file_types_v1_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeIndexRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeIndexRequest) ProtoMessage() {}
+
+func (x *AttributeIndexRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeIndexRequest.ProtoReflect.Descriptor instead.
+func (*AttributeIndexRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AttributeIndexRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *AttributeIndexRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeIndexRequest) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+// AttributeIndex is a secondary index on columns of a tabular attribute
+type AttributeIndex struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Columns       []string               `protobuf:"bytes,3,rep,name=columns,proto3" json:"columns,omitempty"`
+	IndexName     string                 `protobuf:"bytes,4,opt,name=indexName,proto3" json:"indexName,omitempty"` // The name of the index in the tabular store
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttributeIndex) Reset() {
+	*x = AttributeIndex{}
+	mi := &file_types_v1_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttributeIndex) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttributeIndex) ProtoMessage() {}
+
+func (x *AttributeIndex) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttributeIndex.ProtoReflect.Descriptor instead.
+func (*AttributeIndex) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AttributeIndex) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *AttributeIndex) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttributeIndex) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *AttributeIndex) GetIndexName() string {
+	if x != nil {
+		return x.IndexName
+	}
+	return ""
+}
+
+// EntityRelationship is a relationship together with the entity it starts from
+type EntityRelationship struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`         // Source entity of the relationship
+	Relationship  *Relationship          `protobuf:"bytes,2,opt,name=relationship,proto3" json:"relationship,omitempty"` // relatedEntityId is the target entity
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntityRelationship) Reset() {
+	*x = EntityRelationship{}
+	mi := &file_types_v1_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntityRelationship) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntityRelationship) ProtoMessage() {}
+
+func (x *EntityRelationship) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntityRelationship.ProtoReflect.Descriptor instead.
+func (*EntityRelationship) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *EntityRelationship) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *EntityRelationship) GetRelationship() *Relationship {
+	if x != nil {
+		return x.Relationship
+	}
+	return nil
+}
+
+// Request message for reading or deleting a relationship by ID
+type RelationshipId struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelationshipId) Reset() {
+	*x = RelationshipId{}
+	mi := &file_types_v1_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationshipId) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationshipId) ProtoMessage() {}
+
+func (x *RelationshipId) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationshipId.ProtoReflect.Descriptor instead.
+func (*RelationshipId) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RelationshipId) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Request message for reading relationships by either endpoint
+type ReadRelationshipsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SourceEntityId string                 `protobuf:"bytes,1,opt,name=sourceEntityId,proto3" json:"sourceEntityId,omitempty"`
+	TargetEntityId string                 `protobuf:"bytes,2,opt,name=targetEntityId,proto3" json:"targetEntityId,omitempty"`
+	Name           string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`         // Optional relationship name filter
+	ActiveAt       string                 `protobuf:"bytes,4,opt,name=activeAt,proto3" json:"activeAt,omitempty"` // Optional timestamp, only relationships active at this time are returned
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReadRelationshipsRequest) Reset() {
+	*x = ReadRelationshipsRequest{}
+	mi := &file_types_v1_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadRelationshipsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadRelationshipsRequest) ProtoMessage() {}
+
+func (x *ReadRelationshipsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadRelationshipsRequest.ProtoReflect.Descriptor instead.
+func (*ReadRelationshipsRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ReadRelationshipsRequest) GetSourceEntityId() string {
+	if x != nil {
+		return x.SourceEntityId
+	}
+	return ""
+}
+
+func (x *ReadRelationshipsRequest) GetTargetEntityId() string {
+	if x != nil {
+		return x.TargetEntityId
+	}
+	return ""
+}
+
+func (x *ReadRelationshipsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReadRelationshipsRequest) GetActiveAt() string {
+	if x != nil {
+		return x.ActiveAt
+	}
+	return ""
+}
+
+// RelationshipList represents a list of relationships
+type RelationshipList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Relationships []*EntityRelationship  `protobuf:"bytes,1,rep,name=relationships,proto3" json:"relationships,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelationshipList) Reset() {
+	*x = RelationshipList{}
+	mi := &file_types_v1_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelationshipList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelationshipList) ProtoMessage() {}
+
+func (x *RelationshipList) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelationshipList.ProtoReflect.Descriptor instead.
+func (*RelationshipList) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RelationshipList) GetRelationships() []*EntityRelationship {
+	if x != nil {
+		return x.Relationships
+	}
+	return nil
+}
+
+// Request message for terminating a relationship
+type TerminateRelationshipRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	EndTime       string                 `protobuf:"bytes,2,opt,name=endTime,proto3" json:"endTime,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TerminateRelationshipRequest) Reset() {
+	*x = TerminateRelationshipRequest{}
+	mi := &file_types_v1_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TerminateRelationshipRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TerminateRelationshipRequest) ProtoMessage() {}
+
+func (x *TerminateRelationshipRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TerminateRelationshipRequest.ProtoReflect.Descriptor instead.
+func (*TerminateRelationshipRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *TerminateRelationshipRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TerminateRelationshipRequest) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+// BlobInfo describes the content of a blob attribute
+type BlobInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`               // Name of the attribute
+	ContentType   string                 `protobuf:"bytes,3,opt,name=contentType,proto3" json:"contentType,omitempty"` // MIME type of the content, detected from the content when empty on upload
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`              // Size of the content in bytes, set by the server
+	Digest        string                 `protobuf:"bytes,5,opt,name=digest,proto3" json:"digest,omitempty"`           // sha256:<hex> digest of the content, set by the server
+	StartTime     string                 `protobuf:"bytes,6,opt,name=startTime,proto3" json:"startTime,omitempty"`     // Start time of the attribute value on upload
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobInfo) Reset() {
+	*x = BlobInfo{}
+	mi := &file_types_v1_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobInfo) ProtoMessage() {}
+
+func (x *BlobInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobInfo.ProtoReflect.Descriptor instead.
+func (*BlobInfo) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *BlobInfo) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BlobInfo) GetDigest() string {
+	if x != nil {
+		return x.Digest
+	}
+	return ""
+}
+
+func (x *BlobInfo) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+// BlobChunk is a part of the content of a blob attribute streamed by UploadBlob and DownloadBlob.
+// The info is carried by the first chunk only.
+type BlobChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *BlobInfo              `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BlobChunk) Reset() {
+	*x = BlobChunk{}
+	mi := &file_types_v1_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BlobChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlobChunk) ProtoMessage() {}
+
+func (x *BlobChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlobChunk.ProtoReflect.Descriptor instead.
+func (*BlobChunk) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *BlobChunk) GetInfo() *BlobInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *BlobChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// SpatialFilter selects entities by the geometry of a geospatial attribute.
+// When both a point and a bounding box are given the geometry must match both.
+type SpatialFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attribute     string                 `protobuf:"bytes,1,opt,name=attribute,proto3" json:"attribute,omitempty"`         // Name of the geospatial attribute, for example boundary
+	ContainsPoint *Position              `protobuf:"bytes,2,opt,name=containsPoint,proto3" json:"containsPoint,omitempty"` // The geometry contains the point, its boundary included
+	Intersects    *BoundingBox           `protobuf:"bytes,3,opt,name=intersects,proto3" json:"intersects,omitempty"`       // The geometry shares at least one position with the box
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpatialFilter) Reset() {
+	*x = SpatialFilter{}
+	mi := &file_types_v1_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpatialFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpatialFilter) ProtoMessage() {}
+
+func (x *SpatialFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpatialFilter.ProtoReflect.Descriptor instead.
+func (*SpatialFilter) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SpatialFilter) GetAttribute() string {
+	if x != nil {
+		return x.Attribute
+	}
+	return ""
+}
+
+func (x *SpatialFilter) GetContainsPoint() *Position {
+	if x != nil {
+		return x.ContainsPoint
+	}
+	return nil
+}
+
+func (x *SpatialFilter) GetIntersects() *BoundingBox {
+	if x != nil {
+		return x.Intersects
+	}
+	return nil
+}
+
+// Position is a WGS 84 longitude and latitude in degrees
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Longitude     float64                `protobuf:"fixed64,1,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_types_v1_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *Position) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Position) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+// BoundingBox is a box in WGS 84 degrees, its edges belong to it
+type BoundingBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinLongitude  float64                `protobuf:"fixed64,1,opt,name=minLongitude,proto3" json:"minLongitude,omitempty"`
+	MinLatitude   float64                `protobuf:"fixed64,2,opt,name=minLatitude,proto3" json:"minLatitude,omitempty"`
+	MaxLongitude  float64                `protobuf:"fixed64,3,opt,name=maxLongitude,proto3" json:"maxLongitude,omitempty"`
+	MaxLatitude   float64                `protobuf:"fixed64,4,opt,name=maxLatitude,proto3" json:"maxLatitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BoundingBox) Reset() {
+	*x = BoundingBox{}
+	mi := &file_types_v1_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BoundingBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BoundingBox) ProtoMessage() {}
+
+func (x *BoundingBox) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BoundingBox.ProtoReflect.Descriptor instead.
+func (*BoundingBox) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *BoundingBox) GetMinLongitude() float64 {
+	if x != nil {
+		return x.MinLongitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMinLatitude() float64 {
+	if x != nil {
+		return x.MinLatitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLongitude() float64 {
+	if x != nil {
+		return x.MaxLongitude
+	}
+	return 0
+}
+
+func (x *BoundingBox) GetMaxLatitude() float64 {
+	if x != nil {
+		return x.MaxLatitude
+	}
+	return 0
+}
+
+// SearchRequest finds the entities whose name, metadata values or tabular string cells contain every word of the query.
+// Words are compared without case and accents.
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Kinds         []*Kind                `protobuf:"bytes,2,rep,name=kinds,proto3" json:"kinds,omitempty"`  // Keeps the entities of these kinds, a kind without a minor matches any minor
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"` // Maximum number of hits, 20 when unset and at most 100
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_types_v1_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetKinds() []*Kind {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// SearchResponse holds the entities found by a search, most relevant first
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_types_v1_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SearchResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+// SearchHit is an entity found by a search with the fields it was found in, best match first
+type SearchHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entity        *Entity                `protobuf:"bytes,1,opt,name=entity,proto3" json:"entity,omitempty"` // The id, kind, name, created and terminated values of the entity
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	Matches       []*SearchMatch         `protobuf:"bytes,3,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_types_v1_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SearchHit) GetEntity() *Entity {
+	if x != nil {
+		return x.Entity
+	}
+	return nil
+}
+
+func (x *SearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchHit) GetMatches() []*SearchMatch {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+// SearchMatch is a field containing every word of the query
+type SearchMatch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Field         string                 `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"` // name, metadata.<key> or attributes.<attribute>.<column>
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchMatch) Reset() {
+	*x = SearchMatch{}
+	mi := &file_types_v1_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMatch) ProtoMessage() {}
+
+func (x *SearchMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMatch.ProtoReflect.Descriptor instead.
+func (*SearchMatch) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SearchMatch) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *SearchMatch) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+// MetadataHistoryRequest reads the history of the metadata of an entity
+type MetadataHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"` // Optional metadata key, every key when empty
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataHistoryRequest) Reset() {
+	*x = MetadataHistoryRequest{}
+	mi := &file_types_v1_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataHistoryRequest) ProtoMessage() {}
+
+func (x *MetadataHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataHistoryRequest.ProtoReflect.Descriptor instead.
+func (*MetadataHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *MetadataHistoryRequest) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *MetadataHistoryRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// MetadataHistory holds the versions of the metadata of an entity ordered by key and then by time
+type MetadataHistory struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      string                 `protobuf:"bytes,1,opt,name=entityId,proto3" json:"entityId,omitempty"`
+	Versions      []*MetadataVersion     `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataHistory) Reset() {
+	*x = MetadataHistory{}
+	mi := &file_types_v1_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataHistory) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataHistory) ProtoMessage() {}
+
+func (x *MetadataHistory) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataHistory.ProtoReflect.Descriptor instead.
+func (*MetadataHistory) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *MetadataHistory) GetEntityId() string {
+	if x != nil {
+		return x.EntityId
+	}
+	return ""
+}
+
+func (x *MetadataHistory) GetVersions() []*MetadataVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// MetadataVersion is a value a metadata key held between two times
+type MetadataVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         *anypb.Any             `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	ValidFrom     string                 `protobuf:"bytes,3,opt,name=validFrom,proto3" json:"validFrom,omitempty"`   // Empty for values stored before the history was kept
+	ValidTo       string                 `protobuf:"bytes,4,opt,name=validTo,proto3" json:"validTo,omitempty"`       // Empty while the value is current
+	RecordedBy    string                 `protobuf:"bytes,5,opt,name=recordedBy,proto3" json:"recordedBy,omitempty"` // Subject of the caller that set the value, empty when authentication is disabled
+	RecordedAt    string                 `protobuf:"bytes,6,opt,name=recordedAt,proto3" json:"recordedAt,omitempty"` // Time the value was set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetadataVersion) Reset() {
+	*x = MetadataVersion{}
+	mi := &file_types_v1_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetadataVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetadataVersion) ProtoMessage() {}
+
+func (x *MetadataVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_types_v1_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetadataVersion.ProtoReflect.Descriptor instead.
+func (*MetadataVersion) Descriptor() ([]byte, []int) {
+	return file_types_v1_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *MetadataVersion) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MetadataVersion) GetValue() *anypb.Any {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *MetadataVersion) GetValidFrom() string {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return ""
+}
+
+func (x *MetadataVersion) GetValidTo() string {
+	if x != nil {
+		return x.ValidTo
+	}
+	return ""
+}
+
+func (x *MetadataVersion) GetRecordedBy() string {
+	if x != nil {
+		return x.RecordedBy
+	}
+	return ""
+}
+
+func (x *MetadataVersion) GetRecordedAt() string {
+	if x != nil {
+		return x.RecordedAt
+	}
+	return ""
+}
+
+var File_types_v1_proto protoreflect.FileDescriptor
+
+const file_types_v1_proto_rawDesc = "" +
+	"\n" +
+	"\x0etypes_v1.proto\x12\x04crud\x1a\x19google/protobuf/any.proto\x1a\x1cgoogle/protobuf/struct.proto\"2\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x05major\x18\x01 \x01(\tR\x05major\x12\x14\n" +
+	"\x05minor\x18\x02 \x01(\tR\x05minor\"t\n" +
+	"\x0eTimeBasedValue\x12\x1c\n" +
+	"\tstartTime\x18\x01 \x01(\tR\tstartTime\x12\x18\n" +
+	"\aendTime\x18\x02 \x01(\tR\aendTime\x12*\n" +
+	"\x05value\x18\x03 \x01(\v2\x14.google.protobuf.AnyR\x05value\"\xcb\x02\n" +
+	"\fRelationship\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12(\n" +
+	"\x0frelatedEntityId\x18\x02 \x01(\tR\x0frelatedEntityId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1c\n" +
+	"\tstartTime\x18\x04 \x01(\tR\tstartTime\x12\x18\n" +
+	"\aendTime\x18\x05 \x01(\tR\aendTime\x12\x1c\n" +
+	"\tdirection\x18\x06 \x01(\tR\tdirection\x12B\n" +
+	"\n" +
+	"properties\x18\a \x03(\v2\".crud.Relationship.PropertiesEntryR\n" +
+	"properties\x1aS\n" +
+	"\x0fPropertiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05value:\x028\x01\"\xdb\x04\n" +
+	"\x06Entity\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1e\n" +
+	"\x04kind\x18\x02 \x01(\v2\n" +
+	".crud.KindR\x04kind\x12\x18\n" +
+	"\acreated\x18\x03 \x01(\tR\acreated\x12\x1e\n" +
+	"\n" +
+	"terminated\x18\x04 \x01(\tR\n" +
+	"terminated\x12(\n" +
+	"\x04name\x18\x05 \x01(\v2\x14.crud.TimeBasedValueR\x04name\x126\n" +
+	"\bmetadata\x18\x06 \x03(\v2\x1a.crud.Entity.MetadataEntryR\bmetadata\x12<\n" +
+	"\n" +
+	"attributes\x18\a \x03(\v2\x1c.crud.Entity.AttributesEntryR\n" +
+	"attributes\x12E\n" +
+	"\rrelationships\x18\b \x03(\v2\x1f.crud.Entity.RelationshipsEntryR\rrelationships\x1aQ\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05value:\x028\x01\x1aW\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.crud.TimeBasedValueListR\x05value:\x028\x01\x1aT\n" +
+	"\x12RelationshipsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.crud.RelationshipR\x05value:\x028\x01\"b\n" +
+	"\x12TimeBasedValueList\x12,\n" +
+	"\x06values\x18\x01 \x03(\v2\x14.crud.TimeBasedValueR\x06values\x12\x1e\n" +
+	"\n" +
+	"jsonSchema\x18\x02 \x01(\tR\n" +
+	"jsonSchema\"X\n" +
+	"\fTabularValue\x12\x18\n" +
+	"\acolumns\x18\x01 \x03(\tR\acolumns\x12.\n" +
+	"\x04rows\x18\x02 \x03(\v2\x1a.google.protobuf.ListValueR\x04rows\"j\n" +
+	"\n" +
+	"GraphValue\x12-\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x05nodes\x12-\n" +
+	"\x05edges\x18\x02 \x03(\v2\x17.google.protobuf.StructR\x05edges\"D\n" +
+	"\rDocumentValue\x123\n" +
+	"\bdocument\x18\x01 \x01(\v2\x17.google.protobuf.StructR\bdocument\";\n" +
+	"\vScalarValue\x12,\n" +
+	"\x05value\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\x05value\"A\n" +
+	"\tBlobValue\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12 \n" +
+	"\vcontentType\x18\x02 \x01(\tR\vcontentType\"\x9c\x01\n" +
+	"\x11ReadEntityRequest\x12$\n" +
+	"\x06entity\x18\x01 \x01(\v2\f.crud.EntityR\x06entity\x12\x16\n" +
+	"\x06output\x18\x02 \x03(\tR\x06output\x12\x1a\n" +
+	"\bactiveAt\x18\x03 \x01(\tR\bactiveAt\x12-\n" +
+	"\aspatial\x18\x04 \x01(\v2\x13.crud.SpatialFilterR\aspatial\"\x1a\n" +
+	"\bEntityId\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x91\x01\n" +
+	"\x13UpdateEntityRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12$\n" +
+	"\x06entity\x18\x02 \x01(\v2\f.crud.EntityR\x06entity\x12&\n" +
+	"\x0eremoveMetadata\x18\x03 \x03(\tR\x0eremoveMetadata\x12\x1c\n" +
+	"\tvalidFrom\x18\x04 \x01(\tR\tvalidFrom\"\a\n" +
+	"\x05Empty\"6\n" +
+	"\n" +
+	"EntityList\x12(\n" +
+	"\bentities\x18\x01 \x03(\v2\f.crud.EntityR\bentities\"\x94\x02\n" +
+	"\x10RelationshipType\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12,\n" +
+	"\vsourceKinds\x18\x02 \x03(\v2\n" +
+	".crud.KindR\vsourceKinds\x12,\n" +
+	"\vtargetKinds\x18\x03 \x03(\v2\n" +
+	".crud.KindR\vtargetKinds\x123\n" +
+	"\vcardinality\x18\x04 \x01(\x0e2\x11.crud.CardinalityR\vcardinality\x129\n" +
+	"\tdirection\x18\x05 \x01(\x0e2\x1b.crud.RelationshipDirectionR\tdirection\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\"\\\n" +
+	"\x14RelationshipTypeList\x12D\n" +
+	"\x11relationshipTypes\x18\x01 \x03(\v2\x16.crud.RelationshipTypeR\x11relationshipTypes\"W\n" +
+	"\x13MetadataFieldSchema\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\"\x88\x01\n" +
+	"\x14AttributeFieldSchema\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vstorageType\x18\x02 \x01(\tR\vstorageType\x12\x1a\n" +
+	"\brequired\x18\x03 \x01(\bR\brequired\x12\x1e\n" +
+	"\n" +
+	"jsonSchema\x18\x04 \x01(\tR\n" +
+	"jsonSchema\"\xe7\x01\n" +
+	"\n" +
+	"KindSchema\x12\x14\n" +
+	"\x05major\x18\x01 \x01(\tR\x05major\x12\x16\n" +
+	"\x06minors\x18\x02 \x03(\tR\x06minors\x125\n" +
+	"\bmetadata\x18\x03 \x03(\v2\x19.crud.MetadataFieldSchemaR\bmetadata\x12:\n" +
+	"\n" +
+	"attributes\x18\x04 \x03(\v2\x1a.crud.AttributeFieldSchemaR\n" +
+	"attributes\x12\x16\n" +
+	"\x06closed\x18\x05 \x01(\bR\x06closed\x12 \n" +
+	"\vdescription\x18\x06 \x01(\tR\vdescription\"8\n" +
+	"\x0eKindSchemaList\x12&\n" +
+	"\x05kinds\x18\x01 \x03(\v2\x10.crud.KindSchemaR\x05kinds\"=\n" +
+	"\vAttributeId\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\"\x9f\x01\n" +
+	"\x0fAttributeSchema\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vstorageType\x18\x03 \x01(\tR\vstorageType\x12\x1e\n" +
+	"\n" +
+	"jsonSchema\x18\x04 \x01(\tR\n" +
+	"jsonSchema\x12\x1a\n" +
+	"\bdeclared\x18\x05 \x01(\bR\bdeclared\"a\n" +
+	"\x15AttributeIndexRequest\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acolumns\x18\x03 \x03(\tR\acolumns\"x\n" +
+	"\x0eAttributeIndex\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acolumns\x18\x03 \x03(\tR\acolumns\x12\x1c\n" +
+	"\tindexName\x18\x04 \x01(\tR\tindexName\"h\n" +
+	"\x12EntityRelationship\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x126\n" +
+	"\frelationship\x18\x02 \x01(\v2\x12.crud.RelationshipR\frelationship\" \n" +
+	"\x0eRelationshipId\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x9a\x01\n" +
+	"\x18ReadRelationshipsRequest\x12&\n" +
+	"\x0esourceEntityId\x18\x01 \x01(\tR\x0esourceEntityId\x12&\n" +
+	"\x0etargetEntityId\x18\x02 \x01(\tR\x0etargetEntityId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1a\n" +
+	"\bactiveAt\x18\x04 \x01(\tR\bactiveAt\"R\n" +
+	"\x10RelationshipList\x12>\n" +
+	"\rrelationships\x18\x01 \x03(\v2\x18.crud.EntityRelationshipR\rrelationships\"H\n" +
+	"\x1cTerminateRelationshipRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aendTime\x18\x02 \x01(\tR\aendTime\"\xa6\x01\n" +
+	"\bBlobInfo\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vcontentType\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06digest\x18\x05 \x01(\tR\x06digest\x12\x1c\n" +
+	"\tstartTime\x18\x06 \x01(\tR\tstartTime\"C\n" +
+	"\tBlobChunk\x12\"\n" +
+	"\x04info\x18\x01 \x01(\v2\x0e.crud.BlobInfoR\x04info\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"\x96\x01\n" +
+	"\rSpatialFilter\x12\x1c\n" +
+	"\tattribute\x18\x01 \x01(\tR\tattribute\x124\n" +
+	"\rcontainsPoint\x18\x02 \x01(\v2\x0e.crud.PositionR\rcontainsPoint\x121\n" +
+	"\n" +
+	"intersects\x18\x03 \x01(\v2\x11.crud.BoundingBoxR\n" +
+	"intersects\"D\n" +
+	"\bPosition\x12\x1c\n" +
+	"\tlongitude\x18\x01 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\blatitude\x18\x02 \x01(\x01R\blatitude\"\x99\x01\n" +
+	"\vBoundingBox\x12\"\n" +
+	"\fminLongitude\x18\x01 \x01(\x01R\fminLongitude\x12 \n" +
+	"\vminLatitude\x18\x02 \x01(\x01R\vminLatitude\x12\"\n" +
+	"\fmaxLongitude\x18\x03 \x01(\x01R\fmaxLongitude\x12 \n" +
+	"\vmaxLatitude\x18\x04 \x01(\x01R\vmaxLatitude\"]\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12 \n" +
+	"\x05kinds\x18\x02 \x03(\v2\n" +
+	".crud.KindR\x05kinds\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"5\n" +
+	"\x0eSearchResponse\x12#\n" +
+	"\x04hits\x18\x01 \x03(\v2\x0f.crud.SearchHitR\x04hits\"t\n" +
+	"\tSearchHit\x12$\n" +
+	"\x06entity\x18\x01 \x01(\v2\f.crud.EntityR\x06entity\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12+\n" +
+	"\amatches\x18\x03 \x03(\v2\x11.crud.SearchMatchR\amatches\"7\n" +
+	"\vSearchMatch\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"F\n" +
+	"\x16MetadataHistoryRequest\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"`\n" +
+	"\x0fMetadataHistory\x12\x1a\n" +
+	"\bentityId\x18\x01 \x01(\tR\bentityId\x121\n" +
+	"\bversions\x18\x02 \x03(\v2\x15.crud.MetadataVersionR\bversions\"\xc7\x01\n" +
+	"\x0fMetadataVersion\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05value\x12\x1c\n" +
+	"\tvalidFrom\x18\x03 \x01(\tR\tvalidFrom\x12\x18\n" +
+	"\avalidTo\x18\x04 \x01(\tR\avalidTo\x12\x1e\n" +
+	"\n" +
+	"recordedBy\x18\x05 \x01(\tR\n" +
+	"recordedBy\x12\x1e\n" +
+	"\n" +
+	"recordedAt\x18\x06 \x01(\tR\n" +
+	"recordedAt*Q\n" +
+	"\vCardinality\x12\x10\n" +
+	"\fMANY_TO_MANY\x10\x00\x12\x0e\n" +
+	"\n" +
+	"ONE_TO_ONE\x10\x01\x12\x0f\n" +
+	"\vONE_TO_MANY\x10\x02\x12\x0f\n" +
+	"\vMANY_TO_ONE\x10\x03*5\n" +
+	"\x15RelationshipDirection\x12\f\n" +
+	"\bDIRECTED\x10\x00\x12\x0e\n" +
+	"\n" +
+	"UNDIRECTED\x10\x012\xd6\t\n" +
+	"\vCrudService\x12*\n" +
+	"\fCreateEntity\x12\f.crud.Entity\x1a\f.crud.Entity\x123\n" +
+	"\n" +
+	"ReadEntity\x12\x17.crud.ReadEntityRequest\x1a\f.crud.Entity\x129\n" +
+	"\fReadEntities\x12\x17.crud.ReadEntityRequest\x1a\x10.crud.EntityList\x127\n" +
+	"\fUpdateEntity\x12\x19.crud.UpdateEntityRequest\x1a\f.crud.Entity\x12+\n" +
+	"\fDeleteEntity\x12\x0e.crud.EntityId\x1a\v.crud.Empty\x12@\n" +
+	"\x15ListRelationshipTypes\x12\v.crud.Empty\x1a\x1a.crud.RelationshipTypeList\x12.\n" +
+	"\tListKinds\x12\v.crud.Empty\x1a\x14.crud.KindSchemaList\x12,\n" +
+	"\fDescribeKind\x12\n" +
+	".crud.Kind\x1a\x10.crud.KindSchema\x12=\n" +
+	"\x11DescribeAttribute\x12\x11.crud.AttributeId\x1a\x15.crud.AttributeSchema\x12I\n" +
+	"\x14CreateAttributeIndex\x12\x1b.crud.AttributeIndexRequest\x1a\x14.crud.AttributeIndex\x12H\n" +
+	"\x12CreateRelationship\x12\x18.crud.EntityRelationship\x1a\x18.crud.EntityRelationship\x12B\n" +
+	"\x10ReadRelationship\x12\x14.crud.RelationshipId\x1a\x18.crud.EntityRelationship\x12K\n" +
+	"\x11ReadRelationships\x12\x1e.crud.ReadRelationshipsRequest\x1a\x16.crud.RelationshipList\x12H\n" +
+	"\x12UpdateRelationship\x12\x18.crud.EntityRelationship\x1a\x18.crud.EntityRelationship\x12U\n" +
+	"\x15TerminateRelationship\x12\".crud.TerminateRelationshipRequest\x1a\x18.crud.EntityRelationship\x127\n" +
+	"\x12DeleteRelationship\x12\x14.crud.RelationshipId\x1a\v.crud.Empty\x12/\n" +
+	"\n" +
+	"UploadBlob\x12\x0f.crud.BlobChunk\x1a\x0e.crud.BlobInfo(\x01\x124\n" +
+	"\fDownloadBlob\x12\x11.crud.AttributeId\x1a\x0f.crud.BlobChunk0\x01\x123\n" +
+	"\x06Search\x12\x13.crud.SearchRequest\x1a\x14.crud.SearchResponse\x12J\n" +
+	"\x13ReadMetadataHistory\x12\x1c.crud.MetadataHistoryRequest\x1a\x15.crud.MetadataHistoryB\x1cZ\x1alk/datafoundation/crud-apib\x06proto3"
 
 var (
 	file_types_v1_proto_rawDescOnce sync.Once
@@ -681,52 +2898,148 @@ func file_types_v1_proto_rawDescGZIP() []byte {
 	return file_types_v1_proto_rawDescData
 }
 
-var file_types_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_types_v1_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_types_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 46)
 var file_types_v1_proto_goTypes = []any{
-	(*Kind)(nil),                // 0: crud.Kind
-	(*TimeBasedValue)(nil),      // 1: crud.TimeBasedValue
-	(*Relationship)(nil),        // 2: crud.Relationship
-	(*Entity)(nil),              // 3: crud.Entity
-	(*TimeBasedValueList)(nil),  // 4: crud.TimeBasedValueList
-	(*ReadEntityRequest)(nil),   // 5: crud.ReadEntityRequest
-	(*EntityId)(nil),            // 6: crud.EntityId
-	(*UpdateEntityRequest)(nil), // 7: crud.UpdateEntityRequest
-	(*Empty)(nil),               // 8: crud.Empty
-	(*EntityList)(nil),          // 9: crud.EntityList
-	nil,                         // 10: crud.Entity.MetadataEntry
-	nil,                         // 11: crud.Entity.AttributesEntry
-	nil,                         // 12: crud.Entity.RelationshipsEntry
-	(*anypb.Any)(nil),           // 13: google.protobuf.Any
+	(Cardinality)(0),                     // 0: crud.Cardinality
+	(RelationshipDirection)(0),           // 1: crud.RelationshipDirection
+	(*Kind)(nil),                         // 2: crud.Kind
+	(*TimeBasedValue)(nil),               // 3: crud.TimeBasedValue
+	(*Relationship)(nil),                 // 4: crud.Relationship
+	(*Entity)(nil),                       // 5: crud.Entity
+	(*TimeBasedValueList)(nil),           // 6: crud.TimeBasedValueList
+	(*TabularValue)(nil),                 // 7: crud.TabularValue
+	(*GraphValue)(nil),                   // 8: crud.GraphValue
+	(*DocumentValue)(nil),                // 9: crud.DocumentValue
+	(*ScalarValue)(nil),                  // 10: crud.ScalarValue
+	(*BlobValue)(nil),                    // 11: crud.BlobValue
+	(*ReadEntityRequest)(nil),            // 12: crud.ReadEntityRequest
+	(*EntityId)(nil),                     // 13: crud.EntityId
+	(*UpdateEntityRequest)(nil),          // 14: crud.UpdateEntityRequest
+	(*Empty)(nil),                        // 15: crud.Empty
+	(*EntityList)(nil),                   // 16: crud.EntityList
+	(*RelationshipType)(nil),             // 17: crud.RelationshipType
+	(*RelationshipTypeList)(nil),         // 18: crud.RelationshipTypeList
+	(*MetadataFieldSchema)(nil),          // 19: crud.MetadataFieldSchema
+	(*AttributeFieldSchema)(nil),         // 20: crud.AttributeFieldSchema
+	(*KindSchema)(nil),                   // 21: crud.KindSchema
+	(*KindSchemaList)(nil),               // 22: crud.KindSchemaList
+	(*AttributeId)(nil),                  // 23: crud.AttributeId
+	(*AttributeSchema)(nil),              // 24: crud.AttributeSchema
+	(*AttributeIndexRequest)(nil),        // 25: crud.AttributeIndexRequest
+	(*AttributeIndex)(nil),               // 26: crud.AttributeIndex
+	(*EntityRelationship)(nil),           // 27: crud.EntityRelationship
+	(*RelationshipId)(nil),               // 28: crud.RelationshipId
+	(*ReadRelationshipsRequest)(nil),     // 29: crud.ReadRelationshipsRequest
+	(*RelationshipList)(nil),             // 30: crud.RelationshipList
+	(*TerminateRelationshipRequest)(nil), // 31: crud.TerminateRelationshipRequest
+	(*BlobInfo)(nil),                     // 32: crud.BlobInfo
+	(*BlobChunk)(nil),                    // 33: crud.BlobChunk
+	(*SpatialFilter)(nil),                // 34: crud.SpatialFilter
+	(*Position)(nil),                     // 35: crud.Position
+	(*BoundingBox)(nil),                  // 36: crud.BoundingBox
+	(*SearchRequest)(nil),                // 37: crud.SearchRequest
+	(*SearchResponse)(nil),               // 38: crud.SearchResponse
+	(*SearchHit)(nil),                    // 39: crud.SearchHit
+	(*SearchMatch)(nil),                  // 40: crud.SearchMatch
+	(*MetadataHistoryRequest)(nil),       // 41: crud.MetadataHistoryRequest
+	(*MetadataHistory)(nil),              // 42: crud.MetadataHistory
+	(*MetadataVersion)(nil),              // 43: crud.MetadataVersion
+	nil,                                  // 44: crud.Relationship.PropertiesEntry
+	nil,                                  // 45: crud.Entity.MetadataEntry
+	nil,                                  // 46: crud.Entity.AttributesEntry
+	nil,                                  // 47: crud.Entity.RelationshipsEntry
+	(*anypb.Any)(nil),                    // 48: google.protobuf.Any
+	(*structpb.ListValue)(nil),           // 49: google.protobuf.ListValue
+	(*structpb.Struct)(nil),              // 50: google.protobuf.Struct
+	(*structpb.Value)(nil),               // 51: google.protobuf.Value
 }
 var file_types_v1_proto_depIdxs = []int32{
-	13, // 0: crud.TimeBasedValue.value:type_name -> google.protobuf.Any
-	0,  // 1: crud.Entity.kind:type_name -> crud.Kind
-	1,  // 2: crud.Entity.name:type_name -> crud.TimeBasedValue
-	10, // 3: crud.Entity.metadata:type_name -> crud.Entity.MetadataEntry
-	11, // 4: crud.Entity.attributes:type_name -> crud.Entity.AttributesEntry
-	12, // 5: crud.Entity.relationships:type_name -> crud.Entity.RelationshipsEntry
-	1,  // 6: crud.TimeBasedValueList.values:type_name -> crud.TimeBasedValue
-	3,  // 7: crud.ReadEntityRequest.entity:type_name -> crud.Entity
-	3,  // 8: crud.UpdateEntityRequest.entity:type_name -> crud.Entity
-	3,  // 9: crud.EntityList.entities:type_name -> crud.Entity
-	13, // 10: crud.Entity.MetadataEntry.value:type_name -> google.protobuf.Any
-	4,  // 11: crud.Entity.AttributesEntry.value:type_name -> crud.TimeBasedValueList
-	2,  // 12: crud.Entity.RelationshipsEntry.value:type_name -> crud.Relationship
-	3,  // 13: crud.CrudService.CreateEntity:input_type -> crud.Entity
-	5,  // 14: crud.CrudService.ReadEntity:input_type -> crud.ReadEntityRequest
-	5,  // 15: crud.CrudService.ReadEntities:input_type -> crud.ReadEntityRequest
-	7,  // 16: crud.CrudService.UpdateEntity:input_type -> crud.UpdateEntityRequest
-	6,  // 17: crud.CrudService.DeleteEntity:input_type -> crud.EntityId
-	3,  // 18: crud.CrudService.CreateEntity:output_type -> crud.Entity
-	3,  // 19: crud.CrudService.ReadEntity:output_type -> crud.Entity
-	9,  // 20: crud.CrudService.ReadEntities:output_type -> crud.EntityList
-	3,  // 21: crud.CrudService.UpdateEntity:output_type -> crud.Entity
-	8,  // 22: crud.CrudService.DeleteEntity:output_type -> crud.Empty
-	18, // [18:23] is the sub-list for method output_type
-	13, // [13:18] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	48, // 0: crud.TimeBasedValue.value:type_name -> google.protobuf.Any
+	44, // 1: crud.Relationship.properties:type_name -> crud.Relationship.PropertiesEntry
+	2,  // 2: crud.Entity.kind:type_name -> crud.Kind
+	3,  // 3: crud.Entity.name:type_name -> crud.TimeBasedValue
+	45, // 4: crud.Entity.metadata:type_name -> crud.Entity.MetadataEntry
+	46, // 5: crud.Entity.attributes:type_name -> crud.Entity.AttributesEntry
+	47, // 6: crud.Entity.relationships:type_name -> crud.Entity.RelationshipsEntry
+	3,  // 7: crud.TimeBasedValueList.values:type_name -> crud.TimeBasedValue
+	49, // 8: crud.TabularValue.rows:type_name -> google.protobuf.ListValue
+	50, // 9: crud.GraphValue.nodes:type_name -> google.protobuf.Struct
+	50, // 10: crud.GraphValue.edges:type_name -> google.protobuf.Struct
+	50, // 11: crud.DocumentValue.document:type_name -> google.protobuf.Struct
+	51, // 12: crud.ScalarValue.value:type_name -> google.protobuf.Value
+	5,  // 13: crud.ReadEntityRequest.entity:type_name -> crud.Entity
+	34, // 14: crud.ReadEntityRequest.spatial:type_name -> crud.SpatialFilter
+	5,  // 15: crud.UpdateEntityRequest.entity:type_name -> crud.Entity
+	5,  // 16: crud.EntityList.entities:type_name -> crud.Entity
+	2,  // 17: crud.RelationshipType.sourceKinds:type_name -> crud.Kind
+	2,  // 18: crud.RelationshipType.targetKinds:type_name -> crud.Kind
+	0,  // 19: crud.RelationshipType.cardinality:type_name -> crud.Cardinality
+	1,  // 20: crud.RelationshipType.direction:type_name -> crud.RelationshipDirection
+	17, // 21: crud.RelationshipTypeList.relationshipTypes:type_name -> crud.RelationshipType
+	19, // 22: crud.KindSchema.metadata:type_name -> crud.MetadataFieldSchema
+	20, // 23: crud.KindSchema.attributes:type_name -> crud.AttributeFieldSchema
+	21, // 24: crud.KindSchemaList.kinds:type_name -> crud.KindSchema
+	4,  // 25: crud.EntityRelationship.relationship:type_name -> crud.Relationship
+	27, // 26: crud.RelationshipList.relationships:type_name -> crud.EntityRelationship
+	32, // 27: crud.BlobChunk.info:type_name -> crud.BlobInfo
+	35, // 28: crud.SpatialFilter.containsPoint:type_name -> crud.Position
+	36, // 29: crud.SpatialFilter.intersects:type_name -> crud.BoundingBox
+	2,  // 30: crud.SearchRequest.kinds:type_name -> crud.Kind
+	39, // 31: crud.SearchResponse.hits:type_name -> crud.SearchHit
+	5,  // 32: crud.SearchHit.entity:type_name -> crud.Entity
+	40, // 33: crud.SearchHit.matches:type_name -> crud.SearchMatch
+	43, // 34: crud.MetadataHistory.versions:type_name -> crud.MetadataVersion
+	48, // 35: crud.MetadataVersion.value:type_name -> google.protobuf.Any
+	48, // 36: crud.Relationship.PropertiesEntry.value:type_name -> google.protobuf.Any
+	48, // 37: crud.Entity.MetadataEntry.value:type_name -> google.protobuf.Any
+	6,  // 38: crud.Entity.AttributesEntry.value:type_name -> crud.TimeBasedValueList
+	4,  // 39: crud.Entity.RelationshipsEntry.value:type_name -> crud.Relationship
+	5,  // 40: crud.CrudService.CreateEntity:input_type -> crud.Entity
+	12, // 41: crud.CrudService.ReadEntity:input_type -> crud.ReadEntityRequest
+	12, // 42: crud.CrudService.ReadEntities:input_type -> crud.ReadEntityRequest
+	14, // 43: crud.CrudService.UpdateEntity:input_type -> crud.UpdateEntityRequest
+	13, // 44: crud.CrudService.DeleteEntity:input_type -> crud.EntityId
+	15, // 45: crud.CrudService.ListRelationshipTypes:input_type -> crud.Empty
+	15, // 46: crud.CrudService.ListKinds:input_type -> crud.Empty
+	2,  // 47: crud.CrudService.DescribeKind:input_type -> crud.Kind
+	23, // 48: crud.CrudService.DescribeAttribute:input_type -> crud.AttributeId
+	25, // 49: crud.CrudService.CreateAttributeIndex:input_type -> crud.AttributeIndexRequest
+	27, // 50: crud.CrudService.CreateRelationship:input_type -> crud.EntityRelationship
+	28, // 51: crud.CrudService.ReadRelationship:input_type -> crud.RelationshipId
+	29, // 52: crud.CrudService.ReadRelationships:input_type -> crud.ReadRelationshipsRequest
+	27, // 53: crud.CrudService.UpdateRelationship:input_type -> crud.EntityRelationship
+	31, // 54: crud.CrudService.TerminateRelationship:input_type -> crud.TerminateRelationshipRequest
+	28, // 55: crud.CrudService.DeleteRelationship:input_type -> crud.RelationshipId
+	33, // 56: crud.CrudService.UploadBlob:input_type -> crud.BlobChunk
+	23, // 57: crud.CrudService.DownloadBlob:input_type -> crud.AttributeId
+	37, // 58: crud.CrudService.Search:input_type -> crud.SearchRequest
+	41, // 59: crud.CrudService.ReadMetadataHistory:input_type -> crud.MetadataHistoryRequest
+	5,  // 60: crud.CrudService.CreateEntity:output_type -> crud.Entity
+	5,  // 61: crud.CrudService.ReadEntity:output_type -> crud.Entity
+	16, // 62: crud.CrudService.ReadEntities:output_type -> crud.EntityList
+	5,  // 63: crud.CrudService.UpdateEntity:output_type -> crud.Entity
+	15, // 64: crud.CrudService.DeleteEntity:output_type -> crud.Empty
+	18, // 65: crud.CrudService.ListRelationshipTypes:output_type -> crud.RelationshipTypeList
+	22, // 66: crud.CrudService.ListKinds:output_type -> crud.KindSchemaList
+	21, // 67: crud.CrudService.DescribeKind:output_type -> crud.KindSchema
+	24, // 68: crud.CrudService.DescribeAttribute:output_type -> crud.AttributeSchema
+	26, // 69: crud.CrudService.CreateAttributeIndex:output_type -> crud.AttributeIndex
+	27, // 70: crud.CrudService.CreateRelationship:output_type -> crud.EntityRelationship
+	27, // 71: crud.CrudService.ReadRelationship:output_type -> crud.EntityRelationship
+	30, // 72: crud.CrudService.ReadRelationships:output_type -> crud.RelationshipList
+	27, // 73: crud.CrudService.UpdateRelationship:output_type -> crud.EntityRelationship
+	27, // 74: crud.CrudService.TerminateRelationship:output_type -> crud.EntityRelationship
+	15, // 75: crud.CrudService.DeleteRelationship:output_type -> crud.Empty
+	32, // 76: crud.CrudService.UploadBlob:output_type -> crud.BlobInfo
+	33, // 77: crud.CrudService.DownloadBlob:output_type -> crud.BlobChunk
+	38, // 78: crud.CrudService.Search:output_type -> crud.SearchResponse
+	42, // 79: crud.CrudService.ReadMetadataHistory:output_type -> crud.MetadataHistory
+	60, // [60:80] is the sub-list for method output_type
+	40, // [40:60] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_types_v1_proto_init() }
@@ -739,13 +3052,14 @@ func file_types_v1_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_types_v1_proto_rawDesc), len(file_types_v1_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   13,
+			NumEnums:      2,
+			NumMessages:   46,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_types_v1_proto_goTypes,
 		DependencyIndexes: file_types_v1_proto_depIdxs,
+		EnumInfos:         file_types_v1_proto_enumTypes,
 		MessageInfos:      file_types_v1_proto_msgTypes,
 	}.Build()
 	File_types_v1_proto = out.File
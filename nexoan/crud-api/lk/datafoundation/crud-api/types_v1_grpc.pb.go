@@ -19,11 +19,26 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CrudService_CreateEntity_FullMethodName = "/crud.CrudService/CreateEntity"
-	CrudService_ReadEntity_FullMethodName   = "/crud.CrudService/ReadEntity"
-	CrudService_ReadEntities_FullMethodName = "/crud.CrudService/ReadEntities"
-	CrudService_UpdateEntity_FullMethodName = "/crud.CrudService/UpdateEntity"
-	CrudService_DeleteEntity_FullMethodName = "/crud.CrudService/DeleteEntity"
+	CrudService_CreateEntity_FullMethodName          = "/crud.CrudService/CreateEntity"
+	CrudService_ReadEntity_FullMethodName            = "/crud.CrudService/ReadEntity"
+	CrudService_ReadEntities_FullMethodName          = "/crud.CrudService/ReadEntities"
+	CrudService_UpdateEntity_FullMethodName          = "/crud.CrudService/UpdateEntity"
+	CrudService_DeleteEntity_FullMethodName          = "/crud.CrudService/DeleteEntity"
+	CrudService_ListRelationshipTypes_FullMethodName = "/crud.CrudService/ListRelationshipTypes"
+	CrudService_ListKinds_FullMethodName             = "/crud.CrudService/ListKinds"
+	CrudService_DescribeKind_FullMethodName          = "/crud.CrudService/DescribeKind"
+	CrudService_DescribeAttribute_FullMethodName     = "/crud.CrudService/DescribeAttribute"
+	CrudService_CreateAttributeIndex_FullMethodName  = "/crud.CrudService/CreateAttributeIndex"
+	CrudService_CreateRelationship_FullMethodName    = "/crud.CrudService/CreateRelationship"
+	CrudService_ReadRelationship_FullMethodName      = "/crud.CrudService/ReadRelationship"
+	CrudService_ReadRelationships_FullMethodName     = "/crud.CrudService/ReadRelationships"
+	CrudService_UpdateRelationship_FullMethodName    = "/crud.CrudService/UpdateRelationship"
+	CrudService_TerminateRelationship_FullMethodName = "/crud.CrudService/TerminateRelationship"
+	CrudService_DeleteRelationship_FullMethodName    = "/crud.CrudService/DeleteRelationship"
+	CrudService_UploadBlob_FullMethodName            = "/crud.CrudService/UploadBlob"
+	CrudService_DownloadBlob_FullMethodName          = "/crud.CrudService/DownloadBlob"
+	CrudService_Search_FullMethodName                = "/crud.CrudService/Search"
+	CrudService_ReadMetadataHistory_FullMethodName   = "/crud.CrudService/ReadMetadataHistory"
 )
 
 // CrudServiceClient is the client API for CrudService service.
@@ -37,6 +52,21 @@ type CrudServiceClient interface {
 	ReadEntities(ctx context.Context, in *ReadEntityRequest, opts ...grpc.CallOption) (*EntityList, error)
 	UpdateEntity(ctx context.Context, in *UpdateEntityRequest, opts ...grpc.CallOption) (*Entity, error)
 	DeleteEntity(ctx context.Context, in *EntityId, opts ...grpc.CallOption) (*Empty, error)
+	ListRelationshipTypes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RelationshipTypeList, error)
+	ListKinds(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*KindSchemaList, error)
+	DescribeKind(ctx context.Context, in *Kind, opts ...grpc.CallOption) (*KindSchema, error)
+	DescribeAttribute(ctx context.Context, in *AttributeId, opts ...grpc.CallOption) (*AttributeSchema, error)
+	CreateAttributeIndex(ctx context.Context, in *AttributeIndexRequest, opts ...grpc.CallOption) (*AttributeIndex, error)
+	CreateRelationship(ctx context.Context, in *EntityRelationship, opts ...grpc.CallOption) (*EntityRelationship, error)
+	ReadRelationship(ctx context.Context, in *RelationshipId, opts ...grpc.CallOption) (*EntityRelationship, error)
+	ReadRelationships(ctx context.Context, in *ReadRelationshipsRequest, opts ...grpc.CallOption) (*RelationshipList, error)
+	UpdateRelationship(ctx context.Context, in *EntityRelationship, opts ...grpc.CallOption) (*EntityRelationship, error)
+	TerminateRelationship(ctx context.Context, in *TerminateRelationshipRequest, opts ...grpc.CallOption) (*EntityRelationship, error)
+	DeleteRelationship(ctx context.Context, in *RelationshipId, opts ...grpc.CallOption) (*Empty, error)
+	UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobChunk, BlobInfo], error)
+	DownloadBlob(ctx context.Context, in *AttributeId, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobChunk], error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	ReadMetadataHistory(ctx context.Context, in *MetadataHistoryRequest, opts ...grpc.CallOption) (*MetadataHistory, error)
 }
 
 type crudServiceClient struct {
@@ -97,6 +127,168 @@ func (c *crudServiceClient) DeleteEntity(ctx context.Context, in *EntityId, opts
 	return out, nil
 }
 
+func (c *crudServiceClient) ListRelationshipTypes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*RelationshipTypeList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RelationshipTypeList)
+	err := c.cc.Invoke(ctx, CrudService_ListRelationshipTypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) ListKinds(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*KindSchemaList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KindSchemaList)
+	err := c.cc.Invoke(ctx, CrudService_ListKinds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) DescribeKind(ctx context.Context, in *Kind, opts ...grpc.CallOption) (*KindSchema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KindSchema)
+	err := c.cc.Invoke(ctx, CrudService_DescribeKind_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) DescribeAttribute(ctx context.Context, in *AttributeId, opts ...grpc.CallOption) (*AttributeSchema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttributeSchema)
+	err := c.cc.Invoke(ctx, CrudService_DescribeAttribute_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) CreateAttributeIndex(ctx context.Context, in *AttributeIndexRequest, opts ...grpc.CallOption) (*AttributeIndex, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttributeIndex)
+	err := c.cc.Invoke(ctx, CrudService_CreateAttributeIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) CreateRelationship(ctx context.Context, in *EntityRelationship, opts ...grpc.CallOption) (*EntityRelationship, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityRelationship)
+	err := c.cc.Invoke(ctx, CrudService_CreateRelationship_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) ReadRelationship(ctx context.Context, in *RelationshipId, opts ...grpc.CallOption) (*EntityRelationship, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityRelationship)
+	err := c.cc.Invoke(ctx, CrudService_ReadRelationship_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) ReadRelationships(ctx context.Context, in *ReadRelationshipsRequest, opts ...grpc.CallOption) (*RelationshipList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RelationshipList)
+	err := c.cc.Invoke(ctx, CrudService_ReadRelationships_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) UpdateRelationship(ctx context.Context, in *EntityRelationship, opts ...grpc.CallOption) (*EntityRelationship, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityRelationship)
+	err := c.cc.Invoke(ctx, CrudService_UpdateRelationship_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) TerminateRelationship(ctx context.Context, in *TerminateRelationshipRequest, opts ...grpc.CallOption) (*EntityRelationship, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EntityRelationship)
+	err := c.cc.Invoke(ctx, CrudService_TerminateRelationship_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) DeleteRelationship(ctx context.Context, in *RelationshipId, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CrudService_DeleteRelationship_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) UploadBlob(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BlobChunk, BlobInfo], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrudService_ServiceDesc.Streams[0], CrudService_UploadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BlobChunk, BlobInfo]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrudService_UploadBlobClient = grpc.ClientStreamingClient[BlobChunk, BlobInfo]
+
+func (c *crudServiceClient) DownloadBlob(ctx context.Context, in *AttributeId, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BlobChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CrudService_ServiceDesc.Streams[1], CrudService_DownloadBlob_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[AttributeId, BlobChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrudService_DownloadBlobClient = grpc.ServerStreamingClient[BlobChunk]
+
+func (c *crudServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, CrudService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *crudServiceClient) ReadMetadataHistory(ctx context.Context, in *MetadataHistoryRequest, opts ...grpc.CallOption) (*MetadataHistory, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MetadataHistory)
+	err := c.cc.Invoke(ctx, CrudService_ReadMetadataHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CrudServiceServer is the server API for CrudService service.
 // All implementations must embed UnimplementedCrudServiceServer
 // for forward compatibility.
@@ -108,6 +300,21 @@ type CrudServiceServer interface {
 	ReadEntities(context.Context, *ReadEntityRequest) (*EntityList, error)
 	UpdateEntity(context.Context, *UpdateEntityRequest) (*Entity, error)
 	DeleteEntity(context.Context, *EntityId) (*Empty, error)
+	ListRelationshipTypes(context.Context, *Empty) (*RelationshipTypeList, error)
+	ListKinds(context.Context, *Empty) (*KindSchemaList, error)
+	DescribeKind(context.Context, *Kind) (*KindSchema, error)
+	DescribeAttribute(context.Context, *AttributeId) (*AttributeSchema, error)
+	CreateAttributeIndex(context.Context, *AttributeIndexRequest) (*AttributeIndex, error)
+	CreateRelationship(context.Context, *EntityRelationship) (*EntityRelationship, error)
+	ReadRelationship(context.Context, *RelationshipId) (*EntityRelationship, error)
+	ReadRelationships(context.Context, *ReadRelationshipsRequest) (*RelationshipList, error)
+	UpdateRelationship(context.Context, *EntityRelationship) (*EntityRelationship, error)
+	TerminateRelationship(context.Context, *TerminateRelationshipRequest) (*EntityRelationship, error)
+	DeleteRelationship(context.Context, *RelationshipId) (*Empty, error)
+	UploadBlob(grpc.ClientStreamingServer[BlobChunk, BlobInfo]) error
+	DownloadBlob(*AttributeId, grpc.ServerStreamingServer[BlobChunk]) error
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	ReadMetadataHistory(context.Context, *MetadataHistoryRequest) (*MetadataHistory, error)
 	mustEmbedUnimplementedCrudServiceServer()
 }
 
@@ -133,6 +340,51 @@ func (UnimplementedCrudServiceServer) UpdateEntity(context.Context, *UpdateEntit
 func (UnimplementedCrudServiceServer) DeleteEntity(context.Context, *EntityId) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteEntity not implemented")
 }
+func (UnimplementedCrudServiceServer) ListRelationshipTypes(context.Context, *Empty) (*RelationshipTypeList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRelationshipTypes not implemented")
+}
+func (UnimplementedCrudServiceServer) ListKinds(context.Context, *Empty) (*KindSchemaList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListKinds not implemented")
+}
+func (UnimplementedCrudServiceServer) DescribeKind(context.Context, *Kind) (*KindSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeKind not implemented")
+}
+func (UnimplementedCrudServiceServer) DescribeAttribute(context.Context, *AttributeId) (*AttributeSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeAttribute not implemented")
+}
+func (UnimplementedCrudServiceServer) CreateAttributeIndex(context.Context, *AttributeIndexRequest) (*AttributeIndex, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAttributeIndex not implemented")
+}
+func (UnimplementedCrudServiceServer) CreateRelationship(context.Context, *EntityRelationship) (*EntityRelationship, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateRelationship not implemented")
+}
+func (UnimplementedCrudServiceServer) ReadRelationship(context.Context, *RelationshipId) (*EntityRelationship, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadRelationship not implemented")
+}
+func (UnimplementedCrudServiceServer) ReadRelationships(context.Context, *ReadRelationshipsRequest) (*RelationshipList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadRelationships not implemented")
+}
+func (UnimplementedCrudServiceServer) UpdateRelationship(context.Context, *EntityRelationship) (*EntityRelationship, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRelationship not implemented")
+}
+func (UnimplementedCrudServiceServer) TerminateRelationship(context.Context, *TerminateRelationshipRequest) (*EntityRelationship, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TerminateRelationship not implemented")
+}
+func (UnimplementedCrudServiceServer) DeleteRelationship(context.Context, *RelationshipId) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRelationship not implemented")
+}
+func (UnimplementedCrudServiceServer) UploadBlob(grpc.ClientStreamingServer[BlobChunk, BlobInfo]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadBlob not implemented")
+}
+func (UnimplementedCrudServiceServer) DownloadBlob(*AttributeId, grpc.ServerStreamingServer[BlobChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadBlob not implemented")
+}
+func (UnimplementedCrudServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedCrudServiceServer) ReadMetadataHistory(context.Context, *MetadataHistoryRequest) (*MetadataHistory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadMetadataHistory not implemented")
+}
 func (UnimplementedCrudServiceServer) mustEmbedUnimplementedCrudServiceServer() {}
 func (UnimplementedCrudServiceServer) testEmbeddedByValue()                     {}
 
@@ -244,6 +496,258 @@ func _CrudService_DeleteEntity_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CrudService_ListRelationshipTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).ListRelationshipTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_ListRelationshipTypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).ListRelationshipTypes(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_ListKinds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).ListKinds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_ListKinds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).ListKinds(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_DescribeKind_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Kind)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).DescribeKind(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_DescribeKind_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).DescribeKind(ctx, req.(*Kind))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_DescribeAttribute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).DescribeAttribute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_DescribeAttribute_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).DescribeAttribute(ctx, req.(*AttributeId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_CreateAttributeIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).CreateAttributeIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_CreateAttributeIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).CreateAttributeIndex(ctx, req.(*AttributeIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_CreateRelationship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EntityRelationship)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).CreateRelationship(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_CreateRelationship_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).CreateRelationship(ctx, req.(*EntityRelationship))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_ReadRelationship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelationshipId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).ReadRelationship(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_ReadRelationship_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).ReadRelationship(ctx, req.(*RelationshipId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_ReadRelationships_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadRelationshipsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).ReadRelationships(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_ReadRelationships_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).ReadRelationships(ctx, req.(*ReadRelationshipsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_UpdateRelationship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EntityRelationship)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).UpdateRelationship(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_UpdateRelationship_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).UpdateRelationship(ctx, req.(*EntityRelationship))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_TerminateRelationship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TerminateRelationshipRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).TerminateRelationship(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_TerminateRelationship_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).TerminateRelationship(ctx, req.(*TerminateRelationshipRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_DeleteRelationship_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelationshipId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).DeleteRelationship(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_DeleteRelationship_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).DeleteRelationship(ctx, req.(*RelationshipId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_UploadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CrudServiceServer).UploadBlob(&grpc.GenericServerStream[BlobChunk, BlobInfo]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrudService_UploadBlobServer = grpc.ClientStreamingServer[BlobChunk, BlobInfo]
+
+func _CrudService_DownloadBlob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttributeId)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CrudServiceServer).DownloadBlob(m, &grpc.GenericServerStream[AttributeId, BlobChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CrudService_DownloadBlobServer = grpc.ServerStreamingServer[BlobChunk]
+
+func _CrudService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CrudService_ReadMetadataHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetadataHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CrudServiceServer).ReadMetadataHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CrudService_ReadMetadataHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CrudServiceServer).ReadMetadataHistory(ctx, req.(*MetadataHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CrudService_ServiceDesc is the grpc.ServiceDesc for CrudService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -271,7 +775,70 @@ var CrudService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteEntity",
 			Handler:    _CrudService_DeleteEntity_Handler,
 		},
+		{
+			MethodName: "ListRelationshipTypes",
+			Handler:    _CrudService_ListRelationshipTypes_Handler,
+		},
+		{
+			MethodName: "ListKinds",
+			Handler:    _CrudService_ListKinds_Handler,
+		},
+		{
+			MethodName: "DescribeKind",
+			Handler:    _CrudService_DescribeKind_Handler,
+		},
+		{
+			MethodName: "DescribeAttribute",
+			Handler:    _CrudService_DescribeAttribute_Handler,
+		},
+		{
+			MethodName: "CreateAttributeIndex",
+			Handler:    _CrudService_CreateAttributeIndex_Handler,
+		},
+		{
+			MethodName: "CreateRelationship",
+			Handler:    _CrudService_CreateRelationship_Handler,
+		},
+		{
+			MethodName: "ReadRelationship",
+			Handler:    _CrudService_ReadRelationship_Handler,
+		},
+		{
+			MethodName: "ReadRelationships",
+			Handler:    _CrudService_ReadRelationships_Handler,
+		},
+		{
+			MethodName: "UpdateRelationship",
+			Handler:    _CrudService_UpdateRelationship_Handler,
+		},
+		{
+			MethodName: "TerminateRelationship",
+			Handler:    _CrudService_TerminateRelationship_Handler,
+		},
+		{
+			MethodName: "DeleteRelationship",
+			Handler:    _CrudService_DeleteRelationship_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _CrudService_Search_Handler,
+		},
+		{
+			MethodName: "ReadMetadataHistory",
+			Handler:    _CrudService_ReadMetadataHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "UploadBlob",
+			Handler:       _CrudService_UploadBlob_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadBlob",
+			Handler:       _CrudService_DownloadBlob_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "types_v1.proto",
 }
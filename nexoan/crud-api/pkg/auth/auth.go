@@ -0,0 +1,95 @@
+// Package auth authenticates the callers of the CrudService and authorizes their RPCs.
+// Callers present a client certificate (mtls) or a bearer token signed by a key of a local JWKS file (jwt),
+// and a policy file restricts what each caller may read or write per RPC, per Kind.Major and per metadata key.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"lk/datafoundation/crud-api/db/config"
+)
+
+// AnonymousSubject is the subject of callers without credentials when anonymous access is allowed
+const AnonymousSubject = "anonymous"
+
+// ErrNoCredentials is returned by an Authenticator when the caller did not present its kind of credentials,
+// the next authenticator is then tried
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is an authenticated caller
+type Principal struct {
+	// Subject identifies the caller: the common name of its certificate or the sub claim of its token
+	Subject string
+	// Method is the authentication method that accepted the caller: mtls, jwt or anonymous
+	Method string
+	// Roles are the organizational units of the certificate or the roles claim of the token
+	Roles []string
+}
+
+// Authenticator extracts and verifies the credentials of a caller from the context of an RPC
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Principal, error)
+}
+
+// contextKey is the type of the context keys defined by this package
+type contextKey int
+
+const principalKey contextKey = iota
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal of the RPC, or nil when authentication is disabled
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey).(*Principal)
+	return principal
+}
+
+// SubjectFromContext returns the subject of the principal of the RPC, or an empty string when authentication
+// is disabled
+func SubjectFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.Subject
+	}
+	return ""
+}
+
+// NewAuthenticators creates the authenticators of the configured methods, in the configured order
+func NewAuthenticators(cfg config.AuthConfig) ([]Authenticator, error) {
+	var authenticators []Authenticator
+	for _, method := range cfg.Methods {
+		switch method {
+		case "mtls":
+			authenticators = append(authenticators, &MTLSAuthenticator{})
+		case "jwt":
+			authenticator, err := NewJWTAuthenticator(cfg)
+			if err != nil {
+				return nil, err
+			}
+			authenticators = append(authenticators, authenticator)
+		default:
+			return nil, fmt.Errorf("unknown authentication method %q", method)
+		}
+	}
+	return authenticators, nil
+}
+
+// New creates the guard described by the configuration, resolver looks up the kinds of the entities
+// referenced by ID when the policy restricts kinds
+func New(cfg config.AuthConfig, resolver Resolver) (*Guard, error) {
+	authenticators, err := NewAuthenticators(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var policy *Policy
+	if cfg.PolicyFile != "" {
+		if policy, err = LoadPolicy(cfg.PolicyFile); err != nil {
+			return nil, err
+		}
+	}
+	return NewGuard(authenticators, cfg.AllowAnonymous, policy, resolver), nil
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// writeJWKS writes a key set holding the public keys by key ID and returns its path
+func writeJWKS(t *testing.T, keys map[string]crypto.PublicKey) string {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+	var set []map[string]string
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			set = append(set, map[string]string{"kid": kid, "kty": "RSA", "use": "sig", "n": encode(key.N), "e": encode(big.NewInt(int64(key.E)))})
+		case *ecdsa.PublicKey:
+			set = append(set, map[string]string{"kid": kid, "kty": "EC", "crv": "P-256", "x": encode(key.X), "y": encode(key.Y)})
+		}
+	}
+	content, err := json.Marshal(map[string]interface{}{"keys": set})
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// bearer returns an incoming context carrying a token signed by key
+func bearer(t *testing.T, method jwt.SigningMethod, kid string, key crypto.PrivateKey, claims jwt.MapClaims) context.Context {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+signed))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	path := writeJWKS(t, map[string]crypto.PublicKey{"rsa-1": &rsaKey.PublicKey, "ec-1": &ecKey.PublicKey})
+
+	authenticator, err := NewJWTAuthenticator(config.AuthConfig{JWKSFile: path, Issuer: "https://issuer", Audience: "crud-api", RolesClaim: "roles"})
+	assert.NoError(t, err)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub": "reader-1", "iss": "https://issuer", "aud": "crud-api",
+			"exp": time.Now().Add(time.Hour).Unix(), "roles": []string{"public"},
+		}
+	}
+
+	principal, err := authenticator.Authenticate(bearer(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, valid()))
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "reader-1", Method: "jwt", Roles: []string{"public"}}, principal)
+
+	claims := valid()
+	claims["roles"] = "editor reviewer"
+	principal, err = authenticator.Authenticate(bearer(t, jwt.SigningMethodES256, "ec-1", ecKey, claims))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"editor", "reviewer"}, principal.Roles)
+
+	// Callers without a bearer token are left to the next authenticator
+	_, err = authenticator.Authenticate(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+
+	// Expired tokens, other issuers, unknown keys and symmetric signatures are rejected
+	claims = valid()
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+	_, err = authenticator.Authenticate(bearer(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+	assert.ErrorContains(t, err, "expired")
+
+	claims = valid()
+	claims["iss"] = "https://elsewhere"
+	_, err = authenticator.Authenticate(bearer(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims))
+	assert.Error(t, err)
+
+	_, err = authenticator.Authenticate(bearer(t, jwt.SigningMethodRS256, "rsa-2", rsaKey, valid()))
+	assert.ErrorContains(t, err, "unknown key")
+
+	_, err = authenticator.Authenticate(bearer(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), valid()))
+	assert.Error(t, err)
+}
+
+func TestLoadJWKSErrors(t *testing.T) {
+	_, err := LoadJWKS(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kid":"ec","kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`), 0o600))
+	_, err = LoadJWKS(path)
+	assert.ErrorContains(t, err, "invalid point")
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"keys":[{"kid":"enc","kty":"RSA","use":"enc","n":"AQ","e":"AQAB"}]}`), 0o600))
+	_, err = LoadJWKS(path)
+	assert.ErrorContains(t, err, "no signing keys")
+}
+
+// selfSignedCertificate creates a certificate for the common name and organizational units
+func selfSignedCertificate(t *testing.T, commonName string, units []string) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, OrganizationalUnit: units},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return certificate
+}
+
+func TestMTLSAuthenticator(t *testing.T) {
+	authenticator := &MTLSAuthenticator{}
+	certificate := selfSignedCertificate(t, "ingest-job", []string{"editor"})
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}},
+	}})
+	principal, err := authenticator.Authenticate(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "ingest-job", Method: "mtls", Roles: []string{"editor"}}, principal)
+
+	// Connections without a verified client certificate carry no credentials
+	ctx = peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{}})
+	_, err = authenticator.Authenticate(ctx)
+	assert.ErrorIs(t, err, ErrNoCredentials)
+	_, err = authenticator.Authenticate(context.Background())
+	assert.ErrorIs(t, err, ErrNoCredentials)
+}
+
+func TestNewAuthenticators(t *testing.T) {
+	_, err := NewAuthenticators(config.AuthConfig{Methods: []string{"jwt"}, JWKSFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.Error(t, err)
+
+	authenticators, err := NewAuthenticators(config.AuthConfig{Methods: []string{"mtls"}})
+	assert.NoError(t, err)
+	assert.Len(t, authenticators, 1)
+}
@@ -0,0 +1,364 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+	"lk/datafoundation/crud-api/pkg/search"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// servicePrefix identifies the CrudService methods, the health and reflection services are not guarded
+const servicePrefix = "/crud.CrudService/"
+
+// Resolver looks up the stored entities and relationships referenced by ID in a request,
+// so that the policy can be applied to the kind of the entities they belong to.
+// Its errors wrap commons.ErrNotFound when the entity or relationship does not exist.
+type Resolver interface {
+	EntityKind(ctx context.Context, entityID string) (*pb.Kind, error)
+	Relationship(ctx context.Context, relationshipID string) (*pb.EntityRelationship, error)
+}
+
+// Guard authenticates the callers of the CrudService and applies the policy to their requests and responses
+type Guard struct {
+	authenticators []Authenticator
+	allowAnonymous bool
+	// policy is nil when every authenticated caller may do anything
+	policy   *Policy
+	resolver Resolver
+}
+
+// NewGuard creates a guard accepting the credentials checked by authenticators. Callers without credentials
+// are rejected unless allowAnonymous is set and a nil policy allows every request.
+func NewGuard(authenticators []Authenticator, allowAnonymous bool, policy *Policy, resolver Resolver) *Guard {
+	return &Guard{authenticators: authenticators, allowAnonymous: allowAnonymous, policy: policy, resolver: resolver}
+}
+
+// UnaryServerInterceptor authenticates the caller, authorizes the request and removes the metadata the caller
+// may not read from the response
+func (g *Guard) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		rpc, guarded := strings.CutPrefix(info.FullMethod, servicePrefix)
+		if !guarded {
+			return handler(ctx, req)
+		}
+		ctx, principal, err := g.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		kinds, err := g.authorize(ctx, principal, rpc, req)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if err := g.filter(ctx, principal, rpc, kinds, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor authenticates the caller of a streaming RPC and authorizes every message it sends
+// as a request of its own, see UnaryServerInterceptor
+func (g *Guard) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		rpc, guarded := strings.CutPrefix(info.FullMethod, servicePrefix)
+		if !guarded {
+			return handler(srv, stream)
+		}
+		ctx, principal, err := g.authenticate(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &guardedStream{ServerStream: stream, ctx: ctx, guard: g, principal: principal, rpc: rpc})
+	}
+}
+
+// authenticate returns a context carrying the principal of the caller and a logger including its subject
+func (g *Guard) authenticate(ctx context.Context) (context.Context, *Principal, error) {
+	var principal *Principal
+	for _, authenticator := range g.authenticators {
+		authenticated, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "Authentication failed", "error", err)
+			return ctx, nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		principal = authenticated
+		break
+	}
+	if principal == nil {
+		if !g.allowAnonymous {
+			return ctx, nil, status.Error(codes.Unauthenticated, "credentials are required")
+		}
+		principal = &Principal{Subject: AnonymousSubject, Method: "anonymous"}
+	}
+
+	ctx = WithPrincipal(ctx, principal)
+	ctx = logging.WithLogger(ctx, logging.FromContext(ctx).With("subject", principal.Subject))
+	return ctx, principal, nil
+}
+
+// authorize applies the policy to a request and returns the kinds of the entities it touches
+func (g *Guard) authorize(ctx context.Context, principal *Principal, rpc string, req interface{}) ([]string, error) {
+	if g.policy == nil {
+		return nil, nil
+	}
+	request, err := g.describe(ctx, rpc, req)
+	if err != nil {
+		return nil, err
+	}
+	allowed, rule := g.policy.Authorize(principal, request)
+	if !allowed {
+		logging.FromContext(ctx).WarnContext(ctx, "Request denied", "rpc", rpc, "access", request.Access, "kinds", request.Kinds,
+			"unknown_kinds", request.UnknownKinds, "rule", rule)
+		return nil, status.Errorf(codes.PermissionDenied, "%s may not call %s", principal.Subject, rpc)
+	}
+	return request.Kinds, nil
+}
+
+// describe extracts the kinds and the written metadata keys of a request, relationships touch the kinds of
+// both their endpoints. Kinds of entities referenced by ID are only resolved when the policy restricts kinds,
+// entities and relationships that cannot be found have an unknown kind. The request cannot be described,
+// and fails with Unavailable, when the resolver fails otherwise.
+func (g *Guard) describe(ctx context.Context, rpc string, req interface{}) (*Request, error) {
+	request := &Request{RPC: rpc, Access: AccessOf(rpc)}
+	resolveKinds := g.policy.UsesKinds()
+	var resolveErr error
+	addKind := func(kind *pb.Kind) {
+		if kind != nil && kind.Major != "" {
+			request.Kinds = append(request.Kinds, kind.Major)
+		}
+	}
+	// resolved records the outcome of a lookup and reports whether it found what it looked for
+	resolved := func(err error) bool {
+		if err != nil && !errors.Is(err, commons.ErrNotFound) && resolveErr == nil {
+			resolveErr = err
+		}
+		if err != nil {
+			request.UnknownKinds = true
+		}
+		return err == nil
+	}
+	addEntity := func(entityID string) {
+		if !resolveKinds || entityID == "" {
+			return
+		}
+		if g.resolver == nil {
+			request.UnknownKinds = true
+			return
+		}
+		kind, err := g.resolver.EntityKind(ctx, entityID)
+		if !resolved(err) {
+			return
+		}
+		if kind.GetMajor() == "" {
+			request.UnknownKinds = true
+		}
+		addKind(kind)
+	}
+	addRelationship := func(relationshipID string) {
+		if !resolveKinds || relationshipID == "" {
+			return
+		}
+		if g.resolver == nil {
+			request.UnknownKinds = true
+			return
+		}
+		relationship, err := g.resolver.Relationship(ctx, relationshipID)
+		if resolved(err) {
+			addEntity(relationship.EntityId)
+			addEntity(relationship.GetRelationship().GetRelatedEntityId())
+		}
+	}
+	addMetadata := func(entity *pb.Entity) {
+		for key := range entity.GetMetadata() {
+			request.MetadataKeys = append(request.MetadataKeys, key)
+		}
+	}
+
+	switch message := req.(type) {
+	case *pb.Entity:
+		// The kind of a new entity is given by the caller
+		addKind(message.Kind)
+		addMetadata(message)
+	case *pb.UpdateEntityRequest:
+		// The kind cannot be updated, the stored one applies
+		addEntity(message.Id)
+		addMetadata(message.Entity)
+		request.MetadataKeys = append(request.MetadataKeys, message.RemoveMetadata...)
+	case *pb.ReadEntityRequest:
+		if message.GetEntity().GetId() != "" {
+			addEntity(message.Entity.Id)
+		} else {
+			// Searches only return entities of the kind they filter on
+			addKind(message.GetEntity().GetKind())
+		}
+	case *pb.EntityId:
+		addEntity(message.Id)
+	case *pb.MetadataHistoryRequest:
+		addEntity(message.EntityId)
+		if message.Key != "" {
+			request.MetadataKeys = append(request.MetadataKeys, message.Key)
+		}
+	case *pb.AttributeId:
+		addEntity(message.EntityId)
+	case *pb.AttributeIndexRequest:
+		addEntity(message.EntityId)
+	case *pb.BlobChunk:
+		addEntity(message.GetInfo().GetEntityId())
+	case *pb.Kind:
+		addKind(message)
+	case *pb.EntityRelationship:
+		if rpc == "CreateRelationship" {
+			addEntity(message.EntityId)
+			addEntity(message.GetRelationship().GetRelatedEntityId())
+		} else {
+			addRelationship(message.GetRelationship().GetId())
+		}
+	case *pb.RelationshipId:
+		addRelationship(message.Id)
+	case *pb.TerminateRelationshipRequest:
+		addRelationship(message.Id)
+	case *pb.ReadRelationshipsRequest:
+		addEntity(message.SourceEntityId)
+		addEntity(message.TargetEntityId)
+	case *pb.SearchRequest:
+		// Searches only return entities of the kinds they filter on
+		for _, kind := range message.Kinds {
+			addKind(kind)
+		}
+	}
+	if resolveErr != nil {
+		logging.FromContext(ctx).ErrorContext(ctx, "Error resolving the kinds of a request", "rpc", rpc, "error", resolveErr)
+		return nil, status.Errorf(codes.Unavailable, "cannot authorize %s: %v", rpc, resolveErr)
+	}
+	return request, nil
+}
+
+// filter removes from the response of a read RPC the entities of kinds the principal may not read, the metadata
+// keys it may not read from the other entities, the search matches found in them and their history. A single
+// entity of a denied kind fails the RPC with PermissionDenied. Write RPCs only return what the caller sent or
+// may write.
+func (g *Guard) filter(ctx context.Context, principal *Principal, rpc string, kinds []string, resp interface{}) error {
+	if g.policy == nil || AccessOf(rpc) != Read {
+		return nil
+	}
+	// mayRead applies the policy to an entity and the given metadata keys of it, the kinds of the request
+	// apply to entities returned without one
+	mayRead := func(entity *pb.Entity, keys ...string) bool {
+		request := &Request{RPC: rpc, Access: Read, Kinds: kinds, UnknownKinds: len(kinds) == 0, MetadataKeys: keys}
+		if major := entity.GetKind().GetMajor(); major != "" {
+			request.Kinds, request.UnknownKinds = []string{major}, false
+		}
+		allowed, _ := g.policy.Authorize(principal, request)
+		return allowed
+	}
+	// filterEntity removes the metadata the principal may not read and reports whether it may read the entity
+	filterEntity := func(entity *pb.Entity) bool {
+		if entity == nil {
+			return true
+		}
+		if !mayRead(entity) {
+			return false
+		}
+		for key := range entity.Metadata {
+			if !mayRead(entity, key) {
+				delete(entity.Metadata, key)
+			}
+		}
+		return true
+	}
+
+	switch message := resp.(type) {
+	case *pb.Entity:
+		if !filterEntity(message) {
+			logging.FromContext(ctx).WarnContext(ctx, "Response denied", "rpc", rpc, "kind", message.GetKind().GetMajor())
+			return status.Errorf(codes.PermissionDenied, "%s may not read entity %s", principal.Subject, message.Id)
+		}
+	case *pb.EntityList:
+		entities := message.Entities[:0]
+		for _, entity := range message.Entities {
+			if filterEntity(entity) {
+				entities = append(entities, entity)
+			}
+		}
+		message.Entities = entities
+	case *pb.MetadataHistory:
+		versions := message.Versions[:0]
+		for _, version := range message.Versions {
+			if mayRead(nil, version.Key) {
+				versions = append(versions, version)
+			}
+		}
+		message.Versions = versions
+	case *pb.SearchResponse:
+		// A hit found only in metadata the principal may not read is removed, it would disclose the value
+		hits := message.Hits[:0]
+		for _, hit := range message.Hits {
+			if !mayRead(hit.Entity) {
+				continue
+			}
+			matches := hit.Matches[:0]
+			for _, match := range hit.Matches {
+				key, isMetadata := strings.CutPrefix(match.Field, search.MetadataFieldPrefix)
+				if !isMetadata || mayRead(hit.Entity, key) {
+					matches = append(matches, match)
+				}
+			}
+			hit.Matches = matches
+			if len(matches) > 0 {
+				filterEntity(hit.Entity)
+				hits = append(hits, hit)
+			}
+		}
+		message.Hits = hits
+	}
+	return nil
+}
+
+// guardedStream authorizes every message received on a stream and filters every message sent
+type guardedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	guard     *Guard
+	principal *Principal
+	rpc       string
+	received  bool
+}
+
+func (s *guardedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *guardedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	// Only the first chunk of an upload names its entity, the following ones were authorized with it
+	if chunk, ok := m.(*pb.BlobChunk); ok && chunk.Info == nil && s.received {
+		return nil
+	}
+	s.received = true
+	_, err := s.guard.authorize(s.ctx, s.principal, s.rpc, m)
+	return err
+}
+
+func (s *guardedStream) SendMsg(m interface{}) error {
+	if err := s.guard.filter(s.ctx, s.principal, s.rpc, nil, m); err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+// jwtAlgorithms are the signature algorithms accepted for bearer tokens, tokens signed with none or HMAC are rejected
+var jwtAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// JWTAuthenticator accepts callers presenting a bearer token in the authorization metadata.
+// The token must be signed by a key of the JWKS file and, when configured, issued by the issuer for the audience.
+type JWTAuthenticator struct {
+	keys       map[string]crypto.PublicKey
+	parser     *jwt.Parser
+	rolesClaim string
+}
+
+// NewJWTAuthenticator creates an authenticator verifying tokens with the keys of cfg.JWKSFile
+func NewJWTAuthenticator(cfg config.AuthConfig) (*JWTAuthenticator, error) {
+	keys, err := LoadJWKS(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(jwtAlgorithms), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	rolesClaim := cfg.RolesClaim
+	if rolesClaim == "" {
+		rolesClaim = "roles"
+	}
+	return &JWTAuthenticator{keys: keys, parser: jwt.NewParser(options...), rolesClaim: rolesClaim}, nil
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, ErrNoCredentials
+	}
+	scheme, raw, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(raw), claims, a.key); err != nil {
+		return nil, fmt.Errorf("invalid bearer token: %v", err)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("invalid bearer token: the sub claim is required")
+	}
+	return &Principal{Subject: subject, Method: "jwt", Roles: roles(claims[a.rolesClaim])}, nil
+}
+
+// key returns the key a token is signed with, the kid header can only be omitted when the key set holds a single key
+func (a *JWTAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" && len(a.keys) == 1 {
+		for _, key := range a.keys {
+			return key, nil
+		}
+	}
+	key, ok := a.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+// roles reads the roles claim, given either as a list or as a space separated string like the scope claim
+func roles(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		var result []string
+		for _, item := range value {
+			if role, ok := item.(string); ok {
+				result = append(result, role)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// jsonWebKey holds the fields of the RSA and EC public keys of a JSON Web Key Set
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// LoadJWKS reads the RSA and EC public keys of a JSON Web Key Set file, by key ID.
+// Keys that are not meant for signatures are skipped.
+func LoadJWKS(path string) (map[string]crypto.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JWKS file %s: %v", path, err)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("error decoding JWKS file %s: %v", path, err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for i, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d in JWKS file %s: %v", i, path, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s holds no signing keys", path)
+	}
+	return keys, nil
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %v", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %v", err)
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %v", err)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		// The conversion rejects points that are not on the curve
+		if _, err := key.ECDH(); err != nil {
+			return nil, fmt.Errorf("invalid point: %v", err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+// decodeInt decodes a base64url encoded big-endian integer
+func decodeInt(value string) (*big.Int, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing value")
+	}
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(content), nil
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// MTLSAuthenticator accepts callers presenting a client certificate verified by the TLS listener.
+// The common name of the certificate is the subject and its organizational units are the roles.
+type MTLSAuthenticator struct{}
+
+func (a *MTLSAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	certificate := info.State.VerifiedChains[0][0]
+	return &Principal{
+		Subject: certificate.Subject.CommonName,
+		Method:  "mtls",
+		Roles:   certificate.Subject.OrganizationalUnit,
+	}, nil
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Access is the kind of access an RPC needs
+type Access string
+
+const (
+	Read  Access = "read"
+	Write Access = "write"
+)
+
+// Effect is the decision of a rule
+type Effect string
+
+const (
+	Allow Effect = "allow"
+	Deny  Effect = "deny"
+)
+
+// wildcard matches any value in the lists of a rule
+const wildcard = "*"
+
+// Request describes an RPC, or the part of its response being filtered, to the policy
+type Request struct {
+	// RPC is the name of the CrudService method, such as UpdateEntity
+	RPC    string
+	Access Access
+	// Kinds are the Kind.Major of the entities the request touches, empty when it touches no stored entity
+	// or searches any kind
+	Kinds []string
+	// UnknownKinds is set when the request touches an entity whose kind could not be resolved
+	UnknownKinds bool
+	// MetadataKeys are the metadata keys the request writes or the response returns
+	MetadataKeys []string
+}
+
+// Rule grants or denies requests. Every list that is set must match the request and "*" matches anything.
+type Rule struct {
+	Name   string `yaml:"name"`
+	Effect Effect `yaml:"effect"`
+
+	// Roles and Subjects select the callers the rule applies to
+	Roles    []string `yaml:"roles"`
+	Subjects []string `yaml:"subjects"`
+
+	// RPCs lists CrudService method names such as ReadEntity
+	RPCs   []string `yaml:"rpcs"`
+	Access Access   `yaml:"access"`
+	// Kinds lists Kind.Major values. An allow rule matches when every kind of the request is listed,
+	// a deny rule when any of them is. An entity of unknown kind may be of any kind, so requests touching one
+	// match every deny rule listing kinds and only the allow rules listing "*".
+	Kinds []string `yaml:"kinds"`
+	// MetadataKeys lists metadata keys. An allow rule matches when every key of the request is listed,
+	// a deny rule when any of them is.
+	MetadataKeys []string `yaml:"metadata_keys"`
+}
+
+// Policy decides which requests a principal may make. A request is denied when a deny rule matches it,
+// otherwise it is allowed when an allow rule matches it and the default effect applies when no rule does.
+type Policy struct {
+	// DefaultEffect applies to requests matched by no rule, defaults to deny
+	DefaultEffect Effect `yaml:"default_effect"`
+	Rules         []Rule `yaml:"rules"`
+}
+
+// LoadPolicy reads and validates a YAML policy file
+func LoadPolicy(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading policy file %s: %v", path, err)
+	}
+
+	policy := &Policy{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error decoding policy file %s: %v", path, err)
+	}
+	if policy.DefaultEffect == "" {
+		policy.DefaultEffect = Deny
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks the effects, access modes and RPC names of the policy and reports every problem at once
+func (p *Policy) Validate() error {
+	var errs []error
+	if p.DefaultEffect != Allow && p.DefaultEffect != Deny {
+		errs = append(errs, fmt.Errorf("default_effect must be allow or deny, got %q", p.DefaultEffect))
+	}
+
+	rpcs := map[string]bool{}
+	for _, method := range pb.CrudService_ServiceDesc.Methods {
+		rpcs[method.MethodName] = true
+	}
+	for _, stream := range pb.CrudService_ServiceDesc.Streams {
+		rpcs[stream.StreamName] = true
+	}
+
+	for i, rule := range p.Rules {
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.Effect != Allow && rule.Effect != Deny {
+			errs = append(errs, fmt.Errorf("rule %s: effect must be allow or deny, got %q", name, rule.Effect))
+		}
+		if rule.Access != "" && rule.Access != Read && rule.Access != Write {
+			errs = append(errs, fmt.Errorf("rule %s: access must be read or write, got %q", name, rule.Access))
+		}
+		for _, rpc := range rule.RPCs {
+			if rpc != wildcard && !rpcs[rpc] {
+				errs = append(errs, fmt.Errorf("rule %s: unknown rpc %q", name, rpc))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// UsesKinds reports whether a rule restricts kinds, the kinds of a request only need to be resolved if so
+func (p *Policy) UsesKinds() bool {
+	for _, rule := range p.Rules {
+		if len(rule.Kinds) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize decides whether the principal may make the request and returns the name of the deciding rule,
+// which is empty when the default effect applied
+func (p *Policy) Authorize(principal *Principal, request *Request) (bool, string) {
+	allowedBy := ""
+	allowed := false
+	for i, rule := range p.Rules {
+		if !rule.matches(principal, request) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if rule.Effect == Deny {
+			return false, name
+		}
+		if !allowed {
+			allowed, allowedBy = true, name
+		}
+	}
+	if allowed {
+		return true, allowedBy
+	}
+	return p.DefaultEffect == Allow, ""
+}
+
+func (r *Rule) matches(principal *Principal, request *Request) bool {
+	if len(r.Roles) > 0 && !slices.Contains(r.Roles, wildcard) &&
+		!slices.ContainsFunc(principal.Roles, func(role string) bool { return slices.Contains(r.Roles, role) }) {
+		return false
+	}
+	if len(r.Subjects) > 0 && !contains(r.Subjects, principal.Subject) {
+		return false
+	}
+	if len(r.RPCs) > 0 && !contains(r.RPCs, request.RPC) {
+		return false
+	}
+	if r.Access != "" && r.Access != request.Access {
+		return false
+	}
+	if len(r.Kinds) > 0 {
+		if request.UnknownKinds && !slices.Contains(r.Kinds, wildcard) {
+			if r.Effect != Deny {
+				return false
+			}
+		} else if !r.matchesValues(r.Kinds, request.Kinds, true) {
+			return false
+		}
+	}
+	if len(r.MetadataKeys) > 0 && !r.matchesValues(r.MetadataKeys, request.MetadataKeys, false) {
+		return false
+	}
+	return true
+}
+
+// matchesValues matches the kinds or metadata keys of a request against a list of the rule.
+// Allow rules need every value listed, deny rules any of them. An empty request list only matches allow rules
+// listing "*" when required is set.
+func (r *Rule) matchesValues(listed []string, values []string, required bool) bool {
+	if slices.Contains(listed, wildcard) {
+		return true
+	}
+	if r.Effect == Deny {
+		return slices.ContainsFunc(values, func(value string) bool { return slices.Contains(listed, value) })
+	}
+	if len(values) == 0 {
+		return !required
+	}
+	for _, value := range values {
+		if !slices.Contains(listed, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains reports whether a list of the rule holds value or the wildcard
+func contains(list []string, value string) bool {
+	return slices.Contains(list, wildcard) || slices.Contains(list, value)
+}
+
+// AccessOf returns the access needed by a CrudService method, methods that only read start with Read, List,
+// Describe, Download or Search
+func AccessOf(rpc string) Access {
+	for _, prefix := range []string{"Read", "List", "Describe", "Download", "Search"} {
+		if strings.HasPrefix(rpc, prefix) {
+			return Read
+		}
+	}
+	return Write
+}
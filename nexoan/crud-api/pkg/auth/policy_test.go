@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const testPolicy = `
+default_effect: deny
+rules:
+  - name: public-read
+    effect: allow
+    subjects: [anonymous]
+    access: read
+  - name: hide-contacts
+    effect: deny
+    subjects: [anonymous]
+    metadata_keys: [email]
+  - name: hide-secrets
+    effect: deny
+    subjects: [anonymous]
+    kinds: [Secret]
+  - name: person-editors
+    effect: allow
+    roles: [editor]
+    kinds: [Person]
+  - name: no-deletes
+    effect: deny
+    roles: ["*"]
+    rpcs: [DeleteEntity]
+`
+
+func writePolicy(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestPolicy(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	assert.NoError(t, err)
+	assert.True(t, policy.UsesKinds())
+
+	anonymous := &Principal{Subject: AnonymousSubject}
+	editor := &Principal{Subject: "ingest-job", Roles: []string{"editor"}}
+
+	for _, test := range []struct {
+		principal *Principal
+		request   Request
+		allowed   bool
+		rule      string
+	}{
+		{anonymous, Request{RPC: "ReadEntity", Access: Read, Kinds: []string{"Person"}}, true, "public-read"},
+		{anonymous, Request{RPC: "UpdateEntity", Access: Write, Kinds: []string{"Person"}}, false, ""},
+		{anonymous, Request{RPC: "DeleteEntity", Access: Write}, false, "no-deletes"},
+		{anonymous, Request{RPC: "ReadEntity", Access: Read, MetadataKeys: []string{"email"}}, false, "hide-contacts"},
+		{editor, Request{RPC: "UpdateEntity", Access: Write, Kinds: []string{"Person"}, MetadataKeys: []string{"email"}}, true, "person-editors"},
+		{editor, Request{RPC: "CreateRelationship", Access: Write, Kinds: []string{"Person", "Organisation"}}, false, ""},
+		// No kinds do not match a kind restricted allow rule
+		{editor, Request{RPC: "ListKinds", Access: Read}, false, ""},
+		{anonymous, Request{RPC: "ListKinds", Access: Read}, true, "public-read"},
+		// Unknown kinds match kind restricted deny rules but no kind restricted allow rule
+		{anonymous, Request{RPC: "ReadEntity", Access: Read, Kinds: []string{"Person"}, UnknownKinds: true}, false, "hide-secrets"},
+		{editor, Request{RPC: "UpdateEntity", Access: Write, Kinds: []string{"Person"}, UnknownKinds: true}, false, ""},
+		// Deny rules win over allow rules
+		{editor, Request{RPC: "DeleteEntity", Access: Write, Kinds: []string{"Person"}}, false, "no-deletes"},
+	} {
+		allowed, rule := policy.Authorize(test.principal, &test.request)
+		assert.Equal(t, test.allowed, allowed, "%s %+v", test.principal.Subject, test.request)
+		assert.Equal(t, test.rule, rule, "%s %+v", test.principal.Subject, test.request)
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	_, err := LoadPolicy(writePolicy(t, `
+default_effect: maybe
+rules:
+  - name: typo
+    effect: permit
+    access: delete
+    rpcs: [RemoveEntity]
+`))
+	assert.Error(t, err)
+	for _, problem := range []string{"default_effect", "effect must be", "access must be", `unknown rpc "RemoveEntity"`} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	_, err = LoadPolicy(writePolicy(t, "rules:\n  - name: a\n    effect: allow\n    kind: [Person]\n"))
+	assert.ErrorContains(t, err, "kind")
+}
+
+func TestAccessOf(t *testing.T) {
+	assert.Equal(t, Read, AccessOf("ReadEntities"))
+	assert.Equal(t, Read, AccessOf("ListKinds"))
+	assert.Equal(t, Read, AccessOf("DescribeKind"))
+	assert.Equal(t, Read, AccessOf("DescribeAttribute"))
+	assert.Equal(t, Read, AccessOf("DownloadBlob"))
+	assert.Equal(t, Read, AccessOf("Search"))
+	assert.Equal(t, Read, AccessOf("ReadMetadataHistory"))
+	assert.Equal(t, Write, AccessOf("UploadBlob"))
+	assert.Equal(t, Write, AccessOf("TerminateRelationship"))
+	assert.Equal(t, Write, AccessOf("CreateAttributeIndex"))
+}
+
+// fakeResolver resolves entity kinds from a map, the kind "unavailable" fails the lookup
+type fakeResolver map[string]string
+
+func (r fakeResolver) EntityKind(ctx context.Context, entityID string) (*pb.Kind, error) {
+	kind, ok := r[entityID]
+	if !ok {
+		return nil, fmt.Errorf("entity %s: %w", entityID, commons.ErrNotFound)
+	}
+	if kind == "unavailable" {
+		return nil, errors.New("connection refused")
+	}
+	return &pb.Kind{Major: kind}, nil
+}
+
+func (r fakeResolver) Relationship(ctx context.Context, relationshipID string) (*pb.EntityRelationship, error) {
+	return &pb.EntityRelationship{EntityId: "person-1", Relationship: &pb.Relationship{Id: relationshipID, RelatedEntityId: "org-1"}}, nil
+}
+
+// staticAuthenticator authenticates every caller sending the x-test-subject metadata
+type staticAuthenticator map[string]*Principal
+
+func (a staticAuthenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-test-subject"); len(values) > 0 {
+		return a[values[0]], nil
+	}
+	return nil, ErrNoCredentials
+}
+
+func TestGuardUnaryServerInterceptor(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	assert.NoError(t, err)
+	authenticator := staticAuthenticator{"ingest-job": {Subject: "ingest-job", Method: "test", Roles: []string{"editor"}}}
+	resolver := fakeResolver{"person-1": "Person", "org-1": "Organisation", "secret-1": "Secret", "broken-1": "unavailable"}
+	interceptor := NewGuard([]Authenticator{authenticator}, true, policy, resolver).UnaryServerInterceptor()
+
+	call := func(subject string, method string, req interface{}, resp interface{}) (interface{}, error) {
+		ctx := context.Background()
+		if subject != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-test-subject", subject))
+		}
+		info := &grpc.UnaryServerInfo{FullMethod: method}
+		return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if method != "/grpc.health.v1.Health/Check" {
+				assert.NotNil(t, PrincipalFromContext(ctx))
+			}
+			return resp, nil
+		})
+	}
+
+	// Anonymous callers can read but the email metadata is removed from the response
+	email, err := anypb.New(&pb.Kind{Major: "redacted"})
+	assert.NoError(t, err)
+	entity := &pb.Entity{Id: "person-1", Kind: &pb.Kind{Major: "Person"}, Metadata: map[string]*anypb.Any{"email": email, "source": email}}
+	resp, err := call("", pb.CrudService_ReadEntity_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "person-1"}}, entity)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"source"}, keys(resp.(*pb.Entity).Metadata))
+
+	// Search hits found only in metadata the caller may not read are removed
+	results := &pb.SearchResponse{Hits: []*pb.SearchHit{
+		{Entity: &pb.Entity{Id: "person-1", Kind: &pb.Kind{Major: "Person"}}, Matches: []*pb.SearchMatch{{Field: "metadata.email", Text: "jane@example.com"}}},
+		{Entity: &pb.Entity{Id: "person-2", Kind: &pb.Kind{Major: "Person"}}, Matches: []*pb.SearchMatch{
+			{Field: "name", Text: "Jane"},
+			{Field: "metadata.email", Text: "jane@example.org"},
+		}},
+	}}
+	resp, err = call("", pb.CrudService_Search_FullMethodName, &pb.SearchRequest{Query: "jane"}, results)
+	assert.NoError(t, err)
+	hits := resp.(*pb.SearchResponse).Hits
+	assert.Len(t, hits, 1)
+	assert.Equal(t, "person-2", hits[0].Entity.Id)
+	assert.Equal(t, []*pb.SearchMatch{{Field: "name", Text: "Jane"}}, hits[0].Matches)
+
+	// The history of metadata the caller may not read is removed, and cannot be asked for by key
+	history := &pb.MetadataHistory{EntityId: "person-1", Versions: []*pb.MetadataVersion{
+		{Key: "email", Value: email, ValidTo: "2024-01-01T00:00:00Z"},
+		{Key: "email", Value: email, ValidFrom: "2024-01-01T00:00:00Z"},
+		{Key: "source", Value: email},
+	}}
+	resp, err = call("", pb.CrudService_ReadMetadataHistory_FullMethodName, &pb.MetadataHistoryRequest{EntityId: "person-1"}, history)
+	assert.NoError(t, err)
+	versions := resp.(*pb.MetadataHistory).Versions
+	assert.Len(t, versions, 1)
+	assert.Equal(t, "source", versions[0].Key)
+	_, err = call("", pb.CrudService_ReadMetadataHistory_FullMethodName, &pb.MetadataHistoryRequest{EntityId: "person-1", Key: "email"}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Public consumers cannot update or delete
+	_, err = call("", pb.CrudService_UpdateEntity_FullMethodName, &pb.UpdateEntityRequest{Id: "person-1", Entity: &pb.Entity{}}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = call("", pb.CrudService_DeleteEntity_FullMethodName, &pb.EntityId{Id: "person-1"}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Editors can update persons, the stored kind applies rather than the one sent
+	_, err = call("ingest-job", pb.CrudService_UpdateEntity_FullMethodName, &pb.UpdateEntityRequest{Id: "person-1", Entity: &pb.Entity{}}, &pb.Entity{})
+	assert.NoError(t, err)
+	_, err = call("ingest-job", pb.CrudService_UpdateEntity_FullMethodName, &pb.UpdateEntityRequest{Id: "org-1", Entity: &pb.Entity{Kind: &pb.Kind{Major: "Person"}}}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Entities of denied kinds, or whose kind cannot be found, cannot be read
+	_, err = call("", pb.CrudService_ReadEntity_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "secret-1"}}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = call("", pb.CrudService_ReadEntity_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "missing-1"}}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The guard fails closed when the kinds cannot be resolved
+	_, err = call("", pb.CrudService_ReadEntity_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{Id: "broken-1"}}, nil)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	// Entities of denied kinds are removed from lists and search results, a single one fails the RPC
+	list := &pb.EntityList{Entities: []*pb.Entity{
+		{Id: "person-1", Kind: &pb.Kind{Major: "Person"}},
+		{Id: "secret-1", Kind: &pb.Kind{Major: "Secret"}},
+	}}
+	resp, err = call("", pb.CrudService_ReadEntities_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{}}, list)
+	assert.NoError(t, err)
+	assert.Len(t, resp.(*pb.EntityList).Entities, 1)
+	assert.Equal(t, "person-1", resp.(*pb.EntityList).Entities[0].Id)
+	results = &pb.SearchResponse{Hits: []*pb.SearchHit{
+		{Entity: &pb.Entity{Id: "secret-1", Kind: &pb.Kind{Major: "Secret"}}, Matches: []*pb.SearchMatch{{Field: "name", Text: "Jane"}}},
+		{Entity: &pb.Entity{Id: "person-1", Kind: &pb.Kind{Major: "Person"}}, Matches: []*pb.SearchMatch{{Field: "name", Text: "Jane"}}},
+	}}
+	resp, err = call("", pb.CrudService_Search_FullMethodName, &pb.SearchRequest{Query: "jane"}, results)
+	assert.NoError(t, err)
+	assert.Len(t, resp.(*pb.SearchResponse).Hits, 1)
+	assert.Equal(t, "person-1", resp.(*pb.SearchResponse).Hits[0].Entity.Id)
+	_, err = call("", pb.CrudService_ReadEntity_FullMethodName, &pb.ReadEntityRequest{Entity: &pb.Entity{Kind: &pb.Kind{Major: "Person"}}},
+		&pb.Entity{Id: "secret-2", Kind: &pb.Kind{Major: "Secret"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// A relationship touches the kinds of both its endpoints
+	_, err = call("ingest-job", pb.CrudService_DeleteRelationship_FullMethodName, &pb.RelationshipId{Id: "rel-1"}, nil)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Other services are not guarded
+	_, err = call("", "/grpc.health.v1.Health/Check", nil, nil)
+	assert.NoError(t, err)
+}
+
+// chunkStream is a server stream receiving the given blob chunks
+type chunkStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	chunks []*pb.BlobChunk
+}
+
+func (s *chunkStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *chunkStream) RecvMsg(m interface{}) error {
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	m.(*pb.BlobChunk).Info = chunk.Info
+	m.(*pb.BlobChunk).Data = chunk.Data
+	return nil
+}
+
+func TestGuardStreamServerInterceptor(t *testing.T) {
+	policy, err := LoadPolicy(writePolicy(t, testPolicy))
+	assert.NoError(t, err)
+	authenticator := staticAuthenticator{"ingest-job": {Subject: "ingest-job", Method: "test", Roles: []string{"editor"}}}
+	resolver := fakeResolver{"person-1": "Person", "org-1": "Organisation"}
+	interceptor := NewGuard([]Authenticator{authenticator}, true, policy, resolver).StreamServerInterceptor()
+
+	upload := func(entityID string) error {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-test-subject", "ingest-job"))
+		stream := &chunkStream{ctx: ctx, chunks: []*pb.BlobChunk{
+			{Info: &pb.BlobInfo{EntityId: entityID, Name: "photo"}, Data: []byte("first")},
+			{Data: []byte("second")},
+		}}
+		info := &grpc.StreamServerInfo{FullMethod: pb.CrudService_UploadBlob_FullMethodName}
+		return interceptor(nil, stream, info, func(srv interface{}, stream grpc.ServerStream) error {
+			for range 2 {
+				if err := stream.RecvMsg(&pb.BlobChunk{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	// The chunks following the first one belong to the entity it names
+	assert.NoError(t, upload("person-1"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(upload("org-1")))
+}
+
+func TestGuardRequiresCredentials(t *testing.T) {
+	interceptor := NewGuard([]Authenticator{staticAuthenticator{}}, false, nil, nil).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: pb.CrudService_ReadEntity_FullMethodName}
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func keys(m map[string]*anypb.Any) []string {
+	var result []string
+	for key := range m {
+		result = append(result, key)
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+// Package cypher validates and quotes the kind and relationship names that Cypher statements use as labels and
+// relationship types. Labels and types cannot be passed as query parameters, so they are written into the statement.
+package cypher
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength is the longest kind or relationship name in bytes
+const MaxIdentifierLength = 255
+
+// InvalidIdentifierError is returned when a kind or relationship name cannot be used as a label or relationship type
+type InvalidIdentifierError struct {
+	Name   string
+	Reason string
+}
+
+func (e *InvalidIdentifierError) Error() string {
+	return fmt.Sprintf("invalid name %q: %s", e.Name, e.Reason)
+}
+
+// ValidateIdentifier checks that a kind or relationship name can be used as a label or relationship type.
+// Names are kept as given, so letters, digits, spaces and punctuation are allowed. Control characters are
+// rejected along with backticks and backslashes, the characters that could end a quoted name or start an escape.
+func ValidateIdentifier(name string) error {
+	switch {
+	case name == "":
+		return &InvalidIdentifierError{Name: name, Reason: "the name is empty"}
+	case len(name) > MaxIdentifierLength:
+		return &InvalidIdentifierError{Name: name, Reason: fmt.Sprintf("the name is longer than %d bytes", MaxIdentifierLength)}
+	case !utf8.ValidString(name):
+		return &InvalidIdentifierError{Name: name, Reason: "the name is not valid UTF-8"}
+	case strings.TrimSpace(name) != name:
+		return &InvalidIdentifierError{Name: name, Reason: "the name starts or ends with a space"}
+	}
+	for _, r := range name {
+		if r == '`' || r == '\\' || unicode.IsControl(r) {
+			return &InvalidIdentifierError{Name: name, Reason: fmt.Sprintf("the name contains %q", r)}
+		}
+	}
+	return nil
+}
+
+// QuoteIdentifier validates a kind or relationship name and quotes it for use as a label or relationship type
+func QuoteIdentifier(name string) (string, error) {
+	if err := ValidateIdentifier(name); err != nil {
+		return "", err
+	}
+	return Quote(name), nil
+}
+
+// Quote quotes a name for use in a Cypher statement by doubling its backticks. Names read back from the
+// database are quoted without being validated since they may predate ValidateIdentifier.
+func Quote(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
@@ -0,0 +1,89 @@
+package cypher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateIdentifier(t *testing.T) {
+	for _, name := range []string{"Organisation", "Budget-2020", "budget_2020", "Sri Lanka", "දෙපාර්තමේන්තුව", "HAS.CHILD"} {
+		assert.NoError(t, ValidateIdentifier(name), name)
+	}
+	for _, name := range []string{
+		"",
+		strings.Repeat("a", MaxIdentifierLength+1),
+		"\xff",
+		" Organisation",
+		"Organisation ",
+		"Person`) DETACH DELETE n //",
+		"Person\\u0060",
+		"Person\n",
+		"Per\x00son",
+	} {
+		var invalid *InvalidIdentifierError
+		assert.True(t, errors.As(ValidateIdentifier(name), &invalid), "%q", name)
+	}
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	quoted, err := QuoteIdentifier("Budget-2020")
+	assert.NoError(t, err)
+	assert.Equal(t, "`Budget-2020`", quoted)
+
+	_, err = QuoteIdentifier("Person`:Admin")
+	assert.Error(t, err)
+
+	assert.Equal(t, "`Person``:Admin`", Quote("Person`:Admin"))
+}
+
+// closingBacktick returns the index of the backtick that ends a quoted name, or -1 if the name is never closed
+func closingBacktick(quoted string) int {
+	for i := 1; i < len(quoted); i++ {
+		if quoted[i] != '`' {
+			continue
+		}
+		if i+1 < len(quoted) && quoted[i+1] == '`' {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func FuzzQuoteIdentifier(f *testing.F) {
+	for _, seed := range []string{"Organisation", "Person`) DETACH DELETE n //", "a``b", "`", "\\`", " x", "\n"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, name string) {
+		// A quoted name always ends at its last character, so nothing after it is read as Cypher
+		quoted := Quote(name)
+		if closingBacktick(quoted) != len(quoted)-1 {
+			t.Fatalf("quoted name %q ends before its last character", quoted)
+		}
+		if unquoted := strings.ReplaceAll(quoted[1:len(quoted)-1], "``", "`"); unquoted != name {
+			t.Fatalf("quoted name %q reads back as %q", quoted, unquoted)
+		}
+
+		quoted, err := QuoteIdentifier(name)
+		if err != nil {
+			var invalid *InvalidIdentifierError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			return
+		}
+		if quoted != "`"+name+"`" {
+			t.Fatalf("valid name %q was quoted as %q", name, quoted)
+		}
+		for _, r := range name {
+			if r == '`' || r == '\\' || unicode.IsControl(r) {
+				t.Fatalf("valid name %q contains %q", name, r)
+			}
+		}
+	})
+}
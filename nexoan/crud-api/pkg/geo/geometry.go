@@ -0,0 +1,324 @@
+// Package geo parses GeoJSON geometries and evaluates spatial filters without a spatial database.
+// Positions are WGS 84 longitudes and latitudes as in GeoJSON, and the computations are planar
+// like those of PostGIS on geometry columns, so both give the same answers.
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// GeoJSON geometry types
+const (
+	PointType              = "Point"
+	MultiPointType         = "MultiPoint"
+	LineStringType         = "LineString"
+	MultiLineStringType    = "MultiLineString"
+	PolygonType            = "Polygon"
+	MultiPolygonType       = "MultiPolygon"
+	GeometryCollectionType = "GeometryCollection"
+
+	// FeatureType wraps a geometry with properties, only its geometry is kept
+	FeatureType = "Feature"
+)
+
+// Position is a longitude and latitude in degrees
+type Position struct {
+	Lon float64
+	Lat float64
+}
+
+// Geometry is a parsed GeoJSON geometry
+type Geometry struct {
+	Type string
+	// Points holds the positions of a Point or MultiPoint
+	Points []Position
+	// Lines holds the line strings of a LineString or MultiLineString
+	Lines [][]Position
+	// Polygons holds the rings of a Polygon or MultiPolygon, the first ring of a polygon is its exterior
+	// and the others are its holes
+	Polygons [][][]Position
+	// Geometries holds the members of a GeometryCollection
+	Geometries []*Geometry
+}
+
+// rawGeometry is a GeoJSON object before its coordinates are parsed
+type rawGeometry struct {
+	Type        string            `json:"type"`
+	Coordinates json.RawMessage   `json:"coordinates"`
+	Geometries  []json.RawMessage `json:"geometries"`
+	Geometry    json.RawMessage   `json:"geometry"`
+}
+
+// IsGeoJSON reports whether a struct is a GeoJSON geometry or feature. Only the type and the presence
+// of the coordinates are looked at, Parse validates the coordinates.
+//
+// Example:
+//
+//	{"type": "Point", "coordinates": [79.86, 6.93]}
+//	{"type": "Feature", "geometry": {"type": "Point", "coordinates": [79.86, 6.93]}, "properties": {}}
+func IsGeoJSON(value *structpb.Struct) bool {
+	geometryType := value.GetFields()["type"].GetStringValue()
+	switch geometryType {
+	case PointType, MultiPointType, LineStringType, MultiLineStringType, PolygonType, MultiPolygonType:
+		return value.Fields["coordinates"].GetListValue() != nil
+	case GeometryCollectionType:
+		return value.Fields["geometries"].GetListValue() != nil
+	case FeatureType:
+		return IsGeoJSON(value.Fields["geometry"].GetStructValue())
+	}
+	return false
+}
+
+// Parse parses a GeoJSON geometry or feature held in a struct
+func Parse(value *structpb.Struct) (*Geometry, error) {
+	data, err := protojson.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %v", err)
+	}
+	return ParseJSON(data)
+}
+
+// ParseJSON parses a GeoJSON geometry or feature
+func ParseJSON(data []byte) (*Geometry, error) {
+	var raw rawGeometry
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %v", err)
+	}
+
+	geometry := &Geometry{Type: raw.Type}
+	var err error
+	switch raw.Type {
+	case PointType:
+		var position Position
+		position, err = parsePosition(raw.Coordinates)
+		geometry.Points = []Position{position}
+	case MultiPointType:
+		geometry.Points, err = parsePositions(raw.Coordinates, 1)
+	case LineStringType:
+		var line []Position
+		line, err = parsePositions(raw.Coordinates, 2)
+		geometry.Lines = [][]Position{line}
+	case MultiLineStringType:
+		var lines []json.RawMessage
+		if err = unmarshalList(raw.Coordinates, &lines); err == nil {
+			geometry.Lines = make([][]Position, len(lines))
+			for i := 0; i < len(lines) && err == nil; i++ {
+				geometry.Lines[i], err = parsePositions(lines[i], 2)
+			}
+		}
+	case PolygonType:
+		var polygon [][]Position
+		polygon, err = parsePolygon(raw.Coordinates)
+		geometry.Polygons = [][][]Position{polygon}
+	case MultiPolygonType:
+		var polygons []json.RawMessage
+		if err = unmarshalList(raw.Coordinates, &polygons); err == nil {
+			geometry.Polygons = make([][][]Position, len(polygons))
+			for i := 0; i < len(polygons) && err == nil; i++ {
+				geometry.Polygons[i], err = parsePolygon(polygons[i])
+			}
+		}
+	case GeometryCollectionType:
+		geometry.Geometries = make([]*Geometry, len(raw.Geometries))
+		for i := 0; i < len(raw.Geometries) && err == nil; i++ {
+			geometry.Geometries[i], err = ParseJSON(raw.Geometries[i])
+		}
+	case FeatureType:
+		if len(raw.Geometry) == 0 || string(raw.Geometry) == "null" {
+			return nil, fmt.Errorf("invalid GeoJSON: feature has no geometry")
+		}
+		return ParseJSON(raw.Geometry)
+	default:
+		return nil, fmt.Errorf("invalid GeoJSON: unsupported type %q", raw.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON %s: %v", raw.Type, err)
+	}
+	if geometry.IsEmpty() {
+		return nil, fmt.Errorf("invalid GeoJSON %s: geometry has no positions", raw.Type)
+	}
+	return geometry, nil
+}
+
+// unmarshalList decodes a JSON array, a missing array is an error
+func unmarshalList(data json.RawMessage, list *[]json.RawMessage) error {
+	if len(data) == 0 || string(data) == "null" {
+		return fmt.Errorf("coordinates are missing")
+	}
+	if err := json.Unmarshal(data, list); err != nil {
+		return fmt.Errorf("coordinates must be an array: %v", err)
+	}
+	return nil
+}
+
+// parsePosition parses a [longitude, latitude] position, an altitude is ignored
+func parsePosition(data json.RawMessage) (Position, error) {
+	var coordinates []float64
+	if len(data) == 0 || string(data) == "null" {
+		return Position{}, fmt.Errorf("coordinates are missing")
+	}
+	if err := json.Unmarshal(data, &coordinates); err != nil {
+		return Position{}, fmt.Errorf("a position must be an array of numbers: %v", err)
+	}
+	if len(coordinates) < 2 || len(coordinates) > 3 {
+		return Position{}, fmt.Errorf("a position must have 2 or 3 numbers, got %d", len(coordinates))
+	}
+	position := Position{Lon: coordinates[0], Lat: coordinates[1]}
+	if err := position.Validate(); err != nil {
+		return Position{}, err
+	}
+	return position, nil
+}
+
+// parsePositions parses an array of at least minimum positions
+func parsePositions(data json.RawMessage, minimum int) ([]Position, error) {
+	var list []json.RawMessage
+	if err := unmarshalList(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) < minimum {
+		return nil, fmt.Errorf("expected at least %d positions, got %d", minimum, len(list))
+	}
+	positions := make([]Position, len(list))
+	for i, item := range list {
+		position, err := parsePosition(item)
+		if err != nil {
+			return nil, err
+		}
+		positions[i] = position
+	}
+	return positions, nil
+}
+
+// parsePolygon parses the rings of a polygon, each ring must be closed and have at least 4 positions
+func parsePolygon(data json.RawMessage) ([][]Position, error) {
+	var list []json.RawMessage
+	if err := unmarshalList(data, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("a polygon must have an exterior ring")
+	}
+	rings := make([][]Position, len(list))
+	for i, item := range list {
+		ring, err := parsePositions(item, 4)
+		if err != nil {
+			return nil, err
+		}
+		if ring[0] != ring[len(ring)-1] {
+			return nil, fmt.Errorf("ring %d is not closed, its first and last positions must be equal", i)
+		}
+		rings[i] = ring
+	}
+	return rings, nil
+}
+
+// Validate checks that a position lies within the longitude and latitude ranges
+func (p Position) Validate() error {
+	if p.Lon < -180 || p.Lon > 180 {
+		return fmt.Errorf("longitude %v is out of range [-180, 180]", p.Lon)
+	}
+	if p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude %v is out of range [-90, 90]", p.Lat)
+	}
+	return nil
+}
+
+// IsEmpty reports whether a geometry has no positions
+func (g *Geometry) IsEmpty() bool {
+	if len(g.Points) > 0 || len(g.Lines) > 0 || len(g.Polygons) > 0 {
+		return false
+	}
+	for _, member := range g.Geometries {
+		if !member.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// positions calls visit with every position of a geometry
+func (g *Geometry) positions(visit func(Position)) {
+	for _, point := range g.Points {
+		visit(point)
+	}
+	for _, line := range g.Lines {
+		for _, position := range line {
+			visit(position)
+		}
+	}
+	for _, polygon := range g.Polygons {
+		for _, ring := range polygon {
+			for _, position := range ring {
+				visit(position)
+			}
+		}
+	}
+	for _, member := range g.Geometries {
+		member.positions(visit)
+	}
+}
+
+// MarshalJSON encodes a geometry as GeoJSON
+func (g *Geometry) MarshalJSON() ([]byte, error) {
+	coordinates := func(positions []Position) [][]float64 {
+		result := make([][]float64, len(positions))
+		for i, position := range positions {
+			result[i] = []float64{position.Lon, position.Lat}
+		}
+		return result
+	}
+	rings := func(polygon [][]Position) [][][]float64 {
+		result := make([][][]float64, len(polygon))
+		for i, ring := range polygon {
+			result[i] = coordinates(ring)
+		}
+		return result
+	}
+
+	object := map[string]interface{}{"type": g.Type}
+	switch g.Type {
+	case PointType:
+		object["coordinates"] = coordinates(g.Points)[0]
+	case MultiPointType:
+		object["coordinates"] = coordinates(g.Points)
+	case LineStringType:
+		object["coordinates"] = coordinates(g.Lines[0])
+	case MultiLineStringType:
+		lines := make([][][]float64, len(g.Lines))
+		for i, line := range g.Lines {
+			lines[i] = coordinates(line)
+		}
+		object["coordinates"] = lines
+	case PolygonType:
+		object["coordinates"] = rings(g.Polygons[0])
+	case MultiPolygonType:
+		polygons := make([][][][]float64, len(g.Polygons))
+		for i, polygon := range g.Polygons {
+			polygons[i] = rings(polygon)
+		}
+		object["coordinates"] = polygons
+	case GeometryCollectionType:
+		object["geometries"] = g.Geometries
+	default:
+		return nil, fmt.Errorf("unsupported geometry type %q", g.Type)
+	}
+	return json.Marshal(object)
+}
+
+// Struct returns a geometry as a GeoJSON struct
+func (g *Geometry) Struct() (*structpb.Struct, error) {
+	data, err := json.Marshal(g)
+	if err != nil {
+		return nil, err
+	}
+	value := &structpb.Struct{}
+	if err := protojson.Unmarshal(data, value); err != nil {
+		return nil, fmt.Errorf("error converting GeoJSON: %v", err)
+	}
+	return value, nil
+}
@@ -0,0 +1,140 @@
+package geo
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// colombo is a square boundary with a square hole
+const colombo = `{
+	"type": "Polygon",
+	"coordinates": [
+		[[79.80, 6.85], [79.95, 6.85], [79.95, 7.00], [79.80, 7.00], [79.80, 6.85]],
+		[[79.85, 6.90], [79.90, 6.90], [79.90, 6.95], [79.85, 6.95], [79.85, 6.90]]
+	]
+}`
+
+func mustParse(t *testing.T, geoJSON string) *Geometry {
+	geometry, err := ParseJSON([]byte(geoJSON))
+	assert.NoError(t, err)
+	return geometry
+}
+
+func TestIsGeoJSON(t *testing.T) {
+	testCases := map[string]bool{
+		`{"type": "Point", "coordinates": [79.86, 6.93]}`:                           true,
+		`{"type": "GeometryCollection", "geometries": []}`:                          true,
+		`{"type": "Feature", "geometry": {"type": "Point", "coordinates": [0, 0]}}`: true,
+		`{"type": "Point", "name": "office"}`:                                       false,
+		`{"type": "Ministry", "coordinates": [0, 0]}`:                               false,
+		`{"type": "Feature", "geometry": null, "properties": {"name": "unknown"}}`:  false,
+		`{"columns": ["type", "coordinates"], "rows": [["Point", "0,0"]]}`:          false,
+	}
+	for geoJSON, expected := range testCases {
+		value := &structpb.Struct{}
+		assert.NoError(t, value.UnmarshalJSON([]byte(geoJSON)))
+		assert.Equal(t, expected, IsGeoJSON(value), geoJSON)
+	}
+}
+
+func TestParse(t *testing.T) {
+	value, err := structpb.NewStruct(map[string]interface{}{
+		"type":       "Feature",
+		"geometry":   map[string]interface{}{"type": "LineString", "coordinates": []interface{}{[]interface{}{79.8, 6.9, 12}, []interface{}{80.6, 7.3}}},
+		"properties": map[string]interface{}{"name": "A1"},
+	})
+	assert.NoError(t, err)
+	geometry, err := Parse(value)
+	assert.NoError(t, err)
+	assert.Equal(t, LineStringType, geometry.Type)
+	assert.Equal(t, [][]Position{{{79.8, 6.9}, {80.6, 7.3}}}, geometry.Lines)
+	assert.Equal(t, Box{MinLon: 79.8, MinLat: 6.9, MaxLon: 80.6, MaxLat: 7.3}, geometry.Bounds())
+
+	for name, geoJSON := range map[string]string{
+		"unknown type":        `{"type": "Circle", "coordinates": [0, 0]}`,
+		"missing coordinates": `{"type": "Point"}`,
+		"short position":      `{"type": "Point", "coordinates": [79.8]}`,
+		"latitude range":      `{"type": "Point", "coordinates": [79.8, 96.9]}`,
+		"single position":     `{"type": "LineString", "coordinates": [[0, 0]]}`,
+		"open ring":           `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [1, 1], [0, 1]]]}`,
+		"short ring":          `{"type": "Polygon", "coordinates": [[[0, 0], [1, 0], [0, 0]]]}`,
+		"empty collection":    `{"type": "GeometryCollection", "geometries": []}`,
+		"feature without":     `{"type": "Feature", "geometry": null}`,
+		"text coordinates":    `{"type": "MultiPoint", "coordinates": "0,0"}`,
+	} {
+		_, err := ParseJSON([]byte(geoJSON))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	for _, geoJSON := range []string{
+		colombo,
+		`{"type": "MultiPoint", "coordinates": [[79.86, 6.93], [80.63, 7.29]]}`,
+		`{"type": "MultiPolygon", "coordinates": [[[[0, 0], [1, 0], [1, 1], [0, 0]]]]}`,
+		`{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [1, 2]}]}`,
+	} {
+		geometry := mustParse(t, geoJSON)
+		data, err := json.Marshal(geometry)
+		assert.NoError(t, err)
+		assert.JSONEq(t, geoJSON, string(data))
+
+		value, err := geometry.Struct()
+		assert.NoError(t, err)
+		assert.True(t, IsGeoJSON(value))
+	}
+}
+
+func TestContainsPoint(t *testing.T) {
+	boundary := mustParse(t, colombo)
+	assert.True(t, boundary.ContainsPoint(Position{Lon: 79.82, Lat: 6.88}))
+	assert.True(t, boundary.ContainsPoint(Position{Lon: 79.80, Lat: 6.90}), "Expected the boundary to be included")
+	assert.False(t, boundary.ContainsPoint(Position{Lon: 79.87, Lat: 6.92}), "Expected the hole to be excluded")
+	assert.True(t, boundary.ContainsPoint(Position{Lon: 79.85, Lat: 6.92}), "Expected the boundary of the hole to be included")
+	assert.False(t, boundary.ContainsPoint(Position{Lon: 80.00, Lat: 6.90}))
+
+	office := mustParse(t, `{"type": "Point", "coordinates": [79.86, 6.93]}`)
+	assert.True(t, office.ContainsPoint(Position{Lon: 79.86, Lat: 6.93}))
+	assert.False(t, office.ContainsPoint(Position{Lon: 79.86, Lat: 6.94}))
+
+	road := mustParse(t, `{"type": "LineString", "coordinates": [[0, 0], [2, 2]]}`)
+	assert.True(t, road.ContainsPoint(Position{Lon: 1, Lat: 1}))
+	assert.False(t, road.ContainsPoint(Position{Lon: 1, Lat: 0}))
+}
+
+func TestIntersectsBox(t *testing.T) {
+	boundary := mustParse(t, colombo)
+	assert.True(t, boundary.IntersectsBox(Box{MinLon: 79.9, MinLat: 6.9, MaxLon: 80.1, MaxLat: 7.1}), "Expected a crossing box to intersect")
+	assert.True(t, boundary.IntersectsBox(Box{MinLon: 79.81, MinLat: 6.86, MaxLon: 79.82, MaxLat: 6.87}), "Expected a box inside to intersect")
+	assert.True(t, boundary.IntersectsBox(Box{MinLon: 79, MinLat: 6, MaxLon: 81, MaxLat: 8}), "Expected a box around to intersect")
+	assert.False(t, boundary.IntersectsBox(Box{MinLon: 79.86, MinLat: 6.91, MaxLon: 79.89, MaxLat: 6.94}), "Expected a box inside the hole not to intersect")
+	assert.False(t, boundary.IntersectsBox(Box{MinLon: 81, MinLat: 6, MaxLon: 82, MaxLat: 7}))
+
+	// A line crossing the box without a position in it
+	road := mustParse(t, `{"type": "LineString", "coordinates": [[0, 0], [4, 4]]}`)
+	assert.True(t, road.IntersectsBox(Box{MinLon: 1, MinLat: 1, MaxLon: 2, MaxLat: 3}))
+	assert.False(t, road.IntersectsBox(Box{MinLon: 3, MinLat: 0, MaxLon: 4, MaxLat: 1}))
+}
+
+func TestFilter(t *testing.T) {
+	boundary := mustParse(t, colombo)
+	point := &Position{Lon: 79.82, Lat: 6.88}
+	box := &Box{MinLon: 79.9, MinLat: 6.9, MaxLon: 80.1, MaxLat: 7.1}
+
+	assert.Error(t, Filter{}.Validate())
+	assert.Error(t, Filter{IntersectsBox: &Box{MinLon: 1, MaxLon: 0}}.Validate())
+	assert.Error(t, Filter{ContainsPoint: &Position{Lon: 200}}.Validate())
+	assert.NoError(t, Filter{ContainsPoint: point, IntersectsBox: box}.Validate())
+
+	assert.True(t, Filter{ContainsPoint: point}.Matches(boundary))
+	assert.True(t, Filter{IntersectsBox: box}.Matches(boundary))
+	assert.True(t, Filter{ContainsPoint: point, IntersectsBox: box}.Matches(boundary))
+	hole := &Position{Lon: 79.87, Lat: 6.92}
+	assert.False(t, Filter{ContainsPoint: hole, IntersectsBox: box}.Matches(boundary), "Expected both conditions to apply")
+
+	assert.Equal(t, Box{MinLon: 79.82, MinLat: 6.88, MaxLon: 79.82, MaxLat: 6.88}, Filter{ContainsPoint: point}.Bounds())
+	assert.Equal(t, *box, Filter{IntersectsBox: box}.Bounds())
+}
@@ -0,0 +1,246 @@
+package geo
+
+import (
+	"fmt"
+	"math"
+)
+
+// Box is a bounding box in degrees, its edges belong to it
+type Box struct {
+	MinLon float64
+	MinLat float64
+	MaxLon float64
+	MaxLat float64
+}
+
+// Validate checks that the corners of a box are in range and ordered
+func (b Box) Validate() error {
+	if err := (Position{Lon: b.MinLon, Lat: b.MinLat}).Validate(); err != nil {
+		return err
+	}
+	if err := (Position{Lon: b.MaxLon, Lat: b.MaxLat}).Validate(); err != nil {
+		return err
+	}
+	if b.MinLon > b.MaxLon || b.MinLat > b.MaxLat {
+		return fmt.Errorf("the minimum corner of a box must not exceed its maximum corner")
+	}
+	return nil
+}
+
+// Contains reports whether a position lies in the box
+func (b Box) Contains(p Position) bool {
+	return p.Lon >= b.MinLon && p.Lon <= b.MaxLon && p.Lat >= b.MinLat && p.Lat <= b.MaxLat
+}
+
+// Intersects reports whether two boxes share at least one position
+func (b Box) Intersects(other Box) bool {
+	return b.MinLon <= other.MaxLon && b.MaxLon >= other.MinLon && b.MinLat <= other.MaxLat && b.MaxLat >= other.MinLat
+}
+
+// Bounds returns the smallest box holding every position of a geometry
+func (g *Geometry) Bounds() Box {
+	box := Box{MinLon: math.Inf(1), MinLat: math.Inf(1), MaxLon: math.Inf(-1), MaxLat: math.Inf(-1)}
+	g.positions(func(p Position) {
+		box.MinLon = math.Min(box.MinLon, p.Lon)
+		box.MinLat = math.Min(box.MinLat, p.Lat)
+		box.MaxLon = math.Max(box.MaxLon, p.Lon)
+		box.MaxLat = math.Max(box.MaxLat, p.Lat)
+	})
+	return box
+}
+
+// ContainsPoint reports whether a position lies in a geometry, positions on its boundary included.
+// It matches ST_Covers of PostGIS.
+func (g *Geometry) ContainsPoint(p Position) bool {
+	for _, point := range g.Points {
+		if point == p {
+			return true
+		}
+	}
+	for _, line := range g.Lines {
+		for i := 1; i < len(line); i++ {
+			if onSegment(p, line[i-1], line[i]) {
+				return true
+			}
+		}
+	}
+	for _, polygon := range g.Polygons {
+		if polygonCovers(polygon, p) {
+			return true
+		}
+	}
+	for _, member := range g.Geometries {
+		if member.ContainsPoint(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectsBox reports whether a geometry shares at least one position with a box.
+// It matches ST_Intersects of PostGIS with the box as an envelope.
+func (g *Geometry) IntersectsBox(b Box) bool {
+	if !g.Bounds().Intersects(b) {
+		return false
+	}
+	for _, point := range g.Points {
+		if b.Contains(point) {
+			return true
+		}
+	}
+	for _, line := range g.Lines {
+		for i := 1; i < len(line); i++ {
+			if segmentIntersectsBox(line[i-1], line[i], b) {
+				return true
+			}
+		}
+	}
+	corners := []Position{{b.MinLon, b.MinLat}, {b.MaxLon, b.MinLat}, {b.MaxLon, b.MaxLat}, {b.MinLon, b.MaxLat}}
+	for _, polygon := range g.Polygons {
+		for _, ring := range polygon {
+			for i := 1; i < len(ring); i++ {
+				if segmentIntersectsBox(ring[i-1], ring[i], b) {
+					return true
+				}
+			}
+		}
+		// A box crossing no ring lies either wholly inside or wholly outside the polygon
+		if polygonCovers(polygon, corners[0]) {
+			return true
+		}
+	}
+	for _, member := range g.Geometries {
+		if member.IntersectsBox(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// polygonCovers reports whether a position lies in the exterior ring of a polygon and not strictly inside a hole
+func polygonCovers(polygon [][]Position, p Position) bool {
+	if !ringCovers(polygon[0], p) {
+		return false
+	}
+	for _, hole := range polygon[1:] {
+		if ringCovers(hole, p) && !onRing(hole, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringCovers reports whether a position lies inside a closed ring or on its boundary, using ray casting
+func ringCovers(ring []Position, p Position) bool {
+	if onRing(ring, p) {
+		return true
+	}
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) && p.Lon < (b.Lon-a.Lon)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// onRing reports whether a position lies on one of the segments of a ring
+func onRing(ring []Position, p Position) bool {
+	for i := 1; i < len(ring); i++ {
+		if onSegment(p, ring[i-1], ring[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// orientation returns the sign of the cross product of ab and ac: positive when c lies to the left of ab,
+// negative when it lies to the right and zero when the three positions are collinear
+func orientation(a, b, c Position) float64 {
+	return (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+}
+
+// onSegment reports whether p lies on the segment ab
+func onSegment(p, a, b Position) bool {
+	return orientation(a, b, p) == 0 &&
+		p.Lon >= math.Min(a.Lon, b.Lon) && p.Lon <= math.Max(a.Lon, b.Lon) &&
+		p.Lat >= math.Min(a.Lat, b.Lat) && p.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+// segmentsIntersect reports whether the segments ab and cd share at least one position
+func segmentsIntersect(a, b, c, d Position) bool {
+	o1, o2 := orientation(a, b, c), orientation(a, b, d)
+	o3, o4 := orientation(c, d, a), orientation(c, d, b)
+	if ((o1 > 0 && o2 < 0) || (o1 < 0 && o2 > 0)) && ((o3 > 0 && o4 < 0) || (o3 < 0 && o4 > 0)) {
+		return true
+	}
+	return onSegment(c, a, b) || onSegment(d, a, b) || onSegment(a, c, d) || onSegment(b, c, d)
+}
+
+// segmentIntersectsBox reports whether the segment ab shares at least one position with a box
+func segmentIntersectsBox(a, b Position, box Box) bool {
+	if box.Contains(a) || box.Contains(b) {
+		return true
+	}
+	corners := []Position{{box.MinLon, box.MinLat}, {box.MaxLon, box.MinLat}, {box.MaxLon, box.MaxLat}, {box.MinLon, box.MaxLat}}
+	for i := range corners {
+		if segmentsIntersect(a, b, corners[i], corners[(i+1)%len(corners)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter selects the geometries containing a position, intersecting a box or both
+type Filter struct {
+	// ContainsPoint selects the geometries containing the position, boundary included
+	ContainsPoint *Position
+	// IntersectsBox selects the geometries sharing at least one position with the box
+	IntersectsBox *Box
+}
+
+// Validate checks that a filter has at least one valid condition
+func (f Filter) Validate() error {
+	if f.ContainsPoint == nil && f.IntersectsBox == nil {
+		return fmt.Errorf("a spatial filter needs a point or a bounding box")
+	}
+	if f.ContainsPoint != nil {
+		if err := f.ContainsPoint.Validate(); err != nil {
+			return fmt.Errorf("invalid point: %v", err)
+		}
+	}
+	if f.IntersectsBox != nil {
+		if err := f.IntersectsBox.Validate(); err != nil {
+			return fmt.Errorf("invalid bounding box: %v", err)
+		}
+	}
+	return nil
+}
+
+// Bounds returns the box the bounds of every matching geometry intersect, it lets stores
+// look up candidates in a bounding box index before calling Matches
+func (f Filter) Bounds() Box {
+	box := Box{MinLon: -180, MinLat: -90, MaxLon: 180, MaxLat: 90}
+	if f.IntersectsBox != nil {
+		box = *f.IntersectsBox
+	}
+	if p := f.ContainsPoint; p != nil {
+		box = Box{
+			MinLon: math.Max(box.MinLon, p.Lon), MinLat: math.Max(box.MinLat, p.Lat),
+			MaxLon: math.Min(box.MaxLon, p.Lon), MaxLat: math.Min(box.MaxLat, p.Lat),
+		}
+	}
+	return box
+}
+
+// Matches reports whether a geometry meets every condition of the filter
+func (f Filter) Matches(g *Geometry) bool {
+	if f.ContainsPoint != nil && !g.ContainsPoint(*f.ContainsPoint) {
+		return false
+	}
+	if f.IntersectsBox != nil && !g.IntersectsBox(*f.IntersectsBox) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package kindschema
+
+import (
+	"fmt"
+
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// InferMetadataType determines the data type of a metadata value.
+// Metadata values are sent either as protobuf wrapper types or as structpb values,
+// both are normalised to a structpb value before the type is inferred.
+func InferMetadataType(value *anypb.Any) (typeinference.DataType, error) {
+	if value == nil {
+		return typeinference.NullType, nil
+	}
+
+	message, err := value.UnmarshalNew()
+	if err != nil {
+		return "", fmt.Errorf("failed to unpack value: %v", err)
+	}
+
+	var structValue *structpb.Value
+	switch v := message.(type) {
+	case *wrapperspb.StringValue:
+		structValue = structpb.NewStringValue(v.Value)
+	case *wrapperspb.BoolValue:
+		return typeinference.BoolType, nil
+	case *wrapperspb.Int32Value, *wrapperspb.Int64Value, *wrapperspb.UInt32Value, *wrapperspb.UInt64Value:
+		return typeinference.IntType, nil
+	case *wrapperspb.FloatValue, *wrapperspb.DoubleValue:
+		return typeinference.FloatType, nil
+	case *structpb.Value:
+		structValue = v
+	case *structpb.Struct:
+		structValue = structpb.NewStructValue(v)
+	default:
+		return "", fmt.Errorf("unsupported value type %s", value.GetTypeUrl())
+	}
+
+	// Wrap the value in a single field struct so the type inferrer looks at the value itself
+	wrapped, err := anypb.New(&structpb.Struct{Fields: map[string]*structpb.Value{"value": structValue}})
+	if err != nil {
+		return "", fmt.Errorf("failed to pack value: %v", err)
+	}
+	typeInfo, err := (&typeinference.TypeInferrer{}).InferType(wrapped)
+	if err != nil {
+		return "", err
+	}
+	if typeInfo.IsArray {
+		return "", fmt.Errorf("list values are not supported")
+	}
+	return typeInfo.Type, nil
+}
@@ -0,0 +1,360 @@
+// Package kindschema provides a registry of declared entity kinds and validates
+// entities against them. A kind schema declares the allowed minor kinds of a major
+// kind together with the metadata keys and attributes expected on its entities.
+//
+// An empty registry accepts every entity. Once a kind is declared, entities can only
+// be created with declared major kinds.
+package kindschema
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	attributeschema "lk/datafoundation/crud-api/pkg/schema"
+	"lk/datafoundation/crud-api/pkg/storageinference"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// majorKindPattern matches major kinds that can safely be used as graph labels
+var majorKindPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// metadataTypes are the types that can be declared for metadata keys
+var metadataTypes = map[typeinference.DataType]bool{
+	typeinference.IntType:      true,
+	typeinference.FloatType:    true,
+	typeinference.StringType:   true,
+	typeinference.BoolType:     true,
+	typeinference.DateType:     true,
+	typeinference.TimeType:     true,
+	typeinference.DateTimeType: true,
+	typeinference.GeometryType: true,
+}
+
+// storageTypes are the storage types that can be declared for attributes
+var storageTypes = map[storageinference.StorageType]bool{
+	storageinference.TabularData:    true,
+	storageinference.ScalarData:     true,
+	storageinference.ListData:       true,
+	storageinference.MapData:        true,
+	storageinference.GraphData:      true,
+	storageinference.BlobData:       true,
+	storageinference.GeospatialData: true,
+}
+
+// ValidationError is returned when an entity does not match its kind schema
+type ValidationError struct {
+	EntityID   string
+	Kind       string
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("entity %s does not match kind %s: %s", e.EntityID, e.Kind, strings.Join(e.Violations, "; "))
+}
+
+// Registry holds the declared kind schemas keyed by major kind
+type Registry struct {
+	mu    sync.RWMutex
+	kinds map[string]*pb.KindSchema
+}
+
+// NewRegistry creates an empty kind registry
+func NewRegistry() *Registry {
+	return &Registry{
+		kinds: make(map[string]*pb.KindSchema),
+	}
+}
+
+// Load replaces the registered kind schemas after checking that they are well formed
+func (r *Registry) Load(schemas []*pb.KindSchema) error {
+	kinds := make(map[string]*pb.KindSchema)
+	for _, schema := range schemas {
+		if err := checkKindSchema(schema); err != nil {
+			return err
+		}
+		if _, exists := kinds[schema.Major]; exists {
+			return fmt.Errorf("kind %s is declared more than once", schema.Major)
+		}
+		kinds[schema.Major] = proto.Clone(schema).(*pb.KindSchema)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.kinds = kinds
+	slog.Info("Loaded kind schemas", "count", len(kinds))
+	return nil
+}
+
+// checkKindSchema checks that a kind schema is well formed
+func checkKindSchema(schema *pb.KindSchema) error {
+	if schema == nil || schema.Major == "" {
+		return fmt.Errorf("kind major cannot be empty")
+	}
+	if !majorKindPattern.MatchString(schema.Major) {
+		return fmt.Errorf("kind major %q must start with a letter and contain only letters, digits and underscores", schema.Major)
+	}
+	keys := make(map[string]bool)
+	for _, field := range schema.Metadata {
+		if field.Key == "" {
+			return fmt.Errorf("kind %s declares a metadata key without a name", schema.Major)
+		}
+		if keys[field.Key] {
+			return fmt.Errorf("kind %s declares metadata key %s more than once", schema.Major, field.Key)
+		}
+		keys[field.Key] = true
+		if field.Type != "" && !metadataTypes[typeinference.DataType(field.Type)] {
+			return fmt.Errorf("kind %s declares unknown type %s for metadata key %s", schema.Major, field.Type, field.Key)
+		}
+	}
+	names := make(map[string]bool)
+	for _, field := range schema.Attributes {
+		if field.Name == "" {
+			return fmt.Errorf("kind %s declares an attribute without a name", schema.Major)
+		}
+		if names[field.Name] {
+			return fmt.Errorf("kind %s declares attribute %s more than once", schema.Major, field.Name)
+		}
+		names[field.Name] = true
+		if field.StorageType != "" && !storageTypes[storageinference.StorageType(field.StorageType)] {
+			return fmt.Errorf("kind %s declares unknown storage type %s for attribute %s", schema.Major, field.StorageType, field.Name)
+		}
+		if field.JsonSchema != "" {
+			declared, err := attributeschema.ParseDeclaredSchema(field.JsonSchema)
+			if err != nil {
+				return fmt.Errorf("kind %s declares an invalid schema for attribute %s: %v", schema.Major, field.Name, err)
+			}
+			if field.StorageType != "" && storageinference.StorageType(field.StorageType) != declared.StorageType {
+				return fmt.Errorf("kind %s declares storage type %s for attribute %s but its schema describes %s", schema.Major, field.StorageType, field.Name, declared.StorageType)
+			}
+		}
+	}
+	return nil
+}
+
+// Get returns the schema declared for a major kind
+func (r *Registry) Get(major string) (*pb.KindSchema, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schema, ok := r.kinds[major]
+	if !ok {
+		return nil, false
+	}
+	return proto.Clone(schema).(*pb.KindSchema), true
+}
+
+// AttributeSchema returns the schema declared by a kind for an attribute, or nil when none is declared
+func (r *Registry) AttributeSchema(major string, name string) (*attributeschema.SchemaInfo, error) {
+	schema, ok := r.Get(major)
+	if !ok {
+		return nil, nil
+	}
+	for _, field := range schema.Attributes {
+		if field.Name == name && field.JsonSchema != "" {
+			return attributeschema.ParseDeclaredSchema(field.JsonSchema)
+		}
+	}
+	return nil, nil
+}
+
+// List returns the declared kind schemas ordered by major kind
+func (r *Registry) List() []*pb.KindSchema {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*pb.KindSchema, 0, len(r.kinds))
+	for _, schema := range r.kinds {
+		result = append(result, proto.Clone(schema).(*pb.KindSchema))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Major < result[j].Major
+	})
+	return result
+}
+
+// IsEmpty checks if no kinds are declared
+func (r *Registry) IsEmpty() bool {
+	if r == nil {
+		return true
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.kinds) == 0
+}
+
+// Validate checks an entity against the schema of its kind.
+// The kind is passed separately since it cannot be changed by updates and is therefore
+// not part of an update request. Required metadata keys and attributes are only
+// enforced when creating an entity, updates only change the metadata keys they name.
+func (r *Registry) Validate(entity *pb.Entity, kind *pb.Kind, isCreate bool) error {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	schema, ok := r.Get(kind.GetMajor())
+	if !ok {
+		return &ValidationError{
+			EntityID:   entity.GetId(),
+			Kind:       kind.GetMajor(),
+			Violations: []string{fmt.Sprintf("kind %s is not declared", kind.GetMajor())},
+		}
+	}
+
+	var violations []string
+	if len(schema.Minors) > 0 && !containsString(schema.Minors, kind.GetMinor()) {
+		violations = append(violations, fmt.Sprintf("minor kind %q is not one of %v", kind.GetMinor(), schema.Minors))
+	}
+
+	violations = append(violations, validateMetadata(schema, entity, isCreate)...)
+	violations = append(violations, validateAttributes(schema, entity, isCreate)...)
+
+	if len(violations) > 0 {
+		return &ValidationError{
+			EntityID:   entity.GetId(),
+			Kind:       kind.GetMajor(),
+			Violations: violations,
+		}
+	}
+	return nil
+}
+
+// ValidateMetadataRemoval checks that none of the metadata keys an update removes from an entity is required
+// by its kind
+func (r *Registry) ValidateMetadataRemoval(entityID string, kind *pb.Kind, keys []string) error {
+	if r.IsEmpty() || len(keys) == 0 {
+		return nil
+	}
+	schema, ok := r.Get(kind.GetMajor())
+	if !ok {
+		return nil
+	}
+	var violations []string
+	for _, field := range schema.Metadata {
+		if field.Required && containsString(keys, field.Key) {
+			violations = append(violations, fmt.Sprintf("required metadata key %s cannot be removed", field.Key))
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{EntityID: entityID, Kind: kind.GetMajor(), Violations: violations}
+	}
+	return nil
+}
+
+// validateMetadata checks the metadata of an entity against the declared metadata keys
+func validateMetadata(schema *pb.KindSchema, entity *pb.Entity, isCreate bool) []string {
+	var violations []string
+	metadata := entity.GetMetadata()
+	declared := make(map[string]*pb.MetadataFieldSchema)
+	for _, field := range schema.Metadata {
+		declared[field.Key] = field
+
+		// Updates keep the metadata keys they do not name
+		if field.Required && isCreate {
+			if _, ok := metadata[field.Key]; !ok {
+				violations = append(violations, fmt.Sprintf("required metadata key %s is missing", field.Key))
+			}
+		}
+	}
+
+	for key, value := range metadata {
+		field, ok := declared[key]
+		if !ok {
+			if schema.Closed {
+				violations = append(violations, fmt.Sprintf("metadata key %s is not declared", key))
+			}
+			continue
+		}
+		if field.Type == "" {
+			continue
+		}
+		dataType, err := InferMetadataType(value)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("metadata key %s: %v", key, err))
+			continue
+		}
+		if !isCompatibleType(typeinference.DataType(field.Type), dataType) {
+			violations = append(violations, fmt.Sprintf("metadata key %s must be of type %s, got %s", key, field.Type, dataType))
+		}
+	}
+	return violations
+}
+
+// validateAttributes checks the attributes of an entity against the declared attributes
+func validateAttributes(schema *pb.KindSchema, entity *pb.Entity, isCreate bool) []string {
+	var violations []string
+	attributes := entity.GetAttributes()
+	declared := make(map[string]*pb.AttributeFieldSchema)
+	for _, field := range schema.Attributes {
+		declared[field.Name] = field
+		if field.Required && isCreate {
+			if values, ok := attributes[field.Name]; !ok || len(values.GetValues()) == 0 {
+				violations = append(violations, fmt.Sprintf("required attribute %s is missing", field.Name))
+			}
+		}
+	}
+
+	inferrer := &storageinference.StorageInferrer{}
+	for name, values := range attributes {
+		field, ok := declared[name]
+		if !ok {
+			if schema.Closed {
+				violations = append(violations, fmt.Sprintf("attribute %s is not declared", name))
+			}
+			continue
+		}
+		if field.StorageType == "" {
+			continue
+		}
+		for _, value := range values.GetValues() {
+			if value.GetValue() == nil {
+				continue
+			}
+			storageType, err := inferrer.InferType(value.GetValue())
+			if err != nil {
+				violations = append(violations, fmt.Sprintf("attribute %s: %v", name, err))
+				break
+			}
+			if storageType != storageinference.StorageType(field.StorageType) {
+				violations = append(violations, fmt.Sprintf("attribute %s must be stored as %s, got %s", name, field.StorageType, storageType))
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// isCompatibleType checks if an inferred type can be stored under a declared type.
+// Integers are valid floats, and dates, times and geometries are valid strings since they were inferred as such.
+func isCompatibleType(declared typeinference.DataType, inferred typeinference.DataType) bool {
+	if declared == inferred {
+		return true
+	}
+	switch declared {
+	case typeinference.FloatType:
+		return inferred == typeinference.IntType
+	case typeinference.StringType:
+		return inferred == typeinference.DateType || inferred == typeinference.TimeType || inferred == typeinference.DateTimeType ||
+			inferred == typeinference.GeometryType
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
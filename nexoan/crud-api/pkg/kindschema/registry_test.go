@@ -0,0 +1,241 @@
+package kindschema
+
+import (
+	"testing"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// mustAny packs a message into an Any value
+func mustAny(t *testing.T, value interface{}) *anypb.Any {
+	var anyValue *anypb.Any
+	var err error
+	switch v := value.(type) {
+	case string:
+		anyValue, err = anypb.New(wrapperspb.String(v))
+	case int64:
+		anyValue, err = anypb.New(wrapperspb.Int64(v))
+	case bool:
+		anyValue, err = anypb.New(wrapperspb.Bool(v))
+	case map[string]interface{}:
+		var structValue *structpb.Struct
+		structValue, err = structpb.NewStruct(v)
+		assert.NoError(t, err)
+		anyValue, err = anypb.New(structValue)
+	}
+	assert.NoError(t, err)
+	return anyValue
+}
+
+func newTestRegistry(t *testing.T) *Registry {
+	registry := NewRegistry()
+	err := registry.Load([]*pb.KindSchema{
+		{
+			Major:  "Organisation",
+			Minors: []string{"Ministry", "Department"},
+			Metadata: []*pb.MetadataFieldSchema{
+				{Key: "gazetteNumber", Type: "string", Required: true},
+				{Key: "budget", Type: "float"},
+			},
+			Attributes: []*pb.AttributeFieldSchema{
+				{Name: "employees", StorageType: "tabular", Required: true},
+			},
+		},
+		{
+			Major:  "Person",
+			Closed: true,
+			Metadata: []*pb.MetadataFieldSchema{
+				{Key: "born", Type: "date"},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	return registry
+}
+
+func employeesAttribute(t *testing.T) map[string]*pb.TimeBasedValueList {
+	return map[string]*pb.TimeBasedValueList{
+		"employees": {Values: []*pb.TimeBasedValue{{
+			StartTime: "2024-01-01T00:00:00Z",
+			Value: mustAny(t, map[string]interface{}{
+				"columns": []interface{}{"id", "name"},
+				"rows":    []interface{}{[]interface{}{1, "John"}},
+			}),
+		}}},
+	}
+}
+
+func TestEmptyRegistryAcceptsEverything(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Validate(&pb.Entity{Id: "e1"}, &pb.Kind{Major: "Anything", Minor: "Goes"}, true)
+	assert.NoError(t, err)
+}
+
+func TestLoadRejectsInvalidSchemas(t *testing.T) {
+	testCases := map[string]*pb.KindSchema{
+		"empty major":          {Major: ""},
+		"unsafe major":         {Major: "Person`) DETACH DELETE n //"},
+		"unknown type":         {Major: "Person", Metadata: []*pb.MetadataFieldSchema{{Key: "age", Type: "number"}}},
+		"unknown storage type": {Major: "Person", Attributes: []*pb.AttributeFieldSchema{{Name: "salary", StorageType: "video"}}},
+		"duplicate key":        {Major: "Person", Metadata: []*pb.MetadataFieldSchema{{Key: "age"}, {Key: "age"}}},
+		"invalid json schema":  {Major: "Person", Attributes: []*pb.AttributeFieldSchema{{Name: "salary", JsonSchema: `{"type": 1}`}}},
+		"non tabular schema":   {Major: "Person", Attributes: []*pb.AttributeFieldSchema{{Name: "salary", JsonSchema: `{"type": "number"}`}}},
+		"conflicting schema": {Major: "Person", Attributes: []*pb.AttributeFieldSchema{{Name: "salary", StorageType: "map", JsonSchema: `{
+			"type": "array", "x-storage-type": "tabular", "items": {"type": "object", "properties": {"amount": {"type": "number"}}}
+		}`}}},
+	}
+	for name, schema := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := NewRegistry().Load([]*pb.KindSchema{schema})
+			assert.Error(t, err)
+		})
+	}
+
+	err := NewRegistry().Load([]*pb.KindSchema{{Major: "Person"}, {Major: "Person"}})
+	assert.Error(t, err, "Expected an error for a kind declared twice")
+}
+
+func TestValidateCreate(t *testing.T) {
+	registry := newTestRegistry(t)
+	kind := &pb.Kind{Major: "Organisation", Minor: "Ministry"}
+
+	valid := &pb.Entity{
+		Id: "ministry_1",
+		Metadata: map[string]*anypb.Any{
+			"gazetteNumber": mustAny(t, "2024/01"),
+			"budget":        mustAny(t, int64(1000)),
+			"website":       mustAny(t, "https://example.gov"),
+		},
+		Attributes: employeesAttribute(t),
+	}
+	assert.NoError(t, registry.Validate(valid, kind, true))
+
+	// Undeclared kinds are rejected once the registry is not empty
+	err := registry.Validate(valid, &pb.Kind{Major: "Company", Minor: "Private"}, true)
+	assert.Error(t, err)
+
+	// Undeclared minor kinds are rejected
+	err = registry.Validate(valid, &pb.Kind{Major: "Organisation", Minor: "Company"}, true)
+	assert.Error(t, err)
+
+	invalid := &pb.Entity{
+		Id: "ministry_2",
+		Metadata: map[string]*anypb.Any{
+			"budget": mustAny(t, "a lot"),
+		},
+		Attributes: map[string]*pb.TimeBasedValueList{
+			"employees": {Values: []*pb.TimeBasedValue{{Value: mustAny(t, int64(42))}}},
+		},
+	}
+	err = registry.Validate(invalid, kind, true)
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok, "Expected a ValidationError")
+	assert.Equal(t, "Organisation", validationErr.Kind)
+	assert.Len(t, validationErr.Violations, 3, "Expected missing key, wrong type and wrong storage type violations: %v", validationErr.Violations)
+}
+
+func TestValidateUpdate(t *testing.T) {
+	registry := newTestRegistry(t)
+	kind := &pb.Kind{Major: "Organisation", Minor: "Ministry"}
+
+	// Required fields are not needed when nothing replaces them
+	assert.NoError(t, registry.Validate(&pb.Entity{Id: "ministry_1"}, kind, false))
+
+	// Updates only change the metadata keys they name, the required keys keep their values
+	assert.NoError(t, registry.Validate(&pb.Entity{
+		Id:       "ministry_1",
+		Metadata: map[string]*anypb.Any{"budget": mustAny(t, int64(10))},
+	}, kind, false))
+
+	// Required metadata keys cannot be removed
+	err := registry.ValidateMetadataRemoval("ministry_1", kind, []string{"budget", "gazetteNumber"})
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok, "Expected a ValidationError")
+	assert.Len(t, validationErr.Violations, 1)
+	assert.NoError(t, registry.ValidateMetadataRemoval("ministry_1", kind, []string{"budget"}))
+	assert.NoError(t, registry.ValidateMetadataRemoval("ministry_1", kind, nil))
+}
+
+func TestValidateClosedKind(t *testing.T) {
+	registry := newTestRegistry(t)
+	kind := &pb.Kind{Major: "Person", Minor: "Minister"}
+
+	err := registry.Validate(&pb.Entity{
+		Id:       "person_1",
+		Metadata: map[string]*anypb.Any{"born": mustAny(t, "1970-01-01")},
+	}, kind, true)
+	assert.NoError(t, err)
+
+	err = registry.Validate(&pb.Entity{
+		Id:       "person_1",
+		Metadata: map[string]*anypb.Any{"nickname": mustAny(t, "PM")},
+	}, kind, true)
+	assert.Error(t, err, "Expected undeclared metadata to be rejected on a closed kind")
+}
+
+func TestInferMetadataType(t *testing.T) {
+	testCases := []struct {
+		value    *anypb.Any
+		expected string
+	}{
+		{mustAny(t, "hello"), "string"},
+		{mustAny(t, "2024-03-20"), "date"},
+		{mustAny(t, int64(3)), "int"},
+		{mustAny(t, true), "bool"},
+		{mustAny(t, map[string]interface{}{"type": "Point", "coordinates": []interface{}{79.86, 6.93}}), "geometry"},
+	}
+	for _, tc := range testCases {
+		dataType, err := InferMetadataType(tc.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, string(dataType))
+	}
+}
+
+func TestListAndGet(t *testing.T) {
+	registry := newTestRegistry(t)
+
+	kinds := registry.List()
+	assert.Len(t, kinds, 2)
+	assert.Equal(t, "Organisation", kinds[0].Major)
+	assert.Equal(t, "Person", kinds[1].Major)
+
+	schema, ok := registry.Get("Organisation")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Ministry", "Department"}, schema.Minors)
+
+	_, ok = registry.Get("Company")
+	assert.False(t, ok)
+}
+
+func TestAttributeSchema(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Load([]*pb.KindSchema{{
+		Major: "Organisation",
+		Attributes: []*pb.AttributeFieldSchema{
+			{Name: "employees", StorageType: "tabular", JsonSchema: `{
+				"type": "array",
+				"x-storage-type": "tabular",
+				"items": {"type": "object", "properties": {"name": {"type": "string"}}, "required": ["name"]}
+			}`},
+			{Name: "budget"},
+		},
+	}})
+	assert.NoError(t, err)
+
+	declared, err := registry.AttributeSchema("Organisation", "employees")
+	assert.NoError(t, err)
+	assert.True(t, declared.Declared)
+	assert.Contains(t, declared.Fields, "name")
+
+	declared, err = registry.AttributeSchema("Organisation", "budget")
+	assert.NoError(t, err)
+	assert.Nil(t, declared)
+	declared, err = registry.AttributeSchema("Person", "employees")
+	assert.NoError(t, err)
+	assert.Nil(t, declared)
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDHeader is the metadata key carrying the request ID, a caller can set it to correlate its own logs
+const RequestIDHeader = "x-request-id"
+
+// healthServicePrefix identifies the health checks, which are polled too often to be logged at the info level
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// UnaryServerInterceptor assigns a request ID to every unary RPC, stores a logger including it in the context
+// and logs the outcome of the RPC. The ID is taken from the x-request-id metadata when the caller sets it
+// and is returned to the caller in the x-request-id response header.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = startRequest(ctx, info.FullMethod)
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRequest(ctx, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor assigns a request ID to every streaming RPC, see UnaryServerInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := startRequest(stream.Context(), info.FullMethod)
+		start := time.Now()
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		logRequest(ctx, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// startRequest returns a context carrying the request ID of the RPC and a logger including it and the method
+func startRequest(ctx context.Context, method string) context.Context {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	// The header is only sent with the response, failing to set it does not affect the RPC
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, requestID))
+
+	ctx = WithRequestID(ctx, requestID)
+	return WithLogger(ctx, FromContext(ctx).With("method", method))
+}
+
+// logRequest logs the status code and duration of a finished RPC
+func logRequest(ctx context.Context, method string, start time.Time, err error) {
+	level := slog.LevelInfo
+	if strings.HasPrefix(method, healthServicePrefix) {
+		level = slog.LevelDebug
+	}
+	attrs := []slog.Attr{
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	}
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	FromContext(ctx).LogAttrs(ctx, level, "Finished RPC", attrs...)
+}
+
+// contextStream replaces the context of a server stream so that handlers see the request logger
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LevelHandler reports the current log level on GET and changes it on PUT or POST.
+// The new level is read from the level query parameter or the request body:
+//
+//	curl -X PUT 'localhost:9090/debug/loglevel?level=debug'
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			name := r.URL.Query().Get("level")
+			if name == "" {
+				body, err := io.ReadAll(io.LimitReader(r.Body, 64))
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				name = strings.TrimSpace(string(body))
+			}
+			if err := SetLevel(name); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			FromContext(r.Context()).Info("Changed log level", "level", Level().String())
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		fmt.Fprintln(w, strings.ToLower(Level().String()))
+	})
+}
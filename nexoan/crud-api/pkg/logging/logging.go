@@ -0,0 +1,154 @@
+// Package logging provides the structured logger of the CRUD service.
+// Loggers are carried in the request context so that every line logged while serving an RPC
+// includes its request ID and trace ID. The level can be changed while the service runs and
+// request payloads are only logged when explicitly enabled.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// level is shared by every handler created by Setup so that it can be changed at runtime
+	level = new(slog.LevelVar)
+	// payloads enables logging of entities, attribute values and query results
+	payloads atomic.Bool
+)
+
+// contextKey is the type of the context keys defined by this package
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// Setup installs the default logger described by the configuration.
+// Lines written with the standard log package are redirected to it at the info level.
+func Setup(cfg config.LoggingConfig) error {
+	return SetupWithWriter(cfg, os.Stderr)
+}
+
+// SetupWithWriter installs the default logger writing to w
+func SetupWithWriter(cfg config.LoggingConfig, w io.Writer) error {
+	if err := SetLevel(cfg.Level); err != nil {
+		return err
+	}
+	payloads.Store(cfg.Payloads)
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, options)
+	case "json":
+		handler = slog.NewJSONHandler(w, options)
+	default:
+		return fmt.Errorf("unknown log format %q, expected text or json", cfg.Format)
+	}
+	slog.SetDefault(slog.New(&contextHandler{Handler: handler}))
+	return nil
+}
+
+// SetLevel changes the minimum level of the logger, one of debug, info, warn or error
+func SetLevel(name string) error {
+	if name == "" {
+		name = "info"
+	}
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+	}
+	level.Set(parsed)
+	return nil
+}
+
+// Level returns the current minimum level of the logger
+func Level() slog.Level {
+	return level.Level()
+}
+
+// PayloadsEnabled reports whether request payloads are logged
+func PayloadsEnabled() bool {
+	return payloads.Load()
+}
+
+// Payload returns an attribute holding a request payload such as an entity or query result.
+// Payloads can hold personal data so the attribute is dropped unless payload logging is enabled.
+func Payload(key string, value interface{}) slog.Attr {
+	if !payloads.Load() {
+		return slog.Attr{}
+	}
+	return slog.Any(key, value)
+}
+
+// FromContext returns the logger carried by ctx, or the default logger
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// WithLogger returns a context carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// WithRequestID returns a context carrying the request ID and a logger that includes it in every line
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey, requestID)
+	return WithLogger(ctx, FromContext(ctx).With("request_id", requestID))
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// NewRequestID generates a random request ID
+func NewRequestID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(id)
+}
+
+// contextHandler adds the trace and span IDs of the span active in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// setupBuffer installs a JSON logger writing to a buffer and restores the default logger after the test
+func setupBuffer(t *testing.T, cfg config.LoggingConfig) *bytes.Buffer {
+	previous := slog.Default()
+	t.Cleanup(func() {
+		slog.SetDefault(previous)
+		_ = SetLevel("info")
+		payloads.Store(false)
+	})
+
+	var buffer bytes.Buffer
+	cfg.Format = "json"
+	assert.NoError(t, SetupWithWriter(cfg, &buffer))
+	return &buffer
+}
+
+// records decodes every JSON line written to buffer
+func records(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		record := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestSetupErrors(t *testing.T) {
+	var buffer bytes.Buffer
+	assert.Error(t, SetupWithWriter(config.LoggingConfig{Level: "verbose"}, &buffer))
+	assert.Error(t, SetupWithWriter(config.LoggingConfig{Level: "info", Format: "xml"}, &buffer))
+}
+
+func TestLevelAndPayloads(t *testing.T) {
+	buffer := setupBuffer(t, config.LoggingConfig{Level: "info"})
+	ctx := context.Background()
+
+	FromContext(ctx).Debug("hidden")
+	FromContext(ctx).Info("entity created", Payload("entity", "secret"))
+	assert.NoError(t, SetLevel("debug"))
+	FromContext(ctx).Debug("shown")
+
+	logged := records(t, buffer)
+	assert.Len(t, logged, 2)
+	assert.Equal(t, "entity created", logged[0]["msg"])
+	assert.NotContains(t, logged[0], "entity")
+	assert.Equal(t, "shown", logged[1]["msg"])
+
+	payloads.Store(true)
+	buffer.Reset()
+	FromContext(ctx).Info("entity created", Payload("entity", "secret"))
+	assert.Equal(t, "secret", records(t, buffer)[0]["entity"])
+}
+
+func TestContextAttributes(t *testing.T) {
+	buffer := setupBuffer(t, config.LoggingConfig{Level: "info"})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = WithRequestID(ctx, "req-1")
+	assert.Equal(t, "req-1", RequestID(ctx))
+
+	FromContext(ctx).InfoContext(ctx, "reading entity")
+	record := records(t, buffer)[0]
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, traceID.String(), record["trace_id"])
+	assert.Equal(t, spanID.String(), record["span_id"])
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	buffer := setupBuffer(t, config.LoggingConfig{Level: "info"})
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/crud.CrudService/ReadEntity"}
+
+	// The caller's request ID is kept
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(RequestIDHeader, "caller-id"))
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.Equal(t, "caller-id", RequestID(ctx))
+		FromContext(ctx).InfoContext(ctx, "handling")
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+
+	// A request ID is generated when the caller does not send one
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		assert.NotEmpty(t, RequestID(ctx))
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	assert.Error(t, err)
+
+	logged := records(t, buffer)
+	assert.Len(t, logged, 3)
+	assert.Equal(t, "handling", logged[0]["msg"])
+	assert.Equal(t, "caller-id", logged[0]["request_id"])
+	assert.Equal(t, info.FullMethod, logged[0]["method"])
+	assert.Equal(t, "OK", logged[1]["code"])
+	assert.Equal(t, "WARN", logged[2]["level"])
+	assert.Equal(t, "NotFound", logged[2]["code"])
+	assert.NotEqual(t, "caller-id", logged[2]["request_id"])
+
+	// Health checks are only logged at the debug level
+	buffer.Reset()
+	health := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	_, err = interceptor(context.Background(), nil, health, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, buffer.String())
+}
+
+func TestLevelHandler(t *testing.T) {
+	setupBuffer(t, config.LoggingConfig{Level: "info"})
+	handler := LevelHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/loglevel", nil))
+	assert.Equal(t, "info\n", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("PUT", "/debug/loglevel?level=debug", nil))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, slog.LevelDebug, Level())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("PUT", "/debug/loglevel", strings.NewReader("warn")))
+	assert.Equal(t, "warn\n", recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("PUT", "/debug/loglevel?level=loud", nil))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, slog.LevelWarn, Level())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("DELETE", "/debug/loglevel", nil))
+	assert.Equal(t, 405, recorder.Code)
+}
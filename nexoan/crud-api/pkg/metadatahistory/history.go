@@ -0,0 +1,232 @@
+// Package metadatahistory keeps the history of the metadata of an entity. Every key holds a list of versions,
+// each valid from the time its value was set until the next value of the key or the removal of the key.
+// The stores keep the versions and apply changes with Apply, so that every backend records the same history.
+package metadatahistory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Change sets and removes metadata keys of an entity, the keys it does not name keep their values
+type Change struct {
+	// Set holds the new values of the keys
+	Set map[string]*anypb.Any
+	// Remove lists the keys to remove, removing a key without a value does nothing
+	Remove []string
+	// ValidFrom is the time the change takes effect, RecordedAt when empty
+	ValidFrom string
+	// RecordedBy is the subject of the caller making the change
+	RecordedBy string
+	// RecordedAt is the time the change is made, the current time when empty
+	RecordedAt string
+}
+
+// IsEmpty reports whether the change neither sets nor removes a key
+func (c *Change) IsEmpty() bool {
+	return c == nil || (len(c.Set) == 0 && len(c.Remove) == 0)
+}
+
+// InvalidChangeError is returned when a change cannot be applied to the history of a key
+type InvalidChangeError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidChangeError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("invalid metadata change: %s", e.Reason)
+	}
+	return fmt.Sprintf("invalid change of metadata key %q: %s", e.Key, e.Reason)
+}
+
+// ParseTime parses an RFC 3339 timestamp as used for the validity of metadata values
+func ParseTime(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// isBefore reports whether a is before b, an empty or unreadable time is before any other
+func isBefore(a, b string) bool {
+	timeA, errA := ParseTime(a)
+	timeB, errB := ParseTime(b)
+	if errB != nil {
+		return false
+	}
+	return errA != nil || timeA.Before(timeB)
+}
+
+// current returns the index of the current version of a key, or -1 if the key has no value
+func current(versions []*pb.MetadataVersion, key string) int {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Key == key && versions[i].ValidTo == "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// latest returns the index of the last version of a key, or -1 if the key never had a value
+func latest(versions []*pb.MetadataVersion, key string) int {
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Apply returns the versions after the change, the given versions are not modified.
+// The current value of every key the change sets or removes ends when the change takes effect, which cannot
+// be before that value started. A key without a value cannot be set before its last value ended, so the
+// versions must include the last version of every key the change sets. Setting a key to its current value
+// does not add a version.
+func Apply(versions []*pb.MetadataVersion, change *Change) ([]*pb.MetadataVersion, error) {
+	result := make([]*pb.MetadataVersion, len(versions))
+	for i, version := range versions {
+		result[i] = proto.Clone(version).(*pb.MetadataVersion)
+	}
+	if change.IsEmpty() {
+		return result, nil
+	}
+
+	recordedAt := change.RecordedAt
+	if recordedAt == "" {
+		recordedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	validFrom := change.ValidFrom
+	if validFrom == "" {
+		validFrom = recordedAt
+	}
+	if _, err := ParseTime(validFrom); err != nil {
+		return nil, &InvalidChangeError{Reason: fmt.Sprintf("validFrom %q is not an RFC3339 timestamp", validFrom)}
+	}
+
+	// end closes the current value of a key when the change takes effect
+	end := func(key string) error {
+		i := current(result, key)
+		if i < 0 {
+			return nil
+		}
+		if isBefore(validFrom, result[i].ValidFrom) {
+			return &InvalidChangeError{Key: key, Reason: fmt.Sprintf("the current value is valid from %s, after %s", result[i].ValidFrom, validFrom)}
+		}
+		result[i].ValidTo = validFrom
+		return nil
+	}
+
+	keys := make([]string, 0, len(change.Set))
+	for key := range change.Set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := change.Set[key]
+		switch {
+		case key == "":
+			return nil, &InvalidChangeError{Reason: "metadata keys cannot be empty"}
+		case value == nil:
+			return nil, &InvalidChangeError{Key: key, Reason: "the value is missing"}
+		}
+		if i := current(result, key); i >= 0 && proto.Equal(result[i].Value, value) {
+			continue
+		}
+		if i := latest(result, key); i >= 0 && result[i].ValidTo != "" && isBefore(validFrom, result[i].ValidTo) {
+			return nil, &InvalidChangeError{Key: key, Reason: fmt.Sprintf("the last value ended at %s, after %s", result[i].ValidTo, validFrom)}
+		}
+		if err := end(key); err != nil {
+			return nil, err
+		}
+		result = append(result, &pb.MetadataVersion{
+			Key:        key,
+			Value:      proto.Clone(value).(*anypb.Any),
+			ValidFrom:  validFrom,
+			RecordedBy: change.RecordedBy,
+			RecordedAt: recordedAt,
+		})
+	}
+
+	for _, key := range change.Remove {
+		if _, set := change.Set[key]; set {
+			return nil, &InvalidChangeError{Key: key, Reason: "the key is both set and removed"}
+		}
+		if err := end(key); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// FromMetadata returns the versions of metadata stored before its history was kept, they have been valid
+// since an unknown time
+func FromMetadata(metadata map[string]*anypb.Any) []*pb.MetadataVersion {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	versions := make([]*pb.MetadataVersion, 0, len(keys))
+	for _, key := range keys {
+		versions = append(versions, &pb.MetadataVersion{Key: key, Value: proto.Clone(metadata[key]).(*anypb.Any)})
+	}
+	return versions
+}
+
+// Current returns the current value of every key that has one
+func Current(versions []*pb.MetadataVersion) map[string]*anypb.Any {
+	metadata := make(map[string]*anypb.Any)
+	for _, version := range versions {
+		if version.ValidTo == "" {
+			metadata[version.Key] = proto.Clone(version.Value).(*anypb.Any)
+		}
+	}
+	return metadata
+}
+
+// AsOf returns the value every key held at the given time. A value is valid from its start time included
+// until its end time excluded.
+func AsOf(versions []*pb.MetadataVersion, activeAt time.Time) map[string]*anypb.Any {
+	metadata := make(map[string]*anypb.Any)
+	for _, version := range versions {
+		if validFrom, err := ParseTime(version.ValidFrom); err == nil && activeAt.Before(validFrom) {
+			continue
+		}
+		if validTo, err := ParseTime(version.ValidTo); err == nil && !activeAt.Before(validTo) {
+			continue
+		}
+		metadata[version.Key] = proto.Clone(version.Value).(*anypb.Any)
+	}
+	return metadata
+}
+
+// Read returns the current metadata when activeAt is empty and the metadata as of activeAt otherwise
+func Read(versions []*pb.MetadataVersion, activeAt string) (map[string]*anypb.Any, error) {
+	if activeAt == "" {
+		return Current(versions), nil
+	}
+	at, err := ParseTime(activeAt)
+	if err != nil {
+		return nil, fmt.Errorf("activeAt %q is not an RFC3339 timestamp: %v", activeAt, err)
+	}
+	return AsOf(versions, at), nil
+}
+
+// Select returns copies of the versions of a key, or of every key when key is empty, ordered by key and
+// then in the order they were recorded
+func Select(versions []*pb.MetadataVersion, key string) []*pb.MetadataVersion {
+	selected := []*pb.MetadataVersion{}
+	for _, version := range versions {
+		if key == "" || version.Key == key {
+			selected = append(selected, proto.Clone(version).(*pb.MetadataVersion))
+		}
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		return selected[i].Key < selected[j].Key
+	})
+	return selected
+}
@@ -0,0 +1,141 @@
+package metadatahistory
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func mustString(t *testing.T, value string) *anypb.Any {
+	message, err := anypb.New(wrapperspb.String(value))
+	assert.NoError(t, err)
+	return message
+}
+
+// readString returns the string held by a value, or "" if there is none
+func readString(t *testing.T, value *anypb.Any) string {
+	if value == nil {
+		return ""
+	}
+	var message wrapperspb.StringValue
+	assert.NoError(t, value.UnmarshalTo(&message))
+	return message.Value
+}
+
+func TestApply(t *testing.T) {
+	versions, err := Apply(nil, &Change{
+		Set:        map[string]*anypb.Any{"minister": mustString(t, "A"), "gazette": mustString(t, "2019/1")},
+		ValidFrom:  "2019-12-01T00:00:00Z",
+		RecordedBy: "ingest-job",
+		RecordedAt: "2019-12-02T00:00:00Z",
+	})
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "gazette", versions[0].Key)
+	assert.Equal(t, "2019-12-01T00:00:00Z", versions[0].ValidFrom)
+	assert.Equal(t, "ingest-job", versions[0].RecordedBy)
+	assert.Equal(t, "2019-12-02T00:00:00Z", versions[0].RecordedAt)
+
+	// Only the keys that are set change, the previous value ends when the new one starts
+	updated, err := Apply(versions, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2022-07-22T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Len(t, updated, 3)
+	assert.Empty(t, versions[1].ValidTo, "Expected the given versions not to be modified")
+	assert.Equal(t, "2022-07-22T00:00:00Z", updated[1].ValidTo)
+	assert.Empty(t, updated[0].ValidTo)
+	assert.Equal(t, "B", readString(t, Current(updated)["minister"]))
+	assert.Equal(t, "2019/1", readString(t, Current(updated)["gazette"]))
+
+	// Setting the current value does not add a version
+	unchanged, err := Apply(updated, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2023-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Len(t, unchanged, 3)
+
+	removed, err := Apply(updated, &Change{Remove: []string{"gazette", "missing"}, ValidFrom: "2023-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	assert.Len(t, removed, 3)
+	assert.Equal(t, "2023-01-01T00:00:00Z", removed[0].ValidTo)
+	assert.NotContains(t, Current(removed), "gazette")
+
+	// A change without validFrom takes effect when it is recorded
+	recorded, err := Apply(removed, &Change{Set: map[string]*anypb.Any{"gazette": mustString(t, "2024/2")}})
+	assert.NoError(t, err)
+	last := recorded[len(recorded)-1]
+	assert.NotEmpty(t, last.RecordedAt)
+	assert.Equal(t, last.RecordedAt, last.ValidFrom)
+}
+
+func TestApplyInvalid(t *testing.T) {
+	versions, err := Apply(nil, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "A")}, ValidFrom: "2020-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+
+	for _, change := range []*Change{
+		{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2019-01-01T00:00:00Z"},
+		{Remove: []string{"minister"}, ValidFrom: "2019-01-01T00:00:00Z"},
+		{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "22 July 2022"},
+		{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, Remove: []string{"minister"}},
+		{Set: map[string]*anypb.Any{"": mustString(t, "B")}},
+		{Set: map[string]*anypb.Any{"minister": nil}},
+	} {
+		_, err := Apply(versions, change)
+		var invalid *InvalidChangeError
+		assert.True(t, errors.As(err, &invalid), "%+v", change)
+	}
+
+	// A removed key cannot be set again before its last value ended
+	removed, err := Apply(versions, &Change{Remove: []string{"minister"}, ValidFrom: "2022-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+	_, err = Apply(removed, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2021-01-01T00:00:00Z"})
+	var invalid *InvalidChangeError
+	assert.True(t, errors.As(err, &invalid))
+	_, err = Apply(removed, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2022-01-01T00:00:00Z"})
+	assert.NoError(t, err)
+}
+
+func TestRead(t *testing.T) {
+	versions := FromMetadata(map[string]*anypb.Any{"minister": mustString(t, "A"), "gazette": mustString(t, "2019/1")})
+	assert.Empty(t, versions[0].ValidFrom)
+	versions, err := Apply(versions, &Change{Set: map[string]*anypb.Any{"minister": mustString(t, "B")}, ValidFrom: "2022-07-22T00:00:00Z"})
+	assert.NoError(t, err)
+
+	// Values stored before the history was kept have been valid since an unknown time
+	asOf := AsOf(versions, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, "A", readString(t, asOf["minister"]))
+	assert.Equal(t, "2019/1", readString(t, asOf["gazette"]))
+
+	// A value ends at the start of the next one
+	metadata, err := Read(versions, "2022-07-22T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "B", readString(t, metadata["minister"]))
+	metadata, err = Read(versions, "2022-07-21T23:59:59Z")
+	assert.NoError(t, err)
+	assert.Equal(t, "A", readString(t, metadata["minister"]))
+	metadata, err = Read(versions, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "B", readString(t, metadata["minister"]))
+
+	_, err = Read(versions, "yesterday")
+	assert.Error(t, err)
+
+	selected := Select(versions, "minister")
+	assert.Len(t, selected, 2)
+	assert.Equal(t, "A", readString(t, selected[0].Value))
+	assert.Equal(t, "B", readString(t, selected[1].Value))
+	assert.Equal(t, []string{"gazette", "minister", "minister"}, keys(Select(versions, "")))
+	assert.Empty(t, Select(versions, "missing"))
+	assert.Empty(t, Select(nil, ""))
+}
+
+func keys(versions []*pb.MetadataVersion) []string {
+	result := make([]string, len(versions))
+	for i, version := range versions {
+		result[i] = version.Key
+	}
+	return result
+}
@@ -0,0 +1,117 @@
+// Package metrics collects the Prometheus metrics of the CRUD service: RPC latency and errors,
+// the latency and outcome of every store operation and the attributes processed by storage type.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Registry holds every metric of the service along with the Go runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "crud_grpc_requests_total",
+		Help: "Number of gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "crud_grpc_request_duration_seconds",
+		Help:    "Latency of gRPC requests, by method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	storeOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "crud_store_operations_total",
+		Help: "Number of store operations, by store, repository method and result.",
+	}, []string{"store", "method", "result"})
+
+	storeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "crud_store_operation_duration_seconds",
+		Help:    "Latency of store operations, by store and repository method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"store", "method"})
+
+	attributesProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "crud_attributes_processed_total",
+		Help: "Number of attribute values processed, by operation, storage type and result.",
+	}, []string{"operation", "storage_type", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcRequests,
+		rpcDuration,
+		storeOperations,
+		storeDuration,
+		attributesProcessed,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// result returns the result label of an operation
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// observeRPC records the latency and status code of a gRPC request
+func observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	rpcRequests.WithLabelValues(method, code).Inc()
+	rpcDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// UnaryServerInterceptor records the latency and status code of every unary RPC
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPC(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor records the duration and status code of every streaming RPC
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, stream)
+		observeRPC(info.FullMethod, start, err)
+		return err
+	}
+}
+
+// ObserveStoreOperation records the latency and result of a store operation that started at start.
+// It is meant to be deferred with a pointer to the named error result of the operation:
+//
+//	defer metrics.ObserveStoreOperation("neo4j", "HandleGraphEntityFilter", time.Now(), &err)
+func ObserveStoreOperation(store string, method string, start time.Time, err *error) {
+	var opErr error
+	if err != nil {
+		opErr = *err
+	}
+	storeOperations.WithLabelValues(store, method, result(opErr)).Inc()
+	storeDuration.WithLabelValues(store, method).Observe(time.Since(start).Seconds())
+}
+
+// ObserveAttribute counts an attribute value processed by the attribute engine
+func ObserveAttribute(operation string, storageType string, err error) {
+	attributesProcessed.WithLabelValues(operation, storageType, result(err)).Inc()
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/crud.CrudService/ReadEntity"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(rpcRequests.WithLabelValues(info.FullMethod, codes.OK.String())))
+	assert.Equal(t, float64(1), testutil.ToFloat64(rpcRequests.WithLabelValues(info.FullMethod, codes.NotFound.String())))
+}
+
+func TestObserveStoreOperation(t *testing.T) {
+	operation := func(fail bool) (err error) {
+		defer ObserveStoreOperation("postgres", "HandleTabularData", time.Now(), &err)
+		if fail {
+			return errors.New("relation does not exist")
+		}
+		return nil
+	}
+	assert.NoError(t, operation(false))
+	assert.NoError(t, operation(false))
+	assert.Error(t, operation(true))
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(storeOperations.WithLabelValues("postgres", "HandleTabularData", "success")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(storeOperations.WithLabelValues("postgres", "HandleTabularData", "error")))
+}
+
+func TestHandler(t *testing.T) {
+	ObserveAttribute("create", "tabular", nil)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `crud_attributes_processed_total{operation="create",result="success",storage_type="tabular"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+
+	problems, err := testutil.GatherAndLint(Registry)
+	assert.NoError(t, err)
+	assert.Empty(t, problems)
+}
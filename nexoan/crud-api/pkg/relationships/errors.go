@@ -0,0 +1,65 @@
+package relationships
+
+import (
+	"fmt"
+
+	"lk/datafoundation/crud-api/commons"
+)
+
+// Relationship integrity rule names
+const (
+	EndpointLifetimeRule = "ENDPOINT_LIFETIME"
+	NonOverlappingRule   = "NON_OVERLAPPING"
+	SingleValuedRule     = "SINGLE_VALUED"
+	CardinalityRule      = "CARDINALITY"
+	// TypeRule is the rule name reported when a relationship does not match its declared type
+	TypeRule = "RELATIONSHIP_TYPE"
+)
+
+// IntegrityError is returned when a relationship violates one of the configured integrity rules
+type IntegrityError struct {
+	RelationshipID string
+	Rule           string
+	Message        string
+}
+
+func (e *IntegrityError) Error() string {
+	return fmt.Sprintf("relationship %s violates %s rule: %s", e.RelationshipID, e.Rule, e.Message)
+}
+
+// NotFoundError is returned when a relationship with the given Id does not exist, it wraps commons.ErrNotFound
+type NotFoundError struct {
+	RelationshipID string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("relationship with Id %s not found", e.RelationshipID)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return commons.ErrNotFound
+}
+
+// ReservedNameError is returned when a relationship uses a name reserved for the relationships the service
+// creates internally
+type ReservedNameError struct {
+	RelationshipID string
+	Name           string
+}
+
+func (e *ReservedNameError) Error() string {
+	return fmt.Sprintf("relationship %s cannot use the name %s, it is reserved for internal relationships", e.RelationshipID, e.Name)
+}
+
+// InvalidPropertyError is returned when a relationship property cannot be stored
+type InvalidPropertyError struct {
+	Key    string
+	Reason string
+}
+
+func (e *InvalidPropertyError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("invalid relationship property: %s", e.Reason)
+	}
+	return fmt.Sprintf("relationship property '%s' %s", e.Key, e.Reason)
+}
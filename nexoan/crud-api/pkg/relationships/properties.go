@@ -0,0 +1,118 @@
+package relationships
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// reservedProperties are the relationship properties managed by the graph stores themselves.
+// User supplied relationship properties cannot overwrite them.
+var reservedProperties = map[string]bool{
+	"Id":         true,
+	"Created":    true,
+	"Terminated": true,
+}
+
+// IsReservedProperty checks if a relationship property is managed by the graph stores themselves
+func IsReservedProperty(key string) bool {
+	return reservedProperties[key]
+}
+
+// ConvertProperties converts the protobuf relationship properties into the primitive values the graph stores keep.
+// Neo4j only accepts primitive values on relationships, so only wrapper types and
+// scalar structpb values are supported.
+func ConvertProperties(properties map[string]*anypb.Any) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	for key, value := range properties {
+		if key == "" {
+			return nil, &InvalidPropertyError{Reason: "the key cannot be empty"}
+		}
+		if reservedProperties[key] {
+			return nil, &InvalidPropertyError{Key: key, Reason: "is reserved"}
+		}
+		if value == nil {
+			return nil, &InvalidPropertyError{Key: key, Reason: "has no value"}
+		}
+
+		message, err := value.UnmarshalNew()
+		if err != nil {
+			return nil, &InvalidPropertyError{Key: key, Reason: fmt.Sprintf("cannot be unpacked: %v", err)}
+		}
+
+		switch v := message.(type) {
+		case *wrapperspb.StringValue:
+			result[key] = v.Value
+		case *wrapperspb.BoolValue:
+			result[key] = v.Value
+		case *wrapperspb.Int32Value:
+			result[key] = int64(v.Value)
+		case *wrapperspb.Int64Value:
+			result[key] = v.Value
+		case *wrapperspb.UInt32Value:
+			result[key] = int64(v.Value)
+		case *wrapperspb.UInt64Value:
+			// Neo4j integers are signed 64 bit, larger values would wrap around
+			if v.Value > math.MaxInt64 {
+				return nil, &InvalidPropertyError{Key: key, Reason: fmt.Sprintf("value %d is larger than %d", v.Value, int64(math.MaxInt64))}
+			}
+			result[key] = int64(v.Value)
+		case *wrapperspb.FloatValue:
+			result[key] = float64(v.Value)
+		case *wrapperspb.DoubleValue:
+			result[key] = v.Value
+		case *structpb.Value:
+			switch kind := v.Kind.(type) {
+			case *structpb.Value_StringValue:
+				result[key] = kind.StringValue
+			case *structpb.Value_NumberValue:
+				result[key] = kind.NumberValue
+			case *structpb.Value_BoolValue:
+				result[key] = kind.BoolValue
+			default:
+				return nil, &InvalidPropertyError{Key: key, Reason: "must be a scalar value"}
+			}
+		default:
+			return nil, &InvalidPropertyError{Key: key, Reason: fmt.Sprintf("has unsupported value type %s", value.GetTypeUrl())}
+		}
+	}
+	return result, nil
+}
+
+// ConvertStoredProperties converts stored relationship properties back into protobuf values.
+// The reserved properties (Id, Created, Terminated) are skipped since they are returned
+// through the dedicated Relationship fields.
+func ConvertStoredProperties(props map[string]interface{}) map[string]*anypb.Any {
+	result := make(map[string]*anypb.Any)
+	for key, value := range props {
+		if reservedProperties[key] || value == nil {
+			continue
+		}
+
+		var anyValue *anypb.Any
+		var err error
+		switch v := value.(type) {
+		case string:
+			anyValue, err = anypb.New(wrapperspb.String(v))
+		case bool:
+			anyValue, err = anypb.New(wrapperspb.Bool(v))
+		case int64:
+			anyValue, err = anypb.New(wrapperspb.Int64(v))
+		case float64:
+			anyValue, err = anypb.New(wrapperspb.Double(v))
+		case time.Time:
+			anyValue, err = anypb.New(wrapperspb.String(v.Format(time.RFC3339)))
+		default:
+			anyValue, err = anypb.New(wrapperspb.String(fmt.Sprintf("%v", v)))
+		}
+		if err != nil {
+			continue
+		}
+		result[key] = anyValue
+	}
+	return result
+}
@@ -0,0 +1,196 @@
+// Package relationships holds the relationship type registry, the relationship integrity rules and the conversion
+// of relationship properties shared by the graph stores.
+package relationships
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// TypeRegistry holds the declared relationship types.
+// An empty registry allows any relationship, once a type is declared only declared
+// relationship names can be created. Exempt names are used for relationships the service
+// creates internally, they skip the declared types but only writes marked with WithInternalWrite can use them.
+type TypeRegistry struct {
+	mu     sync.RWMutex
+	types  map[string]*pb.RelationshipType
+	exempt map[string]bool
+}
+
+// NewTypeRegistry creates an empty relationship type registry
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		types:  make(map[string]*pb.RelationshipType),
+		exempt: make(map[string]bool),
+	}
+}
+
+// Exempt reserves the given names for internal writes, which are allowed regardless of the declared types.
+// Exempt names are kept when the registry is reloaded.
+func (reg *TypeRegistry) Exempt(names ...string) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, name := range names {
+		reg.exempt[name] = true
+	}
+}
+
+// IsExempt checks if a relationship name is reserved for internal writes
+func (reg *TypeRegistry) IsExempt(name string) bool {
+	if reg == nil {
+		return false
+	}
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	return reg.exempt[name]
+}
+
+// Load replaces the registered relationship types
+func (reg *TypeRegistry) Load(relationshipTypes []*pb.RelationshipType) error {
+	types := make(map[string]*pb.RelationshipType)
+	for _, relType := range relationshipTypes {
+		if relType == nil || relType.Name == "" {
+			return fmt.Errorf("relationship type name cannot be empty")
+		}
+		if _, exists := types[relType.Name]; exists {
+			return fmt.Errorf("relationship type %s is declared more than once", relType.Name)
+		}
+		types[relType.Name] = proto.Clone(relType).(*pb.RelationshipType)
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.types = types
+	slog.Info("Loaded relationship types", "count", len(types))
+	return nil
+}
+
+// Get returns the declared relationship type with the given name
+func (reg *TypeRegistry) Get(name string) (*pb.RelationshipType, bool) {
+	if reg == nil {
+		return nil, false
+	}
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	relType, ok := reg.types[name]
+	return relType, ok
+}
+
+// List returns the declared relationship types ordered by name
+func (reg *TypeRegistry) List() []*pb.RelationshipType {
+	if reg == nil {
+		return nil
+	}
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	result := make([]*pb.RelationshipType, 0, len(reg.types))
+	for _, relType := range reg.types {
+		result = append(result, proto.Clone(relType).(*pb.RelationshipType))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// IsEmpty checks if no relationship types are declared
+func (reg *TypeRegistry) IsEmpty() bool {
+	if reg == nil {
+		return true
+	}
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	return len(reg.types) == 0
+}
+
+// internalWriteKey marks the contexts of relationship writes made by the service itself
+type internalWriteKey struct{}
+
+// WithInternalWrite marks ctx as a write made by the service itself, such writes can use exempt relationship names
+func WithInternalWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalWriteKey{}, true)
+}
+
+// isInternalWrite checks if ctx is marked with WithInternalWrite
+func isInternalWrite(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalWriteKey{}).(bool)
+	return internal
+}
+
+// Validate checks that a relationship between the given kinds is allowed by the registry.
+// Exempt names are only allowed for internal writes.
+func (reg *TypeRegistry) Validate(ctx context.Context, rel *pb.Relationship, sourceKind *pb.Kind, targetKind *pb.Kind) error {
+	if reg.IsExempt(rel.Name) {
+		if isInternalWrite(ctx) {
+			return nil
+		}
+		return &ReservedNameError{RelationshipID: rel.Id, Name: rel.Name}
+	}
+	if reg.IsEmpty() {
+		return nil
+	}
+
+	relType, ok := reg.Get(rel.Name)
+	if !ok {
+		return &IntegrityError{
+			RelationshipID: rel.Id,
+			Rule:           TypeRule,
+			Message:        fmt.Sprintf("relationship type %s is not declared", rel.Name),
+		}
+	}
+
+	// A directed type only goes from its source kinds to its target kinds, which is the outgoing direction of
+	// the relationship
+	if relType.Direction == pb.RelationshipDirection_DIRECTED && rel.Direction != "" && rel.Direction != "OUTGOING" {
+		return &IntegrityError{
+			RelationshipID: rel.Id,
+			Rule:           TypeRule,
+			Message:        fmt.Sprintf("%s relationships are directed, the direction cannot be %s", rel.Name, rel.Direction),
+		}
+	}
+
+	allowed := matchesAnyKind(relType.SourceKinds, sourceKind) && matchesAnyKind(relType.TargetKinds, targetKind)
+	if !allowed && relType.Direction == pb.RelationshipDirection_UNDIRECTED {
+		allowed = matchesAnyKind(relType.SourceKinds, targetKind) && matchesAnyKind(relType.TargetKinds, sourceKind)
+	}
+	if !allowed {
+		return &IntegrityError{
+			RelationshipID: rel.Id,
+			Rule:           TypeRule,
+			Message: fmt.Sprintf("%s relationship is not allowed from %s to %s",
+				rel.Name, formatKind(sourceKind), formatKind(targetKind)),
+		}
+	}
+	return nil
+}
+
+// matchesAnyKind checks if a kind is in the allowed kinds, an empty list allows every kind
+func matchesAnyKind(allowed []*pb.Kind, kind *pb.Kind) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, allowedKind := range allowed {
+		if allowedKind.GetMajor() != kind.GetMajor() {
+			continue
+		}
+		if allowedKind.GetMinor() == "" || allowedKind.GetMinor() == kind.GetMinor() {
+			return true
+		}
+	}
+	return false
+}
+
+// formatKind formats a kind as Major/Minor for error messages
+func formatKind(kind *pb.Kind) string {
+	if kind.GetMinor() == "" {
+		return kind.GetMajor()
+	}
+	return kind.GetMajor() + "/" + kind.GetMinor()
+}
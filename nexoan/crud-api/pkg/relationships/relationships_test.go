@@ -0,0 +1,124 @@
+package relationships
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"lk/datafoundation/crud-api/commons"
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// fakeStore reports the selections the rules asked for and returns a conflict for the ones in conflicting
+type fakeStore struct {
+	selections  []Selection
+	conflicting func(sel Selection) bool
+	outside     []string
+}
+
+func (s *fakeStore) Overlapping(ctx context.Context, rel *Relationship, sel Selection) ([]string, error) {
+	s.selections = append(s.selections, sel)
+	if s.conflicting != nil && s.conflicting(sel) {
+		return []string{DescribeInterval("other", "2020-01-01T00:00:00Z", "")}, nil
+	}
+	return nil, nil
+}
+
+func (s *fakeStore) EndpointsOutside(ctx context.Context, rel *Relationship) ([]string, error) {
+	return s.outside, nil
+}
+
+func TestSelectionPicks(t *testing.T) {
+	assert.True(t, Selection{SourceID: "a", TargetID: "b"}.Picks("a", "b"))
+	assert.False(t, Selection{SourceID: "a", TargetID: "b"}.Picks("b", "a"))
+	assert.True(t, Selection{SourceID: "a", TargetID: "b", EitherDirection: true}.Picks("b", "a"))
+	assert.True(t, Selection{SourceID: "a"}.Picks("a", "c"))
+	assert.True(t, Selection{SourceID: "a", EitherDirection: true}.Picks("c", "a"))
+	assert.False(t, Selection{TargetID: "a"}.Picks("a", "c"))
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	rel := &Relationship{ID: "rel-1", Type: "HEADS", SourceID: "a", TargetID: "b", Created: "2021-01-01T00:00:00Z"}
+	rules := config.RelationshipIntegrityConfig{EnforceEndpointLifetimes: true, NonOverlappingTypes: []string{"HEADS"}, SingleValuedTypes: []string{" HEADS "}}
+
+	store := &fakeStore{}
+	assert.NoError(t, Validate(ctx, rules, NewTypeRegistry(), store, rel))
+	assert.Equal(t, []Selection{{SourceID: "a", TargetID: "b"}, {SourceID: "a"}}, store.selections)
+
+	var integrityErr *IntegrityError
+	err := Validate(ctx, rules, NewTypeRegistry(), &fakeStore{outside: []string{DescribeInterval("b", "2022-01-01T00:00:00Z", "")}}, rel)
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, EndpointLifetimeRule, integrityErr.Rule)
+	assert.Contains(t, err.Error(), "b [2022-01-01T00:00:00Z, open]")
+
+	err = Validate(ctx, rules, NewTypeRegistry(), &fakeStore{conflicting: func(sel Selection) bool { return sel.TargetID == "" }}, rel)
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, SingleValuedRule, integrityErr.Rule)
+
+	// Undirected types count the relationships of each endpoint in both directions
+	types := NewTypeRegistry()
+	assert.NoError(t, types.Load([]*pb.RelationshipType{{Name: "HEADS", Cardinality: pb.Cardinality_ONE_TO_ONE, Direction: pb.RelationshipDirection_UNDIRECTED}}))
+	store = &fakeStore{conflicting: func(sel Selection) bool { return sel.TargetID == "b" && sel.SourceID == "" }}
+	err = Validate(ctx, config.RelationshipIntegrityConfig{}, types, store, rel)
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, CardinalityRule, integrityErr.Rule)
+	assert.Equal(t, []Selection{{SourceID: "a", EitherDirection: true}, {TargetID: "b", EitherDirection: true}}, store.selections)
+}
+
+func TestTypeRegistryValidate(t *testing.T) {
+	ctx := context.Background()
+	types := NewTypeRegistry()
+	rel := &pb.Relationship{Id: "rel-1", Name: "WORKS_AT"}
+	assert.NoError(t, types.Validate(ctx, rel, &pb.Kind{Major: "Person"}, &pb.Kind{Major: "Organisation"}))
+
+	assert.NoError(t, types.Load([]*pb.RelationshipType{{Name: "WORKS_AT", SourceKinds: []*pb.Kind{{Major: "Person"}}}}))
+	assert.NoError(t, types.Validate(ctx, rel, &pb.Kind{Major: "Person", Minor: "Minister"}, &pb.Kind{Major: "Organisation"}))
+
+	var integrityErr *IntegrityError
+	err := types.Validate(ctx, rel, &pb.Kind{Major: "Organisation"}, &pb.Kind{Major: "Person"})
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, TypeRule, integrityErr.Rule)
+	err = types.Validate(ctx, &pb.Relationship{Id: "rel-2", Name: "ADVISES"}, nil, nil)
+	assert.True(t, errors.As(err, &integrityErr))
+
+	// A directed type is only created in its declared direction, an undirected one in either
+	assert.NoError(t, types.Validate(ctx, &pb.Relationship{Id: "rel-3", Name: "WORKS_AT", Direction: "OUTGOING"}, &pb.Kind{Major: "Person"}, &pb.Kind{Major: "Organisation"}))
+	err = types.Validate(ctx, &pb.Relationship{Id: "rel-3", Name: "WORKS_AT", Direction: "INCOMING"}, &pb.Kind{Major: "Person"}, &pb.Kind{Major: "Organisation"})
+	assert.True(t, errors.As(err, &integrityErr))
+	assert.Equal(t, TypeRule, integrityErr.Rule)
+	assert.NoError(t, types.Load([]*pb.RelationshipType{{Name: "WORKS_AT", Direction: pb.RelationshipDirection_UNDIRECTED}}))
+	assert.NoError(t, types.Validate(ctx, &pb.Relationship{Id: "rel-3", Name: "WORKS_AT", Direction: "INCOMING"}, &pb.Kind{Major: "Person"}, &pb.Kind{Major: "Organisation"}))
+
+	// Exempt names are only allowed for internal writes, even when no type is declared
+	types.Exempt("ADVISES")
+	assert.True(t, types.IsExempt("ADVISES"))
+	assert.NoError(t, types.Validate(WithInternalWrite(ctx), &pb.Relationship{Id: "rel-2", Name: "ADVISES"}, nil, nil))
+	var reservedErr *ReservedNameError
+	err = types.Validate(ctx, &pb.Relationship{Id: "rel-2", Name: "ADVISES"}, nil, nil)
+	assert.True(t, errors.As(err, &reservedErr))
+	assert.NoError(t, types.Load(nil))
+	err = types.Validate(ctx, &pb.Relationship{Id: "rel-2", Name: "ADVISES"}, nil, nil)
+	assert.True(t, errors.As(err, &reservedErr))
+}
+
+func TestConvertProperties(t *testing.T) {
+	count, err := anypb.New(wrapperspb.Int32(3))
+	assert.NoError(t, err)
+	properties, err := ConvertProperties(map[string]*anypb.Any{"count": count})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), properties["count"])
+	assert.Len(t, ConvertStoredProperties(properties), 1)
+
+	var propertyErr *InvalidPropertyError
+	_, err = ConvertProperties(map[string]*anypb.Any{"Created": count})
+	assert.True(t, errors.As(err, &propertyErr))
+	assert.True(t, IsReservedProperty("Created"))
+
+	assert.ErrorIs(t, &NotFoundError{RelationshipID: "rel-1"}, commons.ErrNotFound)
+}
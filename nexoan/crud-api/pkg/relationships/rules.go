@@ -0,0 +1,164 @@
+package relationships
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"lk/datafoundation/crud-api/db/config"
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+	"lk/datafoundation/crud-api/pkg/logging"
+)
+
+// Relationship describes the relationship being validated
+type Relationship struct {
+	ID         string
+	Type       string
+	SourceID   string
+	TargetID   string
+	Created    string
+	Terminated string // empty when the relationship is open ended
+}
+
+// Selection selects the existing relationships a rule compares against by their endpoints.
+// An empty endpoint matches any entity.
+type Selection struct {
+	SourceID string
+	TargetID string
+	// EitherDirection also selects relationships with the endpoints swapped
+	EitherDirection bool
+}
+
+// Picks checks if a relationship between the given endpoints is selected
+func (sel Selection) Picks(sourceID string, targetID string) bool {
+	match := func(source string, target string) bool {
+		return (sel.SourceID == "" || sel.SourceID == source) && (sel.TargetID == "" || sel.TargetID == target)
+	}
+	return match(sourceID, targetID) || (sel.EitherDirection && match(targetID, sourceID))
+}
+
+// Store is implemented by each graph store to look up the records the integrity rules check against
+type Store interface {
+	// Overlapping describes the other relationships of the same type as rel that are selected by sel
+	// and are active at some point while rel is, ordered by relationship ID
+	Overlapping(ctx context.Context, rel *Relationship, sel Selection) ([]string, error)
+	// EndpointsOutside describes the endpoints of rel that were created after rel starts
+	// or are terminated before it ends
+	EndpointsOutside(ctx context.Context, rel *Relationship) ([]string, error)
+}
+
+// DescribeInterval formats a record and its lifetime the way the integrity errors report conflicts
+func DescribeInterval(id string, created string, terminated string) string {
+	if terminated == "" {
+		terminated = "open"
+	}
+	return fmt.Sprintf("%s [%s, %s]", id, created, terminated)
+}
+
+// containsType checks if a relationship type is listed in a rule
+func containsType(types []string, relType string) bool {
+	for _, t := range types {
+		if strings.TrimSpace(t) == relType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the relationship against the configured integrity rules and the cardinality of its declared type
+func Validate(ctx context.Context, rules config.RelationshipIntegrityConfig, types *TypeRegistry, store Store, rel *Relationship) error {
+	logger := logging.FromContext(ctx)
+
+	if rules.EnforceEndpointLifetimes {
+		violations, err := store.EndpointsOutside(ctx, rel)
+		if err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			end := rel.Terminated
+			if end == "" {
+				end = "open"
+			}
+			logger.DebugContext(ctx, "Relationship is outside the lifetimes of its endpoints", "relationship_id", rel.ID, "violations", violations)
+			return &IntegrityError{
+				RelationshipID: rel.ID,
+				Rule:           EndpointLifetimeRule,
+				Message: fmt.Sprintf("interval [%s, %s] must lie within the lifetime of both endpoints, violated by %s",
+					rel.Created, end, strings.Join(violations, ", ")),
+			}
+		}
+	}
+
+	if containsType(rules.NonOverlappingTypes, rel.Type) {
+		conflicts, err := store.Overlapping(ctx, rel, Selection{SourceID: rel.SourceID, TargetID: rel.TargetID})
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			logger.DebugContext(ctx, "Relationship overlaps with existing relationships", "relationship_id", rel.ID, "conflicts", conflicts)
+			return &IntegrityError{
+				RelationshipID: rel.ID,
+				Rule:           NonOverlappingRule,
+				Message: fmt.Sprintf("%s relationship between %s and %s overlaps in time with existing relationships %s",
+					rel.Type, rel.SourceID, rel.TargetID, strings.Join(conflicts, ", ")),
+			}
+		}
+	}
+
+	if containsType(rules.SingleValuedTypes, rel.Type) {
+		conflicts, err := store.Overlapping(ctx, rel, Selection{SourceID: rel.SourceID})
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			logger.DebugContext(ctx, "Entity already has active relationships of the type", "entity_id", rel.SourceID, "relationship", rel.Type, "conflicts", conflicts)
+			return &IntegrityError{
+				RelationshipID: rel.ID,
+				Rule:           SingleValuedRule,
+				Message: fmt.Sprintf("entity %s can only have one active %s relationship at a time, conflicts with %s",
+					rel.SourceID, rel.Type, strings.Join(conflicts, ", ")),
+			}
+		}
+	}
+
+	if relType, ok := types.Get(rel.Type); ok {
+		return validateCardinality(ctx, store, rel, relType)
+	}
+	return nil
+}
+
+// validateCardinality checks the relationship against the cardinality of its declared type.
+// Only relationships that are active at the same time count towards the cardinality.
+func validateCardinality(ctx context.Context, store Store, rel *Relationship, relType *pb.RelationshipType) error {
+	// Undirected relationship types count relationships in both directions
+	undirected := relType.Direction == pb.RelationshipDirection_UNDIRECTED
+	cardinality := relType.Cardinality
+
+	check := func(sel Selection, entityID string) error {
+		conflicts, err := store.Overlapping(ctx, rel, sel)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return &IntegrityError{
+				RelationshipID: rel.ID,
+				Rule:           CardinalityRule,
+				Message: fmt.Sprintf("%s is %s, entity %s already has active relationships %s",
+					rel.Type, cardinality, entityID, strings.Join(conflicts, ", ")),
+			}
+		}
+		return nil
+	}
+
+	if cardinality == pb.Cardinality_ONE_TO_ONE || cardinality == pb.Cardinality_MANY_TO_ONE {
+		if err := check(Selection{SourceID: rel.SourceID, EitherDirection: undirected}, rel.SourceID); err != nil {
+			return err
+		}
+	}
+	if cardinality == pb.Cardinality_ONE_TO_ONE || cardinality == pb.Cardinality_ONE_TO_MANY {
+		if err := check(Selection{TargetID: rel.TargetID, EitherDirection: undirected}, rel.TargetID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,376 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"lk/datafoundation/crud-api/pkg/storageinference"
+	"lk/datafoundation/crud-api/pkg/typeinference"
+)
+
+// JSONSchemaDialect is the JSON Schema draft written into exported schemas
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// StorageTypeKeyword annotates the schemas of tabular and graph data, which cannot be told apart
+// from lists and maps by their JSON shape. JSON Schema validators ignore unknown keywords.
+const StorageTypeKeyword = "x-storage-type"
+
+// JSONSchema is the subset of a JSON Schema (draft 2020-12) document that maps onto SchemaInfo.
+//
+// The storage types are represented as follows:
+//   - Scalar: a primitive type, dates and times are strings with the date, time or date-time format
+//   - List: an array with the schema of its items
+//   - Map: an object with its properties, keys that cannot be null are required
+//   - Tabular: an array of row objects annotated with x-storage-type "tabular", one property per column
+//   - Graph: an object with the nodes and edges properties annotated with x-storage-type "graph"
+//
+// A nullable value adds "null" to the type.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Type        JSONSchemaType         `json:"type,omitempty"`
+	Format      string                 `json:"format,omitempty"`
+	StorageType string                 `json:"x-storage-type,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+
+	// Composition keywords are only read to reject schemas that cannot be represented
+	Ref   string        `json:"$ref,omitempty"`
+	AllOf []*JSONSchema `json:"allOf,omitempty"`
+	AnyOf []*JSONSchema `json:"anyOf,omitempty"`
+	OneOf []*JSONSchema `json:"oneOf,omitempty"`
+}
+
+// JSONSchemaType is the value of the type keyword, a single type name or a list of type names
+type JSONSchemaType []string
+
+// MarshalJSON writes a single type as a string and several types as a list
+func (t JSONSchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON reads a type name or a list of type names
+func (t *JSONSchemaType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = JSONSchemaType{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = names
+	return nil
+}
+
+// scalarTypes maps scalar data types to JSON Schema types and formats
+var scalarTypes = map[typeinference.DataType][2]string{
+	typeinference.IntType:      {"integer", ""},
+	typeinference.FloatType:    {"number", ""},
+	typeinference.StringType:   {"string", ""},
+	typeinference.BoolType:     {"boolean", ""},
+	typeinference.NullType:     {"null", ""},
+	typeinference.DateType:     {"string", "date"},
+	typeinference.TimeType:     {"string", "time"},
+	typeinference.DateTimeType: {"string", "date-time"},
+}
+
+// ToJSONSchema converts a SchemaInfo to a JSON Schema document
+func ToJSONSchema(schemaInfo *SchemaInfo) (*JSONSchema, error) {
+	document, err := toJSONSchema(schemaInfo, "#")
+	if err != nil {
+		return nil, err
+	}
+	document.Schema = JSONSchemaDialect
+	return document, nil
+}
+
+// MarshalJSONSchema converts a SchemaInfo to an indented JSON Schema document
+func MarshalJSONSchema(schemaInfo *SchemaInfo) ([]byte, error) {
+	document, err := ToJSONSchema(schemaInfo)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(document, "", "  ")
+}
+
+func toJSONSchema(schemaInfo *SchemaInfo, path string) (*JSONSchema, error) {
+	if schemaInfo == nil {
+		return nil, fmt.Errorf("%s: schema is nil", path)
+	}
+
+	var document *JSONSchema
+	switch schemaInfo.StorageType {
+	case storageinference.ScalarData:
+		if schemaInfo.TypeInfo == nil {
+			return nil, fmt.Errorf("%s: scalar schema has no type", path)
+		}
+		scalar, ok := scalarTypes[schemaInfo.TypeInfo.Type]
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported type %q", path, schemaInfo.TypeInfo.Type)
+		}
+		document = &JSONSchema{Type: JSONSchemaType{scalar[0]}, Format: scalar[1]}
+
+	case storageinference.ListData:
+		document = &JSONSchema{Type: JSONSchemaType{"array"}}
+		items := schemaInfo.Items
+		if items == nil && schemaInfo.TypeInfo != nil && schemaInfo.TypeInfo.ArrayType != nil {
+			items = &SchemaInfo{StorageType: storageinference.ScalarData, TypeInfo: schemaInfo.TypeInfo.ArrayType}
+		}
+		if items != nil {
+			itemsDocument, err := toJSONSchema(items, path+"/items")
+			if err != nil {
+				return nil, err
+			}
+			document.Items = itemsDocument
+		}
+
+	case storageinference.MapData:
+		properties, required, err := toJSONSchemaProperties(schemaInfo.Properties, path+"/properties")
+		if err != nil {
+			return nil, err
+		}
+		document = &JSONSchema{Type: JSONSchemaType{"object"}, Properties: properties, Required: required}
+
+	case storageinference.TabularData:
+		columns, required, err := toJSONSchemaProperties(schemaInfo.Fields, path+"/items/properties")
+		if err != nil {
+			return nil, err
+		}
+		document = &JSONSchema{
+			Type:        JSONSchemaType{"array"},
+			StorageType: string(storageinference.TabularData),
+			Items:       &JSONSchema{Type: JSONSchemaType{"object"}, Properties: columns, Required: required},
+		}
+
+	case storageinference.GraphData:
+		fields, required, err := toJSONSchemaProperties(schemaInfo.Fields, path+"/properties")
+		if err != nil {
+			return nil, err
+		}
+		document = &JSONSchema{
+			Type:        JSONSchemaType{"object"},
+			StorageType: string(storageinference.GraphData),
+			Properties:  fields,
+			Required:    required,
+		}
+
+	default:
+		return nil, fmt.Errorf("%s: unsupported storage type %q", path, schemaInfo.StorageType)
+	}
+
+	if schemaInfo.TypeInfo != nil && schemaInfo.TypeInfo.IsNullable && document.Type[0] != "null" {
+		document.Type = append(document.Type, "null")
+	}
+	return document, nil
+}
+
+// toJSONSchemaProperties converts the schemas of named values, the values that cannot be null are required
+func toJSONSchemaProperties(schemas map[string]*SchemaInfo, path string) (map[string]*JSONSchema, []string, error) {
+	if len(schemas) == 0 {
+		return nil, nil, nil
+	}
+	properties := make(map[string]*JSONSchema, len(schemas))
+	var required []string
+	for name, propertySchema := range schemas {
+		property, err := toJSONSchema(propertySchema, path+"/"+name)
+		if err != nil {
+			return nil, nil, err
+		}
+		properties[name] = property
+		if propertySchema.TypeInfo == nil || !propertySchema.TypeInfo.IsNullable {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(required)
+	return properties, required, nil
+}
+
+// FromJSONSchema converts a JSON Schema document to a SchemaInfo.
+// Properties that are not required are nullable, since ValidateSchema only accepts
+// missing keys for nullable values.
+func FromJSONSchema(document *JSONSchema) (*SchemaInfo, error) {
+	return fromJSONSchema(document, "#")
+}
+
+// ParseJSONSchema reads a JSON Schema document and converts it to a SchemaInfo
+func ParseJSONSchema(data []byte) (*SchemaInfo, error) {
+	var document JSONSchema
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %v", err)
+	}
+	return FromJSONSchema(&document)
+}
+
+// ParseDeclaredSchema reads the JSON Schema a client declared for an attribute.
+// Only tabular attributes have a stored schema, so the declared schema must describe a table.
+func ParseDeclaredSchema(data string) (*SchemaInfo, error) {
+	declared, err := ParseJSONSchema([]byte(data))
+	if err != nil {
+		return nil, err
+	}
+	if declared.StorageType != storageinference.TabularData {
+		return nil, fmt.Errorf("declared schemas must describe tabular data, got %s", declared.StorageType)
+	}
+	declared.Declared = true
+	return declared, nil
+}
+
+func fromJSONSchema(document *JSONSchema, path string) (*SchemaInfo, error) {
+	if document == nil {
+		return nil, fmt.Errorf("%s: schema is nil", path)
+	}
+	switch {
+	case document.Ref != "":
+		return nil, fmt.Errorf("%s: $ref is not supported", path)
+	case len(document.AllOf) > 0:
+		return nil, fmt.Errorf("%s: allOf is not supported", path)
+	case len(document.AnyOf) > 0:
+		return nil, fmt.Errorf("%s: anyOf is not supported", path)
+	case len(document.OneOf) > 0:
+		return nil, fmt.Errorf("%s: oneOf is not supported", path)
+	}
+
+	nullable := slices.Contains(document.Type, "null")
+	var types []string
+	for _, name := range document.Type {
+		if name != "null" {
+			types = append(types, name)
+		}
+	}
+	if len(types) == 0 {
+		if !nullable {
+			return nil, fmt.Errorf("%s: type is required", path)
+		}
+		types = []string{"null"}
+	}
+	if len(types) > 1 {
+		return nil, fmt.Errorf("%s: values of several types %v are not supported", path, types)
+	}
+
+	var schemaInfo *SchemaInfo
+	var err error
+	switch document.StorageType {
+	case "":
+		schemaInfo, err = fromJSONSchemaType(document, types[0], path)
+	case string(storageinference.TabularData):
+		schemaInfo, err = fromJSONSchemaTable(document, types[0], path)
+	case string(storageinference.GraphData):
+		if types[0] != "object" {
+			return nil, fmt.Errorf("%s: graph data must be an object, got %s", path, types[0])
+		}
+		schemaInfo = &SchemaInfo{StorageType: storageinference.GraphData, TypeInfo: &typeinference.TypeInfo{}}
+		schemaInfo.Fields, err = fromJSONSchemaProperties(document, path)
+	default:
+		return nil, fmt.Errorf("%s: unsupported storage type %q", path, document.StorageType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if nullable {
+		schemaInfo.TypeInfo.IsNullable = true
+	}
+	return schemaInfo, nil
+}
+
+// fromJSONSchemaType converts a schema without a storage type annotation from its JSON type
+func fromJSONSchemaType(document *JSONSchema, jsonType string, path string) (*SchemaInfo, error) {
+	switch jsonType {
+	case "object":
+		properties, err := fromJSONSchemaProperties(document, path)
+		if err != nil {
+			return nil, err
+		}
+		return &SchemaInfo{StorageType: storageinference.MapData, TypeInfo: &typeinference.TypeInfo{}, Properties: properties}, nil
+
+	case "array":
+		if document.Items == nil {
+			return nil, fmt.Errorf("%s: items is required for arrays", path)
+		}
+		items, err := fromJSONSchema(document.Items, path+"/items")
+		if err != nil {
+			return nil, err
+		}
+		typeInfo := &typeinference.TypeInfo{IsArray: true}
+		if items.StorageType == storageinference.ScalarData {
+			typeInfo.ArrayType = items.TypeInfo
+		}
+		return &SchemaInfo{StorageType: storageinference.ListData, TypeInfo: typeInfo, Items: items}, nil
+	}
+
+	var dataType typeinference.DataType
+	switch jsonType {
+	case "integer":
+		dataType = typeinference.IntType
+	case "number":
+		dataType = typeinference.FloatType
+	case "boolean":
+		dataType = typeinference.BoolType
+	case "null":
+		dataType = typeinference.NullType
+	case "string":
+		// Formats other than date, time and date-time are annotations of plain strings
+		switch document.Format {
+		case "date":
+			dataType = typeinference.DateType
+		case "time":
+			dataType = typeinference.TimeType
+		case "date-time":
+			dataType = typeinference.DateTimeType
+		default:
+			dataType = typeinference.StringType
+		}
+	default:
+		return nil, fmt.Errorf("%s: unsupported type %q", path, jsonType)
+	}
+	typeInfo := &typeinference.TypeInfo{Type: dataType, IsNullable: dataType == typeinference.NullType}
+	return &SchemaInfo{StorageType: storageinference.ScalarData, TypeInfo: typeInfo}, nil
+}
+
+// fromJSONSchemaTable converts the schema of tabular data, an array of row objects
+func fromJSONSchemaTable(document *JSONSchema, jsonType string, path string) (*SchemaInfo, error) {
+	if jsonType != "array" || document.Items == nil || !slices.Equal(document.Items.Type, JSONSchemaType{"object"}) {
+		return nil, fmt.Errorf("%s: tabular data must be an array of row objects", path)
+	}
+	columns, err := fromJSONSchemaProperties(document.Items, path+"/items")
+	if err != nil {
+		return nil, err
+	}
+	for name, column := range columns {
+		if column.StorageType != storageinference.ScalarData {
+			return nil, fmt.Errorf("%s/items/properties/%s: columns must hold scalar values", path, name)
+		}
+	}
+	return &SchemaInfo{StorageType: storageinference.TabularData, TypeInfo: &typeinference.TypeInfo{}, Fields: columns}, nil
+}
+
+// fromJSONSchemaProperties converts the properties of an object, the properties that are not required are nullable
+func fromJSONSchemaProperties(document *JSONSchema, path string) (map[string]*SchemaInfo, error) {
+	for _, name := range document.Required {
+		if _, ok := document.Properties[name]; !ok {
+			return nil, fmt.Errorf("%s: required property %s is not declared", path, name)
+		}
+	}
+	if len(document.Properties) == 0 {
+		return nil, nil
+	}
+	properties := make(map[string]*SchemaInfo, len(document.Properties))
+	for name, property := range document.Properties {
+		propertySchema, err := fromJSONSchema(property, path+"/properties/"+name)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(document.Required, name) {
+			propertySchema.TypeInfo.IsNullable = true
+		}
+		properties[name] = propertySchema
+	}
+	return properties, nil
+}
@@ -0,0 +1,136 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"lk/datafoundation/crud-api/pkg/typeinference"
+)
+
+func TestToJSONSchema(t *testing.T) {
+	table := &SchemaInfo{
+		StorageType: TabularData,
+		TypeInfo:    &typeinference.TypeInfo{Type: typeinference.StringType},
+		Fields: map[string]*SchemaInfo{
+			"id":       {StorageType: ScalarData, TypeInfo: &typeinference.TypeInfo{Type: typeinference.IntType}},
+			"name":     {StorageType: ScalarData, TypeInfo: &typeinference.TypeInfo{Type: typeinference.StringType}},
+			"budget":   {StorageType: ScalarData, TypeInfo: &typeinference.TypeInfo{Type: typeinference.FloatType, IsNullable: true}},
+			"gazetted": {StorageType: ScalarData, TypeInfo: &typeinference.TypeInfo{Type: typeinference.DateType}},
+		},
+	}
+
+	document, err := MarshalJSONSchema(table)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "array",
+		"x-storage-type": "tabular",
+		"items": {
+			"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string"},
+				"budget": {"type": ["number", "null"]},
+				"gazetted": {"type": "string", "format": "date"}
+			},
+			"required": ["gazetted", "id", "name"]
+		}
+	}`, string(document))
+
+	// The storage type annotation brings the table back
+	parsed, err := ParseJSONSchema(document)
+	assert.NoError(t, err)
+	assert.Equal(t, TabularData, string(parsed.StorageType))
+	assert.Len(t, parsed.Fields, 4)
+	assert.Equal(t, typeinference.DateType, parsed.Fields["gazetted"].TypeInfo.Type)
+	assert.True(t, parsed.Fields["budget"].TypeInfo.IsNullable)
+	assert.False(t, parsed.Fields["id"].TypeInfo.IsNullable)
+
+	_, err = ToJSONSchema(&SchemaInfo{StorageType: "unknown"})
+	assert.ErrorContains(t, err, `unsupported storage type "unknown"`)
+	_, err = ToJSONSchema(&SchemaInfo{StorageType: MapData, Properties: map[string]*SchemaInfo{"x": {StorageType: ScalarData}}})
+	assert.ErrorContains(t, err, "#/properties/x: scalar schema has no type")
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	anyValue, err := JSONToAny(`{
+		"nodes": [{"id": "n1", "label": "Ministry"}],
+		"edges": [{"source": "n1", "target": "n2", "weight": 0.5}]
+	}`)
+	assert.NoError(t, err)
+	generated, err := GenerateSchema(anyValue)
+	assert.NoError(t, err)
+	assert.Equal(t, GraphData, string(generated.StorageType))
+
+	document, err := MarshalJSONSchema(generated)
+	assert.NoError(t, err)
+	parsed, err := ParseJSONSchema(document)
+	assert.NoError(t, err)
+
+	// The converted schema describes the same data as the inferred one
+	again, err := MarshalJSONSchema(parsed)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(document), string(again))
+}
+
+func TestFromJSONSchema(t *testing.T) {
+	declared, err := ParseJSONSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "Department",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 1},
+			"headcount": {"type": "integer"},
+			"budget": {"type": "number"},
+			"established": {"type": "string", "format": "date-time"},
+			"website": {"type": "string", "format": "uri"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"parent": {"type": ["string", "null"]}
+		},
+		"required": ["name", "headcount", "budget", "tags"]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, MapData, string(declared.StorageType))
+	assert.Equal(t, typeinference.DateTimeType, declared.Properties["established"].TypeInfo.Type)
+	assert.Equal(t, typeinference.StringType, declared.Properties["website"].TypeInfo.Type)
+	assert.Equal(t, typeinference.StringType, declared.Properties["tags"].TypeInfo.ArrayType.Type)
+	assert.True(t, declared.Properties["parent"].TypeInfo.IsNullable)
+	assert.True(t, declared.Properties["established"].TypeInfo.IsNullable)
+
+	// Values decoded from JSON validate against the declared schema
+	validate := func(value string) error {
+		var decoded interface{}
+		assert.NoError(t, json.Unmarshal([]byte(value), &decoded))
+		return ValidateSchema(decoded, declared)
+	}
+	assert.NoError(t, validate(`{"name": "Finance", "headcount": 12, "budget": 1000, "tags": ["core"], "parent": null}`))
+	assert.NoError(t, validate(`{"name": "Finance", "headcount": 12, "budget": 10.5, "tags": [], "established": "2024-01-01T00:00:00Z"}`))
+	assert.ErrorContains(t, validate(`{"name": "Finance", "headcount": 1.5, "budget": 1, "tags": []}`), "invalid value for key headcount")
+	assert.ErrorContains(t, validate(`{"name": "Finance", "headcount": 1, "budget": 1, "tags": [], "established": "soon"}`), "expected datetime")
+	assert.ErrorContains(t, validate(`{"name": "Finance", "headcount": 1, "budget": 1}`), "required key tags is missing")
+
+	testCases := map[string]struct {
+		document string
+		err      string
+	}{
+		"reference":         {`{"$ref": "#/$defs/row"}`, "#: $ref is not supported"},
+		"union":             {`{"type": "object", "properties": {"id": {"anyOf": [{"type": "string"}, {"type": "integer"}]}}}`, "#/properties/id: anyOf is not supported"},
+		"several types":     {`{"type": ["string", "integer"]}`, "values of several types"},
+		"missing type":      {`{"properties": {}}`, "#: type is required"},
+		"missing items":     {`{"type": "array"}`, "items is required"},
+		"undeclared":        {`{"type": "object", "required": ["id"]}`, "required property id is not declared"},
+		"table of scalars":  {`{"type": "array", "x-storage-type": "tabular", "items": {"type": "string"}}`, "array of row objects"},
+		"nested column":     {`{"type": "array", "x-storage-type": "tabular", "items": {"type": "object", "properties": {"tags": {"type": "array", "items": {"type": "string"}}}}}`, "columns must hold scalar values"},
+		"unknown storage":   {`{"type": "object", "x-storage-type": "blob"}`, `unsupported storage type "blob"`},
+		"unknown type":      {`{"type": "tuple"}`, `unsupported type "tuple"`},
+		"invalid type list": {`{"type": 1}`, "type must be a string or a list of strings"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseJSONSchema([]byte(tc.document))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
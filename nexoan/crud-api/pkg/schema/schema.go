@@ -179,12 +179,14 @@ import (
 //   - Fields: For tabular/graph data, contains schemas for each field
 //   - Items: For list data, contains the schema for list items
 //   - Properties: For map data, contains schemas for each property
+//   - Declared: Set on the root of a schema declared by a client instead of inferred
 type SchemaInfo struct {
 	StorageType storageinference.StorageType // The storage type (tabular, graph, list, map, scalar)
 	TypeInfo    *typeinference.TypeInfo      // The type information
 	Fields      map[string]*SchemaInfo       // For tabular/graph data, contains field schemas
 	Items       *SchemaInfo                  // For list data, contains item schema
 	Properties  map[string]*SchemaInfo       // For map data, contains property schemas
+	Declared    bool                         `json:",omitempty"` // Writes are validated against a declared schema instead of extending it
 }
 
 // SchemaGenerator combines storage and type inference to generate complete schema information.
@@ -324,7 +326,6 @@ func (sg *SchemaGenerator) GenerateSchema(anyValue *anypb.Any) (*SchemaInfo, err
 				TypeInfo:    &typeinference.TypeInfo{Type: typeinference.BoolType},
 			}, nil
 		default:
-			fmt.Printf("Schema generator hit default case with message type: %T, message: %+v\n", message, message)
 			return nil, fmt.Errorf("expected struct value or supported wrapper type, got %T", message)
 		}
 	}
@@ -486,18 +487,18 @@ func isDateOrDateTime(str string) (bool, bool) {
 //  1. Validates the presence of both columns and rows fields
 //  2. Verifies that columns is a list of strings
 //  3. Verifies that rows is a list of lists
-//  4. Processes the first row to determine column types
+//  4. Scans every row to determine column types (see typeinference.InferColumnTypes)
 //  5. Creates field schemas for each column based on its data type
 //
 // The function handles the following data types:
-//   - String: Regular text or date/datetime values
+//   - String: Regular text or date/time/datetime values
 //   - Number: Integer or floating-point values
 //   - Boolean: True/false values
-//   - Null: Nullable fields
+//   - Null: Makes the column nullable
 //
-// For date/datetime detection:
-//   - Date format: YYYY-MM-DD
-//   - DateTime format: RFC3339 (YYYY-MM-DDTHH:MM:SSZ)
+// Columns mixing types are widened along null < bool < int < float < string and
+// null < date < datetime < string, so a column holding 1 and 1.5 is a float column.
+// Lists and objects in cells are reported with their rows in a *typeinference.ColumnConflictError.
 //
 // Parameters:
 //   - structValue: The protobuf struct value containing tabular data
@@ -584,58 +585,20 @@ func (sg *SchemaGenerator) handleTabularData(structValue *structpb.Struct, schem
 		}
 	}
 
-	// Process first row to determine types
 	if len(rowsList.ListValue.Values) == 0 {
 		return nil, fmt.Errorf("table must have at least one row")
 	}
 
-	firstRow := rowsList.ListValue.Values[0]
-	rowValues, ok := firstRow.GetKind().(*structpb.Value_ListValue)
-	if !ok {
-		return nil, fmt.Errorf("row must be a list")
-	}
-
-	if len(rowValues.ListValue.Values) != len(columnNames) {
-		return nil, fmt.Errorf("row length does not match number of columns")
+	// Infer the type of each column from all of its values
+	columnTypes, err := typeinference.InferColumnTypes(columnNames, rowsList.ListValue.Values)
+	if err != nil {
+		return nil, err
 	}
-
-	// Create field schemas based on first row values
-	for i, value := range rowValues.ListValue.Values {
-		columnName := columnNames[i]
-		fieldSchema := &SchemaInfo{
+	for columnName, typeInfo := range columnTypes {
+		schema.Fields[columnName] = &SchemaInfo{
 			StorageType: storageinference.ScalarData,
-			TypeInfo:    &typeinference.TypeInfo{},
+			TypeInfo:    typeInfo,
 		}
-
-		switch value.GetKind().(type) {
-		case *structpb.Value_StringValue:
-			str := value.GetStringValue()
-			if isDate, isDateTime := isDateOrDateTime(str); isDate {
-				if isDateTime {
-					fieldSchema.TypeInfo.Type = typeinference.DateTimeType
-				} else {
-					fieldSchema.TypeInfo.Type = typeinference.DateType
-				}
-			} else {
-				fieldSchema.TypeInfo.Type = typeinference.StringType
-			}
-		case *structpb.Value_NumberValue:
-			num := value.GetNumberValue()
-			if num == float64(int64(num)) {
-				fieldSchema.TypeInfo.Type = typeinference.IntType
-			} else {
-				fieldSchema.TypeInfo.Type = typeinference.FloatType
-			}
-		case *structpb.Value_BoolValue:
-			fieldSchema.TypeInfo.Type = typeinference.BoolType
-		case *structpb.Value_NullValue:
-			fieldSchema.TypeInfo.Type = typeinference.NullType
-			fieldSchema.TypeInfo.IsNullable = true
-		default:
-			return nil, fmt.Errorf("unsupported value type in row: of type %v", value.GetKind())
-		}
-
-		schema.Fields[columnName] = fieldSchema
 	}
 
 	return schema, nil
@@ -555,6 +555,43 @@ func TestSchemaGeneration(t *testing.T) {
 				}
 			}`,
 		},
+		"tabular_data_with_mixed_columns": {
+			input: `{
+				"columns": ["id", "score", "joined"],
+				"rows": [
+					[1, null, "2024-01-01"],
+					[2, 1, "2024-01-02T10:00:00Z"],
+					[3, 1.5, "2024-01-03"]
+				]
+			}`,
+			expected: `{
+				"storage_type": "tabular",
+				"type_info": {
+					"type": "string"
+				},
+				"fields": {
+					"id": {
+						"storage_type": "scalar",
+						"type_info": {
+							"type": "int"
+						}
+					},
+					"score": {
+						"storage_type": "scalar",
+						"type_info": {
+							"type": "float",
+							"is_nullable": true
+						}
+					},
+					"joined": {
+						"storage_type": "scalar",
+						"type_info": {
+							"type": "datetime"
+						}
+					}
+				}
+			}`,
+		},
 	}
 
 	generator := NewSchemaGenerator()
@@ -3,7 +3,10 @@ package schema
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
 
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -233,17 +236,42 @@ func validateScalarValue(value interface{}, typeInfo *typeinference.TypeInfo) er
 			return fmt.Errorf("expected string, got %T", value)
 		}
 	case typeinference.IntType:
-		if _, ok := value.(int); !ok {
+		// Values decoded from JSON or protobuf structs carry every number as a float64
+		switch v := value.(type) {
+		case int, int32, int64:
+		case float64:
+			if v != math.Trunc(v) {
+				return fmt.Errorf("expected int, got %v", v)
+			}
+		default:
 			return fmt.Errorf("expected int, got %T", value)
 		}
 	case typeinference.FloatType:
-		if _, ok := value.(float64); !ok {
+		switch value.(type) {
+		case float64, float32, int, int32, int64:
+		default:
 			return fmt.Errorf("expected float, got %T", value)
 		}
 	case typeinference.BoolType:
 		if _, ok := value.(bool); !ok {
 			return fmt.Errorf("expected bool, got %T", value)
 		}
+	case typeinference.DateType, typeinference.TimeType, typeinference.DateTimeType:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected %s, got %T", typeInfo.Type, value)
+		}
+		valid := typeinference.IsDate
+		if typeInfo.Type == typeinference.TimeType {
+			valid = typeinference.IsTime
+		} else if typeInfo.Type == typeinference.DateTimeType {
+			valid = typeinference.IsDateTime
+		}
+		if !valid(str) {
+			return fmt.Errorf("expected %s, got %q", typeInfo.Type, str)
+		}
+	case typeinference.NullType:
+		return fmt.Errorf("expected null, got %T", value)
 	default:
 		return fmt.Errorf("unsupported type: %v", typeInfo.Type)
 	}
@@ -389,6 +417,88 @@ func validateTabularValue(value interface{}, schema *SchemaInfo) error {
 	return nil
 }
 
+// maxViolations caps the violations reported for a table so that a bad bulk write stays readable
+const maxViolations = 20
+
+// ValidationError is returned when a value does not match its declared schema
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("value does not match its declared schema: %s", strings.Join(e.Violations, "; "))
+}
+
+// ValidateTable validates the columns and rows of a tabular value against a declared table schema.
+// Every column must be declared, the declared columns that cannot be null must be present and every
+// cell must match the type of its column. Integers are accepted in float columns.
+func ValidateTable(value *anypb.Any, table *SchemaInfo) error {
+	if table == nil || table.StorageType != TabularData {
+		return fmt.Errorf("schema is not a table schema")
+	}
+	var tabularStruct structpb.Struct
+	if err := value.UnmarshalTo(&tabularStruct); err != nil {
+		return &ValidationError{Violations: []string{"value is not tabular, expected columns and rows"}}
+	}
+	columnsValue := tabularStruct.Fields["columns"].GetListValue()
+	rowsValue := tabularStruct.Fields["rows"].GetListValue()
+	if columnsValue == nil || rowsValue == nil {
+		return &ValidationError{Violations: []string{"value is not tabular, expected columns and rows"}}
+	}
+
+	var violations []string
+	columns := make([]string, len(columnsValue.Values))
+	present := make(map[string]bool)
+	for i, column := range columnsValue.Values {
+		columns[i] = column.GetStringValue()
+		switch {
+		case present[columns[i]]:
+			violations = append(violations, fmt.Sprintf("column %s is given more than once", columns[i]))
+		case table.Fields[columns[i]] == nil:
+			violations = append(violations, fmt.Sprintf("column %s is not declared", columns[i]))
+		}
+		present[columns[i]] = true
+	}
+	var missing []string
+	for name, field := range table.Fields {
+		if !present[name] && (field.TypeInfo == nil || !field.TypeInfo.IsNullable) {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		violations = append(violations, fmt.Sprintf("column %s is missing", name))
+	}
+
+	for i, row := range rowsValue.Values {
+		if len(violations) > maxViolations {
+			break
+		}
+		cells := row.GetListValue()
+		if cells == nil || len(cells.Values) != len(columns) {
+			violations = append(violations, fmt.Sprintf("row %d must be a list of %d values", i, len(columns)))
+			continue
+		}
+		for j, cell := range cells.Values {
+			field := table.Fields[columns[j]]
+			if field == nil || field.TypeInfo == nil {
+				continue
+			}
+			if err := validateScalarValue(cell.AsInterface(), field.TypeInfo); err != nil {
+				violations = append(violations, fmt.Sprintf("row %d, column %s: %v", i, columns[j], err))
+			}
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	if len(violations) > maxViolations {
+		violations = append(violations[:maxViolations], "further violations are not reported")
+	}
+	return &ValidationError{Violations: violations}
+}
+
 // generateSchema generates schema information for a value
 func GenerateSchema(value *anypb.Any) (*SchemaInfo, error) {
 	// Generate schema directly from the Any value
@@ -396,31 +506,19 @@ func GenerateSchema(value *anypb.Any) (*SchemaInfo, error) {
 	return schemaGenerator.GenerateSchema(value)
 }
 
-// logSchemaInfo logs schema information in a readable format
+// LogSchemaInfo logs schema information at the debug level
 func LogSchemaInfo(schemaInfo *SchemaInfo) {
 	if schemaInfo == nil {
-		log.Printf("Schema is nil")
+		slog.Debug("Schema is nil")
 		return
 	}
 
-	// Log the schema information
-	log.Printf("Schema: StorageType=%v, TypeInfo=%v",
-		schemaInfo.StorageType,
-		schemaInfo.TypeInfo)
-
 	// Convert schema to JSON for logging
 	schemaJSON, err := SchemaInfoToJSON(schemaInfo)
 	if err != nil {
-		log.Printf("Failed to convert schema to JSON: %v", err)
-		return
-	}
-
-	// Marshal to pretty JSON for better readability
-	prettyJSON, err := json.MarshalIndent(schemaJSON, "", "  ")
-	if err != nil {
-		log.Printf("Failed to marshal schema to JSON: %v", err)
+		slog.Warn("Failed to convert schema to JSON", "error", err)
 		return
 	}
 
-	log.Printf("Schema JSON:\n%s", string(prettyJSON))
+	slog.Debug("Schema", "storage_type", schemaInfo.StorageType, "schema", schemaJSON)
 }
@@ -582,3 +582,63 @@ func TestValidateTabularData(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTable(t *testing.T) {
+	declared, err := ParseDeclaredSchema(`{
+		"type": "array",
+		"x-storage-type": "tabular",
+		"items": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"grade": {"type": "number"},
+				"count": {"type": "integer"},
+				"joined": {"type": "string", "format": "date"}
+			},
+			"required": ["name", "grade", "count"]
+		}
+	}`)
+	assert.NoError(t, err)
+	assert.True(t, declared.Declared)
+
+	table := func(columns []interface{}, rows ...interface{}) *anypb.Any {
+		value, err := structpb.NewStruct(map[string]interface{}{"columns": columns, "rows": rows})
+		assert.NoError(t, err)
+		anyValue, err := anypb.New(value)
+		assert.NoError(t, err)
+		return anyValue
+	}
+
+	// Integers are valid floats and nullable columns can be left out or null
+	assert.NoError(t, ValidateTable(table([]interface{}{"name", "grade", "count"}, []interface{}{"Alice", 3, 1}), declared))
+	assert.NoError(t, ValidateTable(table([]interface{}{"name", "grade", "count", "joined"}, []interface{}{"Bob", 2.5, 2, nil}), declared))
+
+	err = ValidateTable(table([]interface{}{"name", "grade", "team"},
+		[]interface{}{"Carol", 1.5, "ops"},
+		[]interface{}{"Dan", "high", "ops"},
+		[]interface{}{"Eve"},
+	), declared)
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []string{
+		"column team is not declared",
+		"column count is missing",
+		"row 1, column grade: expected float, got string",
+		"row 2 must be a list of 3 values",
+	}, validationErr.Violations)
+
+	err = ValidateTable(table([]interface{}{"name", "grade", "count"}, []interface{}{"Frank", 1, 1.5}), declared)
+	assert.ErrorContains(t, err, "row 0, column count: expected int, got 1.5")
+
+	notTabular, err := anypb.New(&structpb.Struct{Fields: map[string]*structpb.Value{"value": structpb.NewStringValue("x")}})
+	assert.NoError(t, err)
+	assert.ErrorContains(t, ValidateTable(notTabular, declared), "value is not tabular")
+
+	// Violations are capped for large writes
+	rows := make([]interface{}, 50)
+	for i := range rows {
+		rows[i] = []interface{}{"Grace", "high", 1}
+	}
+	assert.ErrorAs(t, ValidateTable(table([]interface{}{"name", "grade", "count"}, rows...), declared), &validationErr)
+	assert.Len(t, validationErr.Violations, maxViolations+1)
+}
@@ -0,0 +1,189 @@
+// Package search tokenizes and ranks full-text matches over entity names, metadata values and
+// the string cells of tabular attributes. Every store normalizes text the same way, lowercased and
+// with accents removed, so that a query finds the same entities whichever backend holds them.
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxMatches is the number of matches a store returns at most for one query
+const MaxMatches = 1000
+
+// Fields a match can be found in, metadata and attribute fields are followed by the key or the attribute and column
+const (
+	NameField            = "name"
+	MetadataFieldPrefix  = "metadata."
+	AttributeFieldPrefix = "attributes."
+)
+
+// Field weights, a term found in the name counts more than one found in metadata or in a table
+var fieldWeights = []struct {
+	prefix string
+	weight float64
+}{
+	{NameField, 3},
+	{MetadataFieldPrefix, 2},
+	{AttributeFieldPrefix, 1},
+}
+
+// MetadataField returns the field of a match found in a metadata value
+func MetadataField(key string) string {
+	return MetadataFieldPrefix + key
+}
+
+// AttributeField returns the field of a match found in a column of a tabular attribute
+func AttributeField(attrName, column string) string {
+	return AttributeFieldPrefix + attrName + "." + column
+}
+
+// Normalize lowercases text and removes its accents, so that "Mahaweli Ganga" and "MAHAWELI GAṄGA" compare equal
+func Normalize(text string) string {
+	// A transformer keeps state between calls, so a new one is needed every time
+	folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(folder, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.ToLower(folded)
+}
+
+// Tokenize splits normalized text into words made of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Query is a full-text query, an entity matches when a single field contains every term
+type Query struct {
+	Terms []string
+	// Kinds restricts the matches to entities of one of these kinds, a kind without a minor matches any minor
+	Kinds []*pb.Kind
+}
+
+// NewQuery tokenizes the text of a query, it fails when the text has no words
+func NewQuery(text string, kinds []*pb.Kind) (Query, error) {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(text) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return Query{}, fmt.Errorf("search query %q has no words", text)
+	}
+	for _, kind := range kinds {
+		if kind.GetMajor() == "" {
+			return Query{}, fmt.Errorf("search kinds must have a major kind")
+		}
+	}
+	return Query{Terms: terms, Kinds: kinds}, nil
+}
+
+// String returns the terms of the query separated by spaces
+func (q Query) String() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// MatchesKind reports whether an entity of the given kind can be returned by the query
+func (q Query) MatchesKind(kind *pb.Kind) bool {
+	if len(q.Kinds) == 0 {
+		return true
+	}
+	for _, allowed := range q.Kinds {
+		if allowed.GetMajor() == kind.GetMajor() && (allowed.GetMinor() == "" || allowed.GetMinor() == kind.GetMinor()) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesText reports whether every term of the query is a word of the text
+func (q Query) MatchesText(text string) bool {
+	words := make(map[string]bool)
+	for _, word := range Tokenize(text) {
+		words[word] = true
+	}
+	for _, term := range q.Terms {
+		if !words[term] {
+			return false
+		}
+	}
+	return true
+}
+
+// Match is a field of an entity that contains every term of a query
+type Match struct {
+	EntityID string
+	Field    string
+	Text     string
+}
+
+// Hit is an entity found by a query with the fields it was found in, best match first
+type Hit struct {
+	EntityID string
+	Score    float64
+	Matches  []Match
+}
+
+// fieldWeight returns the weight of a field
+func fieldWeight(field string) float64 {
+	for _, fieldWeight := range fieldWeights {
+		if strings.HasPrefix(field, fieldWeight.prefix) {
+			return fieldWeight.weight
+		}
+	}
+	return 1
+}
+
+// score rates a match, the terms count more in short texts than in long ones
+func score(match Match) float64 {
+	return fieldWeight(match.Field) / math.Sqrt(float64(len(Tokenize(match.Text))))
+}
+
+// Rank groups matches by entity and orders the entities by the sum of the scores of their matches.
+// Entities with the same score are ordered by ID so that the order is stable.
+func Rank(matches []Match) []Hit {
+	hits := make(map[string]*Hit)
+	for _, match := range matches {
+		hit, ok := hits[match.EntityID]
+		if !ok {
+			hit = &Hit{EntityID: match.EntityID}
+			hits[match.EntityID] = hit
+		}
+		hit.Score += score(match)
+		hit.Matches = append(hit.Matches, match)
+	}
+
+	ranked := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		sort.SliceStable(hit.Matches, func(i, j int) bool {
+			si, sj := score(hit.Matches[i]), score(hit.Matches[j])
+			if si != sj {
+				return si > sj
+			}
+			return hit.Matches[i].Field < hit.Matches[j].Field
+		})
+		ranked = append(ranked, *hit)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].EntityID < ranked[j].EntityID
+	})
+	return ranked
+}
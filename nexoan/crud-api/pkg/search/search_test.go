@@ -0,0 +1,113 @@
+package search
+
+import (
+	"testing"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func mustAny(t *testing.T, message interface{}) *anypb.Any {
+	var value *anypb.Any
+	var err error
+	switch m := message.(type) {
+	case string:
+		value, err = anypb.New(wrapperspb.String(m))
+	case int64:
+		value, err = anypb.New(wrapperspb.Int64(m))
+	case bool:
+		value, err = anypb.New(wrapperspb.Bool(m))
+	case map[string]interface{}:
+		var structValue *structpb.Struct
+		structValue, err = structpb.NewStruct(m)
+		assert.NoError(t, err)
+		value, err = anypb.New(structValue)
+	}
+	assert.NoError(t, err)
+	return value
+}
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, "sri jayawardenepura kotte", Normalize("Sri Jayawardenepura KÖTTE"))
+	assert.Equal(t, []string{"ministry", "of", "defence", "2024"}, Tokenize("Ministry of Défence (2024)"))
+	assert.Equal(t, []string{"mahaweli", "ganga"}, Tokenize("  MAHAWELI—Gaṅgā "))
+	assert.Empty(t, Tokenize(" -- "))
+}
+
+func TestNewQuery(t *testing.T) {
+	query, err := NewQuery("Défence defence ministry", []*pb.Kind{{Major: "Organisation"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"defence", "ministry"}, query.Terms)
+	assert.Equal(t, "defence ministry", query.String())
+
+	_, err = NewQuery(" ?! ", nil)
+	assert.Error(t, err)
+	_, err = NewQuery("defence", []*pb.Kind{{Minor: "Ministry"}})
+	assert.Error(t, err)
+}
+
+func TestQueryMatches(t *testing.T) {
+	query, err := NewQuery("ministry DEFENCE", []*pb.Kind{{Major: "Organisation", Minor: "Ministry"}, {Major: "Person"}})
+	assert.NoError(t, err)
+
+	assert.True(t, query.MatchesText("Ministry of Défence"))
+	assert.False(t, query.MatchesText("Ministry of Finance"))
+	assert.False(t, query.MatchesText("Ministry of Defences"), "Expected terms to match whole words")
+
+	assert.True(t, query.MatchesKind(&pb.Kind{Major: "Organisation", Minor: "Ministry"}))
+	assert.True(t, query.MatchesKind(&pb.Kind{Major: "Person", Minor: "Citizen"}))
+	assert.False(t, query.MatchesKind(&pb.Kind{Major: "Organisation", Minor: "Department"}))
+	assert.True(t, Query{Terms: query.Terms}.MatchesKind(&pb.Kind{Major: "Anything"}))
+}
+
+func TestValueText(t *testing.T) {
+	assert.Equal(t, "Colombo", ValueText(mustAny(t, "Colombo")))
+	assert.Equal(t, "42", ValueText(mustAny(t, int64(42))))
+	assert.Equal(t, "", ValueText(mustAny(t, true)))
+	assert.Equal(t, "5.5 Colombo Western", ValueText(mustAny(t, map[string]interface{}{
+		"province": "Western",
+		"city":     "Colombo",
+		"area":     5.5,
+		"capital":  true,
+	})))
+	assert.Equal(t, "", ValueText(nil))
+}
+
+func TestMatchMetadata(t *testing.T) {
+	query, err := NewQuery("colombo", nil)
+	assert.NoError(t, err)
+	matches := query.MatchMetadata("district_1", map[string]*anypb.Any{
+		"city":    mustAny(t, "Colombo"),
+		"address": mustAny(t, map[string]interface{}{"street": "Galle Road", "city": "COLOMBO 03"}),
+		"code":    mustAny(t, "CMB"),
+	})
+	assert.Equal(t, []Match{
+		{EntityID: "district_1", Field: "metadata.address", Text: "COLOMBO 03 Galle Road"},
+		{EntityID: "district_1", Field: "metadata.city", Text: "Colombo"},
+	}, matches)
+}
+
+func TestRank(t *testing.T) {
+	hits := Rank([]Match{
+		{EntityID: "table_only", Field: AttributeField("staff", "name"), Text: "Defence"},
+		{EntityID: "long_name", Field: NameField, Text: "Ministry of Defence and Urban Development"},
+		{EntityID: "short_name", Field: NameField, Text: "Defence"},
+		{EntityID: "short_name", Field: MetadataField("mandate"), Text: "National defence"},
+		{EntityID: "metadata_only", Field: MetadataField("mandate"), Text: "Defence"},
+	})
+
+	var ids []string
+	for _, hit := range hits {
+		ids = append(ids, hit.EntityID)
+	}
+	assert.Equal(t, []string{"short_name", "metadata_only", "long_name", "table_only"}, ids)
+	assert.Equal(t, NameField, hits[0].Matches[0].Field, "Expected the best match of a hit first")
+	assert.Len(t, hits[0].Matches, 2)
+	assert.Greater(t, hits[0].Score, hits[1].Score)
+
+	assert.Empty(t, Rank(nil))
+}
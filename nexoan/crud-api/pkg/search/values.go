@@ -0,0 +1,88 @@
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ValueText returns the searchable text of a metadata value.
+// Strings and numbers are searchable, the strings and numbers of structs and lists are joined with spaces
+// and any other value has no text.
+func ValueText(value *anypb.Any) string {
+	if value == nil {
+		return ""
+	}
+	message, err := value.UnmarshalNew()
+	if err != nil {
+		return ""
+	}
+	switch v := message.(type) {
+	case *wrapperspb.StringValue:
+		return v.Value
+	case *wrapperspb.Int32Value:
+		return strconv.FormatInt(int64(v.Value), 10)
+	case *wrapperspb.Int64Value:
+		return strconv.FormatInt(v.Value, 10)
+	case *wrapperspb.UInt32Value:
+		return strconv.FormatUint(uint64(v.Value), 10)
+	case *wrapperspb.UInt64Value:
+		return strconv.FormatUint(v.Value, 10)
+	case *wrapperspb.FloatValue:
+		return strconv.FormatFloat(float64(v.Value), 'f', -1, 32)
+	case *wrapperspb.DoubleValue:
+		return strconv.FormatFloat(v.Value, 'f', -1, 64)
+	case *structpb.Struct:
+		return structText(structpb.NewStructValue(v))
+	case *structpb.ListValue:
+		return structText(structpb.NewListValue(v))
+	case *structpb.Value:
+		return structText(v)
+	default:
+		return ""
+	}
+}
+
+// structText joins the strings and numbers of a struct value, the fields of a struct are visited in key order
+func structText(value *structpb.Value) string {
+	var parts []string
+	switch v := value.GetKind().(type) {
+	case *structpb.Value_StringValue:
+		parts = append(parts, v.StringValue)
+	case *structpb.Value_NumberValue:
+		parts = append(parts, strconv.FormatFloat(v.NumberValue, 'f', -1, 64))
+	case *structpb.Value_ListValue:
+		for _, item := range v.ListValue.GetValues() {
+			parts = append(parts, structText(item))
+		}
+	case *structpb.Value_StructValue:
+		keys := make([]string, 0, len(v.StructValue.GetFields()))
+		for key := range v.StructValue.GetFields() {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parts = append(parts, structText(v.StructValue.Fields[key]))
+		}
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
+
+// MatchMetadata returns a match for every metadata value of an entity that contains every term of the query
+func (q Query) MatchMetadata(entityID string, metadata map[string]*anypb.Any) []Match {
+	var matches []Match
+	for key, value := range metadata {
+		text := ValueText(value)
+		if text != "" && q.MatchesText(text) {
+			matches = append(matches, Match{EntityID: entityID, Field: MetadataField(key), Text: text})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Field < matches[j].Field
+	})
+	return matches
+}
@@ -3,6 +3,8 @@ package storageinference
 import (
 	"fmt"
 
+	"lk/datafoundation/crud-api/pkg/geo"
+
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -16,7 +18,11 @@ const (
 	ListData    StorageType = "list"
 	MapData     StorageType = "map"
 	GraphData   StorageType = "graph"
+	BlobData    StorageType = "blob"
 	UnknownData StorageType = "unknown"
+
+	// GeospatialData holds a GeoJSON geometry or feature
+	GeospatialData StorageType = "geospatial"
 )
 
 // TypeInferrer provides functionality to infer data types from protobuf Any values
@@ -31,28 +37,45 @@ type StorageInferrer struct{}
 //
 //   - Handles any unmarshaling errors that might occur
 //
-// 2. For structpb.Struct messages:
+// 2. Typed values are stored by their message type, their shape is not looked at:
+//   - TabularValue returns TabularData
+//   - GraphValue returns GraphData
+//   - DocumentValue returns MapData
+//   - ScalarValue returns ScalarData
+//   - BlobValue returns BlobData
+//
+// 3. For structpb.Struct messages, as a fallback:
+//   - Checks for a GeoJSON geometry or feature (see geo.IsGeoJSON)
 //   - Checks for tabular structure (has both "columns" and "rows" fields)
 //   - Checks for graph structure (has both "nodes" and "edges" fields)
 //   - Checks for list structure (has "value" field with ListValue)
 //   - Checks for scalar structure (single field with scalar value)
 //   - If none of the above, defaults to MapData
 //
-// 3. For non-structpb.Struct messages:
+// 4. For non-structpb.Struct messages:
 //   - Uses reflection to determine the type:
 //   - Slice/Array types return ListData
 //   - Map types return MapData
 //   - All other types return ScalarData
 //
 // The function returns one of the following StorageType values:
+// - GeospatialData: For GeoJSON geometries and features
 // - TabularData: For data with columns and rows structure
 // - GraphData: For data with nodes and edges structure
 // - ListData: For array-like data structures
 // - MapData: For key-value pair structures
 // - ScalarData: For single value data
+// - BlobData: For binary content, only as a BlobValue
 //
 // Example JSON structures for each type:
 //
+// GeospatialData:
+//
+//	{
+//	  "type": "Polygon",
+//	  "coordinates": [[[79.8, 6.8], [80.0, 6.8], [80.0, 7.0], [79.8, 6.8]]]
+//	}
+//
 // TabularData:
 //
 //	{
@@ -97,6 +120,11 @@ func (si *StorageInferrer) InferType(anyValue *anypb.Any) (StorageType, error) {
 		return UnknownData, err
 	}
 
+	// Typed values are routed by their message type
+	if storageType, ok := typedStorageType(message); ok {
+		return storageType, nil
+	}
+
 	// Get the struct value from the message
 	structValue, ok := message.(*structpb.Struct)
 	if !ok {
@@ -104,14 +132,20 @@ func (si *StorageInferrer) InferType(anyValue *anypb.Any) (StorageType, error) {
 	}
 
 	// Check storage types in order of precedence:
-	// 1. Tabular (highest priority)
-	// 2. Graph
-	// 3. List
-	// 4. Scalar
-	// 5. Map
-	// 6. Unknown (lowest priority)
-
-	// Check for tabular data first (highest priority)
+	// 1. Geospatial (highest priority)
+	// 2. Tabular
+	// 3. Graph
+	// 4. List
+	// 5. Scalar
+	// 6. Map
+	// 7. Unknown (lowest priority)
+
+	// GeoJSON is checked first since a geometry would otherwise be stored as a map
+	if geo.IsGeoJSON(structValue) {
+		return GeospatialData, nil
+	}
+
+	// Check for tabular data
 	if isTabular(structValue) {
 		return TabularData, nil
 	}
@@ -119,6 +119,33 @@ func TestStorageTypeInferenceFromEntity(t *testing.T) {
 	}
 }
 
+// TestGeospatialEntity tests GeoJSON geometries and features
+func TestGeospatialEntity(t *testing.T) {
+	testCases := map[string]struct {
+		json     string
+		expected StorageType
+	}{
+		"point":      {`{"type": "Point", "coordinates": [79.86, 6.93]}`, GeospatialData},
+		"polygon":    {`{"type": "Polygon", "coordinates": [[[79.8, 6.8], [80.0, 6.8], [80.0, 7.0], [79.8, 6.8]]]}`, GeospatialData},
+		"feature":    {`{"type": "Feature", "geometry": {"type": "Point", "coordinates": [79.86, 6.93]}, "properties": {"name": "Colombo"}}`, GeospatialData},
+		"collection": {`{"type": "GeometryCollection", "geometries": [{"type": "Point", "coordinates": [0, 0]}]}`, GeospatialData},
+		// A type field alone does not make a geometry
+		"typed_map": {`{"type": "Point", "name": "office"}`, MapData},
+	}
+
+	inferrer := &StorageInferrer{}
+	for testName, tc := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			anyValue, err := JSONToAny(tc.json)
+			assert.NoError(t, err)
+
+			detectedType, err := inferrer.InferType(anyValue)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, detectedType)
+		})
+	}
+}
+
 // TestNestedListEntity tests a nested list structure
 func TestNestedListEntity(t *testing.T) {
 	nestedListJSON := `[
@@ -0,0 +1,97 @@
+package storageinference
+
+import (
+	"fmt"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// typedStorageType returns the storage type of a typed value, it returns false for any other message
+func typedStorageType(message proto.Message) (StorageType, bool) {
+	switch message.(type) {
+	case *pb.TabularValue:
+		return TabularData, true
+	case *pb.GraphValue:
+		return GraphData, true
+	case *pb.DocumentValue:
+		return MapData, true
+	case *pb.ScalarValue:
+		return ScalarData, true
+	case *pb.BlobValue:
+		return BlobData, true
+	default:
+		return UnknownData, false
+	}
+}
+
+// Unwrap converts a typed value into the struct layout its storage type is stored in (see InferType),
+// so that the stores read typed and untyped values alike. Blob values and untyped values are returned as they are.
+//
+// Example:
+//
+//	TabularValue{columns: ["id"], rows: [[1]]}  =>  {"columns": ["id"], "rows": [[1]]}
+//	ScalarValue{value: 42}                      =>  {"value": 42}
+func Unwrap(anyValue *anypb.Any) (*anypb.Any, error) {
+	message, err := anyValue.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	var unwrapped *structpb.Struct
+	switch value := message.(type) {
+	case *pb.TabularValue:
+		columns := make([]*structpb.Value, len(value.Columns))
+		for i, column := range value.Columns {
+			columns[i] = structpb.NewStringValue(column)
+		}
+		rows := make([]*structpb.Value, len(value.Rows))
+		for i, row := range value.Rows {
+			if row == nil {
+				row = &structpb.ListValue{}
+			}
+			rows[i] = structpb.NewListValue(row)
+		}
+		unwrapped = &structpb.Struct{Fields: map[string]*structpb.Value{
+			"columns": structpb.NewListValue(&structpb.ListValue{Values: columns}),
+			"rows":    structpb.NewListValue(&structpb.ListValue{Values: rows}),
+		}}
+	case *pb.GraphValue:
+		unwrapped = &structpb.Struct{Fields: map[string]*structpb.Value{
+			"nodes": structList(value.Nodes),
+			"edges": structList(value.Edges),
+		}}
+	case *pb.DocumentValue:
+		unwrapped = value.GetDocument()
+		if unwrapped == nil {
+			return nil, fmt.Errorf("document value has no document")
+		}
+	case *pb.ScalarValue:
+		scalar := value.GetValue()
+		switch scalar.GetKind().(type) {
+		case nil:
+			scalar = structpb.NewNullValue()
+		case *structpb.Value_ListValue, *structpb.Value_StructValue:
+			return nil, fmt.Errorf("scalar value must be a number, string, boolean or null")
+		}
+		unwrapped = &structpb.Struct{Fields: map[string]*structpb.Value{"value": scalar}}
+	default:
+		return anyValue, nil
+	}
+	return anypb.New(unwrapped)
+}
+
+// structList converts structs into a list value
+func structList(structs []*structpb.Struct) *structpb.Value {
+	values := make([]*structpb.Value, len(structs))
+	for i, item := range structs {
+		if item == nil {
+			item = &structpb.Struct{}
+		}
+		values[i] = structpb.NewStructValue(item)
+	}
+	return structpb.NewListValue(&structpb.ListValue{Values: values})
+}
@@ -0,0 +1,99 @@
+package storageinference
+
+import (
+	"testing"
+
+	pb "lk/datafoundation/crud-api/lk/datafoundation/crud-api"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// pack packs a message into an Any value
+func pack(t *testing.T, message proto.Message) *anypb.Any {
+	value, err := anypb.New(message)
+	assert.NoError(t, err)
+	return value
+}
+
+// unwrapJSON unwraps a typed value and returns the resulting struct as JSON
+func unwrapJSON(t *testing.T, message proto.Message) string {
+	unwrapped, err := Unwrap(pack(t, message))
+	assert.NoError(t, err)
+	var result structpb.Struct
+	assert.NoError(t, unwrapped.UnmarshalTo(&result))
+	data, err := result.MarshalJSON()
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestTypedStorageType(t *testing.T) {
+	row, err := structpb.NewList([]interface{}{1, "Alice"})
+	assert.NoError(t, err)
+	node, err := structpb.NewStruct(map[string]interface{}{"id": "n1"})
+	assert.NoError(t, err)
+	// A document whose keys look like a table is still a document
+	document, err := structpb.NewStruct(map[string]interface{}{"columns": []interface{}{"a"}, "rows": []interface{}{}})
+	assert.NoError(t, err)
+
+	testCases := map[string]struct {
+		message  proto.Message
+		expected StorageType
+	}{
+		"tabular":  {&pb.TabularValue{Columns: []string{"id", "name"}, Rows: []*structpb.ListValue{row}}, TabularData},
+		"graph":    {&pb.GraphValue{Nodes: []*structpb.Struct{node}}, GraphData},
+		"document": {&pb.DocumentValue{Document: document}, MapData},
+		"scalar":   {&pb.ScalarValue{Value: structpb.NewStringValue("columns")}, ScalarData},
+		"blob":     {&pb.BlobValue{Data: []byte{0x1}, ContentType: "application/octet-stream"}, BlobData},
+	}
+	inferrer := &StorageInferrer{}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			storageType, err := inferrer.InferType(pack(t, tc.message))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, storageType)
+		})
+	}
+
+	// Untyped values still have their storage type inferred
+	storageType, err := inferrer.InferType(pack(t, document))
+	assert.NoError(t, err)
+	assert.Equal(t, TabularData, storageType)
+}
+
+func TestUnwrap(t *testing.T) {
+	row, err := structpb.NewList([]interface{}{1, "Alice"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"columns": ["id", "name"], "rows": [[1, "Alice"]]}`,
+		unwrapJSON(t, &pb.TabularValue{Columns: []string{"id", "name"}, Rows: []*structpb.ListValue{row}}))
+
+	node, err := structpb.NewStruct(map[string]interface{}{"id": "n1"})
+	assert.NoError(t, err)
+	edge, err := structpb.NewStruct(map[string]interface{}{"source": "n1", "target": "n1"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nodes": [{"id": "n1"}], "edges": [{"source": "n1", "target": "n1"}]}`,
+		unwrapJSON(t, &pb.GraphValue{Nodes: []*structpb.Struct{node}, Edges: []*structpb.Struct{edge}}))
+
+	document, err := structpb.NewStruct(map[string]interface{}{"title": "Budget"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"title": "Budget"}`, unwrapJSON(t, &pb.DocumentValue{Document: document}))
+	assert.JSONEq(t, `{"value": 42}`, unwrapJSON(t, &pb.ScalarValue{Value: structpb.NewNumberValue(42)}))
+	assert.JSONEq(t, `{"value": null}`, unwrapJSON(t, &pb.ScalarValue{}))
+
+	// Blobs and untyped values are returned as they are
+	blob := pack(t, &pb.BlobValue{Data: []byte("raw")})
+	unwrapped, err := Unwrap(blob)
+	assert.NoError(t, err)
+	assert.Same(t, blob, unwrapped)
+	untyped := pack(t, document)
+	unwrapped, err = Unwrap(untyped)
+	assert.NoError(t, err)
+	assert.Same(t, untyped, unwrapped)
+
+	_, err = Unwrap(pack(t, &pb.DocumentValue{}))
+	assert.EqualError(t, err, "document value has no document")
+	_, err = Unwrap(pack(t, &pb.ScalarValue{Value: structpb.NewListValue(&structpb.ListValue{})}))
+	assert.EqualError(t, err, "scalar value must be a number, string, boolean or null")
+}
@@ -0,0 +1,136 @@
+// Package tlsconfig builds the TLS configurations of the gRPC listener and of the connections to the stores
+// from the service configuration. Certificates and keys are loaded when the configuration is built,
+// so that a missing or invalid file stops the service at startup.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"lk/datafoundation/crud-api/db/config"
+)
+
+// Server returns the TLS configuration of the gRPC listener, or nil when TLS is disabled
+func Server(cfg config.ServerTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading server certificate: %v", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if cfg.MinVersion == "1.3" {
+		tlsConfig.MinVersion = tls.VersionTLS13
+	}
+
+	if cfg.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+	if tlsConfig.ClientCAs, err = loadPool(cfg.ClientCAFile); err != nil {
+		return nil, fmt.Errorf("error loading client CA bundle: %v", err)
+	}
+	switch cfg.ClientAuth {
+	case "", "require":
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	case "optional":
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case "none":
+		tlsConfig.ClientAuth = tls.NoClientCert
+	default:
+		return nil, fmt.Errorf("unknown client authentication %q, expected none, optional or require", cfg.ClientAuth)
+	}
+	return tlsConfig, nil
+}
+
+// Client returns the TLS configuration of the connection to a store, or nil when TLS is disabled.
+// The host name of the server is checked against the server name set by the driver in verify-full mode only.
+func Client(cfg config.TLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	var err error
+	if cfg.CAFile != "" {
+		if tlsConfig.RootCAs, err = loadPool(cfg.CAFile); err != nil {
+			return nil, fmt.Errorf("error loading CA bundle: %v", err)
+		}
+	}
+	if cfg.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	switch cfg.Mode {
+	case "require":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// The standard verification also checks the host name, the chain is verified on its own instead
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = verifyChain(tlsConfig.RootCAs)
+	case "verify-full":
+	default:
+		return nil, fmt.Errorf("unknown TLS mode %q, expected disable, require, verify-ca or verify-full", cfg.Mode)
+	}
+	return tlsConfig, nil
+}
+
+// Validate loads every TLS file of the configuration and reports every problem at once.
+// The stores are only checked when the database backend is selected.
+func Validate(cfg *config.Config) error {
+	var errs []error
+	if _, err := Server(cfg.Server.TLS); err != nil {
+		errs = append(errs, fmt.Errorf("server.tls: %v", err))
+	}
+	if cfg.Storage == "database" {
+		if _, err := Client(cfg.Mongo.TLS); err != nil {
+			errs = append(errs, fmt.Errorf("mongo.tls: %v", err))
+		}
+		if _, err := Client(cfg.Neo4j.TLS); err != nil {
+			errs = append(errs, fmt.Errorf("neo4j.tls: %v", err))
+		}
+		if _, err := Client(cfg.Postgres.TLS); err != nil {
+			errs = append(errs, fmt.Errorf("postgres.tls: %v", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verifyChain returns a callback verifying the certificate chain of the server against roots,
+// the system pool when roots is nil, without checking its host name
+func verifyChain(roots *x509.CertPool) func(tls.ConnectionState) error {
+	return func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("the server did not present a certificate")
+		}
+		intermediates := x509.NewCertPool()
+		for _, certificate := range state.PeerCertificates[1:] {
+			intermediates.AddCert(certificate)
+		}
+		_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// loadPool reads a PEM bundle of CA certificates
+func loadPool(path string) (*x509.CertPool, error) {
+	bundle, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("%s holds no PEM certificates", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,199 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCA issues certificates for the tests and writes them into a temporary directory
+type testCA struct {
+	dir         string
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	file        string
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+
+	ca := &testCA{dir: t.TempDir(), certificate: certificate, key: key}
+	ca.file = ca.write(t, name+".crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	return ca
+}
+
+func (ca *testCA) write(t *testing.T, name string, content []byte) string {
+	path := filepath.Join(ca.dir, name)
+	assert.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+// issue writes a certificate for the host names signed by the CA and returns the certificate and key files
+func (ca *testCA) issue(t *testing.T, name string, hosts []string, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		DNSNames:     hosts,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+	return ca.write(t, name+".crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		ca.write(t, name+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+// handshake connects a client to a server over the loopback interface and returns the first handshake error
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config, serverName string) error {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- tls.Server(conn, serverConfig).Handshake()
+	}()
+
+	clientConfig = clientConfig.Clone()
+	clientConfig.ServerName = serverName
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err == nil {
+		// The server verifies the client certificate after the client completed its handshake in TLS 1.3
+		err = <-serverErr
+		conn.Close()
+		return err
+	}
+	<-serverErr
+	return err
+}
+
+func TestClientModes(t *testing.T) {
+	ca := newTestCA(t, "store-ca")
+	certFile, keyFile := ca.issue(t, "store", []string{"db.internal"}, x509.ExtKeyUsageServerAuth)
+	serverConfig, err := Server(config.ServerTLSConfig{CertFile: certFile, KeyFile: keyFile})
+	assert.NoError(t, err)
+	other := newTestCA(t, "other-ca")
+
+	client := func(cfg config.TLSConfig) *tls.Config {
+		clientConfig, err := Client(cfg)
+		assert.NoError(t, err)
+		return clientConfig
+	}
+
+	// TLS is disabled by default
+	assert.Nil(t, client(config.TLSConfig{}))
+	assert.Nil(t, client(config.TLSConfig{Mode: "disable"}))
+
+	// verify-full checks the chain and the host name
+	assert.NoError(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "verify-full", CAFile: ca.file}), "db.internal"))
+	assert.Error(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "verify-full", CAFile: ca.file}), "db.example"))
+	assert.Error(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "verify-full", CAFile: other.file}), "db.internal"))
+
+	// verify-ca only checks the chain
+	assert.NoError(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "verify-ca", CAFile: ca.file}), "db.example"))
+	assert.Error(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "verify-ca", CAFile: other.file}), "db.internal"))
+
+	// require only encrypts
+	assert.NoError(t, handshake(t, serverConfig, client(config.TLSConfig{Mode: "require", CAFile: other.file}), "db.example"))
+}
+
+func TestServerClientAuth(t *testing.T) {
+	ca := newTestCA(t, "client-ca")
+	certFile, keyFile := ca.issue(t, "crud-api", []string{"crud.internal"}, x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "ingest-job", nil, x509.ExtKeyUsageClientAuth)
+
+	anonymous, err := Client(config.TLSConfig{Mode: "verify-full", CAFile: ca.file})
+	assert.NoError(t, err)
+	authenticated, err := Client(config.TLSConfig{Mode: "verify-full", CAFile: ca.file, CertFile: clientCert, KeyFile: clientKey})
+	assert.NoError(t, err)
+
+	server := func(clientAuth string) *tls.Config {
+		serverConfig, err := Server(config.ServerTLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: ca.file, ClientAuth: clientAuth})
+		assert.NoError(t, err)
+		return serverConfig
+	}
+
+	// Client certificates are required by default once a client CA bundle is set
+	assert.Error(t, handshake(t, server(""), anonymous, "crud.internal"))
+	assert.NoError(t, handshake(t, server(""), authenticated, "crud.internal"))
+	assert.NoError(t, handshake(t, server("optional"), anonymous, "crud.internal"))
+	assert.NoError(t, handshake(t, server("optional"), authenticated, "crud.internal"))
+
+	// A certificate signed by another CA is not accepted
+	other := newTestCA(t, "other-ca")
+	otherCert, otherKey := other.issue(t, "intruder", nil, x509.ExtKeyUsageClientAuth)
+	intruder, err := Client(config.TLSConfig{Mode: "verify-full", CAFile: ca.file, CertFile: otherCert, KeyFile: otherKey})
+	assert.NoError(t, err)
+	assert.Error(t, handshake(t, server("require"), intruder, "crud.internal"))
+
+	serverConfig, err := Server(config.ServerTLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "1.3"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), serverConfig.MinVersion)
+}
+
+func TestValidate(t *testing.T) {
+	ca := newTestCA(t, "ca")
+	certFile, keyFile := ca.issue(t, "crud-api", []string{"crud.internal"}, x509.ExtKeyUsageServerAuth)
+	notPEM := ca.write(t, "ca.txt", []byte("not a certificate"))
+	missing := filepath.Join(ca.dir, "missing.pem")
+
+	cfg := config.Default()
+	cfg.Server.TLS = config.ServerTLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: ca.file}
+	cfg.Mongo.TLS = config.TLSConfig{Mode: "verify-full", CAFile: ca.file}
+	assert.NoError(t, Validate(cfg))
+
+	cfg.Server.TLS.ClientCAFile = notPEM
+	cfg.Mongo.TLS.CAFile = missing
+	cfg.Postgres.TLS = config.TLSConfig{Mode: "require", CertFile: certFile, KeyFile: missing}
+	err := Validate(cfg)
+	assert.Error(t, err)
+	for _, problem := range []string{"server.tls: error loading client CA bundle", "mongo.tls: error loading CA bundle", "postgres.tls: error loading client certificate"} {
+		assert.Contains(t, err.Error(), problem)
+	}
+
+	// The stores are not used by the other backends
+	cfg.Storage = "memory"
+	cfg.Server.TLS = config.ServerTLSConfig{}
+	assert.NoError(t, Validate(cfg))
+}
@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"lk/datafoundation/crud-api/db/config"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+func TestSetupFileExporter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(ctx, config.TracingConfig{Exporter: "file", FilePath: path, ServiceName: "crud-api-test", SampleRatio: 1})
+	assert.NoError(t, err)
+
+	// A span started from an incoming trace context joins the caller's trace
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx = otel.GetTextMapPropagator().Extract(ctx, carrier)
+	ctx, parent := Tracer().Start(ctx, "CreateEntity")
+	_, child := Tracer().Start(ctx, "neo4j.HandleGraphEntityCreation")
+	End(child, errors.New("entity already exists"))
+	End(parent, nil)
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"CreateEntity"`)
+	assert.Contains(t, string(content), `"Name":"neo4j.HandleGraphEntityCreation"`)
+	assert.Contains(t, string(content), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, string(content), "entity already exists")
+	assert.Contains(t, string(content), "crud-api-test")
+}
+
+func TestSetupErrors(t *testing.T) {
+	_, err := Setup(context.Background(), config.TracingConfig{Exporter: "jaeger"})
+	assert.Error(t, err)
+	_, err = Setup(context.Background(), config.TracingConfig{Exporter: "file", FilePath: filepath.Join(t.TempDir(), "missing", "spans.json")})
+	assert.Error(t, err)
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "none"})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
@@ -0,0 +1,203 @@
+package typeinference
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// maxConflictRows is the number of conflicting rows listed for a column
+const maxConflictRows = 10
+
+// numericRanks orders the types that widen along null < bool < int < float < string
+var numericRanks = map[DataType]int{BoolType: 1, IntType: 2, FloatType: 3}
+
+// temporalRanks orders the types that widen along null < date < datetime < string
+var temporalRanks = map[DataType]int{DateType: 1, DateTimeType: 2}
+
+// Widen returns the narrowest type able to hold values of both types.
+// Types widen along null < bool < int < float < string and null < date < datetime < string,
+// values of types on different chains are only held by string.
+//
+// Example:
+//
+//	Widen(IntType, FloatType)      // FloatType
+//	Widen(NullType, DateType)      // DateType
+//	Widen(DateType, DateTimeType)  // DateTimeType
+//	Widen(BoolType, DateType)      // StringType
+func Widen(a, b DataType) DataType {
+	switch {
+	case a == b:
+		return a
+	case a == NullType:
+		return b
+	case b == NullType:
+		return a
+	}
+	if aRank, ok := numericRanks[a]; ok {
+		if bRank, ok := numericRanks[b]; ok {
+			if aRank > bRank {
+				return a
+			}
+			return b
+		}
+	}
+	if aRank, ok := temporalRanks[a]; ok {
+		if bRank, ok := temporalRanks[b]; ok {
+			if aRank > bRank {
+				return a
+			}
+			return b
+		}
+	}
+	return StringType
+}
+
+// CellType returns the type of a single tabular cell.
+// It returns false for lists and objects, which cannot be stored in a column.
+func CellType(value *structpb.Value) (DataType, bool) {
+	switch v := value.GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+		return NullType, true
+	case *structpb.Value_BoolValue:
+		return BoolType, true
+	case *structpb.Value_NumberValue:
+		if v.NumberValue == float64(int64(v.NumberValue)) {
+			return IntType, true
+		}
+		return FloatType, true
+	case *structpb.Value_StringValue:
+		switch {
+		case IsDate(v.StringValue):
+			return DateType, true
+		case IsTime(v.StringValue):
+			return TimeType, true
+		case IsDateTime(v.StringValue):
+			return DateTimeType, true
+		}
+		return StringType, true
+	default:
+		return "", false
+	}
+}
+
+// ColumnValue converts a tabular cell into the value stored in a column of the given type.
+// Cells of a narrower type are widened: booleans become 1 or 0 in numeric columns and
+// numbers and booleans are written out in string columns.
+func ColumnValue(value *structpb.Value, dataType DataType) interface{} {
+	switch v := value.GetKind().(type) {
+	case nil, *structpb.Value_NullValue:
+		return nil
+	case *structpb.Value_BoolValue:
+		number := 0
+		if v.BoolValue {
+			number = 1
+		}
+		switch dataType {
+		case IntType:
+			return int64(number)
+		case FloatType:
+			return float64(number)
+		case StringType:
+			return strconv.FormatBool(v.BoolValue)
+		}
+		return v.BoolValue
+	case *structpb.Value_NumberValue:
+		switch dataType {
+		case IntType:
+			return int64(v.NumberValue)
+		case StringType:
+			return strconv.FormatFloat(v.NumberValue, 'f', -1, 64)
+		}
+		return v.NumberValue
+	case *structpb.Value_StringValue:
+		return v.StringValue
+	default:
+		return value.GetStringValue()
+	}
+}
+
+// ColumnConflict lists the rows of a column holding values that cannot be stored in a column
+type ColumnConflict struct {
+	Column string
+	Rows   []int
+}
+
+// ColumnConflictError is returned when cells of a table cannot be stored in their columns
+type ColumnConflictError struct {
+	Conflicts []ColumnConflict
+}
+
+func (e *ColumnConflictError) Error() string {
+	messages := make([]string, len(e.Conflicts))
+	for i, conflict := range e.Conflicts {
+		rows := make([]string, 0, maxConflictRows+1)
+		for j, row := range conflict.Rows {
+			if j == maxConflictRows {
+				rows = append(rows, fmt.Sprintf("and %d more", len(conflict.Rows)-maxConflictRows))
+				break
+			}
+			rows = append(rows, strconv.Itoa(row))
+		}
+		messages[i] = fmt.Sprintf("column %s holds lists or objects in rows %s", conflict.Column, strings.Join(rows, ", "))
+	}
+	return "conflicting column values: " + strings.Join(messages, "; ")
+}
+
+// InferColumnTypes scans every row of a table and returns the type of each of its columns.
+// The type of a column is the narrowest type holding all of its values (see Widen),
+// a column is nullable when one of its cells is null and a column holding only nulls has NullType.
+// Cells that cannot be stored in a column are reported together in a *ColumnConflictError.
+//
+// Parameters:
+//   - columns: The column names of the table
+//   - rows: The rows of the table, each a list holding one value per column
+//
+// Returns:
+//   - map[string]*TypeInfo: The type of each column, empty when the table has no rows
+//   - error: A malformed row or a *ColumnConflictError
+func InferColumnTypes(columns []string, rows []*structpb.Value) (map[string]*TypeInfo, error) {
+	columnTypes := make(map[string]*TypeInfo, len(columns))
+	if len(rows) == 0 {
+		return columnTypes, nil
+	}
+
+	for _, column := range columns {
+		columnTypes[column] = &TypeInfo{Type: NullType}
+	}
+	conflicts := make(map[string][]int)
+	for i, row := range rows {
+		rowList := row.GetListValue()
+		if rowList == nil {
+			return nil, fmt.Errorf("row %d must be a list", i)
+		}
+		if len(rowList.Values) != len(columns) {
+			return nil, fmt.Errorf("row %d has %d values for %d columns", i, len(rowList.Values), len(columns))
+		}
+		for j, cell := range rowList.Values {
+			cellType, ok := CellType(cell)
+			if !ok {
+				conflicts[columns[j]] = append(conflicts[columns[j]], i)
+				continue
+			}
+			columnType := columnTypes[columns[j]]
+			if cellType == NullType {
+				columnType.IsNullable = true
+			}
+			columnType.Type = Widen(columnType.Type, cellType)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		err := &ColumnConflictError{}
+		for column, conflictRows := range conflicts {
+			err.Conflicts = append(err.Conflicts, ColumnConflict{Column: column, Rows: conflictRows})
+		}
+		sort.Slice(err.Conflicts, func(i, j int) bool { return err.Conflicts[i].Column < err.Conflicts[j].Column })
+		return nil, err
+	}
+	return columnTypes, nil
+}
@@ -0,0 +1,91 @@
+package typeinference
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestWiden(t *testing.T) {
+	testCases := []struct {
+		a, b     DataType
+		expected DataType
+	}{
+		{NullType, NullType, NullType},
+		{NullType, BoolType, BoolType},
+		{BoolType, IntType, IntType},
+		{IntType, FloatType, FloatType},
+		{FloatType, IntType, FloatType},
+		{FloatType, StringType, StringType},
+		{NullType, DateType, DateType},
+		{DateTimeType, DateType, DateTimeType},
+		{DateType, IntType, StringType},
+		{TimeType, DateTimeType, StringType},
+		{TimeType, NullType, TimeType},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, Widen(tc.a, tc.b), "%s and %s", tc.a, tc.b)
+	}
+}
+
+// tableRows decodes the rows of a table from JSON
+func tableRows(t *testing.T, rows string) []*structpb.Value {
+	var decoded []interface{}
+	assert.NoError(t, json.Unmarshal([]byte(rows), &decoded))
+	list, err := structpb.NewList(decoded)
+	assert.NoError(t, err)
+	return list.Values
+}
+
+func TestInferColumnTypes(t *testing.T) {
+	columns := []string{"id", "score", "active", "joined", "note", "empty"}
+	columnTypes, err := InferColumnTypes(columns, tableRows(t, `[
+		[1, 1, true, "2024-01-01", "first", null],
+		[2, 1.5, false, "2024-01-02T10:00:00Z", null, null],
+		[3, null, 1, "2024-01-03", 7, null]
+	]`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*TypeInfo{
+		"id":     {Type: IntType},
+		"score":  {Type: FloatType, IsNullable: true},
+		"active": {Type: IntType},
+		"joined": {Type: DateTimeType},
+		"note":   {Type: StringType, IsNullable: true},
+		"empty":  {Type: NullType, IsNullable: true},
+	}, columnTypes)
+
+	// A table without rows has no column types
+	columnTypes, err = InferColumnTypes(columns, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, columnTypes)
+
+	_, err = InferColumnTypes([]string{"a", "b"}, tableRows(t, `[[1, 2], [3]]`))
+	assert.EqualError(t, err, "row 1 has 1 values for 2 columns")
+	_, err = InferColumnTypes([]string{"a"}, tableRows(t, `[[1], 2]`))
+	assert.EqualError(t, err, "row 1 must be a list")
+
+	// Every conflicting cell is reported with its row
+	_, err = InferColumnTypes([]string{"tags", "owner", "name"}, tableRows(t, `[
+		[["a"], {"id": 1}, "x"],
+		["b", "c", "y"],
+		[[], "d", "z"]
+	]`))
+	var conflictErr *ColumnConflictError
+	assert.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, []ColumnConflict{{Column: "owner", Rows: []int{0}}, {Column: "tags", Rows: []int{0, 2}}}, conflictErr.Conflicts)
+	assert.EqualError(t, err, "conflicting column values: column owner holds lists or objects in rows 0; column tags holds lists or objects in rows 0, 2")
+}
+
+func TestColumnValue(t *testing.T) {
+	assert.Nil(t, ColumnValue(structpb.NewNullValue(), IntType))
+	assert.Equal(t, int64(1), ColumnValue(structpb.NewBoolValue(true), IntType))
+	assert.Equal(t, float64(0), ColumnValue(structpb.NewBoolValue(false), FloatType))
+	assert.Equal(t, "true", ColumnValue(structpb.NewBoolValue(true), StringType))
+	assert.Equal(t, true, ColumnValue(structpb.NewBoolValue(true), BoolType))
+	assert.Equal(t, int64(3), ColumnValue(structpb.NewNumberValue(3), IntType))
+	assert.Equal(t, 3.0, ColumnValue(structpb.NewNumberValue(3), FloatType))
+	assert.Equal(t, "1.5", ColumnValue(structpb.NewNumberValue(1.5), StringType))
+	assert.Equal(t, "2024-01-01", ColumnValue(structpb.NewStringValue("2024-01-01"), DateTimeType))
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"lk/datafoundation/crud-api/pkg/geo"
+
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -26,6 +28,7 @@ const (
 	DateType     DataType = "date"     // Date values (e.g., "2024-03-20")
 	TimeType     DataType = "time"     // Time values (e.g., "14:30:00")
 	DateTimeType DataType = "datetime" // Date and time values (e.g., "2024-03-20T14:30:00Z")
+	GeometryType DataType = "geometry" // GeoJSON geometries and features (e.g., {"type": "Point", "coordinates": [79.86, 6.93]})
 )
 
 // TypeInfo contains both the data type and additional metadata about the type.
@@ -67,6 +70,10 @@ func (ti *TypeInferrer) InferType(anyValue *anypb.Any) (*TypeInfo, error) {
 
 	// Handle structpb.Struct
 	if structValue, ok := message.(*structpb.Struct); ok {
+		// A GeoJSON object is a single value even though it has several fields
+		if geo.IsGeoJSON(structValue) {
+			return &TypeInfo{Type: GeometryType}, nil
+		}
 		// If it's a struct with a single field, use that field's value
 		if len(structValue.Fields) == 1 {
 			for _, value := range structValue.Fields {
@@ -123,13 +130,13 @@ func (ti *TypeInferrer) inferTypeFromValue(value *structpb.Value) (*TypeInfo, er
 	case *structpb.Value_StringValue:
 		str := v.StringValue
 		// Check for special string types
-		if isDate(str) {
+		if IsDate(str) {
 			return &TypeInfo{Type: DateType}, nil
 		}
-		if isTime(str) {
+		if IsTime(str) {
 			return &TypeInfo{Type: TimeType}, nil
 		}
-		if isDateTime(str) {
+		if IsDateTime(str) {
 			return &TypeInfo{Type: DateTimeType}, nil
 		}
 		return &TypeInfo{Type: StringType}, nil
@@ -164,6 +171,9 @@ func (ti *TypeInferrer) inferTypeFromValue(value *structpb.Value) (*TypeInfo, er
 		if structVal == nil {
 			return &TypeInfo{Type: StringType}, nil
 		}
+		if geo.IsGeoJSON(structVal) {
+			return &TypeInfo{Type: GeometryType}, nil
+		}
 
 		// For map types, we use StringType as a base type because:
 		// 1. In many systems, complex objects are serialized to strings (e.g., JSON)
@@ -217,7 +227,7 @@ func (ti *TypeInferrer) inferTypeFromReflection(rv reflect.Value) (*TypeInfo, er
 	}
 }
 
-// isDate checks if a string represents a valid date.
+// IsDate checks if a string represents a valid date.
 // It supports multiple common date formats including:
 // - YYYY-MM-DD (e.g., "2024-03-20")
 // - DD/MM/YYYY (e.g., "20/03/2024")
@@ -232,7 +242,7 @@ func (ti *TypeInferrer) inferTypeFromReflection(rv reflect.Value) (*TypeInfo, er
 //
 // Returns:
 //   - bool: True if the string matches any of the supported date formats
-func isDate(str string) bool {
+func IsDate(str string) bool {
 	dateFormats := []string{
 		"2006-01-02", // YYYY-MM-DD
 		"02/01/2006", // DD/MM/YYYY
@@ -251,7 +261,7 @@ func isDate(str string) bool {
 	return false
 }
 
-// isTime checks if a string represents a valid time.
+// IsTime checks if a string represents a valid time.
 // It supports multiple common time formats including:
 // - HH:MM:SS (e.g., "14:30:00")
 // - HH:MM (e.g., "14:30")
@@ -265,7 +275,7 @@ func isDate(str string) bool {
 //
 // Returns:
 //   - bool: True if the string matches any of the supported time formats
-func isTime(str string) bool {
+func IsTime(str string) bool {
 	timeFormats := []string{
 		"15:04:05",       // HH:MM:SS
 		"15:04",          // HH:MM
@@ -283,7 +293,7 @@ func isTime(str string) bool {
 	return false
 }
 
-// isDateTime checks if a string represents a valid datetime.
+// IsDateTime checks if a string represents a valid datetime.
 // It supports multiple common datetime formats including:
 // - RFC3339 (e.g., "2024-03-20T14:30:00Z07:00")
 // - YYYY-MM-DD HH:MM:SS (e.g., "2024-03-20 14:30:00")
@@ -298,7 +308,7 @@ func isTime(str string) bool {
 //
 // Returns:
 //   - bool: True if the string matches any of the supported datetime formats
-func isDateTime(str string) bool {
+func IsDateTime(str string) bool {
 	datetimeFormats := []string{
 		time.RFC3339,              // 2006-01-02T15:04:05Z07:00
 		"2006-01-02 15:04:05",     // YYYY-MM-DD HH:MM:SS
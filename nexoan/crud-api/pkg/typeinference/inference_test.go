@@ -275,6 +275,22 @@ func TestSpecialTypes(t *testing.T) {
 			json:     `{"attributes": "2024-03-20T14:30:00Z"}`,
 			expected: DateTimeType,
 		},
+		"geometry": {
+			json:     `{"type": "Polygon", "coordinates": [[[79.8, 6.8], [80.0, 6.8], [80.0, 7.0], [79.8, 6.8]]]}`,
+			expected: GeometryType,
+		},
+		"nested_geometry": {
+			json:     `{"boundary": {"type": "Point", "coordinates": [79.86, 6.93]}}`,
+			expected: GeometryType,
+		},
+		"feature": {
+			json:     `{"type": "Feature", "geometry": {"type": "Point", "coordinates": [79.86, 6.93]}, "properties": {}}`,
+			expected: GeometryType,
+		},
+		"typed_map": {
+			json:     `{"type": "Point", "name": "office"}`,
+			expected: StringType,
+		},
 	}
 
 	inferrer := &TypeInferrer{}